@@ -0,0 +1,102 @@
+package ant
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"syscall"
+)
+
+// ErrAddrInUse 监听地址已被其他进程占用，可以通过 errors.Is 判断
+var ErrAddrInUse = errors.New("web: 地址已被占用")
+
+// AddrError 监听地址不合法或无法绑定
+type AddrError struct {
+	// Addr 监听地址
+	Addr string
+	// Reason 原因，例如 "端口超出范围"
+	Reason string
+	// Err 原始错误，可能为nil
+	Err error
+}
+
+// Error 实现 error 接口
+func (e *AddrError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("web: 监听地址 %q %s: %v", e.Addr, e.Reason, e.Err)
+	}
+	return fmt.Sprintf("web: 监听地址 %q %s", e.Addr, e.Reason)
+}
+
+// Unwrap 返回原始错误
+func (e *AddrError) Unwrap() error {
+	return e.Err
+}
+
+// Is 使 errors.Is(err, ErrAddrInUse) 对端口占用的错误返回 true
+func (e *AddrError) Is(target error) bool {
+	return target == ErrAddrInUse && errors.Is(e.Err, syscall.EADDRINUSE)
+}
+
+// ValidateAddr 检查监听地址的格式，不会绑定端口
+// 地址的格式为 "host:port"，host 可以为空，port 可以是 0-65535 的数字或服务名（例如 "http"）
+// 空地址与 http.Server 的语义一致，表示 ":http"
+// 返回值: 不合法时返回 *AddrError
+func ValidateAddr(addr string) error {
+	if addr == "" {
+		return nil
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return &AddrError{Addr: addr, Reason: "格式错误，应为 host:port", Err: err}
+	}
+	if port == "" {
+		return &AddrError{Addr: addr, Reason: "缺少端口"}
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		if _, err = net.LookupPort("tcp", port); err != nil {
+			return &AddrError{Addr: addr, Reason: "端口不合法", Err: err}
+		}
+		return nil
+	}
+	if n < 0 || n > 65535 {
+		return &AddrError{Addr: addr, Reason: fmt.Sprintf("端口 %d 超出范围 0-65535", n)}
+	}
+	return nil
+}
+
+// ProbeAddr 检查监听地址的格式，并尝试绑定后立即释放，用于在启动前发现端口冲突
+// 可以注册为启动前的检查：
+//
+//	server.AddCheck("listen", func() error { return ant.ProbeAddr(addr) })
+//
+// 注意：检查通过后端口仍可能在真正监听前被占用，Run 会再次返回绑定的错误
+func ProbeAddr(addr string) error {
+	l, err := listenTCP(addr)
+	if err != nil {
+		return err
+	}
+	return l.Close()
+}
+
+// listenTCP 校验地址并绑定，失败时返回 *AddrError
+func listenTCP(addr string) (net.Listener, error) {
+	if err := ValidateAddr(addr); err != nil {
+		return nil, err
+	}
+	bind := addr
+	if bind == "" {
+		bind = ":http"
+	}
+	l, err := net.Listen("tcp", bind)
+	if err != nil {
+		reason := "无法绑定"
+		if errors.Is(err, syscall.EADDRINUSE) {
+			reason = "已被占用"
+		}
+		return nil, &AddrError{Addr: addr, Reason: reason, Err: err}
+	}
+	return l, nil
+}
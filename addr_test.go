@@ -0,0 +1,58 @@
+package ant
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestValidateAddr(t *testing.T) {
+	testCases := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{addr: ""},
+		{addr: ":8080"},
+		{addr: "127.0.0.1:0"},
+		{addr: "[::1]:65535"},
+		{addr: "localhost:http"},
+		{addr: "8080", wantErr: true},
+		{addr: "localhost:", wantErr: true},
+		{addr: ":65536", wantErr: true},
+		{addr: ":-1", wantErr: true},
+		{addr: ":no-such-service", wantErr: true},
+		{addr: "::1:80", wantErr: true},
+	}
+	for _, tc := range testCases {
+		err := ValidateAddr(tc.addr)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ValidateAddr(%q) 的错误为 %v，期望出错: %v", tc.addr, err, tc.wantErr)
+		}
+		var addrErr *AddrError
+		if err != nil && (!errors.As(err, &addrErr) || addrErr.Addr != tc.addr) {
+			t.Errorf("ValidateAddr(%q) 应返回 *AddrError，实际为 %T", tc.addr, err)
+		}
+	}
+}
+
+func TestRunAddrInUse(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	addr := l.Addr().String()
+
+	if err := ProbeAddr(addr); !errors.Is(err, ErrAddrInUse) {
+		t.Errorf("端口被占用时 ProbeAddr 应返回 ErrAddrInUse，实际为 %v", err)
+	}
+	if err := NewHTTPServer().Run(addr); !errors.Is(err, ErrAddrInUse) {
+		t.Errorf("端口被占用时 Run 应立即返回 ErrAddrInUse，实际为 %v", err)
+	}
+	if err := NewHTTPServer().Run(":99999"); err == nil || errors.Is(err, ErrAddrInUse) {
+		t.Errorf("端口超出范围时应返回格式错误，实际为 %v", err)
+	}
+	if err := ProbeAddr("127.0.0.1:0"); err != nil {
+		t.Errorf("空闲端口的探测应通过，实际为 %v", err)
+	}
+}
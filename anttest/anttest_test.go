@@ -0,0 +1,173 @@
+package anttest
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/ant"
+	"github.com/justinwongcn/ant/session"
+	"github.com/justinwongcn/ant/session/cookie"
+	"github.com/justinwongcn/ant/session/memory"
+)
+
+// newTestServer 创建测试用的服务器
+func newTestServer(m *session.Manager) *ant.HTTPServer {
+	server := ant.NewHTTPServer()
+	server.Handle("GET /hello", func(ctx *ant.Context) {
+		_ = ctx.RespJSONOK(map[string]string{"message": "hello, ant"})
+	})
+	server.Handle("GET /echo", func(ctx *ant.Context) {
+		ctx.Resp.Header().Set("X-Echo", ctx.Req.Header.Get("X-Token"))
+		ctx.RespStatusCode = http.StatusOK
+		ctx.RespData = []byte(ctx.Req.URL.Query().Get("q"))
+	})
+	server.Handle("POST /users", func(ctx *ant.Context) {
+		var user struct {
+			Name string `json:"name"`
+		}
+		if err := ctx.BindJSON(&user); err != nil {
+			ctx.RespStatusCode = http.StatusBadRequest
+			return
+		}
+		_ = ctx.RespJSON(http.StatusCreated, map[string]any{"id": 1, "name": user.Name})
+	})
+	server.Handle("POST /form", func(ctx *ant.Context) {
+		ctx.RespStatusCode = http.StatusOK
+		ctx.RespData = []byte(ctx.Req.FormValue("name"))
+	})
+	server.Handle("POST /upload", func(ctx *ant.Context) {
+		file, header, err := ctx.Req.FormFile("file")
+		if err != nil {
+			ctx.RespStatusCode = http.StatusBadRequest
+			return
+		}
+		defer file.Close()
+		content, _ := io.ReadAll(file)
+		ctx.RespStatusCode = http.StatusOK
+		ctx.RespData = []byte(ctx.Req.FormValue("title") + ":" + header.Filename + ":" + string(content))
+	})
+	server.Handle("POST /login", func(ctx *ant.Context) {
+		http.SetCookie(ctx.Resp, &http.Cookie{Name: "token", Value: "secret"})
+		ctx.RespStatusCode = http.StatusNoContent
+	})
+	server.Handle("GET /me", func(ctx *ant.Context) {
+		c, err := ctx.Req.Cookie("token")
+		if err != nil || c.Value != "secret" {
+			ctx.RespStatusCode = http.StatusUnauthorized
+			return
+		}
+		ctx.RespStatusCode = http.StatusOK
+	})
+	if m != nil {
+		server.Handle("GET /session", func(ctx *ant.Context) {
+			sess, err := m.GetSession(*ctx)
+			if err != nil {
+				ctx.RespStatusCode = http.StatusUnauthorized
+				return
+			}
+			user, _ := sess.Get(ctx.Req.Context(), "user")
+			ctx.RespStatusCode = http.StatusOK
+			ctx.RespData = []byte(user.(string))
+		})
+	}
+	return server
+}
+
+// TestClientJSON 测试 JSON 请求与响应
+func TestClientJSON(t *testing.T) {
+	client := New(newTestServer(nil))
+
+	var out struct {
+		Message string `json:"message"`
+	}
+	client.GET("/hello").Expect(t).
+		Status(http.StatusOK).
+		Header("Content-Type", "application/json; charset=utf-8").
+		JSON(&out)
+	if out.Message != "hello, ant" {
+		t.Errorf("解码结果错误: %+v", out)
+	}
+
+	client.POST("/users").WithJSON(map[string]string{"name": "tom"}).Expect(t).
+		Status(http.StatusCreated).
+		JSONEq(`{"name": "tom", "id": 1}`)
+}
+
+// TestClientHeaderAndQuery 测试请求头与查询参数
+func TestClientHeaderAndQuery(t *testing.T) {
+	client := New(newTestServer(nil)).WithHeader("X-Token", "default")
+
+	client.GET("/echo").WithQuery("q", "a b").Expect(t).
+		Status(http.StatusOK).
+		Header("X-Echo", "default").
+		Body("a b")
+
+	client.GET("/echo?q=x").WithHeader("X-Token", "override").Expect(t).
+		Header("X-Echo", "override").
+		BodyContains("x")
+}
+
+// TestClientForm 测试表单请求
+func TestClientForm(t *testing.T) {
+	New(newTestServer(nil)).POST("/form").WithForm(url.Values{"name": {"tom"}}).Expect(t).
+		Status(http.StatusOK).
+		Body("tom")
+}
+
+// TestClientMultipart 测试文件上传
+func TestClientMultipart(t *testing.T) {
+	form := NewMultipart().
+		Field("title", "report").
+		File("file", "a.txt", []byte("content"))
+
+	New(newTestServer(nil)).POST("/upload").WithMultipart(form).Expect(t).
+		Status(http.StatusOK).
+		Body("report:a.txt:content")
+}
+
+// TestMultipartFileFromDiskError 测试读取不存在的文件时报告错误
+func TestMultipartFileFromDiskError(t *testing.T) {
+	form := NewMultipart().FileFromDisk("file", "testdata/not-exist")
+	if _, err := New(newTestServer(nil)).POST("/upload").WithMultipart(form).Do(); err == nil {
+		t.Error("读取不存在的文件应返回错误")
+	}
+}
+
+// TestClientCookieJar 测试在请求之间保存 Cookie
+func TestClientCookieJar(t *testing.T) {
+	client := New(newTestServer(nil))
+	client.GET("/me").Expect(t).Status(http.StatusUnauthorized)
+
+	c := client.POST("/login").Expect(t).Status(http.StatusNoContent).Cookie("token")
+	if c.Value != "secret" {
+		t.Errorf("Cookie 值错误: %s", c.Value)
+	}
+	client.GET("/me").Expect(t).Status(http.StatusOK)
+
+	// 其他客户端不共享 Cookie
+	New(newTestServer(nil)).GET("/me").WithCookie(&http.Cookie{Name: "token", Value: "secret"}).Expect(t).
+		Status(http.StatusOK)
+}
+
+// TestClientSession 测试注入会话
+func TestClientSession(t *testing.T) {
+	m := &session.Manager{
+		Store:      memory.NewStore(time.Minute),
+		Propagator: cookie.NewPropagator(),
+		SessCtxKey: "session",
+	}
+	client := New(newTestServer(m))
+
+	client.GET("/session").Expect(t).Status(http.StatusUnauthorized)
+	client.GET("/session").WithSession(m, map[string]any{"user": "tom"}).Expect(t).
+		Status(http.StatusOK).
+		Body("tom")
+}
+
+// TestResponseGolden 测试黄金文件断言
+func TestResponseGolden(t *testing.T) {
+	New(newTestServer(nil)).GET("/hello").Expect(t).Golden("hello")
+}
@@ -0,0 +1,261 @@
+package anttest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/justinwongcn/ant/session"
+)
+
+// baseURL 测试请求使用的地址，与 httptest.NewRequest 的默认主机一致
+const baseURL = "http://example.com"
+
+// Client 在进程内调用 http.Handler 的测试客户端，不需要监听端口
+// 客户端会保存响应设置的 Cookie 并在后续请求中携带，便于测试登录等多步流程
+type Client struct {
+	handler http.Handler
+	header  http.Header
+	jar     *cookiejar.Jar
+}
+
+// New 创建测试客户端
+// handler: 被测试的处理器，通常为 *ant.HTTPServer
+func New(handler http.Handler) *Client {
+	// cookiejar.New 只有在 PublicSuffixList 出错时返回错误，nil 参数不会出错
+	jar, _ := cookiejar.New(nil)
+	return &Client{
+		handler: handler,
+		header:  make(http.Header),
+		jar:     jar,
+	}
+}
+
+// WithHeader 设置所有请求都携带的请求头
+func (c *Client) WithHeader(key, value string) *Client {
+	c.header.Set(key, value)
+	return c
+}
+
+// GET 创建 GET 请求
+func (c *Client) GET(path string) *Request {
+	return c.Request(http.MethodGet, path)
+}
+
+// POST 创建 POST 请求
+func (c *Client) POST(path string) *Request {
+	return c.Request(http.MethodPost, path)
+}
+
+// PUT 创建 PUT 请求
+func (c *Client) PUT(path string) *Request {
+	return c.Request(http.MethodPut, path)
+}
+
+// PATCH 创建 PATCH 请求
+func (c *Client) PATCH(path string) *Request {
+	return c.Request(http.MethodPatch, path)
+}
+
+// DELETE 创建 DELETE 请求
+func (c *Client) DELETE(path string) *Request {
+	return c.Request(http.MethodDelete, path)
+}
+
+// Request 创建指定方法的请求
+// path: 请求路径，可以包含查询参数
+func (c *Client) Request(method, path string) *Request {
+	return &Request{
+		client: c,
+		method: method,
+		path:   path,
+		header: c.header.Clone(),
+		query:  make(url.Values),
+		ctx:    context.Background(),
+	}
+}
+
+// Request 构建中的测试请求
+// 构建过程中的错误会在 Expect 时报告
+type Request struct {
+	client  *Client
+	method  string
+	path    string
+	header  http.Header
+	query   url.Values
+	cookies []*http.Cookie
+	body    []byte
+	ctx     context.Context
+	err     error
+}
+
+// WithHeader 设置请求头
+func (r *Request) WithHeader(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// WithQuery 追加查询参数
+func (r *Request) WithQuery(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// WithCookie 携带 Cookie
+func (r *Request) WithCookie(cookie *http.Cookie) *Request {
+	r.cookies = append(r.cookies, cookie)
+	return r
+}
+
+// WithContext 设置请求的 context，例如用于测试超时与取消
+func (r *Request) WithContext(ctx context.Context) *Request {
+	r.ctx = ctx
+	return r
+}
+
+// WithBody 设置请求体
+// contentType: Content-Type 请求头，为空时不设置
+func (r *Request) WithBody(contentType string, body []byte) *Request {
+	if contentType != "" {
+		r.header.Set("Content-Type", contentType)
+	}
+	r.body = body
+	return r
+}
+
+// WithJSON 将 v 编码为 JSON 作为请求体
+func (r *Request) WithJSON(v any) *Request {
+	data, err := json.Marshal(v)
+	if err != nil {
+		r.err = fmt.Errorf("anttest: 编码 JSON 请求体失败: %w", err)
+		return r
+	}
+	return r.WithBody("application/json", data)
+}
+
+// WithForm 将表单编码为 application/x-www-form-urlencoded 请求体
+func (r *Request) WithForm(values url.Values) *Request {
+	return r.WithBody("application/x-www-form-urlencoded", []byte(values.Encode()))
+}
+
+// WithMultipart 将 multipart 表单作为请求体，用于测试文件上传
+func (r *Request) WithMultipart(m *Multipart) *Request {
+	contentType, body, err := m.Encode()
+	if err != nil {
+		r.err = err
+		return r
+	}
+	return r.WithBody(contentType, body)
+}
+
+// WithSession 在会话存储中创建会话并让请求携带该会话
+// m: 被测试服务器使用的会话管理器
+// values: 写入会话的数据
+// 注意：会话ID通过 m.Propagator 注入，Set-Cookie 转换为 Cookie 请求头，其他响应头原样复制到请求头
+func (r *Request) WithSession(m *session.Manager, values map[string]any) *Request {
+	id, err := newSessionID()
+	if err != nil {
+		r.err = err
+		return r
+	}
+	sess, err := m.Generate(r.ctx, id)
+	if err != nil {
+		r.err = fmt.Errorf("anttest: 创建会话失败: %w", err)
+		return r
+	}
+	for key, value := range values {
+		if err = sess.Set(r.ctx, key, value); err != nil {
+			r.err = fmt.Errorf("anttest: 写入会话数据 %s 失败: %w", key, err)
+			return r
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	if err = m.Inject(id, rec); err != nil {
+		r.err = fmt.Errorf("anttest: 注入会话失败: %w", err)
+		return r
+	}
+	r.cookies = append(r.cookies, rec.Result().Cookies()...)
+	for key, vals := range rec.Header() {
+		if key == "Set-Cookie" {
+			continue
+		}
+		for _, v := range vals {
+			r.header.Add(key, v)
+		}
+	}
+	return r
+}
+
+// newSessionID 生成随机的会话ID
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("anttest: 生成会话ID失败: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// build 创建标准库的请求
+func (r *Request) build() (*http.Request, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	target := r.path
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + r.query.Encode()
+	}
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	req, err := http.NewRequestWithContext(r.ctx, r.method, baseURL+target, body)
+	if err != nil {
+		return nil, fmt.Errorf("anttest: 创建请求失败: %w", err)
+	}
+	req.Header = r.header.Clone()
+	for _, c := range r.client.jar.Cookies(req.URL) {
+		req.AddCookie(c)
+	}
+	for _, c := range r.cookies {
+		req.AddCookie(c)
+	}
+	return req, nil
+}
+
+// Do 执行请求并返回响应记录器，适用于需要自行断言的场景
+func (r *Request) Do() (*httptest.ResponseRecorder, error) {
+	req, err := r.build()
+	if err != nil {
+		return nil, err
+	}
+	rec := httptest.NewRecorder()
+	r.client.handler.ServeHTTP(rec, req)
+	r.client.jar.SetCookies(req.URL, rec.Result().Cookies())
+	return rec, nil
+}
+
+// Expect 执行请求并返回用于断言的响应
+// 请求无法构建时测试立即失败
+func (r *Request) Expect(t testing.TB) *Response {
+	t.Helper()
+	rec, err := r.Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Response{t: t, Recorder: rec}
+}
@@ -0,0 +1,123 @@
+package anttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv 设置该环境变量为非空值时，Golden 断言会用实际响应覆盖黄金文件
+// 例如 ANTTEST_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "ANTTEST_UPDATE_GOLDEN"
+
+// Response 用于断言的响应
+// 断言失败时通过 t.Errorf 报告并继续执行，解码失败等无法继续的情况通过 t.Fatalf 报告
+type Response struct {
+	t testing.TB
+	// Recorder 原始的响应记录器
+	Recorder *httptest.ResponseRecorder
+}
+
+// Status 断言状态码
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	if r.Recorder.Code != code {
+		r.t.Errorf("状态码错误，期望 %d，实际 %d，响应体: %s", code, r.Recorder.Code, r.Recorder.Body.String())
+	}
+	return r
+}
+
+// Header 断言响应头的值
+func (r *Response) Header(key, value string) *Response {
+	r.t.Helper()
+	if got := r.Recorder.Header().Get(key); got != value {
+		r.t.Errorf("响应头 %s 错误，期望 %q，实际 %q", key, value, got)
+	}
+	return r
+}
+
+// Body 断言响应体与 body 完全相同
+func (r *Response) Body(body string) *Response {
+	r.t.Helper()
+	if got := r.Recorder.Body.String(); got != body {
+		r.t.Errorf("响应体错误，期望 %q，实际 %q", body, got)
+	}
+	return r
+}
+
+// BodyContains 断言响应体包含 substr
+func (r *Response) BodyContains(substr string) *Response {
+	r.t.Helper()
+	if got := r.Recorder.Body.String(); !strings.Contains(got, substr) {
+		r.t.Errorf("响应体应包含 %q，实际 %q", substr, got)
+	}
+	return r
+}
+
+// JSON 将响应体解码到 out
+func (r *Response) JSON(out any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Recorder.Body.Bytes(), out); err != nil {
+		r.t.Fatalf("解码 JSON 响应体失败: %v，响应体: %s", err, r.Recorder.Body.String())
+	}
+	return r
+}
+
+// JSONEq 断言响应体与 expected 在 JSON 语义上相等，忽略字段顺序与空白
+func (r *Response) JSONEq(expected string) *Response {
+	r.t.Helper()
+	var want, got any
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		r.t.Fatalf("期望值不是合法的 JSON: %v", err)
+	}
+	if err := json.Unmarshal(r.Recorder.Body.Bytes(), &got); err != nil {
+		r.t.Fatalf("解码 JSON 响应体失败: %v，响应体: %s", err, r.Recorder.Body.String())
+	}
+	if !reflect.DeepEqual(want, got) {
+		r.t.Errorf("JSON 响应体错误，期望 %s，实际 %s", expected, r.Recorder.Body.String())
+	}
+	return r
+}
+
+// Cookie 返回响应设置的 Cookie，不存在时测试失败
+func (r *Response) Cookie(name string) *http.Cookie {
+	r.t.Helper()
+	for _, c := range r.Recorder.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	r.t.Fatalf("响应没有设置 Cookie %s", name)
+	return nil
+}
+
+// Golden 断言响应体与黄金文件 testdata/<name>.golden 相同
+// 设置 UpdateGoldenEnv 环境变量时改为写入黄金文件
+func (r *Response) Golden(name string) *Response {
+	r.t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	got := r.Recorder.Body.Bytes()
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.t.Fatalf("创建黄金文件目录失败: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			r.t.Fatalf("写入黄金文件失败: %v", err)
+		}
+		return r
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		r.t.Fatalf("读取黄金文件失败: %v，可以设置 %s=1 生成", err, UpdateGoldenEnv)
+	}
+	if !bytes.Equal(want, got) {
+		r.t.Errorf("响应体与黄金文件 %s 不一致\n期望: %s\n实际: %s", path, want, got)
+	}
+	return r
+}
@@ -0,0 +1,160 @@
+package ant
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// AssetEntry 静态资源清单中的一项
+type AssetEntry struct {
+	// URL 带有内容指纹的地址，例如 "/assets/css/app.3f2a1b9c.css"
+	URL string `json:"url"`
+	// Size 文件大小（字节）
+	Size int64 `json:"size"`
+	// Integrity 子资源完整性校验值，可以直接用于 <script integrity="...">
+	Integrity string `json:"integrity"`
+	// GzipSize 预压缩的 .gz 文件大小，没有预压缩文件时省略
+	GzipSize int64 `json:"gzip_size,omitempty"`
+}
+
+// AssetManifest 静态资源清单，键为资源的逻辑名称，例如 "css/app.css"
+type AssetManifest map[string]AssetEntry
+
+// immutableCacheControl 带有内容指纹的地址内容不会变化，可以永久缓存
+var immutableCacheControl = CacheControl{Public: true, MaxAge: 365 * 24 * time.Hour, Immutable: true}
+
+// WithFingerprint 创建启用内容指纹的配置选项
+// 启用后：
+// 1. 通过 Manifest 或 AssetURL 获取带有内容指纹的地址，例如 "css/app.css" 对应 "css/app.3f2a1b9c.css"
+// 2. 请求带有指纹的地址时返回对应的文件，并设置 immutable 的 Cache-Control
+// 3. 存在预压缩的 .gz 文件且客户端接受 gzip 时，直接返回预压缩文件
+// 4. manifestName 不为空时，请求 prefix/manifestName 返回 JSON 格式的资源清单，供服务端渲染或 CDN 工具使用
+// 注意：清单在第一次使用时生成并缓存，资源变化后需要调用 ResetManifest
+func WithFingerprint(manifestName string) StaticResourceHandlerOption {
+	return func(h *StaticResourceHandler) {
+		h.fingerprint = true
+		h.manifestName = strings.TrimPrefix(manifestName, "/")
+	}
+}
+
+// Manifest 返回静态资源清单，只包含扩展名已知的文件
+// 返回的清单是缓存的数据，调用方不应修改
+func (h *StaticResourceHandler) Manifest() (AssetManifest, error) {
+	h.manifestMu.Lock()
+	defer h.manifestMu.Unlock()
+	if h.manifest != nil {
+		return h.manifest, nil
+	}
+
+	fsys := h.fsys
+	if fsys == nil {
+		fsys = os.DirFS(h.dir)
+	}
+	manifest := make(AssetManifest)
+	hashed := make(map[string]string)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if _, ok := h.extensionContentTypeMap[getFileExt(name)]; !ok || strings.HasSuffix(name, ".gz") {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		integrity := sha512.Sum384(data)
+		fingerprinted := fingerprintName(name, hex.EncodeToString(sum[:4]))
+		entry := AssetEntry{
+			URL:       h.pathPrefix + "/" + fingerprinted,
+			Size:      int64(len(data)),
+			Integrity: "sha384-" + base64.StdEncoding.EncodeToString(integrity[:]),
+		}
+		if info, err := fs.Stat(fsys, name+".gz"); err == nil {
+			entry.GzipSize = info.Size()
+		}
+		manifest[name] = entry
+		hashed[fingerprinted] = name
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	h.manifest, h.hashed = manifest, hashed
+	return manifest, nil
+}
+
+// ResetManifest 丢弃缓存的资源清单，下一次使用时重新生成
+func (h *StaticResourceHandler) ResetManifest() {
+	h.manifestMu.Lock()
+	defer h.manifestMu.Unlock()
+	h.manifest, h.hashed = nil, nil
+}
+
+// AssetURL 返回资源带有内容指纹的地址，可以注册为模板函数
+// 资源不在清单中或生成清单失败时返回不带指纹的地址
+func (h *StaticResourceHandler) AssetURL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if manifest, err := h.Manifest(); err == nil {
+		if entry, ok := manifest[name]; ok {
+			return entry.URL
+		}
+	}
+	return h.pathPrefix + "/" + name
+}
+
+// resolveAsset 将请求的文件名解析为实际读取的文件
+// 返回值: 逻辑名称，是否为带有指纹的地址，以及清单中的记录
+func (h *StaticResourceHandler) resolveAsset(req string) (string, bool, AssetEntry) {
+	manifest, err := h.Manifest()
+	if err != nil {
+		return req, false, AssetEntry{}
+	}
+	h.manifestMu.Lock()
+	logical, ok := h.hashed[req]
+	h.manifestMu.Unlock()
+	if ok {
+		return logical, true, manifest[logical]
+	}
+	return req, false, manifest[req]
+}
+
+// serveManifest 以 JSON 响应资源清单
+func (h *StaticResourceHandler) serveManifest(ctx *Context) {
+	manifest, err := h.Manifest()
+	if err != nil {
+		ctx.RespStatusCode = http.StatusInternalServerError
+		ctx.RespData = []byte("生成资源清单失败")
+		return
+	}
+	ctx.NoStore()
+	_ = ctx.RespJSONOK(manifest)
+}
+
+// fingerprintName 在扩展名之前插入内容指纹，例如 "css/app.css" 变为 "css/app.3f2a1b9c.css"
+func fingerprintName(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// acceptsGzip 返回客户端是否接受 gzip 编码
+func acceptsGzip(req *http.Request) bool {
+	for _, v := range req.Header.Values("Accept-Encoding") {
+		for _, enc := range strings.Split(v, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+			if strings.EqualFold(strings.TrimSpace(name), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package ant
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func gzipData(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, _ = w.Write([]byte(data))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestStaticFingerprint 测试资源清单、带指纹的地址与预压缩文件
+func TestStaticFingerprint(t *testing.T) {
+	css := "body{color:red}"
+	fsys := fstest.MapFS{
+		"css/app.css":    {Data: []byte(css)},
+		"css/app.css.gz": {Data: gzipData(t, css)},
+		"js/app.js":      {Data: []byte("console.log(1)")},
+		"notes.unknown":  {Data: []byte("x")},
+	}
+	h := NewStaticFSHandler(fsys, "/assets", WithFingerprint("manifest.json"))
+	s := NewHTTPServer()
+	s.Handle("GET /assets/{file...}", h.Handle)
+
+	manifest, err := h.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest) != 2 {
+		t.Fatalf("清单应只包含已知扩展名的文件，实际为 %v", manifest)
+	}
+	entry := manifest["css/app.css"]
+	if !strings.HasPrefix(entry.URL, "/assets/css/app.") || !strings.HasSuffix(entry.URL, ".css") || len(entry.URL) != len("/assets/css/app.12345678.css") {
+		t.Errorf("带指纹的地址格式错误: %s", entry.URL)
+	}
+	if entry.Size != int64(len(css)) || !strings.HasPrefix(entry.Integrity, "sha384-") || entry.GzipSize == 0 {
+		t.Errorf("清单项错误: %+v", entry)
+	}
+	if h.AssetURL("/css/app.css") != entry.URL || h.AssetURL("missing.css") != "/assets/missing.css" {
+		t.Errorf("AssetURL 错误: %s %s", h.AssetURL("css/app.css"), h.AssetURL("missing.css"))
+	}
+
+	get := func(path string, gz bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if gz {
+			req.Header.Set("Accept-Encoding", "br, gzip")
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		return w
+	}
+
+	// 带指纹的地址可以永久缓存，接受 gzip 时返回预压缩文件
+	w := get(entry.URL, true)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Content-Type") != "text/css; charset=utf-8" {
+		t.Errorf("应返回预压缩的 CSS，实际为 %d %v", w.Code, w.Header())
+	}
+	if !strings.Contains(w.Header().Get("Cache-Control"), "immutable") || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("带指纹的地址应设置 immutable 与 Vary，实际为 %v", w.Header())
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plain bytes.Buffer
+	_, _ = plain.ReadFrom(zr)
+	if plain.String() != css {
+		t.Errorf("解压后的内容错误: %q", plain.String())
+	}
+
+	// 不接受 gzip 时返回原文件；不带指纹的地址使用默认的缓存策略
+	w = get("/assets/css/app.css", false)
+	if w.Body.String() != css || w.Header().Get("Content-Encoding") != "" || strings.Contains(w.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("应返回未压缩的原文件，实际为 %q %v", w.Body.String(), w.Header())
+	}
+
+	// 资源清单
+	w = get("/assets/manifest.json", false)
+	var got AssetManifest
+	if err = json.Unmarshal(w.Body.Bytes(), &got); err != nil || got["js/app.js"].URL != manifest["js/app.js"].URL {
+		t.Errorf("资源清单响应错误: %s %v", w.Body.String(), err)
+	}
+
+	// 资源变化后重新生成清单
+	fsys["js/app.js"] = &fstest.MapFile{Data: []byte("console.log(2)")}
+	h.ResetManifest()
+	if h.AssetURL("js/app.js") == manifest["js/app.js"].URL {
+		t.Error("资源变化后指纹应改变")
+	}
+}
+
+// TestAcceptsGzip 测试 Accept-Encoding 的解析
+func TestAcceptsGzip(t *testing.T) {
+	for header, want := range map[string]bool{
+		"gzip":              true,
+		"br, GZIP;q=0.5":    true,
+		"gzip;q=0":          false,
+		"deflate, identity": false,
+		"":                  false,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", header)
+		if got := acceptsGzip(req); got != want {
+			t.Errorf("%q: 期望 %v，实际为 %v", header, want, got)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package ant
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrInvalidPolicy 授权策略中包含空的角色或权限范围
+var ErrInvalidPolicy = errors.New("web: 授权策略不合法")
+
+// AuthorizationPolicy 访问路由需要的角色与权限范围
+type AuthorizationPolicy struct {
+	// Roles 允许访问的角色，拥有其中任意一个即可，为空时不检查角色
+	Roles []string `json:"roles,omitempty"`
+	// Scopes 需要的权限范围，必须全部拥有，为空时不检查权限范围
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// Validate 校验策略，角色与权限范围都不能为空字符串
+func (p AuthorizationPolicy) Validate() error {
+	if slices.Contains(p.Roles, "") || slices.Contains(p.Scopes, "") {
+		return ErrInvalidPolicy
+	}
+	return nil
+}
+
+// Allows 判断拥有 roles 与 scopes 的用户是否满足策略
+func (p AuthorizationPolicy) Allows(roles, scopes []string) bool {
+	if len(p.Roles) > 0 && !slices.ContainsFunc(p.Roles, func(r string) bool { return slices.Contains(roles, r) }) {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if !slices.Contains(scopes, s) {
+			return false
+		}
+	}
+	return true
+}
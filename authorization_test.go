@@ -0,0 +1,27 @@
+package ant
+
+import "testing"
+
+func TestAuthorizationPolicy(t *testing.T) {
+	p := AuthorizationPolicy{Roles: []string{"admin", "editor"}, Scopes: []string{"posts:write"}}
+	tests := []struct {
+		name          string
+		roles, scopes []string
+		want          bool
+	}{
+		{name: "任意一个角色", roles: []string{"editor"}, scopes: []string{"posts:write", "posts:read"}, want: true},
+		{name: "缺少角色", roles: []string{"viewer"}, scopes: []string{"posts:write"}},
+		{name: "缺少权限范围", roles: []string{"admin"}, scopes: []string{"posts:read"}},
+	}
+	for _, tt := range tests {
+		if got := p.Allows(tt.roles, tt.scopes); got != tt.want {
+			t.Errorf("%s: 期望 %v，实际 %v", tt.name, tt.want, got)
+		}
+	}
+	if !(AuthorizationPolicy{}).Allows(nil, nil) {
+		t.Error("空策略应允许任何用户")
+	}
+	if err := (AuthorizationPolicy{Roles: []string{""}}).Validate(); err == nil {
+		t.Error("空的角色应校验失败")
+	}
+}
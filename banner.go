@@ -0,0 +1,140 @@
+package ant
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// StartupReport 服务器启动时输出的报告
+// 通过 ServerWithStartupReport 启用，未启用时只输出一行监听地址
+type StartupReport struct {
+	// Output 报告的输出位置，为nil时使用标准库 log 的输出
+	Output io.Writer
+	// Logo 报告开头的 Logo，为空时不输出
+	Logo string
+	// Routes 是否输出路由表
+	Routes bool
+	// Middlewares 是否输出全局中间件链，按调用顺序排列
+	Middlewares bool
+	// Features 是否输出功能开关的状态
+	Features bool
+}
+
+// ServerWithStartupReport 设置服务器启动时输出的报告
+// 例如：
+//
+//	ant.ServerWithStartupReport(ant.StartupReport{Logo: logo, Routes: true, Middlewares: true})
+func ServerWithStartupReport(report StartupReport) ServerOption {
+	return func(server *HTTPServer) {
+		server.startupReport = &report
+	}
+}
+
+// PrintRoutes 按注册顺序输出路由表，包含方法、路径与通过 Describe 设置的说明，弃用的路由在说明后加上 DeprecationNote
+// 适合在脚本中检查路由，例如 `app routes | grep users`
+func (s *HTTPServer) PrintRoutes(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "METHOD\tPATH\tSUMMARY"); err != nil {
+		return err
+	}
+	for _, pattern := range s.Routes() {
+		method, path, ok := strings.Cut(pattern, " ")
+		if !ok {
+			method, path = "ANY", pattern
+		}
+		meta, _ := s.RouteMeta(pattern)
+		summary := meta.Summary
+		if note := meta.DeprecationNote(); note != "" {
+			summary = strings.TrimSpace(summary + " " + note)
+		}
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", method, strings.TrimSpace(path), summary); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// reportStartup 输出启动报告
+// addrs: 已绑定的地址
+func (s *HTTPServer) reportStartup(addrs ...string) {
+	r := s.startupReport
+	if r == nil {
+		for _, addr := range addrs {
+			fmt.Printf("Server is running on %s\n", addr)
+		}
+		return
+	}
+	w := r.Output
+	if w == nil {
+		w = log.Writer()
+	}
+
+	var sb strings.Builder
+	if r.Logo != "" {
+		sb.WriteString(strings.TrimRight(r.Logo, "\n"))
+		sb.WriteString("\n\n")
+	}
+	for _, addr := range addrs {
+		fmt.Fprintf(&sb, "Listening on %s\n", addr)
+	}
+	if r.Routes {
+		fmt.Fprintf(&sb, "\nRoutes (%d):\n", len(s.Routes()))
+		_ = s.PrintRoutes(&sb)
+	}
+	if r.Middlewares {
+		sb.WriteString("\nMiddlewares:\n")
+		s.mwMu.Lock()
+		mws := slices.Clone(s.middlewares)
+		s.mwMu.Unlock()
+		if len(mws) == 0 {
+			sb.WriteString("  (none)\n")
+		}
+		for i, mw := range mws {
+			fmt.Fprintf(&sb, "  %d. %s\n", i+1, middlewareName(mw))
+		}
+	}
+	if r.Features && s.features != nil {
+		sb.WriteString("\nFeatures:\n")
+		all := s.features.All()
+		names := make([]string, 0, len(all))
+		for name := range all {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			state := "off"
+			if all[name] {
+				state = "on"
+			}
+			fmt.Fprintf(&sb, "  %-3s %s\n", state, name)
+		}
+	}
+	_, _ = io.WriteString(w, sb.String())
+}
+
+// middlewareName 返回中间件的函数名，去掉模块路径与闭包后缀
+// 例如 "ratelimit.(*MiddlewareBuilder).Build"
+func middlewareName(mw Middleware) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(mw).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	for {
+		i := strings.LastIndex(name, ".func")
+		if i < 0 || strings.Trim(name[i+len(".func"):], "0123456789.") != "" {
+			break
+		}
+		name = name[:i]
+	}
+	return name
+}
@@ -0,0 +1,58 @@
+package ant
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPrintRoutes(t *testing.T) {
+	s := NewHTTPServer()
+	s.Handle("GET /users", func(ctx *Context) {})
+	s.Handle("/static/", func(ctx *Context) {})
+	s.Describe("GET /users", RouteMeta{Summary: "用户列表"})
+
+	var sb strings.Builder
+	if err := s.PrintRoutes(&sb); err != nil {
+		t.Fatal(err)
+	}
+	want := "METHOD  PATH      SUMMARY\n" +
+		"GET     /users    用户列表\n" +
+		"ANY     /static/  \n"
+	if sb.String() != want {
+		t.Errorf("路由表应为:\n%s实际为:\n%s", want, sb.String())
+	}
+}
+
+func loggingMiddleware(next HandleFunc) HandleFunc {
+	return next
+}
+
+func TestStartupReport(t *testing.T) {
+	var sb strings.Builder
+	flags, err := NewFeatureFlags(context.Background(), StaticFeatures{"beta": true, "legacy": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewHTTPServer(
+		ServerWithFeatureFlags(flags),
+		ServerWithStartupReport(StartupReport{
+			Output: &sb, Logo: "ANT\n", Routes: true, Middlewares: true, Features: true,
+		}),
+	)
+	s.Use(loggingMiddleware, RequireFeature("beta"))
+	s.Handle("GET /ping", func(ctx *Context) {})
+	s.reportStartup("127.0.0.1:8080", "[::1]:8080")
+
+	got := sb.String()
+	for _, want := range []string{
+		"ANT\n\nListening on 127.0.0.1:8080\nListening on [::1]:8080\n",
+		"Routes (1):\nMETHOD  PATH   SUMMARY\nGET     /ping",
+		"Middlewares:\n  1. ant.loggingMiddleware\n  2. ant.RequireFeature\n",
+		"Features:\n  on  beta\n  off legacy\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("启动报告应包含 %q，实际为:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package ant
+
+import (
+	"encoding"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BindQuery 将 URL 查询参数绑定到结构体中带有 query 标签的字段
+// val: 需要绑定的目标结构体指针
+// 支持的字段类型与标签见 BindForm，查询参数不包含文件
+//
+//	type listReq struct {
+//		Page  int       `query:"page" default:"1"`
+//		Tags  []string  `query:"tag"`
+//		Since time.Time `query:"since" layout:"2006-01-02"`
+//	}
+func (c *Context) BindQuery(val any) error {
+	if c.cacheQueryValues == nil {
+		c.cacheQueryValues = c.Req.URL.Query()
+	}
+	return bindValues(val, "query", c.cacheQueryValues, nil)
+}
+
+// BindForm 将请求体中的表单绑定到结构体中带有 form 标签的字段
+// val: 需要绑定的目标结构体指针
+// 支持 application/x-www-form-urlencoded 与 multipart/form-data，multipart 请求体按默认的 MultipartOptions 解析
+// 1. 字段可以是字符串、布尔值、整数、浮点数、time.Duration、time.Time、实现了 encoding.TextUnmarshaler 的类型，
+// 以及它们的指针与切片，切片按同名的多个值绑定
+// 2. time.Time 默认按 RFC3339 解析，可以通过 layout 标签指定格式，例如 `layout:"2006-01-02"`
+// 3. 嵌套结构体的字段以 "标签." 为前缀，例如 `form:"address"` 中的 `form:"city"` 对应 address.city，匿名嵌入的结构体字段直接展开
+// 4. 表单中没有该字段时使用 default 标签的值，切片的默认值以逗号分隔，没有 default 标签时保留原值
+// 5. multipart 表单中的文件可以绑定到 *UploadedFile 或 []*UploadedFile 字段
+// 值无法转换为字段类型时返回包装了 ErrValidation 的错误；字段类型不支持时 panic
+func (c *Context) BindForm(val any) error {
+	mediaType, _, _ := mime.ParseMediaType(c.Req.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		form, err := c.MultipartForm(MultipartOptions{})
+		if err != nil {
+			return err
+		}
+		return bindValues(val, "form", form.Value, form.File)
+	}
+	if err := c.Req.ParseForm(); err != nil {
+		return err
+	}
+	return bindValues(val, "form", c.Req.PostForm, nil)
+}
+
+// BindXML 解析请求体中的XML数据并绑定到 val，字段通过 xml 标签映射
+func (c *Context) BindXML(val any) error {
+	if c.Req.Body == nil {
+		return errors.New("web: body 为 nil")
+	}
+	return xml.NewDecoder(c.Req.Body).Decode(val)
+}
+
+// formField 带有 form 或 query 标签的结构体字段
+type formField struct {
+	index []int
+	key   string
+	// def 没有该字段时使用的默认值
+	def    string
+	hasDef bool
+	// layout time.Time 字段的格式
+	layout string
+	// nested 是否为需要按前缀继续绑定的嵌套结构体
+	nested bool
+}
+
+// formCacheKey 字段缓存的键，同一个类型的 form 与 query 字段分别缓存
+type formCacheKey struct {
+	t   reflect.Type
+	tag string
+}
+
+// formFieldCache 按类型与标签缓存的字段
+var formFieldCache sync.Map
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	uploadedFileType    = reflect.TypeFor[*UploadedFile]()
+	uploadedFilesType   = reflect.TypeFor[[]*UploadedFile]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// formFields 返回结构体中带有 tag 标签的字段，字段类型不支持时 panic
+func formFields(t reflect.Type, tag string) []formField {
+	key := formCacheKey{t: t, tag: tag}
+	if cached, ok := formFieldCache.Load(key); ok {
+		return cached.([]formField)
+	}
+	var fields []formField
+	for _, f := range reflect.VisibleFields(t) {
+		name := f.Tag.Get(tag)
+		if name == "" || name == "-" || !f.IsExported() {
+			continue
+		}
+		field := formField{index: f.Index, key: name, layout: f.Tag.Get("layout")}
+		field.def, field.hasDef = f.Tag.Lookup("default")
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer && ft != uploadedFileType {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Struct && ft != timeType && !reflect.PointerTo(ft).Implements(textUnmarshalerType):
+			field.nested = true
+		case ft == uploadedFileType || ft == uploadedFilesType:
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() != reflect.Uint8 && scalarType(ft.Elem()):
+		case !scalarType(ft):
+			panic(fmt.Sprintf("web: 字段 %s.%s 的类型 %s 不能绑定 %s 参数", t, f.Name, f.Type, tag))
+		}
+		fields = append(fields, field)
+	}
+	formFieldCache.Store(key, fields)
+	return fields
+}
+
+// scalarType 判断类型是否可以由一个字符串转换得到
+func scalarType(t reflect.Type) bool {
+	if t == timeType || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// bindValues 将 values 与 files 绑定到 val 中带有 tag 标签的字段
+func bindValues(val any, tag string, values url.Values, files map[string][]*UploadedFile) error {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("web: 绑定的目标必须是结构体指针，实际为 %T", val)
+	}
+	return bindStruct(rv.Elem(), tag, "", values, files)
+}
+
+// bindStruct 按前缀 prefix 绑定结构体的字段
+func bindStruct(rv reflect.Value, tag, prefix string, values url.Values, files map[string][]*UploadedFile) error {
+	for _, f := range formFields(rv.Type(), tag) {
+		// 经过为nil的匿名嵌入指针时跳过
+		field, err := rv.FieldByIndexErr(f.index)
+		if err != nil {
+			continue
+		}
+		key := prefix + f.key
+		if f.nested {
+			if field.Kind() == reflect.Pointer {
+				if !hasPrefix(values, files, key+".") {
+					continue
+				}
+				if field.IsNil() {
+					field.Set(reflect.New(field.Type().Elem()))
+				}
+				field = field.Elem()
+			}
+			if err = bindStruct(field, tag, key+".", values, files); err != nil {
+				return err
+			}
+			continue
+		}
+		switch field.Type() {
+		case uploadedFileType:
+			if len(files[key]) > 0 {
+				field.Set(reflect.ValueOf(files[key][0]))
+			}
+			continue
+		case uploadedFilesType:
+			if len(files[key]) > 0 {
+				field.Set(reflect.ValueOf(files[key]))
+			}
+			continue
+		}
+		vals := values[key]
+		if len(vals) == 0 {
+			if !f.hasDef {
+				continue
+			}
+			vals = []string{f.def}
+			if field.Kind() == reflect.Slice {
+				vals = strings.Split(f.def, ",")
+			}
+		}
+		if err = setField(field, vals, f.layout); err != nil {
+			return fmt.Errorf("%w: %s %q: %w", ErrValidation, key, vals[0], err)
+		}
+	}
+	return nil
+}
+
+// hasPrefix 判断是否有以 prefix 开头的值或文件
+func hasPrefix(values url.Values, files map[string][]*UploadedFile, prefix string) bool {
+	for k := range values {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	for k := range files {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// setField 将 vals 转换为字段的类型，切片使用全部的值，其他类型使用第一个值
+func setField(field reflect.Value, vals []string, layout string) error {
+	switch {
+	case field.Kind() == reflect.Pointer:
+		v := reflect.New(field.Type().Elem())
+		if err := setField(v.Elem(), vals, layout); err != nil {
+			return err
+		}
+		field.Set(v)
+		return nil
+	case field.Kind() == reflect.Slice:
+		s := reflect.MakeSlice(field.Type(), len(vals), len(vals))
+		for i, v := range vals {
+			if err := setScalar(s.Index(i), v, layout); err != nil {
+				return err
+			}
+		}
+		field.Set(s)
+		return nil
+	}
+	return setScalar(field, vals[0], layout)
+}
+
+// setScalar 将字符串转换为字段的类型
+func setScalar(field reflect.Value, s, layout string) error {
+	switch field.Type() {
+	case timeType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Bool:
+		// HTML 复选框选中时提交 "on"
+		if s == "on" {
+			s = "true"
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package ant
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+type address struct {
+	City string `form:"city" query:"city"`
+	Zip  *int   `form:"zip" query:"zip"`
+}
+
+type paging struct {
+	Page int `query:"page" default:"1"`
+	Size int `query:"size" default:"20"`
+}
+
+type searchReq struct {
+	paging
+	Keyword  string        `query:"q"`
+	Tags     []string      `query:"tag" default:"a,b"`
+	IDs      []uint        `query:"id"`
+	Since    time.Time     `query:"since" layout:"2006-01-02"`
+	Timeout  time.Duration `query:"timeout"`
+	Score    *float64      `query:"score"`
+	Exact    bool          `query:"exact"`
+	IP       netip.Addr    `query:"ip"`
+	Home     address       `query:"home"`
+	Work     *address      `query:"work"`
+	Internal string
+	Ignored  string `query:"-"`
+}
+
+func TestBindQuery(t *testing.T) {
+	ctx := &Context{Req: httptest.NewRequest(http.MethodGet,
+		"/search?q=go&size=50&id=1&id=2&since=2024-05-01&timeout=1.5s&score=4.5&exact=on&ip=10.0.0.1&home.city=上海&home.zip=200000&Internal=x&-=y", nil)}
+	var req searchReq
+	if err := ctx.BindQuery(&req); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case req.Keyword != "go", req.Page != 1, req.Size != 50:
+		t.Errorf("基本字段或默认值不正确: %+v", req)
+	case len(req.Tags) != 2 || req.Tags[1] != "b", len(req.IDs) != 2 || req.IDs[1] != 2:
+		t.Errorf("切片不正确: %v %v", req.Tags, req.IDs)
+	case !req.Since.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)), req.Timeout != 1500*time.Millisecond:
+		t.Errorf("时间不正确: %v %v", req.Since, req.Timeout)
+	case req.Score == nil || *req.Score != 4.5, !req.Exact, req.IP.String() != "10.0.0.1":
+		t.Errorf("指针、布尔值或 TextUnmarshaler 不正确: %+v", req)
+	case req.Home.City != "上海" || req.Home.Zip == nil || *req.Home.Zip != 200000:
+		t.Errorf("嵌套结构体不正确: %+v", req.Home)
+	case req.Work != nil:
+		t.Errorf("没有对应参数的嵌套指针应保持为nil: %+v", req.Work)
+	case req.Internal != "" || req.Ignored != "":
+		t.Errorf("没有标签的字段不应被绑定: %+v", req)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "整数不合法", query: "page=x"},
+		{name: "整数溢出", query: "id=-1"},
+		{name: "时间格式不正确", query: "since=2024/05/01"},
+		{name: "嵌套字段不合法", query: "work.zip=abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &Context{Req: httptest.NewRequest(http.MethodGet, "/search?"+tt.query, nil)}
+			var req searchReq
+			if err := ctx.BindQuery(&req); !errors.Is(err, ErrValidation) {
+				t.Errorf("应返回 ErrValidation，实际为 %v", err)
+			}
+		})
+	}
+
+	if err := ctx.BindQuery(req); err == nil {
+		t.Error("目标不是结构体指针时应返回错误")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("不支持的字段类型应 panic")
+			}
+		}()
+		var bad struct {
+			M map[string]string `query:"m"`
+		}
+		_ = ctx.BindQuery(&bad)
+	}()
+}
+
+type profileForm struct {
+	Name    string          `form:"name" xml:"name"`
+	Age     int             `form:"age" xml:"age" default:"18"`
+	Langs   []string        `form:"lang" xml:"lang"`
+	Address address         `form:"address" xml:"address"`
+	Avatar  *UploadedFile   `form:"avatar" xml:"-"`
+	Photos  []*UploadedFile `form:"photo" xml:"-"`
+}
+
+// TestBind 测试 Bind 按 Content-Type 选择 XML、表单与 multipart 绑定
+func TestBind(t *testing.T) {
+	var got profileForm
+	server := NewHTTPServer()
+	server.Handle("POST /profile", func(ctx *Context) {
+		got = profileForm{}
+		if err := ctx.Bind(&got); err != nil {
+			ctx.RespError(err)
+		}
+	})
+
+	req := multipartRequest(t, map[string]string{"a.png": "png"})
+	req.URL.Path = "/profile"
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || got.Age != 18 || got.Photos != nil || got.Avatar != nil {
+		t.Fatalf("multipart 表单绑定不正确: %d %s %+v", w.Code, w.Body.String(), got)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantCode    int
+		want        profileForm
+	}{
+		{
+			name:        "urlencoded 表单",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=tom&age=30&lang=go&lang=rust&address.city=北京",
+			wantCode:    http.StatusOK,
+			want:        profileForm{Name: "tom", Age: 30, Langs: []string{"go", "rust"}, Address: address{City: "北京"}},
+		},
+		{
+			name:        "XML",
+			contentType: "application/xml; charset=utf-8",
+			body:        "<profile><name>tom</name><age>30</age><lang>go</lang><address><City>北京</City></address></profile>",
+			wantCode:    http.StatusOK,
+			want:        profileForm{Name: "tom", Age: 30, Langs: []string{"go"}, Address: address{City: "北京"}},
+		},
+		{
+			name:        "XML后缀",
+			contentType: "application/atom+xml",
+			body:        "<profile><name>tom</name></profile>",
+			wantCode:    http.StatusOK,
+			want:        profileForm{Name: "tom"},
+		},
+		{
+			name:        "表单值不合法",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "age=old",
+			wantCode:    http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, req)
+			if w.Code != tt.wantCode {
+				t.Fatalf("期望状态码 %d, 得到 %d %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantCode == http.StatusOK && (got.Name != tt.want.Name || got.Age != tt.want.Age ||
+				strings.Join(got.Langs, ",") != strings.Join(tt.want.Langs, ",") || got.Address.City != tt.want.Address.City) {
+				t.Errorf("期望 %+v, 得到 %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestBindFormFiles 测试 multipart 表单中的文件绑定
+func TestBindFormFiles(t *testing.T) {
+	req := multipartRequest(t, nil)
+	ctx := &Context{Req: req}
+	var form struct {
+		Title string          `form:"title"`
+		Files []*UploadedFile `form:"file"`
+	}
+	if err := ctx.BindForm(&form); err != nil || form.Title != "报告" || form.Files != nil {
+		t.Fatalf("没有文件时绑定不正确: %+v %v", form, err)
+	}
+
+	ctx = &Context{Req: multipartRequest(t, map[string]string{"a.txt": "a", "b.txt": "bb"})}
+	if err := ctx.BindForm(&form); err != nil || len(form.Files) != 2 {
+		t.Fatalf("文件绑定不正确: %+v %v", form, err)
+	}
+	defer ctx.multipartForm.RemoveAll()
+	var size int64
+	for _, f := range form.Files {
+		size += f.Size
+	}
+	if size != 3 {
+		t.Errorf("文件大小不正确: %d", size)
+	}
+}
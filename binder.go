@@ -0,0 +1,71 @@
+package ant
+
+import (
+	"errors"
+	"mime"
+	"strings"
+)
+
+// ErrUnsupportedMediaType 请求的 Content-Type 没有对应的绑定器
+var ErrUnsupportedMediaType = errors.New("web: 不支持的 Content-Type")
+
+// BinderFunc 将请求体解析并绑定到 val，val 为目标的指针
+type BinderFunc func(ctx *Context, val any) error
+
+// RegisterBinder 注册 Content-Type 对应的绑定器，用于 ctx.Bind 与 JSONHandler
+// contentType 为媒体类型，例如 "text/csv"、"application/x-ndjson"，参数部分会被忽略
+// 注册 "application/json" 可以替换默认的JSON绑定
+// 注意：应在服务器启动之前注册
+//
+// 例如：
+//
+//	server.RegisterBinder("application/x-protobuf", func(ctx *ant.Context, val any) error {
+//		body, err := io.ReadAll(ctx.Req.Body)
+//		if err != nil {
+//			return err
+//		}
+//		return proto.Unmarshal(body, val.(proto.Message))
+//	})
+func (s *HTTPServer) RegisterBinder(contentType string, fn BinderFunc) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		panic("web: 不合法的 Content-Type " + contentType)
+	}
+	if s.binders == nil {
+		s.binders = make(map[string]BinderFunc)
+	}
+	s.binders[mediaType] = fn
+}
+
+// Bind 根据请求的 Content-Type 解析请求体并绑定到 val
+// 优先使用通过 RegisterBinder 注册的绑定器，
+// 未注册时 Content-Type 为空、application/json 或以 +json 结尾的请求使用 BindJSON，
+// application/xml、text/xml 或以 +xml 结尾的请求使用 BindXML，
+// application/x-www-form-urlencoded 与 multipart/form-data 请求使用 BindForm，
+// 其他类型返回 ErrUnsupportedMediaType
+// 查询参数不在请求体中，需要时另外调用 BindQuery
+func (c *Context) Bind(val any) error {
+	header := c.Req.Header.Get("Content-Type")
+	if header == "" {
+		if fn, ok := c.binders["application/json"]; ok {
+			return fn(c, val)
+		}
+		return c.BindJSON(val)
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return ErrUnsupportedMediaType
+	}
+	if fn, ok := c.binders[mediaType]; ok {
+		return fn(c, val)
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return c.BindJSON(val)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return c.BindXML(val)
+	case mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data":
+		return c.BindForm(val)
+	}
+	return ErrUnsupportedMediaType
+}
@@ -0,0 +1,67 @@
+package ant
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegisterBinder(t *testing.T) {
+	server := NewHTTPServer()
+	server.RegisterBinder("text/csv; charset=utf-8", func(ctx *Context, val any) error {
+		records, err := csv.NewReader(ctx.Req.Body).ReadAll()
+		if err != nil {
+			return err
+		}
+		rows, ok := val.(*[][]string)
+		if !ok {
+			return errors.New("只支持绑定到 [][]string")
+		}
+		*rows = records
+		return nil
+	})
+	server.Handle("POST /rows", JSONHandler(func(ctx *Context, rows [][]string) (int, error) {
+		return len(rows), nil
+	}))
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantCode    int
+		wantBody    string
+	}{
+		{name: "注册的绑定器", contentType: "text/csv", body: "a,b\nc,d\n", wantCode: http.StatusOK, wantBody: "2"},
+		{name: "默认JSON", contentType: "application/json", body: `[["a"]]`, wantCode: http.StatusOK, wantBody: "1"},
+		{name: "JSON后缀", contentType: "application/vnd.api+json", body: `[]`, wantCode: http.StatusOK, wantBody: "0"},
+		{name: "未设置Content-Type", body: `[["a"],["b"]]`, wantCode: http.StatusOK, wantBody: "2"},
+		{name: "不支持的类型", contentType: "application/octet-stream", body: "<a/>", wantCode: http.StatusUnsupportedMediaType, wantBody: `{"error":"不支持的 Content-Type"}`},
+		{name: "绑定失败", contentType: "text/csv", body: "a,b\nc\n", wantCode: http.StatusBadRequest, wantBody: `{"error":"请求体解析失败"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/rows", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, req)
+			if w.Code != tt.wantCode {
+				t.Errorf("期望状态码 %d，实际 %d", tt.wantCode, w.Code)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tt.wantBody {
+				t.Errorf("期望响应体 %s，实际 %s", tt.wantBody, body)
+			}
+		})
+	}
+
+	ctx := &Context{Req: httptest.NewRequest(http.MethodPost, "/", strings.NewReader("x"))}
+	ctx.Req.Header.Set("Content-Type", "text/csv")
+	var rows [][]string
+	if err := ctx.Bind(&rows); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("没有注册绑定器时应返回 ErrUnsupportedMediaType，实际为 %v", err)
+	}
+}
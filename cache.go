@@ -0,0 +1,110 @@
+package ant
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheControl Cache-Control 响应头的指令
+// 时长类字段为0时不输出对应指令，需要每次验证时使用 NoCache
+type CacheControl struct {
+	// Public 响应可以被共享缓存（CDN、代理）缓存
+	Public bool
+	// Private 响应只能被浏览器缓存
+	Private bool
+	// NoCache 使用缓存前必须向服务器验证
+	NoCache bool
+	// NoStore 不允许缓存
+	NoStore bool
+	// NoTransform 中间代理不能转换响应内容
+	NoTransform bool
+	// MustRevalidate 缓存过期后必须向服务器验证
+	MustRevalidate bool
+	// Immutable 缓存有效期内内容不会变化，浏览器刷新时也不需要验证，适用于带指纹的静态资源
+	Immutable bool
+	// MaxAge 缓存的有效期
+	MaxAge time.Duration
+	// SMaxAge 共享缓存的有效期，覆盖 MaxAge
+	SMaxAge time.Duration
+	// StaleWhileRevalidate 过期后可以继续使用并在后台验证的时长
+	StaleWhileRevalidate time.Duration
+	// StaleIfError 服务器出错时可以继续使用过期缓存的时长
+	StaleIfError time.Duration
+}
+
+// String 返回 Cache-Control 响应头的值，没有任何指令时返回空字符串
+func (c CacheControl) String() string {
+	var directives []string
+	flag := func(ok bool, name string) {
+		if ok {
+			directives = append(directives, name)
+		}
+	}
+	seconds := func(d time.Duration, name string) {
+		if d > 0 {
+			directives = append(directives, name+"="+strconv.FormatInt(int64(d/time.Second), 10))
+		}
+	}
+	flag(c.Public, "public")
+	flag(c.Private, "private")
+	flag(c.NoCache, "no-cache")
+	flag(c.NoStore, "no-store")
+	flag(c.NoTransform, "no-transform")
+	flag(c.MustRevalidate, "must-revalidate")
+	seconds(c.MaxAge, "max-age")
+	seconds(c.SMaxAge, "s-maxage")
+	seconds(c.StaleWhileRevalidate, "stale-while-revalidate")
+	seconds(c.StaleIfError, "stale-if-error")
+	flag(c.Immutable, "immutable")
+	return strings.Join(directives, ", ")
+}
+
+// CacheControl 设置响应的 Cache-Control 头，没有任何指令时删除该响应头
+// 需要在写入响应头之前调用
+func (c *Context) CacheControl(cc CacheControl) {
+	if v := cc.String(); v != "" {
+		c.Resp.Header().Set("Cache-Control", v)
+	} else {
+		c.Resp.Header().Del("Cache-Control")
+	}
+}
+
+// NoStore 禁止缓存响应，用于包含敏感信息的页面
+func (c *Context) NoStore() {
+	c.Resp.Header().Set("Cache-Control", "no-store")
+}
+
+// CachePolicy 按 Content-Type 设置的默认缓存策略
+// 键为媒体类型，例如 "text/html"，也可以是 "image/*" 匹配同一类型的全部子类型
+type CachePolicy map[string]CacheControl
+
+// ServerWithCachePolicy 设置服务器的默认缓存策略
+// 状态码为 2xx 且处理函数没有设置 Cache-Control 时，根据写入响应头时的 Content-Type 选择策略，例如：
+//
+//	ant.ServerWithCachePolicy(ant.CachePolicy{
+//		"text/html": {NoCache: true},
+//		"image/*":   {Public: true, MaxAge: 24 * time.Hour},
+//	})
+func ServerWithCachePolicy(policy CachePolicy) ServerOption {
+	return func(server *HTTPServer) {
+		server.cachePolicy = policy
+	}
+}
+
+// lookup 返回 Content-Type 对应的缓存策略
+func (p CachePolicy) lookup(contentType string) (CacheControl, bool) {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if mediaType == "" {
+		return CacheControl{}, false
+	}
+	if cc, ok := p[mediaType]; ok {
+		return cc, true
+	}
+	if typ, _, ok := strings.Cut(mediaType, "/"); ok {
+		cc, ok := p[typ+"/*"]
+		return cc, ok
+	}
+	return CacheControl{}, false
+}
@@ -0,0 +1,103 @@
+package ant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestCacheControlString 测试缓存指令的格式
+func TestCacheControlString(t *testing.T) {
+	tests := []struct {
+		cc   CacheControl
+		want string
+	}{
+		{CacheControl{}, ""},
+		{CacheControl{Public: true, MaxAge: 365 * 24 * time.Hour}, "public, max-age=31536000"},
+		{CacheControl{Private: true, NoCache: true, MustRevalidate: true}, "private, no-cache, must-revalidate"},
+		{CacheControl{Public: true, MaxAge: time.Minute, SMaxAge: time.Hour, StaleWhileRevalidate: 30 * time.Second, Immutable: true},
+			"public, max-age=60, s-maxage=3600, stale-while-revalidate=30, immutable"},
+	}
+	for _, tt := range tests {
+		if got := tt.cc.String(); got != tt.want {
+			t.Errorf("期望 %q，实际 %q", tt.want, got)
+		}
+	}
+}
+
+// TestCachePolicy 测试服务器的默认缓存策略与处理函数的设置
+func TestCachePolicy(t *testing.T) {
+	server := NewHTTPServer(ServerWithCachePolicy(CachePolicy{
+		"text/html": {NoCache: true},
+		"image/*":   {Public: true, MaxAge: time.Hour},
+	}))
+	respond := func(contentType string, status int) HandleFunc {
+		return func(ctx *Context) {
+			ctx.Resp.Header().Set("Content-Type", contentType)
+			ctx.RespStatusCode = status
+			ctx.RespData = []byte("ok")
+		}
+	}
+	server.Handle("GET /page", respond("text/html; charset=utf-8", http.StatusOK))
+	server.Handle("GET /logo", respond("image/png", http.StatusOK))
+	server.Handle("GET /missing", respond("text/html; charset=utf-8", http.StatusNotFound))
+	server.Handle("GET /data", respond("application/json", http.StatusOK))
+	server.Handle("GET /account", func(ctx *Context) {
+		ctx.NoStore()
+		respond("text/html", http.StatusOK)(ctx)
+	})
+	server.Handle("GET /direct", func(ctx *Context) {
+		ctx.Resp.Header().Set("Content-Type", "image/gif")
+		_, _ = ctx.Resp.Write([]byte("gif"))
+	})
+	server.Handle("GET /custom", func(ctx *Context) {
+		ctx.CacheControl(CacheControl{Private: true, MaxAge: time.Minute})
+		respond("image/png", http.StatusOK)(ctx)
+	})
+
+	tests := map[string]string{
+		"/page":    "no-cache",
+		"/logo":    "public, max-age=3600",
+		"/missing": "",
+		"/data":    "",
+		"/account": "no-store",
+		"/direct":  "public, max-age=3600",
+		"/custom":  "private, max-age=60",
+	}
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if got := w.Header().Get("Cache-Control"); got != want {
+			t.Errorf("%s 期望 Cache-Control %q，实际 %q", path, want, got)
+		}
+	}
+}
+
+// TestStaticCacheControl 测试静态资源处理器的缓存指令
+func TestStaticCacheControl(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("js"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server := NewHTTPServer(ServerWithCachePolicy(CachePolicy{"application/javascript": {NoCache: true}}))
+	server.Handle("GET /default/{file...}", NewStaticResourceHandler(dir, "/default").Handle)
+	server.Handle("GET /immutable/{file...}", NewStaticResourceHandler(dir, "/immutable",
+		WithCacheControl(CacheControl{Public: true, MaxAge: 365 * 24 * time.Hour, Immutable: true})).Handle)
+	server.Handle("GET /policy/{file...}", NewStaticResourceHandler(dir, "/policy", WithCacheControl(CacheControl{})).Handle)
+
+	tests := map[string]string{
+		"/default/app.js":   "public, max-age=31536000",
+		"/immutable/app.js": "public, max-age=31536000, immutable",
+		"/policy/app.js":    "no-cache",
+	}
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if got := w.Header().Get("Cache-Control"); got != want {
+			t.Errorf("%s 期望 Cache-Control %q，实际 %q", path, want, got)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package ant
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownVariant 路由没有通过 HandleVariants 注册，或权重中包含未注册的变体
+var ErrUnknownVariant = errors.New("web: 未知的路由变体")
+
+// Variant 路由的一个处理函数变体，例如稳定版与金丝雀版
+type Variant struct {
+	// Name 变体名称，在同一路由中唯一，例如 "stable"、"canary"
+	Name string
+	// Weight 权重，按权重比例分配请求，为0时不接收请求
+	Weight int
+	// Handler 处理函数
+	Handler HandleFunc
+}
+
+// VariantOption 路由变体的配置选项
+type VariantOption func(set *variantSet)
+
+// VariantWithStickyKey 按请求的键固定分配变体，例如用户ID或会话Cookie
+// 同一个键在权重不变时总是分配到同一个变体，避免用户在两个版本之间来回切换
+// 键为空字符串时随机分配
+func VariantWithStickyKey(fn func(ctx *Context) string) VariantOption {
+	return func(set *variantSet) {
+		set.key = fn
+	}
+}
+
+// VariantWithHeader 在响应头中写入分配到的变体名称，便于排查问题，例如 "X-Variant"
+func VariantWithHeader(header string) VariantOption {
+	return func(set *variantSet) {
+		set.header = header
+	}
+}
+
+// variantSet 路由的全部变体与当前权重
+type variantSet struct {
+	mu       sync.RWMutex
+	variants []Variant
+	total    int
+	key      func(ctx *Context) string
+	header   string
+}
+
+// HandleVariants 注册带有多个处理函数变体的路由，按权重分配请求
+// 用于蓝绿部署与金丝雀发布，例如 95% 的请求由 stable 处理，5% 由 canary 处理
+// 权重可以在运行时通过 SetVariantWeights 或 VariantAdminHandler 调整
+// 变体名称重复、权重为负数或权重之和为0时 panic
+func (s *HTTPServer) HandleVariants(pattern string, variants []Variant, opts ...VariantOption) {
+	set := &variantSet{variants: append([]Variant(nil), variants...)}
+	for _, opt := range opts {
+		opt(set)
+	}
+	weights := make(map[string]int, len(variants))
+	for _, v := range variants {
+		if _, ok := weights[v.Name]; ok {
+			panic(fmt.Sprintf("web: 路由 %s 的变体 %q 重复", pattern, v.Name))
+		}
+		weights[v.Name] = v.Weight
+	}
+	if err := set.setWeights(weights); err != nil {
+		panic(err)
+	}
+
+	s.handle(pattern, set.serve)
+	s.routeMu.Lock()
+	defer s.routeMu.Unlock()
+	if s.variants == nil {
+		s.variants = make(map[string]*variantSet)
+	}
+	s.variants[pattern] = set
+}
+
+// SetVariantWeights 在运行时调整路由变体的权重，未出现在 weights 中的变体保持原权重
+// 例如将金丝雀版本的流量从 5% 逐步提高到 100%，或在出现问题时立即切回 0
+func (s *HTTPServer) SetVariantWeights(pattern string, weights map[string]int) error {
+	set, err := s.variantSet(pattern)
+	if err != nil {
+		return err
+	}
+	return set.setWeights(weights)
+}
+
+// VariantWeights 返回路由变体的当前权重
+func (s *HTTPServer) VariantWeights(pattern string) (map[string]int, error) {
+	set, err := s.variantSet(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return set.weights(), nil
+}
+
+// VariantAdminHandler 返回用于管理路由变体权重的处理函数
+// GET 返回全部路由的权重，格式为 {"GET /users": {"stable": 95, "canary": 5}}
+// PUT 或 POST 接收相同格式的JSON，只更新请求中出现的路由与变体
+// 注意：务必通过鉴权中间件保护该处理函数
+func (s *HTTPServer) VariantAdminHandler() HandleFunc {
+	return func(ctx *Context) {
+		if ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead {
+			var req map[string]map[string]int
+			if err := ctx.BindJSON(&req); err != nil {
+				ctx.RespStatusCode = http.StatusBadRequest
+				ctx.RespData = []byte(err.Error())
+				return
+			}
+			// 先校验全部路由，避免部分更新
+			for pattern, weights := range req {
+				set, err := s.variantSet(pattern)
+				if err == nil {
+					err = set.checkWeights(weights)
+				}
+				if err != nil {
+					ctx.RespStatusCode = http.StatusBadRequest
+					ctx.RespData = []byte(err.Error())
+					return
+				}
+			}
+			for pattern, weights := range req {
+				_ = s.SetVariantWeights(pattern, weights)
+			}
+		}
+
+		s.routeMu.Lock()
+		all := make(map[string]map[string]int, len(s.variants))
+		for pattern, set := range s.variants {
+			all[pattern] = set.weights()
+		}
+		s.routeMu.Unlock()
+		_ = ctx.RespJSONOK(all)
+	}
+}
+
+// variantSet 返回路由的变体
+func (s *HTTPServer) variantSet(pattern string) (*variantSet, error) {
+	s.routeMu.Lock()
+	defer s.routeMu.Unlock()
+	set, ok := s.variants[pattern]
+	if !ok {
+		return nil, fmt.Errorf("%w: 路由 %s", ErrUnknownVariant, pattern)
+	}
+	return set, nil
+}
+
+// serve 按权重选择变体并处理请求
+func (v *variantSet) serve(ctx *Context) {
+	variant := v.pick(ctx)
+	if v.header != "" {
+		ctx.Resp.Header().Set(v.header, variant.Name)
+	}
+	variant.Handler(ctx)
+}
+
+// pick 按权重选择变体
+func (v *variantSet) pick(ctx *Context) Variant {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	var n int
+	if key := v.stickyKey(ctx); key != "" {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(key))
+		n = int(h.Sum32() % uint32(v.total))
+	} else {
+		n = rand.IntN(v.total)
+	}
+	for _, variant := range v.variants {
+		if n < variant.Weight {
+			return variant
+		}
+		n -= variant.Weight
+	}
+	// 不会到达这里：total 是全部权重之和
+	return v.variants[len(v.variants)-1]
+}
+
+func (v *variantSet) stickyKey(ctx *Context) string {
+	if v.key == nil {
+		return ""
+	}
+	return v.key(ctx)
+}
+
+// checkWeights 校验新的权重
+func (v *variantSet) checkWeights(weights map[string]int) error {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	_, err := v.merge(weights)
+	return err
+}
+
+// setWeights 更新权重
+func (v *variantSet) setWeights(weights map[string]int) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	total, err := v.merge(weights)
+	if err != nil {
+		return err
+	}
+	for i := range v.variants {
+		if w, ok := weights[v.variants[i].Name]; ok {
+			v.variants[i].Weight = w
+		}
+	}
+	v.total = total
+	return nil
+}
+
+// merge 校验合并后的权重并返回权重之和，调用方需持有锁
+func (v *variantSet) merge(weights map[string]int) (int, error) {
+	names := make([]string, 0, len(weights))
+	for name := range weights {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		found := false
+		for _, variant := range v.variants {
+			found = found || variant.Name == name
+		}
+		if !found {
+			return 0, fmt.Errorf("%w: %q", ErrUnknownVariant, name)
+		}
+		if weights[name] < 0 {
+			return 0, fmt.Errorf("web: 变体 %q 的权重不能为负数", name)
+		}
+	}
+	total := 0
+	for _, variant := range v.variants {
+		if w, ok := weights[variant.Name]; ok {
+			total += w
+		} else {
+			total += variant.Weight
+		}
+	}
+	if total <= 0 {
+		return 0, errors.New("web: 变体的权重之和必须大于0")
+	}
+	return total, nil
+}
+
+// weights 返回当前权重
+func (v *variantSet) weights() map[string]int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	weights := make(map[string]int, len(v.variants))
+	for _, variant := range v.variants {
+		weights[variant.Name] = variant.Weight
+	}
+	return weights
+}
@@ -0,0 +1,132 @@
+package ant
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newCanaryServer(opts ...VariantOption) *HTTPServer {
+	s := NewHTTPServer()
+	s.HandleVariants("GET /price", []Variant{
+		{Name: "stable", Weight: 95, Handler: func(ctx *Context) { _ = ctx.WriteString("stable") }},
+		{Name: "canary", Weight: 5, Handler: func(ctx *Context) { _ = ctx.WriteString("canary") }},
+	}, opts...)
+	return s
+}
+
+// countVariants 发送 n 个请求并统计各变体处理的数量
+func countVariants(s *HTTPServer, n int, setup func(req *http.Request, i int)) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/price", nil)
+		if setup != nil {
+			setup(req, i)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		counts[w.Body.String()]++
+	}
+	return counts
+}
+
+// TestHandleVariants 测试按权重分配请求与运行时调整权重
+func TestHandleVariants(t *testing.T) {
+	s := newCanaryServer(VariantWithHeader("X-Variant"))
+
+	counts := countVariants(s, 2000, nil)
+	if counts["canary"] < 40 || counts["canary"] > 180 {
+		t.Errorf("金丝雀版本应处理约 5%% 的请求，实际为 %v", counts)
+	}
+
+	// 全部切到金丝雀版本
+	if err := s.SetVariantWeights("GET /price", map[string]int{"stable": 0, "canary": 1}); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/price", nil))
+	if w.Body.String() != "canary" || w.Header().Get("X-Variant") != "canary" {
+		t.Errorf("应由 canary 处理，实际为 %q，响应头 %q", w.Body.String(), w.Header().Get("X-Variant"))
+	}
+	weights, _ := s.VariantWeights("GET /price")
+	if weights["stable"] != 0 || weights["canary"] != 1 {
+		t.Errorf("权重错误: %v", weights)
+	}
+
+	// 非法的权重不生效
+	for _, weights := range []map[string]int{
+		{"canary": 0},
+		{"stable": -1},
+		{"beta": 1},
+	} {
+		if err := s.SetVariantWeights("GET /price", weights); err == nil {
+			t.Errorf("权重 %v 应返回错误", weights)
+		}
+	}
+	if _, err := s.VariantWeights("GET /other"); !errors.Is(err, ErrUnknownVariant) {
+		t.Errorf("未注册的路由应返回 ErrUnknownVariant，实际为 %v", err)
+	}
+}
+
+// TestVariantStickyKey 测试按键固定分配变体
+func TestVariantStickyKey(t *testing.T) {
+	s := newCanaryServer(VariantWithStickyKey(func(ctx *Context) string {
+		return ctx.Req.Header.Get("X-User")
+	}))
+	for _, user := range []string{"alice", "bob", "carol"} {
+		counts := countVariants(s, 50, func(req *http.Request, _ int) {
+			req.Header.Set("X-User", user)
+		})
+		if len(counts) != 1 {
+			t.Errorf("用户 %s 应始终分配到同一个变体，实际为 %v", user, counts)
+		}
+	}
+}
+
+// TestVariantAdminHandler 测试通过管理接口调整权重
+func TestVariantAdminHandler(t *testing.T) {
+	s := newCanaryServer()
+	s.Handle("/admin/variants", s.VariantAdminHandler())
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/variants",
+		strings.NewReader(`{"GET /price": {"canary": 50}}`)))
+	var all map[string]map[string]int
+	if err := json.Unmarshal(w.Body.Bytes(), &all); err != nil {
+		t.Fatalf("响应应为 JSON: %v %s", err, w.Body.String())
+	}
+	if all["GET /price"]["canary"] != 50 || all["GET /price"]["stable"] != 95 {
+		t.Errorf("权重错误: %v", all)
+	}
+
+	for _, body := range []string{`{"GET /other": {"a": 1}}`, `{"GET /price": {"stable": -1}}`, `{`} {
+		w = httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/variants", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("请求 %s 应返回 400，实际为 %d", body, w.Code)
+		}
+	}
+	weights, _ := s.VariantWeights("GET /price")
+	if weights["stable"] != 95 {
+		t.Errorf("非法请求不应修改权重: %v", weights)
+	}
+}
+
+// TestHandleVariantsInvalid 测试非法的变体配置
+func TestHandleVariantsInvalid(t *testing.T) {
+	noop := func(ctx *Context) {}
+	s := NewHTTPServer()
+	if err := catchPanic(func() {
+		s.HandleVariants("GET /a", []Variant{{Name: "x", Weight: 1, Handler: noop}, {Name: "x", Weight: 1, Handler: noop}})
+	}); err == nil {
+		t.Error("变体名称重复时应 panic")
+	}
+	if err := catchPanic(func() {
+		s.HandleVariants("GET /b", []Variant{{Name: "x", Handler: noop}})
+	}); err == nil {
+		t.Error("权重之和为0时应 panic")
+	}
+}
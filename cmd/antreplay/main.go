@@ -0,0 +1,73 @@
+// antreplay 将 replay 中间件录制的请求回放到目标服务
+//
+// 用法：
+//
+//	antreplay -target http://staging.internal:8080 -speed 2 -H "Authorization: Bearer test" traffic.jsonl
+//
+// 没有指定文件时从标准输入读取，完成后以JSON格式输出统计结果
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/justinwongcn/ant/middleware/replay"
+)
+
+// headerFlag 可以重复指定的请求头参数
+type headerFlag http.Header
+
+func (h headerFlag) String() string {
+	return fmt.Sprint(http.Header(h))
+}
+
+func (h headerFlag) Set(val string) error {
+	name, value, ok := strings.Cut(val, ":")
+	if !ok {
+		return fmt.Errorf("请求头 %q 的格式应为 \"Name: value\"", val)
+	}
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	return nil
+}
+
+func main() {
+	header := headerFlag{}
+	r := &replay.Replayer{}
+	flag.StringVar(&r.Target, "target", "", "目标服务的地址，例如 http://127.0.0.1:8080")
+	flag.Float64Var(&r.Speed, "speed", 1, "回放速度的倍数，为0时尽快发送")
+	flag.IntVar(&r.Concurrency, "concurrency", 10, "同时进行的请求的最大数量")
+	flag.Var(header, "H", "覆盖录制的请求头，格式为 \"Name: value\"，可以重复指定")
+	flag.Parse()
+	if r.Target == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	r.Header = http.Header(header)
+
+	var src io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		src = f
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	stats, err := r.Replay(ctx, src)
+	_ = json.NewEncoder(os.Stdout).Encode(stats)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,249 @@
+package ant
+
+import (
+	"context"
+	"expvar"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Backpressure 并发请求的背压策略
+// 处理中的请求达到 MaxInFlight 后，新的请求进入等待队列；队列已满或等待超时的请求返回 503
+type Backpressure struct {
+	// MaxInFlight 同时处理的最大请求数，所有监听器共享
+	MaxInFlight int
+	// MaxQueue 等待队列的最大长度，为 0 时超出 MaxInFlight 的请求立即被拒绝
+	MaxQueue int
+	// QueueTimeout 请求在队列中的最长等待时间，为 0 时只在客户端断开时放弃等待
+	QueueTimeout time.Duration
+	// RetryAfter 拒绝请求时 Retry-After 响应头的值，为 0 时不设置
+	RetryAfter time.Duration
+}
+
+// ServerWithBackpressure 开启并发请求的背压控制
+// MaxInFlight 不大于 0 时不做限制
+func ServerWithBackpressure(bp Backpressure) ServerOption {
+	return func(server *HTTPServer) {
+		if bp.MaxInFlight <= 0 {
+			server.limiter = nil
+			return
+		}
+		server.limiter = &limiter{
+			Backpressure: bp,
+			slots:        make(chan struct{}, bp.MaxInFlight),
+		}
+	}
+}
+
+// ListenerStats 单个监听器的连接与请求统计
+type ListenerStats struct {
+	// Addr 监听地址，汇总数据为空
+	Addr string `json:"addr,omitempty"`
+	// OpenConnections 当前打开的连接数，被接管（例如 WebSocket）的连接不再计入
+	OpenConnections int64 `json:"open_connections"`
+	// TotalConnections 累计接受的连接数
+	TotalConnections uint64 `json:"total_connections"`
+	// InFlight 正在处理的请求数
+	InFlight int64 `json:"in_flight"`
+	// Queued 在背压队列中等待的请求数
+	Queued int64 `json:"queued"`
+	// Rejected 因背压被拒绝的请求数
+	Rejected uint64 `json:"rejected"`
+}
+
+// ConcurrencyStats 服务器的并发统计
+type ConcurrencyStats struct {
+	// Total 所有监听器的汇总，包含不经过监听器直接调用 ServeHTTP 的请求
+	Total ListenerStats `json:"total"`
+	// Listeners 各监听器的统计，按启动顺序排列
+	Listeners []ListenerStats `json:"listeners"`
+}
+
+// ConcurrencyStats 返回当前的连接与请求并发统计
+func (s *HTTPServer) ConcurrencyStats() ConcurrencyStats {
+	s.mu.Lock()
+	metrics := make([]*listenerMetrics, len(s.listenerMetrics))
+	copy(metrics, s.listenerMetrics)
+	s.mu.Unlock()
+
+	stats := ConcurrencyStats{
+		Total:     s.metrics.snapshot(),
+		Listeners: make([]ListenerStats, 0, len(metrics)),
+	}
+	for _, m := range metrics {
+		stats.Listeners = append(stats.Listeners, m.snapshot())
+	}
+	return stats
+}
+
+// PublishConcurrencyMetrics 将并发统计以 expvar 变量的形式发布
+// 发布后可以通过 EnablePprof 挂载的 {prefix}/vars 端点读取
+// name: 变量名，同名变量已存在时 panic，与 expvar.Publish 一致
+func (s *HTTPServer) PublishConcurrencyMetrics(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return s.ConcurrencyStats()
+	}))
+}
+
+// listenerMetrics 连接与请求计数器
+type listenerMetrics struct {
+	addr       string
+	openConns  atomic.Int64
+	totalConns atomic.Uint64
+	inFlight   atomic.Int64
+	queued     atomic.Int64
+	rejected   atomic.Uint64
+}
+
+// snapshot 返回计数器的快照
+func (m *listenerMetrics) snapshot() ListenerStats {
+	return ListenerStats{
+		Addr:             m.addr,
+		OpenConnections:  m.openConns.Load(),
+		TotalConnections: m.totalConns.Load(),
+		InFlight:         m.inFlight.Load(),
+		Queued:           m.queued.Load(),
+		Rejected:         m.rejected.Load(),
+	}
+}
+
+// trackConn 根据连接状态更新连接计数
+func (m *listenerMetrics) trackConn(state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		m.openConns.Add(1)
+		m.totalConns.Add(1)
+	case http.StateHijacked, http.StateClosed:
+		m.openConns.Add(-1)
+	}
+}
+
+// listenerMetricsKey 在请求的 context 中保存所属监听器的计数器
+type listenerMetricsKey struct{}
+
+// instrumentServer 为底层的 http.Server 挂载连接与请求计数
+// 在 serverHooks 之后执行，保留用户通过 ServerWithConnState 等选项设置的回调
+func (s *HTTPServer) instrumentServer(srv *http.Server) {
+	m := &listenerMetrics{addr: srv.Addr}
+	s.mu.Lock()
+	s.listenerMetrics = append(s.listenerMetrics, m)
+	s.mu.Unlock()
+
+	connState := srv.ConnState
+	srv.ConnState = func(conn net.Conn, state http.ConnState) {
+		m.trackConn(state)
+		s.metrics.trackConn(state)
+		if connState != nil {
+			connState(conn, state)
+		}
+	}
+	baseContext := srv.BaseContext
+	srv.BaseContext = func(l net.Listener) context.Context {
+		ctx := context.Background()
+		if baseContext != nil {
+			ctx = baseContext(l)
+		}
+		return context.WithValue(ctx, listenerMetricsKey{}, m)
+	}
+}
+
+// limiter 背压策略的运行状态
+type limiter struct {
+	Backpressure
+	slots  chan struct{}
+	queued atomic.Int64
+}
+
+// acquire 获取处理请求的名额
+// total, m: 汇总与所属监听器的计数器，m 可以为nil
+// 返回值: 是否获得名额，获得名额后需要调用 release 归还
+func (l *limiter) acquire(r *http.Request, total, m *listenerMetrics) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if l.MaxQueue <= 0 {
+		return false
+	}
+	if l.queued.Add(1) > int64(l.MaxQueue) {
+		l.queued.Add(-1)
+		return false
+	}
+	addQueued(total, m, 1)
+	defer func() {
+		l.queued.Add(-1)
+		addQueued(total, m, -1)
+	}()
+
+	var timeout <-chan time.Time
+	if l.QueueTimeout > 0 {
+		timer := time.NewTimer(l.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timeout:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// release 归还处理请求的名额
+func (l *limiter) release() {
+	<-l.slots
+}
+
+// reject 返回 503 拒绝请求
+func (l *limiter) reject(w http.ResponseWriter) {
+	if l.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int((l.RetryAfter+time.Second-1)/time.Second)))
+	}
+	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
+
+// addInFlight 同时更新汇总与所属监听器的处理中请求数，m 可以为nil
+func addInFlight(total, m *listenerMetrics, delta int64) {
+	total.inFlight.Add(delta)
+	if m != nil {
+		m.inFlight.Add(delta)
+	}
+}
+
+// addQueued 同时更新汇总与所属监听器的排队请求数，m 可以为nil
+func addQueued(total, m *listenerMetrics, delta int64) {
+	total.queued.Add(delta)
+	if m != nil {
+		m.queued.Add(delta)
+	}
+}
+
+// enter 执行背压策略并记录开始处理的请求
+// 返回值: 请求所属监听器的计数器（可能为nil），以及请求是否被接受；被拒绝的请求已经写入 503 响应
+func (s *HTTPServer) enter(w http.ResponseWriter, r *http.Request) (*listenerMetrics, bool) {
+	m, _ := r.Context().Value(listenerMetricsKey{}).(*listenerMetrics)
+	if l := s.limiter; l != nil && !l.acquire(r, &s.metrics, m) {
+		s.metrics.rejected.Add(1)
+		if m != nil {
+			m.rejected.Add(1)
+		}
+		l.reject(w)
+		return m, false
+	}
+	addInFlight(&s.metrics, m, 1)
+	return m, true
+}
+
+// leave 记录请求处理完成并归还背压名额
+func (s *HTTPServer) leave(m *listenerMetrics) {
+	addInFlight(&s.metrics, m, -1)
+	if l := s.limiter; l != nil {
+		l.release()
+	}
+}
@@ -0,0 +1,212 @@
+package ant
+
+import (
+	"context"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingServer 创建一个处理函数阻塞到 release 关闭的服务器
+func blockingServer(t *testing.T, opts ...ServerOption) (server *HTTPServer, entered chan struct{}, release chan struct{}) {
+	t.Helper()
+	server = NewHTTPServer(opts...)
+	entered = make(chan struct{}, 16)
+	release = make(chan struct{})
+	server.Handle("GET /block", func(ctx *Context) {
+		entered <- struct{}{}
+		<-release
+		ctx.RespStatusCode = http.StatusOK
+	})
+	return server, entered, release
+}
+
+// serveAsync 在后台处理请求，返回接收响应的通道
+func serveAsync(server http.Handler, path string) chan *httptest.ResponseRecorder {
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		done <- w
+	}()
+	return done
+}
+
+// TestBackpressureReject 测试超出并发限制时立即拒绝
+func TestBackpressureReject(t *testing.T) {
+	server, entered, release := blockingServer(t, ServerWithBackpressure(Backpressure{
+		MaxInFlight: 1,
+		RetryAfter:  1500 * time.Millisecond,
+	}))
+	first := serveAsync(server, "/block")
+	<-entered
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/block", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("期望状态码 503，实际 %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After 应向上取整为 2，实际 %q", got)
+	}
+
+	stats := server.ConcurrencyStats().Total
+	if stats.InFlight != 1 || stats.Rejected != 1 {
+		t.Errorf("统计错误: %+v", stats)
+	}
+
+	close(release)
+	if w := <-first; w.Code != http.StatusOK {
+		t.Errorf("第一个请求应正常完成，实际状态码 %d", w.Code)
+	}
+	if stats := server.ConcurrencyStats().Total; stats.InFlight != 0 {
+		t.Errorf("请求完成后处理中的请求数应为 0，实际 %d", stats.InFlight)
+	}
+}
+
+// TestBackpressureQueue 测试排队等待名额
+func TestBackpressureQueue(t *testing.T) {
+	server, entered, release := blockingServer(t, ServerWithBackpressure(Backpressure{
+		MaxInFlight:  1,
+		MaxQueue:     1,
+		QueueTimeout: time.Second,
+	}))
+	first := serveAsync(server, "/block")
+	<-entered
+	second := serveAsync(server, "/block")
+	waitFor(t, func() bool { return server.ConcurrencyStats().Total.Queued == 1 })
+
+	// 队列已满，第三个请求立即被拒绝
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/block", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("队列已满时期望状态码 503，实际 %d", w.Code)
+	}
+
+	// 第一个请求完成后，排队的请求获得名额
+	close(release)
+	for _, done := range []chan *httptest.ResponseRecorder{first, second} {
+		if w := <-done; w.Code != http.StatusOK {
+			t.Errorf("期望状态码 200，实际 %d", w.Code)
+		}
+	}
+	stats := server.ConcurrencyStats().Total
+	if stats.Queued != 0 || stats.InFlight != 0 || stats.Rejected != 1 {
+		t.Errorf("统计错误: %+v", stats)
+	}
+}
+
+// TestBackpressureQueueTimeout 测试排队超时后拒绝
+func TestBackpressureQueueTimeout(t *testing.T) {
+	server, entered, release := blockingServer(t, ServerWithBackpressure(Backpressure{
+		MaxInFlight:  1,
+		MaxQueue:     1,
+		QueueTimeout: 20 * time.Millisecond,
+	}))
+	defer close(release)
+	serveAsync(server, "/block")
+	<-entered
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/block", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("排队超时期望状态码 503，实际 %d", w.Code)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("应至少等待 QueueTimeout，实际 %v", elapsed)
+	}
+}
+
+// TestBackpressureClientCancel 测试客户端断开时放弃排队
+func TestBackpressureClientCancel(t *testing.T) {
+	server, entered, release := blockingServer(t, ServerWithBackpressure(Backpressure{
+		MaxInFlight: 1,
+		MaxQueue:    1,
+	}))
+	defer close(release)
+	serveAsync(server, "/block")
+	<-entered
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan int, 1)
+	go func() {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/block", nil).WithContext(ctx))
+		done <- w.Code
+	}()
+	waitFor(t, func() bool { return server.ConcurrencyStats().Total.Queued == 1 })
+	cancel()
+	if code := <-done; code != http.StatusServiceUnavailable {
+		t.Errorf("客户端断开后期望状态码 503，实际 %d", code)
+	}
+}
+
+// TestListenerStats 测试按监听器统计连接与请求
+func TestListenerStats(t *testing.T) {
+	var userStates atomic.Int32
+	server, entered, release := blockingServer(t, ServerWithConnState(func(conn net.Conn, state http.ConnState) {
+		userStates.Add(1)
+	}))
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = server.Serve(l) }()
+	defer func() { _ = server.Shutdown(context.Background()) }()
+
+	done := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + l.Addr().String() + "/block")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		done <- err
+	}()
+	<-entered
+
+	stats := server.ConcurrencyStats()
+	if len(stats.Listeners) != 1 {
+		t.Fatalf("期望 1 个监听器，实际 %d", len(stats.Listeners))
+	}
+	ls := stats.Listeners[0]
+	if ls.Addr != l.Addr().String() {
+		t.Errorf("监听地址错误，期望 %s，实际 %s", l.Addr(), ls.Addr)
+	}
+	if ls.OpenConnections != 1 || ls.TotalConnections != 1 || ls.InFlight != 1 {
+		t.Errorf("监听器统计错误: %+v", ls)
+	}
+	if stats.Total.InFlight != 1 || stats.Total.OpenConnections != 1 {
+		t.Errorf("汇总统计错误: %+v", stats.Total)
+	}
+	if userStates.Load() == 0 {
+		t.Error("用户设置的 ConnState 回调应继续生效")
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return server.ConcurrencyStats().Listeners[0].InFlight == 0 })
+}
+
+// TestPublishConcurrencyMetrics 测试通过 expvar 发布并发统计
+func TestPublishConcurrencyMetrics(t *testing.T) {
+	server := NewHTTPServer()
+	// expvar 变量不能重复发布，-count 多次运行时使用不同的名称
+	name := "ant_test_concurrency_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	server.PublishConcurrencyMetrics(name)
+	v := expvar.Get(name)
+	if v == nil {
+		t.Fatal("expvar 变量未发布")
+	}
+	if !strings.Contains(v.String(), `"in_flight":0`) {
+		t.Errorf("expvar 输出错误: %s", v.String())
+	}
+}
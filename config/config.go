@@ -0,0 +1,186 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/justinwongcn/ant"
+	"github.com/justinwongcn/ant/middleware/accesslog"
+)
+
+// Config 服务器配置
+// 字段的 yaml、toml 标签对应配置文件中的键，env 标签对应环境变量名去掉前缀后的部分
+type Config struct {
+	// Server 监听地址、超时与并发限制
+	Server ServerConfig `yaml:"server" toml:"server" env:"SERVER"`
+	// TLS 证书配置
+	TLS TLSConfig `yaml:"tls" toml:"tls" env:"TLS"`
+	// Static 静态资源目录
+	Static []StaticConfig `yaml:"static" toml:"static"`
+	// Session 会话配置
+	Session SessionConfig `yaml:"session" toml:"session" env:"SESSION"`
+	// Middleware 内置中间件的开关
+	Middleware MiddlewareConfig `yaml:"middleware" toml:"middleware" env:"MIDDLEWARE"`
+}
+
+// ServerConfig 服务器的监听地址、超时与并发限制
+type ServerConfig struct {
+	// Addr 监听地址，默认为 ":8080"
+	Addr string `yaml:"addr" toml:"addr" env:"ADDR"`
+	// ReadHeaderTimeout 读取请求头的超时时间，默认为 10 秒
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout" env:"READ_HEADER_TIMEOUT"`
+	// ReadTimeout 读取整个请求的超时时间，为 0 时不限制
+	ReadTimeout time.Duration `yaml:"read_timeout" toml:"read_timeout" env:"READ_TIMEOUT"`
+	// WriteTimeout 写入响应的超时时间，为 0 时不限制
+	WriteTimeout time.Duration `yaml:"write_timeout" toml:"write_timeout" env:"WRITE_TIMEOUT"`
+	// IdleTimeout 空闲连接的超时时间，默认为 120 秒
+	IdleTimeout time.Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"IDLE_TIMEOUT"`
+	// ShutdownTimeout 优雅关闭时等待请求完成的最长时间，默认为 30 秒
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// MaxHeaderBytes 请求头的最大字节数，为 0 时使用标准库默认值
+	MaxHeaderBytes int `yaml:"max_header_bytes" toml:"max_header_bytes" env:"MAX_HEADER_BYTES"`
+	// MaxInFlight 同时处理的最大请求数，为 0 时不限制
+	MaxInFlight int `yaml:"max_in_flight" toml:"max_in_flight" env:"MAX_IN_FLIGHT"`
+	// MaxQueue 超出 MaxInFlight 后排队等待的最大请求数
+	MaxQueue int `yaml:"max_queue" toml:"max_queue" env:"MAX_QUEUE"`
+	// QueueTimeout 请求排队等待的最长时间
+	QueueTimeout time.Duration `yaml:"queue_timeout" toml:"queue_timeout" env:"QUEUE_TIMEOUT"`
+	// TrustedProxies 受信任代理的 IP 或 CIDR
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+}
+
+// TLSConfig 证书配置，CertFile 与 AutoTLSDomains 最多设置一个
+type TLSConfig struct {
+	// CertFile 与 KeyFile 证书文件路径
+	CertFile string `yaml:"cert_file" toml:"cert_file" env:"CERT_FILE"`
+	KeyFile  string `yaml:"key_file" toml:"key_file" env:"KEY_FILE"`
+	// AutoTLSDomains 通过 ACME 自动申请证书的域名
+	AutoTLSDomains []string `yaml:"auto_tls_domains" toml:"auto_tls_domains" env:"AUTO_TLS_DOMAINS"`
+	// AutoTLSEmail 注册 ACME 账号使用的邮箱
+	AutoTLSEmail string `yaml:"auto_tls_email" toml:"auto_tls_email" env:"AUTO_TLS_EMAIL"`
+	// AutoTLSCacheDir 自动申请的证书的缓存目录，默认为 "certs"
+	AutoTLSCacheDir string `yaml:"auto_tls_cache_dir" toml:"auto_tls_cache_dir" env:"AUTO_TLS_CACHE_DIR"`
+}
+
+// StaticConfig 静态资源目录
+type StaticConfig struct {
+	// Prefix URL 路径前缀，例如 "/static"
+	Prefix string `yaml:"prefix" toml:"prefix"`
+	// Dir 静态资源的根目录
+	Dir string `yaml:"dir" toml:"dir"`
+}
+
+// SessionConfig 会话配置
+type SessionConfig struct {
+	// Backend 会话存储，支持 "memory"，为空时不启用会话
+	Backend string `yaml:"backend" toml:"backend" env:"BACKEND"`
+	// CookieName 保存会话ID的 Cookie 名称，默认为 "sessid"
+	CookieName string `yaml:"cookie_name" toml:"cookie_name" env:"COOKIE_NAME"`
+	// Expiration 会话的过期时间，默认为 30 分钟
+	Expiration time.Duration `yaml:"expiration" toml:"expiration" env:"EXPIRATION"`
+}
+
+// MiddlewareConfig 内置中间件的开关
+type MiddlewareConfig struct {
+	// Recovery 是否启用 panic 恢复中间件，默认启用
+	Recovery bool `yaml:"recovery" toml:"recovery" env:"RECOVERY"`
+	// AccessLog 是否启用访问日志中间件
+	AccessLog bool `yaml:"access_log" toml:"access_log" env:"ACCESS_LOG"`
+	// AccessLogLevel 访问日志的级别，例如 "info"、"warn"，为空时使用默认级别
+	AccessLogLevel string `yaml:"access_log_level" toml:"access_log_level" env:"ACCESS_LOG_LEVEL"`
+}
+
+// Default 返回默认配置
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		TLS: TLSConfig{
+			AutoTLSCacheDir: "certs",
+		},
+		Session: SessionConfig{
+			CookieName: "sessid",
+			Expiration: 30 * time.Minute,
+		},
+		Middleware: MiddlewareConfig{
+			Recovery: true,
+		},
+	}
+}
+
+// Validate 校验配置
+// 返回值: 全部不合法的配置项
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Server.Addr == "" {
+		errs = append(errs, errors.New("server.addr 不能为空"))
+	}
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"server.queue_timeout", c.Server.QueueTimeout},
+		{"session.expiration", c.Session.Expiration},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s 不能为负数", d.name))
+		}
+	}
+	if c.Server.MaxHeaderBytes < 0 || c.Server.MaxInFlight < 0 || c.Server.MaxQueue < 0 {
+		errs = append(errs, errors.New("server.max_header_bytes、max_in_flight 与 max_queue 不能为负数"))
+	}
+	if c.Server.MaxQueue > 0 && c.Server.MaxInFlight == 0 {
+		errs = append(errs, errors.New("server.max_queue 需要同时设置 server.max_in_flight"))
+	}
+	if _, err := ant.NewTrustedProxies(c.Server.TrustedProxies...); err != nil {
+		errs = append(errs, fmt.Errorf("server.trusted_proxies: %w", err))
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("tls.cert_file 与 tls.key_file 需要同时设置"))
+	}
+	if c.TLS.CertFile != "" && len(c.TLS.AutoTLSDomains) > 0 {
+		errs = append(errs, errors.New("tls.cert_file 与 tls.auto_tls_domains 不能同时设置"))
+	}
+
+	for i, s := range c.Static {
+		if !strings.HasPrefix(s.Prefix, "/") {
+			errs = append(errs, fmt.Errorf("static[%d].prefix 需要以 / 开头", i))
+		}
+		if s.Dir == "" {
+			errs = append(errs, fmt.Errorf("static[%d].dir 不能为空", i))
+		}
+	}
+
+	switch c.Session.Backend {
+	case "", "memory":
+	default:
+		errs = append(errs, fmt.Errorf("不支持的 session.backend %q", c.Session.Backend))
+	}
+	if c.Session.Backend != "" && c.Session.CookieName == "" {
+		errs = append(errs, errors.New("启用会话时 session.cookie_name 不能为空"))
+	}
+
+	if c.Middleware.AccessLogLevel != "" {
+		if _, err := accesslog.ParseLevel(c.Middleware.AccessLogLevel); err != nil {
+			errs = append(errs, fmt.Errorf("middleware.access_log_level: %w", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("config: 配置不合法: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+// writeFile 在临时目录中写入文件并返回路径
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestLoadDefaults 测试不指定配置文件时使用默认值
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("期望默认配置，实际 %+v", cfg)
+	}
+}
+
+// TestLoadYAML 测试加载 YAML 配置文件
+func TestLoadYAML(t *testing.T) {
+	path := writeFile(t, "ant.yaml", `
+server:
+  addr: ":9090"
+  read_timeout: 5s
+  trusted_proxies: ["10.0.0.0/8"]
+static:
+  - prefix: /static
+    dir: ./public
+session:
+  backend: memory
+middleware:
+  access_log: true
+  access_log_level: warn
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Addr != ":9090" || cfg.Server.ReadTimeout != 5*time.Second {
+		t.Errorf("server 配置错误: %+v", cfg.Server)
+	}
+	// 文件中未出现的字段保持默认值
+	if cfg.Server.IdleTimeout != 120*time.Second || !cfg.Middleware.Recovery {
+		t.Errorf("未配置的字段应保持默认值: %+v", cfg)
+	}
+	if len(cfg.Static) != 1 || cfg.Static[0].Dir != "./public" {
+		t.Errorf("static 配置错误: %+v", cfg.Static)
+	}
+	if cfg.Session.Backend != "memory" || !cfg.Middleware.AccessLog {
+		t.Errorf("session 或 middleware 配置错误: %+v", cfg)
+	}
+}
+
+// TestLoadTOML 测试加载 TOML 配置文件
+func TestLoadTOML(t *testing.T) {
+	path := writeFile(t, "ant.toml", `
+[server]
+addr = ":9091"
+write_timeout = "3s"
+max_in_flight = 100
+max_queue = 10
+
+[[static]]
+prefix = "/assets"
+dir = "./assets"
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Addr != ":9091" || cfg.Server.WriteTimeout != 3*time.Second || cfg.Server.MaxQueue != 10 {
+		t.Errorf("server 配置错误: %+v", cfg.Server)
+	}
+	if len(cfg.Static) != 1 || cfg.Static[0].Prefix != "/assets" {
+		t.Errorf("static 配置错误: %+v", cfg.Static)
+	}
+}
+
+// TestLoadUnknownField 测试未知的配置项
+func TestLoadUnknownField(t *testing.T) {
+	for name, content := range map[string]string{
+		"ant.yaml": "server:\n  adr: \":80\"\n",
+		"ant.toml": "[server]\nadr = \":80\"\n",
+	} {
+		if _, err := Load(writeFile(t, name, content)); err == nil {
+			t.Errorf("%s 中的未知配置项应返回错误", name)
+		}
+	}
+	if _, err := Load(writeFile(t, "ant.json", "{}")); err == nil {
+		t.Error("不支持的扩展名应返回错误")
+	}
+}
+
+// TestApplyEnv 测试环境变量覆盖配置
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"ANT_SERVER_ADDR":             ":7070",
+		"ANT_SERVER_IDLE_TIMEOUT":     "1m",
+		"ANT_SERVER_MAX_IN_FLIGHT":    "8",
+		"ANT_SERVER_TRUSTED_PROXIES":  "10.0.0.1, 192.168.0.0/16",
+		"ANT_MIDDLEWARE_RECOVERY":     "false",
+		"ANT_TLS_AUTO_TLS_DOMAINS":    "example.com",
+		"ANT_SESSION_BACKEND":         "memory",
+		"ANT_SESSION_EXPIRATION":      "2h",
+		"ANT_MIDDLEWARE_ACCESS_LOG":   "true",
+		"OTHER_SERVER_ADDR":           ":1",
+		"ANT_MIDDLEWARE_UNKNOWN_FLAG": "1",
+	}
+	cfg := Default()
+	err := cfg.ApplyEnv("ANT", func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Default()
+	want.Server.Addr = ":7070"
+	want.Server.IdleTimeout = time.Minute
+	want.Server.MaxInFlight = 8
+	want.Server.TrustedProxies = []string{"10.0.0.1", "192.168.0.0/16"}
+	want.Middleware.Recovery = false
+	want.Middleware.AccessLog = true
+	want.TLS.AutoTLSDomains = []string{"example.com"}
+	want.Session.Backend = "memory"
+	want.Session.Expiration = 2 * time.Hour
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("环境变量覆盖错误\n期望 %+v\n实际 %+v", want, cfg)
+	}
+
+	err = cfg.ApplyEnv("ANT", func(key string) (string, bool) {
+		if key == "ANT_SERVER_READ_TIMEOUT" {
+			return "soon", true
+		}
+		return "", false
+	})
+	if err == nil || !strings.Contains(err.Error(), "ANT_SERVER_READ_TIMEOUT") {
+		t.Errorf("非法的环境变量应返回包含变量名的错误，实际 %v", err)
+	}
+}
+
+// TestLoadEnvOverridesFile 测试环境变量的优先级高于配置文件
+func TestLoadEnvOverridesFile(t *testing.T) {
+	t.Setenv("ANT_SERVER_ADDR", ":6060")
+	cfg, err := Load(writeFile(t, "ant.yaml", "server:\n  addr: \":9090\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Addr != ":6060" {
+		t.Errorf("环境变量应覆盖配置文件，实际 %s", cfg.Server.Addr)
+	}
+}
+
+// TestValidate 测试配置校验
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(cfg *Config)
+		errMsg string
+	}{
+		{name: "空地址", modify: func(cfg *Config) { cfg.Server.Addr = "" }, errMsg: "server.addr"},
+		{name: "负数时长", modify: func(cfg *Config) { cfg.Server.ReadTimeout = -time.Second }, errMsg: "server.read_timeout"},
+		{name: "只有队列", modify: func(cfg *Config) { cfg.Server.MaxQueue = 1 }, errMsg: "max_in_flight"},
+		{name: "非法代理", modify: func(cfg *Config) { cfg.Server.TrustedProxies = []string{"bad"} }, errMsg: "trusted_proxies"},
+		{name: "缺少私钥", modify: func(cfg *Config) { cfg.TLS.CertFile = "cert.pem" }, errMsg: "tls.key_file"},
+		{name: "证书冲突", modify: func(cfg *Config) {
+			cfg.TLS.CertFile, cfg.TLS.KeyFile = "cert.pem", "key.pem"
+			cfg.TLS.AutoTLSDomains = []string{"example.com"}
+		}, errMsg: "auto_tls_domains"},
+		{name: "静态目录", modify: func(cfg *Config) { cfg.Static = []StaticConfig{{Prefix: "static"}} }, errMsg: "static[0]"},
+		{name: "会话存储", modify: func(cfg *Config) { cfg.Session.Backend = "redis" }, errMsg: "session.backend"},
+		{name: "日志级别", modify: func(cfg *Config) { cfg.Middleware.AccessLogLevel = "loud" }, errMsg: "access_log_level"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Default()
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("期望包含 %q 的错误，实际 %v", tc.errMsg, err)
+			}
+		})
+	}
+}
+
+// TestNewHTTPServerFromConfig 测试根据配置创建服务器
+func TestNewHTTPServerFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.txt"), []byte("static content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Default()
+	cfg.Static = []StaticConfig{{Prefix: "/static/", Dir: dir}}
+	cfg.Server.MaxInFlight = 1
+
+	server, err := NewHTTPServerFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static/app.txt", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "static content" {
+		t.Errorf("静态资源响应错误: %d %q", w.Code, w.Body.String())
+	}
+
+	// 默认启用的恢复中间件将 panic 转换为 500
+	server.Handle("GET /panic", func(ctx *ant.Context) { panic("boom") })
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("期望状态码 500，实际 %d", w.Code)
+	}
+
+	cfg.Server.Addr = ""
+	if _, err = NewHTTPServerFromConfig(cfg); err == nil {
+		t.Error("不合法的配置应返回错误")
+	}
+}
+
+// TestNewSessionManager 测试根据配置创建会话管理器
+func TestNewSessionManager(t *testing.T) {
+	cfg := Default()
+	m, err := NewSessionManager(cfg)
+	if err != nil || m != nil {
+		t.Errorf("未配置会话存储时应返回nil，实际 %v %v", m, err)
+	}
+
+	cfg.Session.Backend = "memory"
+	cfg.Session.CookieName = "sid"
+	m, err = NewSessionManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	if err = m.Inject("id", w); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(w.Header().Get("Set-Cookie"), "sid=id") {
+		t.Errorf("Cookie 名称错误: %s", w.Header().Get("Set-Cookie"))
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix Load 读取的环境变量前缀，例如 ANT_SERVER_ADDR 对应 server.addr
+const EnvPrefix = "ANT"
+
+// Format 配置文件格式
+type Format string
+
+const (
+	// FormatYAML YAML 格式
+	FormatYAML Format = "yaml"
+	// FormatTOML TOML 格式
+	FormatTOML Format = "toml"
+)
+
+// Load 按 默认值、配置文件、环境变量 的顺序加载配置，后者覆盖前者，最后校验配置
+// path: 配置文件路径，根据扩展名 .yaml、.yml 或 .toml 确定格式，为空时只读取环境变量
+// 返回值: 加载后的配置，文件无法解析或配置不合法时返回错误
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("config: 读取配置文件失败: %w", err)
+		}
+		format, err := formatOf(path)
+		if err != nil {
+			return nil, err
+		}
+		if err = cfg.Decode(data, format); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.ApplyEnv(EnvPrefix, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// formatOf 根据扩展名确定配置文件格式
+func formatOf(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".toml":
+		return FormatTOML, nil
+	default:
+		return "", fmt.Errorf("config: 无法根据扩展名确定 %s 的格式", path)
+	}
+}
+
+// Decode 将配置文件的内容解码到配置中，文件中未出现的字段保持原值
+// 未知的字段视为错误，避免拼写错误的配置项被静默忽略
+func (c *Config) Decode(data []byte, format Format) error {
+	switch format {
+	case FormatYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		// 空文件返回 io.EOF，视为没有配置
+		if err := dec.Decode(c); err != nil && len(bytes.TrimSpace(data)) > 0 {
+			return fmt.Errorf("config: 解析 YAML 失败: %w", err)
+		}
+	case FormatTOML:
+		md, err := toml.NewDecoder(bytes.NewReader(data)).Decode(c)
+		if err != nil {
+			return fmt.Errorf("config: 解析 TOML 失败: %w", err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("config: 未知的配置项 %v", undecoded)
+		}
+	default:
+		return fmt.Errorf("config: 不支持的配置格式 %q", format)
+	}
+	return nil
+}
+
+// ApplyEnv 使用环境变量覆盖配置
+// prefix: 环境变量前缀，变量名由前缀与各级 env 标签以下划线连接，例如 ANT_SESSION_BACKEND
+// lookup: 读取环境变量的函数，通常为 os.LookupEnv，测试时可以替换
+// 注意：
+// 1. 时长使用 time.ParseDuration 的格式，例如 "5s"
+// 2. 字符串列表以逗号分隔
+// 3. 静态资源目录等结构体列表只能在配置文件中设置
+func (c *Config) ApplyEnv(prefix string, lookup func(key string) (string, bool)) error {
+	return applyEnv(reflect.ValueOf(c).Elem(), prefix, lookup)
+}
+
+// durationType time.Duration 的反射类型，需要与普通整数区分
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv 递归地使用环境变量设置结构体字段
+func applyEnv(v reflect.Value, prefix string, lookup func(key string) (string, bool)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("env")
+		if tag == "" {
+			continue
+		}
+		key := tag
+		if prefix != "" {
+			key = prefix + "_" + tag
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field, key, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+		raw, ok := lookup(key)
+		if !ok {
+			continue
+		}
+		if err := setField(field, raw); err != nil {
+			return fmt.Errorf("config: 环境变量 %s=%q 不合法: %w", key, raw, err)
+		}
+	}
+	return nil
+}
+
+// setField 将字符串解析为字段的类型并赋值
+func setField(field reflect.Value, raw string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("不支持的字段类型 %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/justinwongcn/ant"
+	"github.com/justinwongcn/ant/middleware/accesslog"
+	"github.com/justinwongcn/ant/middleware/recovery"
+	"github.com/justinwongcn/ant/session"
+	"github.com/justinwongcn/ant/session/cookie"
+	"github.com/justinwongcn/ant/session/memory"
+)
+
+// NewHTTPServerFromConfig 根据配置创建服务器
+// cfg: 服务器配置，会先经过校验
+// opts: 额外的配置选项，在配置文件的设置之后应用
+// 返回值: 已注册内置中间件与静态资源路由的服务器
+// 注意：
+// 1. 会话存储需要通过 NewSessionManager 单独创建并在处理函数中使用
+// 2. 静态资源目录不存在时不返回错误，可以在启动前调用 server.Validate 检查
+func NewHTTPServerFromConfig(cfg *Config, opts ...ant.ServerOption) (*ant.HTTPServer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	serverOpts := []ant.ServerOption{
+		ant.ServerWithReadHeaderTimeout(cfg.Server.ReadHeaderTimeout),
+		ant.ServerWithReadTimeout(cfg.Server.ReadTimeout),
+		ant.ServerWithWriteTimeout(cfg.Server.WriteTimeout),
+		ant.ServerWithIdleTimeout(cfg.Server.IdleTimeout),
+	}
+	if cfg.Server.MaxHeaderBytes > 0 {
+		serverOpts = append(serverOpts, ant.ServerWithMaxHeaderBytes(cfg.Server.MaxHeaderBytes))
+	}
+	if cfg.Server.MaxInFlight > 0 {
+		serverOpts = append(serverOpts, ant.ServerWithBackpressure(ant.Backpressure{
+			MaxInFlight:  cfg.Server.MaxInFlight,
+			MaxQueue:     cfg.Server.MaxQueue,
+			QueueTimeout: cfg.Server.QueueTimeout,
+		}))
+	}
+	if len(cfg.Server.TrustedProxies) > 0 {
+		proxies, err := ant.NewTrustedProxies(cfg.Server.TrustedProxies...)
+		if err != nil {
+			return nil, err
+		}
+		serverOpts = append(serverOpts, ant.ServerWithTrustedProxies(proxies))
+	}
+	if len(cfg.TLS.AutoTLSDomains) > 0 {
+		serverOpts = append(serverOpts, ant.ServerWithAutoTLSCache(autocert.DirCache(cfg.TLS.AutoTLSCacheDir)))
+		if cfg.TLS.AutoTLSEmail != "" {
+			serverOpts = append(serverOpts, ant.ServerWithAutoTLSEmail(cfg.TLS.AutoTLSEmail))
+		}
+	}
+	server := ant.NewHTTPServer(append(serverOpts, opts...)...)
+
+	// 恢复中间件位于最外层，可以捕获其他中间件中的 panic
+	if cfg.Middleware.Recovery {
+		server.Use(recovery.NewMiddlewareBuilder().Build())
+	}
+	if cfg.Middleware.AccessLog {
+		builder := accesslog.NewBuilder()
+		if cfg.Middleware.AccessLogLevel != "" {
+			level, err := accesslog.ParseLevel(cfg.Middleware.AccessLogLevel)
+			if err != nil {
+				return nil, err
+			}
+			builder.SetLevel(level)
+		}
+		server.Use(builder.Build())
+	}
+
+	for _, s := range cfg.Static {
+		server.Static(s.Prefix, s.Dir)
+	}
+	return server, nil
+}
+
+// NewSessionManager 根据配置创建会话管理器
+// 返回值: 会话管理器，未配置 session.backend 时返回nil
+func NewSessionManager(cfg *Config) (*session.Manager, error) {
+	switch cfg.Session.Backend {
+	case "":
+		return nil, nil
+	case "memory":
+		return &session.Manager{
+			Store:      memory.NewStore(cfg.Session.Expiration),
+			Propagator: cookie.NewPropagator(cookie.WithCookieName(cfg.Session.CookieName)),
+			SessCtxKey: "session",
+		}, nil
+	default:
+		return nil, errors.New("config: 不支持的 session.backend " + cfg.Session.Backend)
+	}
+}
+
+// Run 按配置启动服务器
+// 配置了证书文件时使用 RunTLS，配置了 AutoTLSDomains 时使用 RunAutoTLS，否则启动 HTTP 服务器并在收到信号后优雅关闭
+func Run(server *ant.HTTPServer, cfg *Config) error {
+	switch {
+	case cfg.TLS.CertFile != "":
+		return server.RunTLS(cfg.Server.Addr, cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	case len(cfg.TLS.AutoTLSDomains) > 0:
+		return server.RunAutoTLS(cfg.TLS.AutoTLSDomains...)
+	default:
+		return server.RunWithGracefulShutdown(cfg.Server.Addr, cfg.Server.ShutdownTimeout)
+	}
+}
@@ -0,0 +1,164 @@
+package ant
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// paramConstraint 路径参数的约束，例如 {id:int}
+type paramConstraint struct {
+	// name 参数名
+	name string
+	// kind 约束的名称，例如 "int"、"regexp"
+	kind string
+	// expr regexp 约束的表达式
+	expr string
+	// match 判断参数值是否满足约束，int 与 uint 约束同时返回转换后的数值
+	match func(val string) (pathNumber, bool)
+}
+
+// pathNumber 通过 int 或 uint 约束转换后的路径参数
+type pathNumber struct {
+	kind byte // 0 表示不是数值，'i' 为 int，'u' 为 uint
+	i    int64
+	u    uint64
+}
+
+// typedParam 命中路由时转换好的路径参数，使 PathValue 的数值转换不需要重复解析
+type typedParam struct {
+	name string
+	num  pathNumber
+}
+
+// maxTypedParams 每个请求最多保存的数值参数个数，超出时 PathValue 退回到现场解析
+const maxTypedParams = 8
+
+// matchString 将只判断字符串的函数适配为 match
+func matchString(fn func(val string) bool) func(val string) (pathNumber, bool) {
+	return func(val string) (pathNumber, bool) {
+		return pathNumber{}, fn(val)
+	}
+}
+
+// uuidPattern uuid 约束使用的表达式
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// newParamConstraint 解析约束
+// 支持 int、uint、uuid、alpha（只包含字母）、alnum（只包含字母与数字）与 regexp(表达式)
+func newParamConstraint(name, spec string) (paramConstraint, error) {
+	c := paramConstraint{name: name, kind: spec}
+	switch spec {
+	case "int":
+		c.match = func(val string) (pathNumber, bool) {
+			n, err := strconv.ParseInt(val, 10, 64)
+			return pathNumber{kind: 'i', i: n}, err == nil
+		}
+	case "uint":
+		c.match = func(val string) (pathNumber, bool) {
+			n, err := strconv.ParseUint(val, 10, 64)
+			return pathNumber{kind: 'u', u: n}, err == nil
+		}
+	case "uuid":
+		c.match = matchString(uuidPattern.MatchString)
+	case "alpha":
+		c.match = matchString(func(val string) bool {
+			return val != "" && strings.IndexFunc(val, func(r rune) bool { return !isASCIILetter(r) }) < 0
+		})
+	case "alnum":
+		c.match = matchString(func(val string) bool {
+			return val != "" && strings.IndexFunc(val, func(r rune) bool { return !isASCIILetter(r) && (r < '0' || r > '9') }) < 0
+		})
+	default:
+		expr, ok := strings.CutPrefix(spec, "regexp(")
+		if !ok || !strings.HasSuffix(expr, ")") {
+			return c, fmt.Errorf("web: 未知的路径参数约束 %q", spec)
+		}
+		c.kind, c.expr = "regexp", strings.TrimSuffix(expr, ")")
+		// 表达式需要匹配整个参数值
+		re, err := regexp.Compile("^(?:" + c.expr + ")$")
+		if err != nil {
+			return c, fmt.Errorf("web: 路径参数 %s 的约束不合法: %w", name, err)
+		}
+		c.match = matchString(re.MatchString)
+	}
+	return c, nil
+}
+
+func isASCIILetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// schema 返回约束对应的文档结构
+func (c paramConstraint) schema() *Schema {
+	switch c.kind {
+	case "int", "uint":
+		s := &Schema{Type: "integer", Format: "int64"}
+		if c.kind == "uint" {
+			s.Minimum = new(float64)
+		}
+		return s
+	case "uuid":
+		return &Schema{Type: "string", Format: "uuid"}
+	case "alpha":
+		return &Schema{Type: "string", Pattern: "^[A-Za-z]+$"}
+	case "alnum":
+		return &Schema{Type: "string", Pattern: "^[A-Za-z0-9]+$"}
+	default:
+		return &Schema{Type: "string", Pattern: "^(?:" + c.expr + ")$"}
+	}
+}
+
+// routeKey 返回去掉约束后的路由模式，用于按注册时的模式查找路由
+func routeKey(pattern string) string {
+	if key, _, err := parseConstraints(pattern); err == nil {
+		return key
+	}
+	return pattern
+}
+
+// parseConstraints 从路由模式中去掉路径参数的约束
+// 例如 "GET /users/{id:int}" 返回 "GET /users/{id}" 与 id 的约束
+// 约束中的表达式可以包含成对的花括号，例如 {code:regexp([0-9]{3})}
+// 没有约束的路由模式原样返回，交给 http.ServeMux 校验
+func parseConstraints(pattern string) (string, []paramConstraint, error) {
+	if !strings.Contains(pattern, ":") {
+		return pattern, nil, nil
+	}
+	var sb strings.Builder
+	var constraints []paramConstraint
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '{' {
+			sb.WriteByte(pattern[i])
+			continue
+		}
+		// 找到配对的右花括号
+		end, depth := -1, 0
+		for j := i; j < len(pattern) && end < 0; j++ {
+			switch pattern[j] {
+			case '{':
+				depth++
+			case '}':
+				if depth--; depth == 0 {
+					end = j
+				}
+			}
+		}
+		if end < 0 {
+			sb.WriteString(pattern[i:])
+			break
+		}
+		name, spec, ok := strings.Cut(pattern[i+1:end], ":")
+		if ok {
+			c, err := newParamConstraint(strings.TrimSuffix(name, "..."), spec)
+			if err != nil {
+				return "", nil, err
+			}
+			constraints = append(constraints, c)
+		}
+		sb.WriteString("{" + name + "}")
+		i = end
+	}
+	return sb.String(), constraints, nil
+}
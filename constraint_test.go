@@ -0,0 +1,124 @@
+package ant
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestPathParamConstraints(t *testing.T) {
+	server := NewHTTPServer()
+	echo := func(ctx *Context) { ctx.RespData = []byte(ctx.Req.Pattern) }
+	server.Handle("GET /users/{id:int}", echo)
+	server.Handle("GET /orders/{id:uuid}", echo)
+	server.Handle("GET /codes/{code:regexp([0-9]{3})}", echo)
+	server.Handle("GET /files/{path...:regexp(.*\\.png)}", echo)
+	server.Handle("GET /tags/{name:alpha}/{n:uint}", echo)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/users/42", http.StatusOK},
+		{"/users/-1", http.StatusOK},
+		{"/users/abc", http.StatusNotFound},
+		{"/orders/0f8fad5b-d9cb-469f-a165-70867728950e", http.StatusOK},
+		{"/orders/123", http.StatusNotFound},
+		{"/codes/404", http.StatusOK},
+		{"/codes/4040", http.StatusNotFound},
+		{"/files/a/b/logo.png", http.StatusOK},
+		{"/files/a/b/logo.jpg", http.StatusNotFound},
+		{"/tags/go/3", http.StatusOK},
+		{"/tags/go1/3", http.StatusNotFound},
+		{"/tags/go/-3", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s 应响应 %d，实际为 %d", tt.path, tt.want, w.Code)
+		}
+	}
+
+	// 注册的路由模式不包含约束，通过带约束的模式同样可以查找
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if w.Body.String() != "GET /users/{id}" {
+		t.Errorf("ctx.Req.Pattern 不应包含约束，实际为 %s", w.Body.String())
+	}
+	server.Describe("GET /users/{id:int}", RouteMeta{Summary: "获取用户"})
+	if meta, ok := server.RouteMeta("GET /users/{id}"); !ok || meta.Summary != "获取用户" {
+		t.Errorf("带约束的模式应对应同一个路由，实际为 %+v", meta)
+	}
+
+	doc := server.OpenAPI(OpenAPIInfo{Title: "t", Version: "1"})
+	if p := doc.Paths["/users/{id}"]["get"].Parameters[0]; p.Schema.Type != "integer" {
+		t.Errorf("int 约束的参数应生成为 integer，实际为 %+v", p.Schema)
+	}
+	if p := doc.Paths["/codes/{code}"]["get"].Parameters[0]; p.Schema.Pattern != "^(?:[0-9]{3})$" {
+		t.Errorf("regexp 约束的参数应生成 pattern，实际为 %+v", p.Schema)
+	}
+
+	for _, pattern := range []string{"GET /a/{id:float}", "GET /b/{id:regexp([)}"} {
+		if err := catchPanic(func() { server.Handle(pattern, echo) }); err == nil {
+			t.Errorf("不合法的约束 %s 应 panic", pattern)
+		}
+	}
+}
+
+// TestTypedPathParams 测试约束转换后的路径参数与 JSONHandler 的 path 字段绑定
+func TestTypedPathParams(t *testing.T) {
+	type getOrderReq struct {
+		UserID  int64  `json:"-" path:"uid"`
+		OrderNo uint32 `json:"-" path:"no"`
+		Tag     string `json:"-" path:"tag"`
+		Missing int    `json:"-" path:"missing"`
+	}
+	server := NewHTTPServer()
+	server.Handle("GET /big/{n:uint}", func(ctx *Context) {
+		n, err := ctx.PathValue("n").ToUint64()
+		if err != nil {
+			t.Error(err)
+		}
+		if _, err = ctx.PathValue("n").ToInt64(); err == nil {
+			t.Error("超出 int64 范围的值转换为 int64 时应返回错误")
+		}
+		ctx.RespData = []byte(strconv.FormatUint(n, 10))
+	})
+	server.Handle("GET /users/{uid:int}/orders/{no}/{tag}", JSONHandler(func(ctx *Context, req getOrderReq) (map[string]any, error) {
+		if n, err := ctx.PathValue("uid").ToInt64(); err != nil || n != req.UserID {
+			t.Errorf("PathValue 应返回转换后的值，实际为 %d %v", n, err)
+		}
+		return map[string]any{"uid": req.UserID, "no": req.OrderNo, "tag": req.Tag, "missing": req.Missing}, nil
+	}))
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/big/18446744073709551615", nil))
+	if w.Body.String() != "18446744073709551615" {
+		t.Errorf("uint 约束的参数转换不正确: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/-7/orders/12/vip", nil))
+	var got struct {
+		UID     int64  `json:"uid"`
+		No      uint32 `json:"no"`
+		Tag     string `json:"tag"`
+		Missing int    `json:"missing"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || got.UID != -7 || got.No != 12 || got.Tag != "vip" || got.Missing != 0 {
+		t.Errorf("path 字段绑定不正确，实际为 %d %+v", w.Code, got)
+	}
+
+	// 没有约束的参数在绑定时校验，超出字段范围时响应 400
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1/orders/4294967296/vip", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("参数超出字段范围时应响应 400，实际为 %d", w.Code)
+	}
+}
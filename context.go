@@ -2,15 +2,19 @@ package ant
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Context 封装HTTP请求上下文，提供请求处理相关工具方法
 // 包含原始请求/响应对象，缓存数据及响应状态信息
+// 注意：Context 由服务器复用，请求处理完成后不应在其他 goroutine 中继续持有，
+// 需要异步使用的数据（例如 UserValues 中的值、RespData）应先复制
 type Context struct {
 	Req  *http.Request       // 原始HTTP请求对象
 	Resp http.ResponseWriter // HTTP响应写入器
@@ -27,6 +31,42 @@ type Context struct {
 
 	// 用户相关的数据，用于在请求处理过程中存储临时数据
 	UserValues map[string]any
+
+	// Err 处理函数返回的错误，由 JSONHandler 等适配器设置，错误处理中间件可以据此上报原始错误
+	Err error
+
+	// hijacked 连接是否已被接管（例如升级为WebSocket），接管后不再回写响应
+	hijacked bool
+
+	// trustedProxies 受信任的代理，用于解析客户端真实IP
+	trustedProxies *TrustedProxies
+
+	// buf 可复用的响应缓冲区
+	buf []byte
+
+	// paramNames 命中路由的参数名，在注册路由时解析
+	paramNames *[]string
+
+	// typedParams 通过 int 与 uint 约束转换好的路径参数
+	typedParams []typedParam
+
+	// jsonCodec JSON编解码器，为nil时使用标准库
+	jsonCodec JSONCodec
+
+	// binders 通过 RegisterBinder 注册的绑定器
+	binders map[string]BinderFunc
+
+	// features 功能开关服务
+	features *FeatureFlags
+
+	// templateValidators 是否为 RespTemplate 渲染的页面生成缓存验证器，通过 TemplateValidators 开启
+	templateValidators bool
+
+	// multipartForm 通过 MultipartForm 解析的表单，请求结束后删除其中的临时文件
+	multipartForm *MultipartForm
+
+	// rw 包装后的响应写入器，通过服务器处理的请求中 Resp 指向它
+	rw responseWriter
 }
 
 // BindJSON 解析请求体中的JSON数据并绑定到指定结构体
@@ -37,7 +77,7 @@ func (c *Context) BindJSON(val any) error {
 	if c.Req.Body == nil {
 		return errors.New("web: body 为 nil")
 	}
-	decoder := json.NewDecoder(c.Req.Body)
+	decoder := c.JSONCodec().NewDecoder(c.Req.Body)
 	decoder.DisallowUnknownFields() // 禁止未知字段
 	return decoder.Decode(val)
 }
@@ -47,6 +87,8 @@ func (c *Context) BindJSON(val any) error {
 type StringValue struct {
 	val string
 	err error
+	// num 路由约束已经转换好的数值
+	num pathNumber
 }
 
 // String 获取原始字符串值及可能存在的错误
@@ -56,13 +98,36 @@ func (s StringValue) String() (string, error) {
 
 // ToInt64 将字符串值转换为int64类型
 // 返回值: 转换成功返回整数值，失败返回错误（包含原始错误或转换错误）
+// 路径参数使用了 {id:int} 等约束时直接返回命中路由时转换好的值
 func (s StringValue) ToInt64() (int64, error) {
 	if s.err != nil {
 		return 0, s.err
 	}
+	switch {
+	case s.num.kind == 'i':
+		return s.num.i, nil
+	case s.num.kind == 'u' && s.num.u <= math.MaxInt64:
+		return int64(s.num.u), nil
+	}
 	return strconv.ParseInt(s.val, 10, 64)
 }
 
+// ToUint64 将字符串值转换为uint64类型
+// 返回值: 转换成功返回整数值，失败返回错误（包含原始错误或转换错误）
+// 路径参数使用了 {id:uint} 约束时直接返回命中路由时转换好的值
+func (s StringValue) ToUint64() (uint64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	switch {
+	case s.num.kind == 'u':
+		return s.num.u, nil
+	case s.num.kind == 'i' && s.num.i >= 0:
+		return uint64(s.num.i), nil
+	}
+	return strconv.ParseUint(s.val, 10, 64)
+}
+
 // FormValue 从POST表单中获取指定键的值
 // key: 表单字段名称
 // 返回值: 封装后的字符串值结构，包含值或错误信息
@@ -139,6 +204,11 @@ func (c *Context) PathValue(key string) StringValue {
 	if value == "" {
 		return StringValue{err: errors.New("web: 找不到这个 key")}
 	}
+	for _, p := range c.typedParams {
+		if p.name == key {
+			return StringValue{val: value, num: p.num}
+		}
+	}
 
 	return StringValue{val: value}
 }
@@ -154,7 +224,7 @@ func (c *Context) SetCookie(cookie *http.Cookie) {
 // val: 需要序列化的数据结构
 // 返回值: 序列化或写入响应时发生的错误
 func (c *Context) RespJSON(code int, val any) error {
-	bs, err := json.Marshal(val)
+	bs, err := c.JSONCodec().Marshal(val)
 	if err != nil {
 		return err
 	}
@@ -175,16 +245,33 @@ func (c *Context) RespJSONOK(val any) error {
 // tplName: 模板名称
 // data: 渲染数据
 // 返回值: 渲染过程中的错误
+// 路由使用了 TemplateValidators 中间件时会设置 ETag 与 Last-Modified，页面没有变化时响应 304
 func (c *Context) RespTemplate(tplName string, data any) error {
 	if c.TemplateEngine == nil {
 		return errors.New("web: 未设置模板引擎")
 	}
 
+	// 数据没有变化时直接响应 304，不需要渲染
+	var etag string
+	if c.templateValidators {
+		var notModified bool
+		if etag, notModified = c.checkTemplateValidators(tplName, data); notModified {
+			return nil
+		}
+	}
+
 	// 渲染模板
 	bs, err := c.TemplateEngine.Render(context.Background(), tplName, data)
 	if err != nil {
 		return err
 	}
+	if c.templateValidators && etag == "" {
+		// 数据无法序列化时按渲染结果生成 ETag
+		etag = contentETag(bs)
+		if c.writeNotModified(etag, time.Time{}) {
+			return nil
+		}
+	}
 
 	// 设置状态码和响应数据
 	c.RespStatusCode = http.StatusOK
@@ -200,7 +287,73 @@ func (c *Context) RespTemplate(tplName string, data any) error {
 }
 
 // WriteString 将字符串写入响应体
+// 注意：数据写入可复用的缓冲区，请求结束后 RespData 不再有效
 func (c *Context) WriteString(data string) error {
-	c.RespData = []byte(data)
+	c.buf = append(c.buf[:0], data...)
+	c.RespData = c.buf
 	return nil
 }
+
+// PathParam 单个路径参数
+type PathParam struct {
+	Key   string
+	Value string
+}
+
+// PathParams 返回当前请求命中路由的全部路径参数
+// 返回值: 参数名到参数值的映射，路由不含参数时返回nil
+// 注意：每次调用都会创建新的 map，热点路径上可以使用 AppendPathParams
+func (c *Context) PathParams() map[string]string {
+	names := c.pathParamNames()
+	if len(names) == 0 {
+		return nil
+	}
+	params := make(map[string]string, len(names))
+	for _, name := range names {
+		params[name] = c.Req.PathValue(name)
+	}
+	return params
+}
+
+// AppendPathParams 将当前请求的路径参数追加到 dst 中并返回
+// 按路由模式中的出现顺序追加，dst 容量足够时不产生内存分配
+// 例如：
+//
+//	var buf [4]ant.PathParam
+//	params := ctx.AppendPathParams(buf[:0])
+func (c *Context) AppendPathParams(dst []PathParam) []PathParam {
+	for _, name := range c.pathParamNames() {
+		dst = append(dst, PathParam{Key: name, Value: c.Req.PathValue(name)})
+	}
+	return dst
+}
+
+// pathParamNames 返回命中路由的参数名
+// 通过服务器处理的请求使用注册路由时解析好的结果，否则现场解析
+func (c *Context) pathParamNames() []string {
+	if c.paramNames != nil {
+		return *c.paramNames
+	}
+	return patternParamNames(c.Req.Pattern)
+}
+
+// patternParamNames 解析路由模式中的通配符名称
+// 例如 "GET /users/{id}/files/{path...}" 返回 ["id", "path"]，{$} 不计入
+func patternParamNames(pattern string) []string {
+	var names []string
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			return names
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			return names
+		}
+		name := strings.TrimSuffix(pattern[start+1:start+end], "...")
+		if name != "" && name != "$" {
+			names = append(names, name)
+		}
+		pattern = pattern[start+end+1:]
+	}
+}
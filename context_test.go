@@ -584,3 +584,87 @@ func TestContextRespTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestContextPathParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    map[string]string
+	}{
+		{name: "无参数", pattern: "GET /users", path: "/users", want: nil},
+		{name: "单个参数", pattern: "GET /users/{id}", path: "/users/1", want: map[string]string{"id": "1"}},
+		{
+			name:    "多段通配符",
+			pattern: "/users/{id}/files/{path...}",
+			path:    "/users/1/files/a/b",
+			want:    map[string]string{"id": "1", "path": "a/b"},
+		},
+		{name: "精确匹配标记", pattern: "GET /posts/{$}", path: "/posts/", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]string
+			server := NewHTTPServer()
+			server.Handle(tt.pattern, func(ctx *Context) {
+				got = ctx.PathParams()
+			})
+			server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestContextPoolReset 测试复用的请求上下文不会残留上一个请求的数据
+func TestContextPoolReset(t *testing.T) {
+	server := NewHTTPServer()
+	server.Handle("GET /set", func(ctx *Context) {
+		if ctx.UserValues == nil {
+			ctx.UserValues = make(map[string]any)
+		}
+		ctx.UserValues["user"] = "tom"
+		ctx.RespStatusCode = http.StatusCreated
+		_ = ctx.WriteString("set")
+	})
+	server.Handle("GET /get", func(ctx *Context) {
+		if _, ok := ctx.UserValues["user"]; ok {
+			t.Error("UserValues 残留了上一个请求的数据")
+		}
+		if ctx.RespStatusCode != 0 || len(ctx.RespData) != 0 {
+			t.Errorf("响应数据残留: %d %q", ctx.RespStatusCode, ctx.RespData)
+		}
+	})
+
+	for i := 0; i < 10; i++ {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/set", nil))
+		if rec.Code != http.StatusCreated || rec.Body.String() != "set" {
+			t.Fatalf("响应不正确: %d %q", rec.Code, rec.Body.String())
+		}
+		server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/get", nil))
+	}
+}
+
+// TestContextAppendPathParams 测试按路由顺序追加路径参数
+func TestContextAppendPathParams(t *testing.T) {
+	var got []PathParam
+	server := NewHTTPServer()
+	server.Handle("GET /users/{id}/files/{path...}", func(ctx *Context) {
+		var buf [4]PathParam
+		got = append(got, ctx.AppendPathParams(buf[:0])...)
+	})
+	server.Handle("GET /health", func(ctx *Context) {
+		if params := ctx.AppendPathParams(nil); params != nil {
+			t.Errorf("不含参数的路由期望返回nil, 得到 %v", params)
+		}
+	})
+
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42/files/a/b.txt", nil))
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	want := []PathParam{{Key: "id", Value: "42"}, {Key: "path", Value: "a/b.txt"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("期望 %v, 得到 %v", want, got)
+	}
+}
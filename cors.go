@@ -0,0 +1,83 @@
+package ant
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CORSPolicy 跨域资源共享策略，由 middleware/cors 使用
+// 作为全局策略传给 cors.NewMiddlewareBuilder，或通过 RouteMeta.CORS 与 RouterGroup.CORS 覆盖部分路由的全局策略
+type CORSPolicy struct {
+	// AllowOrigins 允许的来源，例如 "https://example.com"，"*" 表示任意来源，
+	// "https://*.example.com" 匹配任意子域名，为空时拒绝全部跨域请求
+	AllowOrigins []string `json:"allowOrigins,omitempty"`
+	// AllowMethods 预检请求允许的方法，为空时允许 GET、HEAD、POST、PUT、PATCH 与 DELETE
+	AllowMethods []string `json:"allowMethods,omitempty"`
+	// AllowHeaders 预检请求允许的请求头，不区分大小写，"*" 表示任意请求头（不能与 AllowCredentials 同时使用）
+	AllowHeaders []string `json:"allowHeaders,omitempty"`
+	// ExposeHeaders 允许浏览器中的脚本读取的响应头
+	ExposeHeaders []string `json:"exposeHeaders,omitempty"`
+	// AllowCredentials 是否允许携带 Cookie 等凭据，只对 AllowOrigins 中明确列出的来源生效，
+	// 只被 "*" 匹配的来源仍然响应 "*" 且不携带凭据
+	AllowCredentials bool `json:"allowCredentials,omitempty"`
+	// MaxAge 浏览器缓存预检结果的时间，为0时不发送
+	MaxAge time.Duration `json:"maxAge,omitempty"`
+}
+
+// defaultCORSMethods AllowMethods 为空时允许的方法
+var defaultCORSMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// AllowsOrigin 判断是否允许来源 origin 的跨域请求
+func (p CORSPolicy) AllowsOrigin(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	return slices.ContainsFunc(p.AllowOrigins, func(o string) bool {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+		prefix, suffix, ok := strings.Cut(o, "*")
+		return ok && len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+			strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix))
+	})
+}
+
+// AllowsMethod 判断预检请求中的方法是否被允许
+func (p CORSPolicy) AllowsMethod(method string) bool {
+	return slices.Contains(p.Methods(), method)
+}
+
+// AllowsHeader 判断预检请求中的请求头是否被允许
+func (p CORSPolicy) AllowsHeader(header string) bool {
+	if !p.AllowCredentials && slices.Contains(p.AllowHeaders, "*") {
+		return true
+	}
+	return slices.ContainsFunc(p.AllowHeaders, func(h string) bool {
+		return strings.EqualFold(h, header)
+	})
+}
+
+// Methods 返回预检请求允许的方法，AllowMethods 为空时返回默认的方法
+func (p CORSPolicy) Methods() []string {
+	if len(p.AllowMethods) == 0 {
+		return defaultCORSMethods
+	}
+	return p.AllowMethods
+}
+
+// MatchRoute 返回以 method 方法请求 r 的地址时命中的路由模式，没有命中时返回 false
+// 用于 CORS 预检等需要知道实际请求会命中哪个路由的场景，路径参数的约束不会被检查
+func (s *HTTPServer) MatchRoute(method string, r *http.Request) (string, bool) {
+	req := *r
+	req.Method = method
+	_, pattern := s.mux.Handler(&req)
+	if pattern == "" {
+		return "", false
+	}
+	return pattern, slices.Contains(s.Routes(), pattern)
+}
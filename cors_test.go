@@ -0,0 +1,74 @@
+package ant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSPolicyAllowsOrigin(t *testing.T) {
+	policy := CORSPolicy{AllowOrigins: []string{"https://example.com", "https://*.partner.com"}}
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{origin: "https://example.com", want: true},
+		{origin: "HTTPS://EXAMPLE.COM", want: true},
+		{origin: "https://api.partner.com", want: true},
+		{origin: "https://.partner.com"},
+		{origin: "https://partner.com"},
+		{origin: "https://evil.com/https://example.com"},
+		{origin: ""},
+	}
+	for _, tt := range tests {
+		if got := policy.AllowsOrigin(tt.origin); got != tt.want {
+			t.Errorf("%q 期望 %v, 得到 %v", tt.origin, tt.want, got)
+		}
+	}
+	if !(CORSPolicy{AllowOrigins: []string{"*"}}).AllowsOrigin("https://a.com") {
+		t.Error("* 应允许任意来源")
+	}
+	if (CORSPolicy{}).AllowsOrigin("https://a.com") {
+		t.Error("AllowOrigins 为空时应拒绝全部来源")
+	}
+}
+
+func TestMatchRoute(t *testing.T) {
+	s := NewHTTPServer()
+	s.Handle("GET /users/{id:int}", func(ctx *Context) {})
+	s.Handle("POST /users", func(ctx *Context) {})
+
+	req := httptest.NewRequest(http.MethodOptions, "/users/1", nil)
+	if pattern, ok := s.MatchRoute(http.MethodGet, req); !ok || pattern != "GET /users/{id}" {
+		t.Errorf("应命中 GET /users/{id}，实际为 %q %v", pattern, ok)
+	}
+	if _, ok := s.MatchRoute(http.MethodDelete, req); ok {
+		t.Error("没有注册的方法不应命中")
+	}
+	if req.Method != http.MethodOptions {
+		t.Error("不应修改原请求")
+	}
+}
+
+// TestGroupCORS 测试分组的跨域策略写入组内路由的描述信息
+func TestGroupCORS(t *testing.T) {
+	s := NewHTTPServer()
+	partner := s.Group("/partner")
+	partner.CORS(CORSPolicy{AllowOrigins: []string{"https://partner.com"}})
+	partner.Handle("GET /orders", func(ctx *Context) {})
+	partner.Group("/v2").Handle("GET /orders", func(ctx *Context) {})
+	partner.Describe("GET /internal", RouteMeta{CORS: &CORSPolicy{}})
+	s.Group("/public").Handle("GET /items", func(ctx *Context) {})
+
+	for _, pattern := range []string{"GET /partner/orders", "GET /partner/v2/orders"} {
+		if meta, _ := s.RouteMeta(pattern); meta.CORS == nil || meta.CORS.AllowOrigins[0] != "https://partner.com" {
+			t.Errorf("%s 应使用分组的策略，实际为 %+v", pattern, meta.CORS)
+		}
+	}
+	if meta, _ := s.RouteMeta("GET /partner/internal"); meta.CORS == nil || len(meta.CORS.AllowOrigins) != 0 {
+		t.Errorf("单独设置的策略不应被覆盖，实际为 %+v", meta.CORS)
+	}
+	if meta, _ := s.RouteMeta("GET /public/items"); meta.CORS != nil {
+		t.Errorf("没有设置策略的分组不应写入策略，实际为 %+v", meta.CORS)
+	}
+}
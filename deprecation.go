@@ -0,0 +1,99 @@
+package ant
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Deprecation 路由的弃用信息，设置在 RouteMeta.Deprecation 中
+// 命中路由的响应自动加上 Deprecation（RFC 9745）、Sunset（RFC 8594）与 Link 响应头，
+// 接口文档中的操作标记为 deprecated
+//
+//	server.Describe("GET /v1/users", ant.RouteMeta{Deprecation: &ant.Deprecation{
+//		Since:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+//		Sunset:    time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+//		Successor: "/v2/users",
+//	}})
+type Deprecation struct {
+	// Since 开始弃用的时间，可以是将来的时间，为零值时不发送 Deprecation 响应头
+	Since time.Time `json:"since,omitzero"`
+	// Sunset 停止服务的时间，为零值时不发送 Sunset 响应头
+	Sunset time.Time `json:"sunset,omitzero"`
+	// Successor 替代接口的地址，以 rel="successor-version" 的 Link 响应头发送
+	Successor string `json:"successor,omitempty"`
+	// Policy 弃用说明文档的地址，以 rel="deprecation" 的 Link 响应头发送
+	Policy string `json:"policy,omitempty"`
+}
+
+// deprecationHeaders 预先格式化的弃用响应头，避免每个请求重复格式化
+type deprecationHeaders struct {
+	deprecation string
+	sunset      string
+	links       []string
+}
+
+// headers 格式化弃用响应头
+func (d *Deprecation) headers() *deprecationHeaders {
+	h := &deprecationHeaders{}
+	if !d.Since.IsZero() {
+		// RFC 9745 使用结构化字段中的日期，即 @ 加上 Unix 时间戳
+		h.deprecation = "@" + strconv.FormatInt(d.Since.Unix(), 10)
+	}
+	if !d.Sunset.IsZero() {
+		h.sunset = d.Sunset.UTC().Format(http.TimeFormat)
+	}
+	if d.Successor != "" {
+		h.links = append(h.links, "<"+d.Successor+`>; rel="successor-version"`)
+	}
+	if d.Policy != "" {
+		h.links = append(h.links, "<"+d.Policy+`>; rel="deprecation"; type="text/html"`)
+	}
+	return h
+}
+
+// write 将弃用响应头写入 header
+func (h *deprecationHeaders) write(header http.Header) {
+	if h.deprecation != "" {
+		header.Set("Deprecation", h.deprecation)
+	}
+	if h.sunset != "" {
+		header.Set("Sunset", h.sunset)
+	}
+	for _, link := range h.links {
+		header.Add("Link", link)
+	}
+}
+
+// syncDeprecation 在路由的描述信息变化后更新弃用响应头，调用方需要持有 routeMu
+func (t *routeTable) syncDeprecation(pattern string) {
+	p, ok := t.deprecations[pattern]
+	if !ok {
+		return
+	}
+	if d := t.meta[pattern].Deprecation; d != nil {
+		p.Store(d.headers())
+		return
+	}
+	p.Store(nil)
+}
+
+// DeprecationNote 返回路由弃用情况的简短说明，用于路由表等展示场景
+// 例如 "[已弃用，2025-07-01 下线，替代接口 /v2/users]"，没有弃用时返回空字符串
+func (m RouteMeta) DeprecationNote() string {
+	d := m.Deprecation
+	if d == nil {
+		if m.Deprecated {
+			return "[已弃用]"
+		}
+		return ""
+	}
+	note := "[已弃用"
+	if !d.Sunset.IsZero() {
+		note += "，" + d.Sunset.UTC().Format(time.DateOnly) + " 下线"
+	}
+	if d.Successor != "" {
+		note += "，替代接口 " + d.Successor
+	}
+	return note + "]"
+}
@@ -0,0 +1,88 @@
+package ant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDeprecationHeaders 测试弃用路由的响应头，以及修改描述信息后立即生效
+func TestDeprecationHeaders(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2025, 7, 1, 0, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	s := NewHTTPServer()
+	// 注册之前设置的弃用信息同样生效
+	s.Describe("GET /v1/users", RouteMeta{Summary: "用户列表", Deprecation: &Deprecation{
+		Since: since, Sunset: sunset, Successor: "/v2/users", Policy: "https://example.com/deprecation",
+	}})
+	s.Handle("GET /v1/users", func(ctx *Context) { ctx.RespData = []byte("ok") })
+	s.Handle("GET /v2/users", func(ctx *Context) { ctx.RespData = []byte("ok") })
+
+	get := func(path string) http.Header {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("期望状态码 200，实际 %d", w.Code)
+		}
+		return w.Header()
+	}
+
+	h := get("/v1/users")
+	if got := h.Get("Deprecation"); got != "@1735689600" {
+		t.Errorf("Deprecation 不正确: %q", got)
+	}
+	if got := h.Get("Sunset"); got != "Mon, 30 Jun 2025 16:00:00 GMT" {
+		t.Errorf("Sunset 不正确: %q", got)
+	}
+	if got := strings.Join(h.Values("Link"), ", "); got != `</v2/users>; rel="successor-version", <https://example.com/deprecation>; rel="deprecation"; type="text/html"` {
+		t.Errorf("Link 不正确: %q", got)
+	}
+	if h = get("/v2/users"); h.Get("Deprecation") != "" || h.Get("Sunset") != "" || h.Get("Link") != "" {
+		t.Errorf("没有弃用的路由不应包含弃用响应头: %v", h)
+	}
+
+	s.UpdateRouteMeta("GET /v2/users", func(meta *RouteMeta) {
+		meta.Deprecation = &Deprecation{Since: since}
+	})
+	if h = get("/v2/users"); h.Get("Deprecation") != "@1735689600" || h.Get("Sunset") != "" {
+		t.Errorf("UpdateRouteMeta 后应立即生效: %v", h)
+	}
+	s.UpdateRouteMeta("GET /v1/users", func(meta *RouteMeta) {
+		meta.Deprecation = nil
+	})
+	if h = get("/v1/users"); h.Get("Deprecation") != "" || h.Get("Link") != "" {
+		t.Errorf("取消弃用后不应包含弃用响应头: %v", h)
+	}
+}
+
+// TestDeprecationDocs 测试接口文档与路由表中的弃用标记
+func TestDeprecationDocs(t *testing.T) {
+	s := NewHTTPServer()
+	s.Handle("GET /v1/users", func(ctx *Context) {})
+	s.Handle("GET /v1/orders", func(ctx *Context) {})
+	s.Describe("GET /v1/users", RouteMeta{Summary: "用户列表", Deprecation: &Deprecation{
+		Sunset: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), Successor: "/v2/users",
+	}})
+	s.Describe("GET /v1/orders", RouteMeta{Deprecated: true})
+
+	doc := s.OpenAPI(OpenAPIInfo{Title: "test", Version: "1.0"})
+	op := doc.Paths["/v1/users"]["get"]
+	if !op.Deprecated || op.XSunset != "2025-07-01T00:00:00Z" || op.XSuccessor != "/v2/users" {
+		t.Errorf("接口文档中的弃用信息不正确: %+v", op)
+	}
+	if op = doc.Paths["/v1/orders"]["get"]; !op.Deprecated || op.XSunset != "" {
+		t.Errorf("Deprecated 应标记为弃用: %+v", op)
+	}
+
+	var sb strings.Builder
+	if err := s.PrintRoutes(&sb); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"用户列表 [已弃用，2025-07-01 下线，替代接口 /v2/users]", "[已弃用]"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("路由表应包含 %q:\n%s", want, sb.String())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package ant
+
+import (
+	"expvar"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	rpprof "runtime/pprof"
+	"strings"
+	"time"
+)
+
+// gcStats 运行时GC与内存统计信息的JSON表示
+type gcStats struct {
+	NumGC         int64           `json:"num_gc"`
+	LastGC        time.Time       `json:"last_gc"`
+	PauseTotal    time.Duration   `json:"pause_total"`
+	RecentPauses  []time.Duration `json:"recent_pauses"`
+	HeapAlloc     uint64          `json:"heap_alloc"`
+	HeapSys       uint64          `json:"heap_sys"`
+	HeapObjects   uint64          `json:"heap_objects"`
+	NextGC        uint64          `json:"next_gc"`
+	GCCPUFraction float64         `json:"gc_cpu_fraction"`
+	NumGoroutine  int             `json:"num_goroutine"`
+}
+
+// EnablePprof 挂载运行时诊断端点
+// prefix: 诊断端点的路径前缀，例如 "/debug"
+// guards: 保护诊断端点的中间件（通常为管理员鉴权中间件），按顺序执行
+// 注册的端点：
+// - GET {prefix}/pprof/: net/http/pprof 索引及各项 profile
+// - GET {prefix}/vars: expvar 变量
+// - GET {prefix}/gc: GC 与内存统计
+// - GET {prefix}/goroutines: 全部 goroutine 的调用栈
+// 注意：诊断端点会暴露进程内部信息，生产环境务必通过 guards 加以保护
+func (s *HTTPServer) EnablePprof(prefix string, guards ...Middleware) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		prefix = "/debug"
+	}
+
+	s.handle("GET "+prefix+"/pprof/{$}", wrapHandlerFunc(pprof.Index), guards...)
+	s.handle("GET "+prefix+"/pprof/cmdline", wrapHandlerFunc(pprof.Cmdline), guards...)
+	s.handle("GET "+prefix+"/pprof/profile", wrapHandlerFunc(pprof.Profile), guards...)
+	s.handle("GET "+prefix+"/pprof/symbol", wrapHandlerFunc(pprof.Symbol), guards...)
+	s.handle("POST "+prefix+"/pprof/symbol", wrapHandlerFunc(pprof.Symbol), guards...)
+	s.handle("GET "+prefix+"/pprof/trace", wrapHandlerFunc(pprof.Trace), guards...)
+	// pprof.Index 只识别 /debug/pprof/ 前缀，自定义前缀下的具名 profile 需要单独分发
+	s.handle("GET "+prefix+"/pprof/{name}", func(ctx *Context) {
+		pprof.Handler(ctx.Req.PathValue("name")).ServeHTTP(ctx.Resp, ctx.Req)
+	}, guards...)
+	s.handle("GET "+prefix+"/vars", wrapHandlerFunc(expvar.Handler().ServeHTTP), guards...)
+	s.handle("GET "+prefix+"/gc", handleGCStats, guards...)
+	s.handle("GET "+prefix+"/goroutines", handleGoroutineDump, guards...)
+}
+
+// wrapHandlerFunc 将标准库的处理函数适配为HandleFunc
+func wrapHandlerFunc(fn http.HandlerFunc) HandleFunc {
+	return func(ctx *Context) {
+		fn(ctx.Resp, ctx.Req)
+	}
+}
+
+// handleGCStats 以JSON格式输出GC与内存统计信息
+func handleGCStats(ctx *Context) {
+	var gc debug.GCStats
+	debug.ReadGCStats(&gc)
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	// 只保留最近的若干次暂停时间，避免响应过大
+	pauses := gc.Pause
+	if len(pauses) > 16 {
+		pauses = pauses[:16]
+	}
+
+	_ = ctx.RespJSONOK(gcStats{
+		NumGC:         gc.NumGC,
+		LastGC:        gc.LastGC,
+		PauseTotal:    gc.PauseTotal,
+		RecentPauses:  pauses,
+		HeapAlloc:     mem.HeapAlloc,
+		HeapSys:       mem.HeapSys,
+		HeapObjects:   mem.HeapObjects,
+		NextGC:        mem.NextGC,
+		GCCPUFraction: mem.GCCPUFraction,
+		NumGoroutine:  runtime.NumGoroutine(),
+	})
+}
+
+// handleGoroutineDump 以纯文本输出全部goroutine的调用栈
+func handleGoroutineDump(ctx *Context) {
+	ctx.Resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if err := rpprof.Lookup("goroutine").WriteTo(ctx.Resp, 2); err != nil {
+		log.Printf("导出goroutine失败: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package ant
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestEnablePprof 测试诊断端点的注册与访问
+func TestEnablePprof(t *testing.T) {
+	server := NewHTTPServer()
+	server.EnablePprof("/debug")
+
+	tests := []struct {
+		name         string
+		path         string
+		expectedBody string
+	}{
+		{name: "pprof索引", path: "/debug/pprof/", expectedBody: "goroutine"},
+		{name: "具名profile", path: "/debug/pprof/heap?debug=1", expectedBody: "heap profile"},
+		{name: "expvar变量", path: "/debug/vars", expectedBody: "memstats"},
+		{name: "goroutine调用栈", path: "/debug/goroutines", expectedBody: "goroutine"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("期望状态码 200, 得到 %d", rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedBody) {
+				t.Errorf("期望响应包含 %q", tt.expectedBody)
+			}
+		})
+	}
+
+	t.Run("GC统计", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/debug/gc", nil)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+
+		var stats gcStats
+		if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+			t.Fatalf("解析GC统计失败: %v", err)
+		}
+		if stats.NumGoroutine <= 0 {
+			t.Errorf("期望goroutine数量大于0, 得到 %d", stats.NumGoroutine)
+		}
+	})
+}
+
+// TestEnablePprofGuard 测试诊断端点受中间件保护
+func TestEnablePprofGuard(t *testing.T) {
+	server := NewHTTPServer()
+	guard := func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			if ctx.Req.Header.Get("X-Admin-Token") != "secret" {
+				ctx.RespStatusCode = http.StatusUnauthorized
+				return
+			}
+			next(ctx)
+		}
+	}
+	server.EnablePprof("/admin/", guard)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/gc", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("未授权请求期望状态码 401, 得到 %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/gc", nil)
+	req.Header.Set("X-Admin-Token", "secret")
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("授权请求期望状态码 200, 得到 %d", rec.Code)
+	}
+}
@@ -0,0 +1,84 @@
+package ant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// 通用的业务错误，处理函数可以直接返回或包装后返回，例如
+// fmt.Errorf("%w: 用户 %d", ant.ErrNotFound, id)
+// JSONHandler 与 Context.RespError 通过 ErrorStatus 将其映射为对应的HTTP状态码
+var (
+	// ErrValidation 参数不合法，对应 400
+	ErrValidation = errors.New("web: 参数不合法")
+	// ErrUnauthorized 未认证，对应 401
+	ErrUnauthorized = errors.New("web: 未登录")
+	// ErrForbidden 已认证但没有访问权限，对应 403
+	ErrForbidden = errors.New("web: 无权访问")
+	// ErrNotFound 资源不存在，对应 404
+	ErrNotFound = errors.New("web: 资源不存在")
+	// ErrAlreadyExists 资源已存在，对应 409
+	ErrAlreadyExists = errors.New("web: 资源已存在")
+	// ErrConflict 资源的状态冲突，例如版本号不匹配，对应 409
+	ErrConflict = errors.New("web: 资源冲突")
+	// ErrNotRunning 依赖的服务或组件没有运行，对应 503
+	ErrNotRunning = errors.New("web: 服务未运行")
+)
+
+// StatusClientClosedRequest 客户端在服务端响应之前关闭了连接，沿用 nginx 的非标准状态码 499
+const StatusClientClosedRequest = 499
+
+// errorStatuses 错误与HTTP状态码的对应关系，按顺序匹配
+var errorStatuses = []struct {
+	err  error
+	code int
+}{
+	{ErrValidation, http.StatusBadRequest},
+	{ErrInvalidPolicy, http.StatusBadRequest},
+	{ErrUnauthorized, http.StatusUnauthorized},
+	{ErrForbidden, http.StatusForbidden},
+	{ErrNotFound, http.StatusNotFound},
+	{ErrUnknownRoute, http.StatusNotFound},
+	{ErrAlreadyExists, http.StatusConflict},
+	{ErrConflict, http.StatusConflict},
+	{ErrUnsupportedMediaType, http.StatusUnsupportedMediaType},
+	{ErrNotRunning, http.StatusServiceUnavailable},
+	{context.Canceled, StatusClientClosedRequest},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout},
+}
+
+// ErrorStatus 返回错误对应的HTTP状态码
+// *HTTPError 使用其中的状态码，包装了通用业务错误时使用对应的状态码，其他错误返回 500
+// 请求被取消时返回 StatusClientClosedRequest，超过截止时间时返回 504
+// err 为 nil 时返回 200
+func ErrorStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return he.Code
+	}
+	for _, s := range errorStatuses {
+		if errors.Is(err, s.err) {
+			return s.code
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// RespError 按 ErrorStatus 将错误写入缓冲的响应，格式为 {"error": "..."}，并设置 ctx.Err
+// *HTTPError 返回其中的信息；其他 4xx 错误返回错误内容；取消与 5xx 错误只返回状态码的描述，不向客户端暴露错误内容
+// 响应只写入 ctx.RespStatusCode 与 ctx.RespData，因此可以被 errhandle 等错误处理中间件替换或上报
+func (c *Context) RespError(err error) {
+	c.respError(err)
+}
+
+// statusText 返回状态码的描述，包括 StatusClientClosedRequest
+func statusText(code int) string {
+	if code == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(code)
+}
@@ -0,0 +1,103 @@
+package ant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{err: nil, want: http.StatusOK},
+		{err: fmt.Errorf("%w: name", ErrValidation), want: http.StatusBadRequest},
+		{err: fmt.Errorf("%w: 用户 1", ErrNotFound), want: http.StatusNotFound},
+		{err: fmt.Errorf("%w: GET /x", ErrUnknownRoute), want: http.StatusNotFound},
+		{err: ErrAlreadyExists, want: http.StatusConflict},
+		{err: ErrConflict, want: http.StatusConflict},
+		{err: ErrNotRunning, want: http.StatusServiceUnavailable},
+		{err: ErrInvalidPolicy, want: http.StatusBadRequest},
+		{err: NewHTTPError(http.StatusTeapot, ""), want: http.StatusTeapot},
+		// HTTPError 优先于包装的业务错误
+		{err: &HTTPError{Code: http.StatusGone, Err: ErrNotFound}, want: http.StatusGone},
+		{err: fmt.Errorf("查询订单: %w", context.Canceled), want: StatusClientClosedRequest},
+		{err: context.DeadlineExceeded, want: http.StatusGatewayTimeout},
+		{err: errors.New("数据库连接失败"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := ErrorStatus(tt.err); got != tt.want {
+			t.Errorf("%v 应映射为 %d，实际为 %d", tt.err, tt.want, got)
+		}
+	}
+}
+
+func TestJSONHandlerErrorStatus(t *testing.T) {
+	server := NewHTTPServer()
+	server.Handle("GET /users/{id}", JSONHandler(func(ctx *Context, _ struct{}) (userResp, error) {
+		switch id := ctx.Req.PathValue("id"); id {
+		case "1":
+			return userResp{ID: 1}, nil
+		case "stopped":
+			return userResp{}, fmt.Errorf("查询用户: %w", ErrNotRunning)
+		case "broken":
+			return userResp{}, errors.New("连接串 user:pass@db")
+		default:
+			return userResp{}, fmt.Errorf("%w: 用户 %s", ErrNotFound, id)
+		}
+	}))
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{path: "/users/1", wantCode: http.StatusOK, wantBody: `{"id":1,"name":""}`},
+		{path: "/users/2", wantCode: http.StatusNotFound, wantBody: `{"error":"web: 资源不存在: 用户 2"}`},
+		{path: "/users/stopped", wantCode: http.StatusServiceUnavailable, wantBody: `{"error":"Service Unavailable"}`},
+		{path: "/users/broken", wantCode: http.StatusInternalServerError, wantBody: `{"error":"Internal Server Error"}`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.wantCode || w.Body.String() != tt.wantBody {
+			t.Errorf("%s 应响应 %d %s，实际为 %d %s", tt.path, tt.wantCode, tt.wantBody, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestJSONHandlerCanceled(t *testing.T) {
+	called := false
+	server := NewHTTPServer()
+	server.Handle("GET /report", JSONHandler(func(ctx *Context, _ struct{}) (string, error) {
+		called = true
+		return "", nil
+	}))
+	reqCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/report", nil).WithContext(reqCtx))
+	if called {
+		t.Error("请求已取消时不应调用处理函数")
+	}
+	if w.Code != StatusClientClosedRequest || w.Body.String() != `{"error":"Client Closed Request"}` {
+		t.Errorf("请求已取消时应响应 499，实际为 %d %s", w.Code, w.Body.String())
+	}
+
+	server.Handle("GET /slow", JSONHandler(func(ctx *Context, _ struct{}) (string, error) {
+		<-ctx.Req.Context().Done()
+		return "", fmt.Errorf("导出报表: %w", ctx.Req.Context().Err())
+	}))
+	reqCtx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(reqCtx))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("处理函数超时应响应 504，实际为 %d %s", w.Code, w.Body.String())
+	}
+}
@@ -9,4 +9,10 @@ require (
 	github.com/roovet/ant v0.0.0-00010101000000-000000000000
 )
 
-require github.com/hashicorp/golang-lru v1.0.2 // indirect
+require (
+	github.com/gorilla/websocket v1.5.3 // indirect
+	github.com/hashicorp/golang-lru v1.0.2 // indirect
+	golang.org/x/crypto v0.45.0 // indirect
+	golang.org/x/net v0.47.0 // indirect
+	golang.org/x/text v0.31.0 // indirect
+)
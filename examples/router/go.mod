@@ -4,11 +4,14 @@ go 1.24.0
 
 toolchain go1.24.2
 
+require github.com/justinwongcn/ant v0.0.1
+
 require (
-	github.com/hashicorp/golang-lru v1.0.2
-	github.com/justinwongcn/ant v0.0.1
+	github.com/gorilla/websocket v1.5.3 // indirect
+	github.com/hashicorp/golang-lru v1.0.2 // indirect
+	golang.org/x/crypto v0.45.0 // indirect
+	golang.org/x/net v0.47.0 // indirect
+	golang.org/x/text v0.31.0 // indirect
 )
 
-require github.com/patrickmn/go-cache v2.1.0+incompatible // indirect
-
 replace github.com/justinwongcn/ant => ../..
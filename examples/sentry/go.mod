@@ -0,0 +1,19 @@
+module github.com/justinwongcn/ant/examples/sentry
+
+go 1.25.0
+
+require (
+	github.com/getsentry/sentry-go v0.49.0
+	github.com/justinwongcn/ant v0.0.1
+)
+
+require (
+	github.com/gorilla/websocket v1.5.3 // indirect
+	github.com/hashicorp/golang-lru v1.0.2 // indirect
+	golang.org/x/crypto v0.45.0 // indirect
+	golang.org/x/net v0.47.0 // indirect
+	golang.org/x/sys v0.46.0 // indirect
+	golang.org/x/text v0.39.0 // indirect
+)
+
+replace github.com/justinwongcn/ant => ../..
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/justinwongcn/ant"
+	"github.com/justinwongcn/ant/middleware/errhandle"
+	"github.com/justinwongcn/ant/middleware/recovery"
+)
+
+// SentryReporter 基于 sentry-go 的 ant.ErrorReporter 实现
+type SentryReporter struct {
+	hub *sentry.Hub
+}
+
+// NewSentryReporter 创建 Sentry 错误上报实现
+func NewSentryReporter(hub *sentry.Hub) *SentryReporter {
+	return &SentryReporter{hub: hub}
+}
+
+// Report 实现 ant.ErrorReporter 接口
+func (r *SentryReporter) Report(_ context.Context, ev *ant.ErrorEvent) {
+	hub := r.hub.Clone()
+	hub.WithScope(func(scope *sentry.Scope) {
+		if ev.User != "" {
+			scope.SetUser(sentry.User{ID: ev.User})
+		}
+		if ev.SessionID != "" {
+			scope.SetTag("session_id", ev.SessionID)
+		}
+		for k, v := range ev.Tags {
+			scope.SetTag(k, v)
+		}
+		scope.SetContext("request", map[string]any{
+			"method":      ev.Method,
+			"url":         ev.URL,
+			"route":       ev.Route,
+			"remote_addr": ev.RemoteAddr,
+		})
+		if len(ev.Stack) > 0 {
+			scope.SetContext("stack", map[string]any{"trace": string(ev.Stack)})
+		}
+		hub.CaptureException(ev.Err)
+	})
+}
+
+func main() {
+	if err := sentry.Init(sentry.ClientOptions{Dsn: ""}); err != nil {
+		log.Fatal(err)
+	}
+	defer sentry.Flush(2 * time.Second)
+
+	reporter := NewSentryReporter(sentry.CurrentHub())
+
+	rb := recovery.NewMiddlewareBuilder()
+	rb.Reporter = reporter
+
+	server := ant.NewHTTPServer()
+	server.Use(
+		rb.Build(),
+		errhandle.NewMiddlewareBuilder().Reporter(reporter, nil).Build(),
+	)
+
+	server.Handle("GET /panic", func(ctx *ant.Context) {
+		panic("something went wrong")
+	})
+
+	if err := server.Run(":8080"); err != nil {
+		log.Fatal(err)
+	}
+}
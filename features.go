@@ -0,0 +1,502 @@
+package ant
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"html/template"
+	"log"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FeatureProvider 功能开关的来源
+type FeatureProvider interface {
+	// Load 加载全部功能开关
+	Load(ctx context.Context) (map[string]bool, error)
+}
+
+// StaticFeatures 内存中的功能开关
+type StaticFeatures map[string]bool
+
+// Load 实现 FeatureProvider 接口
+func (f StaticFeatures) Load(_ context.Context) (map[string]bool, error) {
+	return maps.Clone(f), nil
+}
+
+// FileFeatures 从 JSON 文件加载功能开关，文件格式为 {"new-checkout": true}
+type FileFeatures string
+
+// Load 实现 FeatureProvider 接口
+func (f FileFeatures) Load(_ context.Context) (map[string]bool, error) {
+	data, err := os.ReadFile(string(f))
+	if err != nil {
+		return nil, err
+	}
+	var flags map[string]bool
+	if err = json.Unmarshal(data, &flags); err != nil {
+		return nil, fmt.Errorf("web: 解析功能开关文件 %s 失败: %w", f, err)
+	}
+	return flags, nil
+}
+
+// RemoteFeatures 通过 HTTP GET 从远程服务加载功能开关，响应格式与 FileFeatures 相同
+type RemoteFeatures struct {
+	// URL 远程服务的地址
+	URL string
+	// Client 发送请求的客户端，为nil时使用 http.DefaultClient
+	Client *http.Client
+}
+
+// Load 实现 FeatureProvider 接口
+func (f RemoteFeatures) Load(ctx context.Context) (map[string]bool, error) {
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("web: 加载功能开关失败，状态码 %d", resp.StatusCode)
+	}
+	var flags map[string]bool
+	if err = json.NewDecoder(resp.Body).Decode(&flags); err != nil {
+		return nil, fmt.Errorf("web: 解析功能开关失败: %w", err)
+	}
+	return flags, nil
+}
+
+// FeatureEvent 功能开关的变更事件，用于审计
+type FeatureEvent struct {
+	// Name 功能名称
+	Name string `json:"name"`
+	// Enabled 变更后是否开启
+	Enabled bool `json:"enabled"`
+	// Previous 变更前是否开启
+	Previous bool `json:"previous"`
+	// Source 变更来源："provider" 表示重新加载，"admin" 表示通过管理接口或 Set 修改
+	Source string `json:"source"`
+	// Actor 执行变更的操作者，重新加载时为空
+	Actor string `json:"actor,omitempty"`
+	// Time 变更时间
+	Time time.Time `json:"time"`
+}
+
+// FeatureFlags 功能开关服务
+// 开关的值来自 FeatureProvider，可以在运行时通过 Set 覆盖，覆盖的值在重新加载后仍然保留，直到调用 Unset
+// 读取开关不加锁，可以在每个请求中调用
+type FeatureFlags struct {
+	provider FeatureProvider
+
+	mu        sync.Mutex
+	loaded    map[string]bool
+	overrides map[string]bool
+	// flags 合并后的开关快照
+	flags atomic.Pointer[map[string]bool]
+
+	onChange []*featureListener
+	// ActorFunc 从管理请求中获取操作者，默认为客户端IP
+	ActorFunc func(ctx *Context) string
+	// MaxListenerFailures 变更回调连续 panic 的次数达到该值后不再调用，默认为 5，为0时从不停用
+	MaxListenerFailures int
+	// ListenerErrorFunc 变更回调 panic 或被停用时调用，默认使用 log 输出
+	ListenerErrorFunc func(err error)
+
+	published      uint64
+	recentFailures []FeatureListenerFailure
+	// pending 等待分发给回调的事件，按发布顺序排列
+	pending []FeatureEvent
+	// dispatching 是否有 goroutine 正在分发事件
+	dispatching bool
+}
+
+// featureListener 变更事件的回调
+type featureListener struct {
+	fn       func(e FeatureEvent)
+	failures int
+	disabled bool
+
+	handled      uint64
+	totalFailed  uint64
+	totalLatency time.Duration
+	maxLatency   time.Duration
+	lastError    string
+}
+
+// maxRecentListenerFailures 保留的最近失败记录数
+const maxRecentListenerFailures = 20
+
+// FeatureListenerStats 变更回调的统计
+type FeatureListenerStats struct {
+	// Index 回调的序号，从1开始，与注册顺序一致
+	Index int `json:"index"`
+	// Handled 成功处理的事件数
+	Handled uint64 `json:"handled"`
+	// Failed panic 的总次数
+	Failed uint64 `json:"failed"`
+	// Disabled 是否已被停用
+	Disabled bool `json:"disabled"`
+	// AvgLatency 平均耗时
+	AvgLatency time.Duration `json:"avg_latency"`
+	// MaxLatency 最大耗时
+	MaxLatency time.Duration `json:"max_latency"`
+	// LastError 最近一次 panic 的内容
+	LastError string `json:"last_error,omitempty"`
+}
+
+// FeatureListenerFailure 变更回调的一次失败
+type FeatureListenerFailure struct {
+	// Index 回调的序号
+	Index int `json:"index"`
+	// Feature 事件对应的功能名称
+	Feature string `json:"feature"`
+	// Error panic 的内容
+	Error string `json:"error"`
+	// Time 失败的时间
+	Time time.Time `json:"time"`
+}
+
+// FeatureEventStats 变更事件的统计
+type FeatureEventStats struct {
+	// Published 发布的事件数
+	Published uint64 `json:"published"`
+	// Listeners 各回调的统计
+	Listeners []FeatureListenerStats `json:"listeners"`
+	// RecentFailures 最近的失败，按时间从旧到新排列，最多保留 20 条
+	RecentFailures []FeatureListenerFailure `json:"recent_failures"`
+}
+
+// NewFeatureFlags 创建功能开关服务并立即加载一次
+// provider: 开关的来源，为nil时所有开关默认关闭，只能通过 Set 开启
+func NewFeatureFlags(ctx context.Context, provider FeatureProvider) (*FeatureFlags, error) {
+	f := &FeatureFlags{
+		provider:  provider,
+		loaded:    make(map[string]bool),
+		overrides: make(map[string]bool),
+		ActorFunc: func(ctx *Context) string {
+			return ctx.ClientIP()
+		},
+		MaxListenerFailures: 5,
+		ListenerErrorFunc: func(err error) {
+			log.Println(err)
+		},
+	}
+	f.flags.Store(&map[string]bool{})
+	if provider == nil {
+		return f, nil
+	}
+	if err := f.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// ServerWithFeatureFlags 设置功能开关服务，处理函数可以通过 ctx.FeatureEnabled 读取
+func ServerWithFeatureFlags(flags *FeatureFlags) ServerOption {
+	return func(server *HTTPServer) {
+		server.features = flags
+	}
+}
+
+// OnChange 注册变更事件的回调，例如写入审计日志
+// 回调在释放锁之后按事件发布的顺序调用，不应执行耗时操作
+// 回调中可以调用 Set、Unset、Reload 或 EventStats，产生的事件在当前事件的回调全部完成后分发；
+// 其他 goroutine 正在分发事件时，新的事件交给该 goroutine 分发，变更方法可能在回调执行前返回
+// 回调 panic 时会被恢复并通过 ListenerErrorFunc 报告，不影响变更本身与其他回调，
+// 连续 panic 的次数达到 MaxListenerFailures 后该回调被停用
+func (f *FeatureFlags) OnChange(fn func(e FeatureEvent)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onChange = append(f.onChange, &featureListener{fn: fn})
+}
+
+// Enabled 返回功能是否开启，未知的功能视为关闭
+func (f *FeatureFlags) Enabled(name string) bool {
+	return (*f.flags.Load())[name]
+}
+
+// All 返回全部开关的当前值
+func (f *FeatureFlags) All() map[string]bool {
+	return maps.Clone(*f.flags.Load())
+}
+
+// Set 在运行时覆盖功能开关
+// actor: 执行变更的操作者，记录在变更事件中
+func (f *FeatureFlags) Set(name string, enabled bool, actor string) {
+	f.mu.Lock()
+	f.overrides[name] = enabled
+	f.publish("admin", actor)
+	f.mu.Unlock()
+	f.dispatch()
+}
+
+// Unset 取消运行时的覆盖，恢复为 FeatureProvider 中的值
+func (f *FeatureFlags) Unset(name string, actor string) {
+	f.mu.Lock()
+	delete(f.overrides, name)
+	f.publish("admin", actor)
+	f.mu.Unlock()
+	f.dispatch()
+}
+
+// Reload 从 FeatureProvider 重新加载开关，加载失败时保留原来的值
+func (f *FeatureFlags) Reload(ctx context.Context) error {
+	if f.provider == nil {
+		return nil
+	}
+	loaded, err := f.provider.Load(ctx)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.loaded = loaded
+	f.publish("provider", "")
+	f.mu.Unlock()
+	f.dispatch()
+	return nil
+}
+
+// Watch 按间隔定期重新加载开关，直到 ctx 被取消
+// onError: 加载失败时调用，可以为nil
+func (f *FeatureFlags) Watch(ctx context.Context, interval time.Duration, onError func(err error)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := f.Reload(ctx); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+}
+
+// publish 合并开关并将变更事件加入待分发队列，调用方需持有锁，释放锁后调用 dispatch
+func (f *FeatureFlags) publish(source, actor string) {
+	prev := *f.flags.Load()
+	next := maps.Clone(f.loaded)
+	if next == nil {
+		next = make(map[string]bool)
+	}
+	maps.Copy(next, f.overrides)
+	f.flags.Store(&next)
+
+	now := time.Now()
+	for name, enabled := range next {
+		if prev[name] != enabled {
+			f.pending = append(f.pending, FeatureEvent{Name: name, Enabled: enabled, Previous: prev[name], Source: source, Actor: actor, Time: now})
+		}
+	}
+	for name, was := range prev {
+		if _, ok := next[name]; !ok && was {
+			f.pending = append(f.pending, FeatureEvent{Name: name, Previous: true, Source: source, Actor: actor, Time: now})
+		}
+	}
+}
+
+// dispatch 在不持有锁的情况下将待分发的事件依次交给回调
+// 同一时间只有一个 goroutine 分发，其他 goroutine 与回调中产生的事件加入队列后由它继续分发，保证顺序
+func (f *FeatureFlags) dispatch() {
+	f.mu.Lock()
+	if f.dispatching {
+		f.mu.Unlock()
+		return
+	}
+	f.dispatching = true
+	for len(f.pending) > 0 {
+		e := f.pending[0]
+		f.pending = f.pending[1:]
+		f.published++
+		var active []int
+		for i, l := range f.onChange {
+			if !l.disabled {
+				active = append(active, i)
+			}
+		}
+		listeners := slices.Clone(f.onChange)
+		f.mu.Unlock()
+
+		var errs []error
+		for _, i := range active {
+			l := listeners[i]
+			start := time.Now()
+			err := l.call(e)
+			latency := time.Since(start)
+
+			f.mu.Lock()
+			errs = append(errs, f.record(i, l, e, start, latency, err)...)
+			f.mu.Unlock()
+		}
+		if f.ListenerErrorFunc != nil {
+			for _, err := range errs {
+				f.ListenerErrorFunc(err)
+			}
+		}
+		f.mu.Lock()
+	}
+	f.pending = nil
+	f.dispatching = false
+	f.mu.Unlock()
+}
+
+// record 记录回调的一次调用，返回需要报告的错误，调用方需持有锁
+func (f *FeatureFlags) record(i int, l *featureListener, e FeatureEvent, start time.Time, latency time.Duration, err error) []error {
+	l.totalLatency += latency
+	l.maxLatency = max(l.maxLatency, latency)
+	if err == nil {
+		l.handled++
+		l.failures = 0
+		return nil
+	}
+	l.totalFailed++
+	l.lastError = err.Error()
+	if len(f.recentFailures) == maxRecentListenerFailures {
+		f.recentFailures = slices.Delete(f.recentFailures, 0, 1)
+	}
+	f.recentFailures = append(f.recentFailures, FeatureListenerFailure{Index: i + 1, Feature: e.Name, Error: err.Error(), Time: start})
+	errs := []error{fmt.Errorf("web: 功能开关的第 %d 个变更回调处理 %s 时 panic: %w", i+1, e.Name, err)}
+	l.failures++
+	if f.MaxListenerFailures > 0 && l.failures >= f.MaxListenerFailures {
+		l.disabled = true
+		errs = append(errs, fmt.Errorf("web: 功能开关的第 %d 个变更回调连续 panic %d 次，已停用", i+1, l.failures))
+	}
+	return errs
+}
+
+// EventStats 返回变更事件与各回调的统计
+func (f *FeatureFlags) EventStats() FeatureEventStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stats := FeatureEventStats{
+		Published:      f.published,
+		Listeners:      make([]FeatureListenerStats, 0, len(f.onChange)),
+		RecentFailures: slices.Clone(f.recentFailures),
+	}
+	for i, l := range f.onChange {
+		ls := FeatureListenerStats{
+			Index:      i + 1,
+			Handled:    l.handled,
+			Failed:     l.totalFailed,
+			Disabled:   l.disabled,
+			MaxLatency: l.maxLatency,
+			LastError:  l.lastError,
+		}
+		if calls := l.handled + l.totalFailed; calls > 0 {
+			ls.AvgLatency = l.totalLatency / time.Duration(calls)
+		}
+		stats.Listeners = append(stats.Listeners, ls)
+	}
+	return stats
+}
+
+// PublishEventMetrics 将变更事件的统计以 expvar 变量的形式发布
+// name: 变量名，同名变量已存在时 panic，与 expvar.Publish 一致
+func (f *FeatureFlags) PublishEventMetrics(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return f.EventStats()
+	}))
+}
+
+// EventsHandler 返回以 JSON 响应变更事件统计的处理函数，用于排查停用的回调与最近的失败
+// 注意：务必通过鉴权中间件保护该处理函数
+func (f *FeatureFlags) EventsHandler() HandleFunc {
+	return func(ctx *Context) {
+		ctx.Resp.Header().Set("Cache-Control", "no-store")
+		_ = ctx.RespJSONOK(f.EventStats())
+	}
+}
+
+// call 调用回调并将 panic 转换为错误
+func (l *featureListener) call(e FeatureEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if rErr, ok := r.(error); ok {
+				err = rErr
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+	l.fn(e)
+	return nil
+}
+
+// AdminHandler 返回用于管理功能开关的处理函数
+// GET 返回全部开关的当前值
+// PUT 或 POST 接收 {"name": true} 格式的JSON，覆盖请求中出现的开关
+// DELETE 通过查询参数 name 取消覆盖，恢复为 FeatureProvider 中的值
+// 注意：务必通过鉴权中间件保护该处理函数
+func (f *FeatureFlags) AdminHandler() HandleFunc {
+	return func(ctx *Context) {
+		switch ctx.Req.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodDelete:
+			name := ctx.Req.URL.Query().Get("name")
+			if name == "" {
+				ctx.RespStatusCode = http.StatusBadRequest
+				ctx.RespData = []byte("缺少参数 name")
+				return
+			}
+			f.Unset(name, f.ActorFunc(ctx))
+		default:
+			var req map[string]bool
+			if err := ctx.BindJSON(&req); err != nil {
+				ctx.RespStatusCode = http.StatusBadRequest
+				ctx.RespData = []byte(err.Error())
+				return
+			}
+			actor := f.ActorFunc(ctx)
+			for name, enabled := range req {
+				f.Set(name, enabled, actor)
+			}
+		}
+		_ = ctx.RespJSONOK(f.All())
+	}
+}
+
+// FuncMap 返回模板中使用的函数，在模板中通过 {{ if feature "new-checkout" }} 判断功能是否开启
+// 需要在解析模板之前设置，例如 GoTemplateEngine.Funcs
+func (f *FeatureFlags) FuncMap() template.FuncMap {
+	return template.FuncMap{"feature": f.Enabled}
+}
+
+// FeatureEnabled 返回功能是否开启
+// 未通过 ServerWithFeatureFlags 设置功能开关服务时总是返回 false
+func (c *Context) FeatureEnabled(name string) bool {
+	if c.features == nil {
+		return false
+	}
+	return c.features.Enabled(name)
+}
+
+// RequireFeature 返回只在功能开启时放行的中间件，功能关闭时响应 404，隐藏尚未发布的路由
+// 例如 s.Handle("GET /checkout/v2", ant.RequireFeature("new-checkout")(handler))
+func RequireFeature(name string) Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			if !ctx.FeatureEnabled(name) {
+				ctx.RespStatusCode = http.StatusNotFound
+				ctx.RespData = []byte(http.StatusText(http.StatusNotFound))
+				return
+			}
+			next(ctx)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package ant
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestFeatureFlags 测试加载、覆盖与变更事件
+func TestFeatureFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	if err := os.WriteFile(path, []byte(`{"a": true, "b": false}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	flags, err := NewFeatureFlags(context.Background(), FileFeatures(path))
+	if err != nil {
+		t.Fatalf("加载功能开关失败: %v", err)
+	}
+	var events []FeatureEvent
+	flags.OnChange(func(e FeatureEvent) {
+		events = append(events, e)
+	})
+	if !flags.Enabled("a") || flags.Enabled("b") || flags.Enabled("unknown") {
+		t.Errorf("开关的值错误: %v", flags.All())
+	}
+
+	// 覆盖的值在重新加载后仍然保留
+	flags.Set("b", true, "alice")
+	if err = os.WriteFile(path, []byte(`{"a": false, "b": false}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err = flags.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if flags.Enabled("a") || !flags.Enabled("b") {
+		t.Errorf("重新加载后开关的值错误: %v", flags.All())
+	}
+	flags.Unset("b", "alice")
+	if flags.Enabled("b") {
+		t.Error("取消覆盖后应恢复为文件中的值")
+	}
+
+	want := []string{"b:true:admin:alice", "a:false:provider:", "b:false:admin:alice"}
+	if len(events) != len(want) {
+		t.Fatalf("应产生 %d 个变更事件，实际为 %+v", len(want), events)
+	}
+	for i, e := range events {
+		got := e.Name + ":" + map[bool]string{true: "true", false: "false"}[e.Enabled] + ":" + e.Source + ":" + e.Actor
+		if got != want[i] {
+			t.Errorf("第 %d 个事件应为 %s，实际为 %s", i, want[i], got)
+		}
+	}
+
+	// 加载失败时保留原来的值
+	if err = os.WriteFile(path, []byte(`{`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err = flags.Reload(context.Background()); err == nil {
+		t.Error("文件格式错误时应返回错误")
+	}
+	if flags.Enabled("a") {
+		t.Errorf("加载失败不应修改开关: %v", flags.All())
+	}
+}
+
+// TestFeatureFlagsListenerPanic 测试变更回调 panic 时的隔离与停用
+func TestFeatureFlagsListenerPanic(t *testing.T) {
+	flags, err := NewFeatureFlags(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flags.MaxListenerFailures = 2
+	var reported []error
+	flags.ListenerErrorFunc = func(err error) {
+		reported = append(reported, err)
+	}
+	var panics, received int
+	flags.OnChange(func(e FeatureEvent) {
+		panics++
+		panic("boom")
+	})
+	flags.OnChange(func(e FeatureEvent) {
+		received++
+	})
+
+	for i, name := range []string{"a", "b", "c"} {
+		flags.Set(name, true, "alice")
+		if !flags.Enabled(name) {
+			t.Errorf("回调 panic 不应影响变更 %s", name)
+		}
+		if received != i+1 {
+			t.Errorf("其他回调应收到 %d 个事件，实际为 %d", i+1, received)
+		}
+	}
+	if panics != 2 {
+		t.Errorf("连续 panic 2 次后应停用，实际调用了 %d 次", panics)
+	}
+	if len(reported) != 3 || !strings.Contains(reported[0].Error(), "boom") || !strings.Contains(reported[2].Error(), "已停用") {
+		t.Errorf("应报告 2 次 panic 与 1 次停用，实际为 %v", reported)
+	}
+
+	stats := flags.EventStats()
+	if stats.Published != 3 || len(stats.Listeners) != 2 || len(stats.RecentFailures) != 2 {
+		t.Fatalf("统计不正确: %+v", stats)
+	}
+	if l := stats.Listeners[0]; !l.Disabled || l.Failed != 2 || l.Handled != 0 || l.LastError != "boom" {
+		t.Errorf("第 1 个回调的统计不正确: %+v", l)
+	}
+	if l := stats.Listeners[1]; l.Disabled || l.Handled != 3 {
+		t.Errorf("第 2 个回调的统计不正确: %+v", l)
+	}
+	if f := stats.RecentFailures[1]; f.Index != 1 || f.Feature != "b" {
+		t.Errorf("最近的失败不正确: %+v", f)
+	}
+
+	w := httptest.NewRecorder()
+	flags.EventsHandler()(&Context{Req: httptest.NewRequest(http.MethodGet, "/", nil), Resp: w})
+	var got FeatureEventStats
+	if err = json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Published != 3 || len(got.Listeners) != 2 {
+		t.Errorf("处理函数的响应不正确: %s", w.Body.String())
+	}
+}
+
+// TestFeatureFlagsListenerReentrant 测试回调中可以修改开关与读取统计，事件按顺序分发
+func TestFeatureFlagsListenerReentrant(t *testing.T) {
+	flags, err := NewFeatureFlags(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []string
+	flags.OnChange(func(e FeatureEvent) {
+		events = append(events, e.Name)
+		_ = flags.EventStats()
+		if e.Name == "checkout" && e.Enabled {
+			// 开启新功能时同时开启依赖的功能
+			flags.Set("payment", true, "listener")
+			_ = flags.Reload(context.Background())
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		flags.Set("checkout", true, "alice")
+		flags.Unset("checkout", "alice")
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("回调中调用 Set 与 EventStats 发生了死锁")
+	}
+
+	if got := strings.Join(events, ","); got != "checkout,payment,checkout" {
+		t.Errorf("事件的顺序不正确: %s", got)
+	}
+	if !flags.Enabled("payment") || flags.Enabled("checkout") {
+		t.Errorf("开关的值不正确: %v", flags.All())
+	}
+	if stats := flags.EventStats(); stats.Published != 3 || stats.Listeners[0].Handled != 3 {
+		t.Errorf("统计不正确: %+v", stats)
+	}
+}
+
+// TestRemoteFeatures 测试从远程服务加载与定期刷新
+func TestRemoteFeatures(t *testing.T) {
+	var body atomic.Value
+	body.Store(`{"x": false}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body.Load().(string)))
+	}))
+	defer srv.Close()
+
+	flags, err := NewFeatureFlags(context.Background(), RemoteFeatures{URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags.Enabled("x") {
+		t.Error("x 应为关闭")
+	}
+	body.Store(`{"x": true}`)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	flags.Watch(ctx, 10*time.Millisecond, nil)
+	waitFor(t, func() bool { return flags.Enabled("x") })
+}
+
+// TestFeatureFlagsServer 测试 ctx.FeatureEnabled、路由开关与管理接口
+func TestFeatureFlagsServer(t *testing.T) {
+	flags, _ := NewFeatureFlags(context.Background(), StaticFeatures{"beta": false})
+	var events []FeatureEvent
+	flags.OnChange(func(e FeatureEvent) {
+		events = append(events, e)
+	})
+	s := NewHTTPServer(ServerWithFeatureFlags(flags))
+	s.Handle("GET /home", func(ctx *Context) {
+		if ctx.FeatureEnabled("beta") {
+			_ = ctx.WriteString("beta")
+			return
+		}
+		_ = ctx.WriteString("stable")
+	})
+	s.Handle("GET /beta", RequireFeature("beta")(func(ctx *Context) {
+		_ = ctx.WriteString("beta only")
+	}))
+	s.Handle("/admin/features", flags.AdminHandler())
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	if w := get("/home"); w.Body.String() != "stable" {
+		t.Errorf("功能关闭时应为 stable，实际为 %q", w.Body.String())
+	}
+	if w := get("/beta"); w.Code != http.StatusNotFound {
+		t.Errorf("功能关闭时应返回 404，实际为 %d", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/admin/features", strings.NewReader(`{"beta": true}`))
+	req.RemoteAddr = "10.0.0.1:1234"
+	s.ServeHTTP(w, req)
+	var all map[string]bool
+	if err := json.Unmarshal(w.Body.Bytes(), &all); err != nil || !all["beta"] {
+		t.Errorf("管理接口应返回开启后的开关，实际为 %s", w.Body.String())
+	}
+	if len(events) != 1 || events[0].Actor != "10.0.0.1" || events[0].Source != "admin" {
+		t.Errorf("应产生一个带有操作者的变更事件，实际为 %+v", events)
+	}
+	if w := get("/home"); w.Body.String() != "beta" {
+		t.Errorf("功能开启后应为 beta，实际为 %q", w.Body.String())
+	}
+	if w := get("/beta"); w.Body.String() != "beta only" {
+		t.Errorf("功能开启后应放行，实际为 %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/features?name=beta", nil))
+	if flags.Enabled("beta") {
+		t.Error("取消覆盖后 beta 应恢复为关闭")
+	}
+
+	// 未设置功能开关服务时总是关闭
+	if (&Context{}).FeatureEnabled("beta") {
+		t.Error("未设置功能开关服务时应返回 false")
+	}
+}
+
+// TestFeatureFlagsTemplate 测试模板中的 feature 函数
+func TestFeatureFlagsTemplate(t *testing.T) {
+	flags, _ := NewFeatureFlags(context.Background(), StaticFeatures{"banner": true})
+	engine := &GoTemplateEngine{Funcs: flags.FuncMap()}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "page.gohtml"), []byte(`{{ if feature "banner" }}banner{{ end }}{{ if feature "other" }}other{{ end }}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.LoadFromGlob(filepath.Join(dir, "*.gohtml")); err != nil {
+		t.Fatal(err)
+	}
+	out, err := engine.Render(context.Background(), "page.gohtml", nil)
+	if err != nil || string(out) != "banner" {
+		t.Errorf("渲染结果应为 banner，实际为 %q，错误: %v", out, err)
+	}
+}
@@ -1,15 +1,23 @@
 package ant
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"maps"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
@@ -24,6 +32,16 @@ type FileUploader struct {
 	DstPathFunc func(fh *multipart.FileHeader) string
 	// FileNameFunc 生成文件名的函数，如果为nil则使用原始文件名
 	FileNameFunc func(originalName string) string
+	// Accounting 存储用量统计，为nil时不统计也不限制
+	Accounting *StorageAccounting
+	// OwnerFunc 从请求中获取用户或租户的标识，用于统计存储用量，设置了 Accounting 时必须设置
+	OwnerFunc func(ctx *Context) string
+	// Multipart 解析请求体的选项，控制内存阈值、临时文件目录与单个文件的大小
+	Multipart MultipartOptions
+	// AllowedExts 允许上传的文件扩展名，例如 ".png"、".pdf"，不区分大小写，为空时不限制
+	AllowedExts []string
+	// ChecksumField 携带文件 SHA-256 摘要（十六进制）的表单字段名称，为空或请求中没有该字段时不校验
+	ChecksumField string
 }
 
 // Handle 实现文件上传处理逻辑
@@ -33,14 +51,41 @@ type FileUploader struct {
 // 2. 返回上传结果和文件大小信息
 // 3. 处理各类错误场景并返回适当的HTTP状态码
 // 4. 支持自定义文件名生成策略，避免文件重名
+// 5. 设置了 Accounting 时按 OwnerFunc 统计存储用量，超出配额返回 413，覆盖已有文件时释放原文件的用量
+// 6. 按 Multipart 流式解析请求体，超出 MaxFileSize 时返回 413，临时文件在请求结束后删除
+// 7. 扩展名不在 AllowedExts 中时返回 415；摘要与 ChecksumField 不一致时返回 400，不会覆盖已有文件
+// 8. 先写入目标目录中的临时文件再重命名，写入失败时已有文件保持不变
+// 需要上传数GB的文件时可以使用 ChunkedUploader 分片上传并断点续传
 func (f *FileUploader) Handle() HandleFunc {
 	return func(ctx *Context) {
-		src, fileHeader, err := ctx.Req.FormFile(f.FileField)
-		if err != nil {
+		form, err := ctx.MultipartForm(f.Multipart)
+		if errors.Is(err, ErrMultipartTooLarge) {
+			ctx.RespStatusCode = http.StatusRequestEntityTooLarge
+			ctx.RespData = []byte("上传失败，文件过大")
+			return
+		}
+		if err != nil || len(form.File[f.FileField]) == 0 {
 			ctx.RespStatusCode = http.StatusBadRequest
 			ctx.RespData = []byte("上传失败，未找到文件")
 			return
 		}
+		fileHeader := form.File[f.FileField][0]
+		if !allowedExt(f.AllowedExts, fileHeader.Filename) {
+			ctx.RespStatusCode = http.StatusUnsupportedMediaType
+			ctx.RespData = []byte("上传失败，不支持的文件类型")
+			return
+		}
+		var checksum string
+		if f.ChecksumField != "" && len(form.Value[f.ChecksumField]) > 0 {
+			checksum = strings.ToLower(form.Value[f.ChecksumField][0])
+		}
+		src, err := fileHeader.Open()
+		if err != nil {
+			ctx.RespStatusCode = http.StatusInternalServerError
+			ctx.RespData = []byte("读取上传文件失败")
+			log.Println(err)
+			return
+		}
 		defer src.Close()
 
 		// 生成文件名
@@ -57,8 +102,29 @@ func (f *FileUploader) Handle() HandleFunc {
 			Header:   fileHeader.Header,
 		}
 
-		// 确保目标目录存在
+		// 覆盖已有文件时只需要预留两者的差值
 		dstPath := f.DstPathFunc(newFileHeader)
+		var replaced int64
+		if info, statErr := os.Stat(dstPath); statErr == nil && info.Mode().IsRegular() {
+			replaced = info.Size()
+		}
+
+		// 预留存储空间，失败时释放
+		var owner string
+		reserved := fileHeader.Size - replaced
+		if f.Accounting != nil {
+			owner = f.OwnerFunc(ctx)
+			if err = f.Accounting.Reserve(owner, reserved); err != nil {
+				ctx.RespStatusCode = http.StatusRequestEntityTooLarge
+				ctx.RespData = []byte("超出存储配额")
+				return
+			}
+			defer func() {
+				f.Accounting.Release(owner, reserved)
+			}()
+		}
+
+		// 确保目标目录存在
 		if err = os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
 			ctx.RespStatusCode = http.StatusInternalServerError
 			ctx.RespData = []byte("创建目录失败")
@@ -67,7 +133,7 @@ func (f *FileUploader) Handle() HandleFunc {
 			return
 		}
 
-		dst, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o666)
+		dst, err := os.CreateTemp(filepath.Dir(dstPath), ".upload-*")
 		if err != nil {
 			ctx.RespStatusCode = http.StatusInternalServerError
 			ctx.RespData = []byte("创建文件失败")
@@ -75,9 +141,17 @@ func (f *FileUploader) Handle() HandleFunc {
 			log.Println(err)
 			return
 		}
-		defer dst.Close()
+		// 重命名后删除会失败，可以忽略
+		defer os.Remove(dst.Name())
 
-		written, err := io.Copy(dst, src)
+		h := sha256.New()
+		written, err := io.Copy(io.MultiWriter(dst, h), src)
+		if closeErr := dst.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Chmod(dst.Name(), 0o644)
+		}
 		if err != nil {
 			ctx.RespStatusCode = http.StatusInternalServerError
 			ctx.RespData = []byte("保存文件失败")
@@ -85,17 +159,62 @@ func (f *FileUploader) Handle() HandleFunc {
 			log.Println(err)
 			return
 		}
+		if checksum != "" && checksum != hex.EncodeToString(h.Sum(nil)) {
+			ctx.RespStatusCode = http.StatusBadRequest
+			ctx.RespData = []byte("上传失败，校验和不匹配")
+			return
+		}
+		if err = os.Rename(dst.Name(), dstPath); err != nil {
+			ctx.RespStatusCode = http.StatusInternalServerError
+			ctx.RespData = []byte("保存文件失败")
+			ctx.Resp.WriteHeader(http.StatusInternalServerError)
+			log.Println(err)
+			return
+		}
+
+		// 按实际写入的大小修正用量
+		reserved -= written - replaced
 
 		ctx.RespStatusCode = http.StatusOK
 		ctx.RespData = fmt.Appendf(nil, "上传成功，文件大小: %d bytes", written)
 	}
 }
 
+// allowedExt 判断文件的扩展名是否在 exts 中，exts 为空时总是允许
+func allowedExt(exts []string, name string) bool {
+	if len(exts) == 0 {
+		return true
+	}
+	ext := filepath.Ext(name)
+	return slices.ContainsFunc(exts, func(e string) bool {
+		return strings.EqualFold(e, ext)
+	})
+}
+
 // FileDownloader 文件下载处理器
 // 提供安全的文件下载功能，支持防止目录遍历攻击
 type FileDownloader struct {
 	// Dir 文件下载的根目录
 	Dir string
+	// AccessCheck 访问检查，为nil时不检查，见 WithAccessCheck
+	AccessCheck AccessCheckFunc
+
+	// digestMu 保护 digests
+	digestMu sync.Mutex
+	// digests 文件摘要的缓存，文件大小或修改时间变化时重新计算
+	digests map[string]fileDigest
+}
+
+// fileDigest 缓存的文件摘要
+type fileDigest struct {
+	size    int64
+	modTime time.Time
+	value   string
+}
+
+// Validate 检查下载目录存在，可以通过 HTTPServer.AddCheck 在启动前执行
+func (f *FileDownloader) Validate() error {
+	return checkDir(f.Dir)
 }
 
 // Handle 实现文件下载处理逻辑
@@ -104,6 +223,11 @@ type FileDownloader struct {
 // 1. 自动处理文件不存在、权限错误等异常情况
 // 2. 防止目录遍历和路径穿越攻击
 // 3. 设置正确的Content-Type和Content-Disposition头
+// 4. HEAD 请求只返回文件大小、修改时间、ETag、SHA-256 摘要（Repr-Digest）与 Accept-Ranges，不返回响应体，
+// 下载工具可以据此规划下载；GET 请求只在摘要已经计算过时返回 Repr-Digest
+// 5. 设置了 AccessCheck 时在读取文件之前检查，返回的错误通过 ctx.RespError 响应
+// 6. GET 请求支持 Range 与 If-Range，可以断点续传，响应 206 或 416；
+// If-None-Match 与 If-Modified-Since 表明文件没有变化时响应 304；文件内容流式发送，不会整体读入内存
 func (f *FileDownloader) Handle() HandleFunc {
 	return func(ctx *Context) {
 		fileName, err := ctx.QueryValue("file").String()
@@ -123,6 +247,14 @@ func (f *FileDownloader) Handle() HandleFunc {
 			return
 		}
 
+		// 在读取文件信息之前检查，避免向无权访问的用户暴露文件是否存在
+		if f.AccessCheck != nil {
+			if err := f.AccessCheck(ctx, filepath.Base(cleanPath)); err != nil {
+				ctx.RespError(err)
+				return
+			}
+		}
+
 		// 使用filepath.Base确保路径限制在目标目录内，防止绝对路径攻击
 		filePath := filepath.Join(f.Dir, filepath.Base(cleanPath))
 		info, err := os.Stat(filePath)
@@ -152,6 +284,29 @@ func (f *FileDownloader) Handle() HandleFunc {
 			return
 		}
 
+		header := ctx.Resp.Header()
+		header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filepath.Base(cleanPath)))
+		header.Set("Content-Type", "application/octet-stream")
+		header.Set("ETag", fileETag(info))
+
+		if ctx.Req.Method == http.MethodHead {
+			header.Set("Content-Length", fmt.Sprintf("%d", info.Size()))
+			header.Set("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
+			header.Set("Accept-Ranges", "bytes")
+			digest, err := f.digest(filePath, info)
+			if err != nil {
+				log.Printf("计算文件摘要失败: %v", err)
+			} else {
+				header.Set("Repr-Digest", digest)
+			}
+			ctx.RespStatusCode = http.StatusOK
+			ctx.Resp.WriteHeader(http.StatusOK)
+			return
+		}
+		if digest, ok := f.cachedDigest(filePath, info); ok {
+			header.Set("Repr-Digest", digest)
+		}
+
 		file, err := os.Open(filePath)
 		if err != nil {
 			// 区分权限错误和其他错误
@@ -168,20 +323,56 @@ func (f *FileDownloader) Handle() HandleFunc {
 		}
 		defer file.Close()
 
-		// 设置响应头
-		header := ctx.Resp.Header()
-		header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filepath.Base(cleanPath)))
-		header.Set("Content-Type", "application/octet-stream")
-		header.Set("Content-Length", fmt.Sprintf("%d", info.Size()))
-
-		// 设置响应状态码
-		ctx.RespStatusCode = http.StatusOK
-		ctx.Resp.WriteHeader(http.StatusOK)
-		_, err = io.Copy(ctx.Resp, file)
-		if err != nil {
-			log.Printf("发送文件失败: %v", err)
+		// 由 ServeContent 处理 Range、If-Range 与条件请求，并流式发送文件内容
+		// 直接构造的 Context 中 ctx.Resp 不记录状态码，需要包装
+		rw, ok := ctx.Resp.(ResponseWriter)
+		if !ok {
+			rw = &responseWriter{ResponseWriter: ctx.Resp}
 		}
+		http.ServeContent(rw, ctx.Req, "", info.ModTime(), file)
+		ctx.RespStatusCode = rw.Status()
+	}
+}
+
+// fileETag 根据文件大小与修改时间生成强 ETag，用于 If-None-Match 与断点续传的 If-Range
+func fileETag(info os.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.Size(), info.ModTime().UnixNano())
+}
+
+// digest 返回文件 SHA-256 摘要的 Repr-Digest 格式（RFC 9530），例如 sha-256=:base64:
+func (f *FileDownloader) digest(path string, info os.FileInfo) (string, error) {
+	if d, ok := f.cachedDigest(path, info); ok {
+		return d, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
 	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, file); err != nil {
+		return "", err
+	}
+	value := "sha-256=:" + base64.StdEncoding.EncodeToString(h.Sum(nil)) + ":"
+
+	f.digestMu.Lock()
+	defer f.digestMu.Unlock()
+	if f.digests == nil {
+		f.digests = make(map[string]fileDigest)
+	}
+	f.digests[path] = fileDigest{size: info.Size(), modTime: info.ModTime(), value: value}
+	return value, nil
+}
+
+// cachedDigest 返回缓存的文件摘要，文件大小或修改时间变化后缓存失效
+func (f *FileDownloader) cachedDigest(path string, info os.FileInfo) (string, bool) {
+	f.digestMu.Lock()
+	defer f.digestMu.Unlock()
+	d, ok := f.digests[path]
+	if !ok || d.size != info.Size() || !d.modTime.Equal(info.ModTime()) {
+		return "", false
+	}
+	return d.value, true
 }
 
 // StaticResourceHandler 静态资源处理器
@@ -189,6 +380,8 @@ func (f *FileDownloader) Handle() HandleFunc {
 type StaticResourceHandler struct {
 	// dir 静态资源的根目录
 	dir string
+	// fsys 静态资源所在的文件系统，为nil时从 dir 读取
+	fsys fs.FS
 	// pathPrefix 静态资源的URL路径前缀
 	pathPrefix string
 	// extensionContentTypeMap 文件扩展名到Content-Type的映射
@@ -197,6 +390,22 @@ type StaticResourceHandler struct {
 	cache *lru.Cache
 	// maxFileSize 可缓存的最大文件大小
 	maxFileSize int
+	// cacheControl 响应的缓存指令，没有任何指令时不设置 Cache-Control
+	cacheControl CacheControl
+	// cacheRules 通过 WithCacheRule 按路径设置的缓存指令
+	cacheRules []cacheRule
+	// fingerprint 是否启用内容指纹，通过 WithFingerprint 设置
+	fingerprint bool
+	// manifestName 资源清单的文件名，为空时不提供清单
+	manifestName string
+	// manifestMu 保护 manifest 与 hashed
+	manifestMu sync.Mutex
+	// manifest 缓存的资源清单
+	manifest AssetManifest
+	// hashed 带有指纹的文件名到逻辑名称的映射
+	hashed map[string]string
+	// accessCheck 访问检查，通过 WithAccessCheck 设置
+	accessCheck AccessCheckFunc
 }
 
 // fileCacheItem 文件缓存项
@@ -210,6 +419,8 @@ type fileCacheItem struct {
 	contentType string
 	// data 文件内容
 	data []byte
+	// encoding 内容编码，预压缩文件为 gzip
+	encoding string
 	// modTime 文件修改时间戳
 	modTime int64
 }
@@ -223,8 +434,23 @@ type StaticResourceHandlerOption func(*StaticResourceHandler)
 // options: 可选的配置选项
 // 返回值: 配置完成的StaticResourceHandler实例
 func NewStaticResourceHandler(dir, pathPrefix string, options ...StaticResourceHandlerOption) *StaticResourceHandler {
+	return newStaticResourceHandler(dir, nil, pathPrefix, options...)
+}
+
+// NewStaticFSHandler 创建从 fs.FS 读取的静态资源处理器，例如通过 embed.FS 打包进二进制文件的资源
+// fsys: 静态资源所在的文件系统
+// pathPrefix: 静态资源的URL路径前缀
+// options: 可选的配置选项
+// 返回值: 配置完成的StaticResourceHandler实例
+func NewStaticFSHandler(fsys fs.FS, pathPrefix string, options ...StaticResourceHandlerOption) *StaticResourceHandler {
+	return newStaticResourceHandler("", fsys, pathPrefix, options...)
+}
+
+// newStaticResourceHandler 创建静态资源处理器，fsys 为nil时从 dir 读取
+func newStaticResourceHandler(dir string, fsys fs.FS, pathPrefix string, options ...StaticResourceHandlerOption) *StaticResourceHandler {
 	h := &StaticResourceHandler{
 		dir:        dir,
+		fsys:       fsys,
 		pathPrefix: pathPrefix,
 		extensionContentTypeMap: map[string]string{
 			"html": "text/html; charset=utf-8",
@@ -240,6 +466,7 @@ func NewStaticResourceHandler(dir, pathPrefix string, options ...StaticResourceH
 			"pdf":  "application/pdf",
 			"txt":  "text/plain",
 		},
+		cacheControl: CacheControl{Public: true, MaxAge: 365 * 24 * time.Hour},
 	}
 
 	for _, opt := range options {
@@ -248,12 +475,31 @@ func NewStaticResourceHandler(dir, pathPrefix string, options ...StaticResourceH
 	return h
 }
 
+// Validate 检查静态资源目录存在，可以通过 HTTPServer.AddCheck 在启动前执行
+func (h *StaticResourceHandler) Validate() error {
+	if h.fsys != nil {
+		_, err := fs.Stat(h.fsys, ".")
+		return err
+	}
+	return checkDir(h.dir)
+}
+
+// open 打开静态资源文件
+func (h *StaticResourceHandler) open(name string) (fs.File, error) {
+	if h.fsys != nil {
+		return h.fsys.Open(name)
+	}
+	return os.Open(filepath.Join(h.dir, name))
+}
+
 // Handle 处理静态资源请求
 // ctx: 请求上下文
 // 注意：
 // 1. 支持从缓存中快速返回资源
 // 2. 自动设置适当的Content-Type
-// 3. 处理各类错误场景
+// 3. 文件不存在或请求的是目录时返回 404
+// 4. 没有启用缓存或超出缓存大小的文件不会读入内存，直接流式发送
+// 5. 设置了 WithAccessCheck 时在读取文件与缓存之前检查
 func (h *StaticResourceHandler) Handle(ctx *Context) {
 	// 获取请求路径中的文件名
 	req, err := ctx.PathValue("file").String()
@@ -264,35 +510,76 @@ func (h *StaticResourceHandler) Handle(ctx *Context) {
 	}
 
 	// 检查是否包含非法路径
-	if strings.Contains(req, "..") {
+	if strings.Contains(req, "..") || (h.fsys != nil && !fs.ValidPath(req)) {
 		ctx.RespStatusCode = http.StatusBadRequest
 		ctx.RespData = []byte("未指定文件名")
 		return
 	}
 
+	if h.manifestName != "" && req == h.manifestName {
+		if h.checkAccess(ctx, req) {
+			h.serveManifest(ctx)
+		}
+		return
+	}
+
+	// 启用内容指纹时，将带有指纹的地址解析为实际的文件，并优先使用预压缩文件
+	name, cacheControl := req, h.cacheControlFor(req)
+	if h.fingerprint {
+		logical, hashed, entry := h.resolveAsset(req)
+		req, name = logical, logical
+		cacheControl = h.cacheControlFor(logical)
+		if hashed {
+			cacheControl = immutableCacheControl
+		}
+		if entry.GzipSize > 0 {
+			ctx.Resp.Header().Add("Vary", "Accept-Encoding")
+			if acceptsGzip(ctx.Req) {
+				name = logical + ".gz"
+			}
+		}
+	}
+	// 在读取缓存之前检查，缓存的文件同样受保护
+	if !h.checkAccess(ctx, req) {
+		return
+	}
+	if h.accessCheck != nil {
+		// 受保护的文件只允许客户端缓存，s-maxage 只对共享缓存有效，一并去掉
+		cacheControl.Public, cacheControl.Private, cacheControl.SMaxAge = false, true, 0
+	}
+
 	// 从数据中读取文件内容
-	item, ok := h.readFileFromData(req)
+	item, ok := h.readFileFromData(name)
 	if ok {
 		// 如果文件存在，则从缓存中写入响应并返回
 		log.Printf("从缓存中读取数据...")
-		h.writeItemAsResponse(item, ctx.Resp)
+		h.writeItemAsResponse(item, ctx.Resp, cacheControl.String())
 		return
 	}
 
-	// 拼接文件路径
-	path := filepath.Join(h.dir, req)
 	// 打开文件
-	file, err := os.Open(path)
+	file, err := h.open(name)
 	if err != nil {
-		// 如果文件打开失败，则返回内部服务器错误状态码
+		if errors.Is(err, fs.ErrNotExist) {
+			ctx.RespStatusCode = http.StatusNotFound
+			ctx.RespData = []byte("文件不存在")
+			return
+		}
+		// 其他打开失败的情况返回内部服务器错误状态码
 		ctx.RespStatusCode = http.StatusInternalServerError
 		ctx.RespData = []byte("打开文件失败")
 		return
 	}
 	defer file.Close()
+	info, statErr := file.Stat()
+	if statErr == nil && info.IsDir() {
+		ctx.RespStatusCode = http.StatusNotFound
+		ctx.RespData = []byte("文件不存在")
+		return
+	}
 
 	// 获取文件扩展名
-	ext := getFileExt(file.Name())
+	ext := getFileExt(req)
 	// 根据扩展名获取对应的 content type
 	t, ok := h.extensionContentTypeMap[ext]
 	if !ok {
@@ -302,6 +589,26 @@ func (h *StaticResourceHandler) Handle(ctx *Context) {
 		return
 	}
 
+	// 不会被缓存的文件直接流式发送，避免大文件整个读入内存
+	if statErr == nil && !h.cacheable(info.Size()) {
+		item = &fileCacheItem{
+			fileName:    name,
+			fileSize:    int(info.Size()),
+			contentType: t,
+			modTime:     time.Now().Unix(),
+		}
+		if name != req {
+			item.encoding = "gzip"
+		}
+		ctx.RespStatusCode = http.StatusOK
+		h.writeItemAsResponse(item, ctx.Resp, cacheControl.String())
+		// ctx.Resp 实现了 io.ReaderFrom，底层连接支持时通过 sendfile 发送，否则使用池化的缓冲区
+		if _, err = io.Copy(ctx.Resp, file); err != nil {
+			log.Printf("发送文件失败: %v", err)
+		}
+		return
+	}
+
 	// 读取文件内容
 	data, err := io.ReadAll(file)
 	if err != nil {
@@ -313,18 +620,61 @@ func (h *StaticResourceHandler) Handle(ctx *Context) {
 
 	// 创建 fileCacheItem 对象并设置属性值
 	item = &fileCacheItem{
-		fileName:    req,
+		fileName:    name,
 		fileSize:    len(data),
 		contentType: t,
 		data:        data,
 		modTime:     time.Now().Unix(),
 	}
+	if name != req {
+		item.encoding = "gzip"
+	}
 
 	// 将文件缓存到内存中
 	h.cacheFile(item)
 	// 将 fileCacheItem 对象写入响应并返回
 	ctx.RespStatusCode = http.StatusOK
-	h.writeItemAsResponse(item, ctx.Resp)
+	h.writeItemAsResponse(item, ctx.Resp, cacheControl.String())
+}
+
+// checkAccess 执行访问检查，不允许访问时写入错误响应并返回false
+func (h *StaticResourceHandler) checkAccess(ctx *Context, name string) bool {
+	if h.accessCheck == nil {
+		return true
+	}
+	if err := h.accessCheck(ctx, name); err != nil {
+		ctx.RespError(err)
+		return false
+	}
+	return true
+}
+
+// Static 在 prefix 下挂载 dir 目录中的静态资源
+// prefix: URL路径前缀，例如 "/assets"，请求 "/assets/css/app.css" 对应 dir 中的 "css/app.css"
+// dir: 静态资源的根目录
+// opts: 静态资源处理器的配置选项
+// 注意：会通过 AddCheck 注册目录存在的检查，可以在启动前调用 Validate 发现配置错误
+func (s *HTTPServer) Static(prefix, dir string, opts ...StaticResourceHandlerOption) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	s.mountStatic(prefix, "静态资源目录 "+dir, NewStaticResourceHandler(dir, prefix, opts...))
+}
+
+// StaticFS 在 prefix 下挂载 fsys 中的静态资源，例如：
+//
+//	//go:embed assets
+//	var assets embed.FS
+//
+//	sub, _ := fs.Sub(assets, "assets")
+//	server.StaticFS("/assets", sub)
+func (s *HTTPServer) StaticFS(prefix string, fsys fs.FS, opts ...StaticResourceHandlerOption) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	s.mountStatic(prefix, "静态资源 "+prefix, NewStaticFSHandler(fsys, prefix, opts...))
+}
+
+// mountStatic 注册匹配 prefix 下全部路径的 GET 路由，同时匹配 HEAD 请求
+func (s *HTTPServer) mountStatic(prefix, checkName string, h *StaticResourceHandler) {
+	s.Handle(http.MethodGet+" "+prefix+"/{file...}", h.Handle)
+	s.AddCheck(checkName, h.Validate)
 }
 
 // readFileFromData 从缓存中读取文件数据
@@ -345,13 +695,19 @@ func (h *StaticResourceHandler) readFileFromData(fileName string) (*fileCacheIte
 // writeItemAsResponse 将缓存项写入HTTP响应
 // item: 要写入的缓存项
 // writer: HTTP响应写入器
+// cacheControl: 响应的 Cache-Control 头，为空时不设置
 // 注意：设置适当的HTTP头部，包括缓存控制
-func (h *StaticResourceHandler) writeItemAsResponse(item *fileCacheItem, writer http.ResponseWriter) {
+func (h *StaticResourceHandler) writeItemAsResponse(item *fileCacheItem, writer http.ResponseWriter, cacheControl string) {
 	header := writer.Header()
 	header.Set("Content-Type", item.contentType)
+	if item.encoding != "" {
+		header.Set("Content-Encoding", item.encoding)
+	}
 	header.Set("Content-Length", fmt.Sprintf("%d", item.fileSize))
 	header.Set("Last-Modified", fmt.Sprintf("%d", item.modTime))
-	header.Set("Cache-Control", "public, max-age=31536000")
+	if cacheControl != "" {
+		header.Set("Cache-Control", cacheControl)
+	}
 	writer.WriteHeader(http.StatusOK)
 	_, err := writer.Write(item.data)
 	if err != nil {
@@ -359,11 +715,16 @@ func (h *StaticResourceHandler) writeItemAsResponse(item *fileCacheItem, writer
 	}
 }
 
+// cacheable 判断大小为 size 的文件是否会被缓存
+func (h *StaticResourceHandler) cacheable(size int64) bool {
+	return h.cache != nil && size < int64(h.maxFileSize)
+}
+
 // cacheFile 将文件缓存到内存中
 // item: 要缓存的文件项
 // 注意：只有文件大小小于maxFileSize时才会被缓存
 func (h *StaticResourceHandler) cacheFile(item *fileCacheItem) {
-	if h.cache != nil && item.fileSize < h.maxFileSize {
+	if h.cacheable(int64(item.fileSize)) {
 		h.cache.Add(item.fileName, item)
 	}
 }
@@ -384,6 +745,96 @@ func WithFileCache(maxFileSizeThreshold int, maxCacheFileCnt int) StaticResource
 	}
 }
 
+// AccessCheckFunc 访问文件之前的检查
+// name: 请求的文件相对于根目录的路径，启用内容指纹时为不带指纹的逻辑名称
+// 返回值: 允许访问时返回nil，否则返回的错误通过 ctx.RespError 响应，
+// 例如未登录时返回 ErrUnauthorized，没有权限时返回 ErrForbidden，不希望暴露文件存在时返回 ErrNotFound
+type AccessCheckFunc func(ctx *Context, name string) error
+
+// WithAccessCheck 创建设置访问检查的配置选项，用于按会话或角色保护文件，例如 authz.AccessCheck
+// check: 访问检查，在读取文件与缓存之前执行
+// 返回值: StaticResourceHandlerOption配置函数
+// 注意：设置后缓存指令去掉 public 与 s-maxage 并加上 private，避免共享缓存将受保护的文件返回给其他用户
+//
+//	server.Static("/docs", "./docs", ant.WithAccessCheck(func(ctx *ant.Context, name string) error {
+//		if strings.HasPrefix(name, "internal/") && !isStaff(ctx) {
+//			return ant.ErrForbidden
+//		}
+//		return nil
+//	}))
+func WithAccessCheck(check AccessCheckFunc) StaticResourceHandlerOption {
+	return func(h *StaticResourceHandler) {
+		h.accessCheck = check
+	}
+}
+
+// WithCacheControl 创建设置 Cache-Control 的配置选项
+// cc: 响应的缓存指令，默认为 public, max-age=31536000
+// 传入零值时不设置 Cache-Control，由 ServerWithCachePolicy 的默认缓存策略决定
+// 返回值: StaticResourceHandlerOption配置函数
+func WithCacheControl(cc CacheControl) StaticResourceHandlerOption {
+	return func(h *StaticResourceHandler) {
+		h.cacheControl = cc
+	}
+}
+
+// WithCacheRule 创建按路径设置 Cache-Control 的配置选项，可以多次使用
+// pattern: 相对于路径前缀的路径模式，以 / 开头，* 匹配除 / 以外的任意字符，** 匹配包括 / 在内的任意字符
+// cc: 匹配的文件使用的缓存指令，传入零值时不设置 Cache-Control
+// 返回值: StaticResourceHandlerOption配置函数
+// 注意：
+// 1. 按添加的顺序匹配，使用第一条匹配的规则，都不匹配时使用 WithCacheControl 设置的值
+// 2. 启用内容指纹时，带有指纹的地址总是使用 immutable 的缓存指令
+//
+// 例如 HTML 每次都需要验证，assets 目录下的资源永久缓存：
+//
+//	ant.WithCacheRule("/**.html", ant.CacheControl{NoCache: true}),
+//	ant.WithCacheRule("/assets/**", ant.CacheControl{Public: true, MaxAge: 365 * 24 * time.Hour, Immutable: true}),
+func WithCacheRule(pattern string, cc CacheControl) StaticResourceHandlerOption {
+	re := globToRegexp(pattern)
+	return func(h *StaticResourceHandler) {
+		h.cacheRules = append(h.cacheRules, cacheRule{pattern: re, cacheControl: cc})
+	}
+}
+
+// cacheRule 按路径设置的缓存指令
+type cacheRule struct {
+	pattern      *regexp.Regexp
+	cacheControl CacheControl
+}
+
+// globToRegexp 将路径模式转换为正则表达式
+func globToRegexp(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// cacheControlFor 返回文件使用的缓存指令
+// name: 相对于路径前缀的文件名，不以 / 开头
+func (h *StaticResourceHandler) cacheControlFor(name string) CacheControl {
+	for _, r := range h.cacheRules {
+		if r.pattern.MatchString("/" + name) {
+			return r.cacheControl
+		}
+	}
+	return h.cacheControl
+}
+
 // WithMoreExtension 创建扩展Content-Type映射的配置选项
 // extMap: 要添加的扩展名到Content-Type的映射
 // 返回值: StaticResourceHandlerOption配置函数
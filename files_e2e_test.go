@@ -146,8 +146,8 @@ func TestFileHandlingE2E(t *testing.T) {
 		req.SetPathValue("file", "nonexistent.html")
 		rec = httptest.NewRecorder()
 		server.ServeHTTP(rec, req)
-		if rec.Code != http.StatusInternalServerError {
-			t.Errorf("期望状态码500，得到：%d", rec.Code)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("期望状态码404，得到：%d", rec.Code)
 		}
 	})
 }
@@ -2,8 +2,12 @@ package ant
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"io/fs"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +15,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 )
 
 // TestFileUploader 测试文件上传功能
@@ -215,6 +221,58 @@ func TestFileDownloader(t *testing.T) {
 	}
 }
 
+// TestFileDownloaderHead 测试 HEAD 请求返回下载所需的元数据
+func TestFileDownloaderHead(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFilePath := filepath.Join(tmpDir, "test.txt")
+	if err := os.WriteFile(testFilePath, []byte("test content"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	downloader := &FileDownloader{Dir: tmpDir}
+	server := NewHTTPServer()
+	server.Handle("GET /download", downloader.Handle())
+
+	digest := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+	}
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/download?file=test.txt", nil))
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Fatalf("期望状态码 200 且没有响应体，得到 %d %q", rec.Code, rec.Body.String())
+	}
+	for key, want := range map[string]string{
+		"Content-Length": "12",
+		"Accept-Ranges":  "bytes",
+		"Repr-Digest":    digest("test content"),
+	} {
+		if got := rec.Header().Get(key); got != want {
+			t.Errorf("期望响应头 %s 为 %q，得到 %q", key, want, got)
+		}
+	}
+	if rec.Header().Get("Last-Modified") == "" {
+		t.Error("未设置Last-Modified头")
+	}
+
+	// 摘要计算过后 GET 请求也返回摘要
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/download?file=test.txt", nil))
+	if rec.Body.String() != "test content" || rec.Header().Get("Repr-Digest") != digest("test content") {
+		t.Errorf("GET 请求响应错误: %q %q", rec.Body.String(), rec.Header().Get("Repr-Digest"))
+	}
+
+	// 文件变化后重新计算摘要
+	if err := os.WriteFile(testFilePath, []byte("changed"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/download?file=test.txt", nil))
+	if got := rec.Header().Get("Repr-Digest"); got != digest("changed") {
+		t.Errorf("文件变化后期望新的摘要，得到 %q", got)
+	}
+}
+
 // TestStaticResourceHandler 测试静态资源处理器
 func TestStaticResourceHandler(t *testing.T) {
 	// 创建临时目录和测试文件
@@ -265,8 +323,8 @@ func TestStaticResourceHandler(t *testing.T) {
 		{
 			name:           "文件不存在",
 			fileName:       "nonexistent.txt",
-			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   "打开文件失败",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "文件不存在",
 		},
 		{
 			name:           "非法路径",
@@ -332,6 +390,136 @@ func TestStaticResourceHandler(t *testing.T) {
 	}
 }
 
+// TestStaticResourceHandlerStreaming 测试不会被缓存的大文件直接流式发送
+func TestStaticResourceHandlerStreaming(t *testing.T) {
+	tmpDir := t.TempDir()
+	large := strings.Repeat("0123456789", 10000)
+	if err := os.WriteFile(filepath.Join(tmpDir, "large.txt"), []byte(large), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "small.txt"), []byte("small"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	handler := NewStaticResourceHandler(tmpDir, "/static", WithFileCache(1024, 10))
+	server := NewHTTPServer()
+	server.Handle("GET /static/{file}", handler.Handle)
+
+	for _, name := range []string{"large.txt", "small.txt"} {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/"+name, nil))
+		want, _ := os.ReadFile(filepath.Join(tmpDir, name))
+		if rec.Code != http.StatusOK || rec.Body.String() != string(want) {
+			t.Fatalf("%s 的响应不正确: %d，%d 字节", name, rec.Code, rec.Body.Len())
+		}
+		if rec.Header().Get("Content-Length") != fmt.Sprint(len(want)) || rec.Header().Get("Content-Type") != "text/plain" {
+			t.Errorf("%s 的响应头不正确: %v", name, rec.Header())
+		}
+	}
+	if _, ok := handler.readFileFromData("large.txt"); ok {
+		t.Error("超出缓存大小的文件不应被缓存")
+	}
+	if _, ok := handler.readFileFromData("small.txt"); !ok {
+		t.Error("小文件应被缓存")
+	}
+
+	// 流式发送时先写入响应头再读取文件，读入内存时先读完文件再写响应头
+	rec := httptest.NewRecorder()
+	fsys := &probeFS{MapFS: fstest.MapFS{"large.txt": {Data: []byte(large)}}, rec: rec}
+	fsHandler := NewStaticFSHandler(fsys, "/static", WithFileCache(1024, 10))
+	server = NewHTTPServer()
+	server.Handle("GET /static/{file}", fsHandler.Handle)
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/large.txt", nil))
+	if rec.Body.String() != large {
+		t.Fatalf("响应体不正确: %d 字节", rec.Body.Len())
+	}
+	if !fsys.streamed {
+		t.Error("超出缓存大小的文件应在读取之前写入响应头，而不是整体读入内存")
+	}
+	if _, ok := fsHandler.readFileFromData("large.txt"); ok {
+		t.Error("超出缓存大小的文件不应被缓存")
+	}
+}
+
+// probeFS 记录第一次读取文件时响应头是否已经写入
+type probeFS struct {
+	fstest.MapFS
+	rec      *httptest.ResponseRecorder
+	streamed bool
+}
+
+func (p *probeFS) Open(name string) (fs.File, error) {
+	f, err := p.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &probeFile{File: f, fs: p}, nil
+}
+
+// probeFile 第一次读取时检查响应头
+type probeFile struct {
+	fs.File
+	fs   *probeFS
+	read bool
+}
+
+func (f *probeFile) Read(b []byte) (int, error) {
+	if !f.read {
+		f.read = true
+		f.fs.streamed = f.fs.rec.Header().Get("Content-Length") != ""
+	}
+	return f.File.Read(b)
+}
+
+// TestServerStatic 测试通过 Static 与 StaticFS 挂载静态资源
+func TestServerStatic(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "css"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "css", "app.css"), []byte("body{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewHTTPServer()
+	server.Static("/assets/", dir)
+	server.StaticFS("/embed", fstest.MapFS{
+		"index.html": {Data: []byte("<html></html>")},
+	})
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{http.MethodGet, "/assets/css/app.css", http.StatusOK, "body{}"},
+		{http.MethodHead, "/assets/css/app.css", http.StatusOK, ""},
+		{http.MethodGet, "/assets/css/missing.css", http.StatusNotFound, "文件不存在"},
+		{http.MethodGet, "/assets/css", http.StatusNotFound, "文件不存在"},
+		{http.MethodGet, "/embed/index.html", http.StatusOK, "<html></html>"},
+		{http.MethodGet, "/embed/missing.html", http.StatusNotFound, "文件不存在"},
+		{http.MethodPost, "/assets/css/app.css", http.StatusMethodNotAllowed, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s %s 期望状态码 %d，得到 %d", tt.method, tt.path, tt.wantStatus, rec.Code)
+		}
+		if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+			t.Errorf("%s %s 期望响应体 %q，得到 %q", tt.method, tt.path, tt.wantBody, rec.Body.String())
+		}
+	}
+
+	if err := server.Validate(); err != nil {
+		t.Errorf("期望目录检查通过，得到 %v", err)
+	}
+	server.Static("/missing", filepath.Join(dir, "missing"))
+	if err := server.Validate(); err == nil {
+		t.Error("期望目录不存在的错误")
+	}
+}
+
 // 在 Windows 环境下，因权限问题测试用例无法通过
 
 func TestFileUploaderError(t *testing.T) {
@@ -687,3 +875,232 @@ func TestWithMoreExtension(t *testing.T) {
 		})
 	}
 }
+
+func TestWithCacheRule(t *testing.T) {
+	server := NewHTTPServer()
+	server.StaticFS("/static", fstest.MapFS{
+		"index.html":       {Data: []byte("<html></html>")},
+		"docs/guide.html":  {Data: []byte("<html></html>")},
+		"assets/app.js":    {Data: []byte("app")},
+		"assets/js/lib.js": {Data: []byte("lib")},
+		"robots.txt":       {Data: []byte("User-agent: *")},
+		"private/data.txt": {Data: []byte("data")},
+	},
+		WithCacheRule("/**.html", CacheControl{NoCache: true}),
+		WithCacheRule("/assets/**", CacheControl{Public: true, MaxAge: 365 * 24 * time.Hour, Immutable: true}),
+		WithCacheRule("/private/*", CacheControl{}),
+		WithCacheControl(CacheControl{Public: true, MaxAge: time.Hour}),
+	)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/static/index.html", "no-cache"},
+		{"/static/docs/guide.html", "no-cache"},
+		{"/static/assets/app.js", "public, max-age=31536000, immutable"},
+		{"/static/assets/js/lib.js", "public, max-age=31536000, immutable"},
+		{"/static/robots.txt", "public, max-age=3600"},
+		{"/static/private/data.txt", ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s 期望状态码 200，得到 %d", tt.path, rec.Code)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != tt.want {
+			t.Errorf("%s 的 Cache-Control 期望为 %q，得到 %q", tt.path, tt.want, got)
+		}
+	}
+}
+
+// TestStaticAccessCheck 测试静态资源与文件下载的访问检查
+func TestStaticAccessCheck(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"public.txt", "secret.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(ctx *Context, name string) error {
+		if name != "secret.txt" {
+			return nil
+		}
+		switch ctx.Req.Header.Get("X-Role") {
+		case "":
+			return ErrUnauthorized
+		case "admin":
+			return nil
+		default:
+			return ErrForbidden
+		}
+	}
+	server := NewHTTPServer()
+	server.Static("/files", dir, WithFileCache(1<<20, 10), WithAccessCheck(check))
+	server.Handle("GET /download", (&FileDownloader{Dir: dir, AccessCheck: check}).Handle())
+
+	tests := []struct {
+		path       string
+		role       string
+		wantStatus int
+	}{
+		{"/files/public.txt", "", http.StatusOK},
+		{"/files/secret.txt", "admin", http.StatusOK},
+		// 文件已被缓存，同样需要检查
+		{"/files/secret.txt", "", http.StatusUnauthorized},
+		{"/files/secret.txt", "user", http.StatusForbidden},
+		{"/download?file=secret.txt", "user", http.StatusForbidden},
+		{"/download?file=secret.txt", "admin", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.role != "" {
+			req.Header.Set("X-Role", tt.role)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s (%s) 期望状态码 %d，得到 %d", tt.path, tt.role, tt.wantStatus, rec.Code)
+		}
+		if rec.Code == http.StatusOK && strings.HasPrefix(tt.path, "/files/") &&
+			rec.Header().Get("Cache-Control") != "private, max-age=31536000" {
+			t.Errorf("受保护的文件不应被共享缓存，得到 %s", rec.Header().Get("Cache-Control"))
+		}
+	}
+}
+
+// TestStaticAccessCheckCacheControl 测试受保护的文件总是使用 private 的缓存指令
+func TestStaticAccessCheckCacheControl(t *testing.T) {
+	server := NewHTTPServer()
+	server.StaticFS("/static", fstest.MapFS{
+		"app.js":   {Data: []byte("app")},
+		"data.txt": {Data: []byte("data")},
+	},
+		WithCacheRule("/*.js", CacheControl{MaxAge: 10 * time.Minute, SMaxAge: time.Hour}),
+		WithCacheControl(CacheControl{}),
+		WithAccessCheck(func(ctx *Context, name string) error { return nil }),
+	)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		// 规则中没有 public 时同样需要加上 private
+		{"/static/app.js", "private, max-age=600"},
+		{"/static/data.txt", "private"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if got := rec.Header().Get("Cache-Control"); got != tt.want {
+			t.Errorf("%s 的 Cache-Control 期望为 %q，得到 %q", tt.path, tt.want, got)
+		}
+	}
+}
+
+// TestFileDownloaderRange 测试断点续传与条件请求
+func TestFileDownloaderRange(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "test.txt"), []byte("test content"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	var size int
+	server := NewHTTPServer()
+	server.Use(func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			size = ctx.Resp.(ResponseWriter).Size()
+		}
+	})
+	server.Handle("GET /download", (&FileDownloader{Dir: dir}).Handle())
+
+	do := func(header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/download?file=test.txt", nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(nil)
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Header().Get("Accept-Ranges") != "bytes" || size != 12 {
+		t.Fatalf("完整下载的响应不正确: %d %v %d", rec.Code, rec.Header(), size)
+	}
+
+	rec = do(map[string]string{"Range": "bytes=5-", "If-Range": etag})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "content" ||
+		rec.Header().Get("Content-Range") != "bytes 5-11/12" || size != 7 {
+		t.Errorf("续传的响应不正确: %d %v %q", rec.Code, rec.Header(), rec.Body.String())
+	}
+
+	// 文件已经变化时 If-Range 不匹配，返回完整的文件
+	rec = do(map[string]string{"Range": "bytes=5-", "If-Range": `"stale"`})
+	if rec.Code != http.StatusOK || rec.Body.String() != "test content" {
+		t.Errorf("If-Range 不匹配时应返回完整的文件，实际为 %d %q", rec.Code, rec.Body.String())
+	}
+
+	if rec = do(map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("ETag 匹配时应响应 304，实际为 %d", rec.Code)
+	}
+	if rec = do(map[string]string{"Range": "bytes=100-"}); rec.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("范围超出文件大小时应响应 416，实际为 %d", rec.Code)
+	}
+}
+
+// TestFileUploaderChecksum 测试扩展名白名单与摘要校验
+func TestFileUploaderChecksum(t *testing.T) {
+	tmpDir := t.TempDir()
+	dst := filepath.Join(tmpDir, "report.txt")
+	if err := os.WriteFile(dst, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	uploader := &FileUploader{
+		FileField:     "file",
+		DstPathFunc:   func(fh *multipart.FileHeader) string { return filepath.Join(tmpDir, fh.Filename) },
+		AllowedExts:   []string{".TXT"},
+		ChecksumField: "sha256",
+	}
+	sum := sha256.Sum256([]byte("new content"))
+
+	tests := []struct {
+		name     string
+		filename string
+		checksum string
+		wantCode int
+		wantFile string
+	}{
+		{name: "不允许的扩展名", filename: "report.exe", wantCode: http.StatusUnsupportedMediaType, wantFile: "old"},
+		{name: "校验和不匹配", filename: "report.txt", checksum: strings.Repeat("0", 64), wantCode: http.StatusBadRequest, wantFile: "old"},
+		{name: "校验和匹配", filename: "report.txt", checksum: strings.ToUpper(hex.EncodeToString(sum[:])), wantCode: http.StatusOK, wantFile: "new content"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			if tt.checksum != "" {
+				_ = writer.WriteField("sha256", tt.checksum)
+			}
+			part, _ := writer.CreateFormFile("file", tt.filename)
+			_, _ = io.WriteString(part, "new content")
+			_ = writer.Close()
+			req := httptest.NewRequest(http.MethodPost, "/upload", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			ctx := &Context{Req: req, Resp: httptest.NewRecorder()}
+
+			uploader.Handle()(ctx)
+			if ctx.RespStatusCode != tt.wantCode {
+				t.Errorf("期望状态码 %d, 得到 %d: %s", tt.wantCode, ctx.RespStatusCode, ctx.RespData)
+			}
+			if got, _ := os.ReadFile(dst); string(got) != tt.wantFile {
+				t.Errorf("期望文件内容 %q, 得到 %q", tt.wantFile, got)
+			}
+			if names := tempFiles(t, tmpDir); len(names) != 1 {
+				t.Errorf("临时文件应被删除，实际为 %v", names)
+			}
+		})
+	}
+}
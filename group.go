@@ -0,0 +1,121 @@
+package ant
+
+import (
+	"strings"
+)
+
+// RouterGroup 路由分组，组内的路由共享路径前缀与中间件
+// 组内中间件位于全局中间件之内、路由处理函数之外，外层分组的中间件先于内层分组执行
+type RouterGroup struct {
+	server *HTTPServer
+	prefix string
+	mdls   []Middleware
+	// cors 组内路由的跨域策略，为nil时使用全局策略
+	cors *CORSPolicy
+}
+
+// Group 创建路由分组
+// prefix: 路径前缀，例如 "/api/v1"，必须以 "/" 开头，末尾的 "/" 会被去掉
+// mdls: 只作用于组内路由的中间件
+//
+// 例如：
+//
+//	api := server.Group("/api/v1", auth)
+//	api.Handle("GET /users", listUsers) // 注册为 "GET /api/v1/users"
+//	admin := api.Group("/admin", requireAdmin)
+//	admin.Handle("DELETE /users/{id}", deleteUser) // 依次经过 auth 与 requireAdmin
+func (s *HTTPServer) Group(prefix string, mdls ...Middleware) *RouterGroup {
+	return &RouterGroup{server: s, prefix: cleanGroupPrefix(prefix), mdls: append([]Middleware(nil), mdls...)}
+}
+
+// Group 创建嵌套的路由分组，前缀与中间件都追加在当前分组之后
+func (g *RouterGroup) Group(prefix string, mdls ...Middleware) *RouterGroup {
+	all := make([]Middleware, 0, len(g.mdls)+len(mdls))
+	all = append(append(all, g.mdls...), mdls...)
+	return &RouterGroup{server: g.server, prefix: g.prefix + cleanGroupPrefix(prefix), mdls: all, cors: g.cors}
+}
+
+// Use 为分组追加中间件
+// 注意：只对之后注册的路由与创建的子分组生效
+func (g *RouterGroup) Use(mdls ...Middleware) {
+	g.mdls = append(g.mdls, mdls...)
+}
+
+// CORS 设置组内路由的跨域策略，覆盖 middleware/cors 的全局策略
+// 策略写入路由的 RouteMeta.CORS，通过 Describe 单独设置了策略的路由不受影响
+// 注意：与 Use 相同，只对之后注册的路由与创建的子分组生效
+//
+//	partner := server.Group("/partner")
+//	partner.CORS(ant.CORSPolicy{AllowOrigins: []string{"https://*.partner.com"}, AllowCredentials: true})
+func (g *RouterGroup) CORS(policy CORSPolicy) {
+	g.cors = &policy
+}
+
+// Prefix 返回分组的完整路径前缀
+func (g *RouterGroup) Prefix() string {
+	return g.prefix
+}
+
+// Handle 在分组内注册路由
+// pattern: 相对于分组前缀的路由模式，例如 "GET /users/{id}"，"/" 表示分组下的所有路径
+func (g *RouterGroup) Handle(pattern string, handler HandleFunc) {
+	pattern = g.Pattern(pattern)
+	g.server.handle(pattern, handler, g.mdls...)
+	g.applyCORS(pattern)
+}
+
+// Describe 设置组内路由的描述信息，pattern 与 Handle 中使用的相同
+// meta.CORS 为nil时使用分组的跨域策略
+func (g *RouterGroup) Describe(pattern string, meta RouteMeta) {
+	if meta.CORS == nil {
+		meta.CORS = g.cors
+	}
+	g.server.Describe(g.Pattern(pattern), meta)
+}
+
+// Resource 在分组内按 RESTful 约定注册资源的路由，分组的中间件位于资源的中间件之外
+func (g *RouterGroup) Resource(prefix string, controller any, opts ...ResourceOption) {
+	opts = append([]ResourceOption{ResourceWithMiddleware(g.mdls...)}, opts...)
+	registered := len(g.server.Routes())
+	g.server.Resource(g.prefix+cleanGroupPrefix(prefix), controller, opts...)
+	for _, pattern := range g.server.Routes()[registered:] {
+		g.applyCORS(pattern)
+	}
+}
+
+// applyCORS 将分组的跨域策略写入路由的描述信息，已有策略时保持不变
+func (g *RouterGroup) applyCORS(pattern string) {
+	if g.cors == nil {
+		return
+	}
+	g.server.UpdateRouteMeta(pattern, func(meta *RouteMeta) {
+		if meta.CORS == nil {
+			meta.CORS = g.cors
+		}
+	})
+}
+
+// Pattern 返回加上分组前缀后的完整路由模式，方法与主机名保持不变
+// 例如分组 "/api" 中的 "GET example.com/users" 为 "GET example.com/api/users"
+func (g *RouterGroup) Pattern(pattern string) string {
+	method, rest := "", pattern
+	if i := strings.IndexAny(rest, " \t"); i >= 0 {
+		method, rest = rest[:i]+" ", strings.TrimLeft(rest[i:], " \t")
+	}
+	host, path := "", rest
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		host, path = rest[:i], rest[i:]
+	}
+	if path == "" {
+		panic("web: 分组内的路由 " + pattern + " 缺少路径")
+	}
+	return method + host + g.prefix + path
+}
+
+// cleanGroupPrefix 校验前缀并去掉末尾的 "/"
+func cleanGroupPrefix(prefix string) string {
+	if !strings.HasPrefix(prefix, "/") {
+		panic("web: 路由分组的前缀必须以 / 开头: " + prefix)
+	}
+	return strings.TrimRight(prefix, "/")
+}
@@ -0,0 +1,60 @@
+package ant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouterGroup(t *testing.T) {
+	var calls []string
+	mark := func(name string) Middleware {
+		return func(next HandleFunc) HandleFunc {
+			return func(ctx *Context) {
+				calls = append(calls, name)
+				next(ctx)
+			}
+		}
+	}
+	server := NewHTTPServer()
+	server.Use(mark("global"))
+	api := server.Group("/api/v1/", mark("api"))
+	api.Handle("GET /ping", func(ctx *Context) { ctx.RespData = []byte("pong") })
+	admin := api.Group("/admin", mark("admin"))
+	admin.Use(mark("audit"))
+	admin.Handle("DELETE /users/{id}", func(ctx *Context) { ctx.RespData = []byte("deleted " + ctx.Req.PathValue("id")) })
+	admin.Describe("DELETE /users/{id}", RouteMeta{Summary: "删除用户"})
+	api.Resource("/posts", readOnlyController{}, ResourceWithIDParam("postID"))
+	server.Handle("GET /ping", func(ctx *Context) { ctx.RespData = []byte("root") })
+
+	tests := []struct {
+		method, path, body, calls string
+	}{
+		{http.MethodGet, "/api/v1/ping", "pong", "global,api"},
+		{http.MethodDelete, "/api/v1/admin/users/7", "deleted 7", "global,api,admin,audit"},
+		{http.MethodGet, "/api/v1/posts/3", "post 3", "global,api"},
+		{http.MethodGet, "/ping", "root", "global"},
+	}
+	for _, tt := range tests {
+		calls = nil
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Body.String() != tt.body {
+			t.Errorf("%s %s 的响应应为 %q，实际为 %q", tt.method, tt.path, tt.body, w.Body.String())
+		}
+		if got := strings.Join(calls, ","); got != tt.calls {
+			t.Errorf("%s %s 的中间件应为 %s，实际为 %s", tt.method, tt.path, tt.calls, got)
+		}
+	}
+
+	if meta, ok := server.RouteMeta("DELETE /api/v1/admin/users/{id}"); !ok || meta.Summary != "删除用户" {
+		t.Errorf("分组内的描述信息应使用完整的路由模式，实际为 %+v", meta)
+	}
+	if got := admin.Pattern("GET example.com/"); got != "GET example.com/api/v1/admin/" {
+		t.Errorf("完整的路由模式不正确: %s", got)
+	}
+	if err := catchPanic(func() { server.Group("api") }); err == nil {
+		t.Error("前缀不以 / 开头时应 panic")
+	}
+}
@@ -0,0 +1,36 @@
+package ant
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MountGRPC 在同一端口上托管 gRPC 服务
+// gRPC 请求（HTTP/2 且 Content-Type 以 application/grpc 开头）交给 h 处理，其余请求仍由路由处理
+// h: gRPC 服务，*grpc.Server 实现了 http.Handler，可以直接传入
+// mdls: 作用于 gRPC 请求的中间件，例如鉴权与指标，不会自动应用通过 Use 注册的全局中间件
+// 注意：
+// 1. 明文部署需要同时使用 ServerWithH2C，TLS 部署会自动协商 HTTP/2
+// 2. 中间件看到的 Req.Pattern 为 gRPC 的完整方法名，例如 "/helloworld.Greeter/SayHello"
+// 3. 中间件未调用 next 时写入的 HTTP 状态码会被 gRPC 客户端映射为对应的状态，例如 401 对应 Unauthenticated
+func (s *HTTPServer) MountGRPC(h http.Handler, mdls ...Middleware) {
+	handler := func(ctx *Context) {
+		h.ServeHTTP(ctx.Resp, ctx.Req)
+		ctx.hijacked = true
+	}
+	for i := len(mdls) - 1; i >= 0; i-- {
+		handler = mdls[i](handler)
+	}
+	s.grpcHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Pattern = r.URL.Path
+		ctx := s.acquireContext(w, r)
+		defer releaseContext(ctx)
+		handler(ctx)
+		s.writeResponse(ctx)
+	})
+}
+
+// isGRPCRequest 判断请求是否为 gRPC 请求
+func isGRPCRequest(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
@@ -0,0 +1,73 @@
+package ant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestMountGRPC 测试按协议与 Content-Type 分流 gRPC 请求
+func TestMountGRPC(t *testing.T) {
+	grpcServer := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Grpc-Status", "0")
+		_, _ = w.Write([]byte("grpc:" + r.URL.Path))
+	})
+
+	var patterns []string
+	auth := func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			patterns = append(patterns, ctx.Req.Pattern)
+			if ctx.Req.Header.Get("Authorization") == "" {
+				ctx.RespStatusCode = http.StatusUnauthorized
+				return
+			}
+			next(ctx)
+		}
+	}
+
+	server := NewHTTPServer()
+	server.Handle("POST /helloworld.Greeter/SayHello", func(ctx *Context) {
+		ctx.RespData = []byte("rest")
+	})
+	server.MountGRPC(grpcServer, auth)
+
+	newReq := func(proto int, contentType string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/helloworld.Greeter/SayHello", nil)
+		req.ProtoMajor = proto
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Authorization", "Bearer token")
+		return req
+	}
+
+	testCases := []struct {
+		name     string
+		req      *http.Request
+		wantBody string
+	}{
+		{name: "gRPC 请求", req: newReq(2, "application/grpc"), wantBody: "grpc:/helloworld.Greeter/SayHello"},
+		{name: "gRPC 编码后缀", req: newReq(2, "application/grpc+proto"), wantBody: "grpc:/helloworld.Greeter/SayHello"},
+		{name: "HTTP/1.1 请求", req: newReq(1, "application/grpc"), wantBody: "rest"},
+		{name: "HTTP/2 JSON 请求", req: newReq(2, "application/json"), wantBody: "rest"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, tc.req)
+			if rec.Body.String() != tc.wantBody {
+				t.Errorf("期望响应 %q, 得到 %q", tc.wantBody, rec.Body.String())
+			}
+		})
+	}
+	if len(patterns) != 2 || patterns[0] != "/helloworld.Greeter/SayHello" {
+		t.Errorf("中间件应只作用于 gRPC 请求并看到完整方法名, 得到 %v", patterns)
+	}
+
+	// 中间件拒绝请求时不调用 gRPC 服务
+	req := newReq(2, "application/grpc")
+	req.Header.Del("Authorization")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized || rec.Body.Len() != 0 {
+		t.Errorf("期望返回 401, 得到 %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,155 @@
+package ant
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// HTTPError 携带HTTP状态码的错误
+// JSONHandler 的处理函数返回该错误时，使用其中的状态码与信息响应客户端
+type HTTPError struct {
+	// Code HTTP状态码
+	Code int
+	// Message 返回给客户端的错误信息
+	Message string
+	// Err 原始错误，不会返回给客户端，可以通过 errors.Unwrap 获取
+	Err error
+}
+
+// NewHTTPError 创建携带HTTP状态码的错误
+// code: HTTP状态码
+// message: 返回给客户端的错误信息，为空时使用状态码对应的描述
+func NewHTTPError(code int, message string) *HTTPError {
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	return &HTTPError{Code: code, Message: message}
+}
+
+// Error 实现 error 接口
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap 返回原始错误
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// Validator 请求参数校验接口
+// JSONHandler 绑定请求后，如果请求类型实现了该接口则调用 Validate，返回错误时响应 400
+type Validator interface {
+	Validate() error
+}
+
+// jsonError 错误响应的JSON结构
+type jsonError struct {
+	Error string `json:"error"`
+}
+
+// JSONHandler 将类型化的处理函数适配为 HandleFunc
+// 依次完成以下步骤：
+// 1. 通过 ctx.Bind 将请求体绑定到 Req，请求体为空时使用零值，
+// Content-Type 不受支持时响应 415，解析失败响应 400
+// 2. Req 中带有 path 标签的字段从路径参数中获取，例如 ID int64 `json:"-" path:"id"`，
+// 路由声明了 {id:int} 等约束时直接使用命中路由时转换好的值，无法转换时响应 400
+// 3. Req 实现了 Validator 时进行校验，失败响应 400
+// 4. 请求的 context 已经取消或超时时不调用处理函数，分别响应 499 与 504，
+// 否则调用处理函数，成功时将 Resp 序列化为JSON并以 200 响应
+// 5. 处理函数返回错误时，*HTTPError 使用其中的状态码与信息，
+// 包装了 ErrNotFound 等通用业务错误时按 ErrorStatus 响应对应的状态码与错误内容，
+// 返回的错误包装了 context.Canceled 或 context.DeadlineExceeded 时同样响应 499 或 504，
+// 其他错误响应 500 且不向客户端暴露错误内容
+// 错误响应写入 ctx.RespStatusCode 与 ctx.RespData 并设置 ctx.Err，
+// 因此可以被 errhandle 等错误处理中间件替换或上报
+//
+// 例如：
+//
+//	server.Handle("POST /users", ant.JSONHandler(func(ctx *ant.Context, req CreateUserReq) (User, error) {
+//		return svc.Create(ctx.Req.Context(), req)
+//	}))
+func JSONHandler[Req, Resp any](fn func(ctx *Context, req Req) (Resp, error)) HandleFunc {
+	return func(ctx *Context) {
+		var req Req
+		if err := ctx.bindBody(&req); err != nil {
+			if errors.Is(err, ErrUnsupportedMediaType) {
+				ctx.respError(&HTTPError{Code: http.StatusUnsupportedMediaType, Message: "不支持的 Content-Type", Err: err})
+				return
+			}
+			ctx.respError(&HTTPError{Code: http.StatusBadRequest, Message: "请求体解析失败", Err: err})
+			return
+		}
+		if err := ctx.bindPathParams(&req); err != nil {
+			ctx.respError(err)
+			return
+		}
+		if err := validate(&req); err != nil {
+			ctx.respError(&HTTPError{Code: http.StatusBadRequest, Message: err.Error(), Err: err})
+			return
+		}
+		// 客户端已经断开或超过截止时间时不再调用处理函数
+		if err := ctx.Req.Context().Err(); err != nil {
+			ctx.respError(err)
+			return
+		}
+		resp, err := fn(ctx, req)
+		if err != nil {
+			ctx.respError(err)
+			return
+		}
+		if err = ctx.RespJSONOK(resp); err != nil {
+			ctx.respError(err)
+		}
+	}
+}
+
+// bindBody 与 Bind 相同，但请求体为空时不返回错误
+func (c *Context) bindBody(val any) error {
+	if c.Req.Body == nil || c.Req.Body == http.NoBody {
+		return nil
+	}
+	err := c.Bind(val)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// validate 在 req 或其指针实现了 Validator 时执行校验
+func validate[Req any](req *Req) error {
+	if v, ok := any(*req).(Validator); ok {
+		return v.Validate()
+	}
+	if v, ok := any(req).(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// respError 将错误写入缓冲的响应，供后续的错误处理中间件处理
+func (c *Context) respError(err error) {
+	c.Err = err
+	code := ErrorStatus(err)
+	msg := statusText(code)
+	var he *HTTPError
+	if errors.As(err, &he) {
+		msg = he.Message
+	} else if code < http.StatusInternalServerError && code != StatusClientClosedRequest {
+		msg = err.Error()
+	}
+	// 已经直接写入了响应时只能记录错误
+	if rw, ok := c.Resp.(ResponseWriter); ok && rw.Written() {
+		return
+	}
+	bs, mErr := c.JSONCodec().Marshal(jsonError{Error: msg})
+	if mErr != nil {
+		bs = []byte(msg)
+	}
+	c.Resp.Header().Set("Content-Type", "application/json; charset=utf-8")
+	c.RespStatusCode = code
+	c.RespData = bs
+}
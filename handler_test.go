@@ -0,0 +1,90 @@
+package ant
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type createUserReq struct {
+	Name string `json:"name"`
+}
+
+func (r createUserReq) Validate() error {
+	if r.Name == "" {
+		return errors.New("name 不能为空")
+	}
+	return nil
+}
+
+type userResp struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// TestJSONHandler 测试类型化处理函数的绑定、校验、调用与序列化
+func TestJSONHandler(t *testing.T) {
+	errInternal := errors.New("数据库连接失败")
+	server := NewHTTPServer()
+	var gotErr error
+	server.Use(func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			gotErr = ctx.Err
+		}
+	})
+	server.Handle("POST /users", JSONHandler(func(ctx *Context, req createUserReq) (userResp, error) {
+		switch req.Name {
+		case "taken":
+			return userResp{}, NewHTTPError(http.StatusConflict, "用户名已存在")
+		case "broken":
+			return userResp{}, errInternal
+		}
+		return userResp{ID: 1, Name: req.Name}, nil
+	}))
+	server.Handle("GET /ping", JSONHandler(func(ctx *Context, _ struct{}) (string, error) {
+		return "pong", nil
+	}))
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+		wantErr  error
+	}{
+		{name: "成功", method: http.MethodPost, path: "/users", body: `{"name":"tom"}`, wantCode: http.StatusOK, wantBody: `{"id":1,"name":"tom"}`},
+		{name: "空请求体", method: http.MethodGet, path: "/ping", wantCode: http.StatusOK, wantBody: `"pong"`},
+		{name: "解析失败", method: http.MethodPost, path: "/users", body: `{"name":`, wantCode: http.StatusBadRequest, wantBody: `{"error":"请求体解析失败"}`},
+		{name: "未知字段", method: http.MethodPost, path: "/users", body: `{"age":1}`, wantCode: http.StatusBadRequest, wantBody: `{"error":"请求体解析失败"}`},
+		{name: "校验失败", method: http.MethodPost, path: "/users", body: `{}`, wantCode: http.StatusBadRequest, wantBody: `{"error":"name 不能为空"}`},
+		{name: "HTTPError", method: http.MethodPost, path: "/users", body: `{"name":"taken"}`, wantCode: http.StatusConflict, wantBody: `{"error":"用户名已存在"}`},
+		{name: "内部错误不暴露", method: http.MethodPost, path: "/users", body: `{"name":"broken"}`, wantCode: http.StatusInternalServerError, wantBody: `{"error":"Internal Server Error"}`, wantErr: errInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotErr = nil
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if w.Code != tt.wantCode {
+				t.Errorf("期望状态码 %d，实际 %d", tt.wantCode, w.Code)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tt.wantBody {
+				t.Errorf("期望响应体 %s，实际 %s", tt.wantBody, body)
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+				t.Errorf("期望JSON响应，实际 Content-Type %q", ct)
+			}
+			if tt.wantErr != nil && !errors.Is(gotErr, tt.wantErr) {
+				t.Errorf("ctx.Err 应为处理函数返回的错误，实际 %v", gotErr)
+			}
+			if tt.wantCode != http.StatusOK && gotErr == nil {
+				t.Error("错误响应应设置 ctx.Err")
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package ant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// handoffEnv 传递给子进程的继承监听器数量
+const handoffEnv = "ANT_INHERITED_FDS"
+
+// inheritedFDsStart 子进程中第一个继承的文件描述符，与 exec.Cmd.ExtraFiles 的约定一致
+const inheritedFDsStart = 3
+
+// handoffCommand 创建用于替换当前进程的子进程命令，测试时可以替换
+var handoffCommand = func() (*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd, nil
+}
+
+// filer 可以导出文件描述符的监听器，例如 *net.TCPListener 与 *net.UnixListener
+type filer interface {
+	File() (*os.File, error)
+}
+
+// Handoff 启动新的进程接管监听器，然后优雅地关闭当前服务器
+// 用于在不断开连接的前提下重启服务，例如升级二进制文件后收到 SIGHUP 时调用
+// ctx: 控制当前服务器关闭的截止时间
+// 返回值: 启动子进程或关闭当前服务器的错误
+// 注意：
+// 1. 只有通过 RunListeners 打开的监听器会被传递，子进程需要以相同的配置调用 RunListeners
+// 2. 子进程使用当前进程的可执行文件与命令行参数启动，继承标准输入输出
+// 3. 监听器在两个进程间共享，子进程就绪前到达的连接会在队列中等待，不会被拒绝
+func (s *HTTPServer) Handoff(ctx context.Context) error {
+	s.mu.Lock()
+	listeners := make([]net.Listener, len(s.listeners))
+	copy(listeners, s.listeners)
+	s.mu.Unlock()
+	if len(listeners) == 0 {
+		return errors.New("web: 没有可以交接的监听器，需要通过 RunListeners 启动服务器")
+	}
+
+	files := make([]*os.File, 0, len(listeners))
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for _, l := range listeners {
+		fl, ok := l.(filer)
+		if !ok {
+			return fmt.Errorf("web: 监听器 %s 不支持导出文件描述符", l.Addr())
+		}
+		// 父进程关闭 unix 监听器时不能删除套接字文件，否则子进程将无法被访问
+		if ul, ok := l.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+		f, err := fl.File()
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+
+	cmd, err := handoffCommand()
+	if err != nil {
+		return err
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, handoffEnv+"="+strconv.Itoa(len(files)))
+	cmd.ExtraFiles = files
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("web: 启动子进程失败: %w", err)
+	}
+	// 子进程独立运行，不等待其退出
+	_ = cmd.Process.Release()
+
+	return s.Shutdown(ctx)
+}
+
+// InheritedListeners 返回从父进程继承的监听器
+// 返回值: 按父进程 RunListeners 配置顺序排列的监听器，不是由 Handoff 启动时返回空切片
+// 注意：调用后会清除对应的环境变量，避免孙进程重复使用
+func InheritedListeners() ([]net.Listener, error) {
+	raw := os.Getenv(handoffEnv)
+	if raw == "" {
+		return nil, nil
+	}
+	_ = os.Unsetenv(handoffEnv)
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("web: 非法的继承监听器数量 %q", raw)
+	}
+
+	listeners := make([]net.Listener, 0, n)
+	for fd := inheritedFDsStart; fd < inheritedFDsStart+n; fd++ {
+		f := os.NewFile(uintptr(fd), "inherited-"+strconv.Itoa(fd))
+		l, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			for _, opened := range listeners {
+				_ = opened.Close()
+			}
+			return nil, fmt.Errorf("web: 文件描述符 %d 不是有效的监听器: %w", fd, err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
@@ -0,0 +1,83 @@
+package ant
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// TestHandoffHelperProcess 由 TestHandoff 以子进程方式启动，使用继承的监听器提供服务
+func TestHandoffHelperProcess(t *testing.T) {
+	if os.Getenv("ANT_HANDOFF_HELPER") != "1" {
+		t.Skip("仅作为 TestHandoff 的子进程运行")
+	}
+	server := NewHTTPServer()
+	server.Handle("GET /who", func(ctx *Context) {
+		ctx.RespData = []byte("child")
+	})
+	go func() {
+		time.Sleep(3 * time.Second)
+		_ = server.Shutdown(context.Background())
+	}()
+	_ = server.RunListeners(TCP("127.0.0.1:0"))
+}
+
+// TestHandoff 测试将监听器交接给子进程后，同一地址由子进程继续提供服务
+func TestHandoff(t *testing.T) {
+	old := handoffCommand
+	handoffCommand = func() (*exec.Cmd, error) {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHandoffHelperProcess$")
+		cmd.Env = append(os.Environ(), "ANT_HANDOFF_HELPER=1")
+		return cmd, nil
+	}
+	defer func() { handoffCommand = old }()
+
+	server := NewHTTPServer()
+	server.Handle("GET /who", func(ctx *Context) {
+		ctx.RespData = []byte("parent")
+	})
+	addr := freeAddr(t)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- server.RunListeners(TCP(addr))
+	}()
+	waitServing(t, addr)
+
+	get := func() string {
+		resp, err := http.Get("http://" + addr + "/who")
+		if err != nil {
+			return ""
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	if who := get(); who != "parent" {
+		t.Fatalf("交接前期望由父进程处理, 得到 %q", who)
+	}
+
+	if err := server.Handoff(context.Background()); err != nil {
+		t.Fatalf("交接失败: %v", err)
+	}
+	if err := <-runErr; err != http.ErrServerClosed {
+		t.Errorf("期望父进程返回 http.ErrServerClosed, 得到 %v", err)
+	}
+	waitFor(t, func() bool {
+		return get() == "child"
+	})
+}
+
+// TestHandoffWithoutListeners 测试没有可交接的监听器时返回错误
+func TestHandoffWithoutListeners(t *testing.T) {
+	if err := NewHTTPServer().Handoff(context.Background()); err == nil {
+		t.Error("期望返回错误")
+	}
+	t.Setenv(handoffEnv, "")
+	if ls, err := InheritedListeners(); err != nil || ls != nil {
+		t.Errorf("未设置环境变量时期望返回空结果, 得到 %v %v", ls, err)
+	}
+}
@@ -0,0 +1,46 @@
+package health
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pinger 支持连通性探测的依赖，例如会话存储、仓储或数据库连接
+type Pinger interface {
+	// Ping 探测依赖是否可用
+	Ping(ctx context.Context) error
+}
+
+// Ping 创建探测 Pinger 连通性的检查器
+func Ping(p Pinger) Checker {
+	return CheckerFunc(p.Ping)
+}
+
+// MaxValue 创建检查指标是否超过阈值的检查器
+// 适用于事件总线积压深度、队列长度等计量值
+// value: 获取当前指标值的函数
+// max: 允许的最大值
+func MaxValue(value func() int64, max int64) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if v := value(); v > max {
+			return fmt.Errorf("health: 当前值 %d 超过阈值 %d", v, max)
+		}
+		return nil
+	})
+}
+
+// DiskSpace 创建检查磁盘剩余空间的检查器
+// path: 需要检查的目录
+// minFree: 允许的最小剩余字节数
+func DiskSpace(path string, minFree uint64) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		free, err := freeBytes(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("health: 磁盘剩余空间 %d 字节低于阈值 %d 字节", free, minFree)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package health
+
+import "errors"
+
+// freeBytes 当前平台不支持查询磁盘剩余空间
+func freeBytes(path string) (uint64, error) {
+	return 0, errors.New("health: 当前平台不支持磁盘空间检查")
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package health
+
+import "syscall"
+
+// freeBytes 返回目录所在文件系统中非特权用户可用的剩余字节数
+func freeBytes(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
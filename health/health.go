@@ -0,0 +1,213 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+// Status 健康状态
+type Status string
+
+const (
+	// StatusUp 所有依赖均正常
+	StatusUp Status = "up"
+	// StatusDegraded 存在非关键依赖异常，服务仍可对外提供能力
+	StatusDegraded Status = "degraded"
+	// StatusDown 存在关键依赖异常，服务不应再接收流量
+	StatusDown Status = "down"
+)
+
+// Checker 依赖检查器
+type Checker interface {
+	// Check 检查依赖是否可用，返回nil表示正常
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc 函数形式的依赖检查器
+type CheckerFunc func(ctx context.Context) error
+
+// Check 实现 Checker 接口
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// CheckResult 单项依赖检查的结果
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    Status  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report 就绪检查的汇总报告
+type Report struct {
+	Status Status        `json:"status"`
+	Time   time.Time     `json:"time"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// liveness 存活检查的响应内容
+type liveness struct {
+	Status     Status  `json:"status"`
+	UptimeSec  float64 `json:"uptime_sec"`
+	Goroutines int     `json:"goroutines"`
+}
+
+// registration 已注册的依赖检查器
+type registration struct {
+	name     string
+	checker  Checker
+	critical bool
+}
+
+// Health 健康检查管理器
+// 存活检查只反映进程本身的状态，就绪检查则汇总所有已注册的依赖检查器
+type Health struct {
+	mu      sync.RWMutex
+	checks  []registration
+	timeout time.Duration
+	started time.Time
+}
+
+// Option 健康检查管理器的配置选项
+type Option func(h *Health)
+
+// WithTimeout 设置单项依赖检查的超时时间，默认为 2 秒
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *Health) {
+		h.timeout = timeout
+	}
+}
+
+// New 创建健康检查管理器
+func New(opts ...Option) *Health {
+	h := &Health{
+		timeout: 2 * time.Second,
+		started: time.Now(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Register 注册关键依赖检查器，关键依赖异常时服务状态为 StatusDown
+func (h *Health) Register(name string, c Checker) {
+	h.register(name, c, true)
+}
+
+// RegisterOptional 注册非关键依赖检查器，非关键依赖异常时服务状态为 StatusDegraded
+func (h *Health) RegisterOptional(name string, c Checker) {
+	h.register(name, c, false)
+}
+
+func (h *Health) register(name string, c Checker, critical bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, registration{name: name, checker: c, critical: critical})
+}
+
+// Check 并发执行所有依赖检查器并汇总结果
+func (h *Health) Check(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := make([]registration, len(h.checks))
+	copy(checks, h.checks)
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, reg := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.runCheck(ctx, reg)
+		}()
+	}
+	wg.Wait()
+
+	status := StatusUp
+	for _, r := range results {
+		if r.Status == StatusUp {
+			continue
+		}
+		if r.Critical {
+			status = StatusDown
+			break
+		}
+		status = StatusDegraded
+	}
+
+	return Report{
+		Status: status,
+		Time:   time.Now(),
+		Checks: results,
+	}
+}
+
+// runCheck 在超时控制下执行单项依赖检查
+func (h *Health) runCheck(ctx context.Context, reg registration) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- reg.checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := CheckResult{
+		Name:      reg.name,
+		Status:    StatusUp,
+		Critical:  reg.critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// LivenessHandler 返回存活检查的处理函数
+// 只反映进程自身的状态，不访问任何外部依赖
+func (h *Health) LivenessHandler() ant.HandleFunc {
+	return func(ctx *ant.Context) {
+		_ = ctx.RespJSONOK(liveness{
+			Status:     StatusUp,
+			UptimeSec:  time.Since(h.started).Seconds(),
+			Goroutines: runtime.NumGoroutine(),
+		})
+	}
+}
+
+// ReadinessHandler 返回就绪检查的处理函数
+// 状态为 StatusDown 时返回 503，其余情况返回 200
+func (h *Health) ReadinessHandler() ant.HandleFunc {
+	return func(ctx *ant.Context) {
+		report := h.Check(ctx.Req.Context())
+		code := http.StatusOK
+		if report.Status == StatusDown {
+			code = http.StatusServiceUnavailable
+		}
+		_ = ctx.RespJSON(code, report)
+	}
+}
+
+// Mount 在服务器上注册 GET /health 存活检查与 GET /ready 就绪检查
+func (h *Health) Mount(server *ant.HTTPServer) {
+	server.Handle("GET /health", h.LivenessHandler())
+	server.Handle("GET /ready", h.ReadinessHandler())
+}
@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/ant"
+	"github.com/justinwongcn/ant/session/memory"
+)
+
+func TestHealthCheck(t *testing.T) {
+	failing := CheckerFunc(func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	passing := CheckerFunc(func(ctx context.Context) error { return nil })
+
+	tests := []struct {
+		name     string
+		setup    func(h *Health)
+		expected Status
+	}{
+		{
+			name:     "没有依赖",
+			setup:    func(h *Health) {},
+			expected: StatusUp,
+		},
+		{
+			name: "所有依赖正常",
+			setup: func(h *Health) {
+				h.Register("db", passing)
+				h.RegisterOptional("cache", passing)
+			},
+			expected: StatusUp,
+		},
+		{
+			name: "非关键依赖异常",
+			setup: func(h *Health) {
+				h.Register("db", passing)
+				h.RegisterOptional("cache", failing)
+			},
+			expected: StatusDegraded,
+		},
+		{
+			name: "关键依赖异常",
+			setup: func(h *Health) {
+				h.Register("db", failing)
+				h.RegisterOptional("cache", failing)
+			},
+			expected: StatusDown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New()
+			tt.setup(h)
+			report := h.Check(context.Background())
+			if report.Status != tt.expected {
+				t.Errorf("期望状态 %s, 得到 %s", tt.expected, report.Status)
+			}
+		})
+	}
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	h := New(WithTimeout(20 * time.Millisecond))
+	h.Register("slow", CheckerFunc(func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}))
+
+	start := time.Now()
+	report := h.Check(context.Background())
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("依赖检查未在超时后返回")
+	}
+	if report.Status != StatusDown || report.Checks[0].Error == "" {
+		t.Errorf("超时的关键依赖应导致状态为 down: %+v", report)
+	}
+}
+
+func TestHealthHandlers(t *testing.T) {
+	h := New()
+	h.Register("session", Ping(memory.NewStore(time.Minute)))
+	h.RegisterOptional("backlog", MaxValue(func() int64 { return 10 }, 5))
+
+	server := ant.NewHTTPServer()
+	h.Mount(server)
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("存活检查期望状态码 200, 得到 %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("降级状态期望状态码 200, 得到 %d", rec.Code)
+	}
+	var report Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != StatusDegraded || len(report.Checks) != 2 {
+		t.Errorf("就绪报告不正确: %+v", report)
+	}
+
+	h.Register("db", CheckerFunc(func(ctx context.Context) error {
+		return errors.New("down")
+	}))
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("关键依赖异常期望状态码 503, 得到 %d", rec.Code)
+	}
+}
+
+func TestDiskSpace(t *testing.T) {
+	if err := DiskSpace(os.TempDir(), 1).Check(context.Background()); err != nil {
+		t.Errorf("期望临时目录剩余空间充足: %v", err)
+	}
+	if err := DiskSpace(os.TempDir(), ^uint64(0)).Check(context.Background()); err == nil {
+		t.Error("期望剩余空间不足时返回错误")
+	}
+}
@@ -0,0 +1,39 @@
+package ant
+
+import (
+	"net/http"
+)
+
+// ServerWithH2C 允许在明文连接上使用 HTTP/2（h2c）
+// 适用于服务网格或内网中直接使用 HTTP/2 的客户端，例如 gRPC 风格的调用方
+// 开启后同一端口仍然接受 HTTP/1.1 请求，TLS 连接上的 HTTP/2 不受影响
+// 注意：只支持先验知识（prior knowledge）方式，不支持通过 Upgrade 头升级
+func ServerWithH2C() ServerOption {
+	return func(server *HTTPServer) {
+		server.protocols = new(http.Protocols)
+		server.protocols.SetHTTP1(true)
+		server.protocols.SetHTTP2(true)
+		server.protocols.SetUnencryptedHTTP2(true)
+	}
+}
+
+// ServerWithHTTP2Config 设置 HTTP/2 的连接参数
+// cfg: 最大并发流数、帧大小、窗口大小等配置，零值字段使用标准库的默认值
+func ServerWithHTTP2Config(cfg http.HTTP2Config) ServerOption {
+	return func(server *HTTPServer) {
+		server.http2Config = &cfg
+	}
+}
+
+// Push 通过 HTTP/2 服务器推送主动发送资源
+// target: 需要推送的资源路径，例如 "/static/app.js"
+// opts: 推送选项，可以为nil
+// 返回值: 连接不支持推送时返回 http.ErrNotSupported
+// 注意：主流浏览器已不再处理服务器推送，调用方应将其视为优化而非依赖
+func (c *Context) Push(target string, opts *http.PushOptions) error {
+	pusher, ok := c.Resp.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return pusher.Push(target, opts)
+}
@@ -0,0 +1,72 @@
+package ant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestServerWithH2C 测试明文 HTTP/2 与 HTTP/1.1 共用同一端口
+func TestServerWithH2C(t *testing.T) {
+	server := NewHTTPServer(
+		ServerWithH2C(),
+		ServerWithHTTP2Config(http.HTTP2Config{MaxConcurrentStreams: 16}),
+	)
+	server.Handle("GET /proto", func(ctx *Context) {
+		ctx.RespData = []byte(ctx.Req.Proto)
+	})
+
+	addr := freeAddr(t)
+	go func() {
+		_ = server.Run(addr)
+	}()
+	waitServing(t, addr)
+	t.Cleanup(func() {
+		_ = server.Shutdown(context.Background())
+	})
+
+	var h2c http.Protocols
+	h2c.SetUnencryptedHTTP2(true)
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{Protocols: &h2c},
+	}
+	resp, err := client.Get("http://" + addr + "/proto")
+	if err != nil {
+		t.Fatalf("h2c 请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("期望使用 HTTP/2, 得到 %s", resp.Proto)
+	}
+
+	resp, err = http.Get("http://" + addr + "/proto")
+	if err != nil {
+		t.Fatalf("HTTP/1.1 请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 1 {
+		t.Errorf("期望使用 HTTP/1.1, 得到 %s", resp.Proto)
+	}
+
+	server.mu.Lock()
+	srv := server.servers[0]
+	server.mu.Unlock()
+	if srv.HTTP2 == nil || srv.HTTP2.MaxConcurrentStreams != 16 {
+		t.Errorf("HTTP/2 配置未生效: %+v", srv.HTTP2)
+	}
+}
+
+// TestContextPush 测试不支持推送的连接返回 http.ErrNotSupported
+func TestContextPush(t *testing.T) {
+	ctx := &Context{
+		Req:  httptest.NewRequest(http.MethodGet, "/", nil),
+		Resp: httptest.NewRecorder(),
+	}
+	if err := ctx.Push("/app.js", nil); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("期望 http.ErrNotSupported, 得到 %v", err)
+	}
+}
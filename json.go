@@ -0,0 +1,69 @@
+package ant
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONDecoder 流式JSON解码器
+// *json.Decoder 以及 sonic、go-json 的解码器都实现了该接口
+type JSONDecoder interface {
+	// DisallowUnknownFields 遇到目标结构体中不存在的字段时返回错误
+	DisallowUnknownFields()
+	// Decode 解析下一个JSON值
+	Decode(v any) error
+}
+
+// JSONCodec JSON编解码器
+// 由 BindJSON、RespJSON 以及 WebSocket 的 JSON 读写方法使用，
+// 高吞吐的场景可以替换为 sonic、go-json 等更快的实现
+type JSONCodec interface {
+	// Marshal 将值序列化为JSON
+	Marshal(v any) ([]byte, error)
+	// Unmarshal 将JSON解析到 v 中
+	Unmarshal(data []byte, v any) error
+	// NewDecoder 创建从 r 读取的解码器
+	NewDecoder(r io.Reader) JSONDecoder
+}
+
+// StdJSONCodec 基于标准库 encoding/json 的编解码器，作为默认实现
+type StdJSONCodec struct{}
+
+// Marshal 实现 JSONCodec 接口
+func (StdJSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal 实现 JSONCodec 接口
+func (StdJSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// NewDecoder 实现 JSONCodec 接口
+func (StdJSONCodec) NewDecoder(r io.Reader) JSONDecoder {
+	return json.NewDecoder(r)
+}
+
+// ServerWithJSONCodec 设置服务器使用的JSON编解码器
+// codec: 编解码器，例如基于 sonic 的实现：
+//
+//	type sonicCodec struct{}
+//
+//	func (sonicCodec) Marshal(v any) ([]byte, error)      { return sonic.Marshal(v) }
+//	func (sonicCodec) Unmarshal(data []byte, v any) error { return sonic.Unmarshal(data, v) }
+//	func (sonicCodec) NewDecoder(r io.Reader) ant.JSONDecoder {
+//		return sonic.ConfigDefault.NewDecoder(r)
+//	}
+func ServerWithJSONCodec(codec JSONCodec) ServerOption {
+	return func(server *HTTPServer) {
+		server.jsonCodec = codec
+	}
+}
+
+// JSONCodec 返回当前请求使用的JSON编解码器
+func (c *Context) JSONCodec() JSONCodec {
+	if c.jsonCodec == nil {
+		return StdJSONCodec{}
+	}
+	return c.jsonCodec
+}
@@ -0,0 +1,120 @@
+package ant
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	gojson "github.com/goccy/go-json"
+)
+
+// goJSONCodec 基于 go-json 的编解码器，用于验证第三方实现
+type goJSONCodec struct {
+	marshals atomic.Int32
+}
+
+func (c *goJSONCodec) Marshal(v any) ([]byte, error) {
+	c.marshals.Add(1)
+	return gojson.Marshal(v)
+}
+
+func (c *goJSONCodec) Unmarshal(data []byte, v any) error {
+	return gojson.Unmarshal(data, v)
+}
+
+func (c *goJSONCodec) NewDecoder(r io.Reader) JSONDecoder {
+	return gojson.NewDecoder(r)
+}
+
+// jsonPayload 一致性测试使用的数据结构
+type jsonPayload struct {
+	Name  string            `json:"name"`
+	Age   int               `json:"age,omitempty"`
+	Tags  []string          `json:"tags"`
+	Extra map[string]string `json:"extra,omitempty"`
+}
+
+// TestJSONCodecConformance 测试各个编解码器在 BindJSON 与 RespJSON 中的行为一致
+func TestJSONCodecConformance(t *testing.T) {
+	codecs := map[string]JSONCodec{
+		"std":     StdJSONCodec{},
+		"go-json": &goJSONCodec{},
+	}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			server := NewHTTPServer(ServerWithJSONCodec(codec))
+			server.Handle("POST /echo", func(ctx *Context) {
+				var p jsonPayload
+				if err := ctx.BindJSON(&p); err != nil {
+					ctx.RespStatusCode = http.StatusBadRequest
+					_ = ctx.WriteString(err.Error())
+					return
+				}
+				_ = ctx.RespJSONOK(p)
+			})
+
+			testCases := []struct {
+				name     string
+				body     string
+				wantCode int
+				wantBody string
+			}{
+				{
+					name:     "往返",
+					body:     `{"name":"tom","age":18,"tags":["a","b"],"extra":{"k":"v"}}`,
+					wantCode: http.StatusOK,
+					wantBody: `{"name":"tom","age":18,"tags":["a","b"],"extra":{"k":"v"}}`,
+				},
+				{
+					name:     "省略空值",
+					body:     `{"name":"tom","tags":null}`,
+					wantCode: http.StatusOK,
+					wantBody: `{"name":"tom","tags":null}`,
+				},
+				{
+					name:     "未知字段",
+					body:     `{"name":"tom","unknown":1}`,
+					wantCode: http.StatusBadRequest,
+				},
+				{
+					name:     "类型不匹配",
+					body:     `{"name":1}`,
+					wantCode: http.StatusBadRequest,
+				},
+				{
+					name:     "格式错误",
+					body:     `{"name":`,
+					wantCode: http.StatusBadRequest,
+				},
+			}
+			for _, tc := range testCases {
+				t.Run(tc.name, func(t *testing.T) {
+					rec := httptest.NewRecorder()
+					server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(tc.body)))
+					if rec.Code != tc.wantCode {
+						t.Fatalf("期望状态码 %d, 得到 %d: %s", tc.wantCode, rec.Code, rec.Body.String())
+					}
+					if tc.wantBody != "" && strings.TrimSpace(rec.Body.String()) != tc.wantBody {
+						t.Errorf("期望响应 %s, 得到 %s", tc.wantBody, rec.Body.String())
+					}
+				})
+			}
+		})
+	}
+
+	if n := codecs["go-json"].(*goJSONCodec).marshals.Load(); n == 0 {
+		t.Error("期望 RespJSON 使用配置的编解码器")
+	}
+}
+
+// TestContextJSONCodecDefault 测试未配置时使用标准库
+func TestContextJSONCodecDefault(t *testing.T) {
+	ctx := &Context{}
+	if _, ok := ctx.JSONCodec().(StdJSONCodec); !ok {
+		t.Errorf("期望默认使用 StdJSONCodec, 得到 %T", ctx.JSONCodec())
+	}
+}
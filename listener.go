@@ -0,0 +1,208 @@
+package ant
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// ListenerConfig 单个监听器的配置
+// Listener 非nil时直接使用该监听器，否则按 Network 与 Address 创建
+type ListenerConfig struct {
+	// Network 网络类型，支持 "tcp"、"tcp4"、"tcp6" 与 "unix"，默认为 "tcp"
+	Network string
+	// Address 监听地址，unix 网络下为套接字文件路径
+	Address string
+	// Listener 预先打开的监听器，例如通过 SystemdListeners 获得
+	Listener net.Listener
+	// TLSConfig 该监听器的 TLS 配置，为nil时使用服务器的 TLS 配置
+	// TLSConfig 为nil且未指定证书文件时使用明文 HTTP
+	TLSConfig *tls.Config
+	// CertFile 与 KeyFile 证书文件路径，TLSConfig 中已包含证书时可以为空
+	CertFile string
+	KeyFile  string
+	// ProxyProtocol 是否解析 PROXY protocol 头部，只接受服务器受信任代理发送的头部
+	// 需要通过 ServerWithTrustedProxies 配置受信任的代理，否则 RunListeners 返回 ErrProxyProtocolUntrusted
+	ProxyProtocol bool
+}
+
+// TCP 创建 TCP 监听配置
+func TCP(addr string) ListenerConfig {
+	return ListenerConfig{Network: "tcp", Address: addr}
+}
+
+// Unix 创建 unix 域套接字监听配置
+// path: 套接字文件路径，已存在的同名套接字文件会被移除
+func Unix(path string) ListenerConfig {
+	return ListenerConfig{Network: "unix", Address: path}
+}
+
+// FromListener 使用预先打开的监听器创建配置
+func FromListener(l net.Listener) ListenerConfig {
+	return ListenerConfig{Listener: l}
+}
+
+// WithTLS 为监听器开启 TLS
+// cfg: TLS 配置，为nil时使用服务器的 TLS 配置
+// certFile, keyFile: 证书文件路径，cfg 中已包含证书时可以为空
+func (c ListenerConfig) WithTLS(cfg *tls.Config, certFile, keyFile string) ListenerConfig {
+	c.TLSConfig = cfg
+	c.CertFile = certFile
+	c.KeyFile = keyFile
+	return c
+}
+
+// WithProxyProtocol 为监听器开启 PROXY protocol 解析
+// 只解析 ServerWithTrustedProxies 中受信任代理发送的头部，没有配置受信任的代理时无法启动
+func (c ListenerConfig) WithProxyProtocol() ListenerConfig {
+	c.ProxyProtocol = true
+	return c
+}
+
+// listen 按配置打开监听器
+func (c ListenerConfig) listen() (net.Listener, error) {
+	if c.Listener != nil {
+		return c.Listener, nil
+	}
+	network := c.Network
+	if network == "" {
+		network = "tcp"
+	}
+	if network == "unix" {
+		// 移除上次运行遗留的套接字文件，其他类型的文件不做处理
+		if fi, err := os.Lstat(c.Address); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(c.Address)
+		}
+	}
+	return net.Listen(network, c.Address)
+}
+
+// Serve 在指定的监听器上处理请求
+// l: 监听器，服务器关闭时会被关闭
+// 返回值: 服务器运行过程中的错误，通过 Shutdown 关闭时返回 http.ErrServerClosed
+func (s *HTTPServer) Serve(l net.Listener) error {
+	return s.newServer(l.Addr().String()).Serve(l)
+}
+
+// RunListeners 同时在多个监听器上启动服务器
+// cfgs: 监听器配置，每个监听器可以单独配置 TLS
+// 返回值: 任意一个监听器出错时返回该错误，通过 Shutdown 关闭时返回 http.ErrServerClosed
+// 注意：
+// 1. 所有监听器都打开成功后才开始处理请求，任意一个打开失败时已打开的监听器会被关闭
+// 2. 任意一个监听器出错不会影响其他监听器，需要调用 Shutdown 关闭
+// 3. 由 Handoff 启动的子进程会按顺序使用从父进程继承的监听器，而不是重新监听
+func (s *HTTPServer) RunListeners(cfgs ...ListenerConfig) error {
+	if len(cfgs) == 0 {
+		return errors.New("web: RunListeners 至少需要一个监听器")
+	}
+	// 任意对端都可以通过 PROXY protocol 头部伪造客户端地址，必须限定受信任的代理
+	for _, cfg := range cfgs {
+		if cfg.ProxyProtocol && s.trustedProxies == nil {
+			return ErrProxyProtocolUntrusted
+		}
+	}
+	inherited, err := InheritedListeners()
+	if err != nil {
+		return err
+	}
+	if len(inherited) > 0 && len(inherited) != len(cfgs) {
+		for _, l := range inherited {
+			_ = l.Close()
+		}
+		return fmt.Errorf("web: 继承了 %d 个监听器，但配置了 %d 个", len(inherited), len(cfgs))
+	}
+
+	listeners := make([]net.Listener, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		if len(inherited) > 0 {
+			cfg.Listener = inherited[i]
+		}
+		l, err := cfg.listen()
+		if err != nil {
+			for _, opened := range listeners {
+				_ = opened.Close()
+			}
+			return fmt.Errorf("web: 监听 %s %s 失败: %w", cfg.Network, cfg.Address, err)
+		}
+		listeners = append(listeners, l)
+	}
+	s.mu.Lock()
+	s.listeners = append(s.listeners, listeners...)
+	s.mu.Unlock()
+
+	addrs := make([]string, len(listeners))
+	for i, l := range listeners {
+		addrs[i] = l.Addr().String()
+	}
+	s.reportStartup(addrs...)
+
+	errCh := make(chan error, len(listeners))
+	for i, l := range listeners {
+		cfg := cfgs[i]
+		srv := s.newServer(l.Addr().String())
+		if cfg.ProxyProtocol {
+			l = ProxyProtocolListener(l, s.trustedProxies)
+		}
+		if !cfg.isTLS() {
+			go func() {
+				errCh <- srv.Serve(l)
+			}()
+			continue
+		}
+		srv.TLSConfig = s.serverTLSConfig()
+		if cfg.TLSConfig != nil {
+			srv.TLSConfig = cfg.TLSConfig.Clone()
+		}
+		go func() {
+			errCh <- srv.ServeTLS(l, cfg.CertFile, cfg.KeyFile)
+		}()
+	}
+	return <-errCh
+}
+
+// isTLS 判断监听器是否需要开启 TLS
+func (c ListenerConfig) isTLS() bool {
+	return c.TLSConfig != nil || c.CertFile != ""
+}
+
+// listenFDsStart systemd 传递的第一个文件描述符
+var listenFDsStart = 3
+
+// SystemdListeners 返回通过 systemd 套接字激活传入的监听器
+// 返回值: 按 systemd 传递顺序排列的监听器，未通过套接字激活启动时返回空切片
+// 注意：调用后会清除 LISTEN_PID、LISTEN_FDS 与 LISTEN_FDNAMES 环境变量，避免子进程重复使用
+func SystemdListeners() ([]net.Listener, error) {
+	defer func() {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+
+	listeners := make([]net.Listener, 0, n)
+	for fd := listenFDsStart; fd < listenFDsStart+n; fd++ {
+		f := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+		l, err := net.FileListener(f)
+		// FileListener 会复制文件描述符，原文件需要关闭
+		_ = f.Close()
+		if err != nil {
+			for _, opened := range listeners {
+				_ = opened.Close()
+			}
+			return nil, fmt.Errorf("web: 文件描述符 %d 不是有效的监听器: %w", fd, err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
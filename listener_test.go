@@ -0,0 +1,95 @@
+package ant
+
+import (
+	"context"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestRunListeners 测试同时在 TCP、TLS 与 unix 套接字上提供服务
+func TestRunListeners(t *testing.T) {
+	cert := newTestCert(t, "server", nil, true)
+	server := NewHTTPServer()
+	server.Handle("GET /ping", func(ctx *Context) {
+		ctx.RespData = []byte("pong")
+	})
+
+	plainAddr, tlsAddr := freeAddr(t), freeAddr(t)
+	sock := filepath.Join(t.TempDir(), "ant.sock")
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- server.RunListeners(
+			TCP(plainAddr),
+			TCP(tlsAddr).WithTLS(nil, cert.certFile, cert.keyFile),
+			Unix(sock),
+		)
+	}()
+	waitServing(t, plainAddr)
+	waitServing(t, tlsAddr)
+
+	get := func(client *http.Client, url string) string {
+		t.Helper()
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("请求 %s 失败: %v", url, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	if body := get(http.DefaultClient, "http://"+plainAddr+"/ping"); body != "pong" {
+		t.Errorf("TCP 监听器响应不正确: %q", body)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert.cert)
+	if body := get(tlsClient(roots), "https://"+tlsAddr+"/ping"); body != "pong" {
+		t.Errorf("TLS 监听器响应不正确: %q", body)
+	}
+
+	unixClient := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", sock)
+			},
+		},
+	}
+	if body := get(unixClient, "http://unix/ping"); body != "pong" {
+		t.Errorf("unix 监听器响应不正确: %q", body)
+	}
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-runErr; err != http.ErrServerClosed {
+		t.Errorf("期望返回 http.ErrServerClosed, 得到 %v", err)
+	}
+}
+
+// TestRunListenersOpenError 测试任意监听器打开失败时关闭已打开的监听器
+func TestRunListenersOpenError(t *testing.T) {
+	server := NewHTTPServer()
+	addr := freeAddr(t)
+	err := server.RunListeners(TCP(addr), Unix(filepath.Join(t.TempDir(), "missing", "ant.sock")))
+	if err == nil {
+		t.Fatal("期望打开 unix 套接字失败")
+	}
+	// 第一个监听器应已被关闭，地址可以再次使用
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("已打开的监听器未被关闭: %v", err)
+	}
+	l.Close()
+
+	if err = server.RunListeners(); err == nil {
+		t.Error("未指定监听器时期望返回错误")
+	}
+}
@@ -0,0 +1,55 @@
+//go:build unix
+
+package ant
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+// TestSystemdListeners 测试解析 systemd 套接字激活传入的文件描述符
+func TestSystemdListeners(t *testing.T) {
+	t.Setenv("LISTEN_PID", "")
+	t.Setenv("LISTEN_FDS", "")
+	listeners, err := SystemdListeners()
+	if err != nil || len(listeners) != 0 {
+		t.Fatalf("未通过套接字激活时期望返回空结果, 得到 %v %v", listeners, err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	f, err := l.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 复制出不受 os.File 管理的文件描述符，由 SystemdListeners 负责关闭
+	fd, err := syscall.Dup(int(f.Fd()))
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := listenFDsStart
+	listenFDsStart = fd
+	defer func() { listenFDsStart = old }()
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "1")
+	listeners, err = SystemdListeners()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listeners) != 1 || listeners[0].Addr().String() != l.Addr().String() {
+		t.Fatalf("期望得到地址为 %s 的监听器, 得到 %v", l.Addr(), listeners)
+	}
+	listeners[0].Close()
+	if os.Getenv("LISTEN_FDS") != "" {
+		t.Error("期望清除 LISTEN_FDS 环境变量")
+	}
+}
@@ -0,0 +1,207 @@
+package ant
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 基准测试按子系统命名，CI 中按名称分组与基准分支对比：
+// BenchmarkLoad* 通过真实的 TCP 连接压测 HTTPServer，BenchmarkStatic* 与 BenchmarkRouter* 统计内存占用
+
+// loadUser 压测使用的请求体与响应体
+type loadUser struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+// newLoadServer 创建压测使用的服务器，包含纯文本、路径参数与 JSON 请求体三类路由
+func newLoadServer() *HTTPServer {
+	server := NewHTTPServer()
+	server.Handle("GET /ping", func(ctx *Context) {
+		_ = ctx.WriteString("pong")
+	})
+	server.Handle("GET /users/{id}", func(ctx *Context) {
+		id, err := ctx.PathValue("id").ToInt64()
+		if err != nil {
+			ctx.RespStatusCode = http.StatusBadRequest
+			return
+		}
+		_ = ctx.RespJSONOK(loadUser{ID: int(id), Name: "tom"})
+	})
+	server.Handle("POST /users", func(ctx *Context) {
+		var req loadUser
+		if err := ctx.BindJSON(&req); err != nil {
+			ctx.RespStatusCode = http.StatusBadRequest
+			return
+		}
+		req.ID = 1
+		_ = ctx.RespJSON(http.StatusCreated, req)
+	})
+	return server
+}
+
+// latencyRecorder 汇总各个 goroutine 记录的请求耗时
+type latencyRecorder struct {
+	mu   sync.Mutex
+	all  []time.Duration
+	fail int
+}
+
+func (r *latencyRecorder) add(samples []time.Duration, fail int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.all = append(r.all, samples...)
+	r.fail += fail
+}
+
+// report 输出吞吐量与 p50、p99 延迟，与 wrk 的统计口径一致
+func (r *latencyRecorder) report(b *testing.B, elapsed time.Duration) {
+	if r.fail > 0 {
+		b.Fatalf("%d 个请求失败", r.fail)
+	}
+	if len(r.all) == 0 {
+		return
+	}
+	slices.Sort(r.all)
+	b.ReportMetric(float64(len(r.all))/elapsed.Seconds(), "req/s")
+	b.ReportMetric(float64(r.all[len(r.all)/2].Nanoseconds()), "p50-ns")
+	b.ReportMetric(float64(r.all[len(r.all)*99/100].Nanoseconds()), "p99-ns")
+}
+
+// BenchmarkLoad 通过保持连接的 HTTP 客户端并发压测真实监听的服务器
+// 与 BenchmarkServeHTTP 不同，结果包含连接读写、请求解析与响应序列化的开销
+//
+//	go test -run '^$' -bench BenchmarkLoad -cpu 1,4,8
+func BenchmarkLoad(b *testing.B) {
+	srv := httptest.NewServer(newLoadServer())
+	defer srv.Close()
+	client := &http.Client{Transport: &http.Transport{
+		MaxIdleConnsPerHost: runtime.GOMAXPROCS(0) * 4,
+	}}
+	defer client.CloseIdleConnections()
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+	}{
+		{name: "text", method: http.MethodGet, path: "/ping", wantCode: http.StatusOK},
+		{name: "param_json", method: http.MethodGet, path: "/users/42", wantCode: http.StatusOK},
+		{name: "bind_json", method: http.MethodPost, path: "/users", body: `{"name":"tom","email":"tom@example.com"}`, wantCode: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			rec := &latencyRecorder{}
+			b.ReportAllocs()
+			b.ResetTimer()
+			start := time.Now()
+			b.RunParallel(func(pb *testing.PB) {
+				var samples []time.Duration
+				fail := 0
+				for pb.Next() {
+					req, _ := http.NewRequest(tt.method, srv.URL+tt.path, bytes.NewReader([]byte(tt.body)))
+					begin := time.Now()
+					resp, err := client.Do(req)
+					if err != nil {
+						fail++
+						continue
+					}
+					// 读完响应体才能复用连接
+					_, _ = io.Copy(io.Discard, resp.Body)
+					_ = resp.Body.Close()
+					samples = append(samples, time.Since(begin))
+					if resp.StatusCode != tt.wantCode {
+						fail++
+					}
+				}
+				rec.add(samples, fail)
+			})
+			rec.report(b, time.Since(start))
+		})
+	}
+}
+
+// heapInUse 强制回收后返回堆上存活对象占用的字节数
+func heapInUse() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// BenchmarkStaticCacheMemory 统计静态资源缓存中每个文件的内存占用，B/file 包含文件内容与缓存项的开销
+func BenchmarkStaticCacheMemory(b *testing.B) {
+	for _, size := range []int{1 << 10, 64 << 10} {
+		b.Run(strconv.Itoa(size>>10)+"KB", func(b *testing.B) {
+			const files = 100
+			dir := b.TempDir()
+			for i := 0; i < files; i++ {
+				if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.txt", i)), bytes.Repeat([]byte("a"), size), 0o644); err != nil {
+					b.Fatal(err)
+				}
+			}
+			// 静态资源处理器每次命中缓存都会输出日志
+			log.SetOutput(io.Discard)
+			defer log.SetOutput(os.Stderr)
+			w := &discardWriter{header: make(http.Header)}
+
+			var total uint64
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				before := heapInUse()
+				b.StartTimer()
+				h := NewStaticResourceHandler(dir, "/static", WithFileCache(size*2, files))
+				for j := 0; j < files; j++ {
+					req := httptest.NewRequest(http.MethodGet, "/static/"+strconv.Itoa(j)+".txt", nil)
+					req.SetPathValue("file", strconv.Itoa(j)+".txt")
+					h.Handle(&Context{Req: req, Resp: w})
+				}
+				b.StopTimer()
+				if after := heapInUse(); after > before {
+					total += after - before
+				}
+				runtime.KeepAlive(h)
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(total)/float64(b.N*files), "B/file")
+		})
+	}
+}
+
+// BenchmarkRouterMemory 统计注册大量路由后每条路由的内存占用，B/route 包含 ServeMux 与路由统计的开销
+func BenchmarkRouterMemory(b *testing.B) {
+	const routes = 1000
+	var total uint64
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		before := heapInUse()
+		b.StartTimer()
+		server := NewHTTPServer()
+		for j := 0; j < routes; j++ {
+			server.Handle(fmt.Sprintf("GET /api/v1/resource%d/{id}", j), func(ctx *Context) {})
+		}
+		b.StopTimer()
+		if after := heapInUse(); after > before {
+			total += after - before
+		}
+		runtime.KeepAlive(server)
+		b.StartTimer()
+	}
+	b.ReportMetric(float64(total)/float64(b.N*routes), "B/route")
+}
@@ -0,0 +1,59 @@
+package ant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// LongPoll 长轮询，用于不支持 SSE（见 Context.SSE）与 WebSocket 的客户端
+// 阻塞直到 wait 返回数据或超时：
+//   - wait 返回数据时以 JSON 响应 200
+//   - 超时或 wait 返回 nil 时响应 204，客户端应立即发起下一次轮询
+//   - 客户端断开连接时不写入响应，返回请求上下文的错误
+//   - wait 返回其他错误时直接返回该错误
+//
+// wait 需要在传入的 ctx 结束时尽快返回，例如：
+//
+//	server.Handle("GET /messages", func(ctx *ant.Context) {
+//		err := ctx.LongPoll(30*time.Second, func(pctx context.Context) (any, error) {
+//			select {
+//			case msg := <-inbox:
+//				return msg, nil
+//			case <-pctx.Done():
+//				return nil, pctx.Err()
+//			}
+//		})
+//		...
+//	})
+//
+// 注意：timeout 需要小于服务器的 WriteTimeout 以及代理的超时时间
+func (c *Context) LongPoll(timeout time.Duration, wait func(ctx context.Context) (any, error)) error {
+	reqCtx := c.Req.Context()
+	pollCtx, cancel := context.WithTimeout(reqCtx, timeout)
+	defer cancel()
+
+	val, err := wait(pollCtx)
+	// 客户端已断开，响应不会被读取
+	if reqCtx.Err() != nil {
+		return reqCtx.Err()
+	}
+	c.Resp.Header().Set("Cache-Control", "no-store")
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if val == nil {
+		c.RespStatusCode = http.StatusNoContent
+		c.RespData = nil
+		return nil
+	}
+	bs, err := c.JSONCodec().Marshal(val)
+	if err != nil {
+		return err
+	}
+	c.Resp.Header().Set("Content-Type", "application/json; charset=utf-8")
+	c.RespStatusCode = http.StatusOK
+	c.RespData = bs
+	return nil
+}
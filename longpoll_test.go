@@ -0,0 +1,77 @@
+package ant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLongPoll(t *testing.T) {
+	inbox := make(chan string, 1)
+	var pollErr error
+	done := make(chan struct{}, 1)
+	s := NewHTTPServer()
+	s.Handle("GET /messages", func(ctx *Context) {
+		pollErr = ctx.LongPoll(50*time.Millisecond, func(pctx context.Context) (any, error) {
+			select {
+			case msg := <-inbox:
+				if msg == "fail" {
+					return nil, errors.New("读取失败")
+				}
+				return map[string]string{"msg": msg}, nil
+			case <-pctx.Done():
+				return nil, pctx.Err()
+			}
+		})
+		done <- struct{}{}
+	})
+
+	t.Run("有数据", func(t *testing.T) {
+		inbox <- "hello"
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/messages", nil))
+		<-done
+		if w.Code != http.StatusOK || w.Body.String() != `{"msg":"hello"}` {
+			t.Errorf("应响应数据，实际为 %d %q", w.Code, w.Body.String())
+		}
+		if w.Header().Get("Cache-Control") != "no-store" {
+			t.Errorf("长轮询的响应不应被缓存")
+		}
+	})
+
+	t.Run("超时", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/messages", nil))
+		<-done
+		if w.Code != http.StatusNoContent || pollErr != nil {
+			t.Errorf("超时应响应 204，实际为 %d %v", w.Code, pollErr)
+		}
+	})
+
+	t.Run("客户端断开", func(t *testing.T) {
+		reqCtx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest(http.MethodGet, "/messages", nil).WithContext(reqCtx)
+		time.AfterFunc(10*time.Millisecond, cancel)
+		start := time.Now()
+		s.ServeHTTP(httptest.NewRecorder(), req)
+		<-done
+		if !errors.Is(pollErr, context.Canceled) {
+			t.Errorf("客户端断开时应返回 context.Canceled，实际为 %v", pollErr)
+		}
+		if time.Since(start) >= 50*time.Millisecond {
+			t.Errorf("客户端断开后应立即返回")
+		}
+	})
+
+	t.Run("等待出错", func(t *testing.T) {
+		inbox <- "fail"
+		s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/messages", nil))
+		<-done
+		if pollErr == nil || pollErr.Error() != "读取失败" {
+			t.Errorf("应返回等待函数的错误，实际为 %v", pollErr)
+		}
+	})
+}
@@ -1,5 +1,63 @@
 package ant
 
+import (
+	"mime"
+	"strings"
+)
+
 // Middleware 定义中间件类型
 // 中间件函数接收下一个处理器，返回一个新的处理器
 type Middleware func(next HandleFunc) HandleFunc
+
+// When 只在 cond 返回 true 时执行中间件 mw，否则直接调用下一个处理器
+func When(mw Middleware, cond func(ctx *Context) bool) Middleware {
+	return func(next HandleFunc) HandleFunc {
+		wrapped := mw(next)
+		return func(ctx *Context) {
+			if cond(ctx) {
+				wrapped(ctx)
+				return
+			}
+			next(ctx)
+		}
+	}
+}
+
+// ForMethods 只对指定方法的请求执行中间件 mw，方法名区分大小写，例如：
+//
+//	server.Use(ant.ForMethods(validation, http.MethodPost, http.MethodPut))
+func ForMethods(mw Middleware, methods ...string) Middleware {
+	set := make(map[string]struct{}, len(methods))
+	for _, m := range methods {
+		set[m] = struct{}{}
+	}
+	return When(mw, func(ctx *Context) bool {
+		_, ok := set[ctx.Req.Method]
+		return ok
+	})
+}
+
+// ForContentType 只对指定 Content-Type 的请求执行中间件 mw，例如：
+//
+//	server.Use(ant.ForContentType(bodyDump, "application/json", "text/*"))
+//
+// 比较时忽略参数（例如 charset）与大小写，"type/*" 匹配该类型下的所有子类型
+// 没有 Content-Type 或无法解析的请求不执行 mw
+func ForContentType(mw Middleware, contentTypes ...string) Middleware {
+	types := make([]string, 0, len(contentTypes))
+	for _, ct := range contentTypes {
+		types = append(types, strings.ToLower(ct))
+	}
+	return When(mw, func(ctx *Context) bool {
+		mediaType, _, err := mime.ParseMediaType(ctx.Req.Header.Get("Content-Type"))
+		if err != nil {
+			return false
+		}
+		for _, t := range types {
+			if t == mediaType || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, t[:len(t)-1])) {
+				return true
+			}
+		}
+		return false
+	})
+}
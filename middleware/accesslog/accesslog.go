@@ -12,14 +12,21 @@ import (
 type accessLog struct {
 	Timestamp  string        `json:"timestamp"`
 	Host       string        `json:"host"`
+	ClientIP   string        `json:"client_ip"`
 	HTTPMethod string        `json:"http_method"`
 	Path       string        `json:"path"`
+	Status     int           `json:"status"`
+	Bytes      int           `json:"bytes"`
 	Duration   time.Duration `json:"duration"`
+	// Header 请求头，只在 LevelDebug 级别记录，ant.DefaultSensitiveHeaders 中的请求头已脱敏
+	Header map[string][]string `json:"header,omitempty"`
 }
 
 // MiddlewareBuilder 中间件构建器
 type MiddlewareBuilder struct {
 	logFunc func(accessLog string)
+	// ctrl 可在运行时调整的日志级别与采样率
+	ctrl *control
 }
 
 // LogFunc 设置自定义日志记录函数
@@ -34,6 +41,7 @@ func NewBuilder() *MiddlewareBuilder {
 		logFunc: func(accessLog string) {
 			log.Println(accessLog)
 		},
+		ctrl: newControl(),
 	}
 }
 
@@ -46,15 +54,28 @@ func (b *MiddlewareBuilder) Build() ant.Middleware {
 			// 执行下一个处理器
 			next(ctx)
 
+			status := ctx.ResponseStatus()
+			// 按日志级别与采样率决定是否记录
+			if !b.control().shouldLog(status) {
+				return
+			}
+
 			// 构建访问日志
 			l := accessLog{
 				Timestamp:  start.Format("2006-01-02 15:04:05"),
 				Host:       ctx.Req.Host,
+				ClientIP:   ctx.ClientIP(),
 				HTTPMethod: ctx.Req.Method,
 				Path:       ctx.Req.URL.Path,
+				Status:     status,
+				Bytes:      responseSize(ctx),
 				Duration:   time.Since(start),
 			}
 
+			if Level(b.control().level.Load()) == LevelDebug {
+				l.Header = ant.RedactHeader(ctx.Req.Header, ant.DefaultSensitiveHeaders)
+			}
+
 			// 序列化并记录日志
 			val, _ := json.Marshal(l)
 			b.logFunc(string(val))
@@ -66,3 +87,12 @@ func (b *MiddlewareBuilder) Build() ant.Middleware {
 func AccessLog() ant.Middleware {
 	return NewBuilder().Build()
 }
+
+// responseSize 返回响应体的字节数
+// 处理函数直接写入响应时以实际写入的字节数为准，否则使用 RespData 的长度
+func responseSize(ctx *ant.Context) int {
+	if rw, ok := ctx.Resp.(ant.ResponseWriter); ok && rw.Size() > 0 {
+		return rw.Size()
+	}
+	return len(ctx.RespData)
+}
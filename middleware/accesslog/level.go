@@ -0,0 +1,230 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/justinwongcn/ant"
+)
+
+// Level 访问日志级别
+type Level int32
+
+const (
+	// LevelDebug 记录所有请求，并附带脱敏后的请求头
+	LevelDebug Level = iota
+	// LevelInfo 记录所有请求，默认级别
+	LevelInfo
+	// LevelWarn 只记录 4xx 与 5xx 请求
+	LevelWarn
+	// LevelError 只记录 5xx 请求
+	LevelError
+	// LevelOff 关闭访问日志
+	LevelOff
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+	LevelOff:   "off",
+}
+
+// String 返回日志级别的名称
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("Level(%d)", int32(l))
+}
+
+// MarshalJSON 将日志级别序列化为名称
+func (l Level) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.String())
+}
+
+// UnmarshalJSON 从名称解析日志级别
+func (l *Level) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	level, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// ParseLevel 根据名称解析日志级别，不区分大小写
+func ParseLevel(name string) (Level, error) {
+	for l, n := range levelNames {
+		if strings.EqualFold(n, name) {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("accesslog: 未知的日志级别 %q", name)
+}
+
+// levelOf 根据响应状态码确定请求对应的日志级别
+func levelOf(status int) Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return LevelError
+	case status >= http.StatusBadRequest:
+		return LevelWarn
+	default:
+		return LevelInfo
+	}
+}
+
+// statusClasses 支持单独配置采样率的状态码类别
+var statusClasses = []string{"1xx", "2xx", "3xx", "4xx", "5xx"}
+
+// classIndex 返回状态码所属类别在 statusClasses 中的下标
+func classIndex(status int) int {
+	idx := status/100 - 1
+	if idx < 0 || idx >= len(statusClasses) {
+		// 非法状态码按 2xx 处理
+		return 1
+	}
+	return idx
+}
+
+// Config 访问日志的运行时配置
+type Config struct {
+	// Level 最低记录级别
+	Level Level `json:"level"`
+	// SampleRates 各状态码类别的采样率，取值范围 [0, 1]，key 为 "2xx"、"5xx" 等
+	SampleRates map[string]float64 `json:"sample_rates"`
+}
+
+// control 支持并发读写的日志级别与采样率
+type control struct {
+	level atomic.Int32
+	// rates 按状态码类别存储采样率的位模式
+	rates [5]atomic.Uint64
+}
+
+// newControl 创建默认配置：Info 级别，全部请求都记录
+func newControl() *control {
+	c := &control{}
+	c.level.Store(int32(LevelInfo))
+	for i := range c.rates {
+		c.rates[i].Store(math.Float64bits(1))
+	}
+	return c
+}
+
+// shouldLog 判断请求是否需要记录
+func (c *control) shouldLog(status int) bool {
+	if levelOf(status) < Level(c.level.Load()) {
+		return false
+	}
+	rate := math.Float64frombits(c.rates[classIndex(status)].Load())
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	default:
+		return rand.Float64() < rate
+	}
+}
+
+// setSampleRate 设置状态码类别的采样率
+func (c *control) setSampleRate(class string, rate float64) error {
+	if rate < 0 || rate > 1 || math.IsNaN(rate) {
+		return fmt.Errorf("accesslog: 采样率 %v 超出范围 [0, 1]", rate)
+	}
+	for i, name := range statusClasses {
+		if strings.EqualFold(name, class) {
+			c.rates[i].Store(math.Float64bits(rate))
+			return nil
+		}
+	}
+	return fmt.Errorf("accesslog: 未知的状态码类别 %q", class)
+}
+
+// config 返回当前配置的快照
+func (c *control) config() Config {
+	cfg := Config{
+		Level:       Level(c.level.Load()),
+		SampleRates: make(map[string]float64, len(statusClasses)),
+	}
+	for i, name := range statusClasses {
+		cfg.SampleRates[name] = math.Float64frombits(c.rates[i].Load())
+	}
+	return cfg
+}
+
+// SetLevel 在运行时调整最低记录级别
+func (b *MiddlewareBuilder) SetLevel(l Level) *MiddlewareBuilder {
+	b.control().level.Store(int32(l))
+	return b
+}
+
+// SetSampleRate 在运行时调整状态码类别的采样率
+// class: 状态码类别，例如 "2xx"、"5xx"
+// rate: 采样率，取值范围 [0, 1]，例如 0.01 表示只记录 1% 的请求
+func (b *MiddlewareBuilder) SetSampleRate(class string, rate float64) error {
+	return b.control().setSampleRate(class, rate)
+}
+
+// Config 返回当前的运行时配置
+func (b *MiddlewareBuilder) Config() Config {
+	return b.control().config()
+}
+
+// AdminHandler 返回用于管理访问日志配置的处理函数
+// GET 返回当前配置；PUT 或 POST 接收 Config 格式的JSON，只更新请求中出现的字段
+func (b *MiddlewareBuilder) AdminHandler() ant.HandleFunc {
+	return func(ctx *ant.Context) {
+		if ctx.Req.Method == http.MethodGet || ctx.Req.Method == http.MethodHead {
+			_ = ctx.RespJSONOK(b.Config())
+			return
+		}
+
+		var req struct {
+			Level       *Level             `json:"level"`
+			SampleRates map[string]float64 `json:"sample_rates"`
+		}
+		if err := ctx.BindJSON(&req); err != nil {
+			ctx.RespStatusCode = http.StatusBadRequest
+			ctx.RespData = []byte(err.Error())
+			return
+		}
+		// 先校验全部采样率，避免部分更新
+		probe := newControl()
+		for class, rate := range req.SampleRates {
+			if err := probe.setSampleRate(class, rate); err != nil {
+				ctx.RespStatusCode = http.StatusBadRequest
+				ctx.RespData = []byte(err.Error())
+				return
+			}
+		}
+
+		if req.Level != nil {
+			b.SetLevel(*req.Level)
+		}
+		for class, rate := range req.SampleRates {
+			_ = b.SetSampleRate(class, rate)
+		}
+		_ = ctx.RespJSONOK(b.Config())
+	}
+}
+
+// control 返回运行时配置，兼容未通过 NewBuilder 创建的构建器
+func (b *MiddlewareBuilder) control() *control {
+	if b.ctrl == nil {
+		b.ctrl = newControl()
+	}
+	return b.ctrl
+}
@@ -0,0 +1,157 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/justinwongcn/ant"
+)
+
+func TestAccessLogLevel(t *testing.T) {
+	count := 0
+	builder := NewBuilder().LogFunc(func(string) { count++ })
+	server := ant.NewHTTPServer()
+	server.Use(builder.Build())
+	server.Handle("GET /status/{code}", func(ctx *ant.Context) {
+		code, _ := ctx.PathValue("code").ToInt64()
+		ctx.RespStatusCode = int(code)
+	})
+
+	send := func(path string) {
+		server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	tests := []struct {
+		name  string
+		level Level
+		want  int
+	}{
+		{name: "info级别记录全部请求", level: LevelInfo, want: 3},
+		{name: "warn级别只记录4xx和5xx", level: LevelWarn, want: 2},
+		{name: "error级别只记录5xx", level: LevelError, want: 1},
+		{name: "关闭日志", level: LevelOff, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count = 0
+			builder.SetLevel(tt.level)
+			send("/status/200")
+			send("/status/404")
+			send("/status/503")
+			if count != tt.want {
+				t.Errorf("期望记录 %d 条日志, 实际 %d 条", tt.want, count)
+			}
+		})
+	}
+}
+
+func TestAccessLogDebugLevel(t *testing.T) {
+	var logs []string
+	builder := NewBuilder().LogFunc(func(l string) { logs = append(logs, l) })
+	server := ant.NewHTTPServer()
+	server.Use(builder.Build())
+	server.Handle("GET /stream", func(ctx *ant.Context) {
+		// 直接写入响应时 RespStatusCode 为 0
+		ctx.Resp.WriteHeader(http.StatusAccepted)
+		_, _ = ctx.Resp.Write([]byte("ok"))
+	})
+
+	send := func() accessLog {
+		req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("X-Request-Id", "req-1")
+		server.ServeHTTP(httptest.NewRecorder(), req)
+		var l accessLog
+		if err := json.Unmarshal([]byte(logs[len(logs)-1]), &l); err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+
+	if l := send(); l.Header != nil || l.Status != http.StatusAccepted {
+		t.Errorf("info级别不应记录请求头, 且状态码应为 202: %+v", l)
+	}
+	builder.SetLevel(LevelDebug)
+	l := send()
+	if l.Header["Authorization"][0] != ant.Redacted || l.Header["X-Request-Id"][0] != "req-1" {
+		t.Errorf("debug级别应记录脱敏后的请求头: %v", l.Header)
+	}
+	if l.Status != http.StatusAccepted {
+		t.Errorf("期望状态码 202, 实际 %d", l.Status)
+	}
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	var logs []string
+	builder := NewBuilder().LogFunc(func(s string) { logs = append(logs, s) })
+	if err := builder.SetSampleRate("2xx", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.SetSampleRate("2xx", 1.5); err == nil {
+		t.Error("期望超出范围的采样率返回错误")
+	}
+	if err := builder.SetSampleRate("6xx", 0.5); err == nil {
+		t.Error("期望未知的状态码类别返回错误")
+	}
+
+	handler := builder.Build()
+	for _, code := range []int{http.StatusOK, http.StatusInternalServerError} {
+		ctx, _ := createTestContext(http.MethodGet, "/test")
+		handler(func(ctx *ant.Context) {
+			ctx.RespStatusCode = code
+		})(ctx)
+	}
+
+	if len(logs) != 1 {
+		t.Fatalf("期望只记录 5xx 请求, 实际记录 %d 条", len(logs))
+	}
+	var entry accessLog
+	if err := json.Unmarshal([]byte(logs[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Status != http.StatusInternalServerError {
+		t.Errorf("期望状态码 500, 实际 %d", entry.Status)
+	}
+}
+
+func TestAccessLogAdminHandler(t *testing.T) {
+	builder := NewBuilder()
+	server := ant.NewHTTPServer()
+	server.Handle("/admin/accesslog", builder.AdminHandler())
+
+	rec := httptest.NewRecorder()
+	body := `{"level":"warn","sample_rates":{"2xx":0.01}}`
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/accesslog", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("期望状态码 200, 实际 %d: %s", rec.Code, rec.Body.String())
+	}
+
+	cfg := builder.Config()
+	if cfg.Level != LevelWarn || cfg.SampleRates["2xx"] != 0.01 || cfg.SampleRates["5xx"] != 1 {
+		t.Errorf("配置未正确更新: %+v", cfg)
+	}
+
+	// 非法配置不应产生部分更新
+	rec = httptest.NewRecorder()
+	body = `{"sample_rates":{"4xx":0.5,"5xx":2}}`
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/accesslog", strings.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("期望状态码 400, 实际 %d", rec.Code)
+	}
+	if builder.Config().SampleRates["4xx"] != 1 {
+		t.Error("非法请求不应修改配置")
+	}
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/accesslog", nil))
+	var got Config
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Level != LevelWarn {
+		t.Errorf("期望级别 warn, 实际 %s", got.Level)
+	}
+}
@@ -0,0 +1,229 @@
+package allocbudget
+
+import (
+	"log"
+	"runtime/metrics"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+// 采样使用的运行时指标，均为进程启动以来的累计值
+const (
+	metricAllocBytes   = "/gc/heap/allocs:bytes"
+	metricAllocObjects = "/gc/heap/allocs:objects"
+)
+
+// Budget 单个请求的内存分配预算
+type Budget struct {
+	// Bytes 单个请求允许分配的最大字节数，为0时不限制
+	Bytes uint64 `json:"bytes,omitempty"`
+	// Objects 单个请求允许分配的最大对象数，为0时不限制
+	Objects uint64 `json:"objects,omitempty"`
+}
+
+// exceeded 判断分配量是否超出预算
+func (b Budget) exceeded(s Sample) bool {
+	return b.Bytes > 0 && s.Bytes > b.Bytes || b.Objects > 0 && s.Objects > b.Objects
+}
+
+// Sample 一次请求的分配量
+type Sample struct {
+	// Bytes 分配的字节数
+	Bytes uint64 `json:"bytes"`
+	// Objects 分配的对象数
+	Objects uint64 `json:"objects"`
+}
+
+// Violation 超出预算的请求
+type Violation struct {
+	Route  string
+	Method string
+	Path   string
+	Sample Sample
+	Budget Budget
+	Time   time.Time
+}
+
+// RouteStats 单个路由的分配统计
+type RouteStats struct {
+	Route    string `json:"route"`
+	Requests int64  `json:"requests"`
+	// Total 全部请求的分配总量
+	Total Sample `json:"total"`
+	// Peak 单个请求的最大分配量
+	Peak Sample `json:"peak"`
+	// OverBudget 超出预算的请求数
+	OverBudget int64   `json:"over_budget"`
+	Budget     *Budget `json:"budget,omitempty"`
+}
+
+// MiddlewareBuilder 用于构建请求内存分配预算中间件
+// 通过请求前后运行时指标的差值估算每个请求的分配量，按路由统计并标记超出预算的请求
+// 注意：运行时指标是进程级的，并发请求与后台 goroutine 的分配也会计入，
+// 且小对象按 span 批量计数，结果只适合在开发或压测环境中定位分配异常的路由
+type MiddlewareBuilder struct {
+	// Enabled 是否启用，默认取决于 ant.DevMode()，未启用时中间件不做任何处理
+	Enabled bool
+
+	mu            sync.RWMutex
+	defaultBudget Budget
+	budgets       map[string]Budget
+	stats         map[string]*RouteStats
+	violationFunc func(v Violation)
+	headers       bool
+	read          func() Sample
+}
+
+// NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
+// 注意：只有设置了 ANT_MODE=development 时才会启用，需要在其他环境使用时设置 Enabled
+func NewMiddlewareBuilder() *MiddlewareBuilder {
+	return &MiddlewareBuilder{
+		Enabled: ant.DevMode(),
+		budgets: make(map[string]Budget),
+		stats:   make(map[string]*RouteStats),
+		violationFunc: func(v Violation) {
+			log.Printf("allocbudget: %s %s 分配了 %d 字节 %d 个对象，超出预算 %+v",
+				v.Method, v.Path, v.Sample.Bytes, v.Sample.Objects, v.Budget)
+		},
+		read: readSample,
+	}
+}
+
+// DefaultBudget 设置没有单独声明预算的路由使用的预算
+func (b *MiddlewareBuilder) DefaultBudget(budget Budget) *MiddlewareBuilder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.defaultBudget = budget
+	return b
+}
+
+// Budget 为路由声明预算
+// route: 注册路由时使用的路由模式，例如 "GET /users/{id}"
+func (b *MiddlewareBuilder) Budget(route string, budget Budget) *MiddlewareBuilder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.budgets[route] = budget
+	return b
+}
+
+// ViolationFunc 设置请求超出预算时的回调，默认输出日志
+func (b *MiddlewareBuilder) ViolationFunc(fn func(v Violation)) *MiddlewareBuilder {
+	b.violationFunc = fn
+	return b
+}
+
+// ExposeHeaders 在响应头 X-Alloc-Bytes 与 X-Alloc-Objects 中返回请求的分配量
+// 直接写入了响应的处理函数无法再设置响应头
+func (b *MiddlewareBuilder) ExposeHeaders() *MiddlewareBuilder {
+	b.headers = true
+	return b
+}
+
+// Build 构建请求内存分配预算中间件
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	if !b.Enabled {
+		return func(next ant.HandleFunc) ant.HandleFunc {
+			return next
+		}
+	}
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			before := b.read()
+			next(ctx)
+			after := b.read()
+			s := Sample{Bytes: after.Bytes - before.Bytes, Objects: after.Objects - before.Objects}
+			if b.headers {
+				header := ctx.Resp.Header()
+				header.Set("X-Alloc-Bytes", strconv.FormatUint(s.Bytes, 10))
+				header.Set("X-Alloc-Objects", strconv.FormatUint(s.Objects, 10))
+			}
+			b.observe(ctx, s)
+		}
+	}
+}
+
+// observe 记录一次请求的分配量，超出预算时调用回调
+func (b *MiddlewareBuilder) observe(ctx *ant.Context, s Sample) {
+	route := ctx.Req.Pattern
+	if route == "" {
+		return
+	}
+	b.mu.Lock()
+	budget, ok := b.budgets[route]
+	if !ok {
+		budget = b.defaultBudget
+	}
+	st, ok := b.stats[route]
+	if !ok {
+		st = &RouteStats{Route: route}
+		b.stats[route] = st
+	}
+	st.Requests++
+	st.Total.Bytes += s.Bytes
+	st.Total.Objects += s.Objects
+	st.Peak.Bytes = max(st.Peak.Bytes, s.Bytes)
+	st.Peak.Objects = max(st.Peak.Objects, s.Objects)
+	over := budget.exceeded(s)
+	if over {
+		st.OverBudget++
+	}
+	b.mu.Unlock()
+
+	if over {
+		b.violationFunc(Violation{
+			Route:  route,
+			Method: ctx.Req.Method,
+			Path:   ctx.Req.URL.Path,
+			Sample: s,
+			Budget: budget,
+			Time:   time.Now(),
+		})
+	}
+}
+
+// Snapshot 返回所有路由的分配统计，按路由模式排序
+func (b *MiddlewareBuilder) Snapshot() []RouteStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	res := make([]RouteStats, 0, len(b.stats))
+	for route, st := range b.stats {
+		stats := *st
+		budget, ok := b.budgets[route]
+		if !ok {
+			budget = b.defaultBudget
+		}
+		if budget != (Budget{}) {
+			stats.Budget = &budget
+		}
+		res = append(res, stats)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Route < res[j].Route
+	})
+	return res
+}
+
+// Handler 返回以JSON格式输出分配统计的处理函数
+func (b *MiddlewareBuilder) Handler() ant.HandleFunc {
+	return func(ctx *ant.Context) {
+		_ = ctx.RespJSONOK(b.Snapshot())
+	}
+}
+
+// readSample 读取进程启动以来的累计分配量
+func readSample() Sample {
+	samples := [2]metrics.Sample{{Name: metricAllocBytes}, {Name: metricAllocObjects}}
+	metrics.Read(samples[:])
+	var s Sample
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		s.Bytes = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		s.Objects = samples[1].Value.Uint64()
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+package allocbudget
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/justinwongcn/ant"
+)
+
+// fakeCounter 每次处理请求时按 alloc 增加的累计分配量
+type fakeCounter struct {
+	total Sample
+	alloc Sample
+}
+
+func (c *fakeCounter) handler(ctx *ant.Context) {
+	c.total.Bytes += c.alloc.Bytes
+	c.total.Objects += c.alloc.Objects
+}
+
+func TestAllocBudget(t *testing.T) {
+	counter := &fakeCounter{}
+	var violations []Violation
+	b := NewMiddlewareBuilder().
+		DefaultBudget(Budget{Bytes: 1000}).
+		Budget("GET /export", Budget{Bytes: 1 << 20, Objects: 100}).
+		ViolationFunc(func(v Violation) {
+			violations = append(violations, v)
+		}).
+		ExposeHeaders()
+	b.Enabled = true
+	b.read = func() Sample { return counter.total }
+
+	server := ant.NewHTTPServer()
+	server.Use(b.Build())
+	server.Handle("GET /users/{id}", counter.handler)
+	server.Handle("GET /export", counter.handler)
+	server.Handle("GET /debug/allocs", b.Handler())
+
+	get := func(path string, alloc Sample) *httptest.ResponseRecorder {
+		counter.alloc = alloc
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/users/1", Sample{Bytes: 400, Objects: 4})
+	if w.Header().Get("X-Alloc-Bytes") != "400" || w.Header().Get("X-Alloc-Objects") != "4" {
+		t.Errorf("响应头中的分配量不正确: %v", w.Header())
+	}
+	get("/users/2", Sample{Bytes: 1500, Objects: 10})
+	get("/export", Sample{Bytes: 1500, Objects: 10})
+	get("/export", Sample{Bytes: 2000, Objects: 200})
+
+	if len(violations) != 2 {
+		t.Fatalf("应有 2 个请求超出预算，实际为 %+v", violations)
+	}
+	if v := violations[0]; v.Route != "GET /users/{id}" || v.Path != "/users/2" || v.Sample.Bytes != 1500 {
+		t.Errorf("默认预算的违规不正确: %+v", v)
+	}
+	if v := violations[1]; v.Route != "GET /export" || v.Budget.Objects != 100 {
+		t.Errorf("路由预算的违规不正确: %+v", v)
+	}
+
+	counter.alloc = Sample{}
+	w = get("/debug/allocs", Sample{})
+	var stats []RouteStats
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("应有 2 个路由的统计，实际为 %+v", stats)
+	}
+	export := stats[0]
+	if export.Route != "GET /export" || export.Requests != 2 || export.OverBudget != 1 ||
+		export.Total.Bytes != 3500 || export.Peak.Objects != 200 || export.Budget == nil {
+		t.Errorf("GET /export 的统计不正确: %+v", export)
+	}
+	if users := stats[1]; users.Peak.Bytes != 1500 || users.Budget.Bytes != 1000 {
+		t.Errorf("GET /users/{id} 的统计不正确: %+v", users)
+	}
+}
+
+func TestAllocBudgetDisabled(t *testing.T) {
+	b := NewMiddlewareBuilder()
+	b.Enabled = false
+	server := ant.NewHTTPServer()
+	server.Use(b.ExposeHeaders().Build())
+	server.Handle("GET /", func(ctx *ant.Context) {})
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Header().Get("X-Alloc-Bytes") != "" || len(b.Snapshot()) != 0 {
+		t.Error("未启用时不应记录分配量")
+	}
+}
+
+var sink []byte
+
+func TestReadSample(t *testing.T) {
+	before := readSample()
+	sink = make([]byte, 4<<20)
+	after := readSample()
+	if after.Bytes-before.Bytes < 4<<20 || after.Objects <= before.Objects {
+		t.Errorf("分配 4MB 后的采样不正确: %+v -> %+v", before, after)
+	}
+}
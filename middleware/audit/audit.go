@@ -0,0 +1,315 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+// Redacted 被脱敏字段的替换值
+const Redacted = "[REDACTED]"
+
+// MiddlewareBuilder 审计中间件构建器
+type MiddlewareBuilder struct {
+	sink         Sink
+	routes       map[string]struct{}
+	headers      []string
+	bodyFields   []string
+	redactFields map[string]struct{}
+	redactRegexp []*regexp.Regexp
+	userFunc     func(ctx *ant.Context) string
+	maxBodySize  int64
+	errFunc      func(err error)
+}
+
+// NewMiddlewareBuilder 创建审计中间件构建器
+// sink: 审计记录的持久化实现
+func NewMiddlewareBuilder(sink Sink) *MiddlewareBuilder {
+	return &MiddlewareBuilder{
+		sink:         sink,
+		routes:       make(map[string]struct{}),
+		redactFields: make(map[string]struct{}),
+		userFunc:     func(ctx *ant.Context) string { return "" },
+		maxBodySize:  64 << 10,
+		errFunc: func(err error) {
+			log.Printf("写入审计记录失败: %v", err)
+		},
+	}
+}
+
+// Routes 限定需要审计的路由，参数为注册时使用的路由模式
+// 未调用时审计所有路由
+func (b *MiddlewareBuilder) Routes(patterns ...string) *MiddlewareBuilder {
+	for _, p := range patterns {
+		b.routes[p] = struct{}{}
+	}
+	return b
+}
+
+// Headers 设置需要记录的请求头
+func (b *MiddlewareBuilder) Headers(names ...string) *MiddlewareBuilder {
+	b.headers = append(b.headers, names...)
+	return b
+}
+
+// BodyFields 设置需要记录的JSON请求体字段，嵌套字段使用点号分隔，例如 "user.email"
+func (b *MiddlewareBuilder) BodyFields(fields ...string) *MiddlewareBuilder {
+	b.bodyFields = append(b.bodyFields, fields...)
+	return b
+}
+
+// Redact 设置需要脱敏的请求头或请求体字段名称，不区分大小写
+// 对于嵌套字段，既可以使用完整路径也可以只使用最后一级名称
+func (b *MiddlewareBuilder) Redact(fields ...string) *MiddlewareBuilder {
+	for _, f := range fields {
+		b.redactFields[strings.ToLower(f)] = struct{}{}
+	}
+	return b
+}
+
+// RedactPattern 设置需要脱敏的值模式，匹配的部分会被替换为 Redacted
+// 例如邮箱、银行卡号等无法通过字段名识别的敏感信息
+func (b *MiddlewareBuilder) RedactPattern(patterns ...*regexp.Regexp) *MiddlewareBuilder {
+	b.redactRegexp = append(b.redactRegexp, patterns...)
+	return b
+}
+
+// UserFunc 设置从请求上下文中解析操作用户的函数
+func (b *MiddlewareBuilder) UserFunc(fn func(ctx *ant.Context) string) *MiddlewareBuilder {
+	b.userFunc = fn
+	return b
+}
+
+// MaxBodySize 设置解析请求体的最大字节数，超过该大小的请求体不会被记录
+func (b *MiddlewareBuilder) MaxBodySize(size int64) *MiddlewareBuilder {
+	b.maxBodySize = size
+	return b
+}
+
+// ErrFunc 设置写入审计记录失败时的处理函数
+func (b *MiddlewareBuilder) ErrFunc(fn func(err error)) *MiddlewareBuilder {
+	b.errFunc = fn
+	return b
+}
+
+// Build 构建审计中间件
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			if len(b.routes) > 0 {
+				if _, ok := b.routes[ctx.Req.Pattern]; !ok {
+					next(ctx)
+					return
+				}
+			}
+
+			start := time.Now()
+			body := b.captureBody(ctx.Req)
+
+			next(ctx)
+
+			r := Record{
+				Time:     start,
+				Method:   ctx.Req.Method,
+				Path:     ctx.Req.URL.Path,
+				Route:    ctx.Req.Pattern,
+				User:     b.userFunc(ctx),
+				Status:   ctx.ResponseStatus(),
+				Headers:  b.collectHeaders(ctx.Req.Header),
+				Body:     b.collectBody(body),
+				Duration: time.Since(start),
+			}
+			if err := b.sink.Write(ctx.Req.Context(), r); err != nil {
+				b.errFunc(err)
+			}
+		}
+	}
+}
+
+// captureBody 读取JSON请求体用于审计，并还原请求体供后续处理器使用
+func (b *MiddlewareBuilder) captureBody(req *http.Request) []byte {
+	if len(b.bodyFields) == 0 || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return nil
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(req.Body, b.maxBodySize+1))
+	req.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(buf), req.Body),
+		Closer: req.Body,
+	}
+	if err != nil || int64(len(buf)) > b.maxBodySize {
+		return nil
+	}
+	return buf
+}
+
+// collectHeaders 收集并脱敏需要记录的请求头
+func (b *MiddlewareBuilder) collectHeaders(header http.Header) map[string]string {
+	if len(b.headers) == 0 {
+		return nil
+	}
+	res := make(map[string]string, len(b.headers))
+	for _, name := range b.headers {
+		val := header.Get(name)
+		if val == "" {
+			continue
+		}
+		if b.shouldRedact(name) {
+			val = Redacted
+		} else {
+			val = b.redactValue(val)
+		}
+		res[name] = val
+	}
+	return res
+}
+
+// collectBody 从JSON请求体中提取并脱敏需要记录的字段
+func (b *MiddlewareBuilder) collectBody(body []byte) map[string]any {
+	if len(body) == 0 {
+		return nil
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil
+	}
+
+	res := make(map[string]any, len(b.bodyFields))
+	for _, field := range b.bodyFields {
+		val, ok := lookup(doc, field)
+		if !ok {
+			continue
+		}
+		res[field] = b.redactAny(field, val)
+	}
+	return res
+}
+
+// redactAny 递归地对字段值进行脱敏
+func (b *MiddlewareBuilder) redactAny(path string, val any) any {
+	if b.shouldRedact(path) {
+		return Redacted
+	}
+	switch v := val.(type) {
+	case string:
+		return b.redactValue(v)
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for k, item := range v {
+			res[k] = b.redactAny(path+"."+k, item)
+		}
+		return res
+	case []any:
+		res := make([]any, len(v))
+		for i, item := range v {
+			res[i] = b.redactAny(path, item)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+// shouldRedact 判断字段是否需要整体脱敏
+func (b *MiddlewareBuilder) shouldRedact(path string) bool {
+	path = strings.ToLower(path)
+	if _, ok := b.redactFields[path]; ok {
+		return true
+	}
+	if idx := strings.LastIndex(path, "."); idx >= 0 {
+		_, ok := b.redactFields[path[idx+1:]]
+		return ok
+	}
+	return false
+}
+
+// redactValue 将值中匹配脱敏模式的部分替换为 Redacted
+func (b *MiddlewareBuilder) redactValue(val string) string {
+	for _, re := range b.redactRegexp {
+		val = re.ReplaceAllString(val, Redacted)
+	}
+	return val
+}
+
+// lookup 按点号分隔的路径在JSON文档中查找字段
+func lookup(doc map[string]any, path string) (any, bool) {
+	var cur any = doc
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		cur, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// readCloser 组合读取器与原始请求体的关闭方法
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// QueryHandler 创建查询审计记录的处理函数
+// 支持的查询参数: method、path_prefix、user、status、since、until（RFC3339格式）、limit
+func QueryHandler(q Querier) ant.HandleFunc {
+	return func(ctx *ant.Context) {
+		f := Filter{
+			Method:     ctx.Req.URL.Query().Get("method"),
+			PathPrefix: ctx.Req.URL.Query().Get("path_prefix"),
+			User:       ctx.Req.URL.Query().Get("user"),
+		}
+
+		var err error
+		if v := ctx.Req.URL.Query().Get("status"); v != "" {
+			if f.Status, err = strconv.Atoi(v); err != nil {
+				ctx.RespStatusCode = http.StatusBadRequest
+				ctx.RespData = []byte("非法的 status 参数")
+				return
+			}
+		}
+		if v := ctx.Req.URL.Query().Get("limit"); v != "" {
+			if f.Limit, err = strconv.Atoi(v); err != nil {
+				ctx.RespStatusCode = http.StatusBadRequest
+				ctx.RespData = []byte("非法的 limit 参数")
+				return
+			}
+		}
+		if v := ctx.Req.URL.Query().Get("since"); v != "" {
+			if f.Since, err = time.Parse(time.RFC3339, v); err != nil {
+				ctx.RespStatusCode = http.StatusBadRequest
+				ctx.RespData = []byte("非法的 since 参数")
+				return
+			}
+		}
+		if v := ctx.Req.URL.Query().Get("until"); v != "" {
+			if f.Until, err = time.Parse(time.RFC3339, v); err != nil {
+				ctx.RespStatusCode = http.StatusBadRequest
+				ctx.RespData = []byte("非法的 until 参数")
+				return
+			}
+		}
+
+		records, err := q.Query(ctx.Req.Context(), f)
+		if err != nil {
+			ctx.RespStatusCode = http.StatusInternalServerError
+			ctx.RespData = []byte("查询审计记录失败")
+			return
+		}
+		_ = ctx.RespJSONOK(records)
+	}
+}
@@ -0,0 +1,191 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	sink := NewMemorySink(10)
+	builder := NewMiddlewareBuilder(sink).
+		Routes("POST /users").
+		Headers("Authorization", "X-Request-Id").
+		BodyFields("name", "password", "profile", "note").
+		Redact("authorization", "password", "phone").
+		RedactPattern(regexp.MustCompile(`[\w.]+@[\w.]+`)).
+		UserFunc(func(ctx *ant.Context) string {
+			return ctx.Req.Header.Get("X-User")
+		})
+
+	server := ant.NewHTTPServer()
+	server.Use(builder.Build())
+
+	var handlerBody string
+	server.Handle("POST /users", func(ctx *ant.Context) {
+		bs, _ := io.ReadAll(ctx.Req.Body)
+		handlerBody = string(bs)
+		ctx.RespStatusCode = http.StatusCreated
+	})
+	server.Handle("GET /users", func(ctx *ant.Context) {})
+
+	body := `{"name":"tom","password":"123456","profile":{"phone":"13800000000","city":"sz"},"note":"mail tom@example.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("X-Request-Id", "req-1")
+	req.Header.Set("X-User", "admin")
+	server.ServeHTTP(httptest.NewRecorder(), req)
+
+	// 未被选中的路由不应被审计
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	if handlerBody != body {
+		t.Errorf("处理器读取到的请求体被修改: %s", handlerBody)
+	}
+
+	records, _ := sink.Query(context.Background(), Filter{})
+	if len(records) != 1 {
+		t.Fatalf("期望 1 条审计记录, 得到 %d", len(records))
+	}
+
+	r := records[0]
+	if r.User != "admin" || r.Status != http.StatusCreated || r.Route != "POST /users" {
+		t.Errorf("审计记录基本信息不正确: %+v", r)
+	}
+	if r.Headers["Authorization"] != Redacted {
+		t.Errorf("Authorization 头未被脱敏: %s", r.Headers["Authorization"])
+	}
+	if r.Headers["X-Request-Id"] != "req-1" {
+		t.Errorf("期望记录 X-Request-Id, 得到 %s", r.Headers["X-Request-Id"])
+	}
+	if r.Body["name"] != "tom" || r.Body["password"] != Redacted {
+		t.Errorf("请求体字段脱敏不正确: %v", r.Body)
+	}
+	profile := r.Body["profile"].(map[string]any)
+	if profile["phone"] != Redacted || profile["city"] != "sz" {
+		t.Errorf("嵌套字段脱敏不正确: %v", profile)
+	}
+	if r.Body["note"] != "mail "+Redacted {
+		t.Errorf("模式脱敏不正确: %v", r.Body["note"])
+	}
+}
+
+func TestAuditDirectWriteStatus(t *testing.T) {
+	sink := NewMemorySink(10)
+	server := ant.NewHTTPServer()
+	server.Use(NewMiddlewareBuilder(sink).Build())
+	server.Handle("DELETE /users/{id}", func(ctx *ant.Context) {
+		// 直接写入响应时 RespStatusCode 为 0
+		ctx.Resp.WriteHeader(http.StatusForbidden)
+	})
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/users/1", nil))
+
+	records, _ := sink.Query(context.Background(), Filter{})
+	if len(records) != 1 || records[0].Status != http.StatusForbidden {
+		t.Errorf("期望记录直接写入的状态码 403, 得到 %+v", records)
+	}
+}
+
+func TestAuditOversizedBody(t *testing.T) {
+	sink := NewMemorySink(10)
+	mdl := NewMiddlewareBuilder(sink).BodyFields("name").MaxBodySize(8).Build()
+
+	var handlerBody string
+	handler := mdl(func(ctx *ant.Context) {
+		bs, _ := io.ReadAll(ctx.Req.Body)
+		handlerBody = string(bs)
+	})
+
+	body := `{"name":"a very long name"}`
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	handler(&ant.Context{Req: req, Resp: httptest.NewRecorder()})
+
+	if handlerBody != body {
+		t.Errorf("超长请求体应完整传递给处理器, 得到 %s", handlerBody)
+	}
+	records, _ := sink.Query(context.Background(), Filter{})
+	if len(records) != 1 || records[0].Body != nil {
+		t.Errorf("超长请求体不应被记录: %+v", records)
+	}
+}
+
+func TestMemorySinkQuery(t *testing.T) {
+	sink := NewMemorySink(3)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		method := http.MethodGet
+		if i%2 == 0 {
+			method = http.MethodPost
+		}
+		_ = sink.Write(context.Background(), Record{
+			Time:   base.Add(time.Duration(i) * time.Minute),
+			Method: method,
+			Path:   "/items",
+			User:   "u1",
+			Status: http.StatusOK,
+		})
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{name: "超出容量后只保留最新记录", filter: Filter{}, want: 3},
+		{name: "按方法过滤", filter: Filter{Method: "post"}, want: 2},
+		{name: "按时间过滤", filter: Filter{Since: base.Add(3 * time.Minute)}, want: 2},
+		{name: "限制数量", filter: Filter{Limit: 1}, want: 1},
+		{name: "按用户过滤", filter: Filter{User: "u2"}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := sink.Query(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != tt.want {
+				t.Errorf("期望 %d 条记录, 得到 %d", tt.want, len(records))
+			}
+		})
+	}
+
+	records, _ := sink.Query(context.Background(), Filter{})
+	if !records[0].Time.Equal(base.Add(4 * time.Minute)) {
+		t.Errorf("期望按时间从新到旧排列, 第一条为 %v", records[0].Time)
+	}
+}
+
+func TestQueryHandler(t *testing.T) {
+	sink := NewMemorySink(10)
+	_ = sink.Write(context.Background(), Record{Method: http.MethodPost, Path: "/a", Status: 201})
+	_ = sink.Write(context.Background(), Record{Method: http.MethodGet, Path: "/b", Status: 200})
+
+	server := ant.NewHTTPServer()
+	server.Handle("GET /audit", QueryHandler(sink))
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/audit?status=201", nil))
+	var records []Record
+	if err := json.Unmarshal(rec.Body.Bytes(), &records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Path != "/a" {
+		t.Errorf("查询结果不正确: %+v", records)
+	}
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/audit?since=yesterday", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("非法参数期望状态码 400, 得到 %d", rec.Code)
+	}
+}
@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record 一条审计记录
+type Record struct {
+	Time     time.Time         `json:"time"`
+	Method   string            `json:"method"`
+	Path     string            `json:"path"`
+	Route    string            `json:"route,omitempty"`
+	User     string            `json:"user,omitempty"`
+	Status   int               `json:"status"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     map[string]any    `json:"body,omitempty"`
+	Duration time.Duration     `json:"duration"`
+}
+
+// Sink 审计记录的持久化接口
+// 实现可以将记录写入数据库、消息队列或日志文件
+type Sink interface {
+	// Write 持久化一条审计记录
+	Write(ctx context.Context, r Record) error
+}
+
+// Filter 审计记录的查询条件，零值字段表示不限制
+type Filter struct {
+	// Method 请求方法
+	Method string
+	// PathPrefix 请求路径前缀
+	PathPrefix string
+	// User 操作用户
+	User string
+	// Status 响应状态码
+	Status int
+	// Since 起始时间（包含）
+	Since time.Time
+	// Until 截止时间（不包含）
+	Until time.Time
+	// Limit 最多返回的记录数，0表示不限制
+	Limit int
+}
+
+// Match 判断记录是否满足查询条件
+func (f Filter) Match(r Record) bool {
+	if f.Method != "" && !strings.EqualFold(f.Method, r.Method) {
+		return false
+	}
+	if f.PathPrefix != "" && !strings.HasPrefix(r.Path, f.PathPrefix) {
+		return false
+	}
+	if f.User != "" && f.User != r.User {
+		return false
+	}
+	if f.Status != 0 && f.Status != r.Status {
+		return false
+	}
+	if !f.Since.IsZero() && r.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !r.Time.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Querier 支持按条件查询审计记录的接口
+type Querier interface {
+	// Query 返回满足条件的审计记录，按时间从新到旧排列
+	Query(ctx context.Context, f Filter) ([]Record, error)
+}
+
+// MemorySink 基于内存的审计记录存储
+// 只保留最近的 capacity 条记录，适合开发环境或作为测试替身
+type MemorySink struct {
+	mu       sync.RWMutex
+	records  []Record
+	capacity int
+	next     int
+	full     bool
+}
+
+// 确保 MemorySink 同时实现了 Sink 和 Querier 接口
+var (
+	_ Sink    = (*MemorySink)(nil)
+	_ Querier = (*MemorySink)(nil)
+)
+
+// NewMemorySink 创建内存审计存储
+// capacity: 最多保留的记录数
+func NewMemorySink(capacity int) *MemorySink {
+	if capacity <= 0 {
+		capacity = 1024
+	}
+	return &MemorySink{
+		records:  make([]Record, capacity),
+		capacity: capacity,
+	}
+}
+
+// Write 实现 Sink 接口，超出容量时覆盖最旧的记录
+func (m *MemorySink) Write(_ context.Context, r Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records[m.next] = r
+	m.next = (m.next + 1) % m.capacity
+	if m.next == 0 {
+		m.full = true
+	}
+	return nil
+}
+
+// Query 实现 Querier 接口
+func (m *MemorySink) Query(_ context.Context, f Filter) ([]Record, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	size := m.next
+	if m.full {
+		size = m.capacity
+	}
+
+	res := make([]Record, 0)
+	// 从最新的记录开始向前遍历
+	for i := 0; i < size; i++ {
+		idx := (m.next - 1 - i + m.capacity) % m.capacity
+		r := m.records[idx]
+		if !f.Match(r) {
+			continue
+		}
+		res = append(res, r)
+		if f.Limit > 0 && len(res) >= f.Limit {
+			break
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,171 @@
+package authz
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/justinwongcn/ant"
+	"github.com/justinwongcn/ant/session"
+)
+
+// Subject 发起请求的用户
+type Subject struct {
+	// ID 用户标识
+	ID string
+	// Roles 用户的角色
+	Roles []string
+	// Scopes 用户的权限范围，例如 OAuth 令牌的 scope
+	Scopes []string
+}
+
+// SubjectFunc 从请求中获取已认证的用户，未认证时返回 false
+type SubjectFunc func(ctx *ant.Context) (Subject, bool)
+
+// FromSession 从会话中登录的用户获取角色，见 session.Manager.Login
+func FromSession(m *session.Manager) SubjectFunc {
+	return func(ctx *ant.Context) (Subject, bool) {
+		p, err := m.CurrentUser(*ctx)
+		if err != nil {
+			return Subject{}, false
+		}
+		return Subject{ID: p.UserID.String(), Roles: p.Roles}, true
+	}
+}
+
+// FromUserValue 从鉴权中间件写入 ctx.UserValues 的 Subject 获取用户
+func FromUserValue(key string) SubjectFunc {
+	return func(ctx *ant.Context) (Subject, bool) {
+		s, ok := ctx.UserValues[key].(Subject)
+		return s, ok
+	}
+}
+
+// Routes 读取与修改路由描述信息，*ant.HTTPServer 实现了该接口
+type Routes interface {
+	Routes() []string
+	RouteMeta(pattern string) (ant.RouteMeta, bool)
+	UpdateRouteMeta(pattern string, fn func(meta *ant.RouteMeta))
+}
+
+// MiddlewareBuilder 用于构建路由授权中间件
+// 按命中路由的 RouteMeta.Authorization 检查用户的角色与权限范围，
+// 没有设置策略的路由直接放行
+type MiddlewareBuilder struct {
+	routes  Routes
+	subject SubjectFunc
+}
+
+// NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
+// routes: 路由描述信息的来源，通常为 server
+// subject: 获取当前用户的函数，例如 FromSession(manager)
+func NewMiddlewareBuilder(routes Routes, subject SubjectFunc) *MiddlewareBuilder {
+	return &MiddlewareBuilder{routes: routes, subject: subject}
+}
+
+// Build 构建路由授权中间件
+// 路由设置了策略时，未认证的请求响应 401，不满足策略的请求响应 403
+// 策略在每个请求中读取，通过 AdminHandler 修改后立即生效
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			meta, _ := b.routes.RouteMeta(ctx.Req.Pattern)
+			if meta.Authorization == nil {
+				next(ctx)
+				return
+			}
+			sub, ok := b.subject(ctx)
+			if !ok {
+				ctx.RespStatusCode = http.StatusUnauthorized
+				ctx.RespData = []byte("未登录")
+				return
+			}
+			if !meta.Authorization.Allows(sub.Roles, sub.Scopes) {
+				ctx.RespStatusCode = http.StatusForbidden
+				ctx.RespData = []byte("无权访问")
+				return
+			}
+			next(ctx)
+		}
+	}
+}
+
+// AccessCheck 返回按策略保护静态文件的访问检查，用于 ant.WithAccessCheck 与 ant.FileDownloader.AccessCheck
+// subject: 获取当前用户的函数，例如 FromSession(manager)
+// policy: 访问文件需要的角色与权限范围
+// 未认证时返回 ant.ErrUnauthorized，响应 401；不满足策略时返回 ant.ErrForbidden，响应 403
+//
+//	server.Static("/reports", "./reports", ant.WithAccessCheck(
+//		authz.AccessCheck(authz.FromSession(manager), ant.AuthorizationPolicy{Roles: []string{"finance"}})))
+func AccessCheck(subject SubjectFunc, policy ant.AuthorizationPolicy) ant.AccessCheckFunc {
+	return func(ctx *ant.Context, _ string) error {
+		sub, ok := subject(ctx)
+		if !ok {
+			return ant.ErrUnauthorized
+		}
+		if !policy.Allows(sub.Roles, sub.Scopes) {
+			return ant.ErrForbidden
+		}
+		return nil
+	}
+}
+
+// policyRequest 修改策略的请求
+type policyRequest struct {
+	Pattern string   `json:"pattern"`
+	Roles   []string `json:"roles"`
+	Scopes  []string `json:"scopes"`
+}
+
+// AdminHandler 返回管理路由授权策略的处理函数
+// GET 返回全部设置了策略的路由，格式为 {"GET /users": {"roles": ["admin"]}}
+// PUT 或 POST 接收 {"pattern": "GET /users", "roles": ["admin"], "scopes": ["users:read"]}，设置路由的策略
+// DELETE 通过查询参数 pattern 取消路由的策略
+// 错误通过 ctx.RespError 以JSON响应，路由不存在时响应 404，策略不合法时响应 400
+// 注意：务必通过鉴权中间件保护该处理函数
+func (b *MiddlewareBuilder) AdminHandler() ant.HandleFunc {
+	return func(ctx *ant.Context) {
+		switch ctx.Req.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodDelete:
+			pattern := ctx.Req.URL.Query().Get("pattern")
+			if !slices.Contains(b.routes.Routes(), pattern) {
+				ctx.RespError(fmt.Errorf("%w: %s", ant.ErrUnknownRoute, pattern))
+				return
+			}
+			b.routes.UpdateRouteMeta(pattern, func(meta *ant.RouteMeta) {
+				meta.Authorization = nil
+			})
+		default:
+			var req policyRequest
+			if err := ctx.BindJSON(&req); err != nil {
+				ctx.RespError(fmt.Errorf("%w: %w", ant.ErrValidation, err))
+				return
+			}
+			policy := &ant.AuthorizationPolicy{Roles: req.Roles, Scopes: req.Scopes}
+			if err := policy.Validate(); err != nil {
+				ctx.RespError(err)
+				return
+			}
+			if !slices.Contains(b.routes.Routes(), req.Pattern) {
+				ctx.RespError(fmt.Errorf("%w: %s", ant.ErrUnknownRoute, req.Pattern))
+				return
+			}
+			b.routes.UpdateRouteMeta(req.Pattern, func(meta *ant.RouteMeta) {
+				meta.Authorization = policy
+			})
+		}
+		_ = ctx.RespJSONOK(b.policies())
+	}
+}
+
+// policies 返回全部设置了策略的路由
+func (b *MiddlewareBuilder) policies() map[string]*ant.AuthorizationPolicy {
+	res := make(map[string]*ant.AuthorizationPolicy)
+	for _, pattern := range b.routes.Routes() {
+		if meta, ok := b.routes.RouteMeta(pattern); ok && meta.Authorization != nil {
+			res[pattern] = meta.Authorization
+		}
+	}
+	return res
+}
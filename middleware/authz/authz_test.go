@@ -0,0 +1,122 @@
+package authz
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/justinwongcn/ant"
+	"github.com/justinwongcn/ant/session"
+	"github.com/justinwongcn/ant/session/cookie"
+	"github.com/justinwongcn/ant/session/memory"
+)
+
+func TestAuthz(t *testing.T) {
+	server := ant.NewHTTPServer()
+	b := NewMiddlewareBuilder(server, func(ctx *ant.Context) (Subject, bool) {
+		user := ctx.Req.Header.Get("X-User")
+		if user == "" {
+			return Subject{}, false
+		}
+		return Subject{ID: user, Roles: strings.Split(ctx.Req.Header.Get("X-Roles"), ","), Scopes: []string{"posts:read"}}, true
+	})
+	server.Use(b.Build())
+	server.Handle("GET /posts", func(ctx *ant.Context) { ctx.RespData = []byte("posts") })
+	server.Handle("DELETE /posts/{id}", func(ctx *ant.Context) { ctx.RespData = []byte("deleted") })
+	server.Handle("/admin/policies", b.AdminHandler())
+	server.Describe("DELETE /posts/{id}", ant.RouteMeta{Authorization: &ant.AuthorizationPolicy{Roles: []string{"admin"}}})
+
+	send := func(method, path, user, roles string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if user != "" {
+			req.Header.Set("X-User", user)
+			req.Header.Set("X-Roles", roles)
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := send(http.MethodGet, "/posts", "", ""); code != http.StatusOK {
+		t.Errorf("没有策略的路由应放行，实际为 %d", code)
+	}
+	if code := send(http.MethodDelete, "/posts/1", "", ""); code != http.StatusUnauthorized {
+		t.Errorf("未登录应响应 401，实际为 %d", code)
+	}
+	if code := send(http.MethodDelete, "/posts/1", "tom", "editor"); code != http.StatusForbidden {
+		t.Errorf("缺少角色应响应 403，实际为 %d", code)
+	}
+	if code := send(http.MethodDelete, "/posts/1", "tom", "editor,admin"); code != http.StatusOK {
+		t.Errorf("满足策略应放行，实际为 %d", code)
+	}
+
+	admin := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+	// 通过管理接口修改的策略立即生效
+	w := admin(http.MethodPut, "/admin/policies", `{"pattern":"GET /posts","scopes":["posts:write"]}`)
+	var policies map[string]*ant.AuthorizationPolicy
+	if err := json.Unmarshal(w.Body.Bytes(), &policies); err != nil || len(policies) != 2 {
+		t.Fatalf("应返回 2 条策略，实际为 %d %s", w.Code, w.Body.String())
+	}
+	if code := send(http.MethodGet, "/posts", "tom", "admin"); code != http.StatusForbidden {
+		t.Errorf("缺少权限范围应响应 403，实际为 %d", code)
+	}
+	if w = admin(http.MethodPut, "/admin/policies", `{"pattern":"GET /missing","roles":["admin"]}`); w.Code != http.StatusNotFound {
+		t.Errorf("路由不存在时应响应 404，实际为 %d", w.Code)
+	}
+	if w = admin(http.MethodPut, "/admin/policies", `{"pattern":"GET /posts","roles":[""]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("策略不合法时应响应 400，实际为 %d", w.Code)
+	}
+	if w = admin(http.MethodDelete, "/admin/policies?pattern=GET+/posts", ""); w.Code != http.StatusOK {
+		t.Fatalf("取消策略失败: %d", w.Code)
+	}
+	if code := send(http.MethodGet, "/posts", "", ""); code != http.StatusOK {
+		t.Errorf("取消策略后应放行，实际为 %d", code)
+	}
+}
+
+func TestFromSession(t *testing.T) {
+	m := &session.Manager{Store: memory.NewStore(time.Minute), Propagator: cookie.NewPropagator(), SessCtxKey: "session"}
+	w := httptest.NewRecorder()
+	ctx := ant.Context{Req: httptest.NewRequest(http.MethodGet, "/", nil), Resp: w, UserValues: map[string]any{}}
+	if _, err := m.Login(ctx, session.Principal{UserID: "u-1", Roles: []string{"admin"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range w.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	sub, ok := FromSession(m)(&ant.Context{Req: req, UserValues: map[string]any{}})
+	if !ok || sub.ID != "u-1" || len(sub.Roles) != 1 || sub.Roles[0] != "admin" {
+		t.Errorf("应从会话中获取用户，实际为 %+v %v", sub, ok)
+	}
+	if _, ok = FromSession(m)(&ant.Context{Req: httptest.NewRequest(http.MethodGet, "/", nil)}); ok {
+		t.Error("没有会话时应返回 false")
+	}
+}
+
+func TestAccessCheck(t *testing.T) {
+	check := AccessCheck(func(ctx *ant.Context) (Subject, bool) {
+		user := ctx.Req.Header.Get("X-User")
+		return Subject{ID: user, Roles: []string{user}}, user != ""
+	}, ant.AuthorizationPolicy{Roles: []string{"admin"}})
+	server := ant.NewHTTPServer()
+	server.StaticFS("/docs", fstest.MapFS{"a.txt": {Data: []byte("a")}}, ant.WithAccessCheck(check))
+
+	for user, want := range map[string]int{"": http.StatusUnauthorized, "bob": http.StatusForbidden, "admin": http.StatusOK} {
+		req := httptest.NewRequest(http.MethodGet, "/docs/a.txt", nil)
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("用户 %q 期望状态码 %d，得到 %d", user, want, w.Code)
+		}
+	}
+}
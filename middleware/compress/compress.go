@@ -0,0 +1,284 @@
+package compress
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/justinwongcn/ant"
+)
+
+// DefaultContentTypes 默认压缩的媒体类型，"text/*" 匹配全部文本类型
+var DefaultContentTypes = []string{
+	"text/*",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/x-ndjson",
+	"image/svg+xml",
+}
+
+// MiddlewareBuilder 用于构建 gzip 响应压缩中间件
+// 响应通过编码器流式写入客户端，不会缓冲完整的响应体，导出数百MB的文件时内存占用保持稳定
+// 注意：中间件返回前会写出 ctx.RespData，外层中间件对 RespData 的修改不再生效，
+// 因此应在 errhandle 等需要替换响应的中间件之后注册
+type MiddlewareBuilder struct {
+	level        int
+	minSize      int
+	contentTypes []string
+	pool         sync.Pool
+}
+
+// NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
+// 默认使用 gzip.DefaultCompression，响应体不足 1KB 时不压缩
+func NewMiddlewareBuilder() *MiddlewareBuilder {
+	return &MiddlewareBuilder{
+		level:        gzip.DefaultCompression,
+		minSize:      1024,
+		contentTypes: DefaultContentTypes,
+	}
+}
+
+// Level 设置压缩级别，取值范围与 gzip.NewWriterLevel 相同
+func (b *MiddlewareBuilder) Level(level int) *MiddlewareBuilder {
+	b.level = level
+	return b
+}
+
+// MinSize 设置压缩的最小响应体字节数
+// 写入的数据达到该大小之前暂存在内存中，用于判断是否值得压缩
+func (b *MiddlewareBuilder) MinSize(n int) *MiddlewareBuilder {
+	b.minSize = n
+	return b
+}
+
+// ContentTypes 设置压缩的媒体类型，例如 "text/*"、"application/json"
+func (b *MiddlewareBuilder) ContentTypes(types ...string) *MiddlewareBuilder {
+	b.contentTypes = types
+	return b
+}
+
+// Build 构建 gzip 响应压缩中间件
+// 以下情况不压缩：客户端不接受 gzip、HEAD 请求、WebSocket 升级请求、
+// 响应已设置 Content-Encoding 或 Content-Range、媒体类型不在压缩范围内、响应体小于 MinSize
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	if _, err := gzip.NewWriterLevel(nil, b.level); err != nil {
+		panic("compress: " + err.Error())
+	}
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			rw, ok := ctx.Resp.(ant.ResponseWriter)
+			if !ok || ctx.Req.Method == http.MethodHead || ctx.Req.Header.Get("Upgrade") != "" || !acceptsGzip(ctx.Req) {
+				next(ctx)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: rw, b: b}
+			ctx.Resp = cw
+			defer func() { ctx.Resp = rw }()
+			next(ctx)
+			cw.finish(ctx)
+		}
+	}
+}
+
+// compressWriter 按需压缩响应体的写入器
+// 在确定是否压缩之前暂存状态码与不超过 MinSize 的响应体
+type compressWriter struct {
+	ant.ResponseWriter
+	b *MiddlewareBuilder
+
+	// status 暂存的状态码
+	status int
+	// buf 暂存的响应体
+	buf []byte
+	// decided 是否已经确定压缩方式并写入了响应头
+	decided bool
+	gz      *gzip.Writer
+}
+
+// WriteHeader 暂存状态码，确定是否压缩后再写入
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	// 1xx 信息性响应直接发送
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// Write 写入响应体，数据达到 MinSize 后开始流式压缩
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.decided {
+		if len(w.buf)+len(p) < w.b.minSize {
+			w.buf = append(w.buf, p...)
+			return len(p), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush 确定压缩方式，并将编码器中的数据发送给客户端，用于 SSE 等流式响应
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		if err := w.decide(true); err != nil {
+			return
+		}
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Written 实现 ant.ResponseWriter 接口，暂存了状态码时同样视为已写入
+func (w *compressWriter) Written() bool {
+	return w.status != 0 || w.ResponseWriter.Written()
+}
+
+// Status 实现 ant.ResponseWriter 接口
+func (w *compressWriter) Status() int {
+	if !w.decided {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+// Unwrap 返回被包装的写入器，供 http.ResponseController 使用
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide 确定是否压缩，写入响应头与暂存的响应体
+// large: 响应体是否可能达到 MinSize，为 false 时不压缩
+func (w *compressWriter) decide(large bool) error {
+	w.decided = true
+	header := w.ResponseWriter.Header()
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	if w.compressible(header) {
+		header.Add("Vary", "Accept-Encoding")
+		if large && !w.tooSmall(header) {
+			header.Set("Content-Encoding", "gzip")
+			header.Del("Content-Length")
+			// 压缩后的内容与原内容不同，强 ETag 降级为弱 ETag
+			if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+				header.Set("ETag", "W/"+etag)
+			}
+			w.gz = w.b.acquire(w.ResponseWriter)
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// compressible 判断响应的状态码与媒体类型是否适合压缩
+func (w *compressWriter) compressible(header http.Header) bool {
+	switch {
+	case w.status < http.StatusOK, w.status == http.StatusNoContent,
+		w.status == http.StatusNotModified, w.status == http.StatusPartialContent:
+		return false
+	case header.Get("Content-Encoding") != "", header.Get("Content-Range") != "":
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, t := range w.b.contentTypes {
+		if t == mediaType || strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// tooSmall 判断声明的 Content-Length 是否小于 MinSize
+func (w *compressWriter) tooSmall(header http.Header) bool {
+	n, err := strconv.Atoi(header.Get("Content-Length"))
+	return err == nil && n < w.b.minSize
+}
+
+// finish 写出处理函数通过 RespData 设置的响应，并关闭编码器
+func (w *compressWriter) finish(ctx *ant.Context) {
+	// 连接已被接管
+	if !w.decided && w.ResponseWriter.Written() {
+		return
+	}
+	if !w.decided {
+		if w.status == 0 {
+			w.status = ctx.RespStatusCode
+		}
+		if len(w.buf) == 0 && len(ctx.RespData) > 0 {
+			if w.status == 0 {
+				w.status = http.StatusOK
+			}
+			w.buf = ctx.RespData
+		}
+		if w.status == 0 {
+			// 没有任何响应，交给服务器处理
+			return
+		}
+		_ = w.decide(len(w.buf) >= w.b.minSize)
+	}
+	if w.gz != nil {
+		_ = w.gz.Close()
+		w.b.pool.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+// acquire 从池中获取写入 dst 的编码器
+func (b *MiddlewareBuilder) acquire(dst http.ResponseWriter) *gzip.Writer {
+	if gz, ok := b.pool.Get().(*gzip.Writer); ok {
+		gz.Reset(dst)
+		return gz
+	}
+	gz, _ := gzip.NewWriterLevel(dst, b.level)
+	return gz
+}
+
+// acceptsGzip 返回客户端是否接受 gzip 编码
+func acceptsGzip(req *http.Request) bool {
+	for _, v := range req.Header.Values("Accept-Encoding") {
+		for _, enc := range strings.Split(v, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+			if strings.EqualFold(strings.TrimSpace(name), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package compress
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/justinwongcn/ant"
+)
+
+func newServer() *ant.HTTPServer {
+	server := ant.NewHTTPServer()
+	server.Use(NewMiddlewareBuilder().MinSize(64).Build())
+	server.Handle("GET /buffered", func(ctx *ant.Context) {
+		ctx.Resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		ctx.Resp.Header().Set("ETag", `"v1"`)
+		ctx.RespStatusCode = http.StatusCreated
+		ctx.RespData = []byte(strings.Repeat("buffered ", 20))
+	})
+	server.Handle("GET /small", func(ctx *ant.Context) {
+		_ = ctx.RespJSONOK(map[string]int{"n": 1})
+	})
+	server.Handle("GET /image", func(ctx *ant.Context) {
+		ctx.Resp.Header().Set("Content-Type", "image/png")
+		ctx.RespData = make([]byte, 1024)
+	})
+	server.Handle("GET /export", func(ctx *ant.Context) {
+		ctx.Resp.Header().Set("Content-Type", "text/csv")
+		for i := 0; i < 1000; i++ {
+			_, _ = io.WriteString(ctx.Resp, "id,name,email\n")
+		}
+	})
+	server.Handle("GET /empty", func(ctx *ant.Context) {
+		ctx.RespStatusCode = http.StatusNoContent
+	})
+	return server
+}
+
+func get(server http.Handler, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func gunzip(t *testing.T, data []byte) string {
+	t.Helper()
+	r, err := gzip.NewReader(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("响应不是合法的 gzip: %v", err)
+	}
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(bs)
+}
+
+func TestCompress(t *testing.T) {
+	server := newServer()
+
+	w := get(server, "/buffered", "br, gzip")
+	if w.Code != http.StatusCreated || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("RespData 应被压缩，实际为 %d %v", w.Code, w.Header())
+	}
+	if got := gunzip(t, w.Body.Bytes()); got != strings.Repeat("buffered ", 20) {
+		t.Errorf("解压后的内容不正确: %q", got)
+	}
+	if w.Header().Get("ETag") != `W/"v1"` || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("压缩后应使用弱 ETag 并设置 Vary，实际为 %v", w.Header())
+	}
+
+	w = get(server, "/export", "gzip")
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Content-Length") != "" {
+		t.Fatalf("直接写入的响应应被压缩，实际为 %v", w.Header())
+	}
+	if got := gunzip(t, w.Body.Bytes()); got != strings.Repeat("id,name,email\n", 1000) {
+		t.Errorf("解压后的内容不正确，长度为 %d", len(got))
+	}
+	if w.Body.Len() >= 14000/10 {
+		t.Errorf("重复内容应被有效压缩，实际为 %d 字节", w.Body.Len())
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantCode       int
+		wantVary       bool
+	}{
+		{name: "小于 MinSize", path: "/small", acceptEncoding: "gzip", wantCode: http.StatusOK, wantVary: true},
+		{name: "不压缩的媒体类型", path: "/image", acceptEncoding: "gzip", wantCode: http.StatusOK},
+		{name: "客户端不接受 gzip", path: "/buffered", acceptEncoding: "gzip;q=0, br", wantCode: http.StatusCreated},
+		{name: "没有响应体", path: "/empty", acceptEncoding: "gzip", wantCode: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(server, tt.path, tt.acceptEncoding)
+			if w.Code != tt.wantCode || w.Header().Get("Content-Encoding") != "" {
+				t.Errorf("不应压缩，实际为 %d %v", w.Code, w.Header())
+			}
+			if got := w.Header().Get("Vary") == "Accept-Encoding"; got != tt.wantVary {
+				t.Errorf("Vary 不正确: %v", w.Header())
+			}
+		})
+	}
+	if w = get(server, "/small", "gzip"); w.Body.String() != `{"n":1}` {
+		t.Errorf("未压缩的响应体不正确: %q", w.Body.String())
+	}
+}
+
+// TestCompressStreaming 测试刷新时压缩数据立即发送给客户端
+func TestCompressStreaming(t *testing.T) {
+	next := make(chan struct{})
+	server := ant.NewHTTPServer()
+	server.Use(NewMiddlewareBuilder().Build())
+	server.Handle("GET /events", func(ctx *ant.Context) {
+		stream, err := ctx.SSE()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_ = stream.Send(ant.SSEEvent{Data: "first"})
+		<-next
+		_ = stream.Send(ant.SSEEvent{Data: "second"})
+	})
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("事件流应被压缩，实际为 %v", resp.Header)
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(gz)
+	// 第二个事件发送之前应能读到第一个事件
+	if line, err := r.ReadString('\n'); err != nil || line != "data: first\n" {
+		t.Fatalf("应立即收到第一个事件，实际为 %q %v", line, err)
+	}
+	close(next)
+	rest, _ := io.ReadAll(r)
+	if string(rest) != "\ndata: second\n\n" {
+		t.Errorf("后续事件不正确: %q", rest)
+	}
+}
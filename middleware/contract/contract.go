@@ -0,0 +1,309 @@
+package contract
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"reflect"
+	"strings"
+
+	"github.com/justinwongcn/ant"
+)
+
+// Mismatch 响应与路由声明的结构不一致
+type Mismatch struct {
+	// Pattern 路由模式
+	Pattern string
+	// Status 响应状态码
+	Status int
+	// Problems 不一致的地方，例如 "$.user.id: 应为 number，实际为 string"
+	Problems []string
+}
+
+// MiddlewareBuilder 用于构建响应结构校验中间件
+// 按路由通过 Describe 声明的 RouteMeta.Responses 校验处理函数返回的 JSON，
+// 在开发与测试环境中尽早发现接口与文档不一致，只记录不一致，不修改响应
+type MiddlewareBuilder struct {
+	// Enabled 是否启用，默认取决于 ant.DevMode()，未启用时中间件不做任何处理
+	Enabled bool
+	// Meta 返回路由描述信息的函数，通常为 server.RouteMeta
+	Meta func(pattern string) (ant.RouteMeta, bool)
+	// Report 发现不一致时的回调，默认使用 log 输出，测试中可以调用 t.Error
+	Report func(m Mismatch)
+	// MaxBodySize 校验的最大响应体字节数，超过时跳过校验，默认为 1MB
+	MaxBodySize int
+}
+
+// NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
+// meta: 返回路由描述信息的函数，通常为 server.RouteMeta
+// 注意：只有设置了 ANT_MODE=development 时才会启用，测试中可以将 Enabled 设置为 true
+func NewMiddlewareBuilder(meta func(pattern string) (ant.RouteMeta, bool)) *MiddlewareBuilder {
+	return &MiddlewareBuilder{
+		Enabled: ant.DevMode(),
+		Meta:    meta,
+		Report: func(m Mismatch) {
+			log.Printf("contract: %s 的 %d 响应与声明不一致: %s", m.Pattern, m.Status, strings.Join(m.Problems, "; "))
+		},
+		MaxBodySize: 1 << 20,
+	}
+}
+
+// Build 构建响应结构校验中间件
+// 校验规则：
+// 1. 只校验声明了 Responses 的路由，以及 Content-Type 为 JSON 的响应
+// 2. 2xx 响应的状态码没有声明时报告不一致，其他未声明的状态码不校验
+// 3. 非指针且没有 omitempty 的字段必须出现，未声明的字段同样报告为不一致
+// 4. 实现了 json.Marshaler 或 encoding.TextMarshaler 的类型不校验内部结构
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	if !b.Enabled {
+		return func(next ant.HandleFunc) ant.HandleFunc {
+			return next
+		}
+	}
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			meta, ok := b.Meta(ctx.Req.Pattern)
+			if !ok || len(meta.Responses) == 0 {
+				next(ctx)
+				return
+			}
+			// 记录处理函数直接写入的响应体，例如 RespJSON
+			var tee *teeWriter
+			if rw, ok := ctx.Resp.(ant.ResponseWriter); ok {
+				tee = &teeWriter{ResponseWriter: rw, limit: b.MaxBodySize}
+				ctx.Resp = tee
+				defer func() { ctx.Resp = rw }()
+			}
+			next(ctx)
+
+			status, body := ctx.RespStatusCode, ctx.RespData
+			if tee != nil && tee.Written() {
+				status, body = tee.Status(), tee.buf.Bytes()
+				if tee.overflow {
+					return
+				}
+			}
+			if status == 0 {
+				status = 200
+			}
+			b.check(ctx, meta, status, body)
+		}
+	}
+}
+
+// check 校验响应并报告不一致
+func (b *MiddlewareBuilder) check(ctx *ant.Context, meta ant.RouteMeta, status int, body []byte) {
+	decl, ok := meta.Responses[status]
+	if !ok {
+		if status >= 200 && status < 300 {
+			b.Report(Mismatch{Pattern: ctx.Req.Pattern, Status: status, Problems: []string{"未声明的状态码"}})
+		}
+		return
+	}
+	if decl.Type == nil || len(body) == 0 || len(body) > b.MaxBodySize {
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(ctx.Resp.Header().Get("Content-Type"))
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return
+	}
+	var val any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&val); err != nil {
+		b.Report(Mismatch{Pattern: ctx.Req.Pattern, Status: status, Problems: []string{"响应不是合法的 JSON: " + err.Error()}})
+		return
+	}
+	var problems []string
+	validate("$", val, decl.Type, &problems)
+	if len(problems) > 0 {
+		b.Report(Mismatch{Pattern: ctx.Req.Pattern, Status: status, Problems: problems})
+	}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// validate 按 Go 类型校验解码后的 JSON 值，不一致的地方追加到 problems 中
+func validate(path string, val any, t reflect.Type, problems *[]string) {
+	if val == nil {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		default:
+			*problems = append(*problems, fmt.Sprintf("%s: 应为 %s，实际为 null", path, jsonKind(t)))
+		}
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		expect(path, val, t, "string", problems)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+	case reflect.Bool:
+		expect(path, val, t, "boolean", problems)
+	case reflect.String:
+		expect(path, val, t, "string", problems)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, ok := val.(json.Number); ok {
+			if strings.ContainsAny(string(n), ".eE") {
+				*problems = append(*problems, fmt.Sprintf("%s: 应为整数，实际为 %s", path, n))
+			}
+			return
+		}
+		expect(path, val, t, "number", problems)
+	case reflect.Float32, reflect.Float64:
+		expect(path, val, t, "number", problems)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			expect(path, val, t, "string", problems)
+			return
+		}
+		items, ok := val.([]any)
+		if !ok {
+			expect(path, val, t, "array", problems)
+			return
+		}
+		for i, item := range items {
+			validate(fmt.Sprintf("%s[%d]", path, i), item, t.Elem(), problems)
+		}
+	case reflect.Map:
+		obj, ok := val.(map[string]any)
+		if !ok {
+			expect(path, val, t, "object", problems)
+			return
+		}
+		for k, v := range obj {
+			validate(path+"."+k, v, t.Elem(), problems)
+		}
+	case reflect.Struct:
+		obj, ok := val.(map[string]any)
+		if !ok {
+			expect(path, val, t, "object", problems)
+			return
+		}
+		seen := make(map[string]bool, len(obj))
+		validateStruct(path, obj, t, seen, problems)
+		for k := range obj {
+			if !seen[k] {
+				*problems = append(*problems, fmt.Sprintf("%s.%s: 未声明的字段", path, k))
+			}
+		}
+	}
+}
+
+// validateStruct 校验结构体的字段，匿名嵌入的结构体按 encoding/json 的规则展开
+func validateStruct(path string, obj map[string]any, t reflect.Type, seen map[string]bool, problems *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				validateStruct(path, obj, ft, seen, problems)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		seen[name] = true
+		v, ok := obj[name]
+		if !ok {
+			if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") && ft.Kind() != reflect.Pointer {
+				*problems = append(*problems, fmt.Sprintf("%s.%s: 缺少字段", path, name))
+			}
+			continue
+		}
+		if strings.Contains(opts, "string") {
+			expect(path+"."+name, v, ft, "string", problems)
+			continue
+		}
+		validate(path+"."+name, v, ft, problems)
+	}
+}
+
+// expect 检查 JSON 值的类型
+func expect(path string, val any, t reflect.Type, want string, problems *[]string) {
+	if got := valueKind(val); got != want {
+		*problems = append(*problems, fmt.Sprintf("%s: 应为 %s（%s），实际为 %s", path, want, t, got))
+	}
+}
+
+// valueKind 返回解码后的 JSON 值的类型名称
+func valueKind(val any) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number, float64:
+		return "number"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// jsonKind 返回 Go 类型对应的 JSON 类型名称
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	default:
+		return "number"
+	}
+}
+
+// teeWriter 记录直接写入的响应体，超过 limit 时停止记录
+type teeWriter struct {
+	ant.ResponseWriter
+	buf      bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+// Write 写入响应并记录响应体
+func (w *teeWriter) Write(b []byte) (int, error) {
+	if !w.overflow {
+		if w.buf.Len()+len(b) > w.limit {
+			w.overflow = true
+			w.buf.Reset()
+		} else {
+			w.buf.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,137 @@
+package contract
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+type base struct {
+	ID int64 `json:"id"`
+}
+
+type user struct {
+	base
+	Name      string            `json:"name"`
+	Email     *string           `json:"email"`
+	Tags      []string          `json:"tags,omitempty"`
+	Meta      map[string]int    `json:"meta,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	Extra     map[string]string `json:"-"`
+}
+
+func newServer(t *testing.T, got *[]Mismatch) *ant.HTTPServer {
+	t.Helper()
+	server := ant.NewHTTPServer()
+	b := NewMiddlewareBuilder(server.RouteMeta)
+	b.Enabled = true
+	b.Report = func(m Mismatch) { *got = append(*got, m) }
+	server.Use(b.Build())
+	server.Handle("GET /users/{id}", func(ctx *ant.Context) {
+		ctx.Resp.Header().Set("Content-Type", "application/json")
+		ctx.RespData = []byte(ctx.Req.URL.Query().Get("body"))
+		if code := ctx.Req.URL.Query().Get("code"); code == "201" {
+			ctx.RespStatusCode = http.StatusCreated
+		}
+	})
+	server.Handle("GET /direct", func(ctx *ant.Context) {
+		_ = ctx.RespJSONOK(map[string]any{"id": "1", "name": "tom", "email": nil, "created_at": "2024-01-01T00:00:00Z"})
+	})
+	server.Handle("GET /undocumented", func(ctx *ant.Context) {
+		ctx.RespData = []byte(`{"anything":1}`)
+	})
+	for _, pattern := range []string{"GET /users/{id}", "GET /direct"} {
+		server.Describe(pattern, ant.RouteMeta{Responses: map[int]ant.Body{
+			http.StatusOK:       *ant.BodyOf[user]("用户"),
+			http.StatusNotFound: {Description: "不存在"},
+		}})
+	}
+	return server
+}
+
+func TestContract(t *testing.T) {
+	testCases := []struct {
+		name   string
+		target string
+		want   []string
+	}{
+		{
+			name:   "一致",
+			target: `/users/1?body={"id":1,"name":"tom","email":null,"tags":["a"],"created_at":"2024-01-01T00:00:00Z"}`,
+		},
+		{
+			name:   "类型不一致与缺少字段",
+			target: `/users/1?body={"id":1.5,"email":3,"tags":"a","meta":{"x":"y"},"created_at":"2024-01-01T00:00:00Z"}`,
+			want: []string{
+				"$.id: 应为整数，实际为 1.5",
+				"$.name: 缺少字段",
+				"$.email: 应为 string（string），实际为 number",
+				"$.tags: 应为 array（[]string），实际为 string",
+				"$.meta.x: 应为 number（int），实际为 string",
+			},
+		},
+		{
+			name:   "未声明的字段",
+			target: `/users/1?body={"id":1,"name":"tom","created_at":"2024-01-01T00:00:00Z","password":"x"}`,
+			want:   []string{"$.password: 未声明的字段"},
+		},
+		{
+			name:   "未声明的状态码",
+			target: `/users/1?code=201&body={}`,
+			want:   []string{"未声明的状态码"},
+		},
+		{
+			name:   "直接写入的响应",
+			target: "/direct",
+			want:   []string{"$.id: 应为 number（int64），实际为 string"},
+		},
+		{
+			name:   "未描述的路由",
+			target: "/undocumented",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []Mismatch
+			server := newServer(t, &got)
+			target := strings.NewReplacer(`"`, "%22", "{", "%7B", "}", "%7D", " ", "%20").Replace(tc.target)
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+			if len(tc.want) == 0 {
+				if len(got) != 0 {
+					t.Errorf("不应报告不一致，实际为 %+v", got)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("应报告一次不一致，实际为 %+v", got)
+			}
+			problems := strings.Join(got[0].Problems, "\n")
+			for _, want := range tc.want {
+				if !strings.Contains(problems, want) {
+					t.Errorf("应包含 %q，实际为:\n%s", want, problems)
+				}
+			}
+			if len(got[0].Problems) != len(tc.want) {
+				t.Errorf("应有 %d 处不一致，实际为:\n%s", len(tc.want), problems)
+			}
+		})
+	}
+}
+
+func TestContractDisabled(t *testing.T) {
+	b := NewMiddlewareBuilder(func(string) (ant.RouteMeta, bool) {
+		t.Fatal("未启用时不应读取路由描述")
+		return ant.RouteMeta{}, false
+	})
+	b.Enabled = false
+	called := false
+	b.Build()(func(ctx *ant.Context) { called = true })(&ant.Context{})
+	if !called {
+		t.Error("未启用时应直接调用下一个处理器")
+	}
+}
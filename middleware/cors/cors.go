@@ -0,0 +1,150 @@
+package cors
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/justinwongcn/ant"
+)
+
+// Routes 读取路由描述信息并查找请求命中的路由，*ant.HTTPServer 实现了该接口
+type Routes interface {
+	RouteMeta(pattern string) (ant.RouteMeta, bool)
+	MatchRoute(method string, r *http.Request) (string, bool)
+}
+
+// MiddlewareBuilder 用于构建跨域资源共享中间件
+// 按命中路由的 RouteMeta.CORS 确定生效的策略，没有设置时使用全局策略，
+// 例如公开接口允许任意来源，合作方接口只允许合作方的域名并携带凭据
+type MiddlewareBuilder struct {
+	routes Routes
+	policy ant.CORSPolicy
+}
+
+// NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
+// routes: 路由描述信息的来源，通常为 server
+// policy: 全局策略
+//
+//	b := cors.NewMiddlewareBuilder(server, ant.CORSPolicy{AllowOrigins: []string{"*"}})
+//	server.Use(b.Build())
+//	server.Handle("OPTIONS /", b.Preflight())
+//	partner := server.Group("/partner")
+//	partner.CORS(ant.CORSPolicy{AllowOrigins: []string{"https://*.partner.com"}, AllowCredentials: true})
+func NewMiddlewareBuilder(routes Routes, policy ant.CORSPolicy) *MiddlewareBuilder {
+	return &MiddlewareBuilder{routes: routes, policy: policy}
+}
+
+// Build 构建跨域资源共享中间件
+// 1. 来源被允许时为响应加上 Access-Control-Allow-Origin 等响应头，不被允许时不加，由浏览器拦截响应
+// 2. 预检请求由中间件直接响应，不会调用处理函数
+// 3. 策略在每个请求中读取，通过 UpdateRouteMeta 修改后立即生效
+// 注意：没有注册 OPTIONS 路由的路径，预检请求在进入中间件之前就被响应 405，需要同时注册 Preflight
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			if isPreflight(ctx.Req) {
+				b.preflight(ctx)
+				return
+			}
+			origin := ctx.Req.Header.Get("Origin")
+			if origin == "" {
+				next(ctx)
+				return
+			}
+			policy := b.resolve(ctx.Req.Pattern)
+			header := ctx.Resp.Header()
+			header.Add("Vary", "Origin")
+			if policy.AllowsOrigin(origin) {
+				setAllowOrigin(header, policy, origin)
+				if len(policy.ExposeHeaders) > 0 {
+					header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposeHeaders, ", "))
+				}
+			}
+			next(ctx)
+		}
+	}
+}
+
+// Preflight 返回响应预检请求的处理函数，通常注册为 "OPTIONS /"
+// 按 Access-Control-Request-Method 查找实际请求命中的路由，使用该路由的策略，
+// 不是预检请求的 OPTIONS 请求响应 404
+func (b *MiddlewareBuilder) Preflight() ant.HandleFunc {
+	return func(ctx *ant.Context) {
+		if !isPreflight(ctx.Req) {
+			ctx.RespError(ant.ErrNotFound)
+			return
+		}
+		b.preflight(ctx)
+	}
+}
+
+// preflight 响应预检请求，实际请求没有命中路由，或者来源、方法、请求头不被允许时响应 403
+func (b *MiddlewareBuilder) preflight(ctx *ant.Context) {
+	method := ctx.Req.Header.Get("Access-Control-Request-Method")
+	pattern, matched := b.routes.MatchRoute(method, ctx.Req)
+	policy := b.resolve(pattern)
+
+	var headers []string
+	for _, v := range ctx.Req.Header.Values("Access-Control-Request-Headers") {
+		for _, h := range strings.Split(v, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				headers = append(headers, h)
+			}
+		}
+	}
+
+	header := ctx.Resp.Header()
+	header.Add("Vary", "Origin, Access-Control-Request-Method, Access-Control-Request-Headers")
+	origin := ctx.Req.Header.Get("Origin")
+	if !matched || !policy.AllowsOrigin(origin) || !policy.AllowsMethod(method) ||
+		slices.ContainsFunc(headers, func(h string) bool { return !policy.AllowsHeader(h) }) {
+		ctx.RespStatusCode = http.StatusForbidden
+		ctx.RespData = []byte("跨域请求不被允许")
+		return
+	}
+	setAllowOrigin(header, policy, origin)
+	header.Set("Access-Control-Allow-Methods", strings.Join(policy.Methods(), ", "))
+	if len(headers) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	}
+	if policy.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+	}
+	ctx.RespStatusCode = http.StatusNoContent
+}
+
+// resolve 返回路由生效的策略，路由没有设置时使用全局策略
+func (b *MiddlewareBuilder) resolve(pattern string) ant.CORSPolicy {
+	if pattern != "" {
+		if meta, _ := b.routes.RouteMeta(pattern); meta.CORS != nil {
+			return *meta.CORS
+		}
+	}
+	return b.policy
+}
+
+// setAllowOrigin 设置允许的来源与凭据
+// 只有 AllowOrigins 中明确列出的来源才携带凭据，只被 "*" 匹配的来源响应 "*" 且不携带凭据，
+// 避免任意网站以用户的身份读取响应
+func setAllowOrigin(header http.Header, policy ant.CORSPolicy, origin string) {
+	explicit := ant.CORSPolicy{AllowOrigins: slices.DeleteFunc(slices.Clone(policy.AllowOrigins), func(o string) bool {
+		return o == "*"
+	})}
+	credentials := policy.AllowCredentials && explicit.AllowsOrigin(origin)
+	if !credentials && slices.Contains(policy.AllowOrigins, "*") {
+		header.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+	if credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// isPreflight 判断是否为预检请求
+func isPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions && req.Header.Get("Origin") != "" &&
+		req.Header.Get("Access-Control-Request-Method") != ""
+}
@@ -0,0 +1,150 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+func newServer() *ant.HTTPServer {
+	server := ant.NewHTTPServer()
+	b := NewMiddlewareBuilder(server, ant.CORSPolicy{AllowOrigins: []string{"*"}, AllowHeaders: []string{"Content-Type"}})
+	server.Use(b.Build())
+	server.Handle("OPTIONS /", b.Preflight())
+	ok := func(ctx *ant.Context) { ctx.RespData = []byte("ok") }
+	server.Handle("GET /public/items", ok)
+
+	partner := server.Group("/partner")
+	partner.CORS(ant.CORSPolicy{
+		AllowOrigins:     []string{"https://*.partner.com"},
+		AllowMethods:     []string{http.MethodGet, http.MethodPost},
+		AllowHeaders:     []string{"Authorization"},
+		ExposeHeaders:    []string{"X-Request-Id"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+	partner.Handle("POST /orders", ok)
+	partner.Handle("PUT /orders", ok)
+	partner.Handle("GET /internal", ok)
+	partner.Describe("GET /internal", ant.RouteMeta{Summary: "内部接口", CORS: &ant.CORSPolicy{}})
+
+	mixed := server.Group("/mixed")
+	mixed.CORS(ant.CORSPolicy{AllowOrigins: []string{"https://app.com", "*"}, AllowCredentials: true})
+	mixed.Handle("GET /items", ok)
+	return server
+}
+
+func request(server http.Handler, method, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORS(t *testing.T) {
+	server := newServer()
+
+	tests := []struct {
+		name            string
+		path            string
+		origin          string
+		wantOrigin      string
+		wantCredentials string
+		wantExpose      string
+	}{
+		{name: "全局策略允许任意来源", path: "/public/items", origin: "https://a.com", wantOrigin: "*"},
+		{name: "分组策略允许合作方", path: "/partner/orders", origin: "https://api.partner.com",
+			wantOrigin: "https://api.partner.com", wantCredentials: "true", wantExpose: "X-Request-Id"},
+		{name: "分组策略拒绝其他来源", path: "/partner/orders", origin: "https://a.com"},
+		{name: "路由策略拒绝全部来源", path: "/partner/internal", origin: "https://api.partner.com"},
+		{name: "不是跨域请求", path: "/partner/orders"},
+		{name: "任意来源不携带凭据", path: "/mixed/items", origin: "https://evil.com", wantOrigin: "*"},
+		{name: "明确列出的来源携带凭据", path: "/mixed/items", origin: "https://app.com",
+			wantOrigin: "https://app.com", wantCredentials: "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := http.MethodGet
+			if tt.path == "/partner/orders" {
+				method = http.MethodPost
+			}
+			w := request(server, method, tt.path, map[string]string{"Origin": tt.origin})
+			if w.Code != http.StatusOK || w.Body.String() != "ok" {
+				t.Fatalf("处理函数应被调用，实际为 %d %q", w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin 期望 %q, 得到 %q", tt.wantOrigin, got)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials 期望 %q, 得到 %q", tt.wantCredentials, got)
+			}
+			if got := w.Header().Get("Access-Control-Expose-Headers"); got != tt.wantExpose {
+				t.Errorf("Access-Control-Expose-Headers 期望 %q, 得到 %q", tt.wantExpose, got)
+			}
+		})
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	server := newServer()
+
+	tests := []struct {
+		name     string
+		path     string
+		origin   string
+		method   string
+		headers  string
+		wantCode int
+	}{
+		{name: "全局策略", path: "/public/items", origin: "https://a.com", method: http.MethodGet, headers: "content-type", wantCode: http.StatusNoContent},
+		{name: "全局策略不允许的请求头", path: "/public/items", origin: "https://a.com", method: http.MethodGet, headers: "Authorization", wantCode: http.StatusForbidden},
+		{name: "分组策略", path: "/partner/orders", origin: "https://x.partner.com", method: http.MethodPost, headers: "Authorization", wantCode: http.StatusNoContent},
+		{name: "分组策略不允许的来源", path: "/partner/orders", origin: "https://a.com", method: http.MethodPost, wantCode: http.StatusForbidden},
+		{name: "实际请求没有命中路由", path: "/partner/orders", origin: "https://x.partner.com", method: http.MethodDelete, wantCode: http.StatusForbidden},
+		{name: "分组策略不允许的方法", path: "/partner/orders", origin: "https://x.partner.com", method: http.MethodPut, wantCode: http.StatusForbidden},
+		{name: "路由策略", path: "/partner/internal", origin: "https://x.partner.com", method: http.MethodGet, wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(server, http.MethodOptions, tt.path, map[string]string{
+				"Origin":                         tt.origin,
+				"Access-Control-Request-Method":  tt.method,
+				"Access-Control-Request-Headers": tt.headers,
+			})
+			if w.Code != tt.wantCode {
+				t.Fatalf("期望状态码 %d, 得到 %d %q", tt.wantCode, w.Code, w.Body.String())
+			}
+			if allowed := w.Header().Get("Access-Control-Allow-Origin") != ""; allowed != (tt.wantCode == http.StatusNoContent) {
+				t.Errorf("响应头不正确: %v", w.Header())
+			}
+		})
+	}
+
+	w := request(server, http.MethodOptions, "/partner/orders", map[string]string{
+		"Origin":                         "https://x.partner.com",
+		"Access-Control-Request-Method":  http.MethodPost,
+		"Access-Control-Request-Headers": "authorization",
+	})
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "https://x.partner.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, POST",
+		"Access-Control-Allow-Headers":     "authorization",
+		"Access-Control-Max-Age":           "600",
+	}
+	for k, v := range want {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("%s 期望 %q, 得到 %q", k, v, got)
+		}
+	}
+
+	if w = request(server, http.MethodOptions, "/public/items", nil); w.Code != http.StatusNotFound {
+		t.Errorf("不是预检请求的 OPTIONS 请求应返回 404，实际为 %d", w.Code)
+	}
+}
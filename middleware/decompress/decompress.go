@@ -0,0 +1,191 @@
+package decompress
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/justinwongcn/ant"
+)
+
+// ErrBodyTooLarge 解压后的请求体超出 MaxSize 或 MaxRatio 的限制
+var ErrBodyTooLarge = errors.New("decompress: 解压后的请求体超出限制")
+
+// ratioThreshold 解压后的大小超过该值才检查压缩比，避免误伤高度重复的小请求体
+const ratioThreshold = 64 << 10
+
+// Decoder 根据压缩后的数据流创建解压后的数据流
+type Decoder func(r io.Reader) (io.ReadCloser, error)
+
+// MiddlewareBuilder 用于构建请求体解压中间件
+// 根据请求头 Content-Encoding 透明地解压请求体，处理函数可以像普通请求一样调用 BindJSON
+type MiddlewareBuilder struct {
+	// MaxSize 解压后请求体的最大字节数，默认为 10MB，为0时不限制
+	MaxSize int64
+	// MaxRatio 解压后与压缩前大小的最大比例，默认为 100，为0时不限制
+	// 用于防御压缩炸弹：很小的请求体解压后占用大量内存
+	MaxRatio int64
+	decoders map[string]Decoder
+}
+
+// NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
+// 默认支持 gzip、deflate 与 br 编码
+func NewMiddlewareBuilder() *MiddlewareBuilder {
+	return &MiddlewareBuilder{
+		MaxSize:  10 << 20,
+		MaxRatio: 100,
+		decoders: map[string]Decoder{
+			"gzip": func(r io.Reader) (io.ReadCloser, error) {
+				return gzip.NewReader(r)
+			},
+			// HTTP 中的 deflate 编码是 zlib 格式的数据
+			"deflate": zlib.NewReader,
+			"br": func(r io.Reader) (io.ReadCloser, error) {
+				return io.NopCloser(brotli.NewReader(r)), nil
+			},
+		},
+	}
+}
+
+// RegisterDecoder 注册编码对应的解压实现，例如 zstd
+// encoding: Content-Encoding 中的编码名称，不区分大小写
+func (b *MiddlewareBuilder) RegisterDecoder(encoding string, decoder Decoder) *MiddlewareBuilder {
+	b.decoders[strings.ToLower(encoding)] = decoder
+	return b
+}
+
+// Build 构建请求体解压中间件
+// 注意：
+// 1. 不支持的编码返回 415，并通过 Accept-Encoding 响应头告知支持的编码
+// 2. 压缩数据格式错误返回 400
+// 3. 超出限制时读取请求体返回 ErrBodyTooLarge，由处理函数决定响应，通常为 413
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			encodings := parseEncodings(ctx.Req.Header.Values("Content-Encoding"))
+			if len(encodings) == 0 || ctx.Req.Body == nil || ctx.Req.Body == http.NoBody {
+				next(ctx)
+				return
+			}
+			for _, enc := range encodings {
+				if _, ok := b.decoders[enc]; !ok {
+					ctx.Resp.Header().Set("Accept-Encoding", b.acceptEncoding())
+					ctx.RespStatusCode = http.StatusUnsupportedMediaType
+					ctx.RespData = []byte("不支持的 Content-Encoding: " + enc)
+					return
+				}
+			}
+
+			body, err := b.wrap(ctx.Req.Body, encodings)
+			if err != nil {
+				ctx.RespStatusCode = http.StatusBadRequest
+				ctx.RespData = []byte("请求体解压失败")
+				return
+			}
+			ctx.Req.Body = body
+			ctx.Req.Header.Del("Content-Encoding")
+			ctx.Req.Header.Del("Content-Length")
+			ctx.Req.ContentLength = -1
+			next(ctx)
+		}
+	}
+}
+
+// wrap 按编码的相反顺序逐层解压
+func (b *MiddlewareBuilder) wrap(raw io.ReadCloser, encodings []string) (io.ReadCloser, error) {
+	compressed := &countingReader{r: raw}
+	body := &limitedBody{raw: raw, compressed: compressed, maxSize: b.MaxSize, maxRatio: b.MaxRatio}
+	var r io.Reader = compressed
+	for i := len(encodings) - 1; i >= 0; i-- {
+		rc, err := b.decoders[encodings[i]](r)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		body.closers = append(body.closers, rc)
+		r = rc
+	}
+	body.r = r
+	return body, nil
+}
+
+// acceptEncoding 返回支持的编码列表
+func (b *MiddlewareBuilder) acceptEncoding() string {
+	encodings := make([]string, 0, len(b.decoders))
+	for enc := range b.decoders {
+		encodings = append(encodings, enc)
+	}
+	sort.Strings(encodings)
+	return strings.Join(encodings, ", ")
+}
+
+// parseEncodings 解析 Content-Encoding，忽略 identity
+func parseEncodings(values []string) []string {
+	var encodings []string
+	for _, v := range values {
+		for _, enc := range strings.Split(v, ",") {
+			enc = strings.ToLower(strings.TrimSpace(enc))
+			if enc != "" && enc != "identity" {
+				encodings = append(encodings, enc)
+			}
+		}
+	}
+	return encodings
+}
+
+// countingReader 统计读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// limitedBody 解压后的请求体，超出限制时返回 ErrBodyTooLarge
+type limitedBody struct {
+	r          io.Reader
+	raw        io.Closer
+	closers    []io.Closer
+	compressed *countingReader
+	n          int64
+	maxSize    int64
+	maxRatio   int64
+	err        error
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.maxSize > 0 && l.n > l.maxSize {
+		l.err = ErrBodyTooLarge
+		return 0, l.err
+	}
+	if l.maxRatio > 0 && l.n > ratioThreshold && l.n > l.compressed.n*l.maxRatio {
+		l.err = ErrBodyTooLarge
+		return 0, l.err
+	}
+	return n, err
+}
+
+// Close 关闭各层解压器与原始请求体
+func (l *limitedBody) Close() error {
+	var errs []error
+	for i := len(l.closers) - 1; i >= 0; i-- {
+		errs = append(errs, l.closers[i].Close())
+	}
+	errs = append(errs, l.raw.Close())
+	return errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+package decompress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/justinwongcn/ant"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newServer 创建回显请求体的服务器，读取失败时返回 413
+func newServer(b *MiddlewareBuilder) *ant.HTTPServer {
+	server := ant.NewHTTPServer()
+	server.Use(b.Build())
+	server.Handle("POST /echo", func(ctx *ant.Context) {
+		if enc := ctx.Req.Header.Get("Content-Encoding"); enc != "" {
+			ctx.RespStatusCode = http.StatusInternalServerError
+			return
+		}
+		data, err := io.ReadAll(ctx.Req.Body)
+		if errors.Is(err, ErrBodyTooLarge) {
+			ctx.RespStatusCode = http.StatusRequestEntityTooLarge
+			return
+		}
+		ctx.RespData = data
+	})
+	return server
+}
+
+func TestDecompress(t *testing.T) {
+	server := newServer(NewMiddlewareBuilder())
+	payload := []byte(`{"name":"tom"}`)
+	for _, enc := range []string{"gzip", "deflate", "br"} {
+		t.Run(enc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader(compress(t, enc, payload)))
+			req.Header.Set("Content-Encoding", strings.ToUpper(enc))
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, req)
+			if w.Code != http.StatusOK || w.Body.String() != string(payload) {
+				t.Errorf("期望解压后的请求体，实际 %d %q", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// 多层编码按相反顺序解压
+	req := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader(compress(t, "br", compress(t, "gzip", payload))))
+	req.Header.Set("Content-Encoding", "gzip, br")
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	if w.Body.String() != string(payload) {
+		t.Errorf("多层编码解压错误: %q", w.Body.String())
+	}
+}
+
+func TestDecompressErrors(t *testing.T) {
+	server := newServer(NewMiddlewareBuilder())
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("data"))
+	req.Header.Set("Content-Encoding", "zstd")
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	if w.Code != http.StatusUnsupportedMediaType || w.Header().Get("Accept-Encoding") != "br, deflate, gzip" {
+		t.Errorf("不支持的编码期望 415 与 Accept-Encoding，实际 %d %q", w.Code, w.Header().Get("Accept-Encoding"))
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("格式错误的数据期望 400，实际 %d", w.Code)
+	}
+}
+
+func TestDecompressLimits(t *testing.T) {
+	bomb := compress(t, "gzip", make([]byte, 1<<20))
+
+	// 压缩比超出限制
+	req := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader(bomb))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	newServer(NewMiddlewareBuilder()).ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("压缩比超出限制期望 413，实际 %d", w.Code)
+	}
+
+	// 大小超出限制
+	b := NewMiddlewareBuilder()
+	b.MaxRatio = 0
+	b.MaxSize = 1 << 10
+	req = httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader(bomb))
+	req.Header.Set("Content-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	newServer(b).ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("大小超出限制期望 413，实际 %d", w.Code)
+	}
+
+	// 关闭限制后可以完整读取
+	b.MaxSize = 0
+	req = httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader(bomb))
+	req.Header.Set("Content-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	newServer(b).ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.Len() != 1<<20 {
+		t.Errorf("期望完整读取，实际 %d %d", w.Code, w.Body.Len())
+	}
+}
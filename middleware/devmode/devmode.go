@@ -0,0 +1,160 @@
+package devmode
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"runtime/debug"
+	"slices"
+
+	"github.com/justinwongcn/ant"
+)
+
+// MiddlewareBuilder 用于构建开发模式的错误页面中间件
+// 处理函数 panic 或返回错误状态码时，渲染包含调用栈、请求内容与路由表的 HTML 页面
+type MiddlewareBuilder struct {
+	// Enabled 是否启用，默认取决于 ant.DevMode()，未启用时中间件不做任何处理
+	Enabled bool
+	// Routes 返回路由表的函数，通常为 server.Routes，为nil时页面不显示路由表
+	Routes func() []string
+	// RouteMeta 返回路由描述信息的函数，通常为 server.RouteMeta，设置后路由表中标记弃用的路由
+	RouteMeta func(pattern string) (ant.RouteMeta, bool)
+	// MinStatus 渲染错误页面的最小状态码，默认为 500
+	MinStatus int
+	// SensitiveHeaders 页面中需要脱敏的请求头
+	SensitiveHeaders []string
+}
+
+// NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
+// routes: 返回路由表的函数，通常为 server.Routes
+// 注意：只有设置了 ANT_MODE=development 时才会启用，生产环境中自动关闭
+func NewMiddlewareBuilder(routes func() []string) *MiddlewareBuilder {
+	return &MiddlewareBuilder{
+		Enabled:          ant.DevMode(),
+		Routes:           routes,
+		MinStatus:        http.StatusInternalServerError,
+		SensitiveHeaders: slices.Clone(ant.DefaultSensitiveHeaders),
+	}
+}
+
+// Build 构建开发模式的错误页面中间件
+// 应注册为第一个中间件，以便捕获其他中间件中的 panic
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	if !b.Enabled {
+		return func(next ant.HandleFunc) ant.HandleFunc {
+			return next
+		}
+	}
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			defer func() {
+				if val := recover(); val != nil {
+					log.Printf("devmode: %s %s panic: %v", ctx.Req.Method, ctx.Req.URL.Path, val)
+					b.render(ctx, errorPage{
+						Status:  http.StatusInternalServerError,
+						Title:   "panic",
+						Message: fmt.Sprint(val),
+						Stack:   string(debug.Stack()),
+					})
+				}
+			}()
+			next(ctx)
+			if ctx.RespStatusCode >= b.MinStatus {
+				b.render(ctx, errorPage{
+					Status:  ctx.RespStatusCode,
+					Title:   http.StatusText(ctx.RespStatusCode),
+					Message: string(ctx.RespData),
+				})
+			}
+		}
+	}
+}
+
+// errorPage 错误页面的数据
+type errorPage struct {
+	Status  int
+	Title   string
+	Message string
+	Stack   string
+	Request string
+	Pattern string
+	Routes  []routeItem
+}
+
+// routeItem 路由表中的一行
+type routeItem struct {
+	Pattern string
+	// Note 弃用说明，没有弃用时为空
+	Note string
+}
+
+// render 渲染错误页面，处理函数已经直接写入响应时不做处理
+func (b *MiddlewareBuilder) render(ctx *ant.Context, page errorPage) {
+	if rw, ok := ctx.Resp.(ant.ResponseWriter); ok && rw.Written() {
+		return
+	}
+	page.Request = b.dumpRequest(ctx.Req)
+	page.Pattern = ctx.Req.Pattern
+	if b.Routes != nil {
+		for _, pattern := range b.Routes() {
+			item := routeItem{Pattern: pattern}
+			if b.RouteMeta != nil {
+				meta, _ := b.RouteMeta(pattern)
+				item.Note = meta.DeprecationNote()
+			}
+			page.Routes = append(page.Routes, item)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, page); err != nil {
+		log.Printf("devmode: 渲染错误页面失败: %v", err)
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "text/html; charset=utf-8")
+	ctx.RespStatusCode = page.Status
+	ctx.RespData = buf.Bytes()
+}
+
+// dumpRequest 返回脱敏后的请求行与请求头
+func (b *MiddlewareBuilder) dumpRequest(req *http.Request) string {
+	r := req.Clone(req.Context())
+	r.Header = ant.RedactHeader(req.Header, b.SensitiveHeaders)
+	// 请求体可能已经被处理函数读取，只输出请求行与请求头
+	dump, err := httputil.DumpRequest(r, false)
+	if err != nil {
+		return err.Error()
+	}
+	return string(dump)
+}
+
+// pageTemplate 错误页面模板，html/template 会转义全部内容
+var pageTemplate = template.Must(template.New("devmode").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Status}} {{.Title}}</title>
+<style>
+body { font-family: -apple-system, sans-serif; margin: 2em; color: #222; }
+h1 { color: #c0392b; }
+pre { background: #f6f8fa; padding: 1em; overflow-x: auto; }
+li.matched { font-weight: bold; color: #c0392b; }
+span.deprecated { color: #b7950b; }
+</style>
+</head>
+<body>
+<h1>{{.Status}} {{.Title}}</h1>
+{{if .Message}}<pre>{{.Message}}</pre>{{end}}
+{{if .Stack}}<h2>调用栈</h2>
+<pre>{{.Stack}}</pre>{{end}}
+<h2>请求</h2>
+<pre>{{.Request}}</pre>
+{{if .Routes}}<h2>路由表</h2>
+<ul>{{range .Routes}}<li{{if eq .Pattern $.Pattern}} class="matched"{{end}}>{{.Pattern}}{{with .Note}} <span class="deprecated">{{.}}</span>{{end}}</li>{{end}}</ul>{{end}}
+<p>开发模式错误页面，设置 ANT_MODE=production 或移除该环境变量后关闭</p>
+</body>
+</html>
+`))
@@ -0,0 +1,123 @@
+package devmode
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/justinwongcn/ant"
+)
+
+// newServer 创建注册了开发模式中间件的服务器
+func newServer(enabled bool) *ant.HTTPServer {
+	server := ant.NewHTTPServer()
+	builder := NewMiddlewareBuilder(server.Routes)
+	builder.Enabled = enabled
+	builder.RouteMeta = server.RouteMeta
+	server.Use(builder.Build())
+	server.Handle("GET /panic/{id}", func(ctx *ant.Context) {
+		panic("<script>boom</script>")
+	})
+	server.Handle("GET /error", func(ctx *ant.Context) {
+		ctx.RespStatusCode = http.StatusBadGateway
+		ctx.RespData = []byte("上游不可用")
+	})
+	server.Handle("GET /notfound", func(ctx *ant.Context) {
+		ctx.RespStatusCode = http.StatusNotFound
+		ctx.RespData = []byte("not found")
+	})
+	server.Describe("GET /notfound", ant.RouteMeta{Deprecated: true})
+	server.Handle("GET /direct", func(ctx *ant.Context) {
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		_, _ = ctx.Resp.Write([]byte("direct"))
+	})
+	return server
+}
+
+// TestPanicPage 测试 panic 时渲染错误页面
+func TestPanicPage(t *testing.T) {
+	server := newServer(true)
+	req := httptest.NewRequest(http.MethodGet, "/panic/1", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("期望状态码 500，实际 %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Content-Type 错误: %s", ct)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"&lt;script&gt;boom&lt;/script&gt;", // panic 值被转义
+		"devmode_test.go",                   // 调用栈
+		"GET /panic/1 HTTP/1.1",             // 请求行
+		ant.Redacted,                        // 脱敏的请求头
+		`<li class="matched">GET /panic/{id}</li>`,
+		"<li>GET /error</li>",
+		`<li>GET /notfound <span class="deprecated">[已弃用]</span></li>`, // 弃用的路由
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("错误页面应包含 %q", want)
+		}
+	}
+	if strings.Contains(body, "secret") || strings.Contains(body, "<script>") {
+		t.Error("错误页面不应包含敏感信息或未转义的内容")
+	}
+}
+
+// TestErrorStatusPage 测试错误状态码时渲染错误页面
+func TestErrorStatusPage(t *testing.T) {
+	server := newServer(true)
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/error", nil))
+	if w.Code != http.StatusBadGateway || !strings.Contains(w.Body.String(), "上游不可用") {
+		t.Errorf("错误页面错误: %d %s", w.Code, w.Body.String())
+	}
+
+	// 低于 MinStatus 的状态码保持原样
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/notfound", nil))
+	if w.Body.String() != "not found" {
+		t.Errorf("4xx 响应不应被替换，实际 %q", w.Body.String())
+	}
+
+	// 已经直接写入的响应保持原样
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/direct", nil))
+	if w.Body.String() != "direct" {
+		t.Errorf("直接写入的响应不应被替换，实际 %q", w.Body.String())
+	}
+}
+
+// TestDisabled 测试未启用时不做任何处理
+func TestDisabled(t *testing.T) {
+	server := newServer(false)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/error", nil))
+	if w.Body.String() != "上游不可用" {
+		t.Errorf("未启用时响应不应被替换，实际 %q", w.Body.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("未启用时不应捕获 panic")
+		}
+	}()
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic/1", nil))
+}
+
+// TestEnabledByMode 测试根据运行模式决定是否启用
+func TestEnabledByMode(t *testing.T) {
+	t.Setenv(ant.ModeEnv, "development")
+	if !NewMiddlewareBuilder(nil).Enabled {
+		t.Error("开发模式下应默认启用")
+	}
+	t.Setenv(ant.ModeEnv, "production")
+	if NewMiddlewareBuilder(nil).Enabled {
+		t.Error("生产模式下应默认关闭")
+	}
+}
@@ -1,16 +1,23 @@
 package errhandle
 
-import "github.com/justinwongcn/ant"
+import (
+	"fmt"
+
+	"github.com/justinwongcn/ant"
+)
 
 // MiddlewareBuilder 用于构建错误处理中间件
 type MiddlewareBuilder struct {
-	resp map[int][]byte
+	resp         map[int][]byte
+	reporter     ant.ErrorReporter
+	identityFunc ant.IdentityFunc
 }
 
 // NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
 func NewMiddlewareBuilder() *MiddlewareBuilder {
 	return &MiddlewareBuilder{
-		resp: make(map[int][]byte, 64),
+		resp:     make(map[int][]byte, 64),
+		reporter: ant.NopErrorReporter{},
 	}
 }
 
@@ -22,14 +29,32 @@ func (m *MiddlewareBuilder) RegisterError(code int, resp []byte) *MiddlewareBuil
 	return m
 }
 
+// Reporter 设置错误上报实现，响应状态码为5xx时会上报错误
+// reporter: 错误上报实现
+// identity: 用于解析当前用户与会话ID的函数，可以为nil
+func (m *MiddlewareBuilder) Reporter(reporter ant.ErrorReporter, identity ant.IdentityFunc) *MiddlewareBuilder {
+	m.reporter = reporter
+	m.identityFunc = identity
+	return m
+}
+
 // Build 构建错误处理中间件
 // 该中间件会检查响应状态码，如果匹配已注册的错误码，则使用预设的响应内容
+// 处理函数只设置了 ctx.Err 而没有设置状态码时，按 ant.ErrorStatus 映射状态码并写入错误响应
 func (m *MiddlewareBuilder) Build() ant.Middleware {
 	return func(next ant.HandleFunc) ant.HandleFunc {
 		return func(ctx *ant.Context) {
 			// 先执行后续的处理函数
 			next(ctx)
 
+			if ctx.Err != nil && ctx.RespStatusCode == 0 {
+				ctx.RespError(ctx.Err)
+			}
+
+			if ctx.RespStatusCode >= 500 {
+				m.report(ctx)
+			}
+
 			// 检查状态码是否匹配预设的错误响应
 			resp, ok := m.resp[ctx.RespStatusCode]
 			if ok {
@@ -39,3 +64,18 @@ func (m *MiddlewareBuilder) Build() ant.Middleware {
 		}
 	}
 }
+
+// report 将服务端错误上报给 reporter
+// 处理函数通过 ctx.Err 返回了错误时上报原始错误，否则根据响应内容构造错误
+func (m *MiddlewareBuilder) report(ctx *ant.Context) {
+	err := ctx.Err
+	if err == nil {
+		err = fmt.Errorf("errhandle: 响应状态码 %d: %s", ctx.RespStatusCode, ctx.RespData)
+	}
+	ev := ant.NewErrorEvent(ctx, err)
+	ev.Tags["source"] = "errhandle"
+	if m.identityFunc != nil {
+		ev.User, ev.SessionID = m.identityFunc(ctx)
+	}
+	m.reporter.Report(ctx.Req.Context(), ev)
+}
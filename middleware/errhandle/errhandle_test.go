@@ -1,6 +1,8 @@
 package errhandle
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -97,3 +99,61 @@ func TestErrorHandleMiddlewareChaining(t *testing.T) {
 		t.Errorf("期望响应体 %s, 实际获得 %s", "Not Found", string(ctx.RespData))
 	}
 }
+
+func TestErrorHandleMiddlewareReporter(t *testing.T) {
+	var reported []*ant.ErrorEvent
+	reporter := ant.ErrorReporterFunc(func(_ context.Context, ev *ant.ErrorEvent) {
+		reported = append(reported, ev)
+	})
+	mb := NewMiddlewareBuilder().Reporter(reporter, func(ctx *ant.Context) (string, string) {
+		return "u-1", ""
+	})
+	middleware := mb.Build()
+
+	codes := []int{http.StatusOK, http.StatusNotFound, http.StatusBadGateway}
+	for _, code := range codes {
+		handler := middleware(func(ctx *ant.Context) {
+			ctx.RespStatusCode = code
+		})
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		handler(&ant.Context{Req: req, Resp: httptest.NewRecorder()})
+	}
+
+	if len(reported) != 1 {
+		t.Fatalf("期望只上报 5xx 错误, 实际上报 %d 次", len(reported))
+	}
+	if reported[0].User != "u-1" || reported[0].Tags["source"] != "errhandle" {
+		t.Errorf("上报事件不正确: %+v", reported[0])
+	}
+}
+
+func TestErrorHandleMiddlewareReportsCtxErr(t *testing.T) {
+	var reported []*ant.ErrorEvent
+	reporter := ant.ErrorReporterFunc(func(_ context.Context, ev *ant.ErrorEvent) {
+		reported = append(reported, ev)
+	})
+	sentinel := errors.New("数据库连接失败")
+	server := ant.NewHTTPServer()
+	server.Use(NewMiddlewareBuilder().Reporter(reporter, nil).Build())
+	server.Handle("GET /test", ant.JSONHandler(func(ctx *ant.Context, _ struct{}) (string, error) {
+		return "", sentinel
+	}))
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	if len(reported) != 1 || !errors.Is(reported[0].Err, sentinel) {
+		t.Fatalf("期望上报处理函数返回的原始错误, 实际 %+v", reported)
+	}
+}
+
+func TestErrorHandleMiddlewareMapsCtxErr(t *testing.T) {
+	server := ant.NewHTTPServer()
+	server.Use(NewMiddlewareBuilder().RegisterError(http.StatusNotFound, []byte("not found")).Build())
+	server.Handle("GET /test", func(ctx *ant.Context) {
+		ctx.Err = ant.ErrNotFound
+	})
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+	if w.Code != http.StatusNotFound || w.Body.String() != "not found" {
+		t.Errorf("只设置了 ctx.Err 时应按错误映射状态码，实际为 %d %s", w.Code, w.Body.String())
+	}
+}
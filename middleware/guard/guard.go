@@ -0,0 +1,208 @@
+package guard
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/justinwongcn/ant"
+	"github.com/justinwongcn/ant/middleware/ratelimit"
+)
+
+// 拒绝的原因，同时作为错误响应中的 code 字段
+const (
+	// ReasonRateLimited 超出变更频率限制
+	ReasonRateLimited = "rate_limited"
+	// ReasonConflict 同一资源上已有正在执行的变更
+	ReasonConflict = "conflict"
+)
+
+// Rejection 被拒绝的变更请求，用于审计
+type Rejection struct {
+	// Reason 拒绝的原因，ReasonRateLimited 或 ReasonConflict
+	Reason string
+	// Resource 变更的资源
+	Resource string
+	// Method 请求方法
+	Method string
+	// Path 请求路径
+	Path string
+	// ClientIP 客户端IP
+	ClientIP string
+	// Time 拒绝的时间
+	Time time.Time
+}
+
+// errorBody 错误响应的JSON结构
+type errorBody struct {
+	Code       string `json:"code"`
+	Error      string `json:"error"`
+	Resource   string `json:"resource"`
+	RetryAfter int    `json:"retry_after,omitempty"`
+}
+
+// MiddlewareBuilder 用于构建管理接口的变更保护中间件
+// 对变更请求单独限流，并且同一资源同时只允许一个变更执行，
+// 让突发的变更请求尽快得到 429 或 409，而不是堆积在存储的锁上
+// 读请求（GET、HEAD 等）直接放行
+type MiddlewareBuilder struct {
+	methods     map[string]struct{}
+	quota       ratelimit.Quota
+	store       ratelimit.Store
+	resource    func(ctx *ant.Context) string
+	onReject    func(r Rejection)
+	errFunc     func(err error)
+	mu          sync.Mutex
+	inProgress  map[string]struct{}
+	concurrency bool
+}
+
+// NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
+// 默认保护 POST、PUT、PATCH 与 DELETE 请求，按路由模式区分资源，只启用并发保护
+func NewMiddlewareBuilder() *MiddlewareBuilder {
+	b := &MiddlewareBuilder{
+		quota:       ratelimit.Quota{Limit: -1},
+		store:       ratelimit.NewMemoryStore(),
+		inProgress:  make(map[string]struct{}),
+		concurrency: true,
+		resource: func(ctx *ant.Context) string {
+			return ctx.Req.Pattern
+		},
+		onReject: func(r Rejection) {
+			log.Printf("拒绝变更请求 %s %s: %s, 资源 %s, 客户端 %s", r.Method, r.Path, r.Reason, r.Resource, r.ClientIP)
+		},
+		errFunc: func(err error) {
+			log.Printf("变更限流计数失败: %v", err)
+		},
+	}
+	return b.Methods(http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete)
+}
+
+// Methods 设置需要保护的请求方法，覆盖默认值
+func (b *MiddlewareBuilder) Methods(methods ...string) *MiddlewareBuilder {
+	b.methods = make(map[string]struct{}, len(methods))
+	for _, m := range methods {
+		b.methods[m] = struct{}{}
+	}
+	return b
+}
+
+// RateLimit 设置每个资源的变更频率上限，未调用时不限流
+func (b *MiddlewareBuilder) RateLimit(quota ratelimit.Quota) *MiddlewareBuilder {
+	b.quota = quota
+	return b
+}
+
+// Store 设置限流计数存储，默认使用 ratelimit.MemoryStore
+func (b *MiddlewareBuilder) Store(store ratelimit.Store) *MiddlewareBuilder {
+	b.store = store
+	return b
+}
+
+// Resource 设置获取变更资源的函数，默认为路由模式
+// 例如按服务器聚合区分资源：
+//
+//	guard.NewMiddlewareBuilder().Resource(func(ctx *ant.Context) string {
+//		return "server:" + ctx.Req.PathValue("name")
+//	})
+func (b *MiddlewareBuilder) Resource(fn func(ctx *ant.Context) string) *MiddlewareBuilder {
+	b.resource = fn
+	return b
+}
+
+// AllowConcurrent 允许同一资源上的变更并发执行，只保留限流
+func (b *MiddlewareBuilder) AllowConcurrent() *MiddlewareBuilder {
+	b.concurrency = false
+	return b
+}
+
+// OnReject 设置拒绝变更时的回调，可以写入审计日志，默认使用 log 输出
+func (b *MiddlewareBuilder) OnReject(fn func(r Rejection)) *MiddlewareBuilder {
+	b.onReject = fn
+	return b
+}
+
+// ErrFunc 设置计数存储出错时的回调，出错时放行请求
+func (b *MiddlewareBuilder) ErrFunc(fn func(err error)) *MiddlewareBuilder {
+	b.errFunc = fn
+	return b
+}
+
+// Build 构建变更保护中间件
+// 注意：
+// 1. 超出频率限制时响应 429 并设置 Retry-After，同一资源上已有变更时响应 409
+// 2. 错误响应为 JSON，例如 {"code":"conflict","error":"...","resource":"..."}
+// 3. 被限流的请求不会占用并发保护，被并发保护拒绝的请求仍然计入限流
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			if _, ok := b.methods[ctx.Req.Method]; !ok {
+				next(ctx)
+				return
+			}
+			resource := b.resource(ctx)
+
+			if b.quota.Limit >= 0 {
+				count, reset, err := b.store.Incr(ctx.Req.Context(), "mutation:"+resource, b.quota.Window)
+				if err != nil {
+					b.errFunc(err)
+				} else if count > b.quota.Limit {
+					retryAfter := int(math.Ceil(time.Until(reset).Seconds()))
+					ctx.Resp.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+					b.reject(ctx, http.StatusTooManyRequests, errorBody{
+						Code: ReasonRateLimited, Error: "变更过于频繁，请稍后重试",
+						Resource: resource, RetryAfter: retryAfter,
+					})
+					return
+				}
+			}
+
+			if b.concurrency {
+				if !b.acquire(resource) {
+					b.reject(ctx, http.StatusConflict, errorBody{
+						Code: ReasonConflict, Error: "该资源正在变更，请稍后重试", Resource: resource,
+					})
+					return
+				}
+				defer b.release(resource)
+			}
+			next(ctx)
+		}
+	}
+}
+
+func (b *MiddlewareBuilder) acquire(resource string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.inProgress[resource]; ok {
+		return false
+	}
+	b.inProgress[resource] = struct{}{}
+	return true
+}
+
+func (b *MiddlewareBuilder) release(resource string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.inProgress, resource)
+}
+
+// reject 写入错误响应并回调 OnReject
+func (b *MiddlewareBuilder) reject(ctx *ant.Context, code int, body errorBody) {
+	b.onReject(Rejection{
+		Reason:   body.Code,
+		Resource: body.Resource,
+		Method:   ctx.Req.Method,
+		Path:     ctx.Req.URL.Path,
+		ClientIP: ctx.ClientIP(),
+		Time:     time.Now(),
+	})
+	bs, _ := json.Marshal(body)
+	ctx.Resp.Header().Set("Content-Type", "application/json; charset=utf-8")
+	ctx.RespStatusCode = code
+	ctx.RespData = bs
+}
@@ -0,0 +1,107 @@
+package guard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/ant"
+	"github.com/justinwongcn/ant/middleware/ratelimit"
+)
+
+func call(server *ant.HTTPServer, method, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func TestGuardConflict(t *testing.T) {
+	var mu sync.Mutex
+	var rejections []Rejection
+	b := NewMiddlewareBuilder().
+		Resource(func(ctx *ant.Context) string { return "server:" + ctx.Req.PathValue("name") }).
+		OnReject(func(r Rejection) {
+			mu.Lock()
+			defer mu.Unlock()
+			rejections = append(rejections, r)
+		})
+
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	server := ant.NewHTTPServer()
+	server.Use(b.Build())
+	server.Handle("/servers/{name}", func(ctx *ant.Context) {
+		if ctx.Req.PathValue("name") == "slow" && ctx.Req.Method == http.MethodPut {
+			close(entered)
+			<-unblock
+		}
+		ctx.RespData = []byte("ok")
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- call(server, http.MethodPut, "/servers/slow") }()
+	<-entered
+
+	// 同一资源上的变更被拒绝
+	w := call(server, http.MethodDelete, "/servers/slow")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("并发变更应响应 409，实际为 %d", w.Code)
+	}
+	var body errorBody
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != ReasonConflict || body.Resource != "server:slow" {
+		t.Errorf("错误响应不符合预期: %s", w.Body.String())
+	}
+	// 读请求与其他资源不受影响
+	if w := call(server, http.MethodGet, "/servers/slow"); w.Code != http.StatusOK {
+		t.Errorf("读请求应放行，实际为 %d", w.Code)
+	}
+	if w := call(server, http.MethodPut, "/servers/other"); w.Code != http.StatusOK {
+		t.Errorf("其他资源的变更应放行，实际为 %d", w.Code)
+	}
+
+	close(unblock)
+	if w := <-done; w.Code != http.StatusOK {
+		t.Errorf("第一个变更应成功，实际为 %d", w.Code)
+	}
+	if w := call(server, http.MethodDelete, "/servers/slow"); w.Code != http.StatusOK {
+		t.Errorf("前一个变更完成后应放行，实际为 %d", w.Code)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(rejections) != 1 || rejections[0].Reason != ReasonConflict || rejections[0].Method != http.MethodDelete {
+		t.Errorf("应审计一次拒绝，实际为 %+v", rejections)
+	}
+}
+
+func TestGuardRateLimit(t *testing.T) {
+	b := NewMiddlewareBuilder().
+		RateLimit(ratelimit.Quota{Limit: 2, Window: time.Minute}).
+		OnReject(func(Rejection) {})
+	server := ant.NewHTTPServer()
+	server.Use(b.Build())
+	server.Handle("/flags", func(ctx *ant.Context) {
+		ctx.RespData = []byte("ok")
+	})
+
+	for i := 0; i < 2; i++ {
+		if w := call(server, http.MethodPut, "/flags"); w.Code != http.StatusOK {
+			t.Fatalf("第 %d 次变更应放行，实际为 %d", i+1, w.Code)
+		}
+	}
+	w := call(server, http.MethodPut, "/flags")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("超出频率应响应 429 并设置 Retry-After，实际为 %d %v", w.Code, w.Header())
+	}
+	var body errorBody
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != ReasonRateLimited || body.RetryAfter <= 0 {
+		t.Errorf("错误响应不符合预期: %s", w.Body.String())
+	}
+	if w := call(server, http.MethodGet, "/flags"); w.Code != http.StatusOK {
+		t.Errorf("读请求不应计入限流，实际为 %d", w.Code)
+	}
+}
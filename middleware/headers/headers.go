@@ -0,0 +1,252 @@
+package headers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/justinwongcn/ant"
+)
+
+// op 规则的操作类型
+type op int
+
+const (
+	opAdd op = iota
+	opSet
+	opRemove
+	opRename
+)
+
+// Rule 请求头或响应头的改写规则，通过 Add、Set、Remove 与 Rename 创建
+type Rule struct {
+	op    op
+	name  string
+	to    string
+	value string
+}
+
+// Add 追加头部，已有的值保留
+// value 是值模板，支持的占位符：
+//   - {param:name} 路径参数
+//   - {query:name} 查询参数
+//   - {header:Name} 请求头，改写请求头时读取改写前的值
+//   - {env:NAME} 环境变量，在 Build 时读取
+//   - {method}、{path}、{host}、{client_ip} 请求的方法、路径、主机与客户端IP
+//
+// 使用 {{ 与 }} 表示字面量的花括号
+func Add(name, value string) Rule {
+	return Rule{op: opAdd, name: name, value: value}
+}
+
+// Set 设置头部，覆盖已有的值，value 的格式与 Add 相同
+func Set(name, value string) Rule {
+	return Rule{op: opSet, name: name, value: value}
+}
+
+// Remove 删除头部
+func Remove(name string) Rule {
+	return Rule{op: opRemove, name: name}
+}
+
+// Rename 重命名头部，from 不存在时不做处理，to 已有的值会被覆盖
+func Rename(from, to string) Rule {
+	return Rule{op: opRename, name: from, to: to}
+}
+
+// MiddlewareBuilder 用于构建头部改写中间件
+// 按声明的顺序改写请求头与响应头，常用于对接要求旧式头部的网关或上游服务
+type MiddlewareBuilder struct {
+	routes   map[string]struct{}
+	request  []Rule
+	response []Rule
+}
+
+// NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
+func NewMiddlewareBuilder() *MiddlewareBuilder {
+	return &MiddlewareBuilder{routes: make(map[string]struct{})}
+}
+
+// Routes 限定需要改写的路由，参数为注册时使用的路由模式
+// 未调用时改写所有路由
+func (b *MiddlewareBuilder) Routes(patterns ...string) *MiddlewareBuilder {
+	for _, p := range patterns {
+		b.routes[p] = struct{}{}
+	}
+	return b
+}
+
+// Request 追加请求头的改写规则，在调用后续处理器之前执行
+func (b *MiddlewareBuilder) Request(rules ...Rule) *MiddlewareBuilder {
+	b.request = append(b.request, rules...)
+	return b
+}
+
+// Response 追加响应头的改写规则，在写入响应头之前执行
+// 处理函数直接写入响应或由服务器在最后写入响应时都会执行
+func (b *MiddlewareBuilder) Response(rules ...Rule) *MiddlewareBuilder {
+	b.response = append(b.response, rules...)
+	return b
+}
+
+// Build 构建头部改写中间件
+// 值模板中有未知的占位符或花括号不匹配时 panic
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	request := compileRules(b.request)
+	response := compileRules(b.response)
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			if len(b.routes) > 0 {
+				if _, ok := b.routes[ctx.Req.Pattern]; !ok {
+					next(ctx)
+					return
+				}
+			}
+			if len(response) > 0 {
+				// 响应头的模板使用改写前的请求头
+				orig := ctx.Req.Header.Clone()
+				ctx.OnWriteHeader(func(_ int, header http.Header) {
+					apply(response, header, ctx, orig)
+				})
+			}
+			if len(request) > 0 {
+				header := ctx.Req.Header
+				if header == nil {
+					header = make(http.Header)
+					ctx.Req.Header = header
+				}
+				apply(request, header, ctx, header.Clone())
+			}
+			next(ctx)
+		}
+	}
+}
+
+// compiledRule 解析了值模板的规则
+type compiledRule struct {
+	Rule
+	parts []part
+}
+
+// part 值模板的一段，kind 为空时是字面量
+type part struct {
+	kind string
+	arg  string
+}
+
+func compileRules(rules []Rule) []compiledRule {
+	res := make([]compiledRule, 0, len(rules))
+	for _, r := range rules {
+		c := compiledRule{Rule: r}
+		if r.op == opAdd || r.op == opSet {
+			parts, err := parseTemplate(r.value)
+			if err != nil {
+				panic(fmt.Sprintf("headers: 头部 %s 的值模板 %q 无效: %v", r.name, r.value, err))
+			}
+			c.parts = parts
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+// parseTemplate 解析值模板，环境变量在解析时替换为字面量
+func parseTemplate(tpl string) ([]part, error) {
+	var parts []part
+	var lit strings.Builder
+	for i := 0; i < len(tpl); i++ {
+		switch c := tpl[i]; {
+		case c == '{' && strings.HasPrefix(tpl[i:], "{{"), c == '}' && strings.HasPrefix(tpl[i:], "}}"):
+			lit.WriteByte(c)
+			i++
+		case c == '{':
+			end := strings.IndexByte(tpl[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("缺少 }")
+			}
+			kind, arg, _ := strings.Cut(tpl[i+1:i+end], ":")
+			switch kind {
+			case "env":
+				lit.WriteString(os.Getenv(arg))
+			case "param", "query", "header":
+				if arg == "" {
+					return nil, fmt.Errorf("占位符 {%s} 缺少名称", kind)
+				}
+				fallthrough
+			case "method", "path", "host", "client_ip":
+				if lit.Len() > 0 {
+					parts = append(parts, part{arg: lit.String()})
+					lit.Reset()
+				}
+				parts = append(parts, part{kind: kind, arg: arg})
+			default:
+				return nil, fmt.Errorf("未知的占位符 {%s}", tpl[i+1:i+end])
+			}
+			i += end
+		case c == '}':
+			return nil, fmt.Errorf("多余的 }")
+		default:
+			lit.WriteByte(c)
+		}
+	}
+	if lit.Len() > 0 || len(parts) == 0 {
+		parts = append(parts, part{arg: lit.String()})
+	}
+	return parts, nil
+}
+
+// apply 按顺序执行规则
+// orig: 改写前的请求头，用于 {header:Name} 占位符
+func apply(rules []compiledRule, header http.Header, ctx *ant.Context, orig http.Header) {
+	for _, r := range rules {
+		switch r.op {
+		case opAdd:
+			header.Add(r.name, render(r.parts, ctx, orig))
+		case opSet:
+			header.Set(r.name, render(r.parts, ctx, orig))
+		case opRemove:
+			header.Del(r.name)
+		case opRename:
+			values := header.Values(r.name)
+			if len(values) == 0 {
+				continue
+			}
+			values = append([]string(nil), values...)
+			header.Del(r.name)
+			header.Del(r.to)
+			for _, v := range values {
+				header.Add(r.to, v)
+			}
+		}
+	}
+}
+
+// render 渲染值模板
+func render(parts []part, ctx *ant.Context, orig http.Header) string {
+	if len(parts) == 1 && parts[0].kind == "" {
+		return parts[0].arg
+	}
+	var sb strings.Builder
+	for _, p := range parts {
+		switch p.kind {
+		case "":
+			sb.WriteString(p.arg)
+		case "param":
+			sb.WriteString(ctx.Req.PathValue(p.arg))
+		case "query":
+			sb.WriteString(ctx.Req.URL.Query().Get(p.arg))
+		case "header":
+			sb.WriteString(orig.Get(p.arg))
+		case "method":
+			sb.WriteString(ctx.Req.Method)
+		case "path":
+			sb.WriteString(ctx.Req.URL.Path)
+		case "host":
+			sb.WriteString(ctx.Req.Host)
+		case "client_ip":
+			sb.WriteString(ctx.ClientIP())
+		}
+	}
+	return sb.String()
+}
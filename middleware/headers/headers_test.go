@@ -0,0 +1,107 @@
+package headers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/justinwongcn/ant"
+)
+
+func TestRequestRules(t *testing.T) {
+	t.Setenv("ANT_TEST_REGION", "eu")
+	b := NewMiddlewareBuilder().Request(
+		Set("X-User-ID", "user-{param:id}"),
+		Add("X-Region", "{env:ANT_TEST_REGION}"),
+		Set("X-Trace", "{method} {path}?v={query:v} {{raw}} from {header:Authorization}"),
+		Rename("Authorization", "X-Legacy-Auth"),
+		Remove("Cookie"),
+	)
+	server := ant.NewHTTPServer()
+	server.Use(b.Build())
+	var got http.Header
+	server.Handle("GET /users/{id}", func(ctx *ant.Context) {
+		got = ctx.Req.Header.Clone()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42?v=2", nil)
+	req.Header.Set("Authorization", "Bearer t")
+	req.Header.Set("Cookie", "a=b")
+	server.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := map[string]string{
+		"X-User-Id":     "user-42",
+		"X-Region":      "eu",
+		"X-Trace":       "GET /users/42?v=2 {raw} from Bearer t",
+		"X-Legacy-Auth": "Bearer t",
+		"Authorization": "",
+		"Cookie":        "",
+	}
+	for name, v := range want {
+		if got.Get(name) != v {
+			t.Errorf("请求头 %s 应为 %q，实际为 %q", name, v, got.Get(name))
+		}
+	}
+}
+
+func TestResponseRules(t *testing.T) {
+	b := NewMiddlewareBuilder().Routes("GET /legacy").Response(
+		Rename("X-Request-Id", "X-Correlation-Id"),
+		Remove("Server"),
+		Set("X-Client", "{client_ip}"),
+	)
+	server := ant.NewHTTPServer()
+	server.Use(b.Build())
+	handler := func(ctx *ant.Context) {
+		ctx.Resp.Header().Set("X-Request-Id", "r1")
+		ctx.Resp.Header().Set("Server", "ant")
+		ctx.RespData = []byte("ok")
+	}
+	server.Handle("GET /legacy", handler)
+	server.Handle("GET /direct", handler)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/legacy", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	server.ServeHTTP(w, req)
+	if w.Header().Get("X-Correlation-Id") != "r1" || w.Header().Get("X-Request-Id") != "" {
+		t.Errorf("X-Request-Id 应重命名为 X-Correlation-Id，实际为 %v", w.Header())
+	}
+	if w.Header().Get("Server") != "" || w.Header().Get("X-Client") != "10.0.0.1" {
+		t.Errorf("响应头改写错误: %v", w.Header())
+	}
+
+	// 未列出的路由不改写
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/direct", nil))
+	if w.Header().Get("Server") != "ant" {
+		t.Errorf("未列出的路由不应改写，实际为 %v", w.Header())
+	}
+}
+
+func TestResponseRulesDirectWrite(t *testing.T) {
+	server := ant.NewHTTPServer()
+	server.Use(NewMiddlewareBuilder().Response(Remove("Server")).Build())
+	server.Handle("GET /stream", func(ctx *ant.Context) {
+		ctx.Resp.Header().Set("Server", "ant")
+		_, _ = ctx.Resp.Write([]byte("ok"))
+	})
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if w.Header().Get("Server") != "" {
+		t.Errorf("处理函数直接写入响应时也应改写，实际为 %v", w.Header())
+	}
+}
+
+func TestInvalidTemplate(t *testing.T) {
+	for _, tpl := range []string{"{unknown}", "{param}", "{method", "a}"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("模板 %q 应 panic", tpl)
+				}
+			}()
+			NewMiddlewareBuilder().Request(Set("X", tpl)).Build()
+		}()
+	}
+}
@@ -0,0 +1,164 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+// HeaderShadow 镜像请求带有的请求头，影子服务可以据此跳过有副作用的操作，例如发送邮件
+const HeaderShadow = "X-Shadow-Request"
+
+// ErrDropped 同时进行的镜像请求达到 MaxInFlight，镜像请求被丢弃
+var ErrDropped = errors.New("mirror: 镜像请求过多，已丢弃")
+
+// hopHeaders 逐跳的请求头，不转发给影子服务
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization",
+	"Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// MiddlewareBuilder 用于构建流量镜像中间件
+// 将按比例采样的请求异步复制一份发送给影子服务，忽略影子服务的响应
+// 常用于使用生产流量验证新版本的服务，镜像请求不会影响原请求的响应与延迟
+type MiddlewareBuilder struct {
+	upstream *url.URL
+	// Percent 镜像的请求比例，取值 0~100，默认为 100
+	Percent float64
+	// Client 发送镜像请求的客户端，默认为 http.DefaultClient
+	Client *http.Client
+	// Timeout 单个镜像请求的超时时间，默认为 5 秒
+	Timeout time.Duration
+	// MaxBodySize 镜像的请求体最大字节数，默认为 1MB，请求体更大的请求不镜像
+	MaxBodySize int64
+	// MaxInFlight 同时进行的镜像请求的最大数量，默认为 100，超出时丢弃镜像请求
+	MaxInFlight int
+	// OnError 镜像请求失败或被丢弃时调用，默认忽略
+	OnError func(req *http.Request, err error)
+
+	sem chan struct{}
+	// rand 返回 [0, 100) 之间的随机数，测试时可以替换
+	rand func() float64
+}
+
+// NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
+// upstream: 影子服务的地址，例如 "http://shadow.internal:8080"，请求的路径与查询参数会追加在其后
+func NewMiddlewareBuilder(upstream string) (*MiddlewareBuilder, error) {
+	u, err := url.Parse(upstream)
+	if err != nil {
+		return nil, err
+	}
+	return &MiddlewareBuilder{
+		upstream:    u,
+		Percent:     100,
+		Client:      http.DefaultClient,
+		Timeout:     5 * time.Second,
+		MaxBodySize: 1 << 20,
+		MaxInFlight: 100,
+		rand: func() float64 {
+			return rand.Float64() * 100
+		},
+	}, nil
+}
+
+// Build 构建流量镜像中间件
+// 注意：
+// 1. 需要镜像的请求体会先读入内存，再为原请求恢复
+// 2. 镜像请求带有 X-Shadow-Request: 1 请求头，并保留原请求的其他请求头（包括认证信息）
+// 3. 同时进行的镜像请求达到 MaxInFlight 时直接丢弃，保护原服务
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	b.sem = make(chan struct{}, b.MaxInFlight)
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			if b.Percent <= 0 || b.rand() >= b.Percent {
+				next(ctx)
+				return
+			}
+			shadow, err := b.shadowRequest(ctx.Req)
+			if err != nil {
+				b.reportError(ctx.Req, err)
+				next(ctx)
+				return
+			}
+			if shadow != nil {
+				b.send(shadow)
+			}
+			next(ctx)
+		}
+	}
+}
+
+// shadowRequest 复制请求，请求体超出 MaxBodySize 时返回nil
+func (b *MiddlewareBuilder) shadowRequest(req *http.Request) (*http.Request, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		buf, err := io.ReadAll(io.LimitReader(req.Body, b.MaxBodySize+1))
+		if err != nil {
+			return nil, err
+		}
+		// 恢复原请求的请求体，超出限制时拼接未读取的部分
+		req.Body = readCloser{io.MultiReader(bytes.NewReader(buf), req.Body), req.Body}
+		if int64(len(buf)) > b.MaxBodySize {
+			return nil, nil
+		}
+		body = buf
+	}
+
+	target := *b.upstream
+	target.Path = strings.TrimSuffix(target.Path, "/") + req.URL.Path
+	target.RawPath = ""
+	target.RawQuery = req.URL.RawQuery
+	shadow, err := http.NewRequest(req.Method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	shadow.Header = req.Header.Clone()
+	for _, h := range hopHeaders {
+		shadow.Header.Del(h)
+	}
+	shadow.Header.Set(HeaderShadow, "1")
+	shadow.Host = req.Host
+	return shadow, nil
+}
+
+// send 异步发送镜像请求并丢弃响应
+func (b *MiddlewareBuilder) send(shadow *http.Request) {
+	select {
+	case b.sem <- struct{}{}:
+	default:
+		b.reportError(shadow, ErrDropped)
+		return
+	}
+	go func() {
+		defer func() { <-b.sem }()
+		ctx, cancel := context.WithTimeout(context.Background(), b.Timeout)
+		defer cancel()
+		resp, err := b.Client.Do(shadow.WithContext(ctx))
+		if err != nil {
+			b.reportError(shadow, err)
+			return
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+}
+
+func (b *MiddlewareBuilder) reportError(req *http.Request, err error) {
+	if b.OnError != nil {
+		b.OnError(req, err)
+	}
+}
+
+// readCloser 读取拼接后的请求体，关闭时关闭原请求体
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
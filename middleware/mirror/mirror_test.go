@@ -0,0 +1,137 @@
+package mirror
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+// shadowRecorder 记录收到的镜像请求的影子服务
+type shadowRecorder struct {
+	mu   sync.Mutex
+	reqs []string
+	got  chan struct{}
+}
+
+func newShadow(t *testing.T, handler func(w http.ResponseWriter)) (*httptest.Server, *shadowRecorder) {
+	rec := &shadowRecorder{got: make(chan struct{}, 16)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec.mu.Lock()
+		rec.reqs = append(rec.reqs, r.Method+" "+r.URL.RequestURI()+" "+string(body)+" "+r.Header.Get(HeaderShadow))
+		rec.mu.Unlock()
+		handler(w)
+		rec.got <- struct{}{}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, rec
+}
+
+func newServer(b *MiddlewareBuilder) *ant.HTTPServer {
+	server := ant.NewHTTPServer()
+	server.Use(b.Build())
+	server.Handle("POST /orders", func(ctx *ant.Context) {
+		data, _ := io.ReadAll(ctx.Req.Body)
+		ctx.RespData = data
+	})
+	return server
+}
+
+func TestMirror(t *testing.T) {
+	shadow, rec := newShadow(t, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	b, err := NewMiddlewareBuilder(shadow.URL + "/v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newServer(b)
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders?id=1", strings.NewReader("apple")))
+	// 影子服务的错误不影响原请求
+	if w.Code != http.StatusOK || w.Body.String() != "apple" {
+		t.Errorf("原请求应正常处理，实际为 %d %q", w.Code, w.Body.String())
+	}
+
+	select {
+	case <-rec.got:
+	case <-time.After(time.Second):
+		t.Fatal("影子服务没有收到镜像请求")
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if want := "POST /v2/orders?id=1 apple 1"; rec.reqs[0] != want {
+		t.Errorf("镜像请求应为 %q，实际为 %q", want, rec.reqs[0])
+	}
+}
+
+func TestMirrorSampling(t *testing.T) {
+	shadow, rec := newShadow(t, func(w http.ResponseWriter) {})
+	b, _ := NewMiddlewareBuilder(shadow.URL)
+	b.Percent = 10
+	var n int
+	b.rand = func() float64 {
+		n++
+		return float64(n*7%100) + 0.5
+	}
+	server := newServer(b)
+
+	for i := 0; i < 100; i++ {
+		server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+	}
+	for i := 0; i < 10; i++ {
+		select {
+		case <-rec.got:
+		case <-time.After(time.Second):
+			t.Fatalf("应镜像 10 个请求，只收到 %d 个", i)
+		}
+	}
+	select {
+	case <-rec.got:
+		t.Error("镜像的请求超过了采样比例")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMirrorLimits(t *testing.T) {
+	release := make(chan struct{})
+	shadow, _ := newShadow(t, func(w http.ResponseWriter) {
+		<-release
+	})
+	defer close(release)
+	b, _ := NewMiddlewareBuilder(shadow.URL)
+	b.MaxInFlight = 1
+	b.MaxBodySize = 4
+	var mu sync.Mutex
+	var errs []error
+	b.OnError = func(_ *http.Request, err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+	server := newServer(b)
+
+	// 请求体超出限制时不镜像，原请求仍能读取完整的请求体
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("too large")))
+	if w.Body.String() != "too large" {
+		t.Errorf("原请求体应完整，实际为 %q", w.Body.String())
+	}
+
+	// 第一个镜像请求阻塞在影子服务中，第二个被丢弃
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 1 || !errors.Is(errs[0], ErrDropped) {
+		t.Errorf("应丢弃一个镜像请求，实际错误为 %v", errs)
+	}
+}
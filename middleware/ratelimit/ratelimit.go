@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+// Quota 限流配额
+type Quota struct {
+	// Limit 窗口内允许的请求数
+	Limit int
+	// Window 窗口长度
+	Window time.Duration
+}
+
+// Identity 限流的对象
+type Identity struct {
+	// Key 限流的键，例如用户ID、API Key 或客户端IP
+	Key string
+	// Plan 套餐名称，用于选择配额，为空时使用默认配额
+	Plan string
+}
+
+// KeyFunc 从请求中获取限流的对象
+type KeyFunc func(ctx *ant.Context) Identity
+
+// ByIP 按客户端IP限流，默认的 KeyFunc
+func ByIP() KeyFunc {
+	return func(ctx *ant.Context) Identity {
+		return Identity{Key: "ip:" + ctx.ClientIP()}
+	}
+}
+
+// ByHeader 按请求头的值限流，例如 API Key 所在的 X-API-Key
+// 请求头为空时按客户端IP限流
+// 注意：请求头的值由客户端任意填写，只应在鉴权之后使用，否则客户端可以通过更换值绕过限流
+func ByHeader(name string) KeyFunc {
+	return func(ctx *ant.Context) Identity {
+		v := ctx.Req.Header.Get(name)
+		if v == "" {
+			return ByIP()(ctx)
+		}
+		return Identity{Key: "header:" + v}
+	}
+}
+
+// ByPrincipal 按鉴权中间件识别出的用户限流
+// 鉴权中间件需要将用户标识与套餐名称写入 ctx.UserValues，例如：
+//
+//	ctx.UserValues["user_id"] = claims.Subject
+//	ctx.UserValues["plan"] = claims.Plan
+//
+// principalKey: 用户标识在 UserValues 中的键，值需要是 string
+// planKey: 套餐名称在 UserValues 中的键，为空时不区分套餐
+// 未鉴权的请求按客户端IP限流
+func ByPrincipal(principalKey, planKey string) KeyFunc {
+	return func(ctx *ant.Context) Identity {
+		id, _ := ctx.UserValues[principalKey].(string)
+		if id == "" {
+			return ByIP()(ctx)
+		}
+		plan, _ := ctx.UserValues[planKey].(string)
+		return Identity{Key: "user:" + id, Plan: plan}
+	}
+}
+
+// MiddlewareBuilder 用于构建限流中间件
+// 使用固定窗口计数，通过 X-RateLimit-Limit、X-RateLimit-Remaining 与 X-RateLimit-Reset 响应头告知客户端配额
+type MiddlewareBuilder struct {
+	quota   Quota
+	plans   map[string]Quota
+	keyFunc KeyFunc
+	store   Store
+	errFunc func(err error)
+}
+
+// NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
+// quota: 默认配额，用于未指定套餐或套餐未配置的请求
+func NewMiddlewareBuilder(quota Quota) *MiddlewareBuilder {
+	return &MiddlewareBuilder{
+		quota:   quota,
+		plans:   make(map[string]Quota),
+		keyFunc: ByIP(),
+		store:   NewMemoryStore(),
+		errFunc: func(err error) {
+			log.Printf("限流计数失败: %v", err)
+		},
+	}
+}
+
+// KeyFunc 设置获取限流对象的函数，默认按客户端IP限流
+func (b *MiddlewareBuilder) KeyFunc(fn KeyFunc) *MiddlewareBuilder {
+	b.keyFunc = fn
+	return b
+}
+
+// Plan 设置套餐的配额，例如 free 每分钟 60 次，pro 每分钟 600 次
+// Limit 为负数时表示不限流
+func (b *MiddlewareBuilder) Plan(name string, quota Quota) *MiddlewareBuilder {
+	b.plans[name] = quota
+	return b
+}
+
+// Store 设置计数存储，默认使用 MemoryStore
+func (b *MiddlewareBuilder) Store(store Store) *MiddlewareBuilder {
+	b.store = store
+	return b
+}
+
+// ErrFunc 设置计数存储出错时的回调，出错时放行请求
+func (b *MiddlewareBuilder) ErrFunc(fn func(err error)) *MiddlewareBuilder {
+	b.errFunc = fn
+	return b
+}
+
+// Build 构建限流中间件
+// 注意：
+// 1. 需要放在鉴权中间件之内（先注册鉴权中间件），才能读取到鉴权的结果
+// 2. 超出配额时响应 429，并通过 Retry-After 告知客户端重试的时间
+// 3. 计数存储出错时放行请求，避免存储故障导致服务不可用
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			id := b.keyFunc(ctx)
+			quota, ok := b.plans[id.Plan]
+			if !ok {
+				quota = b.quota
+			}
+			if quota.Limit < 0 {
+				next(ctx)
+				return
+			}
+
+			count, reset, err := b.store.Incr(ctx.Req.Context(), id.Key, quota.Window)
+			if err != nil {
+				b.errFunc(err)
+				next(ctx)
+				return
+			}
+
+			resetIn := int(math.Ceil(time.Until(reset).Seconds()))
+			header := ctx.Resp.Header()
+			header.Set("X-RateLimit-Limit", strconv.Itoa(quota.Limit))
+			header.Set("X-RateLimit-Remaining", strconv.Itoa(max(quota.Limit-count, 0)))
+			header.Set("X-RateLimit-Reset", strconv.Itoa(resetIn))
+			if count > quota.Limit {
+				header.Set("Retry-After", strconv.Itoa(resetIn))
+				ctx.RespStatusCode = http.StatusTooManyRequests
+				ctx.RespData = []byte(http.StatusText(http.StatusTooManyRequests))
+				return
+			}
+			next(ctx)
+		}
+	}
+}
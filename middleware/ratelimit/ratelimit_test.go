@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+// auth 模拟鉴权中间件，从请求头读取用户与套餐
+func auth(next ant.HandleFunc) ant.HandleFunc {
+	return func(ctx *ant.Context) {
+		if ctx.UserValues == nil {
+			ctx.UserValues = make(map[string]any)
+		}
+		if user := ctx.Req.Header.Get("X-User"); user != "" {
+			ctx.UserValues["user_id"] = user
+			ctx.UserValues["plan"] = ctx.Req.Header.Get("X-Plan")
+		}
+		next(ctx)
+	}
+}
+
+func newServer(b *MiddlewareBuilder) *ant.HTTPServer {
+	server := ant.NewHTTPServer()
+	server.Use(auth, b.Build())
+	server.Handle("GET /api", func(ctx *ant.Context) {
+		ctx.RespData = []byte("ok")
+	})
+	return server
+}
+
+func call(server *ant.HTTPServer, user, plan, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api", nil)
+	req.RemoteAddr = ip + ":1234"
+	if user != "" {
+		req.Header.Set("X-User", user)
+		req.Header.Set("X-Plan", plan)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitByPrincipal(t *testing.T) {
+	b := NewMiddlewareBuilder(Quota{Limit: 1, Window: time.Minute}).
+		KeyFunc(ByPrincipal("user_id", "plan")).
+		Plan("pro", Quota{Limit: 3, Window: time.Minute}).
+		Plan("internal", Quota{Limit: -1})
+	server := newServer(b)
+
+	// 同一用户从不同IP访问共享配额
+	w := call(server, "alice", "", "10.0.0.1")
+	if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "1" || w.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("第一次请求应放行并报告剩余 0 次，实际为 %d %v", w.Code, w.Header())
+	}
+	w = call(server, "alice", "", "10.0.0.2")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("超出配额应返回 429 与 Retry-After，实际为 %d %v", w.Code, w.Header())
+	}
+
+	// 不同套餐使用不同配额
+	for i := 0; i < 3; i++ {
+		if w = call(server, "bob", "pro", "10.0.0.1"); w.Code != http.StatusOK {
+			t.Fatalf("pro 套餐的第 %d 次请求应放行", i+1)
+		}
+	}
+	if w = call(server, "bob", "pro", "10.0.0.1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("pro 套餐的第 4 次请求应被限流，实际为 %d", w.Code)
+	}
+
+	// 不限流的套餐不设置响应头
+	for i := 0; i < 5; i++ {
+		w = call(server, "svc", "internal", "10.0.0.1")
+	}
+	if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "" {
+		t.Errorf("不限流的套餐应放行且不设置响应头，实际为 %d %v", w.Code, w.Header())
+	}
+
+	// 未鉴权的请求按IP限流
+	if w = call(server, "", "", "10.0.0.9"); w.Code != http.StatusOK {
+		t.Errorf("未鉴权的第一次请求应放行，实际为 %d", w.Code)
+	}
+	if w = call(server, "", "", "10.0.0.9"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("未鉴权的请求应按IP限流，实际为 %d", w.Code)
+	}
+}
+
+// failingStore 总是返回错误的计数存储
+type failingStore struct{}
+
+func (failingStore) Incr(context.Context, string, time.Duration) (int, time.Time, error) {
+	return 0, time.Time{}, errors.New("store down")
+}
+
+func TestRateLimitStoreError(t *testing.T) {
+	var got error
+	b := NewMiddlewareBuilder(Quota{Limit: 1, Window: time.Minute}).
+		KeyFunc(ByHeader("X-API-Key")).
+		Store(failingStore{}).
+		ErrFunc(func(err error) { got = err })
+	server := newServer(b)
+	for i := 0; i < 3; i++ {
+		if w := call(server, "", "", "10.0.0.1"); w.Code != http.StatusOK {
+			t.Fatalf("存储出错时应放行，实际为 %d", w.Code)
+		}
+	}
+	if got == nil {
+		t.Error("存储出错时应调用 ErrFunc")
+	}
+}
+
+func TestMemoryStoreWindow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 1; i <= 2; i++ {
+		count, reset, _ := s.Incr(ctx, "k", time.Minute)
+		if count != i || !reset.Equal(now.Add(time.Minute)) {
+			t.Errorf("第 %d 次计数错误: %d %v", i, count, reset)
+		}
+	}
+	now = now.Add(time.Minute)
+	if count, _, _ := s.Incr(ctx, "other", time.Minute); count != 1 {
+		t.Errorf("新键的计数应为 1，实际为 %d", count)
+	}
+	if _, ok := s.windows["k"]; ok {
+		t.Error("过期的计数应被清理")
+	}
+	if count, _, _ := s.Incr(ctx, "k", time.Minute); count != 1 {
+		t.Errorf("新窗口的计数应重新开始，实际为 %d", count)
+	}
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store 限流计数的存储，多实例部署时可以使用 Redis 等共享存储实现
+type Store interface {
+	// Incr 将键在当前窗口内的计数加一
+	// 返回值: 加一后的计数，以及当前窗口的结束时间
+	Incr(ctx context.Context, key string, window time.Duration) (count int, reset time.Time, err error)
+}
+
+// MemoryStore 基于内存的固定窗口计数
+type MemoryStore struct {
+	mu      sync.Mutex
+	windows map[string]*counter
+	// lastSweep 上一次清理过期计数的时间
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// counter 单个键在当前窗口内的计数
+type counter struct {
+	count int
+	reset time.Time
+}
+
+// NewMemoryStore 创建基于内存的计数存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		windows: make(map[string]*counter),
+		now:     time.Now,
+	}
+}
+
+// Incr 实现 Store 接口
+func (s *MemoryStore) Incr(_ context.Context, key string, window time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweep(now, window)
+	c, ok := s.windows[key]
+	if !ok || !now.Before(c.reset) {
+		c = &counter{reset: now.Add(window)}
+		s.windows[key] = c
+	}
+	c.count++
+	return c.count, c.reset, nil
+}
+
+// sweep 每隔一个窗口清理一次过期的计数，避免键的数量无限增长
+func (s *MemoryStore) sweep(now time.Time, window time.Duration) {
+	if now.Sub(s.lastSweep) < window {
+		return
+	}
+	s.lastSweep = now
+	for key, c := range s.windows {
+		if !now.Before(c.reset) {
+			delete(s.windows, key)
+		}
+	}
+}
@@ -1,6 +1,14 @@
 package recovery
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"time"
+
 	"github.com/justinwongcn/ant"
 )
 
@@ -12,24 +20,36 @@ type MiddlewareBuilder struct {
 	ErrMsg string
 	// LogFunc 用于记录panic信息的日志函数
 	LogFunc func(ctx *ant.Context)
+	// Reporter 发生panic时用于上报错误的实现
+	Reporter ant.ErrorReporter
+	// IdentityFunc 用于在上报时解析当前用户与会话ID，可以为nil
+	IdentityFunc ant.IdentityFunc
+	// BodyPrefixSize panic报告中保留的请求体前缀字节数，为0时不记录请求体
+	BodyPrefixSize int
+	// SensitiveHeaders panic报告中需要脱敏的请求头
+	SensitiveHeaders []string
 }
 
 // NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
 // 默认使用500状态码和通用错误消息
 func NewMiddlewareBuilder() *MiddlewareBuilder {
 	return &MiddlewareBuilder{
-		StatusCode: 500,
-		ErrMsg:     "Internal Server Error",
-		LogFunc:    func(ctx *ant.Context) {},
+		StatusCode:       500,
+		ErrMsg:           "Internal Server Error",
+		LogFunc:          func(ctx *ant.Context) {},
+		Reporter:         ant.NopErrorReporter{},
+		BodyPrefixSize:   1024,
+		SensitiveHeaders: slices.Clone(ant.DefaultSensitiveHeaders),
 	}
 }
 
 // Build 构建panic恢复中间件
 // 该中间件会捕获处理器中的panic，设置自定义的响应状态码和错误信息，
-// 并通过用户定义的日志函数记录错误信息
+// 通过用户定义的日志函数记录错误信息，并将结构化的panic报告上报给 Reporter
 func (m *MiddlewareBuilder) Build() ant.Middleware {
 	return func(next ant.HandleFunc) ant.HandleFunc {
 		return func(ctx *ant.Context) {
+			body := m.recordBody(ctx.Req)
 			defer func() {
 				if err := recover(); err != nil {
 					// 设置响应状态码和错误信息
@@ -38,9 +58,86 @@ func (m *MiddlewareBuilder) Build() ant.Middleware {
 					ctx.RespData = []byte(m.ErrMsg)
 					// 调用日志函数记录错误信息
 					m.LogFunc(ctx)
+					m.report(ctx, err, body)
 				}
 			}()
 			next(ctx)
 		}
 	}
 }
+
+// recordBody 包装请求体，记录处理器已读取内容的前缀
+func (m *MiddlewareBuilder) recordBody(req *http.Request) *prefixRecorder {
+	if m.BodyPrefixSize <= 0 || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	rec := &prefixRecorder{ReadCloser: req.Body, limit: m.BodyPrefixSize}
+	req.Body = rec
+	return rec
+}
+
+// report 构建panic报告并上报给 Reporter
+func (m *MiddlewareBuilder) report(ctx *ant.Context, val any, body *prefixRecorder) {
+	if m.Reporter == nil {
+		return
+	}
+	err, ok := val.(error)
+	if !ok {
+		err = fmt.Errorf("panic: %v", val)
+	}
+
+	stack := debug.Stack()
+	ev := ant.NewErrorEvent(ctx, err)
+	// 上报的事件与panic报告使用同一份脱敏规则
+	ev.Header = ant.RedactHeader(ctx.Req.Header, m.SensitiveHeaders)
+	ev.Stack = stack
+	ev.Tags["source"] = "recovery"
+	ev.Panic = m.newPanicReport(ctx, val, stack, body)
+	if m.IdentityFunc != nil {
+		ev.User, ev.SessionID = m.IdentityFunc(ctx)
+	}
+	m.Reporter.Report(ctx.Req.Context(), ev)
+}
+
+// newPanicReport 采集panic发生时的请求与运行时快照
+func (m *MiddlewareBuilder) newPanicReport(ctx *ant.Context, val any, stack []byte, body *prefixRecorder) *ant.PanicReport {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	report := &ant.PanicReport{
+		Value:      val,
+		Message:    fmt.Sprint(val),
+		Route:      ctx.Req.Pattern,
+		Method:     ctx.Req.Method,
+		Path:       ctx.Req.URL.Path,
+		Params:     ctx.PathParams(),
+		Header:     ant.RedactHeader(ctx.Req.Header, m.SensitiveHeaders),
+		Stack:      string(stack),
+		Goroutines: runtime.NumGoroutine(),
+		HeapAlloc:  mem.HeapAlloc,
+		HeapInuse:  mem.HeapInuse,
+		Sys:        mem.Sys,
+		NumGC:      mem.NumGC,
+		Time:       time.Now(),
+	}
+	if body != nil {
+		report.BodyPrefix = body.prefix
+	}
+	return report
+}
+
+// prefixRecorder 记录已读取请求体前缀的读取器
+type prefixRecorder struct {
+	io.ReadCloser
+	prefix []byte
+	limit  int
+}
+
+// Read 读取请求体并记录前 limit 个字节
+func (p *prefixRecorder) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	if remain := p.limit - len(p.prefix); remain > 0 && n > 0 {
+		p.prefix = append(p.prefix, b[:min(n, remain)]...)
+	}
+	return n, err
+}
@@ -1,8 +1,12 @@
 package recovery
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/justinwongcn/ant"
@@ -107,3 +111,121 @@ func TestRecoveryMiddlewareCustomization(t *testing.T) {
 		t.Error("日志函数未被调用")
 	}
 }
+
+func TestRecoveryMiddlewareReporter(t *testing.T) {
+	var reported *ant.ErrorEvent
+	mb := NewMiddlewareBuilder()
+	mb.Reporter = ant.ErrorReporterFunc(func(_ context.Context, ev *ant.ErrorEvent) {
+		reported = ev
+	})
+	mb.IdentityFunc = func(ctx *ant.Context) (string, string) {
+		return "u-1", "sess-1"
+	}
+
+	handler := mb.Build()(func(ctx *ant.Context) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders?id=1", nil)
+	handler(&ant.Context{Req: req, Resp: httptest.NewRecorder()})
+
+	if reported == nil {
+		t.Fatal("期望panic被上报")
+	}
+	if reported.Err.Error() != "panic: boom" {
+		t.Errorf("期望错误信息 panic: boom, 实际获得 %v", reported.Err)
+	}
+	if reported.URL != "/orders?id=1" || reported.Method != http.MethodGet {
+		t.Errorf("请求信息不正确: %s %s", reported.Method, reported.URL)
+	}
+	if len(reported.Stack) == 0 {
+		t.Error("期望上报调用栈")
+	}
+	if reported.User != "u-1" || reported.SessionID != "sess-1" {
+		t.Errorf("用户信息不正确: %s %s", reported.User, reported.SessionID)
+	}
+}
+
+func TestRecoveryMiddlewarePanicReport(t *testing.T) {
+	var reported *ant.ErrorEvent
+	mb := NewMiddlewareBuilder()
+	mb.BodyPrefixSize = 8
+	mb.Reporter = ant.ErrorReporterFunc(func(_ context.Context, ev *ant.ErrorEvent) {
+		reported = ev
+	})
+
+	server := ant.NewHTTPServer()
+	server.Use(mb.Build())
+	server.Handle("POST /users/{id}/files/{path...}", func(ctx *ant.Context) {
+		buf := make([]byte, 4)
+		_, _ = io.ReadFull(ctx.Req.Body, buf)
+		panic(errors.New("boom"))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users/42/files/a/b.txt", strings.NewReader(`{"name":"tom"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Request-Id", "req-1")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("期望状态码 500, 实际获得 %d", rec.Code)
+	}
+	if reported == nil || reported.Panic == nil {
+		t.Fatal("期望上报panic报告")
+	}
+
+	report := reported.Panic
+	if report.Route != "POST /users/{id}/files/{path...}" {
+		t.Errorf("路由不正确: %s", report.Route)
+	}
+	if report.Params["id"] != "42" || report.Params["path"] != "a/b.txt" {
+		t.Errorf("路径参数不正确: %v", report.Params)
+	}
+	if string(report.BodyPrefix) != `{"na` {
+		t.Errorf("请求体前缀不正确: %q", report.BodyPrefix)
+	}
+	if report.Header["Authorization"][0] != ant.Redacted || report.Header["X-Request-Id"][0] != "req-1" {
+		t.Errorf("请求头脱敏不正确: %v", report.Header)
+	}
+	if report.Message != "boom" || report.Goroutines <= 0 || report.HeapAlloc == 0 || report.Stack == "" {
+		t.Errorf("运行时快照不完整: %+v", report)
+	}
+	if reported.Err.Error() != "boom" {
+		t.Errorf("期望保留原始错误, 实际获得 %v", reported.Err)
+	}
+}
+
+// TestRecoveryReportRedactsEventHeader 测试上报的错误事件同样使用 SensitiveHeaders 脱敏
+func TestRecoveryReportRedactsEventHeader(t *testing.T) {
+	var reported *ant.ErrorEvent
+	mb := NewMiddlewareBuilder()
+	mb.SensitiveHeaders = append(mb.SensitiveHeaders, "X-Api-Key")
+	mb.Reporter = ant.ErrorReporterFunc(func(_ context.Context, ev *ant.ErrorEvent) {
+		reported = ev
+	})
+
+	server := ant.NewHTTPServer()
+	server.Use(mb.Build())
+	server.Handle("GET /panic", func(ctx *ant.Context) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Api-Key", "key")
+	req.Header.Set("X-Request-Id", "req-1")
+	server.ServeHTTP(httptest.NewRecorder(), req)
+
+	if reported == nil {
+		t.Fatal("期望上报错误事件")
+	}
+	for _, header := range []http.Header{reported.Header, reported.Panic.Header} {
+		if header.Get("Authorization") != ant.Redacted || header.Get("X-Api-Key") != ant.Redacted {
+			t.Errorf("敏感请求头没有脱敏: %v", header)
+		}
+		if header.Get("X-Request-Id") != "req-1" {
+			t.Errorf("普通请求头不应脱敏: %v", header)
+		}
+	}
+}
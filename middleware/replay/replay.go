@@ -0,0 +1,281 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math/rand/v2"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+// Redacted 被脱敏的请求头或请求体字段的替换值
+const Redacted = "[REDACTED]"
+
+// Entry 一条录制的请求
+type Entry struct {
+	// Time 收到请求的时间，回放时据此还原请求之间的间隔
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	// URI 请求路径与查询参数，例如 "/users?page=2"，需要脱敏的查询参数已脱敏
+	URI    string      `json:"uri"`
+	Host   string      `json:"host,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	// Body 请求体，超出 MaxBodySize 的请求不录制
+	// JSON、表单与 multipart 请求体中需要脱敏的字段已脱敏，其他类型的请求体原样保留
+	Body []byte `json:"body,omitempty"`
+	// Status 录制时的响应状态码，回放时用于对比
+	Status   int           `json:"status"`
+	Duration time.Duration `json:"duration"`
+}
+
+// MiddlewareBuilder 流量录制中间件构建器
+// 将按比例采样的请求脱敏后写入 Sink，配合 Replayer 回放用于回归测试与压测
+type MiddlewareBuilder struct {
+	sink         Sink
+	percent      float64
+	maxBodySize  int64
+	redactFields map[string]struct{}
+	errFunc      func(err error)
+	// rand 返回 [0, 100) 之间的随机数，测试时可以替换
+	rand func() float64
+}
+
+// NewMiddlewareBuilder 创建流量录制中间件构建器
+// sink: 录制的请求写入的位置，例如 NewJSONLinesSink 或消息队列
+// 默认录制全部请求，并脱敏 Authorization、Proxy-Authorization 与 Cookie 请求头
+func NewMiddlewareBuilder(sink Sink) *MiddlewareBuilder {
+	b := &MiddlewareBuilder{
+		sink:         sink,
+		percent:      100,
+		maxBodySize:  64 << 10,
+		redactFields: make(map[string]struct{}),
+		errFunc: func(err error) {
+			log.Printf("写入录制的请求失败: %v", err)
+		},
+		rand: func() float64 {
+			return rand.Float64() * 100
+		},
+	}
+	return b.Redact("Authorization", "Proxy-Authorization", "Cookie")
+}
+
+// Percent 设置录制的请求比例，取值 0~100
+func (b *MiddlewareBuilder) Percent(percent float64) *MiddlewareBuilder {
+	b.percent = percent
+	return b
+}
+
+// MaxBodySize 设置录制的请求体最大字节数，默认为 64KB，请求体更大的请求不录制
+func (b *MiddlewareBuilder) MaxBodySize(size int64) *MiddlewareBuilder {
+	b.maxBodySize = size
+	return b
+}
+
+// Redact 设置需要脱敏的名称，不区分大小写
+// 作用于请求头、查询参数、JSON请求体的顶层字段，以及 urlencoded 与 multipart 表单的字段
+// 注意：其他类型的请求体无法解析，会原样录制；声明为上述类型却无法解析的请求体不录制
+func (b *MiddlewareBuilder) Redact(fields ...string) *MiddlewareBuilder {
+	for _, f := range fields {
+		b.redactFields[strings.ToLower(f)] = struct{}{}
+	}
+	return b
+}
+
+// ErrFunc 设置写入失败时的处理函数，默认输出日志
+func (b *MiddlewareBuilder) ErrFunc(fn func(err error)) *MiddlewareBuilder {
+	b.errFunc = fn
+	return b
+}
+
+// Build 构建流量录制中间件
+// 注意：
+// 1. 需要录制的请求体会先读入内存，再为原请求恢复
+// 2. 请求处理完成后同步写入 Sink，写入较慢的 Sink 应自行缓冲
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			if b.percent <= 0 || b.rand() >= b.percent {
+				next(ctx)
+				return
+			}
+			body, ok := b.captureBody(ctx.Req)
+			if !ok {
+				next(ctx)
+				return
+			}
+			entry := Entry{
+				Time:   time.Now(),
+				Method: ctx.Req.Method,
+				URI:    b.redactURI(ctx.Req.URL),
+				Host:   ctx.Req.Host,
+				Header: b.redactHeader(ctx.Req.Header),
+				Body:   b.redactBody(ctx.Req.Header.Get("Content-Type"), body),
+			}
+			next(ctx)
+			entry.Duration = time.Since(entry.Time)
+			entry.Status = ctx.ResponseStatus()
+			if err := b.sink.Write(ctx.Req.Context(), entry); err != nil && b.errFunc != nil {
+				b.errFunc(err)
+			}
+		}
+	}
+}
+
+// captureBody 读取并恢复请求体，请求体超出 MaxBodySize 时返回false
+func (b *MiddlewareBuilder) captureBody(req *http.Request) ([]byte, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, true
+	}
+	buf, err := io.ReadAll(io.LimitReader(req.Body, b.maxBodySize+1))
+	// 恢复原请求的请求体，超出限制时拼接未读取的部分
+	req.Body = readCloser{io.MultiReader(bytes.NewReader(buf), req.Body), req.Body}
+	if err != nil || int64(len(buf)) > b.maxBodySize {
+		return nil, false
+	}
+	return buf, true
+}
+
+// redactHeader 复制请求头并脱敏
+func (b *MiddlewareBuilder) redactHeader(header http.Header) http.Header {
+	res := header.Clone()
+	for name := range res {
+		if _, ok := b.redactFields[strings.ToLower(name)]; ok {
+			res[name] = []string{Redacted}
+		}
+	}
+	return res
+}
+
+// redactURI 返回请求路径与查询参数，并脱敏查询参数
+func (b *MiddlewareBuilder) redactURI(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.RequestURI()
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		// 无法解析的查询参数可能包含敏感信息，不录制
+		return u.EscapedPath()
+	}
+	if !b.redactValues(query) {
+		return u.RequestURI()
+	}
+	return u.EscapedPath() + "?" + query.Encode()
+}
+
+// redactValues 脱敏表单或查询参数中的字段，返回是否有字段被脱敏
+func (b *MiddlewareBuilder) redactValues(values url.Values) bool {
+	changed := false
+	for name := range values {
+		if _, ok := b.redactFields[strings.ToLower(name)]; ok {
+			values[name] = []string{Redacted}
+			changed = true
+		}
+	}
+	return changed
+}
+
+// redactBody 根据 Content-Type 脱敏请求体
+// JSON 脱敏顶层字段，urlencoded 与 multipart 表单脱敏字段，其他请求体原样保留
+// 声明为这些类型却无法解析的请求体返回nil
+func (b *MiddlewareBuilder) redactBody(contentType string, body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil
+		}
+		if !b.redactValues(values) {
+			return body
+		}
+		return []byte(values.Encode())
+	case mediaType == "multipart/form-data":
+		return b.redactMultipart(params["boundary"], body)
+	case strings.Contains(mediaType, "json"):
+		return b.redactJSON(body)
+	default:
+		return body
+	}
+}
+
+// redactJSON 脱敏JSON请求体的顶层字段，顶层不是对象时原样保留
+func (b *MiddlewareBuilder) redactJSON(body []byte) []byte {
+	if !json.Valid(body) {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	changed := false
+	for name := range fields {
+		if _, ok := b.redactFields[strings.ToLower(name)]; ok {
+			fields[name] = json.RawMessage(`"` + Redacted + `"`)
+			changed = true
+		}
+	}
+	if !changed {
+		return body
+	}
+	res, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+	return res
+}
+
+// redactMultipart 使用相同的分隔符重新编码 multipart 请求体，脱敏非文件字段的值
+func (b *MiddlewareBuilder) redactMultipart(boundary string, body []byte) []byte {
+	if boundary == "" {
+		return nil
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return nil
+	}
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil
+		}
+		dst, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return nil
+		}
+		_, redact := b.redactFields[strings.ToLower(part.FormName())]
+		if redact && part.FileName() == "" {
+			_, err = io.WriteString(dst, Redacted)
+		} else {
+			_, err = io.Copy(dst, part)
+		}
+		if err != nil {
+			return nil
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// readCloser 读取拼接后的请求体，关闭时关闭原请求体
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,204 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	var buf bytes.Buffer
+	b := NewMiddlewareBuilder(NewJSONLinesSink(&buf)).Redact("password").MaxBodySize(64)
+	server := ant.NewHTTPServer()
+	server.Use(b.Build())
+	server.Handle("POST /login", func(ctx *ant.Context) {
+		body, _ := io.ReadAll(ctx.Req.Body)
+		if !strings.Contains(string(body), "secret") {
+			t.Errorf("处理函数应读取到完整的原请求体，实际为 %s", body)
+		}
+		ctx.RespStatusCode = http.StatusCreated
+	})
+	server.Handle("GET /users", func(ctx *ant.Context) {
+		ctx.RespData = []byte("ok")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"name":"tom","password":"secret"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer prod")
+	server.ServeHTTP(httptest.NewRecorder(), req)
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users?page=2", nil))
+	// 请求体超出 MaxBodySize 的请求不录制
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(strings.Repeat("secret", 20))))
+
+	var entries []Entry
+	if err := ReadEntries(bytes.NewReader(buf.Bytes()), func(e Entry) error {
+		entries = append(entries, e)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("应录制 2 个请求，实际为 %d", len(entries))
+	}
+	login := entries[0]
+	if login.Status != http.StatusCreated || login.Header.Get("Authorization") != Redacted ||
+		string(login.Body) != `{"name":"tom","password":"[REDACTED]"}` {
+		t.Errorf("录制的请求不正确: %+v %s", login, login.Body)
+	}
+	if entries[1].URI != "/users?page=2" || entries[1].Status != http.StatusOK {
+		t.Errorf("录制的请求不正确: %+v", entries[1])
+	}
+
+	var (
+		mu       sync.Mutex
+		received []*http.Request
+	)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received = append(received, r)
+		mu.Unlock()
+		if r.URL.Path == "/users" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer target.Close()
+
+	r := &Replayer{Target: target.URL, Header: http.Header{"Authorization": {"Bearer test"}}}
+	stats, err := r.Replay(context.Background(), bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Sent != 2 || stats.Failed != 0 || stats.StatusMismatch != 2 {
+		t.Errorf("统计结果不正确: %+v", stats)
+	}
+	for _, r := range received {
+		if r.Header.Get("Authorization") != "Bearer test" || r.Header.Get(HeaderReplay) != "1" {
+			t.Errorf("回放的请求头不正确: %v", r.Header)
+		}
+	}
+}
+
+func TestRecordPercent(t *testing.T) {
+	var n int
+	b := NewMiddlewareBuilder(SinkFunc(func(ctx context.Context, e Entry) error {
+		n++
+		return nil
+	})).Percent(30)
+	b.rand = func() float64 { return 50 }
+	server := ant.NewHTTPServer()
+	server.Use(b.Build())
+	server.Handle("GET /", func(ctx *ant.Context) {})
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if n != 0 {
+		t.Error("未被采样的请求不应录制")
+	}
+	b.rand = func() float64 { return 10 }
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if n != 1 {
+		t.Error("被采样的请求应录制")
+	}
+}
+
+func TestRecordRedactQueryAndForm(t *testing.T) {
+	var entries []Entry
+	b := NewMiddlewareBuilder(SinkFunc(func(ctx context.Context, e Entry) error {
+		entries = append(entries, e)
+		return nil
+	})).Redact("password", "token", "api_key")
+	server := ant.NewHTTPServer()
+	server.Use(b.Build())
+	server.Handle("POST /login", func(ctx *ant.Context) {
+		// 带有查询参数的请求使用表单提交
+		if ctx.Req.URL.RawQuery != "" && ctx.Req.FormValue("password") != "secret" {
+			t.Errorf("处理函数应读取到原始的表单值，实际为 %q", ctx.Req.FormValue("password"))
+		}
+	})
+
+	var multipartBody bytes.Buffer
+	mw := multipart.NewWriter(&multipartBody)
+	_ = mw.WriteField("name", "tom")
+	_ = mw.WriteField("password", "secret")
+	fw, _ := mw.CreateFormFile("avatar", "a.txt")
+	_, _ = fw.Write([]byte("file content"))
+	_ = mw.Close()
+
+	requests := []struct {
+		uri         string
+		contentType string
+		body        string
+	}{
+		{"/login?token=abc&page=2", "application/x-www-form-urlencoded", "name=tom&password=secret"},
+		{"/login?api_key=k", mw.FormDataContentType(), multipartBody.String()},
+		{"/login", "application/json", `{"password":`},
+		{"/login", "text/plain", "password=secret"},
+	}
+	for _, r := range requests {
+		req := httptest.NewRequest(http.MethodPost, r.uri, strings.NewReader(r.body))
+		req.Header.Set("Content-Type", r.contentType)
+		server.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if len(entries) != len(requests) {
+		t.Fatalf("应录制 %d 个请求，实际为 %d", len(requests), len(entries))
+	}
+
+	if entries[0].URI != "/login?page=2&token=%5BREDACTED%5D" || string(entries[0].Body) != "name=tom&password=%5BREDACTED%5D" {
+		t.Errorf("urlencoded 请求没有脱敏: %s %s", entries[0].URI, entries[0].Body)
+	}
+
+	if entries[1].URI != "/login?api_key=%5BREDACTED%5D" {
+		t.Errorf("查询参数没有脱敏: %s", entries[1].URI)
+	}
+	_, params, _ := strings.Cut(mw.FormDataContentType(), "boundary=")
+	form, err := multipart.NewReader(bytes.NewReader(entries[1].Body), params).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.Value["password"][0] != Redacted || form.Value["name"][0] != "tom" || len(form.File["avatar"]) != 1 {
+		t.Errorf("multipart 请求没有正确脱敏: %v %v", form.Value, form.File)
+	}
+
+	// 无法解析的 JSON 请求体不录制，无法识别的类型原样保留
+	if entries[2].Body != nil {
+		t.Errorf("无法解析的请求体不应录制，实际为 %s", entries[2].Body)
+	}
+	if string(entries[3].Body) != "password=secret" {
+		t.Errorf("其他类型的请求体应原样保留，实际为 %s", entries[3].Body)
+	}
+}
+
+func TestReplaySpeed(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+
+	var buf bytes.Buffer
+	sink := NewJSONLinesSink(&buf)
+	now := time.Now()
+	for i := range 3 {
+		_ = sink.Write(context.Background(), Entry{Time: now.Add(time.Duration(i) * 100 * time.Millisecond), Method: http.MethodGet, URI: "/", Status: http.StatusOK})
+	}
+
+	r := &Replayer{Target: target.URL, Speed: 2}
+	stats, err := r.Replay(context.Background(), bytes.NewReader(buf.Bytes()))
+	if err != nil || stats.Sent != 3 || stats.StatusMismatch != 0 {
+		t.Fatalf("统计结果不正确: %+v %v", stats, err)
+	}
+	if stats.Duration < 100*time.Millisecond {
+		t.Errorf("两倍速回放 200ms 的流量应至少耗时 100ms，实际为 %s", stats.Duration)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	r.Speed = 0.1
+	if _, err = r.Replay(ctx, bytes.NewReader(buf.Bytes())); err == nil {
+		t.Error("ctx 被取消时应返回错误")
+	}
+}
@@ -0,0 +1,166 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HeaderReplay 回放的请求带有的请求头，目标服务可以据此跳过有副作用的操作，例如发送邮件
+const HeaderReplay = "X-Replay-Request"
+
+// hopHeaders 逐跳的请求头，回放时不发送
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization",
+	"Te", "Trailer", "Transfer-Encoding", "Upgrade", "Content-Length",
+}
+
+// Stats 回放的统计结果
+type Stats struct {
+	// Sent 发送的请求数
+	Sent int `json:"sent"`
+	// Failed 发送失败的请求数，例如连接被拒绝或超时
+	Failed int `json:"failed"`
+	// StatusMismatch 响应状态码与录制时不同的请求数
+	StatusMismatch int `json:"status_mismatch"`
+	// Duration 回放的总耗时
+	Duration time.Duration `json:"duration"`
+}
+
+// Replayer 将录制的请求重新发送到目标服务，用于回归测试与压测
+type Replayer struct {
+	// Target 目标服务的地址，例如 "http://staging.internal:8080"，录制的路径与查询参数会追加在其后
+	Target string
+	// Speed 回放速度的倍数，1 按录制时请求之间的间隔发送，2 为两倍速，为0时不等待，尽快发送
+	Speed float64
+	// Concurrency 同时进行的请求的最大数量，默认为 10
+	Concurrency int
+	// Client 发送请求的客户端，默认为 http.DefaultClient
+	Client *http.Client
+	// Header 覆盖录制的请求头，例如为已脱敏的 Authorization 设置测试环境的令牌
+	// 值为 Redacted 且没有被覆盖的请求头不会发送
+	Header http.Header
+	// OnResult 每个请求完成后调用，可以为nil，err 不为nil时 resp 为nil
+	// 可能被多个 goroutine 同时调用，调用返回后响应体会被关闭
+	OnResult func(e Entry, resp *http.Response, err error)
+}
+
+// Replay 从 src 中读取 JSONLinesSink 写入的请求并回放，等待全部请求完成后返回
+// 返回值: 回放的统计结果；读取录制文件失败或 ctx 被取消时同时返回错误
+func (r *Replayer) Replay(ctx context.Context, src io.Reader) (Stats, error) {
+	concurrency := r.Concurrency
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+	var (
+		stats   Stats
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, concurrency)
+		base    time.Time
+		started = time.Now()
+	)
+	err := ReadEntries(src, func(e Entry) error {
+		if base.IsZero() {
+			base = e.Time
+		}
+		if err := r.wait(ctx, started, e.Time.Sub(base)); err != nil {
+			return err
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			failed, mismatch := r.send(ctx, e)
+			mu.Lock()
+			defer mu.Unlock()
+			stats.Sent++
+			if failed {
+				stats.Failed++
+			}
+			if mismatch {
+				stats.StatusMismatch++
+			}
+		}()
+		return nil
+	})
+	wg.Wait()
+	stats.Duration = time.Since(started)
+	return stats, err
+}
+
+// wait 等待到按 Speed 缩放后的发送时间
+func (r *Replayer) wait(ctx context.Context, started time.Time, offset time.Duration) error {
+	if r.Speed <= 0 {
+		return ctx.Err()
+	}
+	d := time.Until(started.Add(time.Duration(float64(offset) / r.Speed)))
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// send 发送一条请求，返回是否失败以及状态码是否与录制时不同
+func (r *Replayer) send(ctx context.Context, e Entry) (failed, mismatch bool) {
+	req, err := http.NewRequestWithContext(ctx, e.Method, strings.TrimSuffix(r.Target, "/")+e.URI, bytes.NewReader(e.Body))
+	if err == nil {
+		req.Header = r.header(e.Header)
+		var resp *http.Response
+		client := r.Client
+		if client == nil {
+			client = http.DefaultClient
+		}
+		if resp, err = client.Do(req); err == nil {
+			if r.OnResult != nil {
+				r.OnResult(e, resp, nil)
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+			return false, resp.StatusCode != e.Status
+		}
+	}
+	if r.OnResult != nil {
+		r.OnResult(e, nil, err)
+	}
+	return true, false
+}
+
+// header 返回回放的请求头
+func (r *Replayer) header(recorded http.Header) http.Header {
+	header := recorded.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	for _, h := range hopHeaders {
+		header.Del(h)
+	}
+	for name, vals := range header {
+		if len(vals) == 1 && vals[0] == Redacted {
+			delete(header, name)
+		}
+	}
+	for name, vals := range r.Header {
+		header[http.CanonicalHeaderKey(name)] = vals
+	}
+	header.Set(HeaderReplay, "1")
+	return header
+}
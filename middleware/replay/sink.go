@@ -0,0 +1,62 @@
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Sink 录制的请求的写入接口
+// 实现可以将请求写入文件、消息队列或对象存储
+type Sink interface {
+	// Write 写入一条录制的请求
+	Write(ctx context.Context, e Entry) error
+}
+
+// SinkFunc 函数形式的 Sink，便于将请求发送到消息队列等
+type SinkFunc func(ctx context.Context, e Entry) error
+
+// Write 实现 Sink 接口
+func (f SinkFunc) Write(ctx context.Context, e Entry) error {
+	return f(ctx, e)
+}
+
+// JSONLinesSink 将录制的请求以每行一个JSON的格式写入 io.Writer，可以直接交给 Replayer 回放
+type JSONLinesSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONLinesSink 创建写入 w 的 JSONLinesSink，w 通常为打开的文件，由调用方负责关闭
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{enc: json.NewEncoder(w)}
+}
+
+// Write 实现 Sink 接口，并发调用是安全的
+func (s *JSONLinesSink) Write(_ context.Context, e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(e)
+}
+
+// ReadEntries 逐行读取 JSONLinesSink 写入的请求，fn 返回错误时停止读取并返回该错误
+func ReadEntries(r io.Reader, fn func(e Entry) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), 16<<20)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
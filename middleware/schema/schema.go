@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/justinwongcn/ant"
+)
+
+// errorBody 校验失败时的响应
+type errorBody struct {
+	Error      string                `json:"error"`
+	Violations []ant.SchemaViolation `json:"violations,omitempty"`
+}
+
+// compiled 路由请求体的结构
+type compiled struct {
+	schema *ant.Schema
+	defs   map[string]*ant.Schema
+}
+
+// MiddlewareBuilder 用于构建请求体结构校验中间件
+// 按路由通过 Describe 声明的 RouteMeta.Request 校验 JSON 请求体，不符合时响应 400 并列出所有不符合的地方，
+// 使用与 OpenAPI 文档相同的结构，因此文档与校验不会不一致
+type MiddlewareBuilder struct {
+	meta        func(pattern string) (ant.RouteMeta, bool)
+	maxBodySize int64
+	schemas     sync.Map
+}
+
+// NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
+// meta: 返回路由描述信息的函数，通常为 server.RouteMeta
+func NewMiddlewareBuilder(meta func(pattern string) (ant.RouteMeta, bool)) *MiddlewareBuilder {
+	return &MiddlewareBuilder{
+		meta:        meta,
+		maxBodySize: 1 << 20,
+	}
+}
+
+// MaxBodySize 设置请求体的最大字节数，超过时响应 413，默认为 1MB
+func (b *MiddlewareBuilder) MaxBodySize(n int64) *MiddlewareBuilder {
+	b.maxBodySize = n
+	return b
+}
+
+// Build 构建请求体结构校验中间件
+// 1. 只校验声明了 Request 且设置了 Schema 或 Type 的路由，以及 Content-Type 为空或为 JSON 的请求
+// 2. 路由的结构在第一次请求时生成并缓存，之后通过 Describe 修改不会生效
+// 3. 校验通过后请求体可以被处理函数再次读取
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			c := b.compiled(ctx.Req.Pattern)
+			if c == nil || !isJSON(ctx.Req.Header.Get("Content-Type")) {
+				next(ctx)
+				return
+			}
+			var body []byte
+			if ctx.Req.Body != nil {
+				var err error
+				body, err = io.ReadAll(io.LimitReader(ctx.Req.Body, b.maxBodySize+1))
+				if err != nil {
+					_ = ctx.RespJSON(http.StatusBadRequest, errorBody{Error: "读取请求体失败"})
+					return
+				}
+				if int64(len(body)) > b.maxBodySize {
+					_ = ctx.RespJSON(http.StatusRequestEntityTooLarge, errorBody{Error: "请求体过大"})
+					return
+				}
+			}
+			if len(bytes.TrimSpace(body)) == 0 {
+				_ = ctx.RespJSON(http.StatusBadRequest, errorBody{Error: "缺少请求体"})
+				return
+			}
+			var val any
+			dec := json.NewDecoder(bytes.NewReader(body))
+			dec.UseNumber()
+			if err := dec.Decode(&val); err != nil {
+				_ = ctx.RespJSON(http.StatusBadRequest, errorBody{Error: "请求体不是合法的 JSON"})
+				return
+			}
+			if violations := ant.ValidateSchema(c.schema, c.defs, val); len(violations) > 0 {
+				_ = ctx.RespJSON(http.StatusBadRequest, errorBody{Error: "请求体不符合约定", Violations: violations})
+				return
+			}
+			ctx.Req.Body = io.NopCloser(bytes.NewReader(body))
+			next(ctx)
+		}
+	}
+}
+
+// compiled 返回路由请求体的结构，没有声明时返回nil
+func (b *MiddlewareBuilder) compiled(pattern string) *compiled {
+	if c, ok := b.schemas.Load(pattern); ok {
+		return c.(*compiled)
+	}
+	var c *compiled
+	if meta, ok := b.meta(pattern); ok && meta.Request != nil {
+		if s, defs := meta.Request.JSONSchema(); s != nil {
+			c = &compiled{schema: s, defs: defs}
+		}
+	}
+	b.schemas.Store(pattern, c)
+	return c
+}
+
+// isJSON 判断 Content-Type 是否为空或为 JSON
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/justinwongcn/ant"
+)
+
+type createUserReq struct {
+	Name  string   `json:"name"`
+	Age   int      `json:"age"`
+	Email string   `json:"email,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func TestSchemaMiddleware(t *testing.T) {
+	server := ant.NewHTTPServer()
+	server.Use(NewMiddlewareBuilder(server.RouteMeta).MaxBodySize(256).Build())
+	server.Handle("POST /users", func(ctx *ant.Context) {
+		body, _ := io.ReadAll(ctx.Req.Body)
+		ctx.RespData = body
+	})
+	server.Describe("POST /users", ant.RouteMeta{Request: ant.BodyOf[createUserReq]("新用户")})
+	minAge := 18.0
+	server.Handle("POST /adults", func(ctx *ant.Context) {})
+	server.Describe("POST /adults", ant.RouteMeta{Request: &ant.Body{Schema: &ant.Schema{
+		Type:       "object",
+		Required:   []string{"age"},
+		Properties: map[string]*ant.Schema{"age": {Type: "integer", Minimum: &minAge}},
+	}}})
+	server.Handle("POST /free", func(ctx *ant.Context) {})
+
+	send := func(path, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("/users", "application/json", `{"name":"tom","age":20}`)
+	if w.Code != http.StatusOK || w.Body.String() != `{"name":"tom","age":20}` {
+		t.Fatalf("合法的请求体应传给处理函数，实际为 %d %s", w.Code, w.Body.String())
+	}
+
+	w = send("/users", "", `{"age":"20","tags":[1]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("不符合约定的请求体应响应 400，实际为 %d", w.Code)
+	}
+	var resp struct {
+		Error      string                `json:"error"`
+		Violations []ant.SchemaViolation `json:"violations"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, 0, len(resp.Violations))
+	for _, v := range resp.Violations {
+		paths = append(paths, v.Path)
+	}
+	if strings.Join(paths, ",") != "$.name,$.age,$.tags[0]" {
+		t.Errorf("应列出所有不符合的地方，实际为 %+v", resp.Violations)
+	}
+
+	if w = send("/adults", "application/json", `{"age":16}`); w.Code != http.StatusBadRequest {
+		t.Errorf("手动编写的结构应生效，实际为 %d", w.Code)
+	}
+	if w = send("/adults", "application/json", `{"age":`); w.Code != http.StatusBadRequest {
+		t.Errorf("不合法的 JSON 应响应 400，实际为 %d", w.Code)
+	}
+	if w = send("/adults", "application/json", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("缺少请求体应响应 400，实际为 %d", w.Code)
+	}
+	if w = send("/adults", "application/json", `{"age":`+strings.Repeat("1", 300)+`}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("请求体过大应响应 413，实际为 %d", w.Code)
+	}
+	if w = send("/adults", "text/csv", "age\n16"); w.Code != http.StatusOK {
+		t.Errorf("非 JSON 的请求不校验，实际为 %d", w.Code)
+	}
+	if w = send("/free", "application/json", `{`); w.Code != http.StatusOK {
+		t.Errorf("没有声明请求体的路由不校验，实际为 %d", w.Code)
+	}
+}
@@ -0,0 +1,198 @@
+package secure
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+// maxReportSize 单个违规报告请求体的最大字节数
+const maxReportSize = 64 << 10
+
+// Violation 聚合后的内容安全策略违规
+// 按违反的指令、被阻止的地址与所在页面聚合
+type Violation struct {
+	Directive   string    `json:"directive"`
+	BlockedURI  string    `json:"blocked_uri"`
+	DocumentURI string    `json:"document_uri"`
+	Disposition string    `json:"disposition,omitempty"`
+	SourceFile  string    `json:"source_file,omitempty"`
+	LineNumber  int       `json:"line_number,omitempty"`
+	Count       int       `json:"count"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+type violationKey struct {
+	directive, blockedURI, documentURI string
+}
+
+// Collector 收集浏览器上报的内容安全策略违规
+// 支持旧式的 application/csp-report 与 Reporting API 的 application/reports+json
+type Collector struct {
+	mu         sync.Mutex
+	maxEntries int
+	violations map[violationKey]*Violation
+	dropped    int
+	now        func() time.Time
+}
+
+// NewCollector 创建违规收集器
+// maxEntries: 最多保留的不同违规数量，超过后丢弃新的违规并计数，为0时使用 1000
+func NewCollector(maxEntries int) *Collector {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &Collector{
+		maxEntries: maxEntries,
+		violations: make(map[violationKey]*Violation),
+		now:        time.Now,
+	}
+}
+
+// legacyReport application/csp-report 的格式
+type legacyReport struct {
+	Report struct {
+		DocumentURI        string `json:"document-uri"`
+		BlockedURI         string `json:"blocked-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+	} `json:"csp-report"`
+}
+
+// reportingAPIReport application/reports+json 中的单个报告
+type reportingAPIReport struct {
+	Type string `json:"type"`
+	Body struct {
+		DocumentURL        string `json:"documentURL"`
+		BlockedURL         string `json:"blockedURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"sourceFile"`
+		LineNumber         int    `json:"lineNumber"`
+	} `json:"body"`
+}
+
+// Handler 返回接收违规报告的处理函数，通常注册为 "POST /csp-report"
+// 报告格式不合法时响应 400，否则响应 204
+func (c *Collector) Handler() ant.HandleFunc {
+	return func(ctx *ant.Context) {
+		body, err := io.ReadAll(io.LimitReader(ctx.Req.Body, maxReportSize+1))
+		if err != nil || len(body) > maxReportSize {
+			ctx.RespStatusCode = http.StatusRequestEntityTooLarge
+			return
+		}
+		mediaType, _, _ := mime.ParseMediaType(ctx.Req.Header.Get("Content-Type"))
+		var violations []Violation
+		if mediaType == "application/reports+json" {
+			var reports []reportingAPIReport
+			if err = json.Unmarshal(body, &reports); err == nil {
+				for _, r := range reports {
+					if r.Type != "csp-violation" {
+						continue
+					}
+					violations = append(violations, Violation{
+						Directive: r.Body.EffectiveDirective, BlockedURI: r.Body.BlockedURL, DocumentURI: r.Body.DocumentURL,
+						Disposition: r.Body.Disposition, SourceFile: r.Body.SourceFile, LineNumber: r.Body.LineNumber,
+					})
+				}
+			}
+		} else {
+			var r legacyReport
+			if err = json.Unmarshal(body, &r); err == nil {
+				directive := r.Report.EffectiveDirective
+				if directive == "" {
+					directive = r.Report.ViolatedDirective
+				}
+				violations = append(violations, Violation{
+					Directive: directive, BlockedURI: r.Report.BlockedURI, DocumentURI: r.Report.DocumentURI,
+					Disposition: r.Report.Disposition, SourceFile: r.Report.SourceFile, LineNumber: r.Report.LineNumber,
+				})
+			}
+		}
+		if err != nil {
+			ctx.RespStatusCode = http.StatusBadRequest
+			return
+		}
+		for _, v := range violations {
+			c.add(v)
+		}
+		ctx.RespStatusCode = http.StatusNoContent
+	}
+}
+
+// add 聚合一条违规
+func (c *Collector) add(v Violation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	key := violationKey{v.Directive, v.BlockedURI, v.DocumentURI}
+	if existing, ok := c.violations[key]; ok {
+		existing.Count++
+		existing.LastSeen = now
+		return
+	}
+	if len(c.violations) >= c.maxEntries {
+		c.dropped++
+		return
+	}
+	v.Count, v.FirstSeen, v.LastSeen = 1, now, now
+	c.violations[key] = &v
+}
+
+// Violations 返回聚合后的违规，按次数从多到少排列
+func (c *Collector) Violations() []Violation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := make([]Violation, 0, len(c.violations))
+	for _, v := range c.violations {
+		res = append(res, *v)
+	}
+	slices.SortFunc(res, func(a, b Violation) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return a.LastSeen.Compare(b.LastSeen)
+	})
+	return res
+}
+
+// Reset 清空已收集的违规
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.violations)
+	c.dropped = 0
+}
+
+// AdminHandler 返回查看违规的管理接口
+// GET 返回聚合后的违规与被丢弃的数量，DELETE 清空
+// 注意：需要自行添加鉴权中间件
+func (c *Collector) AdminHandler() ant.HandleFunc {
+	return func(ctx *ant.Context) {
+		switch ctx.Req.Method {
+		case http.MethodGet:
+			violations := c.Violations()
+			c.mu.Lock()
+			dropped := c.dropped
+			c.mu.Unlock()
+			ctx.Resp.Header().Set("Cache-Control", "no-store")
+			_ = ctx.RespJSONOK(map[string]any{"violations": violations, "dropped": dropped})
+		case http.MethodDelete:
+			c.Reset()
+			ctx.RespStatusCode = http.StatusNoContent
+		default:
+			ctx.Resp.Header().Set("Allow", "GET, DELETE")
+			ctx.RespStatusCode = http.StatusMethodNotAllowed
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package secure
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/justinwongcn/ant"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	server := ant.NewHTTPServer()
+	server.Use(NewMiddlewareBuilder().
+		CSP("default-src 'self';").
+		ReportOnly().
+		ReportURI("/csp-report").
+		HSTS(3600, true).
+		Build())
+	server.Handle("GET /", func(ctx *ant.Context) {
+		ctx.RespData = []byte("ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	header := w.Header()
+	if got := header.Get("Content-Security-Policy-Report-Only"); got != "default-src 'self'; report-uri /csp-report; report-to csp-endpoint" {
+		t.Errorf("报告模式的策略不正确: %q", got)
+	}
+	if header.Get("Content-Security-Policy") != "" {
+		t.Error("报告模式下不应设置 Content-Security-Policy")
+	}
+	if header.Get("Reporting-Endpoints") != `csp-endpoint="/csp-report"` {
+		t.Errorf("Reporting-Endpoints 不正确: %q", header.Get("Reporting-Endpoints"))
+	}
+	if header.Get("X-Content-Type-Options") != "nosniff" || header.Get("X-Frame-Options") != "DENY" {
+		t.Errorf("默认的安全响应头不正确: %v", header)
+	}
+	if header.Get("Strict-Transport-Security") != "" {
+		t.Error("非 TLS 请求不应设置 HSTS")
+	}
+
+	req.TLS = &tls.ConnectionState{}
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=3600; includeSubDomains" {
+		t.Errorf("TLS 请求的 HSTS 不正确: %q", got)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	c := NewCollector(2)
+	server := ant.NewHTTPServer()
+	server.Handle("POST /csp-report", c.Handler())
+	server.Handle("/admin/csp", c.AdminHandler())
+
+	post := func(contentType, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/csp-report", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	legacy := `{"csp-report":{"document-uri":"https://a.com/","blocked-uri":"https://evil.com/x.js","violated-directive":"script-src-elem"}}`
+	for i := 0; i < 2; i++ {
+		if code := post("application/csp-report", legacy); code != http.StatusNoContent {
+			t.Fatalf("旧式报告应响应 204，实际为 %d", code)
+		}
+	}
+	reports := `[{"type":"csp-violation","body":{"documentURL":"https://a.com/p","blockedURL":"inline","effectiveDirective":"style-src","disposition":"report"}},
+		{"type":"deprecation","body":{}}]`
+	if code := post("application/reports+json", reports); code != http.StatusNoContent {
+		t.Fatalf("Reporting API 报告应响应 204，实际为 %d", code)
+	}
+	// 超出保留数量的新违规被丢弃
+	if code := post("application/csp-report", `{"csp-report":{"blocked-uri":"data","violated-directive":"img-src"}}`); code != http.StatusNoContent {
+		t.Fatalf("应响应 204，实际为 %d", code)
+	}
+	if code := post("application/csp-report", "not json"); code != http.StatusBadRequest {
+		t.Errorf("格式不合法的报告应响应 400，实际为 %d", code)
+	}
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/csp", nil))
+	var resp struct {
+		Violations []Violation `json:"violations"`
+		Dropped    int         `json:"dropped"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Violations) != 2 || resp.Dropped != 1 {
+		t.Fatalf("应有 2 条违规与 1 条被丢弃，实际为 %+v", resp)
+	}
+	first := resp.Violations[0]
+	if first.Directive != "script-src-elem" || first.Count != 2 || first.BlockedURI != "https://evil.com/x.js" {
+		t.Errorf("次数最多的违规不正确: %+v", first)
+	}
+	if second := resp.Violations[1]; second.Directive != "style-src" || second.Disposition != "report" {
+		t.Errorf("Reporting API 的违规不正确: %+v", second)
+	}
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/csp", nil))
+	if w.Code != http.StatusNoContent || len(c.Violations()) != 0 {
+		t.Errorf("DELETE 应清空违规，实际为 %d %v", w.Code, c.Violations())
+	}
+}
@@ -0,0 +1,300 @@
+package slo
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+// DefaultBuckets 默认的延迟直方图分桶上界
+var DefaultBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+// Objective 路由的服务等级目标
+type Objective struct {
+	// Latency 延迟阈值，超过该值的请求视为慢请求，为0时不跟踪延迟目标
+	Latency time.Duration
+	// LatencyTarget 延迟达标率目标，例如 0.99 表示 99% 的请求应在 Latency 内完成
+	LatencyTarget float64
+	// ErrorRate 允许的错误率（5xx），例如 0.01，为0时不跟踪错误率目标
+	ErrorRate float64
+	// Window 计算燃烧率的滑动窗口，默认为 5 分钟
+	Window time.Duration
+	// BurnThreshold 触发告警的燃烧率，默认为 2，即错误预算以两倍速度消耗
+	BurnThreshold float64
+	// MinRequests 窗口内的最少请求数，低于该值时不告警，默认为 10
+	MinRequests int64
+}
+
+// AlertKind 告警类型
+type AlertKind string
+
+const (
+	// AlertLatency 延迟目标受到威胁
+	AlertLatency AlertKind = "latency"
+	// AlertErrorRate 错误率目标受到威胁
+	AlertErrorRate AlertKind = "error_rate"
+)
+
+// Alert SLO 告警事件
+type Alert struct {
+	Route     string
+	Kind      AlertKind
+	BurnRate  float64
+	Total     int64
+	Bad       int64
+	Objective Objective
+	Time      time.Time
+	// Resolved 为 true 表示燃烧率已恢复到阈值以下
+	Resolved bool
+}
+
+// Histogram 延迟直方图
+type Histogram struct {
+	// Buckets 各分桶的上界
+	Buckets []time.Duration `json:"buckets"`
+	// Counts 各分桶的累计请求数，最后一项为超过所有上界的请求数
+	Counts []int64 `json:"counts"`
+	// Count 请求总数
+	Count int64 `json:"count"`
+	// Sum 请求延迟总和
+	Sum time.Duration `json:"sum"`
+}
+
+// RouteStats 单个路由的统计快照
+type RouteStats struct {
+	Route          string     `json:"route"`
+	Histogram      Histogram  `json:"histogram"`
+	Objective      *Objective `json:"objective,omitempty"`
+	LatencyBurn    float64    `json:"latency_burn"`
+	ErrorRateBurn  float64    `json:"error_rate_burn"`
+	WindowRequests int64      `json:"window_requests"`
+}
+
+// tracker 单个路由的统计状态
+type tracker struct {
+	mu        sync.Mutex
+	hist      Histogram
+	objective *Objective
+	window    *window
+	alerting  map[AlertKind]bool
+}
+
+// MiddlewareBuilder SLO 中间件构建器
+type MiddlewareBuilder struct {
+	mu         sync.RWMutex
+	objectives map[string]Objective
+	trackers   map[string]*tracker
+	buckets    []time.Duration
+	alertFunc  func(a Alert)
+	now        func() time.Time
+}
+
+// NewMiddlewareBuilder 创建 SLO 中间件构建器
+func NewMiddlewareBuilder() *MiddlewareBuilder {
+	return &MiddlewareBuilder{
+		objectives: make(map[string]Objective),
+		trackers:   make(map[string]*tracker),
+		buckets:    DefaultBuckets,
+		alertFunc:  func(a Alert) {},
+		now:        time.Now,
+	}
+}
+
+// Objective 为路由声明服务等级目标
+// route: 注册路由时使用的路由模式，例如 "GET /users/{id}"
+func (b *MiddlewareBuilder) Objective(route string, obj Objective) *MiddlewareBuilder {
+	if obj.Window <= 0 {
+		obj.Window = 5 * time.Minute
+	}
+	if obj.BurnThreshold <= 0 {
+		obj.BurnThreshold = 2
+	}
+	if obj.MinRequests <= 0 {
+		obj.MinRequests = 10
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objectives[route] = obj
+	delete(b.trackers, route)
+	return b
+}
+
+// Buckets 设置延迟直方图的分桶上界，需要按升序排列
+func (b *MiddlewareBuilder) Buckets(buckets ...time.Duration) *MiddlewareBuilder {
+	b.buckets = buckets
+	return b
+}
+
+// AlertFunc 设置告警回调，燃烧率超过阈值及恢复时都会调用
+func (b *MiddlewareBuilder) AlertFunc(fn func(a Alert)) *MiddlewareBuilder {
+	b.alertFunc = fn
+	return b
+}
+
+// Build 构建 SLO 中间件
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			start := b.now()
+			next(ctx)
+			b.observe(ctx.Req.Pattern, ctx.ResponseStatus(), b.now().Sub(start))
+		}
+	}
+}
+
+// observe 记录一次请求并检查是否需要告警
+func (b *MiddlewareBuilder) observe(route string, status int, elapsed time.Duration) {
+	if route == "" {
+		return
+	}
+	t := b.tracker(route)
+
+	t.mu.Lock()
+	idx := sort.Search(len(t.hist.Buckets), func(i int) bool {
+		return elapsed <= t.hist.Buckets[i]
+	})
+	t.hist.Counts[idx]++
+	t.hist.Count++
+	t.hist.Sum += elapsed
+
+	if t.objective == nil {
+		t.mu.Unlock()
+		return
+	}
+	now := b.now()
+	obj := *t.objective
+	isSlow := obj.Latency > 0 && elapsed > obj.Latency
+	t.window.add(now, status >= http.StatusInternalServerError, isSlow)
+	total, errs, slow := t.window.sum(now)
+
+	var alerts []Alert
+	if obj.ErrorRate > 0 {
+		alerts = t.transition(alerts, route, AlertErrorRate, burnRate(errs, total, obj.ErrorRate), total, errs, now)
+	}
+	if obj.Latency > 0 && obj.LatencyTarget > 0 && obj.LatencyTarget < 1 {
+		alerts = t.transition(alerts, route, AlertLatency, burnRate(slow, total, 1-obj.LatencyTarget), total, slow, now)
+	}
+	t.mu.Unlock()
+
+	for _, a := range alerts {
+		b.alertFunc(a)
+	}
+}
+
+// transition 根据燃烧率判断告警状态是否发生变化
+// 只在进入告警与恢复时产生事件，避免持续告警
+func (t *tracker) transition(alerts []Alert, route string, kind AlertKind, burn float64, total, bad int64, now time.Time) []Alert {
+	obj := *t.objective
+	threatened := total >= obj.MinRequests && burn >= obj.BurnThreshold
+	if threatened == t.alerting[kind] {
+		return alerts
+	}
+	t.alerting[kind] = threatened
+	return append(alerts, Alert{
+		Route:     route,
+		Kind:      kind,
+		BurnRate:  burn,
+		Total:     total,
+		Bad:       bad,
+		Objective: obj,
+		Time:      now,
+		Resolved:  !threatened,
+	})
+}
+
+// tracker 获取或创建路由的统计状态
+func (b *MiddlewareBuilder) tracker(route string) *tracker {
+	b.mu.RLock()
+	t, ok := b.trackers[route]
+	b.mu.RUnlock()
+	if ok {
+		return t
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if t, ok = b.trackers[route]; ok {
+		return t
+	}
+	t = &tracker{
+		hist: Histogram{
+			Buckets: b.buckets,
+			Counts:  make([]int64, len(b.buckets)+1),
+		},
+		alerting: make(map[AlertKind]bool, 2),
+	}
+	if obj, ok := b.objectives[route]; ok {
+		t.objective = &obj
+		t.window = newWindow(obj.Window, 10)
+	}
+	b.trackers[route] = t
+	return t
+}
+
+// Snapshot 返回所有路由的统计快照，按路由模式排序
+func (b *MiddlewareBuilder) Snapshot() []RouteStats {
+	b.mu.RLock()
+	routes := make([]string, 0, len(b.trackers))
+	for route := range b.trackers {
+		routes = append(routes, route)
+	}
+	b.mu.RUnlock()
+	sort.Strings(routes)
+
+	now := b.now()
+	res := make([]RouteStats, 0, len(routes))
+	for _, route := range routes {
+		t := b.tracker(route)
+		t.mu.Lock()
+		stats := RouteStats{
+			Route: route,
+			Histogram: Histogram{
+				Buckets: t.hist.Buckets,
+				Counts:  append([]int64(nil), t.hist.Counts...),
+				Count:   t.hist.Count,
+				Sum:     t.hist.Sum,
+			},
+		}
+		if t.objective != nil {
+			obj := *t.objective
+			total, errs, slow := t.window.sum(now)
+			stats.Objective = &obj
+			stats.WindowRequests = total
+			stats.ErrorRateBurn = burnRate(errs, total, obj.ErrorRate)
+			stats.LatencyBurn = burnRate(slow, total, 1-obj.LatencyTarget)
+		}
+		t.mu.Unlock()
+		res = append(res, stats)
+	}
+	return res
+}
+
+// Handler 返回以JSON格式输出统计快照的处理函数
+func (b *MiddlewareBuilder) Handler() ant.HandleFunc {
+	return func(ctx *ant.Context) {
+		_ = ctx.RespJSONOK(b.Snapshot())
+	}
+}
+
+// burnRate 计算错误预算的燃烧率
+// 燃烧率为实际不达标比例与允许不达标比例之比，1 表示恰好按预算消耗
+func burnRate(bad, total int64, allowed float64) float64 {
+	if total == 0 || allowed <= 0 {
+		return 0
+	}
+	return float64(bad) / float64(total) / allowed
+}
@@ -0,0 +1,163 @@
+package slo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+// fakeClock 可控的时钟，每次读取推进固定时长
+type fakeClock struct {
+	now  time.Time
+	step time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.now = c.now.Add(c.step)
+	return c.now
+}
+
+func TestSLOErrorRateAlert(t *testing.T) {
+	var alerts []Alert
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), step: time.Millisecond}
+	b := NewMiddlewareBuilder().
+		Objective("GET /orders", Objective{ErrorRate: 0.1, MinRequests: 10}).
+		AlertFunc(func(a Alert) { alerts = append(alerts, a) })
+	b.now = clock.Now
+
+	status := http.StatusOK
+	server := ant.NewHTTPServer()
+	server.Use(b.Build())
+	server.Handle("GET /orders", func(ctx *ant.Context) {
+		ctx.RespStatusCode = status
+	})
+
+	send := func(n int) {
+		for i := 0; i < n; i++ {
+			server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+		}
+	}
+
+	send(20)
+	if len(alerts) != 0 {
+		t.Fatalf("全部成功时不应告警, 得到 %v", alerts)
+	}
+
+	// 错误率达到 20%，燃烧率为 2，触发告警
+	status = http.StatusInternalServerError
+	send(5)
+	if len(alerts) != 1 || alerts[0].Kind != AlertErrorRate || alerts[0].Resolved {
+		t.Fatalf("期望产生错误率告警, 得到 %+v", alerts)
+	}
+
+	// 持续错误不应重复告警
+	send(5)
+	if len(alerts) != 1 {
+		t.Fatalf("告警状态未变化时不应重复告警, 得到 %d 次", len(alerts))
+	}
+
+	// 错误率回落后产生恢复事件
+	status = http.StatusOK
+	send(100)
+	if len(alerts) != 2 || !alerts[1].Resolved {
+		t.Fatalf("期望产生恢复事件, 得到 %+v", alerts)
+	}
+}
+
+func TestSLODirectWriteStatus(t *testing.T) {
+	b := NewMiddlewareBuilder().Objective("GET /stream", Objective{ErrorRate: 0.1, MinRequests: 1})
+	server := ant.NewHTTPServer()
+	server.Use(b.Build())
+	server.Handle("GET /stream", func(ctx *ant.Context) {
+		// 直接写入响应时 RespStatusCode 为 0
+		ctx.Resp.WriteHeader(http.StatusServiceUnavailable)
+	})
+	for i := 0; i < 10; i++ {
+		server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream", nil))
+	}
+	stats := b.Snapshot()
+	if len(stats) != 1 || stats[0].ErrorRateBurn != 10 {
+		t.Errorf("直接写入的 503 应计为错误, 得到 %+v", stats)
+	}
+}
+
+func TestSLOLatencyAlert(t *testing.T) {
+	var alerts []Alert
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), step: 50 * time.Millisecond}
+	b := NewMiddlewareBuilder().
+		Objective("GET /slow", Objective{Latency: 10 * time.Millisecond, LatencyTarget: 0.9}).
+		AlertFunc(func(a Alert) { alerts = append(alerts, a) })
+	b.now = clock.Now
+
+	handler := b.Build()(func(ctx *ant.Context) {})
+	for i := 0; i < 10; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+		req.Pattern = "GET /slow"
+		handler(&ant.Context{Req: req, Resp: httptest.NewRecorder()})
+	}
+
+	if len(alerts) != 1 || alerts[0].Kind != AlertLatency {
+		t.Fatalf("期望产生延迟告警, 得到 %+v", alerts)
+	}
+	if alerts[0].BurnRate < 9.99 {
+		t.Errorf("期望燃烧率为 10, 得到 %f", alerts[0].BurnRate)
+	}
+}
+
+func TestSLOHistogramSnapshot(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), step: 7 * time.Millisecond}
+	b := NewMiddlewareBuilder().Buckets(5*time.Millisecond, 10*time.Millisecond)
+	b.now = clock.Now
+
+	server := ant.NewHTTPServer()
+	server.Use(b.Build())
+	server.Handle("GET /a", func(ctx *ant.Context) {})
+	server.Handle("GET /metrics/slo", b.Handler())
+
+	for i := 0; i < 3; i++ {
+		server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	}
+
+	var stats RouteStats
+	for _, s := range b.Snapshot() {
+		if s.Route == "GET /a" {
+			stats = s
+		}
+	}
+	if stats.Histogram.Count != 3 {
+		t.Fatalf("期望请求数 3, 得到 %d", stats.Histogram.Count)
+	}
+	// 每个请求耗时 7ms，应落在 (5ms, 10ms] 分桶
+	if stats.Histogram.Counts[1] != 3 {
+		t.Errorf("分桶计数不正确: %v", stats.Histogram.Counts)
+	}
+	if stats.Objective != nil {
+		t.Error("未声明目标的路由不应包含目标信息")
+	}
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics/slo", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("期望状态码 200, 得到 %d", rec.Code)
+	}
+}
+
+func TestWindowExpiry(t *testing.T) {
+	w := newWindow(time.Minute, 10)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	w.add(start, true, false)
+	w.add(start.Add(30*time.Second), false, true)
+
+	total, errs, slow := w.sum(start.Add(30 * time.Second))
+	if total != 2 || errs != 1 || slow != 1 {
+		t.Errorf("窗口内计数不正确: %d %d %d", total, errs, slow)
+	}
+
+	total, _, _ = w.sum(start.Add(80 * time.Second))
+	if total != 1 {
+		t.Errorf("过期时间片应被排除, 得到 %d", total)
+	}
+}
@@ -0,0 +1,59 @@
+package slo
+
+import "time"
+
+// slot 滑动窗口中的一个时间片
+type slot struct {
+	start  time.Time
+	total  int64
+	errors int64
+	slow   int64
+}
+
+// window 由固定数量时间片组成的滑动窗口计数器
+type window struct {
+	slots    []slot
+	slotSize time.Duration
+}
+
+// newWindow 创建滑动窗口
+// size: 窗口总时长
+// n: 时间片数量
+func newWindow(size time.Duration, n int) *window {
+	return &window{
+		slots:    make([]slot, n),
+		slotSize: size / time.Duration(n),
+	}
+}
+
+// add 在当前时间片中记录一次请求
+func (w *window) add(now time.Time, isErr, isSlow bool) {
+	start := now.Truncate(w.slotSize)
+	idx := int(start.UnixNano()/int64(w.slotSize)) % len(w.slots)
+	s := &w.slots[idx]
+	// 时间片已过期，重新计数
+	if !s.start.Equal(start) {
+		*s = slot{start: start}
+	}
+	s.total++
+	if isErr {
+		s.errors++
+	}
+	if isSlow {
+		s.slow++
+	}
+}
+
+// sum 汇总窗口内仍然有效的时间片
+func (w *window) sum(now time.Time) (total, errors, slow int64) {
+	oldest := now.Add(-w.slotSize * time.Duration(len(w.slots)))
+	for _, s := range w.slots {
+		if !s.start.After(oldest) {
+			continue
+		}
+		total += s.total
+		errors += s.errors
+		slow += s.slow
+	}
+	return
+}
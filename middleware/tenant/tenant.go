@@ -0,0 +1,182 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+
+	"github.com/justinwongcn/ant"
+)
+
+var (
+	// ErrInvalidID 租户标识格式不合法
+	ErrInvalidID = errors.New("tenant: 租户标识不合法")
+	// ErrMissing 上下文中没有租户标识
+	ErrMissing = errors.New("tenant: 缺少租户标识")
+)
+
+// idPattern 租户标识的格式：小写字母、数字、- 与 _，以字母或数字开头，最长 63 个字符
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// ID 租户标识
+// 只能通过 ParseID 创建，保证可以安全地用作存储键或表名的一部分
+type ID string
+
+// ParseID 校验并创建租户标识
+func ParseID(s string) (ID, error) {
+	if !idPattern.MatchString(s) {
+		return "", ErrInvalidID
+	}
+	return ID(s), nil
+}
+
+// String 实现 fmt.Stringer 接口
+func (id ID) String() string {
+	return string(id)
+}
+
+type ctxKey struct{}
+
+// NewContext 返回携带租户标识的上下文
+func NewContext(ctx context.Context, id ID) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext 从上下文中读取租户标识
+// 存储层应通过它获取租户，而不是从请求参数中读取
+func FromContext(ctx context.Context) (ID, bool) {
+	id, ok := ctx.Value(ctxKey{}).(ID)
+	return id, ok && id != ""
+}
+
+// Key 返回带租户前缀的存储键，例如 "acme:users:42"
+// 上下文中没有租户时返回 ErrMissing，避免在多租户存储中读写不属于任何租户的数据
+func Key(ctx context.Context, key string) (string, error) {
+	id, ok := FromContext(ctx)
+	if !ok {
+		return "", ErrMissing
+	}
+	return string(id) + ":" + key, nil
+}
+
+// Resolver 从请求中读取租户标识，没有时返回空字符串
+type Resolver func(ctx *ant.Context) string
+
+// FromHeader 从请求头读取租户标识
+func FromHeader(name string) Resolver {
+	return func(ctx *ant.Context) string {
+		return ctx.Req.Header.Get(name)
+	}
+}
+
+// FromClaim 从鉴权中间件写入 ctx.UserValues 的令牌声明中读取租户标识，值需要是 string
+// 与客户端可以任意设置的来源（例如请求头）一起使用时，应通过 MiddlewareBuilder.Require 注册
+func FromClaim(key string) Resolver {
+	return func(ctx *ant.Context) string {
+		id, _ := ctx.UserValues[key].(string)
+		return id
+	}
+}
+
+// MiddlewareBuilder 用于构建租户识别中间件
+// 识别出的租户写入请求的上下文，处理函数与存储层通过 FromContext 读取
+type MiddlewareBuilder struct {
+	resolvers []Resolver
+	// required 必须提供租户标识的来源
+	required []Resolver
+	allowed  map[ID]struct{}
+	optional bool
+}
+
+// NewMiddlewareBuilder 创建一个新的MiddlewareBuilder实例
+// resolvers: 读取租户标识的方式，为空时读取 X-Tenant-ID 请求头
+// 有多个来源时，所有非空的来源必须一致，例如请求头中的租户必须与令牌中的租户相同
+// 注意：为空的来源会被跳过，令牌中没有租户时客户端可以通过请求头选择任意租户，
+// 令牌声明等可信来源应通过 Require 注册
+func NewMiddlewareBuilder(resolvers ...Resolver) *MiddlewareBuilder {
+	if len(resolvers) == 0 {
+		resolvers = []Resolver{FromHeader("X-Tenant-ID")}
+	}
+	return &MiddlewareBuilder{resolvers: resolvers}
+}
+
+// Allow 限定允许的租户，未调用时允许所有格式合法的租户
+func (b *MiddlewareBuilder) Allow(ids ...ID) *MiddlewareBuilder {
+	if b.allowed == nil {
+		b.allowed = make(map[ID]struct{}, len(ids))
+	}
+	for _, id := range ids {
+		b.allowed[id] = struct{}{}
+	}
+	return b
+}
+
+// Require 注册必须提供租户标识的来源，例如 FromClaim
+// 请求中其他来源提供了租户、而必需的来源没有时响应 403，避免客户端绕过令牌自行选择租户
+func (b *MiddlewareBuilder) Require(resolvers ...Resolver) *MiddlewareBuilder {
+	b.required = append(b.required, resolvers...)
+	return b
+}
+
+// Optional 允许请求不携带租户，此时上下文中没有租户标识
+func (b *MiddlewareBuilder) Optional() *MiddlewareBuilder {
+	b.optional = true
+	return b
+}
+
+// Build 构建租户识别中间件
+// 注意：
+// 1. 使用 FromClaim 时需要放在鉴权中间件之内（先注册鉴权中间件）
+// 2. 缺少租户或格式不合法时响应 400，来源不一致、缺少必需的来源或租户不在允许范围内时响应 403
+func (b *MiddlewareBuilder) Build() ant.Middleware {
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			var raw string
+			missing := false
+			for i, r := range slices.Concat(b.required, b.resolvers) {
+				v := r(ctx)
+				if v == "" {
+					missing = missing || i < len(b.required)
+					continue
+				}
+				if raw != "" && v != raw {
+					reject(ctx, http.StatusForbidden, "租户不一致")
+					return
+				}
+				raw = v
+			}
+			if raw == "" {
+				if b.optional {
+					next(ctx)
+					return
+				}
+				reject(ctx, http.StatusBadRequest, "缺少租户标识")
+				return
+			}
+			if missing {
+				reject(ctx, http.StatusForbidden, "缺少必需的租户来源")
+				return
+			}
+			id, err := ParseID(raw)
+			if err != nil {
+				reject(ctx, http.StatusBadRequest, "租户标识不合法")
+				return
+			}
+			if b.allowed != nil {
+				if _, ok := b.allowed[id]; !ok {
+					reject(ctx, http.StatusForbidden, "无权访问该租户")
+					return
+				}
+			}
+			ctx.Req = ctx.Req.WithContext(NewContext(ctx.Req.Context(), id))
+			next(ctx)
+		}
+	}
+}
+
+func reject(ctx *ant.Context, code int, msg string) {
+	ctx.RespStatusCode = code
+	ctx.RespData = []byte(msg)
+}
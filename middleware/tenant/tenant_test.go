@@ -0,0 +1,127 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/justinwongcn/ant"
+)
+
+func newServer(b *MiddlewareBuilder) *ant.HTTPServer {
+	server := ant.NewHTTPServer()
+	server.Use(func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			// 模拟鉴权中间件写入令牌中的租户
+			if claim := ctx.Req.Header.Get("X-Claim"); claim != "" {
+				ctx.UserValues = map[string]any{"tenant": claim}
+			}
+			next(ctx)
+		}
+	}, b.Build())
+	server.Handle("GET /items", func(ctx *ant.Context) {
+		key, err := Key(ctx.Req.Context(), "items")
+		if err != nil {
+			ctx.RespData = []byte("none")
+			return
+		}
+		ctx.RespData = []byte(key)
+	})
+	return server
+}
+
+func call(server *ant.HTTPServer, header, claim string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	if header != "" {
+		req.Header.Set("X-Tenant-ID", header)
+	}
+	if claim != "" {
+		req.Header.Set("X-Claim", claim)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	server := newServer(NewMiddlewareBuilder(FromClaim("tenant"), FromHeader("X-Tenant-ID")).Allow("acme", "globex"))
+
+	testCases := []struct {
+		name     string
+		header   string
+		claim    string
+		wantCode int
+		wantBody string
+	}{
+		{name: "请求头", header: "acme", wantCode: http.StatusOK, wantBody: "acme:items"},
+		{name: "令牌声明", claim: "globex", wantCode: http.StatusOK, wantBody: "globex:items"},
+		{name: "来源一致", header: "acme", claim: "acme", wantCode: http.StatusOK, wantBody: "acme:items"},
+		{name: "来源不一致", header: "globex", claim: "acme", wantCode: http.StatusForbidden},
+		{name: "缺少租户", wantCode: http.StatusBadRequest},
+		{name: "格式不合法", header: "../acme", wantCode: http.StatusBadRequest},
+		{name: "不在允许范围内", header: "initech", wantCode: http.StatusForbidden},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := call(server, tc.header, tc.claim)
+			if w.Code != tc.wantCode {
+				t.Fatalf("状态码应为 %d，实际为 %d", tc.wantCode, w.Code)
+			}
+			if tc.wantBody != "" && w.Body.String() != tc.wantBody {
+				t.Errorf("响应应为 %q，实际为 %q", tc.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTenantRequiredClaim(t *testing.T) {
+	server := newServer(NewMiddlewareBuilder(FromHeader("X-Tenant-ID")).Require(FromClaim("tenant")))
+
+	testCases := []struct {
+		name     string
+		header   string
+		claim    string
+		wantCode int
+	}{
+		{name: "令牌声明", claim: "acme", wantCode: http.StatusOK},
+		{name: "来源一致", header: "acme", claim: "acme", wantCode: http.StatusOK},
+		{name: "令牌中没有租户时不能通过请求头选择", header: "acme", wantCode: http.StatusForbidden},
+		{name: "来源不一致", header: "globex", claim: "acme", wantCode: http.StatusForbidden},
+		{name: "缺少租户", wantCode: http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if w := call(server, tc.header, tc.claim); w.Code != tc.wantCode {
+				t.Errorf("状态码应为 %d，实际为 %d", tc.wantCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestTenantOptional(t *testing.T) {
+	server := newServer(NewMiddlewareBuilder().Optional())
+	if w := call(server, "", ""); w.Code != http.StatusOK || w.Body.String() != "none" {
+		t.Errorf("可选租户时应放行且上下文中没有租户，实际为 %d %q", w.Code, w.Body.String())
+	}
+	if w := call(server, "acme", ""); w.Body.String() != "acme:items" {
+		t.Errorf("默认应读取 X-Tenant-ID 请求头，实际为 %q", w.Body.String())
+	}
+}
+
+func TestParseID(t *testing.T) {
+	for _, s := range []string{"acme", "team-1", "a_b"} {
+		if _, err := ParseID(s); err != nil {
+			t.Errorf("%q 应为合法的租户标识: %v", s, err)
+		}
+	}
+	for _, s := range []string{"", "Acme", "-acme", "a:b", string(make([]byte, 64))} {
+		if _, err := ParseID(s); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("%q 应为不合法的租户标识", s)
+		}
+	}
+	if _, err := Key(context.Background(), "k"); !errors.Is(err, ErrMissing) {
+		t.Errorf("没有租户时 Key 应返回 ErrMissing，实际为 %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package ant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConditionalMiddleware(t *testing.T) {
+	var ran []string
+	mark := func(name string) Middleware {
+		return func(next HandleFunc) HandleFunc {
+			return func(ctx *Context) {
+				ran = append(ran, name)
+				next(ctx)
+			}
+		}
+	}
+	s := NewHTTPServer()
+	s.Use(ForMethods(mark("methods"), http.MethodPost, http.MethodPut),
+		ForContentType(mark("json"), "application/json", "text/*"))
+	s.Handle("/items", func(ctx *Context) {
+		ctx.RespData = []byte("ok")
+	})
+
+	testCases := []struct {
+		name        string
+		method      string
+		contentType string
+		want        string
+	}{
+		{name: "GET 无请求体", method: http.MethodGet, want: ""},
+		{name: "POST JSON", method: http.MethodPost, contentType: "application/json", want: "methods,json"},
+		{name: "带参数与大小写", method: http.MethodPut, contentType: "Application/JSON; charset=utf-8", want: "methods,json"},
+		{name: "通配子类型", method: http.MethodDelete, contentType: "text/plain", want: "json"},
+		{name: "其他类型", method: http.MethodPost, contentType: "multipart/form-data; boundary=x", want: "methods"},
+		{name: "无法解析", method: http.MethodPatch, contentType: ";;", want: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ran = nil
+			req := httptest.NewRequest(tc.method, "/items", strings.NewReader("{}"))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("请求应成功，实际为 %d", w.Code)
+			}
+			if got := strings.Join(ran, ","); got != tc.want {
+				t.Errorf("执行的中间件应为 %q，实际为 %q", tc.want, got)
+			}
+		})
+	}
+}
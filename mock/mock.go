@@ -0,0 +1,196 @@
+// Package mock 根据声明式的路由描述启动模拟服务，不需要编写处理函数
+//
+// 路由描述可以写在 YAML 或 JSON 文件中，例如：
+//
+//	routes:
+//	  - pattern: GET /users/{id:int}
+//	    summary: 获取用户
+//	    headers:
+//	      Content-Type: application/json
+//	    body: '{"id": {{.Path "id"}}, "name": "{{.Query "name"}}"}'
+//	    latency: 50ms
+//	    jitter: 20ms
+//	  - pattern: POST /users
+//	    status: 201
+//	    body: '{{.Body}}'
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/justinwongcn/ant"
+)
+
+// Route 模拟路由的描述
+type Route struct {
+	// Pattern 路由模式，与 ant.HTTPServer.Handle 相同，例如 "GET /users/{id:int}"
+	Pattern string `yaml:"pattern" json:"pattern"`
+	// Summary 简短的说明，写入路由描述，生成的 OpenAPI 文档中可见
+	Summary string `yaml:"summary" json:"summary"`
+	// Status 响应状态码，默认为 200
+	Status int `yaml:"status" json:"status"`
+	// Headers 响应头
+	Headers map[string]string `yaml:"headers" json:"headers"`
+	// Body 响应体模板，使用 text/template 语法，数据为 Request
+	Body string `yaml:"body" json:"body"`
+	// Latency 响应前的固定延迟
+	Latency time.Duration `yaml:"latency" json:"latency"`
+	// Jitter 在 Latency 之上增加的随机延迟的上限
+	Jitter time.Duration `yaml:"jitter" json:"jitter"`
+}
+
+// Spec 模拟服务的描述
+type Spec struct {
+	Routes []Route `yaml:"routes" json:"routes"`
+}
+
+// Request 响应体模板中可以使用的请求数据
+type Request struct {
+	ctx  *ant.Context
+	body []byte
+}
+
+// Method 请求方法
+func (r Request) Method() string {
+	return r.ctx.Req.Method
+}
+
+// Path 路径参数的值，参数不存在时为空
+func (r Request) Path(name string) string {
+	return r.ctx.Req.PathValue(name)
+}
+
+// Query 查询参数的值，参数不存在时为空
+func (r Request) Query(name string) string {
+	return r.ctx.Req.URL.Query().Get(name)
+}
+
+// Header 请求头的值，请求头不存在时为空
+func (r Request) Header(name string) string {
+	return r.ctx.Req.Header.Get(name)
+}
+
+// Body 原始请求体
+func (r Request) Body() string {
+	return string(r.body)
+}
+
+// Load 从 YAML 或 JSON 文件中加载模拟服务的描述
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("mock: 读取描述文件失败: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse 解析 YAML 或 JSON 格式的模拟服务描述，未知的字段视为错误
+// JSON 是 YAML 的子集，两种格式使用同一个解码器，时长使用 time.ParseDuration 的格式，例如 "50ms"
+func Parse(data []byte) (*Spec, error) {
+	var spec Spec
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&spec); err != nil && len(bytes.TrimSpace(data)) > 0 {
+		return nil, fmt.Errorf("mock: 解析描述失败: %w", err)
+	}
+	return &spec, nil
+}
+
+// Mount 将描述中的全部路由注册到服务器
+// 返回值: 任意一个路由的描述不合法时返回错误，此时不会注册任何路由
+// 注意：路由与已注册的路由冲突时 panic，与 ant.HTTPServer.Handle 一致
+func (s *Spec) Mount(server *ant.HTTPServer) error {
+	handlers := make([]ant.HandleFunc, len(s.Routes))
+	for i, route := range s.Routes {
+		h, err := Handler(route)
+		if err != nil {
+			return err
+		}
+		handlers[i] = h
+	}
+	for i, route := range s.Routes {
+		server.Handle(route.Pattern, handlers[i])
+		if route.Summary != "" {
+			server.Describe(route.Pattern, ant.RouteMeta{Summary: route.Summary})
+		}
+	}
+	return nil
+}
+
+// Handler 创建按描述响应的处理函数
+// 返回值: 路由模式为空、状态码或延迟不合法、响应体模板无法解析时返回错误
+// 注意：
+// 1. 延迟期间客户端断开时不再响应
+// 2. 没有设置 Content-Type 时，响应体是合法的JSON则使用 application/json，否则由标准库根据内容推断
+func Handler(route Route) (ant.HandleFunc, error) {
+	switch {
+	case route.Pattern == "":
+		return nil, fmt.Errorf("mock: 路由模式不能为空")
+	case route.Status != 0 && (route.Status < 100 || route.Status > 999):
+		return nil, fmt.Errorf("mock: 路由 %s 的状态码 %d 不合法", route.Pattern, route.Status)
+	case route.Latency < 0 || route.Jitter < 0:
+		return nil, fmt.Errorf("mock: 路由 %s 的延迟不能为负数", route.Pattern)
+	}
+	tpl, err := template.New(route.Pattern).Option("missingkey=error").Parse(route.Body)
+	if err != nil {
+		return nil, fmt.Errorf("mock: 路由 %s 的响应体模板不合法: %w", route.Pattern, err)
+	}
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return func(ctx *ant.Context) {
+		if !wait(ctx, route.Latency, route.Jitter) {
+			return
+		}
+		body, err := io.ReadAll(ctx.Req.Body)
+		if err != nil {
+			ctx.RespStatusCode = http.StatusBadRequest
+			return
+		}
+		var buf bytes.Buffer
+		if err = tpl.Execute(&buf, Request{ctx: ctx, body: body}); err != nil {
+			ctx.RespStatusCode = http.StatusInternalServerError
+			ctx.RespData = []byte(err.Error())
+			return
+		}
+		header := ctx.Resp.Header()
+		for k, v := range route.Headers {
+			header.Set(k, v)
+		}
+		if header.Get("Content-Type") == "" && buf.Len() > 0 && json.Valid(buf.Bytes()) {
+			header.Set("Content-Type", "application/json")
+		}
+		ctx.RespStatusCode = status
+		ctx.RespData = buf.Bytes()
+	}, nil
+}
+
+// wait 等待固定延迟与随机延迟，客户端断开时返回false
+func wait(ctx *ant.Context, latency, jitter time.Duration) bool {
+	d := latency
+	if jitter > 0 {
+		d += rand.N(jitter)
+	}
+	if d == 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Req.Context().Done():
+		return false
+	}
+}
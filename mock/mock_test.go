@@ -0,0 +1,94 @@
+package mock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+const spec = `
+routes:
+  - pattern: GET /users/{id:int}
+    summary: 获取用户
+    body: '{"id": {{.Path "id"}}, "name": "{{.Query "name"}}"}'
+    latency: 20ms
+    jitter: 5ms
+  - pattern: POST /users
+    status: 201
+    headers:
+      Content-Type: text/plain
+      X-Mock: "true"
+    body: '{{.Method}} {{.Header "X-Token"}} {{.Body}}'
+`
+
+func TestMount(t *testing.T) {
+	s, err := Parse([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := ant.NewHTTPServer()
+	if err = s.Mount(server); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/7?name=tom", nil))
+	if w.Code != http.StatusOK || w.Body.String() != `{"id": 7, "name": "tom"}` ||
+		w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("响应不正确: %d %v %s", w.Code, w.Header(), w.Body.String())
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("响应前应等待 latency")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("hello"))
+	req.Header.Set("X-Token", "abc")
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated || w.Body.String() != "POST abc hello" ||
+		w.Header().Get("Content-Type") != "text/plain" || w.Header().Get("X-Mock") != "true" {
+		t.Errorf("响应不正确: %d %v %s", w.Code, w.Header(), w.Body.String())
+	}
+
+	// 路由约束同样生效
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/abc", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("不满足约束时应响应 404，实际为 %d", w.Code)
+	}
+	if meta, ok := server.RouteMeta("GET /users/{id}"); !ok || meta.Summary != "获取用户" {
+		t.Errorf("应写入路由描述，实际为 %+v", meta)
+	}
+}
+
+func TestInvalidSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{name: "未知字段", spec: "routes:\n  - pattern: GET /a\n    code: 200\n"},
+		{name: "模板不合法", spec: "routes:\n  - pattern: GET /a\n    body: '{{.Path'\n"},
+		{name: "状态码不合法", spec: "routes:\n  - pattern: GET /a\n    status: 42\n"},
+		{name: "缺少路由模式", spec: `{"routes": [{"body": "x"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse([]byte(tt.spec))
+			if err == nil {
+				server := ant.NewHTTPServer()
+				err = s.Mount(server)
+				if len(server.Routes()) != 0 {
+					t.Error("描述不合法时不应注册任何路由")
+				}
+			}
+			if err == nil {
+				t.Error("期望返回错误")
+			}
+		})
+	}
+}
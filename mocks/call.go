@@ -0,0 +1,53 @@
+package mocks
+
+import "sync"
+
+// Call 一次方法调用的记录
+type Call struct {
+	// Method 被调用的方法名
+	Method string
+	// Args 调用参数，不包含 context.Context
+	Args []any
+}
+
+// recorder 记录方法调用，所有模拟实现共用
+type recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+// record 记录一次调用
+func (r *recorder) record(method string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Calls 返回按调用顺序排列的全部调用记录
+func (r *recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := make([]Call, len(r.calls))
+	copy(calls, r.calls)
+	return calls
+}
+
+// CallCount 返回指定方法被调用的次数
+func (r *recorder) CallCount(method string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, c := range r.calls {
+		if c.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset 清空调用记录
+func (r *recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+}
@@ -0,0 +1,155 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/justinwongcn/ant"
+	"github.com/justinwongcn/ant/middleware/accesslog"
+	"github.com/justinwongcn/ant/session"
+)
+
+// TestSessionManager 测试模拟的存储与传播器配合会话管理器使用
+func TestSessionManager(t *testing.T) {
+	store := NewStore()
+	propagator := NewPropagator()
+	m := &session.Manager{Store: store, Propagator: propagator, SessCtxKey: "session"}
+
+	w := httptest.NewRecorder()
+	ctx := ant.Context{Req: httptest.NewRequest(http.MethodGet, "/", nil), Resp: w}
+	sess, err := m.InitSession(ctx, "sid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = sess.Set(context.Background(), "user", "tom"); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Header().Get("X-Session-ID"); got != "sid" {
+		t.Errorf("会话ID应注入到响应头，实际 %q", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Session-ID", "sid")
+	got, err := m.GetSession(ant.Context{Req: req, Resp: httptest.NewRecorder()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user, _ := got.Get(context.Background(), "user"); user != "tom" {
+		t.Errorf("会话数据错误，实际 %v", user)
+	}
+
+	if store.CallCount("Generate") != 1 || store.CallCount("Get") != 1 {
+		t.Errorf("存储的调用记录错误: %+v", store.Calls())
+	}
+	calls := propagator.Calls()
+	if len(calls) != 2 || calls[0].Method != "Inject" || calls[1].Method != "Extract" {
+		t.Errorf("传播器的调用记录错误: %+v", calls)
+	}
+}
+
+// TestStoreErrors 测试默认实现的错误与注入的错误
+func TestStoreErrors(t *testing.T) {
+	store := NewStore()
+	if _, err := store.Get(context.Background(), "missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("期望 ErrSessionNotFound，实际 %v", err)
+	}
+	if err := store.Refresh(context.Background(), "missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("期望 ErrSessionNotFound，实际 %v", err)
+	}
+
+	store.Put(NewSession("sid", map[string]any{"k": "v"}))
+	if err := store.Refresh(context.Background(), "sid"); err != nil {
+		t.Errorf("刷新已存在的会话不应出错: %v", err)
+	}
+	if err := store.Remove(context.Background(), "sid"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(context.Background(), "sid"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("删除后期望 ErrSessionNotFound，实际 %v", err)
+	}
+
+	injected := errors.New("存储不可用")
+	store.GenerateFunc = func(ctx context.Context, id string) (session.Session, error) {
+		return nil, injected
+	}
+	if _, err := store.Generate(context.Background(), "sid"); !errors.Is(err, injected) {
+		t.Errorf("期望注入的错误，实际 %v", err)
+	}
+
+	store.Reset()
+	if len(store.Calls()) != 0 {
+		t.Error("Reset 后调用记录应为空")
+	}
+}
+
+// TestPropagator 测试模拟的传播器
+func TestPropagator(t *testing.T) {
+	p := &Propagator{Header: "X-Sid"}
+	if _, err := p.Extract(httptest.NewRequest(http.MethodGet, "/", nil)); !errors.Is(err, ErrSessionIDNotFound) {
+		t.Errorf("期望 ErrSessionIDNotFound，实际 %v", err)
+	}
+	w := httptest.NewRecorder()
+	_ = p.Inject("sid", w)
+	_ = p.Remove(w)
+	if got := w.Header().Get("X-Sid"); got != "" {
+		t.Errorf("Remove 后响应头应被删除，实际 %q", got)
+	}
+}
+
+// TestTemplateEngine 测试模拟的模板引擎
+func TestTemplateEngine(t *testing.T) {
+	engine := &TemplateEngine{Templates: map[string]string{"index": "<h1>hello</h1>"}}
+	server := ant.NewHTTPServer(ant.ServerWithTemplateEngine(engine))
+	server.Handle("GET /", func(ctx *ant.Context) {
+		if err := ctx.RespTemplate("index", map[string]string{"name": "tom"}); err != nil {
+			ctx.RespStatusCode = http.StatusInternalServerError
+		}
+	})
+	server.Handle("GET /missing", func(ctx *ant.Context) {
+		if err := ctx.RespTemplate("missing", nil); err != nil {
+			ctx.RespStatusCode = http.StatusInternalServerError
+		}
+	})
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Body.String() != "<h1>hello</h1>" {
+		t.Errorf("渲染结果错误: %q", w.Body.String())
+	}
+	calls := engine.Calls()
+	if len(calls) != 1 || calls[0].Args[0] != "index" {
+		t.Errorf("调用记录错误: %+v", calls)
+	}
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("模板不存在时期望状态码 500，实际 %d", w.Code)
+	}
+}
+
+// TestLoggerAndReporter 测试日志与错误上报的模拟实现
+func TestLoggerAndReporter(t *testing.T) {
+	logger := &Logger{}
+	server := ant.NewHTTPServer()
+	server.Use(accesslog.NewBuilder().LogFunc(logger.Log).Build())
+	server.Handle("GET /hello", func(ctx *ant.Context) {
+		ctx.RespStatusCode = http.StatusOK
+	})
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/hello", nil))
+
+	lines := logger.Lines()
+	if len(lines) != 1 || !strings.Contains(lines[0], "/hello") {
+		t.Errorf("访问日志错误: %v", lines)
+	}
+
+	reporter := &ErrorReporter{}
+	reporter.Report(context.Background(), &ant.ErrorEvent{Err: errors.New("boom")})
+	if events := reporter.Events(); len(events) != 1 || events[0].Err.Error() != "boom" {
+		t.Errorf("错误事件错误: %+v", events)
+	}
+}
@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/justinwongcn/ant"
+)
+
+// 确保 ErrorReporter 实现了 ant.ErrorReporter 接口
+var _ ant.ErrorReporter = (*ErrorReporter)(nil)
+
+// ErrorReporter ant.ErrorReporter 的模拟实现，保存收到的全部错误事件
+type ErrorReporter struct {
+	mu     sync.Mutex
+	events []*ant.ErrorEvent
+}
+
+// Report 实现 ant.ErrorReporter 接口
+func (r *ErrorReporter) Report(_ context.Context, ev *ant.ErrorEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+}
+
+// Events 返回按上报顺序排列的错误事件
+func (r *ErrorReporter) Events() []*ant.ErrorEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := make([]*ant.ErrorEvent, len(r.events))
+	copy(events, r.events)
+	return events
+}
+
+// Logger 记录日志行的模拟实现
+// 可以作为访问日志等中间件的日志函数，例如 accesslog.NewBuilder().LogFunc(logger.Log)
+type Logger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// Log 记录一行日志
+func (l *Logger) Log(line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, line)
+}
+
+// Lines 返回按记录顺序排列的日志行
+func (l *Logger) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lines := make([]string, len(l.lines))
+	copy(lines, l.lines)
+	return lines
+}
@@ -0,0 +1,220 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/justinwongcn/ant/session"
+)
+
+// ErrSessionNotFound 模拟存储中不存在指定的会话
+var ErrSessionNotFound = errors.New("mocks: 会话不存在")
+
+// ErrSessionIDNotFound 请求中没有携带会话ID
+var ErrSessionIDNotFound = errors.New("mocks: 请求中没有会话ID")
+
+// 确保模拟实现满足对应的接口
+var (
+	_ session.Session    = (*Session)(nil)
+	_ session.Store      = (*Store)(nil)
+	_ session.Propagator = (*Propagator)(nil)
+)
+
+// Session session.Session 的模拟实现，数据保存在内存中
+type Session struct {
+	recorder
+	// SessionID 会话ID
+	SessionID string
+	// GetFunc 非nil时替代默认的 Get 实现，例如用于注入错误
+	GetFunc func(ctx context.Context, key string) (any, error)
+	// SetFunc 非nil时替代默认的 Set 实现
+	SetFunc func(ctx context.Context, key string, value any) error
+
+	mu     sync.RWMutex
+	values map[string]any
+}
+
+// NewSession 创建模拟会话
+// id: 会话ID
+// values: 会话的初始数据，可以为nil
+func NewSession(id string, values map[string]any) *Session {
+	s := &Session{SessionID: id, values: make(map[string]any, len(values))}
+	for k, v := range values {
+		s.values[k] = v
+	}
+	return s
+}
+
+// Get 实现 session.Session 接口，键不存在时返回 ErrSessionNotFound
+func (s *Session) Get(ctx context.Context, key string) (any, error) {
+	s.record("Get", key)
+	if s.GetFunc != nil {
+		return s.GetFunc(ctx, key)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, ok := s.values[key]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return val, nil
+}
+
+// Set 实现 session.Session 接口
+func (s *Session) Set(ctx context.Context, key string, value any) error {
+	s.record("Set", key, value)
+	if s.SetFunc != nil {
+		return s.SetFunc(ctx, key, value)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values == nil {
+		s.values = make(map[string]any)
+	}
+	s.values[key] = value
+	return nil
+}
+
+// ID 实现 session.Session 接口
+func (s *Session) ID() string {
+	return s.SessionID
+}
+
+// Store session.Store 的模拟实现
+// 默认行为是不会过期的内存存储，各 Func 字段非nil时替代对应方法的默认实现
+type Store struct {
+	recorder
+	GenerateFunc func(ctx context.Context, id string) (session.Session, error)
+	RefreshFunc  func(ctx context.Context, id string) error
+	RemoveFunc   func(ctx context.Context, id string) error
+	GetFunc      func(ctx context.Context, id string) (session.Session, error)
+
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+// NewStore 创建模拟会话存储
+func NewStore() *Store {
+	return &Store{sessions: make(map[string]*Session)}
+}
+
+// Put 直接放入一个会话，用于准备测试数据
+func (s *Store) Put(sess *Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions == nil {
+		s.sessions = make(map[string]*Session)
+	}
+	s.sessions[sess.SessionID] = sess
+}
+
+// Generate 实现 session.Store 接口
+func (s *Store) Generate(ctx context.Context, id string) (session.Session, error) {
+	s.record("Generate", id)
+	if s.GenerateFunc != nil {
+		return s.GenerateFunc(ctx, id)
+	}
+	sess := NewSession(id, nil)
+	s.Put(sess)
+	return sess, nil
+}
+
+// Refresh 实现 session.Store 接口，会话不存在时返回 ErrSessionNotFound
+func (s *Store) Refresh(ctx context.Context, id string) error {
+	s.record("Refresh", id)
+	if s.RefreshFunc != nil {
+		return s.RefreshFunc(ctx, id)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.sessions[id]; !ok {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// Remove 实现 session.Store 接口
+func (s *Store) Remove(ctx context.Context, id string) error {
+	s.record("Remove", id)
+	if s.RemoveFunc != nil {
+		return s.RemoveFunc(ctx, id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// Get 实现 session.Store 接口，会话不存在时返回 ErrSessionNotFound
+func (s *Store) Get(ctx context.Context, id string) (session.Session, error) {
+	s.record("Get", id)
+	if s.GetFunc != nil {
+		return s.GetFunc(ctx, id)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return sess, nil
+}
+
+// Propagator session.Propagator 的模拟实现
+// 默认通过 Header 字段指定的请求头与响应头传递会话ID，各 Func 字段非nil时替代对应方法的默认实现
+type Propagator struct {
+	recorder
+	// Header 传递会话ID的头部，默认为 "X-Session-ID"
+	Header      string
+	InjectFunc  func(id string, writer http.ResponseWriter) error
+	ExtractFunc func(req *http.Request) (string, error)
+	RemoveFunc  func(writer http.ResponseWriter) error
+}
+
+// NewPropagator 创建模拟会话传播器
+func NewPropagator() *Propagator {
+	return &Propagator{Header: "X-Session-ID"}
+}
+
+// header 返回传递会话ID的头部
+func (p *Propagator) header() string {
+	if p.Header == "" {
+		return "X-Session-ID"
+	}
+	return p.Header
+}
+
+// Inject 实现 session.Propagator 接口
+func (p *Propagator) Inject(id string, writer http.ResponseWriter) error {
+	p.record("Inject", id)
+	if p.InjectFunc != nil {
+		return p.InjectFunc(id, writer)
+	}
+	writer.Header().Set(p.header(), id)
+	return nil
+}
+
+// Extract 实现 session.Propagator 接口，请求没有携带会话ID时返回 ErrSessionIDNotFound
+func (p *Propagator) Extract(req *http.Request) (string, error) {
+	p.record("Extract")
+	if p.ExtractFunc != nil {
+		return p.ExtractFunc(req)
+	}
+	id := req.Header.Get(p.header())
+	if id == "" {
+		return "", ErrSessionIDNotFound
+	}
+	return id, nil
+}
+
+// Remove 实现 session.Propagator 接口
+func (p *Propagator) Remove(writer http.ResponseWriter) error {
+	p.record("Remove")
+	if p.RemoveFunc != nil {
+		return p.RemoveFunc(writer)
+	}
+	writer.Header().Del(p.header())
+	return nil
+}
@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/justinwongcn/ant"
+)
+
+// 确保 TemplateEngine 实现了 ant.TemplateEngine 接口
+var _ ant.TemplateEngine = (*TemplateEngine)(nil)
+
+// TemplateEngine ant.TemplateEngine 的模拟实现
+// 默认返回 Templates 中对应模板名的内容，模板不存在时返回错误
+type TemplateEngine struct {
+	recorder
+	// Templates 模板名到渲染结果的映射
+	Templates map[string]string
+	// RenderFunc 非nil时替代默认的 Render 实现，例如用于注入错误
+	RenderFunc func(ctx context.Context, tplName string, data any) ([]byte, error)
+}
+
+// Render 实现 ant.TemplateEngine 接口
+func (e *TemplateEngine) Render(ctx context.Context, tplName string, data any) ([]byte, error) {
+	e.record("Render", tplName, data)
+	if e.RenderFunc != nil {
+		return e.RenderFunc(ctx, tplName, data)
+	}
+	out, ok := e.Templates[tplName]
+	if !ok {
+		return nil, fmt.Errorf("mocks: 模板 %s 不存在", tplName)
+	}
+	return []byte(out), nil
+}
@@ -0,0 +1,21 @@
+package ant
+
+import (
+	"os"
+	"strings"
+)
+
+// ModeEnv 指定运行模式的环境变量
+// 取值为 "development" 或 "dev" 时为开发模式，其他取值或未设置时为生产模式
+const ModeEnv = "ANT_MODE"
+
+// DevMode 判断当前是否为开发模式
+// 开发模式下会启用详细的错误页面等只适合本地调试的功能，生产环境不应开启
+func DevMode() bool {
+	switch strings.ToLower(os.Getenv(ModeEnv)) {
+	case "development", "dev":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,191 @@
+package ant
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+)
+
+// ErrMultipartTooLarge multipart 请求中的文件或表单值超出了大小限制
+var ErrMultipartTooLarge = errors.New("web: multipart 内容超出大小限制")
+
+// defaultMultipartMaxMemory 与标准库 ParseMultipartForm 的默认值一致
+const defaultMultipartMaxMemory = 32 << 20
+
+// maxMultipartValueSize 非文件表单值的总大小上限，与标准库一致
+const maxMultipartValueSize = 10 << 20
+
+// MultipartOptions 解析 multipart 请求体的选项
+type MultipartOptions struct {
+	// MaxMemory 文件内容保存在内存中的总大小上限，超过后写入临时文件，默认为 32MB
+	MaxMemory int64
+	// TempDir 临时文件所在的目录，默认为 os.TempDir()
+	TempDir string
+	// MaxFileSize 单个文件的大小上限，超过时返回 ErrMultipartTooLarge，为0时不限制
+	MaxFileSize int64
+}
+
+// MultipartForm 解析后的 multipart 表单
+type MultipartForm struct {
+	// Value 非文件的表单值
+	Value map[string][]string
+	// File 上传的文件，按字段名分组
+	File map[string][]*UploadedFile
+}
+
+// UploadedFile 上传的文件，内容保存在内存或临时文件中
+type UploadedFile struct {
+	// Filename 客户端提供的文件名，已去掉目录部分
+	Filename string
+	// Header 文件部分的头部，例如 Content-Type
+	Header textproto.MIMEHeader
+	// Size 文件的字节数
+	Size int64
+
+	content []byte
+	tmpFile string
+}
+
+// Open 打开文件内容
+func (f *UploadedFile) Open() (multipart.File, error) {
+	if f.tmpFile != "" {
+		return os.Open(f.tmpFile)
+	}
+	return sectionReadCloser{io.NewSectionReader(bytes.NewReader(f.content), 0, int64(len(f.content)))}, nil
+}
+
+// sectionReadCloser 为内存中的文件内容实现 multipart.File 接口
+type sectionReadCloser struct {
+	*io.SectionReader
+}
+
+func (sectionReadCloser) Close() error {
+	return nil
+}
+
+// RemoveAll 删除所有临时文件，可以重复调用
+func (f *MultipartForm) RemoveAll() error {
+	var errs []error
+	for _, files := range f.File {
+		for _, file := range files {
+			if file.tmpFile == "" {
+				continue
+			}
+			if err := os.Remove(file.tmpFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			file.tmpFile = ""
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// MultipartForm 流式解析 multipart 请求体
+// 与 Request.ParseMultipartForm 相比，可以控制内存阈值、临时文件目录与单个文件的大小
+// 注意：
+// 1. 同一个请求只解析一次，之后的调用返回第一次的结果，opts 不再生效
+// 2. 临时文件在请求结束后删除，即使处理函数 panic 也会删除
+// 3. 超出大小限制时返回 ErrMultipartTooLarge，已经写入的临时文件会被立即删除
+func (c *Context) MultipartForm(opts MultipartOptions) (*MultipartForm, error) {
+	if c.multipartForm != nil {
+		return c.multipartForm, nil
+	}
+	reader, err := c.Req.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	maxMemory := opts.MaxMemory
+	if maxMemory <= 0 {
+		maxMemory = defaultMultipartMaxMemory
+	}
+
+	form := &MultipartForm{Value: make(map[string][]string), File: make(map[string][]*UploadedFile)}
+	valueBudget := int64(maxMultipartValueSize)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			_ = form.RemoveAll()
+			return nil, err
+		}
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+		if part.FileName() == "" {
+			var buf bytes.Buffer
+			n, err := io.CopyN(&buf, part, valueBudget+1)
+			if err != nil && !errors.Is(err, io.EOF) {
+				_ = form.RemoveAll()
+				return nil, err
+			}
+			if valueBudget -= n; valueBudget < 0 {
+				_ = form.RemoveAll()
+				return nil, ErrMultipartTooLarge
+			}
+			form.Value[name] = append(form.Value[name], buf.String())
+			continue
+		}
+
+		file, err := readUploadedFile(part, &maxMemory, opts)
+		// 先加入表单，出错时由 RemoveAll 删除已写入的临时文件
+		if file != nil {
+			form.File[name] = append(form.File[name], file)
+		}
+		if err != nil {
+			_ = form.RemoveAll()
+			return nil, err
+		}
+	}
+	c.multipartForm = form
+	return form, nil
+}
+
+// readUploadedFile 读取文件部分，内存预算不足时写入临时文件
+// memory: 剩余的内存预算，读取后扣减
+func readUploadedFile(part *multipart.Part, memory *int64, opts MultipartOptions) (*UploadedFile, error) {
+	file := &UploadedFile{Filename: part.FileName(), Header: part.Header}
+	var src io.Reader = part
+	if opts.MaxFileSize > 0 {
+		src = io.LimitReader(part, opts.MaxFileSize+1)
+	}
+
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, src, *memory+1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if n <= *memory {
+		if opts.MaxFileSize > 0 && n > opts.MaxFileSize {
+			return nil, ErrMultipartTooLarge
+		}
+		*memory -= n
+		file.content = buf.Bytes()
+		file.Size = n
+		return file, nil
+	}
+
+	// 超出内存预算，写入临时文件
+	tmp, err := os.CreateTemp(opts.TempDir, "ant-multipart-")
+	if err != nil {
+		return nil, err
+	}
+	file.tmpFile = tmp.Name()
+	size, err := io.Copy(tmp, io.MultiReader(&buf, src))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return file, err
+	}
+	if opts.MaxFileSize > 0 && size > opts.MaxFileSize {
+		return file, ErrMultipartTooLarge
+	}
+	file.Size = size
+	return file, nil
+}
@@ -0,0 +1,136 @@
+package ant
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// multipartRequest 构造包含一个表单值与多个文件的请求
+func multipartRequest(t *testing.T, files map[string]string) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	if err := w.WriteField("title", "报告"); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		part, err := w.CreateFormFile("file", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.WriteString(part, content)
+	}
+	_ = w.Close()
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func tempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestMultipartForm(t *testing.T) {
+	tmpDir := t.TempDir()
+	opts := MultipartOptions{MaxMemory: 8, TempDir: tmpDir}
+
+	var spilled []string
+	s := NewHTTPServer()
+	s.Handle("POST /upload", func(ctx *Context) {
+		form, err := ctx.MultipartForm(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again, _ := ctx.MultipartForm(MultipartOptions{}); again != form {
+			t.Error("同一个请求应只解析一次")
+		}
+		if form.Value["title"][0] != "报告" {
+			t.Errorf("表单值应为 报告，实际为 %v", form.Value["title"])
+		}
+		for _, fh := range form.File["file"] {
+			f, err := fh.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, _ := io.ReadAll(f)
+			_ = f.Close()
+			if int64(len(content)) != fh.Size {
+				t.Errorf("%s 的大小应为 %d，实际读取 %d", fh.Filename, fh.Size, len(content))
+			}
+		}
+		spilled = tempFiles(t, tmpDir)
+		if ctx.Req.URL.Query().Get("panic") != "" {
+			panic("boom")
+		}
+	})
+
+	// small.txt 放在内存中，big.txt 超出内存阈值写入临时文件
+	req := multipartRequest(t, map[string]string{"small.txt": "tiny", "big.txt": strings.Repeat("x", 100)})
+	s.ServeHTTP(httptest.NewRecorder(), req)
+	if len(spilled) != 1 || !strings.HasPrefix(spilled[0], "ant-multipart-") {
+		t.Errorf("超出内存阈值的文件应写入 TempDir，实际为 %v", spilled)
+	}
+	if left := tempFiles(t, tmpDir); len(left) != 0 {
+		t.Errorf("请求结束后应删除临时文件，剩余 %v", left)
+	}
+
+	// 处理函数 panic 时同样删除
+	req = multipartRequest(t, map[string]string{"big.txt": strings.Repeat("x", 100)})
+	req.URL.RawQuery = "panic=1"
+	if err := catchPanic(func() { s.ServeHTTP(httptest.NewRecorder(), req) }); err == nil {
+		t.Fatal("处理函数应 panic")
+	}
+	if left := tempFiles(t, tmpDir); len(left) != 0 {
+		t.Errorf("处理函数 panic 后应删除临时文件，剩余 %v", left)
+	}
+}
+
+func TestMultipartMaxFileSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, maxMemory := range []int64{1 << 20, 4} {
+		ctx := &Context{Req: multipartRequest(t, map[string]string{"big.txt": strings.Repeat("x", 100)})}
+		_, err := ctx.MultipartForm(MultipartOptions{MaxMemory: maxMemory, TempDir: tmpDir, MaxFileSize: 50})
+		if !errors.Is(err, ErrMultipartTooLarge) {
+			t.Errorf("内存阈值为 %d 时应返回 ErrMultipartTooLarge，实际为 %v", maxMemory, err)
+		}
+		if left := tempFiles(t, tmpDir); len(left) != 0 {
+			t.Errorf("超出大小限制时应立即删除临时文件，剩余 %v", left)
+		}
+	}
+
+	dstDir := t.TempDir()
+	uploader := &FileUploader{
+		FileField:   "file",
+		DstPathFunc: func(fh *multipart.FileHeader) string { return filepath.Join(dstDir, fh.Filename) },
+		Multipart:   MultipartOptions{MaxFileSize: 50},
+	}
+	s := NewHTTPServer()
+	s.Handle("POST /upload", uploader.Handle())
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, multipartRequest(t, map[string]string{"big.txt": strings.Repeat("x", 100)}))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("超出 MaxFileSize 应响应 413，实际为 %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, multipartRequest(t, map[string]string{"ok.txt": "hello"}))
+	if w.Code != http.StatusOK {
+		t.Errorf("未超出限制时应上传成功，实际为 %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,154 @@
+package ant
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNDJSONTooManyErrors 出错的记录数超出了 NDJSONOptions.MaxErrors
+var ErrNDJSONTooManyErrors = errors.New("web: NDJSON 出错的记录过多")
+
+// NDJSONErrorPolicy 单条记录解析或处理失败时的处理方式
+type NDJSONErrorPolicy int
+
+const (
+	// NDJSONAbort 遇到第一条出错的记录时停止，默认值
+	NDJSONAbort NDJSONErrorPolicy = iota
+	// NDJSONSkip 跳过出错的记录并记录错误，继续处理后续记录
+	NDJSONSkip
+)
+
+// defaultNDJSONMaxLineSize 单行的默认大小上限
+const defaultNDJSONMaxLineSize = 1 << 20
+
+// maxNDJSONErrors NDJSONResult 中最多保留的错误数量
+const maxNDJSONErrors = 100
+
+// NDJSONOptions 流式读取 NDJSON 请求体的选项
+type NDJSONOptions struct {
+	// OnError 单条记录出错时的处理方式，默认为 NDJSONAbort
+	OnError NDJSONErrorPolicy
+	// MaxErrors 使用 NDJSONSkip 时最多允许的出错记录数，超过后返回 ErrNDJSONTooManyErrors，为0时不限制
+	MaxErrors int
+	// MaxLineSize 单行的大小上限，超出的行视为出错的记录，默认为 1MB
+	MaxLineSize int
+}
+
+// NDJSONLineError 某一行记录的错误
+type NDJSONLineError struct {
+	// Line 行号，从1开始
+	Line int
+	// Err 解析或处理的错误
+	Err error
+}
+
+// Error 实现 error 接口
+func (e *NDJSONLineError) Error() string {
+	return fmt.Sprintf("web: NDJSON 第 %d 行: %v", e.Line, e.Err)
+}
+
+// Unwrap 返回原始错误
+func (e *NDJSONLineError) Unwrap() error {
+	return e.Err
+}
+
+// NDJSONResult 流式读取的统计
+type NDJSONResult struct {
+	// Processed 处理成功的记录数
+	Processed int `json:"processed"`
+	// Failed 出错的记录数
+	Failed int `json:"failed"`
+	// Errors 出错记录的详情，最多保留前 100 条
+	Errors []*NDJSONLineError `json:"-"`
+}
+
+// BindNDJSONStream 逐行解析 NDJSON（换行分隔的JSON）请求体，每解析一条记录调用一次 fn
+// 请求体不会被整体读入内存，适合接收大量记录的批量导入接口
+// 空行会被忽略，未知字段会被忽略
+// 返回值:
+// - 处理的统计，出错时同样返回已处理的部分
+// - 使用 NDJSONAbort 时为第一条出错记录的 *NDJSONLineError，
+// 出错记录超出 MaxErrors 时为 ErrNDJSONTooManyErrors，读取请求体失败时为读取的错误
+//
+// 例如：
+//
+//	res, err := ant.BindNDJSONStream(ctx, ant.NDJSONOptions{OnError: ant.NDJSONSkip}, func(u User) error {
+//		return repo.Save(ctx.Req.Context(), u)
+//	})
+func BindNDJSONStream[T any](c *Context, opts NDJSONOptions, fn func(item T) error) (NDJSONResult, error) {
+	var res NDJSONResult
+	if c.Req.Body == nil {
+		return res, nil
+	}
+	maxLineSize := opts.MaxLineSize
+	if maxLineSize <= 0 {
+		maxLineSize = defaultNDJSONMaxLineSize
+	}
+	codec := c.JSONCodec()
+	reader := bufio.NewReaderSize(c.Req.Body, min(maxLineSize, 64<<10))
+	var line []byte
+	for lineNo := 1; ; lineNo++ {
+		var tooLong bool
+		var err error
+		line, tooLong, err = readNDJSONLine(reader, line[:0], maxLineSize)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return res, err
+		}
+		eof := err != nil
+
+		var itemErr error
+		if tooLong {
+			itemErr = fmt.Errorf("行的大小超出 %d 字节", maxLineSize)
+		} else if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			var item T
+			if itemErr = codec.Unmarshal(trimmed, &item); itemErr == nil {
+				itemErr = fn(item)
+			}
+			if itemErr == nil {
+				res.Processed++
+			}
+		}
+		if itemErr != nil {
+			lineErr := &NDJSONLineError{Line: lineNo, Err: itemErr}
+			res.Failed++
+			if len(res.Errors) < maxNDJSONErrors {
+				res.Errors = append(res.Errors, lineErr)
+			}
+			if opts.OnError == NDJSONAbort {
+				return res, lineErr
+			}
+			if opts.MaxErrors > 0 && res.Failed > opts.MaxErrors {
+				return res, ErrNDJSONTooManyErrors
+			}
+		}
+		if eof {
+			return res, nil
+		}
+	}
+}
+
+// readNDJSONLine 读取一行并追加到 buf，超出 maxSize 时丢弃该行剩余的内容并返回 tooLong
+func readNDJSONLine(r *bufio.Reader, buf []byte, maxSize int) (line []byte, tooLong bool, err error) {
+	for {
+		frag, err := r.ReadSlice('\n')
+		if !tooLong {
+			// 多保留换行符 "\r\n" 的两个字节
+			if len(buf)+len(frag) > maxSize+2 {
+				tooLong = true
+				buf = buf[:0]
+			} else {
+				buf = append(buf, frag...)
+			}
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			if !tooLong && len(bytes.TrimRight(buf, "\r\n")) > maxSize {
+				tooLong = true
+				buf = buf[:0]
+			}
+			return buf, tooLong, err
+		}
+	}
+}
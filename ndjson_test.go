@@ -0,0 +1,70 @@
+package ant
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type ndjsonItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestBindNDJSONStream(t *testing.T) {
+	body := `{"id":1,"name":"a"}` + "\n\n" +
+		`{"id":2,` + "\n" +
+		`{"id":3,"name":"` + strings.Repeat("x", 100) + `"}` + "\r\n" +
+		`{"id":-1,"name":"bad"}` + "\n" +
+		`{"id":5,"name":"e"}`
+	newCtx := func() *Context {
+		return &Context{Req: httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(body))}
+	}
+	errNegative := errors.New("id 不能为负数")
+	var got []int
+	handle := func(item ndjsonItem) error {
+		if item.ID < 0 {
+			return errNegative
+		}
+		got = append(got, item.ID)
+		return nil
+	}
+
+	// 默认在第一条出错的记录处停止
+	res, err := BindNDJSONStream(newCtx(), NDJSONOptions{}, handle)
+	var lineErr *NDJSONLineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 3 {
+		t.Fatalf("应在第 3 行停止，实际为 %v", err)
+	}
+	if res.Processed != 1 || len(got) != 1 {
+		t.Errorf("停止前应处理 1 条记录，实际为 %+v %v", res, got)
+	}
+
+	// 跳过出错的记录
+	got = nil
+	res, err = BindNDJSONStream(newCtx(), NDJSONOptions{OnError: NDJSONSkip, MaxLineSize: 64}, handle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Processed != 2 || res.Failed != 3 || len(got) != 2 || got[1] != 5 {
+		t.Errorf("应处理 2 条并跳过 3 条，实际为 %+v %v", res, got)
+	}
+	lines := make([]int, 0, len(res.Errors))
+	for _, e := range res.Errors {
+		lines = append(lines, e.Line)
+	}
+	if len(lines) != 3 || lines[0] != 3 || lines[1] != 4 || lines[2] != 5 {
+		t.Errorf("出错的行应为 3、4、5，实际为 %v", lines)
+	}
+	if !errors.Is(res.Errors[2], errNegative) {
+		t.Errorf("处理函数的错误应被保留，实际为 %v", res.Errors[2])
+	}
+
+	// 出错记录过多
+	_, err = BindNDJSONStream(newCtx(), NDJSONOptions{OnError: NDJSONSkip, MaxLineSize: 64, MaxErrors: 1}, handle)
+	if !errors.Is(err, ErrNDJSONTooManyErrors) {
+		t.Errorf("应返回 ErrNDJSONTooManyErrors，实际为 %v", err)
+	}
+}
@@ -0,0 +1,286 @@
+package ant
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAPIInfo 接口文档的基本信息
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIDocument OpenAPI 3.0 文档，只包含由路由与 RouteMeta 生成的部分
+type OpenAPIDocument struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       OpenAPIInfo                      `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas,omitempty"`
+	} `json:"components"`
+}
+
+// Operation 文档中的一个操作
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+
+	// 以下为扩展字段，由 RouteMeta.Deprecation 生成，分别为停止服务的时间（RFC 3339）与替代接口
+	XSunset    string `json:"x-sunset,omitempty"`
+	XSuccessor string `json:"x-successor,omitempty"`
+}
+
+// Parameter 文档中的参数，目前只生成路径参数
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody 文档中的请求体
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// Response 文档中的响应
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType 某种内容类型的结构与示例
+type MediaType struct {
+	Schema  *Schema `json:"schema,omitempty"`
+	Example any     `json:"example,omitempty"`
+}
+
+// Schema 数据结构的描述
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+
+	// 以下为校验关键字，由 ValidateSchema 检查并输出到文档中
+	Enum      []any    `json:"enum,omitempty"`
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	MinItems  *int     `json:"minItems,omitempty"`
+	MaxItems  *int     `json:"maxItems,omitempty"`
+}
+
+// OpenAPI 根据已注册的路由与 RouteMeta 生成接口文档
+// 1. 只包含带有方法的路由，GET 路由不会额外生成 HEAD 操作
+// 2. 路径参数 {name} 与 {name...} 都生成为必填的参数，默认为字符串，带有约束时使用约束对应的类型，{$} 会被去掉
+// 3. 请求体与响应的结构通过反射 Body.Type 得到，具名结构体放在 components.schemas 中引用
+// 4. 设置了 RouteMeta.Deprecation 的操作标记为 deprecated，停止服务的时间与替代接口写入 x-sunset 与 x-successor
+func (s *HTTPServer) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   make(map[string]map[string]*Operation),
+	}
+	gen := schemaGenerator{schemas: make(map[string]*Schema)}
+
+	for _, pattern := range s.Routes() {
+		method, _, segments := splitPattern(pattern)
+		if method == "" {
+			continue
+		}
+		meta, _ := s.RouteMeta(pattern)
+		s.routeMu.Lock()
+		constraints := s.routes.constraints[pattern]
+		s.routeMu.Unlock()
+		op := &Operation{
+			OperationID: meta.OperationID,
+			Summary:     meta.Summary,
+			Description: meta.Description,
+			Tags:        meta.Tags,
+			Deprecated:  meta.Deprecated || meta.Deprecation != nil,
+			Responses:   make(map[string]*Response),
+		}
+		if d := meta.Deprecation; d != nil {
+			op.XSuccessor = d.Successor
+			if !d.Sunset.IsZero() {
+				op.XSunset = d.Sunset.UTC().Format(time.RFC3339)
+			}
+		}
+
+		for i, seg := range segments {
+			if !strings.HasPrefix(seg, "{") {
+				continue
+			}
+			name := strings.TrimSuffix(strings.Trim(seg, "{}"), "...")
+			segments[i] = "{" + name + "}"
+			schema := &Schema{Type: "string"}
+			for _, c := range constraints {
+				if c.name == name {
+					schema = c.schema()
+				}
+			}
+			op.Parameters = append(op.Parameters, Parameter{
+				Name: name, In: "path", Required: true, Schema: schema,
+			})
+		}
+		if meta.Request != nil {
+			op.RequestBody = &RequestBody{
+				Description: meta.Request.Description,
+				Required:    true,
+				Content:     gen.content(*meta.Request),
+			}
+		}
+		for code, body := range meta.Responses {
+			desc := body.Description
+			if desc == "" {
+				desc = http.StatusText(code)
+			}
+			op.Responses[strconv.Itoa(code)] = &Response{Description: desc, Content: gen.content(body)}
+		}
+		if len(op.Responses) == 0 {
+			op.Responses["200"] = &Response{Description: http.StatusText(http.StatusOK)}
+		}
+
+		path := "/" + strings.Join(segments, "/")
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*Operation)
+		}
+		doc.Paths[path][strings.ToLower(method)] = op
+	}
+
+	if len(gen.schemas) > 0 {
+		doc.Components.Schemas = gen.schemas
+	}
+	return doc
+}
+
+// OpenAPIHandler 返回以 JSON 响应接口文档的处理函数
+// 文档在每次请求时生成，会包含之后注册的路由
+func (s *HTTPServer) OpenAPIHandler(info OpenAPIInfo) HandleFunc {
+	return func(ctx *Context) {
+		_ = ctx.RespJSONOK(s.OpenAPI(info))
+	}
+}
+
+// timeType time.Time 按 RFC 3339 字符串序列化
+var timeType = reflect.TypeFor[time.Time]()
+
+// schemaGenerator 通过反射生成 Schema，记录遇到的具名结构体
+type schemaGenerator struct {
+	schemas map[string]*Schema
+}
+
+// content 生成 Body 对应的内容描述
+func (g schemaGenerator) content(body Body) map[string]MediaType {
+	if body.Schema == nil && body.Type == nil && body.Example == nil {
+		return nil
+	}
+	ct := body.ContentType
+	if ct == "" {
+		ct = "application/json"
+	}
+	mt := MediaType{Example: body.Example}
+	if body.Schema != nil {
+		mt.Schema = body.Schema
+	} else if body.Type != nil {
+		mt.Schema = g.schema(body.Type)
+	}
+	return map[string]MediaType{ct: mt}
+}
+
+// schema 生成类型对应的 Schema
+func (g schemaGenerator) schema(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time", Nullable: nullable}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean", Nullable: nullable}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Nullable: nullable}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Nullable: nullable}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float", Nullable: nullable}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double", Nullable: nullable}
+	case reflect.String:
+		return &Schema{Type: "string", Nullable: nullable}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json 将 []byte 序列化为 base64 字符串
+			return &Schema{Type: "string", Format: "byte", Nullable: nullable}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem()), Nullable: nullable}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem()), Nullable: nullable}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := t.Name()
+		if _, ok := g.schemas[name]; !ok {
+			// 先占位，处理自引用的结构体
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name, Nullable: nullable}
+	default:
+		// interface 等无法确定结构的类型
+		return &Schema{}
+	}
+}
+
+// object 按 encoding/json 的规则生成结构体的 Schema
+// 没有 omitempty 的字段视为必填
+func (g schemaGenerator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, f := range reflect.VisibleFields(t) {
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" && opts == "" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			// 嵌入结构体的字段已由 VisibleFields 提升
+			if ft := f.Type; ft.Kind() == reflect.Struct || ft.Kind() == reflect.Pointer && ft.Elem().Kind() == reflect.Struct {
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = g.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	sort.Strings(s.Required)
+	return s
+}
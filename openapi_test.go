@@ -0,0 +1,110 @@
+package ant
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// treeNode 自引用的结构体
+type treeNode struct {
+	Name     string      `json:"name"`
+	Children []*treeNode `json:"children,omitempty"`
+	Created  time.Time   `json:"created"`
+	Internal string      `json:"-"`
+}
+
+// TestOpenAPI 测试由路由与描述信息生成接口文档
+func TestOpenAPI(t *testing.T) {
+	s := NewHTTPServer()
+	noop := func(ctx *Context) {}
+	s.Handle("POST /users", noop)
+	s.Handle("GET /users/{id}", noop)
+	s.Handle("GET /files/{path...}", noop)
+	s.Handle("/any", noop)
+	s.Handle("GET /tree", noop)
+
+	s.Describe("POST /users", RouteMeta{
+		OperationID: "createUser",
+		Request:     &Body{Description: "新用户", Type: reflect.TypeFor[createUserReq](), Example: createUserReq{Name: "tom"}},
+		Responses: map[int]Body{
+			http.StatusCreated:    *BodyOf[userResp]("已创建"),
+			http.StatusBadRequest: {},
+		},
+	})
+	s.UpdateRouteMeta("GET /users/{id}", func(meta *RouteMeta) {
+		meta.Deprecated = true
+	})
+	s.Describe("GET /tree", RouteMeta{Responses: map[int]Body{http.StatusOK: *BodyOf[[]treeNode]("")}})
+
+	doc := s.OpenAPI(OpenAPIInfo{Title: "测试", Version: "1.0"})
+	if _, ok := doc.Paths["/any"]; ok {
+		t.Error("没有方法的路由不应出现在文档中")
+	}
+
+	create := doc.Paths["/users"]["post"]
+	if create == nil || create.OperationID != "createUser" {
+		t.Fatalf("缺少 POST /users 操作: %+v", doc.Paths["/users"])
+	}
+	req := create.RequestBody.Content["application/json"]
+	if req.Schema.Ref != "#/components/schemas/createUserReq" || req.Example == nil {
+		t.Errorf("请求体应引用 createUserReq 并带有示例，实际为 %+v", req)
+	}
+	if create.Responses["201"].Description != "已创建" || create.Responses["400"].Description != "Bad Request" {
+		t.Errorf("响应描述错误: %+v", create.Responses)
+	}
+	if create.Responses["400"].Content != nil {
+		t.Error("没有结构与示例的响应不应有内容")
+	}
+
+	show := doc.Paths["/users/{id}"]["get"]
+	if !show.Deprecated || len(show.Parameters) != 1 || show.Parameters[0].Name != "id" {
+		t.Errorf("GET /users/{id} 应已弃用且带有路径参数 id: %+v", show)
+	}
+	if show.Responses["200"] == nil {
+		t.Error("没有描述响应时应生成默认的 200 响应")
+	}
+	if files := doc.Paths["/files/{path}"]; files == nil {
+		t.Errorf("{path...} 应转换为 {path}，实际路径: %v", doc.Paths)
+	}
+
+	node := doc.Components.Schemas["treeNode"]
+	if node == nil {
+		t.Fatalf("缺少 treeNode 的结构: %v", doc.Components.Schemas)
+	}
+	if node.Properties["children"].Items.Ref != "#/components/schemas/treeNode" {
+		t.Error("自引用的字段应引用自身")
+	}
+	if node.Properties["created"].Format != "date-time" {
+		t.Error("time.Time 应为 date-time 字符串")
+	}
+	if _, ok := node.Properties["Internal"]; ok {
+		t.Error("json:\"-\" 的字段应被忽略")
+	}
+	if len(node.Required) != 2 || node.Required[0] != "created" || node.Required[1] != "name" {
+		t.Errorf("必填字段应为 [created name]，实际为 %v", node.Required)
+	}
+}
+
+// TestOpenAPIHandler 测试以 JSON 响应接口文档
+func TestOpenAPIHandler(t *testing.T) {
+	s := NewHTTPServer()
+	info := OpenAPIInfo{Title: "测试", Version: "1.0"}
+	s.Handle("GET /openapi.json", s.OpenAPIHandler(info))
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var doc map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("文档应为 JSON: %v", err)
+	}
+	if doc["openapi"] != "3.0.3" {
+		t.Errorf("openapi 版本错误: %v", doc["openapi"])
+	}
+	if _, ok := doc["paths"].(map[string]any)["/openapi.json"]; !ok {
+		t.Error("文档应包含自身的路由")
+	}
+}
@@ -0,0 +1,247 @@
+package ant
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SortField 排序字段
+type SortField struct {
+	// Field 存储中的字段名，例如数据库列名
+	Field string
+	// Desc 是否降序
+	Desc bool
+}
+
+// Paginator 解析列表接口的分页与排序参数
+// 支持的查询参数：
+//   - page: 页码，从 1 开始，默认为 1
+//   - limit: 每页数量，默认为 DefaultLimit，不能超过 MaxLimit
+//   - cursor: 游标，出现时忽略 page，由存储层解释，可以通过 EncodeCursor 生成
+//   - sort: 排序字段，逗号分隔，前缀 - 表示降序，例如 "-created_at,name"
+//   - locale: 排序使用的语言区域，未设置时取 Accept-Language 中的第一个语言
+type Paginator struct {
+	// DefaultLimit 默认每页数量，为0时使用 20
+	DefaultLimit int
+	// MaxLimit 每页数量的上限，为0时使用 100
+	MaxLimit int
+	// MaxPage 页码的上限，避免深分页拖慢数据库，为0时不限制
+	MaxPage int
+	// SortFields 允许排序的字段，键为查询参数中的名称，值为存储中的字段名
+	// 只有列出的字段可以排序，OrderBy 的结果因此可以安全地拼接到 SQL 中
+	SortFields map[string]string
+	// DefaultSort 没有 sort 参数时的排序
+	DefaultSort []SortField
+}
+
+// Page 解析后的分页参数
+type Page struct {
+	// Page 页码，从 1 开始，使用游标时为 0
+	Page int
+	// Limit 每页数量
+	Limit int
+	// Offset 跳过的数量，使用游标时为 0
+	Offset int
+	// Cursor 游标，为空时使用页码分页
+	Cursor string
+	// Sort 排序字段，已映射为存储中的字段名
+	Sort []SortField
+	// Locale 排序使用的语言区域，例如 "zh-CN"，可以用于选择数据库的排序规则
+	Locale string
+}
+
+// PageMeta 标准的分页元数据
+type PageMeta struct {
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Total      *int64 `json:"total,omitempty"`
+	TotalPages int    `json:"total_pages,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// PageResult 标准的分页响应
+type PageResult[T any] struct {
+	Data []T      `json:"data"`
+	Meta PageMeta `json:"meta"`
+}
+
+// Parse 从请求中解析分页参数，参数不合法时返回状态码为 400 的 *HTTPError
+func (p Paginator) Parse(ctx *Context) (Page, error) {
+	q := ctx.Req.URL.Query()
+	page := Page{Page: 1, Limit: p.defaultLimit(), Cursor: q.Get("cursor")}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return Page{}, NewHTTPError(http.StatusBadRequest, "limit 必须是正整数")
+		}
+		page.Limit = min(n, p.maxLimit())
+	}
+	if page.Cursor != "" {
+		page.Page = 0
+	} else if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return Page{}, NewHTTPError(http.StatusBadRequest, "page 必须是正整数")
+		}
+		if p.MaxPage > 0 && n > p.MaxPage {
+			return Page{}, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("page 不能超过 %d", p.MaxPage))
+		}
+		page.Page = n
+		page.Offset = (n - 1) * page.Limit
+	}
+
+	page.Sort = p.DefaultSort
+	if v := q.Get("sort"); v != "" {
+		sort, err := p.parseSort(v)
+		if err != nil {
+			return Page{}, err
+		}
+		page.Sort = sort
+	}
+
+	page.Locale = q.Get("locale")
+	if page.Locale == "" {
+		page.Locale = primaryLanguage(ctx.Req.Header.Get("Accept-Language"))
+	}
+	return page, nil
+}
+
+// parseSort 解析排序参数，只接受 SortFields 中的字段
+func (p Paginator) parseSort(v string) ([]SortField, error) {
+	var sort []SortField
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		desc := strings.HasPrefix(item, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(item, "-"), "+")
+		field, ok := p.SortFields[name]
+		if !ok {
+			return nil, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("不支持按 %q 排序", name))
+		}
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		sort = append(sort, SortField{Field: field, Desc: desc})
+	}
+	return sort, nil
+}
+
+func (p Paginator) defaultLimit() int {
+	if p.DefaultLimit > 0 {
+		return min(p.DefaultLimit, p.maxLimit())
+	}
+	return min(20, p.maxLimit())
+}
+
+func (p Paginator) maxLimit() int {
+	if p.MaxLimit > 0 {
+		return p.MaxLimit
+	}
+	return 100
+}
+
+// primaryLanguage 返回 Accept-Language 中的第一个语言，忽略权重
+func primaryLanguage(header string) string {
+	lang, _, _ := strings.Cut(header, ",")
+	lang, _, _ = strings.Cut(lang, ";")
+	lang = strings.TrimSpace(lang)
+	if lang == "*" {
+		return ""
+	}
+	return lang
+}
+
+// OrderBy 返回 SQL 的排序子句，不包含 ORDER BY 关键字，例如 "created_at DESC, name ASC"
+// 没有排序字段时返回空字符串
+func (pg Page) OrderBy() string {
+	parts := make([]string, 0, len(pg.Sort))
+	for _, s := range pg.Sort {
+		dir := "ASC"
+		if s.Desc {
+			dir = "DESC"
+		}
+		parts = append(parts, s.Field+" "+dir)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Meta 生成页码分页的元数据
+// total: 总数，未知时传入负数，此时根据 fetched 是否超过 Limit 判断是否还有下一页
+// fetched: 本次查询到的数量，可以多查一条用于判断 HasMore
+func (pg Page) Meta(total int64, fetched int) PageMeta {
+	meta := PageMeta{Page: pg.Page, Limit: pg.Limit}
+	if total >= 0 {
+		meta.Total = &total
+		meta.TotalPages = int(math.Ceil(float64(total) / float64(pg.Limit)))
+		meta.HasMore = int64(pg.Offset+pg.Limit) < total
+		return meta
+	}
+	meta.HasMore = fetched > pg.Limit
+	return meta
+}
+
+// CursorMeta 生成游标分页的元数据
+// next: 下一页的游标，为空时表示没有更多数据
+func (pg Page) CursorMeta(next string) PageMeta {
+	return PageMeta{Limit: pg.Limit, HasMore: next != "", NextCursor: next}
+}
+
+// DecodeCursor 将游标解析到 v 中，游标由 EncodeCursor 生成
+// 游标格式不合法时返回状态码为 400 的 *HTTPError
+func (pg Page) DecodeCursor(v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(pg.Cursor)
+	if err == nil {
+		err = json.Unmarshal(data, v)
+	}
+	if err != nil {
+		return &HTTPError{Code: http.StatusBadRequest, Message: "cursor 不合法", Err: err}
+	}
+	return nil
+}
+
+// EncodeCursor 将游标数据编码为可以放在查询参数中的字符串，例如最后一条记录的排序键
+// 注意：游标没有签名，客户端可以修改，存储层需要像对待其他输入一样校验
+func EncodeCursor(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// SetPageLinks 按 RFC 8288 设置 Link 响应头，包含 next 与 prev 链接
+// 链接基于当前请求的 URL，只替换 page 或 cursor 参数
+func SetPageLinks(ctx *Context, pg Page, meta PageMeta) {
+	link := func(rel string, set func(q url.Values)) {
+		u := *ctx.Req.URL
+		q := u.Query()
+		set(q)
+		u.RawQuery = q.Encode()
+		ctx.Resp.Header().Add("Link", fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), rel))
+	}
+	if meta.NextCursor != "" {
+		link("next", func(q url.Values) {
+			q.Del("page")
+			q.Set("cursor", meta.NextCursor)
+		})
+		return
+	}
+	if pg.Page == 0 {
+		return
+	}
+	if meta.HasMore {
+		link("next", func(q url.Values) { q.Set("page", strconv.Itoa(pg.Page+1)) })
+	}
+	if pg.Page > 1 {
+		link("prev", func(q url.Values) { q.Set("page", strconv.Itoa(pg.Page-1)) })
+	}
+}
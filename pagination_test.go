@@ -0,0 +1,102 @@
+package ant
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newPageContext(target string) *Context {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	return &Context{Req: req, Resp: httptest.NewRecorder()}
+}
+
+// TestPaginatorParse 测试分页参数的解析、校验与上限
+func TestPaginatorParse(t *testing.T) {
+	p := Paginator{
+		MaxLimit:    50,
+		MaxPage:     100,
+		SortFields:  map[string]string{"created": "created_at", "name": "name"},
+		DefaultSort: []SortField{{Field: "id"}},
+	}
+
+	ctx := newPageContext("/users")
+	ctx.Req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+	pg, err := p.Parse(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pg.Page != 1 || pg.Limit != 20 || pg.Offset != 0 || pg.OrderBy() != "id ASC" || pg.Locale != "zh-CN" {
+		t.Errorf("默认分页参数错误: %+v", pg)
+	}
+
+	pg, _ = p.Parse(newPageContext("/users?page=3&limit=500&sort=-created,name,created&locale=de"))
+	if pg.Page != 3 || pg.Limit != 50 || pg.Offset != 100 {
+		t.Errorf("limit 应被限制为 50，实际为 %+v", pg)
+	}
+	if pg.OrderBy() != "created_at DESC, name ASC" || pg.Locale != "de" {
+		t.Errorf("排序错误: %q %q", pg.OrderBy(), pg.Locale)
+	}
+
+	pg, _ = p.Parse(newPageContext("/users?page=3&cursor=abc"))
+	if pg.Page != 0 || pg.Offset != 0 || pg.Cursor != "abc" {
+		t.Errorf("使用游标时应忽略页码: %+v", pg)
+	}
+
+	for _, target := range []string{"/users?page=0", "/users?page=x", "/users?limit=-1", "/users?page=101", "/users?sort=password"} {
+		_, err = p.Parse(newPageContext(target))
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Code != http.StatusBadRequest {
+			t.Errorf("%s 应返回 400 错误，实际为 %v", target, err)
+		}
+	}
+}
+
+// TestPageMeta 测试分页元数据与 Link 响应头
+func TestPageMeta(t *testing.T) {
+	ctx := newPageContext("/users?page=2&limit=10&q=a")
+	pg, _ := Paginator{}.Parse(ctx)
+
+	meta := pg.Meta(25, 10)
+	if *meta.Total != 25 || meta.TotalPages != 3 || !meta.HasMore {
+		t.Errorf("元数据错误: %+v", meta)
+	}
+	if meta = pg.Meta(-1, 10); meta.Total != nil || meta.HasMore {
+		t.Errorf("总数未知且没有多查到数据时不应有下一页: %+v", meta)
+	}
+
+	SetPageLinks(ctx, pg, pg.Meta(25, 10))
+	links := ctx.Resp.Header().Values("Link")
+	want := []string{`</users?limit=10&page=3&q=a>; rel="next"`, `</users?limit=10&page=1&q=a>; rel="prev"`}
+	if len(links) != 2 || links[0] != want[0] || links[1] != want[1] {
+		t.Errorf("Link 响应头应为 %v，实际为 %v", want, links)
+	}
+}
+
+// TestPageCursor 测试游标的编码与解析
+func TestPageCursor(t *testing.T) {
+	type key struct {
+		ID int `json:"id"`
+	}
+	cursor, err := EncodeCursor(key{ID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := newPageContext("/users?cursor=" + cursor)
+	pg, _ := Paginator{}.Parse(ctx)
+	var k key
+	if err = pg.DecodeCursor(&k); err != nil || k.ID != 42 {
+		t.Errorf("游标解析错误: %+v %v", k, err)
+	}
+
+	SetPageLinks(ctx, pg, pg.CursorMeta("next-cursor"))
+	if got := ctx.Resp.Header().Get("Link"); got != `</users?cursor=next-cursor>; rel="next"` {
+		t.Errorf("游标分页的 Link 响应头错误: %s", got)
+	}
+
+	pg.Cursor = "!!"
+	if err = pg.DecodeCursor(&k); err == nil {
+		t.Error("不合法的游标应返回错误")
+	}
+}
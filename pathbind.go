@@ -0,0 +1,79 @@
+package ant
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// pathField 带有 path 标签的结构体字段
+type pathField struct {
+	index []int
+	name  string
+	kind  reflect.Kind
+}
+
+// pathFieldCache 按类型缓存的 path 字段
+var pathFieldCache sync.Map
+
+// pathFields 返回结构体中带有 path 标签的字段，只支持字符串与整数类型
+func pathFields(t reflect.Type) []pathField {
+	if cached, ok := pathFieldCache.Load(t); ok {
+		return cached.([]pathField)
+	}
+	var fields []pathField
+	for _, f := range reflect.VisibleFields(t) {
+		name := f.Tag.Get("path")
+		if name == "" || !f.IsExported() {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			panic(fmt.Sprintf("web: 字段 %s.%s 的类型 %s 不能绑定路径参数", t, f.Name, f.Type))
+		}
+		fields = append(fields, pathField{index: f.Index, name: name, kind: f.Type.Kind()})
+	}
+	pathFieldCache.Store(t, fields)
+	return fields
+}
+
+// bindPathParams 将路径参数写入结构体中带有 path 标签的字段，例如 `path:"id"`
+// 整数字段使用 PathValue 的转换结果，路由声明了 {id:int} 等约束时不需要重复解析
+// 路由中没有对应参数时保留零值，参数无法转换为字段类型时返回状态码为 400 的 *HTTPError
+func (c *Context) bindPathParams(val any) error {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	rv = rv.Elem()
+	for _, f := range pathFields(rv.Type()) {
+		pv := c.PathValue(f.name)
+		if pv.err != nil {
+			continue
+		}
+		field := rv.FieldByIndex(f.index)
+		ok := true
+		switch f.kind {
+		case reflect.String:
+			field.SetString(pv.val)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := pv.ToInt64()
+			if ok = err == nil && !field.OverflowInt(n); ok {
+				field.SetInt(n)
+			}
+		default:
+			n, err := pv.ToUint64()
+			if ok = err == nil && !field.OverflowUint(n); ok {
+				field.SetUint(n)
+			}
+		}
+		if !ok {
+			return &HTTPError{Code: http.StatusBadRequest, Message: fmt.Sprintf("路径参数 %s 不合法", f.name)}
+		}
+	}
+	return nil
+}
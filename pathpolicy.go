@@ -0,0 +1,163 @@
+package ant
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// EncodedSlashPolicy 路径中编码斜杠（%2F）的处理方式
+type EncodedSlashPolicy int
+
+const (
+	// EncodedSlashAllow 编码斜杠属于所在的路径段，PathValue 返回解码后的 "/"，与 http.ServeMux 一致，默认值
+	EncodedSlashAllow EncodedSlashPolicy = iota
+	// EncodedSlashReject 拒绝包含编码斜杠的请求，响应 400
+	EncodedSlashReject
+	// EncodedSlashDecode 在匹配之前解码，编码斜杠与普通斜杠一样分隔路径段
+	EncodedSlashDecode
+)
+
+// PathPolicy 请求路径在匹配路由之前的规范化策略
+// 未设置时由 http.ServeMux 处理：不规范的路径会被重定向，编码斜杠保留在路径段中
+type PathPolicy struct {
+	// Clean 在匹配之前规范化路径：
+	// 解码非保留字符的百分号编码（例如 %41 与 %2e），合并连续的斜杠，并解析 . 与 .. 路径段
+	// 保留末尾的斜杠，编码斜杠不参与规范化
+	Clean bool
+	// Redirect 与 Clean 一起使用，规范化改变了路径时重定向到规范的路径，而不是直接匹配
+	// GET 与 HEAD 请求使用 301，其他请求使用 308 以保留请求方法与请求体
+	Redirect bool
+	// EncodedSlash 编码斜杠的处理方式
+	EncodedSlash EncodedSlashPolicy
+}
+
+// ServerWithPathPolicy 设置请求路径的规范化策略
+// 例如直接匹配规范化后的路径，并拒绝参数中的编码斜杠：
+//
+//	ant.ServerWithPathPolicy(ant.PathPolicy{Clean: true, EncodedSlash: ant.EncodedSlashReject})
+func ServerWithPathPolicy(policy PathPolicy) ServerOption {
+	return func(server *HTTPServer) {
+		server.pathPolicy = &policy
+	}
+}
+
+// apply 按策略改写请求路径
+// 返回值: 是否继续处理请求，已经写入了错误或重定向时返回 false
+func (p *PathPolicy) apply(w http.ResponseWriter, r *http.Request) bool {
+	escaped := r.URL.EscapedPath()
+	hasEncodedSlash := strings.Contains(escaped, "%2F") || strings.Contains(escaped, "%2f")
+	if hasEncodedSlash {
+		switch p.EncodedSlash {
+		case EncodedSlashReject:
+			http.Error(w, "路径中不允许编码的斜杠", http.StatusBadRequest)
+			return false
+		case EncodedSlashDecode:
+			escaped = strings.ReplaceAll(strings.ReplaceAll(escaped, "%2F", "/"), "%2f", "/")
+		}
+	}
+	if !p.Clean && escaped == r.URL.EscapedPath() {
+		return true
+	}
+
+	if p.Clean {
+		escaped = cleanEscapedPath(normalizeEscapes(escaped))
+	}
+	decoded, err := url.PathUnescape(escaped)
+	if err != nil {
+		http.Error(w, "路径编码不合法", http.StatusBadRequest)
+		return false
+	}
+	if escaped == r.URL.EscapedPath() {
+		return true
+	}
+	if p.Redirect && p.Clean {
+		target := escaped
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		code := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, target, code)
+		return false
+	}
+	r.URL.Path = decoded
+	r.URL.RawPath = ""
+	if r.URL.EscapedPath() != escaped {
+		r.URL.RawPath = escaped
+	}
+	return true
+}
+
+// normalizeEscapes 解码非保留字符的百分号编码，其他编码统一为大写
+// 例如 "/%7euser/%2e%2e/a%2fb" 变为 "/~user/../a%2Fb"
+func normalizeEscapes(escaped string) string {
+	if !strings.Contains(escaped, "%") {
+		return escaped
+	}
+	var sb strings.Builder
+	sb.Grow(len(escaped))
+	for i := 0; i < len(escaped); i++ {
+		c := escaped[i]
+		if c != '%' || i+2 >= len(escaped) || !isHex(escaped[i+1]) || !isHex(escaped[i+2]) {
+			sb.WriteByte(c)
+			continue
+		}
+		b := unhex(escaped[i+1])<<4 | unhex(escaped[i+2])
+		if isUnreserved(b) {
+			sb.WriteByte(b)
+		} else {
+			sb.WriteByte('%')
+			sb.WriteString(strings.ToUpper(escaped[i+1 : i+3]))
+		}
+		i += 2
+	}
+	return sb.String()
+}
+
+// cleanEscapedPath 合并连续的斜杠并解析 . 与 .. 路径段，保留末尾的斜杠
+// 与 path.Clean 不同，结果总是以 / 开头，.. 不会越过根路径
+func cleanEscapedPath(escaped string) string {
+	segments := strings.Split(escaped, "/")
+	out := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		switch seg {
+		case "", ".":
+		case "..":
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+		default:
+			out = append(out, seg)
+		}
+	}
+	cleaned := "/" + strings.Join(out, "/")
+	last := segments[len(segments)-1]
+	if len(out) > 0 && (last == "" || last == "." || last == "..") {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// isUnreserved 判断是否为 RFC 3986 中的非保留字符
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
@@ -0,0 +1,77 @@
+package ant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newPathServer(policy *PathPolicy) *HTTPServer {
+	var opts []ServerOption
+	if policy != nil {
+		opts = append(opts, ServerWithPathPolicy(*policy))
+	}
+	s := NewHTTPServer(opts...)
+	s.Handle("/a/c", func(ctx *Context) {
+		ctx.RespData = []byte("c")
+	})
+	s.Handle("/dir/", func(ctx *Context) {
+		ctx.RespData = []byte("dir:" + ctx.Req.URL.Path)
+	})
+	s.Handle("GET /files/{name}", func(ctx *Context) {
+		ctx.RespData = []byte("name:" + ctx.Req.PathValue("name"))
+	})
+	s.Handle("GET /files/{dir}/{name}", func(ctx *Context) {
+		ctx.RespData = []byte("nested:" + ctx.Req.PathValue("dir") + "|" + ctx.Req.PathValue("name"))
+	})
+	return s
+}
+
+func TestPathPolicy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   *PathPolicy
+		method   string
+		target   string
+		wantCode int
+		wantBody string
+		wantLoc  string
+	}{
+		{name: "默认编码斜杠属于路径段", target: "/files/a%2Fb", wantCode: http.StatusOK, wantBody: "name:a/b"},
+		// ServeMux 使用的重定向状态码随 Go 版本变化，只检查是否重定向
+		{name: "默认重定向不规范的路径", target: "/a//b/../c", wantCode: http.StatusMultipleChoices, wantLoc: "/a/c"},
+		{name: "规范化后直接匹配", policy: &PathPolicy{Clean: true}, target: "/a//b/../c", wantCode: http.StatusOK, wantBody: "c"},
+		{name: "解码非保留字符", policy: &PathPolicy{Clean: true}, target: "/a/b/%2e%2E/%63", wantCode: http.StatusOK, wantBody: "c"},
+		{name: "不越过根路径", policy: &PathPolicy{Clean: true}, target: "/../../a/c", wantCode: http.StatusOK, wantBody: "c"},
+		{name: "保留末尾斜杠", policy: &PathPolicy{Clean: true}, target: "/dir//x/..//", wantCode: http.StatusOK, wantBody: "dir:/dir/"},
+		{name: "编码斜杠不参与规范化", policy: &PathPolicy{Clean: true}, target: "/files/..%2Fb", wantCode: http.StatusOK, wantBody: "name:../b"},
+		{name: "重定向保留查询参数", policy: &PathPolicy{Clean: true, Redirect: true}, target: "/a/./c?x=1", wantCode: http.StatusMovedPermanently, wantLoc: "/a/c?x=1"},
+		{name: "非GET使用308", policy: &PathPolicy{Clean: true, Redirect: true}, method: http.MethodPost, target: "/a//c", wantCode: http.StatusPermanentRedirect, wantLoc: "/a/c"},
+		{name: "规范的路径不重定向", policy: &PathPolicy{Clean: true, Redirect: true}, target: "/a/c", wantCode: http.StatusOK, wantBody: "c"},
+		{name: "拒绝编码斜杠", policy: &PathPolicy{EncodedSlash: EncodedSlashReject}, target: "/files/a%2fb", wantCode: http.StatusBadRequest},
+		{name: "解码编码斜杠", policy: &PathPolicy{EncodedSlash: EncodedSlashDecode}, target: "/files/a%2Fb", wantCode: http.StatusOK, wantBody: "nested:a|b"},
+		{name: "解码后再规范化", policy: &PathPolicy{Clean: true, EncodedSlash: EncodedSlashDecode}, target: "/a/b%2F..%2Fc", wantCode: http.StatusOK, wantBody: "c"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			w := httptest.NewRecorder()
+			newPathServer(tc.policy).ServeHTTP(w, httptest.NewRequest(method, tc.target, nil))
+			if tc.wantCode == http.StatusMultipleChoices && w.Code/100 == 3 {
+				w.Code = tc.wantCode
+			}
+			if w.Code != tc.wantCode {
+				t.Fatalf("状态码应为 %d，实际为 %d", tc.wantCode, w.Code)
+			}
+			if tc.wantBody != "" && w.Body.String() != tc.wantBody {
+				t.Errorf("响应应为 %q，实际为 %q", tc.wantBody, w.Body.String())
+			}
+			if tc.wantLoc != "" && w.Header().Get("Location") != tc.wantLoc {
+				t.Errorf("重定向地址应为 %q，实际为 %q", tc.wantLoc, w.Header().Get("Location"))
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package ant
+
+import (
+	"log"
+	"net/http"
+	"sync"
+)
+
+// maxPooledBufferSize 归还到池中的响应缓冲区的最大容量，超过时丢弃以免长期占用内存
+const maxPooledBufferSize = 64 << 10
+
+// contextPool 复用请求上下文，减少每个请求的内存分配
+var contextPool = sync.Pool{
+	New: func() any {
+		return &Context{}
+	},
+}
+
+// acquireContext 从池中获取并初始化请求上下文
+func (s *HTTPServer) acquireContext(w http.ResponseWriter, r *http.Request) *Context {
+	ctx := contextPool.Get().(*Context)
+	ctx.rw.reset(w)
+	ctx.rw.cachePolicy = s.cachePolicy
+	ctx.Req = r
+	ctx.Resp = &ctx.rw
+	ctx.TemplateEngine = s.TemplateEngine
+	ctx.trustedProxies = s.trustedProxies
+	ctx.jsonCodec = s.jsonCodec
+	ctx.binders = s.binders
+	ctx.features = s.features
+	return ctx
+}
+
+// releaseContext 重置请求上下文并放回池中
+// 注意：调用后 ctx 可能被其他请求复用，处理函数返回后不应再持有 ctx 或其 RespData
+func releaseContext(ctx *Context) {
+	ctx.Req = nil
+	ctx.Resp = nil
+	ctx.rw.reset(nil)
+	ctx.cacheQueryValues = nil
+	ctx.RespStatusCode = 0
+	ctx.RespData = nil
+	ctx.Err = nil
+	ctx.TemplateEngine = nil
+	ctx.hijacked = false
+	ctx.trustedProxies = nil
+	ctx.paramNames = nil
+	ctx.typedParams = ctx.typedParams[:0]
+	ctx.jsonCodec = nil
+	ctx.binders = nil
+	ctx.features = nil
+	ctx.templateValidators = false
+	if ctx.multipartForm != nil {
+		if err := ctx.multipartForm.RemoveAll(); err != nil {
+			log.Printf("web: 删除上传的临时文件失败: %v", err)
+		}
+		ctx.multipartForm = nil
+	}
+	// UserValues 保留已分配的 map，清空后复用
+	clear(ctx.UserValues)
+	if cap(ctx.buf) > maxPooledBufferSize {
+		ctx.buf = nil
+	} else {
+		ctx.buf = ctx.buf[:0]
+	}
+	contextPool.Put(ctx)
+}
@@ -0,0 +1,301 @@
+package ant
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoUpstream 没有可用的上游服务
+var ErrNoUpstream = errors.New("web: 没有可用的上游服务")
+
+// ProxyOptions 反向代理的配置
+type ProxyOptions struct {
+	// StripPrefix 转发前从请求路径中移除的前缀
+	StripPrefix string
+	// Rewrite 转发前改写请求路径，在 StripPrefix 之后执行
+	Rewrite func(path string) string
+	// SetHeaders 转发前设置的请求头
+	SetHeaders map[string]string
+	// RemoveHeaders 转发前移除的请求头
+	RemoveHeaders []string
+	// ModifyResponse 修改上游响应，返回错误时按上游不可用处理
+	ModifyResponse func(resp *http.Response) error
+	// Retries 连接上游失败时的重试次数，每次重试会选择下一个可用的上游
+	// 请求体无法重放的请求不会重试
+	Retries int
+	// FailureThreshold 连续失败多少次后将上游标记为不可用，默认为 3
+	FailureThreshold int
+	// Cooldown 上游被标记为不可用后的冷却时间，冷却结束后重新参与转发，默认为 10 秒
+	Cooldown time.Duration
+	// FlushInterval 响应的刷新间隔，负数表示每次写入后立即刷新
+	// 流式响应（例如 text/event-stream）总是立即刷新
+	FlushInterval time.Duration
+	// Transport 转发请求使用的 RoundTripper，默认为 http.DefaultTransport
+	Transport http.RoundTripper
+}
+
+// ProxyOption 反向代理的配置选项
+type ProxyOption func(opts *ProxyOptions)
+
+// ProxyWithStripPrefix 转发前从请求路径中移除前缀
+func ProxyWithStripPrefix(prefix string) ProxyOption {
+	return func(opts *ProxyOptions) {
+		opts.StripPrefix = prefix
+	}
+}
+
+// ProxyWithRewrite 转发前改写请求路径
+func ProxyWithRewrite(fn func(path string) string) ProxyOption {
+	return func(opts *ProxyOptions) {
+		opts.Rewrite = fn
+	}
+}
+
+// ProxyWithSetHeader 转发前设置请求头
+func ProxyWithSetHeader(key, value string) ProxyOption {
+	return func(opts *ProxyOptions) {
+		if opts.SetHeaders == nil {
+			opts.SetHeaders = make(map[string]string)
+		}
+		opts.SetHeaders[key] = value
+	}
+}
+
+// ProxyWithRemoveHeader 转发前移除请求头，例如内部使用的鉴权头
+func ProxyWithRemoveHeader(keys ...string) ProxyOption {
+	return func(opts *ProxyOptions) {
+		opts.RemoveHeaders = append(opts.RemoveHeaders, keys...)
+	}
+}
+
+// ProxyWithModifyResponse 设置修改上游响应的函数
+func ProxyWithModifyResponse(fn func(resp *http.Response) error) ProxyOption {
+	return func(opts *ProxyOptions) {
+		opts.ModifyResponse = fn
+	}
+}
+
+// ProxyWithRetries 设置连接上游失败时的重试次数
+func ProxyWithRetries(n int) ProxyOption {
+	return func(opts *ProxyOptions) {
+		opts.Retries = n
+	}
+}
+
+// ProxyWithHealth 设置上游的被动健康检查参数
+// threshold: 连续失败多少次后将上游标记为不可用
+// cooldown: 不可用状态的持续时间
+func ProxyWithHealth(threshold int, cooldown time.Duration) ProxyOption {
+	return func(opts *ProxyOptions) {
+		opts.FailureThreshold = threshold
+		opts.Cooldown = cooldown
+	}
+}
+
+// ProxyWithFlushInterval 设置响应的刷新间隔
+func ProxyWithFlushInterval(interval time.Duration) ProxyOption {
+	return func(opts *ProxyOptions) {
+		opts.FlushInterval = interval
+	}
+}
+
+// ProxyWithTransport 设置转发请求使用的 RoundTripper
+func ProxyWithTransport(rt http.RoundTripper) ProxyOption {
+	return func(opts *ProxyOptions) {
+		opts.Transport = rt
+	}
+}
+
+// upstream 上游服务及其健康状态
+type upstream struct {
+	target    *url.URL
+	failures  atomic.Int32
+	downUntil atomic.Int64
+}
+
+// proxy 反向代理的运行状态
+type proxy struct {
+	opts      ProxyOptions
+	upstreams []*upstream
+	next      atomic.Uint32
+	rp        *httputil.ReverseProxy
+}
+
+// Proxy 创建将请求转发到上游服务的处理函数
+// target: 上游服务地址，多个地址以逗号分隔时按轮询方式转发，例如 "http://10.0.0.1:8080,http://10.0.0.2:8080"
+// opts: 路径改写、请求头修改、重试与健康检查等配置
+// 返回值: 处理函数，上游地址不正确时 panic
+// 注意：
+// 1. 请求体与响应体均以流的方式转发，不会被完整读入内存
+// 2. WebSocket 等协议升级请求会被透明转发
+// 3. 处理函数直接写入响应，RespStatusCode 会被设置为上游的状态码以便中间件读取
+// 4. 多个上游时路径前缀以第一个上游为准
+func Proxy(target string, opts ...ProxyOption) HandleFunc {
+	o := ProxyOptions{
+		FailureThreshold: 3,
+		Cooldown:         10 * time.Second,
+		Transport:        http.DefaultTransport,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	p := &proxy{opts: o}
+	for _, raw := range strings.Split(target, ",") {
+		u, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			panic("web: 非法的上游地址 " + raw)
+		}
+		p.upstreams = append(p.upstreams, &upstream{target: u})
+	}
+
+	p.rp = &httputil.ReverseProxy{
+		Rewrite:        p.rewrite,
+		Transport:      &retryTransport{proxy: p},
+		FlushInterval:  o.FlushInterval,
+		ModifyResponse: o.ModifyResponse,
+		ErrorHandler:   p.errorHandler,
+	}
+
+	return func(ctx *Context) {
+		rw, ok := ctx.Resp.(ResponseWriter)
+		if !ok {
+			w := &responseWriter{}
+			w.reset(ctx.Resp)
+			rw = w
+		}
+		p.rp.ServeHTTP(rw, ctx.Req)
+		status := rw.Status()
+		if status == 0 && ctx.Req.Header.Get("Upgrade") != "" {
+			// 协议升级的响应直接写入被接管的连接
+			status = http.StatusSwitchingProtocols
+		}
+		ctx.RespStatusCode = status
+	}
+}
+
+// rewrite 改写转发的请求
+func (p *proxy) rewrite(r *httputil.ProxyRequest) {
+	path := r.In.URL.Path
+	if p.opts.StripPrefix != "" {
+		path = strings.TrimPrefix(path, p.opts.StripPrefix)
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+	}
+	if p.opts.Rewrite != nil {
+		path = p.opts.Rewrite(path)
+	}
+	r.Out.URL.Path = path
+	r.Out.URL.RawPath = ""
+
+	// 实际的上游在 retryTransport 中选择，这里只设置第一个上游以便 SetURL 拼接路径
+	r.SetURL(p.upstreams[0].target)
+	r.SetXForwarded()
+	for _, key := range p.opts.RemoveHeaders {
+		r.Out.Header.Del(key)
+	}
+	for key, value := range p.opts.SetHeaders {
+		r.Out.Header.Set(key, value)
+	}
+}
+
+// errorHandler 所有上游都不可用时返回 502
+func (p *proxy) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("web: 转发 %s %s 失败: %v", r.Method, r.URL.Path, err)
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+// pick 按轮询选择一个可用的上游，全部不可用时仍然选择一个，避免完全拒绝服务
+func (p *proxy) pick(exclude map[*upstream]bool) *upstream {
+	now := time.Now().UnixNano()
+	n := len(p.upstreams)
+	start := int(p.next.Add(1))
+	var fallback *upstream
+	for i := 0; i < n; i++ {
+		u := p.upstreams[(start+i)%n]
+		if exclude[u] {
+			continue
+		}
+		if u.downUntil.Load() <= now {
+			return u
+		}
+		if fallback == nil {
+			fallback = u
+		}
+	}
+	return fallback
+}
+
+// report 记录上游的请求结果
+func (p *proxy) report(u *upstream, err error) {
+	if err == nil {
+		u.failures.Store(0)
+		return
+	}
+	if int(u.failures.Add(1)) >= p.opts.FailureThreshold {
+		u.downUntil.Store(time.Now().Add(p.opts.Cooldown).UnixNano())
+		u.failures.Store(0)
+	}
+}
+
+// retryTransport 选择上游并在连接失败时重试
+type retryTransport struct {
+	proxy *proxy
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := t.proxy
+	tried := make(map[*upstream]bool, len(p.upstreams))
+	var lastErr error = ErrNoUpstream
+	for attempt := 0; attempt <= p.opts.Retries; attempt++ {
+		u := p.pick(tried)
+		if u == nil {
+			break
+		}
+		tried[u] = true
+
+		if attempt > 0 {
+			if !replayable(req) {
+				break
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				break
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		req.URL.Scheme = u.target.Scheme
+		req.URL.Host = u.target.Host
+		req.Host = ""
+
+		resp, err := p.opts.Transport.RoundTrip(req)
+		p.report(u, err)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if req.Context().Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// replayable 判断请求体是否可以重放
+func replayable(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		req.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+		return true
+	}
+	return req.GetBody != nil
+}
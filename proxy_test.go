@@ -0,0 +1,148 @@
+package ant
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestProxy 测试路径改写与请求头修改
+func TestProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.Path)
+		w.Header().Set("X-Token", r.Header.Get("X-Token"))
+		w.Header().Set("X-Internal", r.Header.Get("X-Internal"))
+		w.Header().Set("X-Forwarded", r.Header.Get("X-Forwarded-For"))
+		w.WriteHeader(http.StatusCreated)
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	defer upstream.Close()
+
+	var status int
+	server := NewHTTPServer()
+	server.Use(func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			status = ctx.RespStatusCode
+		}
+	})
+	server.Handle("/legacy/", Proxy(upstream.URL,
+		ProxyWithStripPrefix("/legacy"),
+		ProxyWithSetHeader("X-Token", "secret"),
+		ProxyWithRemoveHeader("X-Internal"),
+	))
+
+	req := httptest.NewRequest(http.MethodPost, "/legacy/orders", strings.NewReader("payload"))
+	req.Header.Set("X-Internal", "1")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated || status != http.StatusCreated {
+		t.Errorf("期望状态码 201, 得到 %d, 中间件得到 %d", rec.Code, status)
+	}
+	if rec.Body.String() != "payload" {
+		t.Errorf("请求体转发不正确: %q", rec.Body.String())
+	}
+	if got := rec.Header().Get("X-Path"); got != "/orders" {
+		t.Errorf("期望上游路径 /orders, 得到 %s", got)
+	}
+	if rec.Header().Get("X-Token") != "secret" || rec.Header().Get("X-Internal") != "" {
+		t.Errorf("请求头修改未生效: %v", rec.Header())
+	}
+	if rec.Header().Get("X-Forwarded") == "" {
+		t.Error("期望设置 X-Forwarded-For")
+	}
+}
+
+// TestProxyRetryAndHealth 测试连接失败时切换上游并标记不可用的上游
+func TestProxyRetryAndHealth(t *testing.T) {
+	var hits int
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer healthy.Close()
+
+	// 已关闭的端口，连接会被拒绝
+	dead := "http://" + freeAddr(t)
+
+	handler := Proxy(dead+","+healthy.URL, ProxyWithRetries(1), ProxyWithHealth(1, time.Minute))
+	server := NewHTTPServer()
+	server.Handle("/", handler)
+
+	for i := 0; i < 4; i++ {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+			t.Fatalf("第 %d 次请求期望由健康的上游处理, 得到 %d %q", i, rec.Code, rec.Body.String())
+		}
+	}
+	if hits != 4 {
+		t.Errorf("期望健康的上游处理 4 次请求, 得到 %d", hits)
+	}
+
+	// 没有重试且唯一的上游不可用时返回 502
+	server = NewHTTPServer()
+	server.Handle("/", Proxy(dead))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("期望状态码 502, 得到 %d", rec.Code)
+	}
+}
+
+// TestProxyWebSocket 测试 WebSocket 升级请求的透明转发
+func TestProxyWebSocket(t *testing.T) {
+	backend := NewHTTPServer()
+	backend.Handle("GET /ws", func(ctx *Context) {
+		conn, err := ctx.Upgrade(WSWithCheckOrigin(func(r *http.Request) bool { return true }))
+		if err != nil {
+			return
+		}
+		_, msg, err := conn.ReadMessage()
+		if err == nil {
+			_ = conn.Send(append([]byte("echo:"), msg...))
+		}
+		<-conn.Done()
+	})
+	upstream := httptest.NewServer(backend)
+	defer upstream.Close()
+
+	front := NewHTTPServer()
+	front.Handle("/", Proxy(upstream.URL))
+	srv := httptest.NewServer(front)
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("通过代理建立 WebSocket 连接失败: %v", err)
+	}
+	defer conn.Close()
+	if err = conn.WriteMessage(websocket.TextMessage, []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(msg) != "echo:hi" {
+		t.Errorf("期望收到 echo:hi, 得到 %q", msg)
+	}
+}
+
+// TestProxyInvalidTarget 测试非法的上游地址
+func TestProxyInvalidTarget(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("非法的上游地址应 panic")
+		}
+	}()
+	Proxy("not a url")
+}
@@ -0,0 +1,230 @@
+package ant
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrProxyProtocolHeader PROXY protocol 头部格式不正确
+var ErrProxyProtocolHeader = errors.New("web: PROXY protocol 头部格式不正确")
+
+// ErrProxyProtocolUntrusted 监听器开启了 PROXY protocol，但服务器没有配置受信任的代理
+var ErrProxyProtocolUntrusted = errors.New("web: 开启 PROXY protocol 需要配置受信任的代理")
+
+var (
+	// proxyV1Prefix v1 文本格式的前缀
+	proxyV1Prefix = []byte("PROXY ")
+	// proxyV2Signature v2 二进制格式的签名
+	proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// proxyV1MaxLen v1 头部的最大长度，包含结尾的 CRLF
+const proxyV1MaxLen = 107
+
+// proxyHeaderTimeout 读取 PROXY protocol 头部的超时时间
+const proxyHeaderTimeout = 5 * time.Second
+
+// ProxyProtocolListener 包装监听器，解析 PROXY protocol v1 与 v2 头部
+// 适用于部署在 HAProxy、AWS NLB 等四层负载均衡之后的场景，解析后连接的 RemoteAddr 为真实客户端地址
+// l: 原始监听器
+// trusted: 允许发送头部的对端，为nil时不解析任何头部，直接返回 l
+// 注意：
+// 1. 不受信任的对端发送的连接不做解析，原样交给 HTTP 服务器处理
+// 2. 没有头部的连接同样原样处理，便于负载均衡的健康检查直接访问
+func ProxyProtocolListener(l net.Listener, trusted *TrustedProxies) net.Listener {
+	// 任意对端都可以通过头部伪造客户端地址，与 RunListeners 一样必须限定受信任的代理
+	if trusted == nil {
+		return l
+	}
+	return &proxyListener{Listener: l, trusted: trusted}
+}
+
+// proxyListener 解析 PROXY protocol 头部的监听器
+type proxyListener struct {
+	net.Listener
+	trusted *TrustedProxies
+}
+
+// Accept 接受连接，头部在第一次读取或获取地址时才解析，避免阻塞 Accept
+func (l *proxyListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	addr, err := netip.ParseAddr(remoteIP(conn.RemoteAddr().String()))
+	if err != nil || !l.trusted.Contains(addr) {
+		return conn, nil
+	}
+	return &proxyConn{Conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// proxyConn 解析了 PROXY protocol 头部的连接
+type proxyConn struct {
+	net.Conn
+	r      *bufio.Reader
+	once   sync.Once
+	remote net.Addr
+	local  net.Addr
+	err    error
+}
+
+// Read 读取头部之后的数据
+func (c *proxyConn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(b)
+}
+
+// RemoteAddr 返回头部中的源地址，没有头部时返回连接的对端地址
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr 返回头部中的目标地址，没有头部时返回连接的本地地址
+func (c *proxyConn) LocalAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.local != nil {
+		return c.local
+	}
+	return c.Conn.LocalAddr()
+}
+
+// readHeader 读取并解析 PROXY protocol 头部
+func (c *proxyConn) readHeader() {
+	_ = c.Conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout))
+	defer func() {
+		_ = c.Conn.SetReadDeadline(time.Time{})
+	}()
+
+	// 先根据第一个字节判断，避免在没有头部的短连接上等待更多数据
+	first, err := c.r.Peek(1)
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			c.err = err
+		}
+		return
+	}
+	if first[0] != proxyV1Prefix[0] && first[0] != proxyV2Signature[0] {
+		return
+	}
+
+	// v2 签名比 v1 前缀长，先按 v1 前缀的长度判断
+	prefix, err := c.r.Peek(len(proxyV1Prefix))
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			c.err = err
+		}
+		return
+	}
+	switch {
+	case bytes.Equal(prefix, proxyV1Prefix):
+		c.remote, c.local, c.err = readProxyV1(c.r)
+	case bytes.Equal(prefix, proxyV2Signature[:len(prefix)]):
+		c.remote, c.local, c.err = readProxyV2(c.r)
+	}
+}
+
+// readProxyV1 解析 v1 文本格式的头部，例如 "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n"
+func readProxyV1(r *bufio.Reader) (remote, local net.Addr, err error) {
+	var line []byte
+	for len(line) < proxyV1MaxLen {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, ErrProxyProtocolHeader
+	}
+
+	fields := strings.Fields(string(line[:len(line)-2]))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, nil, ErrProxyProtocolHeader
+	}
+	src, err := parseProxyAddr(fields[2], fields[4])
+	if err != nil {
+		return nil, nil, err
+	}
+	dst, err := parseProxyAddr(fields[3], fields[5])
+	if err != nil {
+		return nil, nil, err
+	}
+	return src, dst, nil
+}
+
+// parseProxyAddr 解析 v1 头部中的地址与端口
+func parseProxyAddr(ip, port string) (net.Addr, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProxyProtocolHeader, err)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProxyProtocolHeader, err)
+	}
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(addr, uint16(p))), nil
+}
+
+// readProxyV2 解析 v2 二进制格式的头部
+func readProxyV2(r *bufio.Reader) (remote, local net.Addr, err error) {
+	header := make([]byte, 16)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(header[:12], proxyV2Signature) || header[12]>>4 != 2 {
+		return nil, nil, ErrProxyProtocolHeader
+	}
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return nil, nil, err
+	}
+
+	// LOCAL 命令由代理自身发起（例如健康检查），使用连接的真实地址
+	if header[12]&0x0F == 0 {
+		return nil, nil, nil
+	}
+	switch header[13] >> 4 {
+	case 1: // IPv4
+		if len(payload) < 12 {
+			return nil, nil, ErrProxyProtocolHeader
+		}
+		src := netip.AddrFrom4([4]byte(payload[0:4]))
+		dst := netip.AddrFrom4([4]byte(payload[4:8]))
+		return net.TCPAddrFromAddrPort(netip.AddrPortFrom(src, binary.BigEndian.Uint16(payload[8:10]))),
+			net.TCPAddrFromAddrPort(netip.AddrPortFrom(dst, binary.BigEndian.Uint16(payload[10:12]))), nil
+	case 2: // IPv6
+		if len(payload) < 36 {
+			return nil, nil, ErrProxyProtocolHeader
+		}
+		src := netip.AddrFrom16([16]byte(payload[0:16]))
+		dst := netip.AddrFrom16([16]byte(payload[16:32]))
+		return net.TCPAddrFromAddrPort(netip.AddrPortFrom(src, binary.BigEndian.Uint16(payload[32:34]))),
+			net.TCPAddrFromAddrPort(netip.AddrPortFrom(dst, binary.BigEndian.Uint16(payload[34:36]))), nil
+	default:
+		// unix 套接字等其他地址族不改变连接地址
+		return nil, nil, nil
+	}
+}
@@ -0,0 +1,194 @@
+package ant
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+)
+
+// proxyV2Header 构造 v2 格式的 IPv4 头部
+func proxyV2Header(cmd byte, src, dst [4]byte, srcPort, dstPort uint16) []byte {
+	header := append([]byte{}, proxyV2Signature...)
+	header = append(header, 0x20|cmd, 0x11, 0, 12)
+	header = append(header, src[:]...)
+	header = append(header, dst[:]...)
+	header = binary.BigEndian.AppendUint16(header, srcPort)
+	return binary.BigEndian.AppendUint16(header, dstPort)
+}
+
+// acceptWith 通过监听器建立连接并发送数据，返回服务端接受的连接
+func acceptWith(t *testing.T, trusted *TrustedProxies, data []byte) net.Conn {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	pl := ProxyProtocolListener(l, trusted)
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	if _, err = client.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := pl.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// TestProxyProtocolListener 测试 PROXY protocol 头部的解析
+func TestProxyProtocolListener(t *testing.T) {
+	testCases := []struct {
+		name       string
+		data       []byte
+		wantRemote string
+		wantErr    error
+	}{
+		{
+			name:       "v1 TCP4",
+			data:       []byte("PROXY TCP4 198.51.100.7 10.0.0.1 56324 443\r\nping"),
+			wantRemote: "198.51.100.7:56324",
+		},
+		{
+			name:       "v1 TCP6",
+			data:       []byte("PROXY TCP6 2001:db8::1 2001:db8::2 4000 443\r\nping"),
+			wantRemote: "[2001:db8::1]:4000",
+		},
+		{
+			name:       "v1 UNKNOWN",
+			data:       []byte("PROXY UNKNOWN\r\nping"),
+			wantRemote: "127.0.0.1",
+		},
+		{
+			name:       "v2 PROXY",
+			data:       append(proxyV2Header(1, [4]byte{198, 51, 100, 7}, [4]byte{10, 0, 0, 1}, 56324, 443), "ping"...),
+			wantRemote: "198.51.100.7:56324",
+		},
+		{
+			name:       "v2 LOCAL",
+			data:       append(proxyV2Header(0, [4]byte{198, 51, 100, 7}, [4]byte{10, 0, 0, 1}, 56324, 443), "ping"...),
+			wantRemote: "127.0.0.1",
+		},
+		{
+			name:       "没有头部",
+			data:       []byte("ping"),
+			wantRemote: "127.0.0.1",
+		},
+		{
+			name:    "v1 格式错误",
+			data:    []byte("PROXY TCP4 bad\r\nping"),
+			wantErr: ErrProxyProtocolHeader,
+		},
+	}
+
+	trusted, err := NewTrustedProxies("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := acceptWith(t, trusted, tc.data)
+			buf := make([]byte, 4)
+			_, err := io.ReadFull(conn, buf)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("期望错误 %v, 得到 %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(buf) != "ping" {
+				t.Errorf("头部之后的数据不正确: %q", buf)
+			}
+			remote := conn.RemoteAddr().String()
+			if tc.wantRemote == "127.0.0.1" {
+				remote = remoteIP(remote)
+			}
+			if remote != tc.wantRemote {
+				t.Errorf("期望对端地址 %s, 得到 %s", tc.wantRemote, remote)
+			}
+		})
+	}
+}
+
+// TestProxyProtocolUntrusted 测试不受信任的对端发送的头部不被解析
+func TestProxyProtocolUntrusted(t *testing.T) {
+	trusted, err := NewTrustedProxies("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 没有配置受信任的代理时同样不解析
+	for _, trusted := range []*TrustedProxies{trusted, nil} {
+		conn := acceptWith(t, trusted, []byte("PROXY TCP4 198.51.100.7 10.0.0.1 56324 443\r\n"))
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != "PROXY TCP4 198.51.100.7 10.0.0.1 56324 443\r\n" {
+			t.Errorf("不受信任的连接应原样读取, 得到 %q", line)
+		}
+		if ip := remoteIP(conn.RemoteAddr().String()); ip != "127.0.0.1" {
+			t.Errorf("期望对端地址 127.0.0.1, 得到 %s", ip)
+		}
+	}
+}
+
+// TestRunListenersProxyProtocol 测试监听器开启 PROXY protocol 后 ClientIP 返回真实地址
+func TestRunListenersProxyProtocol(t *testing.T) {
+	trusted, err := NewTrustedProxies("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewHTTPServer(ServerWithTrustedProxies(trusted))
+	server.Handle("GET /ip", func(ctx *Context) {
+		ctx.RespData = []byte(ctx.ClientIP())
+	})
+	addr := freeAddr(t)
+	go func() {
+		_ = server.RunListeners(TCP(addr).WithProxyProtocol())
+	}()
+	waitServing(t, addr)
+	t.Cleanup(func() { _ = server.Shutdown(t.Context()) })
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte("PROXY TCP4 198.51.100.7 10.0.0.1 56324 443\r\n" +
+		"GET /ip HTTP/1.1\r\nHost: example.com\r\nConnection: close\r\n\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "198.51.100.7" {
+		t.Errorf("期望客户端IP 198.51.100.7, 得到 %s", body)
+	}
+}
+
+// TestRunListenersProxyProtocolUntrusted 测试没有配置受信任代理时拒绝开启 PROXY protocol
+func TestRunListenersProxyProtocolUntrusted(t *testing.T) {
+	server := NewHTTPServer()
+	err := server.RunListeners(TCP(freeAddr(t)).WithProxyProtocol())
+	if !errors.Is(err, ErrProxyProtocolUntrusted) {
+		t.Errorf("期望 ErrProxyProtocolUntrusted, 得到 %v", err)
+	}
+}
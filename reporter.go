@@ -0,0 +1,138 @@
+package ant
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Redacted 敏感请求头被脱敏后的替换值
+const Redacted = "[REDACTED]"
+
+// DefaultSensitiveHeaders 默认需要脱敏的请求头
+// NewErrorEvent 使用该列表，recovery 与 devmode 中间件以它作为默认值
+// 可以在启动时、处理请求之前修改
+var DefaultSensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// ErrorEvent 上报给错误跟踪系统的错误事件
+// 事件只包含请求信息的快照，上报实现可以安全地异步处理
+type ErrorEvent struct {
+	// Err 发生的错误
+	Err error
+	// Time 错误发生的时间
+	Time time.Time
+	// Method 请求方法
+	Method string
+	// URL 请求的完整URL
+	URL string
+	// Route 命中的路由模式
+	Route string
+	// RemoteAddr 客户端地址
+	RemoteAddr string
+	// Header 请求头的副本，DefaultSensitiveHeaders 中的请求头已脱敏
+	Header map[string][]string
+	// Stack 错误发生时的调用栈，可能为空
+	Stack []byte
+	// User 当前用户标识，可能为空
+	User string
+	// SessionID 当前会话ID，可能为空
+	SessionID string
+	// Tags 附加的标签信息
+	Tags map[string]string
+	// Panic 由panic引起的错误附带的结构化报告，其他错误为nil
+	Panic *PanicReport
+}
+
+// PanicReport panic发生时的结构化现场快照
+type PanicReport struct {
+	// Value panic的原始值
+	Value any `json:"-"`
+	// Message panic值的字符串形式
+	Message string `json:"message"`
+	// Route 命中的路由模式
+	Route string `json:"route"`
+	// Method 请求方法
+	Method string `json:"method"`
+	// Path 请求路径
+	Path string `json:"path"`
+	// Params 路径参数
+	Params map[string]string `json:"params,omitempty"`
+	// Header 请求头，敏感头部已脱敏
+	Header map[string][]string `json:"header,omitempty"`
+	// BodyPrefix 处理器在panic前已读取的请求体前缀
+	BodyPrefix []byte `json:"body_prefix,omitempty"`
+	// Stack 发生panic的goroutine调用栈
+	Stack string `json:"stack"`
+	// Goroutines panic时的goroutine数量
+	Goroutines int `json:"goroutines"`
+	// HeapAlloc 已分配的堆内存字节数
+	HeapAlloc uint64 `json:"heap_alloc"`
+	// HeapInuse 使用中的堆内存字节数
+	HeapInuse uint64 `json:"heap_inuse"`
+	// Sys 从操作系统获取的内存字节数
+	Sys uint64 `json:"sys"`
+	// NumGC 已完成的GC次数
+	NumGC uint32 `json:"num_gc"`
+	// Time panic发生的时间
+	Time time.Time `json:"time"`
+}
+
+// NewErrorEvent 根据请求上下文创建错误事件
+// ctx: 请求上下文
+// err: 发生的错误
+// 返回值: 填充了请求信息的错误事件
+// 注意：事件会发送给第三方错误跟踪系统，DefaultSensitiveHeaders 中的请求头会被脱敏
+func NewErrorEvent(ctx *Context, err error) *ErrorEvent {
+	ev := &ErrorEvent{
+		Err:  err,
+		Time: time.Now(),
+		Tags: make(map[string]string),
+	}
+	if ctx.Req != nil {
+		ev.Method = ctx.Req.Method
+		ev.URL = ctx.Req.URL.String()
+		ev.Route = ctx.Req.Pattern
+		ev.RemoteAddr = ctx.Req.RemoteAddr
+		ev.Header = RedactHeader(ctx.Req.Header, DefaultSensitiveHeaders)
+	}
+	return ev
+}
+
+// RedactHeader 返回请求头的副本，其中 names 列出的请求头的值被替换为 Redacted
+// h: 原始请求头，不会被修改
+// names: 需要脱敏的请求头名称，不区分大小写
+func RedactHeader(h http.Header, names []string) http.Header {
+	res := h.Clone()
+	for _, name := range names {
+		if _, ok := res[http.CanonicalHeaderKey(name)]; ok {
+			res.Set(name, Redacted)
+		}
+	}
+	return res
+}
+
+// ErrorReporter 错误上报接口
+// 由恢复中间件与统一错误处理中间件调用，用于对接 Sentry 等错误跟踪系统
+type ErrorReporter interface {
+	// Report 上报一个错误事件
+	// ctx: 请求的上下文，可用于获取链路追踪等信息
+	// ev: 错误事件
+	Report(ctx context.Context, ev *ErrorEvent)
+}
+
+// ErrorReporterFunc 函数形式的错误上报实现
+type ErrorReporterFunc func(ctx context.Context, ev *ErrorEvent)
+
+// Report 实现 ErrorReporter 接口
+func (f ErrorReporterFunc) Report(ctx context.Context, ev *ErrorEvent) {
+	f(ctx, ev)
+}
+
+// NopErrorReporter 不做任何处理的错误上报实现，作为默认值使用
+type NopErrorReporter struct{}
+
+// Report 实现 ErrorReporter 接口
+func (NopErrorReporter) Report(context.Context, *ErrorEvent) {}
+
+// IdentityFunc 从请求上下文中解析当前用户与会话ID的函数
+type IdentityFunc func(ctx *Context) (user string, sessionID string)
@@ -0,0 +1,45 @@
+package ant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestNewErrorEvent 测试错误事件的请求信息快照
+func TestNewErrorEvent(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/users/1?debug=1", nil)
+	req.Header.Set("X-Request-Id", "req-1")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Cookie", "sid=secret")
+	ctx := &Context{Req: req, Resp: httptest.NewRecorder()}
+
+	err := errors.New("boom")
+	ev := NewErrorEvent(ctx, err)
+
+	if !errors.Is(ev.Err, err) {
+		t.Errorf("期望错误 %v, 得到 %v", err, ev.Err)
+	}
+	if ev.Method != http.MethodPost || ev.URL != "/users/1?debug=1" {
+		t.Errorf("请求信息不正确: %s %s", ev.Method, ev.URL)
+	}
+
+	// 敏感请求头不应原样发送给上报实现
+	if ev.Header["Authorization"][0] != Redacted || ev.Header["Cookie"][0] != Redacted {
+		t.Errorf("敏感请求头没有脱敏: %v", ev.Header)
+	}
+	if req.Header.Get("Authorization") != "Bearer secret" {
+		t.Error("脱敏不应修改原始请求头")
+	}
+
+	// 修改原始请求头不应影响事件中的副本
+	req.Header.Set("X-Request-Id", "changed")
+	if got := ev.Header["X-Request-Id"][0]; got != "req-1" {
+		t.Errorf("期望请求头副本不受影响, 得到 %s", got)
+	}
+
+	// NopErrorReporter 不应产生任何副作用
+	NopErrorReporter{}.Report(context.Background(), ev)
+}
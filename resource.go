@@ -0,0 +1,177 @@
+package ant
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ResourceAction RESTful 资源的操作
+type ResourceAction string
+
+const (
+	// ActionIndex 列出资源，GET /users
+	ActionIndex ResourceAction = "index"
+	// ActionShow 获取单个资源，GET /users/{id}
+	ActionShow ResourceAction = "show"
+	// ActionCreate 创建资源，POST /users
+	ActionCreate ResourceAction = "create"
+	// ActionUpdate 更新资源，PUT 与 PATCH /users/{id}
+	ActionUpdate ResourceAction = "update"
+	// ActionDelete 删除资源，DELETE /users/{id}
+	ActionDelete ResourceAction = "delete"
+)
+
+// ResourceIndexer 实现了列出资源操作的控制器
+type ResourceIndexer interface {
+	Index(ctx *Context)
+}
+
+// ResourceShower 实现了获取单个资源操作的控制器
+type ResourceShower interface {
+	Show(ctx *Context)
+}
+
+// ResourceCreator 实现了创建资源操作的控制器
+type ResourceCreator interface {
+	Create(ctx *Context)
+}
+
+// ResourceUpdater 实现了更新资源操作的控制器
+type ResourceUpdater interface {
+	Update(ctx *Context)
+}
+
+// ResourceDeleter 实现了删除资源操作的控制器
+type ResourceDeleter interface {
+	Delete(ctx *Context)
+}
+
+// resourceConfig Resource 的配置
+type resourceConfig struct {
+	name    string
+	idParam string
+	mdls    map[ResourceAction][]Middleware
+	all     []Middleware
+}
+
+// ResourceOption 定义资源注册的配置选项函数类型
+type ResourceOption func(cfg *resourceConfig)
+
+// ResourceWithName 设置资源名称，用于路由描述信息中的 OperationID 与 Tags
+// 默认为路径中最后一个非参数段，例如 "/users" 为 "users"
+func ResourceWithName(name string) ResourceOption {
+	return func(cfg *resourceConfig) {
+		cfg.name = name
+	}
+}
+
+// ResourceWithIDParam 设置资源标识的路径参数名，默认为 "id"
+// 嵌套资源需要使用不同的参数名，例如 "/users/{id}/posts" 的资源应设置为 "postID"
+func ResourceWithIDParam(name string) ResourceOption {
+	return func(cfg *resourceConfig) {
+		cfg.idParam = name
+	}
+}
+
+// ResourceWithMiddleware 设置作用于资源全部操作的中间件
+func ResourceWithMiddleware(mdls ...Middleware) ResourceOption {
+	return func(cfg *resourceConfig) {
+		cfg.all = append(cfg.all, mdls...)
+	}
+}
+
+// ResourceWithActionMiddleware 设置只作用于指定操作的中间件，例如只对创建、更新、删除要求登录
+// 位于 ResourceWithMiddleware 设置的中间件之内
+func ResourceWithActionMiddleware(action ResourceAction, mdls ...Middleware) ResourceOption {
+	return func(cfg *resourceConfig) {
+		cfg.mdls[action] = append(cfg.mdls[action], mdls...)
+	}
+}
+
+// Resource 按 RESTful 约定注册资源的路由
+// prefix: 资源路径，例如 "/users"
+// controller: 控制器，只为实现了的操作接口注册路由：
+//
+//	Index   GET    /users
+//	Create  POST   /users
+//	Show    GET    /users/{id}
+//	Update  PUT    /users/{id} 与 PATCH /users/{id}
+//	Delete  DELETE /users/{id}
+//
+// opts: 资源的配置选项
+// 注意：
+// 1. 每条路由都会通过 Describe 设置描述信息，OperationID 形如 "users.index"（PATCH 为 "users.patch"），Tags 为资源名称
+// 2. 控制器没有实现任何操作接口、或者路由冲突时 panic
+func (s *HTTPServer) Resource(prefix string, controller any, opts ...ResourceOption) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	cfg := &resourceConfig{
+		name:    resourceName(prefix),
+		idParam: "id",
+		mdls:    make(map[ResourceAction][]Middleware),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	collection := prefix
+	if collection == "" {
+		collection = "/"
+	}
+	member := prefix + "/{" + cfg.idParam + "}"
+
+	type route struct {
+		action  ResourceAction
+		methods []string
+		path    string
+		handler HandleFunc
+		summary string
+	}
+	var routes []route
+	if c, ok := controller.(ResourceIndexer); ok {
+		routes = append(routes, route{ActionIndex, []string{http.MethodGet}, collection, c.Index, "列出 " + cfg.name})
+	}
+	if c, ok := controller.(ResourceCreator); ok {
+		routes = append(routes, route{ActionCreate, []string{http.MethodPost}, collection, c.Create, "创建 " + cfg.name})
+	}
+	if c, ok := controller.(ResourceShower); ok {
+		routes = append(routes, route{ActionShow, []string{http.MethodGet}, member, c.Show, "获取 " + cfg.name})
+	}
+	if c, ok := controller.(ResourceUpdater); ok {
+		routes = append(routes, route{ActionUpdate, []string{http.MethodPut, http.MethodPatch}, member, c.Update, "更新 " + cfg.name})
+	}
+	if c, ok := controller.(ResourceDeleter); ok {
+		routes = append(routes, route{ActionDelete, []string{http.MethodDelete}, member, c.Delete, "删除 " + cfg.name})
+	}
+	if len(routes) == 0 {
+		panic("web: 资源 " + prefix + " 的控制器没有实现任何操作接口")
+	}
+
+	for _, r := range routes {
+		mdls := append(append([]Middleware{}, cfg.all...), cfg.mdls[r.action]...)
+		for _, method := range r.methods {
+			pattern := method + " " + r.path
+			opID := cfg.name + "." + string(r.action)
+			if method == http.MethodPatch {
+				// PUT 与 PATCH 共用处理函数，OperationID 仍需唯一
+				opID = cfg.name + ".patch"
+			}
+			s.handle(pattern, r.handler, mdls...)
+			s.Describe(pattern, RouteMeta{
+				OperationID: opID,
+				Summary:     r.summary,
+				Tags:        []string{cfg.name},
+			})
+		}
+	}
+}
+
+// resourceName 返回路径中最后一个非参数段
+func resourceName(prefix string) string {
+	segments := strings.Split(strings.Trim(prefix, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if seg := segments[i]; seg != "" && !strings.HasPrefix(seg, "{") {
+			return seg
+		}
+	}
+	return "resource"
+}
@@ -0,0 +1,113 @@
+package ant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// userController 实现了全部操作的控制器
+type userController struct{}
+
+func (userController) Index(ctx *Context)  { ctx.RespData = []byte("index") }
+func (userController) Show(ctx *Context)   { ctx.RespData = []byte("show " + ctx.Req.PathValue("id")) }
+func (userController) Create(ctx *Context) { ctx.RespData = []byte("create") }
+func (userController) Update(ctx *Context) {
+	ctx.RespData = []byte("update " + ctx.Req.PathValue("id"))
+}
+func (userController) Delete(ctx *Context) {
+	ctx.RespData = []byte("delete " + ctx.Req.PathValue("id"))
+}
+
+// readOnlyController 只实现了读取操作的控制器
+type readOnlyController struct{}
+
+func (readOnlyController) Index(ctx *Context) {
+	ctx.RespData = []byte("posts of " + ctx.Req.PathValue("id"))
+}
+func (readOnlyController) Show(ctx *Context) {
+	ctx.RespData = []byte("post " + ctx.Req.PathValue("postID"))
+}
+
+// TestResource 测试资源路由的注册
+func TestResource(t *testing.T) {
+	server := NewHTTPServer()
+	server.Resource("/users", userController{})
+	server.Resource("/users/{id}/posts", readOnlyController{}, ResourceWithIDParam("postID"))
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/users", "index"},
+		{http.MethodPost, "/users", "create"},
+		{http.MethodGet, "/users/1", "show 1"},
+		{http.MethodPut, "/users/1", "update 1"},
+		{http.MethodPatch, "/users/1", "update 1"},
+		{http.MethodDelete, "/users/1", "delete 1"},
+		{http.MethodGet, "/users/1/posts", "posts of 1"},
+		{http.MethodGet, "/users/1/posts/2", "post 2"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Body.String() != tt.want {
+			t.Errorf("%s %s 期望 %q，实际 %q", tt.method, tt.path, tt.want, w.Body.String())
+		}
+	}
+
+	// 没有实现的操作不注册路由
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/users/1/posts/2", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("未实现的操作期望 405，实际 %d", w.Code)
+	}
+
+	meta, ok := server.RouteMeta("PATCH /users/{id}")
+	want := RouteMeta{OperationID: "users.patch", Summary: "更新 users", Tags: []string{"users"}}
+	if !ok || !reflect.DeepEqual(meta, want) {
+		t.Errorf("路由描述信息错误: %+v", meta)
+	}
+	if meta, _ = server.RouteMeta("GET /users/{id}/posts/{postID}"); meta.OperationID != "posts.show" {
+		t.Errorf("嵌套资源的 OperationID 错误: %s", meta.OperationID)
+	}
+}
+
+// TestResourceMiddleware 测试资源级与操作级中间件
+func TestResourceMiddleware(t *testing.T) {
+	var calls []string
+	mark := func(name string) Middleware {
+		return func(next HandleFunc) HandleFunc {
+			return func(ctx *Context) {
+				calls = append(calls, name)
+				next(ctx)
+			}
+		}
+	}
+	server := NewHTTPServer()
+	server.Resource("/users", userController{},
+		ResourceWithMiddleware(mark("all")),
+		ResourceWithActionMiddleware(ActionDelete, mark("auth")),
+	)
+
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	if strings.Join(calls, ",") != "all" {
+		t.Errorf("列出资源时期望只执行资源级中间件，实际 %v", calls)
+	}
+	calls = nil
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/users/1", nil))
+	if strings.Join(calls, ",") != "all,auth" {
+		t.Errorf("删除资源时期望依次执行 all、auth，实际 %v", calls)
+	}
+}
+
+// TestResourceNoActions 测试没有实现任何操作的控制器
+func TestResourceNoActions(t *testing.T) {
+	server := NewHTTPServer()
+	if err := catchPanic(func() { server.Resource("/users", struct{}{}) }); err == nil {
+		t.Error("期望 panic")
+	}
+}
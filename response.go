@@ -0,0 +1,221 @@
+package ant
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ResponseWriter 框架使用的响应写入器
+// 记录状态码与写入的字节数，并保留底层写入器的 Flush、Hijack 与 Push 能力
+// 通过服务器处理的请求中 ctx.Resp 总是实现了该接口，可以通过类型断言获取：
+//
+//	if rw, ok := ctx.Resp.(ant.ResponseWriter); ok {
+//		log.Println(rw.Status(), rw.Size())
+//	}
+type ResponseWriter interface {
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	// Status 返回已写入的状态码，尚未写入时返回0
+	Status() int
+	// Size 返回已写入的响应体字节数
+	Size() int
+	// Written 返回响应头是否已经写入
+	Written() bool
+	// Unwrap 返回底层的 ResponseWriter，供 http.ResponseController 使用
+	Unwrap() http.ResponseWriter
+}
+
+// ResponseStatus 返回请求的响应状态码，供访问日志、审计等中间件在调用 next 之后使用
+// 处理函数直接写入响应时以实际写入的状态码为准，否则使用 RespStatusCode，都没有设置时返回 200
+func (c *Context) ResponseStatus() int {
+	if rw, ok := c.Resp.(ResponseWriter); ok && rw.Status() != 0 {
+		return rw.Status()
+	}
+	if c.RespStatusCode != 0 {
+		return c.RespStatusCode
+	}
+	return http.StatusOK
+}
+
+// 确保 responseWriter 实现了 ResponseWriter、http.Pusher 与 io.ReaderFrom 接口
+var (
+	_ ResponseWriter = (*responseWriter)(nil)
+	_ http.Pusher    = (*responseWriter)(nil)
+	_ io.ReaderFrom  = (*responseWriter)(nil)
+)
+
+// responseWriter ResponseWriter 的实现，随 Context 一起复用
+type responseWriter struct {
+	http.ResponseWriter
+	status   int
+	size     int
+	hijacked bool
+
+	// cachePolicy 服务器的默认缓存策略，为nil时不处理
+	cachePolicy CachePolicy
+	// hooks 写入响应头前调用的函数，通过 Context.OnWriteHeader 注册
+	hooks []func(status int, header http.Header)
+}
+
+// reset 绑定新的底层写入器
+func (w *responseWriter) reset(rw http.ResponseWriter) {
+	w.ResponseWriter = rw
+	w.status = 0
+	w.size = 0
+	w.hijacked = false
+	w.cachePolicy = nil
+	clear(w.hooks)
+	w.hooks = w.hooks[:0]
+}
+
+// WriteHeader 写入状态码，重复调用会被忽略，避免 superfluous WriteHeader 警告
+func (w *responseWriter) WriteHeader(code int) {
+	if w.status != 0 || w.hijacked {
+		return
+	}
+	// 1xx 信息性响应可以多次发送，不计为最终状态码
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.commit(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write 写入响应体，未写入状态码时记为 200
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.hijacked {
+		return 0, http.ErrHijacked
+	}
+	if w.status == 0 {
+		w.commit(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+// commit 确定最终状态码，在写入响应头前调用钩子并应用默认缓存策略
+func (w *responseWriter) commit(code int) {
+	w.status = code
+	for _, hook := range w.hooks {
+		hook(code, w.ResponseWriter.Header())
+	}
+	w.applyCachePolicy()
+}
+
+// applyCachePolicy 在写入响应头前应用默认缓存策略
+// 只处理 2xx 响应，处理函数已经设置了 Cache-Control 时不覆盖
+func (w *responseWriter) applyCachePolicy() {
+	if w.cachePolicy == nil || w.status < 200 || w.status >= 300 {
+		return
+	}
+	header := w.ResponseWriter.Header()
+	if _, ok := header["Cache-Control"]; ok {
+		return
+	}
+	if cc, ok := w.cachePolicy.lookup(header.Get("Content-Type")); ok {
+		if v := cc.String(); v != "" {
+			header.Set("Cache-Control", v)
+		}
+	}
+}
+
+// Status 实现 ResponseWriter 接口
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+// Size 实现 ResponseWriter 接口
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+// Written 实现 ResponseWriter 接口
+func (w *responseWriter) Written() bool {
+	return w.status != 0 || w.hijacked
+}
+
+// Unwrap 实现 ResponseWriter 接口
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush 将缓冲的数据发送给客户端，底层写入器不支持时忽略
+func (w *responseWriter) Flush() {
+	if w.status == 0 {
+		w.commit(http.StatusOK)
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// copyBufPool ReadFrom 使用的复制缓冲区
+var copyBufPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 32<<10)
+		return &buf
+	},
+}
+
+// ReadFrom 实现 io.ReaderFrom 接口，io.Copy 与 http.ServeContent 发送文件时使用
+// 底层写入器支持时交给其实现，例如 HTTP/1.x 连接上的 sendfile，否则使用池化的缓冲区复制，避免每次复制都分配缓冲区
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if w.hijacked {
+		return 0, http.ErrHijacked
+	}
+	if w.status == 0 {
+		w.commit(http.StatusOK)
+	}
+	var (
+		n   int64
+		err error
+	)
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		buf := copyBufPool.Get().(*[]byte)
+		// 隐藏 r 的 WriteTo 与底层写入器的其他方法，确保使用池化的缓冲区
+		n, err = io.CopyBuffer(struct{ io.Writer }{w.ResponseWriter}, struct{ io.Reader }{r}, *buf)
+		copyBufPool.Put(buf)
+	}
+	w.size += int(n)
+	return n, err
+}
+
+// Hijack 接管底层连接，接管后框架不再写入响应
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Push 实现 http.Pusher 接口
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	pusher, ok := w.ResponseWriter.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return pusher.Push(target, opts)
+}
+
+// OnWriteHeader 注册在写入响应头之前调用的函数，可以在此修改最终的响应头
+// 无论响应由处理函数直接写入，还是由服务器在中间件链结束后写入，钩子都只调用一次
+// 钩子按注册顺序调用；响应头已经写入时不再调用
+// 中间件包装了 ctx.Resp 时（例如 compress）钩子在被包装的写入器写入响应头时调用
+// 注意：Context 不是由服务器创建时（例如测试中直接构造的 Context）立即调用，status 为0
+func (c *Context) OnWriteHeader(fn func(status int, header http.Header)) {
+	if c.rw.ResponseWriter == nil {
+		fn(0, c.Resp.Header())
+		return
+	}
+	if c.rw.Written() {
+		return
+	}
+	c.rw.hooks = append(c.rw.hooks, fn)
+}
@@ -0,0 +1,195 @@
+package ant
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestResponseWriterCapture 测试包装后的写入器记录状态码与字节数
+func TestResponseWriterCapture(t *testing.T) {
+	var status, size int
+	server := NewHTTPServer()
+	server.Use(func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			rw := ctx.Resp.(ResponseWriter)
+			status, size = rw.Status(), rw.Size()
+		}
+	})
+	server.Handle("GET /json", func(ctx *Context) {
+		_ = ctx.RespJSON(http.StatusAccepted, map[string]string{"a": "b"})
+	})
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/json", nil))
+	if status != http.StatusAccepted || size != rec.Body.Len() {
+		t.Errorf("期望记录状态码 202 与 %d 字节, 得到 %d %d", rec.Body.Len(), status, size)
+	}
+}
+
+// TestContextResponseStatus 测试中间件在 next 之后读取的响应状态码
+func TestContextResponseStatus(t *testing.T) {
+	var status int
+	server := NewHTTPServer()
+	server.Use(func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			status = ctx.ResponseStatus()
+		}
+	})
+	server.Handle("GET /default", func(ctx *Context) {})
+	server.Handle("GET /code", func(ctx *Context) { ctx.RespStatusCode = http.StatusCreated })
+	server.Handle("GET /written", func(ctx *Context) {
+		ctx.RespStatusCode = http.StatusCreated
+		ctx.Resp.WriteHeader(http.StatusAccepted)
+	})
+
+	tests := map[string]int{
+		"/default": http.StatusOK,
+		"/code":    http.StatusCreated,
+		"/written": http.StatusAccepted,
+	}
+	for path, want := range tests {
+		server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		if status != want {
+			t.Errorf("%s 期望状态码 %d，得到 %d", path, want, status)
+		}
+	}
+}
+
+// TestResponseWriterReadFrom 测试 io.Copy 通过 ReadFrom 发送并记录字节数
+// 底层写入器不支持 ReadFrom 时使用池化的缓冲区，真实连接上交给底层实现（sendfile）
+func TestResponseWriterReadFrom(t *testing.T) {
+	content := strings.Repeat("sendfile", 8<<10)
+	path := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var status, size int
+	server := NewHTTPServer()
+	server.Handle("GET /file", func(ctx *Context) {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer f.Close()
+		_, _ = io.Copy(ctx.Resp, f)
+		rw := ctx.Resp.(ResponseWriter)
+		status, size = rw.Status(), rw.Size()
+	})
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/file", nil))
+	if rec.Body.String() != content || status != http.StatusOK || size != len(content) {
+		t.Errorf("池化缓冲区复制不正确: %d 字节，状态码 %d，记录 %d 字节", rec.Body.Len(), status, size)
+	}
+
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != content || status != http.StatusOK || size != len(content) {
+		t.Errorf("真实连接上的复制不正确: %d 字节，状态码 %d，记录 %d 字节", len(body), status, size)
+	}
+}
+
+// TestResponseNoDuplicateWrite 测试处理函数直接写入后框架不再重复写入
+func TestResponseNoDuplicateWrite(t *testing.T) {
+	tpl, err := template.New("page").Parse("<p>{{.}}</p>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewHTTPServer(ServerWithTemplateEngine(&GoTemplateEngine{T: tpl}))
+	server.Handle("GET /page", func(ctx *Context) {
+		_ = ctx.RespTemplate("page", "hi")
+	})
+	server.Handle("GET /direct", func(ctx *Context) {
+		ctx.Resp.WriteHeader(http.StatusCreated)
+		// 处理函数写入状态码后再设置的状态码不会覆盖已写入的状态码
+		ctx.RespStatusCode = http.StatusTeapot
+		ctx.RespData = []byte("body")
+	})
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/page", nil))
+	if got := rec.Body.String(); got != "<p>hi</p>" {
+		t.Errorf("模板内容被重复写入: %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/direct", nil))
+	if rec.Code != http.StatusCreated || rec.Body.String() != "body" {
+		t.Errorf("期望 201 与 body, 得到 %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+// TestResponseWriterFlushAndHijack 测试 Flush 与 Hijack 委托给底层写入器
+func TestResponseWriterFlushAndHijack(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := &responseWriter{}
+	rw.reset(rec)
+
+	rw.Flush()
+	if !rec.Flushed || rw.Status() != http.StatusOK {
+		t.Errorf("Flush 未委托给底层写入器: %v %d", rec.Flushed, rw.Status())
+	}
+	if _, _, err := rw.Hijack(); err == nil {
+		t.Error("底层写入器不支持 Hijack 时期望返回错误")
+	}
+	if rw.hijacked {
+		t.Error("Hijack 失败时不应标记为已接管")
+	}
+	if err := rw.Push("/app.js", nil); err != http.ErrNotSupported {
+		t.Errorf("期望 http.ErrNotSupported, 得到 %v", err)
+	}
+	if _, err := rw.Write([]byte("abc")); err != nil || rw.Size() != 3 {
+		t.Errorf("写入字节数不正确: %d %v", rw.Size(), err)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), []byte("abc")) {
+		t.Errorf("写入内容不正确: %q", rec.Body.String())
+	}
+}
+
+// TestOnWriteHeader 测试写入响应头前的钩子只调用一次
+func TestOnWriteHeader(t *testing.T) {
+	var calls []int
+	server := NewHTTPServer()
+	server.Use(func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			ctx.OnWriteHeader(func(status int, header http.Header) {
+				calls = append(calls, status)
+				header.Set("X-Hook", "1")
+			})
+			next(ctx)
+		}
+	})
+	server.Handle("GET /buffered", func(ctx *Context) {
+		ctx.RespStatusCode = http.StatusCreated
+		ctx.RespData = []byte("ok")
+	})
+	server.Handle("GET /direct", func(ctx *Context) {
+		_, _ = ctx.Resp.Write([]byte("a"))
+		_, _ = ctx.Resp.Write([]byte("b"))
+	})
+
+	for path, status := range map[string]int{"/buffered": http.StatusCreated, "/direct": http.StatusOK} {
+		calls = nil
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if len(calls) != 1 || calls[0] != status || rec.Header().Get("X-Hook") != "1" {
+			t.Errorf("%s: 钩子应以状态码 %d 调用一次，实际为 %v，响应头 %v", path, status, calls, rec.Header())
+		}
+	}
+}
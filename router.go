@@ -0,0 +1,286 @@
+package ant
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"unsafe"
+)
+
+// ErrRouteLimit 注册的路由超出了 RouteLimits 的限制
+var ErrRouteLimit = errors.New("web: 超出路由限制")
+
+// ErrUnknownRoute 路由模式没有注册过
+var ErrUnknownRoute = errors.New("web: 未注册的路由")
+
+// RouteLimits 路由注册的限制，用于拒绝异常的注册，例如程序错误地自动生成了上百万条路由
+// 各字段为零时表示不限制
+type RouteLimits struct {
+	// MaxRoutes 最多可以注册的路由数量
+	MaxRoutes int
+	// MaxDepth 路径的最大段数，例如 "/users/{id}/posts" 为 3 段
+	MaxDepth int
+	// MaxParams 单条路由最多包含的路径参数数量
+	MaxParams int
+	// MaxPatternLength 路由模式的最大长度（字节）
+	MaxPatternLength int
+}
+
+// ServerWithRouteLimits 设置路由注册的限制
+// 超出限制时 Handle 会 panic，与 http.ServeMux 处理冲突路由的方式一致
+// panic 的值是包装了 ErrRouteLimit 的 error
+func ServerWithRouteLimits(limits RouteLimits) ServerOption {
+	return func(server *HTTPServer) {
+		server.routeLimits = limits
+	}
+}
+
+// RouterStats 路由结构的统计信息
+type RouterStats struct {
+	// Routes 已注册的路由数量
+	Routes int
+	// Nodes 路由树的节点数量，按主机、方法与路径段逐级展开，公共前缀共享节点
+	Nodes int
+	// MaxDepth 路径段数的最大值
+	MaxDepth int
+	// Params 所有路由的路径参数总数
+	Params int
+	// Wildcards 以 {name...} 匹配剩余路径的路由数量
+	Wildcards int
+	// MemoryBytes 路由结构占用内存的估算值，包含节点、路由模式与参数名
+	// 只用于观察增长趋势，不是精确值
+	MemoryBytes int
+}
+
+// routeNodeSize 估算单个路由树节点的固定开销：节点结构体与其在父节点中的映射项
+const routeNodeSize = int(unsafe.Sizeof(routeNode{})) + 48
+
+// routeEntrySize 估算单条路由的固定开销：处理函数闭包、参数名切片与 ServeMux 中的模式记录
+const routeEntrySize = 256
+
+// routeNode 路由树的节点，只用于统计，实际的匹配仍由 http.ServeMux 完成
+type routeNode struct {
+	children map[string]*routeNode
+}
+
+// routeTable 已注册路由的统计数据
+type routeTable struct {
+	root     routeNode
+	stats    RouterStats
+	patterns []string
+	// meta 通过 Describe 设置的路由描述信息
+	meta map[string]RouteMeta
+	// enabled 路由是否启用，在请求中读取，不需要持有锁
+	enabled map[string]*atomic.Bool
+	// constraints 路径参数的约束，键为去掉约束后的路由模式
+	constraints map[string][]paramConstraint
+	// deprecations 弃用路由的响应头，在请求中读取，不需要持有锁
+	deprecations map[string]*atomic.Pointer[deprecationHeaders]
+}
+
+// RouteMeta 路由的描述信息，用于生成接口文档
+type RouteMeta struct {
+	// OperationID 操作的唯一标识，例如 "listUsers"
+	OperationID string
+	// Summary 简短的说明
+	Summary string
+	// Tags 分组标签，例如资源名称
+	Tags []string
+	// Description 详细说明，可以使用 Markdown
+	Description string
+	// Deprecated 是否已弃用，只在接口文档中标记，需要同时发送弃用响应头时使用 Deprecation
+	Deprecated bool
+	// Deprecation 弃用的时间、停止服务的时间与替代接口，为nil时没有弃用
+	Deprecation *Deprecation
+	// Request 请求体的描述，为nil时没有请求体
+	Request *Body
+	// Responses 按状态码描述的响应，为空时生成文档会使用不带内容的 200 响应
+	Responses map[int]Body
+	// Authorization 访问路由需要的角色与权限范围，为nil时不限制，由 middleware/authz 检查
+	Authorization *AuthorizationPolicy
+	// CORS 覆盖全局跨域策略的路由策略，为nil时使用全局策略，由 middleware/cors 使用
+	CORS *CORSPolicy
+}
+
+// Body 请求体或响应体的描述
+type Body struct {
+	// Description 说明
+	Description string
+	// ContentType 内容类型，默认为 application/json
+	ContentType string
+	// Type 内容对应的 Go 类型，生成文档时通过反射得到结构，为nil时不描述结构
+	Type reflect.Type
+	// Schema 手动编写的结构，可以包含 Minimum、Pattern 等校验关键字，设置后优先于 Type
+	Schema *Schema
+	// Example 示例值，会按 JSON 序列化
+	Example any
+}
+
+// BodyOf 返回以 T 描述结构的 JSON 请求体或响应体
+// 例如 RouteMeta{Request: ant.BodyOf[createUserReq]("新用户")}
+func BodyOf[T any](description string) *Body {
+	return &Body{Description: description, Type: reflect.TypeFor[T]()}
+}
+
+// RouterStats 返回路由结构的统计信息
+func (s *HTTPServer) RouterStats() RouterStats {
+	s.routeMu.Lock()
+	defer s.routeMu.Unlock()
+	return s.routes.stats
+}
+
+// Routes 返回按注册顺序排列的路由模式
+func (s *HTTPServer) Routes() []string {
+	s.routeMu.Lock()
+	defer s.routeMu.Unlock()
+	routes := make([]string, len(s.routes.patterns))
+	copy(routes, s.routes.patterns)
+	return routes
+}
+
+// Describe 设置路由的描述信息，已有的描述信息会被覆盖
+// pattern: 路由模式，与注册时使用的模式相同
+func (s *HTTPServer) Describe(pattern string, meta RouteMeta) {
+	pattern = routeKey(pattern)
+	s.routeMu.Lock()
+	defer s.routeMu.Unlock()
+	if s.routes.meta == nil {
+		s.routes.meta = make(map[string]RouteMeta)
+	}
+	s.routes.meta[pattern] = meta
+	s.routes.syncDeprecation(pattern)
+}
+
+// UpdateRouteMeta 修改路由已有的描述信息，没有描述信息时从零值开始
+// 例如为 Resource 生成的路由补充请求体与响应的描述：
+//
+//	s.UpdateRouteMeta("POST /users", func(meta *ant.RouteMeta) {
+//		meta.Request = ant.BodyOf[createUserReq]("新用户")
+//	})
+func (s *HTTPServer) UpdateRouteMeta(pattern string, fn func(meta *RouteMeta)) {
+	pattern = routeKey(pattern)
+	s.routeMu.Lock()
+	defer s.routeMu.Unlock()
+	if s.routes.meta == nil {
+		s.routes.meta = make(map[string]RouteMeta)
+	}
+	meta := s.routes.meta[pattern]
+	fn(&meta)
+	s.routes.meta[pattern] = meta
+	s.routes.syncDeprecation(pattern)
+}
+
+// RouteMeta 返回路由的描述信息
+// 返回值: 描述信息，以及是否通过 Describe 设置过
+func (s *HTTPServer) RouteMeta(pattern string) (RouteMeta, bool) {
+	pattern = routeKey(pattern)
+	s.routeMu.Lock()
+	defer s.routeMu.Unlock()
+	meta, ok := s.routes.meta[pattern]
+	return meta, ok
+}
+
+// SetRouteEnabled 启用或停用路由，路由注册后默认启用
+// 停用的路由响应 404，与没有注册的路由相同，修改从下一个请求开始生效
+// 路由没有注册过时返回 ErrUnknownRoute
+func (s *HTTPServer) SetRouteEnabled(pattern string, enabled bool) error {
+	pattern = routeKey(pattern)
+	s.routeMu.Lock()
+	defer s.routeMu.Unlock()
+	flag, ok := s.routes.enabled[pattern]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownRoute, pattern)
+	}
+	flag.Store(enabled)
+	return nil
+}
+
+// RouteEnabled 返回路由是否启用，没有注册过的路由返回 false
+func (s *HTTPServer) RouteEnabled(pattern string) bool {
+	pattern = routeKey(pattern)
+	s.routeMu.Lock()
+	defer s.routeMu.Unlock()
+	flag, ok := s.routes.enabled[pattern]
+	return ok && flag.Load()
+}
+
+// registerRoute 检查路由限制并将路由模式记录到统计数据中
+// register: 实际注册路由的函数，在检查通过后调用，panic 时不会记录统计数据
+func (s *HTTPServer) registerRoute(pattern string, paramNames []string, register func()) {
+	method, host, segments := splitPattern(pattern)
+
+	s.routeMu.Lock()
+	defer s.routeMu.Unlock()
+	if err := s.routeLimits.check(&s.routes.stats, pattern, segments, paramNames); err != nil {
+		panic(err)
+	}
+	register()
+	s.routes.insert(pattern, method, host, segments, paramNames)
+}
+
+// check 检查新的路由是否超出限制
+func (l RouteLimits) check(stats *RouterStats, pattern string, segments, paramNames []string) error {
+	switch {
+	case l.MaxRoutes > 0 && stats.Routes >= l.MaxRoutes:
+		return fmt.Errorf("%w: 路由数量超过 %d，拒绝注册 %q", ErrRouteLimit, l.MaxRoutes, pattern)
+	case l.MaxDepth > 0 && len(segments) > l.MaxDepth:
+		return fmt.Errorf("%w: %q 的路径段数 %d 超过 %d", ErrRouteLimit, pattern, len(segments), l.MaxDepth)
+	case l.MaxParams > 0 && len(paramNames) > l.MaxParams:
+		return fmt.Errorf("%w: %q 的路径参数数量 %d 超过 %d", ErrRouteLimit, pattern, len(paramNames), l.MaxParams)
+	case l.MaxPatternLength > 0 && len(pattern) > l.MaxPatternLength:
+		return fmt.Errorf("%w: %q 的长度超过 %d", ErrRouteLimit, pattern, l.MaxPatternLength)
+	}
+	return nil
+}
+
+// insert 将路由插入路由树并更新统计数据
+func (t *routeTable) insert(pattern, method, host string, segments, paramNames []string) {
+	node := &t.root
+	for _, key := range append([]string{host, method}, segments...) {
+		child, ok := node.children[key]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[string]*routeNode)
+			}
+			child = &routeNode{}
+			node.children[key] = child
+			t.stats.Nodes++
+			t.stats.MemoryBytes += routeNodeSize + len(key)
+		}
+		node = child
+	}
+
+	t.patterns = append(t.patterns, pattern)
+	t.stats.Routes++
+	t.stats.Params += len(paramNames)
+	t.stats.MaxDepth = max(t.stats.MaxDepth, len(segments))
+	if n := len(segments); n > 0 && strings.HasSuffix(segments[n-1], "...}") {
+		t.stats.Wildcards++
+	}
+	t.stats.MemoryBytes += routeEntrySize + len(pattern)
+	for _, name := range paramNames {
+		t.stats.MemoryBytes += len(name) + int(unsafe.Sizeof(name))
+	}
+}
+
+// splitPattern 将路由模式拆分为方法、主机与路径段
+// 例如 "GET example.com/users/{id}" 拆分为 "GET"、"example.com" 与 ["users", "{id}"]
+func splitPattern(pattern string) (method, host string, segments []string) {
+	rest := pattern
+	if i := strings.IndexAny(rest, " \t"); i >= 0 {
+		method = rest[:i]
+		rest = strings.TrimLeft(rest[i:], " \t")
+	}
+	path := rest
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		host, path = rest[:i], rest[i:]
+	}
+	// {$} 只表示精确匹配末尾的斜杠，不是独立的路径段
+	path = strings.Trim(strings.TrimSuffix(path, "{$}"), "/")
+	if path == "" {
+		return method, host, nil
+	}
+	return method, host, strings.Split(path, "/")
+}
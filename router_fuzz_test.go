@@ -0,0 +1,127 @@
+package ant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// FuzzHandlePattern 注册任意路由模式：要么 panic 拒绝，要么注册成功且统计信息一致
+func FuzzHandlePattern(f *testing.F) {
+	for _, seed := range []string{
+		"GET /users/{id}",
+		"/static/{path...}",
+		"POST example.com/a/{b}/{$}",
+		"GET /{a}/{a}",
+		"/{",
+		"/a/{b...}/c",
+		"GET  /../etc/passwd",
+		"/%2e%2e/{x}",
+		"",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, pattern string) {
+		s := NewHTTPServer()
+		if err := catchPanic(func() { s.Handle(pattern, func(ctx *Context) {}) }); err != nil {
+			if stats := s.RouterStats(); stats.Routes != 0 {
+				t.Fatalf("注册失败的路由 %q 不应计入统计: %+v", pattern, stats)
+			}
+			return
+		}
+		stats := s.RouterStats()
+		// 路径参数的约束不属于注册的路由模式
+		pattern = routeKey(pattern)
+		if stats.Routes != 1 || s.Routes()[0] != pattern {
+			t.Fatalf("路由 %q 注册后统计信息不一致: %+v", pattern, stats)
+		}
+		if stats.Params != len(patternParamNames(pattern)) {
+			t.Fatalf("路由 %q 的参数数量应为 %d，实际为 %d", pattern, len(patternParamNames(pattern)), stats.Params)
+		}
+	})
+}
+
+// FuzzPathParamRoundTrip 编码后的任意参数值要么原样取回，要么因路径不规范被拒绝，不会被解释为其他路由
+func FuzzPathParamRoundTrip(f *testing.F) {
+	for _, seed := range []string{"alice", "a/b", "..", ".", "../../etc/passwd", "%2e%2e", "a b", "中文", "a%2Fb", "\x00"} {
+		f.Add(seed)
+	}
+	s := NewHTTPServer()
+	s.Handle("GET /files/{name}/raw", func(ctx *Context) {
+		ctx.RespData = []byte(ctx.Req.PathValue("name"))
+	})
+	f.Fuzz(func(t *testing.T, name string) {
+		if name == "" {
+			return
+		}
+		req := httptest.NewRequest(http.MethodGet, "/files/"+url.PathEscape(name)+"/raw", nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		switch {
+		case w.Code == http.StatusOK:
+			if w.Body.String() != name {
+				t.Fatalf("参数应为 %q，实际为 %q", name, w.Body.String())
+			}
+		case path.Clean(req.URL.Path) != req.URL.Path:
+			// 解码后包含点号路径段或连续斜杠的路径会被重定向或拒绝，不会命中其他处理函数
+			if (w.Code < 300 || w.Code >= 400) && w.Code != http.StatusNotFound {
+				t.Fatalf("不规范的路径应被重定向或拒绝，实际为 %d", w.Code)
+			}
+		default:
+			t.Fatalf("参数 %q 应命中路由，实际为 %d", name, w.Code)
+		}
+	})
+}
+
+// segment 将任意字符串转换为只包含字母与数字的非空路径段
+func segment(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r < 128 && (r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		}
+	}
+	return "s" + sb.String()
+}
+
+// TestRoutePriorityProperty 更具体的路由总是优先：字面量优先于通配符，更长的前缀优先于更短的前缀
+func TestRoutePriorityProperty(t *testing.T) {
+	property := func(a, b, c string) bool {
+		a, b, c = segment(a), segment(b), segment(c)
+		s := NewHTTPServer()
+		mark := func(name string) HandleFunc {
+			return func(ctx *Context) { ctx.RespData = []byte(name) }
+		}
+		s.Handle("GET /"+a+"/{x}", mark("param"))
+		s.Handle("GET /"+a+"/"+b, mark("literal"))
+		s.Handle("/"+a+"/", mark("prefix"))
+		s.Handle("/"+a+"/"+b+"/", mark("longer"))
+
+		hit := func(path string) string {
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			return w.Body.String()
+		}
+		want := map[string]string{
+			"/" + a + "/" + b:                 "literal",
+			"/" + a + "/" + b + "x":           "param",
+			"/" + a + "/" + b + "/" + c:       "longer",
+			"/" + a + "/" + b + "x/" + c:      "prefix",
+			"/" + a + "/" + b + "/" + c + "/": "longer",
+		}
+		for path, name := range want {
+			if got := hit(path); got != name {
+				t.Logf("%s 应命中 %s，实际为 %s", path, name, got)
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,176 @@
+package ant
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// TestRouterStats 测试路由统计信息
+func TestRouterStats(t *testing.T) {
+	server := NewHTTPServer()
+	if stats := server.RouterStats(); stats != (RouterStats{}) {
+		t.Fatalf("未注册路由时统计信息应为零值，实际为 %+v", stats)
+	}
+
+	handler := func(ctx *Context) {}
+	server.Handle("GET /users/{id}", handler)
+	server.Handle("POST /users/{id}", handler)
+	server.Handle("GET /users/{id}/posts/{post}", handler)
+	server.Handle("GET /static/{path...}", handler)
+	server.Handle("/{$}", handler)
+
+	stats := server.RouterStats()
+	if stats.Routes != 5 {
+		t.Errorf("路由数量错误，期望 5，实际 %d", stats.Routes)
+	}
+	// 主机 "" 1 个；方法 GET、POST、"" 3 个；
+	// GET 下 users、{id}、posts、{post}、static、{path...} 6 个；POST 下 users、{id} 2 个
+	if stats.Nodes != 12 {
+		t.Errorf("节点数量错误，期望 12，实际 %d", stats.Nodes)
+	}
+	if stats.MaxDepth != 4 {
+		t.Errorf("最大深度错误，期望 4，实际 %d", stats.MaxDepth)
+	}
+	if stats.Params != 5 {
+		t.Errorf("路径参数数量错误，期望 5，实际 %d", stats.Params)
+	}
+	if stats.Wildcards != 1 {
+		t.Errorf("通配路由数量错误，期望 1，实际 %d", stats.Wildcards)
+	}
+	if stats.MemoryBytes <= 0 {
+		t.Errorf("内存估算值应大于 0，实际 %d", stats.MemoryBytes)
+	}
+
+	before := stats.MemoryBytes
+	server.Handle("GET /users/{id}/friends", handler)
+	if stats = server.RouterStats(); stats.MemoryBytes <= before {
+		t.Errorf("注册路由后内存估算值应增加，之前 %d，之后 %d", before, stats.MemoryBytes)
+	}
+}
+
+// TestRouteLimits 测试超出路由限制时拒绝注册
+func TestRouteLimits(t *testing.T) {
+	handler := func(ctx *Context) {}
+	testCases := []struct {
+		name    string
+		limits  RouteLimits
+		pattern string
+	}{
+		{name: "路由数量", limits: RouteLimits{MaxRoutes: 1}, pattern: "GET /b"},
+		{name: "路径深度", limits: RouteLimits{MaxDepth: 2}, pattern: "GET /a/b/c"},
+		{name: "路径参数", limits: RouteLimits{MaxParams: 1}, pattern: "GET /{x}/{y}"},
+		{name: "模式长度", limits: RouteLimits{MaxPatternLength: 8}, pattern: "GET /too-long"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewHTTPServer(ServerWithRouteLimits(tc.limits))
+			server.Handle("GET /a", handler)
+
+			err := catchPanic(func() { server.Handle(tc.pattern, handler) })
+			if !errors.Is(err, ErrRouteLimit) {
+				t.Fatalf("期望 ErrRouteLimit，实际 %v", err)
+			}
+			if stats := server.RouterStats(); stats.Routes != 1 {
+				t.Errorf("被拒绝的路由不应计入统计，实际路由数量 %d", stats.Routes)
+			}
+		})
+	}
+}
+
+// TestRouteLimitsRejectGenerated 测试限制大量自动生成的路由
+func TestRouteLimitsRejectGenerated(t *testing.T) {
+	server := NewHTTPServer(ServerWithRouteLimits(RouteLimits{MaxRoutes: 100}))
+	var err error
+	registered := 0
+	for i := 0; i < 1000 && err == nil; i++ {
+		err = catchPanic(func() {
+			server.Handle(fmt.Sprintf("GET /generated/%d", i), func(ctx *Context) {})
+		})
+		if err == nil {
+			registered++
+		}
+	}
+	if registered != 100 || !errors.Is(err, ErrRouteLimit) {
+		t.Errorf("期望注册 100 条路由后被拒绝，实际注册 %d 条，错误 %v", registered, err)
+	}
+}
+
+// TestRouteConflictNotCounted 测试冲突的路由不计入统计
+func TestRouteConflictNotCounted(t *testing.T) {
+	server := NewHTTPServer()
+	server.Handle("GET /a", func(ctx *Context) {})
+	if catchPanic(func() { server.Handle("GET /a", func(ctx *Context) {}) }) == nil {
+		t.Fatal("重复注册路由应该 panic")
+	}
+	if stats := server.RouterStats(); stats.Routes != 1 {
+		t.Errorf("冲突的路由不应计入统计，实际路由数量 %d", stats.Routes)
+	}
+}
+
+// TestSplitPattern 测试路由模式的拆分
+func TestSplitPattern(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		method   string
+		host     string
+		segments []string
+	}{
+		{pattern: "/", segments: nil},
+		{pattern: "/{$}", segments: nil},
+		{pattern: "GET /users/{id}", method: "GET", segments: []string{"users", "{id}"}},
+		{pattern: "POST  example.com/a/b/", method: "POST", host: "example.com", segments: []string{"a", "b"}},
+		{pattern: "example.com/", host: "example.com", segments: nil},
+	}
+	for _, tc := range testCases {
+		method, host, segments := splitPattern(tc.pattern)
+		if method != tc.method || host != tc.host || !reflect.DeepEqual(segments, tc.segments) {
+			t.Errorf("拆分 %q 错误，实际 %q %q %v", tc.pattern, method, host, segments)
+		}
+	}
+}
+
+// catchPanic 执行函数并返回 panic 的值
+func catchPanic(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+				return
+			}
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	fn()
+	return nil
+}
+
+// TestSetRouteEnabled 测试运行时启用与停用路由
+func TestSetRouteEnabled(t *testing.T) {
+	server := NewHTTPServer()
+	server.Handle("GET /users", func(ctx *Context) { ctx.RespData = []byte("users") })
+
+	get := func() int {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+		return w.Code
+	}
+	if !server.RouteEnabled("GET /users") || get() != http.StatusOK {
+		t.Fatal("路由注册后应默认启用")
+	}
+	if err := server.SetRouteEnabled("GET /users", false); err != nil {
+		t.Fatal(err)
+	}
+	if server.RouteEnabled("GET /users") || get() != http.StatusNotFound {
+		t.Error("停用的路由应响应 404")
+	}
+	if err := server.SetRouteEnabled("GET /users", true); err != nil || get() != http.StatusOK {
+		t.Errorf("重新启用后应响应 200，实际为 %v", err)
+	}
+	if err := server.SetRouteEnabled("GET /missing", false); !errors.Is(err, ErrUnknownRoute) {
+		t.Errorf("未注册的路由应返回 ErrUnknownRoute，实际为 %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package ant
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// SchemaViolation 值不符合 Schema 的地方
+type SchemaViolation struct {
+	// Path 出错的位置，例如 "$.items[0].name"
+	Path string `json:"path"`
+	// Message 说明
+	Message string `json:"message"`
+}
+
+// schemaRefPrefix 引用 components.schemas 的前缀
+const schemaRefPrefix = "#/components/schemas/"
+
+// JSONSchema 返回 Body 描述的结构与其中引用的具名结构
+// 设置了 Schema 时直接返回，否则通过反射 Type 生成，与 OpenAPI 文档中的结构一致
+// 都没有设置时返回nil
+func (b Body) JSONSchema() (*Schema, map[string]*Schema) {
+	if b.Schema != nil {
+		return b.Schema, nil
+	}
+	if b.Type == nil {
+		return nil, nil
+	}
+	gen := schemaGenerator{schemas: make(map[string]*Schema)}
+	return gen.schema(b.Type), gen.schemas
+}
+
+// ValidateSchema 校验JSON解析后的值是否符合 Schema，返回所有不符合的地方
+// val: 通过 encoding/json 解析到 any 的值，数字可以是 float64 或 json.Number
+// defs: 解析 $ref 引用使用的具名结构，即 OpenAPI 文档的 components.schemas
+// 支持 type、nullable、properties、required、additionalProperties、items、enum、
+// minimum、maximum、minLength、maxLength、pattern、minItems、maxItems 以及 date-time 与 byte 格式
+func ValidateSchema(schema *Schema, defs map[string]*Schema, val any) []SchemaViolation {
+	v := schemaValidator{defs: defs}
+	v.validate(schema, val, "$", 0)
+	return v.violations
+}
+
+// maxSchemaDepth 解析引用的最大深度，避免自引用的结构无限递归
+const maxSchemaDepth = 64
+
+// schemaValidator 收集校验过程中的错误
+type schemaValidator struct {
+	defs       map[string]*Schema
+	violations []SchemaViolation
+}
+
+func (v *schemaValidator) fail(path, format string, args ...any) {
+	v.violations = append(v.violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *schemaValidator) validate(s *Schema, val any, path string, depth int) {
+	if s == nil {
+		return
+	}
+	if depth > maxSchemaDepth {
+		v.fail(path, "嵌套过深")
+		return
+	}
+	if val == nil && s.Nullable {
+		return
+	}
+	if s.Ref != "" {
+		def, ok := v.defs[strings.TrimPrefix(s.Ref, schemaRefPrefix)]
+		if !ok {
+			v.fail(path, "无法解析的引用 %s", s.Ref)
+			return
+		}
+		v.validate(def, val, path, depth+1)
+		return
+	}
+	if val == nil {
+		if !s.Nullable && s.Type != "" {
+			v.fail(path, "不能为 null")
+		}
+		return
+	}
+	if len(s.Enum) > 0 && !enumContains(s.Enum, val) {
+		v.fail(path, "应为 %v 之一", s.Enum)
+	}
+
+	switch s.Type {
+	case "":
+		// 没有声明类型时不校验
+	case "object":
+		obj, ok := val.(map[string]any)
+		if !ok {
+			v.fail(path, "应为 object，实际为 %s", jsonTypeOf(val))
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				v.fail(path+"."+name, "缺少必填字段")
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prop, ok := s.Properties[k]; ok {
+				v.validate(prop, obj[k], path+"."+k, depth+1)
+			} else if s.AdditionalProperties != nil {
+				v.validate(s.AdditionalProperties, obj[k], path+"."+k, depth+1)
+			}
+		}
+	case "array":
+		arr, ok := val.([]any)
+		if !ok {
+			v.fail(path, "应为 array，实际为 %s", jsonTypeOf(val))
+			return
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			v.fail(path, "至少需要 %d 项，实际为 %d 项", *s.MinItems, len(arr))
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			v.fail(path, "最多允许 %d 项，实际为 %d 项", *s.MaxItems, len(arr))
+		}
+		for i, item := range arr {
+			v.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i), depth+1)
+		}
+	case "string":
+		str, ok := val.(string)
+		if !ok {
+			v.fail(path, "应为 string，实际为 %s", jsonTypeOf(val))
+			return
+		}
+		n := utf8.RuneCountInString(str)
+		if s.MinLength != nil && n < *s.MinLength {
+			v.fail(path, "长度不能小于 %d", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			v.fail(path, "长度不能大于 %d", *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			re, err := compileSchemaPattern(s.Pattern)
+			if err != nil {
+				v.fail(path, "不合法的 pattern %q", s.Pattern)
+			} else if !re.MatchString(str) {
+				v.fail(path, "不匹配 %s", s.Pattern)
+			}
+		}
+		switch s.Format {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				v.fail(path, "应为 RFC 3339 格式的时间")
+			}
+		case "byte":
+			if _, err := base64.StdEncoding.DecodeString(str); err != nil {
+				v.fail(path, "应为 base64 编码")
+			}
+		}
+	case "integer", "number":
+		num, ok := jsonNumber(val)
+		if !ok {
+			v.fail(path, "应为 %s，实际为 %s", s.Type, jsonTypeOf(val))
+			return
+		}
+		if s.Type == "integer" && num != math.Trunc(num) {
+			v.fail(path, "应为 integer，实际为 %v", num)
+		}
+		if s.Minimum != nil && num < *s.Minimum {
+			v.fail(path, "不能小于 %v", *s.Minimum)
+		}
+		if s.Maximum != nil && num > *s.Maximum {
+			v.fail(path, "不能大于 %v", *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := val.(bool); !ok {
+			v.fail(path, "应为 boolean，实际为 %s", jsonTypeOf(val))
+		}
+	}
+}
+
+// schemaPatterns 编译过的 pattern
+var schemaPatterns sync.Map
+
+func compileSchemaPattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := schemaPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	schemaPatterns.Store(pattern, re)
+	return re, nil
+}
+
+// jsonNumber 将 float64 或 json.Number 转换为 float64
+func jsonNumber(val any) (float64, bool) {
+	switch n := val.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// enumContains 判断 val 是否为 enum 中的值，数字按数值比较
+func enumContains(enum []any, val any) bool {
+	num, isNum := jsonNumber(val)
+	for _, e := range enum {
+		if isNum {
+			if en, ok := toFloat(e); ok && en == num {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, val) {
+			return true
+		}
+	}
+	return false
+}
+
+// toFloat 将 Enum 中声明的 Go 数字转换为 float64
+func toFloat(v any) (float64, bool) {
+	if n, ok := jsonNumber(v); ok {
+		return n, true
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int()), true
+	case rv.CanUint():
+		return float64(rv.Uint()), true
+	case rv.CanFloat():
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// jsonTypeOf 返回值的 JSON 类型名称
+func jsonTypeOf(val any) string {
+	switch val.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64, json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", val)
+}
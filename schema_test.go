@@ -0,0 +1,78 @@
+package ant
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestValidateSchema(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+	}
+	type order struct {
+		ID      int64    `json:"id"`
+		Items   []string `json:"items"`
+		Address *address `json:"address"`
+		Note    string   `json:"note,omitempty"`
+	}
+	s, defs := BodyOf[order]("").JSONSchema()
+
+	decode := func(s string) any {
+		var v any
+		dec := json.NewDecoder(strings.NewReader(s))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	if v := ValidateSchema(s, defs, decode(`{"id":1,"items":["a"],"address":null}`)); len(v) != 0 {
+		t.Errorf("合法的值不应有错误，实际为 %v", v)
+	}
+	got := ValidateSchema(s, defs, decode(`{"id":1.5,"items":[1],"address":{}}`))
+	want := []string{"$.address.city", "$.id", "$.items[0]"}
+	if len(got) != len(want) {
+		t.Fatalf("应有 %d 个错误，实际为 %v", len(want), got)
+	}
+	for i, v := range got {
+		if v.Path != want[i] {
+			t.Errorf("第 %d 个错误的位置应为 %s，实际为 %s", i, want[i], v.Path)
+		}
+	}
+
+	manual := &Schema{Type: "object", Required: []string{"name"}, Properties: map[string]*Schema{
+		"name":  {Type: "string", MinLength: ptr(2), Pattern: "^[a-z]+$"},
+		"age":   {Type: "integer", Minimum: ptr(0.0), Maximum: ptr(150.0)},
+		"role":  {Type: "string", Enum: []any{"admin", "user"}},
+		"level": {Type: "integer", Enum: []any{1, 2}},
+		"tags":  {Type: "array", Items: &Schema{Type: "string"}, MaxItems: ptr(1)},
+		"at":    {Type: "string", Format: "date-time"},
+	}}
+	got = ValidateSchema(manual, nil, decode(`{"name":"A","age":200,"role":"root","level":2,"tags":["a","b"],"at":"yesterday"}`))
+	paths := make([]string, 0, len(got))
+	for _, v := range got {
+		paths = append(paths, v.Path)
+	}
+	if strings.Join(paths, ",") != "$.age,$.at,$.name,$.name,$.role,$.tags" {
+		t.Errorf("手动编写的结构校验结果不正确: %v", got)
+	}
+	if v := ValidateSchema(manual, nil, decode(`{"name":"tom","level":1.0}`)); len(v) != 0 {
+		t.Errorf("合法的值不应有错误，实际为 %v", v)
+	}
+}
+
+func TestOpenAPIUsesManualSchema(t *testing.T) {
+	s := NewHTTPServer()
+	s.Handle("POST /tags", func(ctx *Context) {})
+	schema := &Schema{Type: "string", MaxLength: ptr(10)}
+	s.Describe("POST /tags", RouteMeta{Request: &Body{Schema: schema}})
+	doc := s.OpenAPI(OpenAPIInfo{Title: "t", Version: "1"})
+	if got := doc.Paths["/tags"]["post"].RequestBody.Content["application/json"].Schema; got != schema {
+		t.Errorf("文档应使用手动编写的结构，实际为 %+v", got)
+	}
+}
@@ -1,9 +1,18 @@
 package ant
 
 import (
-	"fmt"
+	"context"
+	"crypto/tls"
+	"errors"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 )
 
 // HandleFunc 定义HTTP请求处理函数类型
@@ -35,9 +44,55 @@ var _ Server = (*HTTPServer)(nil)
 
 // HTTPServer HTTP服务器的具体实现
 type HTTPServer struct {
-	mux            *http.ServeMux // 底层路由复用器
-	middlewares    []Middleware   // 已注册的中间件列表
-	TemplateEngine TemplateEngine // 模板引擎
+	mux            *http.ServeMux               // 底层路由复用器
+	middlewares    []Middleware                 // 已注册的中间件列表
+	mwMu           sync.Mutex                   // 保护中间件的注册
+	mwSnapshot     atomic.Pointer[[]Middleware] // 请求处理时读取的中间件快照
+	TemplateEngine TemplateEngine               // 模板引擎
+
+	mu               sync.Mutex                        // 保护 servers、shutdownHooks 与 stoppedListeners
+	servers          []*http.Server                    // 运行中的底层HTTP服务器
+	shutdownHooks    []func(ctx context.Context) error // 关闭时执行的钩子
+	stoppedListeners []func(ev ServerStopped)          // 关闭完成后通知的监听器
+
+	tlsConfig   *tls.Config    // TLS 配置，为nil时使用 DefaultTLSConfig
+	autoTLSOpts autoTLSOptions // 自动证书管理配置
+
+	protocols   *http.Protocols   // 启用的协议，为nil时使用标准库默认值
+	http2Config *http.HTTP2Config // HTTP/2 连接参数
+
+	trustedProxies *TrustedProxies // 受信任的代理
+
+	listeners []net.Listener // RunListeners 打开的监听器，用于 Handoff
+
+	grpcHandler http.Handler // 通过 MountGRPC 托管的 gRPC 服务
+
+	serverHooks []func(srv *http.Server) // 创建 http.Server 后执行的配置函数
+
+	jsonCodec JSONCodec // JSON编解码器，为nil时使用标准库
+
+	binders map[string]BinderFunc // 通过 RegisterBinder 注册的绑定器
+
+	features *FeatureFlags // 功能开关服务
+
+	routeMu     sync.Mutex             // 保护路由的注册与统计数据
+	routes      routeTable             // 已注册路由的统计数据
+	routeLimits RouteLimits            // 路由注册的限制
+	variants    map[string]*variantSet // 通过 HandleVariants 注册的路由，由 routeMu 保护
+
+	metrics         listenerMetrics    // 所有请求的并发计数
+	listenerMetrics []*listenerMetrics // 各监听器的并发计数，由 mu 保护
+	limiter         *limiter           // 背压策略，为nil时不限制并发
+
+	checks []namedCheck // Validate 时执行的检查，由 mu 保护
+
+	tasks *taskPool // Go 使用的后台任务池，第一次使用时创建，由 mu 保护
+
+	cachePolicy CachePolicy // 按 Content-Type 设置的默认缓存策略
+
+	startupReport *StartupReport // 启动时输出的报告，为nil时只输出监听地址
+
+	pathPolicy *PathPolicy // 请求路径的规范化策略，为nil时由 ServeMux 处理
 }
 
 // ServerOption 定义服务器配置选项函数类型
@@ -61,6 +116,7 @@ func NewHTTPServer(opts ...ServerOption) *HTTPServer {
 	server := &HTTPServer{
 		mux:         http.NewServeMux(),
 		middlewares: make([]Middleware, 0),
+		autoTLSOpts: autoTLSOptions{httpAddr: ":80"},
 	}
 	// 应用所有配置选项
 	for _, opt := range opts {
@@ -72,30 +128,98 @@ func NewHTTPServer(opts ...ServerOption) *HTTPServer {
 // Use 注册中间件
 // mdls: 要注册的中间件列表，支持同时注册多个
 // 注意：中间件的调用顺序与注册顺序相反
+// 可以在服务器运行期间调用，新的中间件从下一个请求开始生效，不会阻塞正在处理的请求
 func (s *HTTPServer) Use(mdls ...Middleware) {
-	if s.middlewares == nil {
-		s.middlewares = mdls
-		return
-	}
-	s.middlewares = append(s.middlewares, mdls...)
+	s.mwMu.Lock()
+	defer s.mwMu.Unlock()
+	// 写时复制，正在处理的请求持有的快照不受影响
+	next := make([]Middleware, 0, len(s.middlewares)+len(mdls))
+	next = append(next, s.middlewares...)
+	next = append(next, mdls...)
+	s.middlewares = next
+	s.mwSnapshot.Store(&next)
 }
 
 // Handle 注册路由处理函数
-// pattern: 路由模式，支持Go 1.22新路由语法
+// pattern: 路由模式，支持Go 1.22新路由语法，
+// 路径参数可以带有约束，例如 {id:int}、{id:uuid}、{name:regexp([a-z]+)}、{path...:regexp(.*\.png)}，
+// 不满足约束的请求响应 404，注册的路由模式与 ctx.Req.Pattern 中不包含约束，例如 "GET /users/{id}"
 // handler: 该路由的处理函数
-// 注意：每个请求都会创建新的Context实例
+// 注意：
+// 1. 请求上下文从池中获取，处理函数返回后会被复用
+// 2. 路由冲突或超出 ServerWithRouteLimits 设置的限制时 panic
 func (s *HTTPServer) Handle(pattern string, handler HandleFunc) {
-	s.mux.Handle(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// 创建请求上下文
-		ctx := &Context{
-			Req:            r,
-			Resp:           w,
-			TemplateEngine: s.TemplateEngine, // 将服务器的模板引擎传递给Context
+	s.handle(pattern, handler)
+}
+
+// handle 注册路由处理函数，并可附加仅作用于该路由的中间件
+// pattern: 路由模式
+// handler: 该路由的处理函数
+// mdls: 路由级中间件，位于全局中间件之内、处理函数之外
+func (s *HTTPServer) handle(pattern string, handler HandleFunc, mdls ...Middleware) {
+	pattern, constraints, err := parseConstraints(pattern)
+	if err != nil {
+		panic(err)
+	}
+	for i := len(mdls) - 1; i >= 0; i-- {
+		handler = mdls[i](handler)
+	}
+	// 注册时解析路径参数名，避免每个请求重复解析
+	paramNames := patternParamNames(pattern)
+	enabled := &atomic.Bool{}
+	enabled.Store(true)
+	deprecation := &atomic.Pointer[deprecationHeaders]{}
+	s.registerRoute(pattern, paramNames, func() {
+		s.mux.Handle(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// 停用的路由与没有注册的路由相同
+			if !enabled.Load() {
+				http.NotFound(w, r)
+				return
+			}
+			// 不满足约束的参数同样视为没有命中
+			var typed [maxTypedParams]typedParam
+			n := 0
+			for _, c := range constraints {
+				num, ok := c.match(r.PathValue(c.name))
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				if num.kind != 0 && n < len(typed) {
+					typed[n] = typedParam{name: c.name, num: num}
+					n++
+				}
+			}
+			if h := deprecation.Load(); h != nil {
+				h.write(w.Header())
+			}
+			// 从池中获取请求上下文，请求结束后归还
+			ctx := s.acquireContext(w, r)
+			ctx.paramNames = &paramNames
+			ctx.typedParams = append(ctx.typedParams, typed[:n]...)
+			defer releaseContext(ctx)
+			// 构建并执行中间件链
+			middlewareChain := s.buildMiddlewareChain(handler)
+			middlewareChain(ctx)
+		}))
+		// registerRoute 持有 routeMu
+		if s.routes.enabled == nil {
+			s.routes.enabled = make(map[string]*atomic.Bool)
 		}
-		// 构建并执行中间件链
-		middlewareChain := s.buildMiddlewareChain(handler)
-		middlewareChain(ctx)
-	}))
+		s.routes.enabled[pattern] = enabled
+		if s.routes.deprecations == nil {
+			s.routes.deprecations = make(map[string]*atomic.Pointer[deprecationHeaders])
+		}
+		// 注册之前可能已经通过 Describe 设置了弃用信息
+		s.routes.deprecations[pattern] = deprecation
+		s.routes.syncDeprecation(pattern)
+		if len(constraints) > 0 {
+			if s.routes.constraints == nil {
+				s.routes.constraints = make(map[string][]paramConstraint)
+			}
+			s.routes.constraints[pattern] = constraints
+		}
+	})
 }
 
 // buildMiddlewareChain 使用迭代器模式构建中间件调用链
@@ -103,8 +227,14 @@ func (s *HTTPServer) Handle(pattern string, handler HandleFunc) {
 // 返回值: 包含所有中间件的处理函数
 // 注意：中间件的执行顺序与注册顺序相反
 func (s *HTTPServer) buildMiddlewareChain(handler HandleFunc) HandleFunc {
-	// 创建中间件切片副本
-	middlewares := s.middlewares
+	// 读取中间件快照，不需要加锁
+	// 未通过 Use 注册时（例如由配置选项直接设置）使用 middlewares 字段
+	var middlewares []Middleware
+	if snapshot := s.mwSnapshot.Load(); snapshot != nil {
+		middlewares = *snapshot
+	} else {
+		middlewares = s.middlewares
+	}
 
 	// 返回包含完整中间件链的闭包
 	return func(ctx *Context) {
@@ -124,16 +254,37 @@ func (s *HTTPServer) buildMiddlewareChain(handler HandleFunc) HandleFunc {
 // ServeHTTP 实现http.Handler接口
 // 作为HTTP服务器的请求处理入口
 func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m, ok := s.enter(w, r)
+	if !ok {
+		return
+	}
+	defer s.leave(m)
+
+	if s.grpcHandler != nil && isGRPCRequest(r) {
+		s.grpcHandler.ServeHTTP(w, r)
+		return
+	}
+	if s.pathPolicy != nil && !s.pathPolicy.apply(w, r) {
+		return
+	}
 	s.mux.ServeHTTP(w, r)
 }
 
 // writeResponse 将Context中缓存的响应数据写入HTTP响应
 // ctx: 请求上下文
-// 注意：会自动处理状态码和响应体的写入
+// 注意：
+// 1. 处理函数已经直接写入状态码时不再重复写入
+// 2. 处理函数已经直接写入响应体时不再写入 RespData，避免响应体重复
 func (s *HTTPServer) writeResponse(ctx *Context) {
-	if ctx.RespStatusCode > 0 {
+	if ctx.hijacked || ctx.rw.hijacked {
+		return
+	}
+	if ctx.RespStatusCode > 0 && !ctx.rw.Written() {
 		ctx.Resp.WriteHeader(ctx.RespStatusCode)
 	}
+	if len(ctx.RespData) == 0 || ctx.rw.Size() > 0 {
+		return
+	}
 
 	// 写入响应体
 	_, err := ctx.Resp.Write(ctx.RespData)
@@ -144,9 +295,169 @@ func (s *HTTPServer) writeResponse(ctx *Context) {
 
 // Run 启动HTTP服务器
 // addr: 服务器监听地址
-// 返回值: 服务器运行过程中的错误
-// 注意：这是一个阻塞调用，服务器会一直运行直到出错
+// 返回值: 服务器运行过程中的错误，通过 Shutdown 关闭时返回 http.ErrServerClosed
+// 注意：
+// 1. 这是一个阻塞调用，服务器会一直运行直到出错或被关闭
+// 2. 地址不合法或无法绑定时立即返回 *AddrError，端口被占用时 errors.Is(err, ErrAddrInUse) 为 true
+// 3. 绑定成功后才输出启动信息
 func (s *HTTPServer) Run(addr string) error {
-	fmt.Printf("Server is running on %s\n", addr)
-	return http.ListenAndServe(addr, s)
+	l, err := listenTCP(addr)
+	if err != nil {
+		return err
+	}
+	srv := s.newServer(addr)
+	s.reportStartup(l.Addr().String())
+	return srv.Serve(l)
+}
+
+// newServer 创建并记录底层的 http.Server
+// addr: 服务器监听地址
+func (s *HTTPServer) newServer(addr string) *http.Server {
+	srv := &http.Server{
+		Addr:      addr,
+		Handler:   s,
+		Protocols: s.protocols,
+		HTTP2:     s.http2Config,
+	}
+	for _, hook := range s.serverHooks {
+		hook(srv)
+	}
+	s.instrumentServer(srv)
+	s.mu.Lock()
+	s.servers = append(s.servers, srv)
+	s.mu.Unlock()
+	return srv
+}
+
+// OnShutdown 注册服务器关闭时执行的钩子，例如关闭会话存储、刷新日志
+// hook: 关闭钩子，ctx 携带关闭的截止时间
+// 注意：钩子按注册的相反顺序执行，与 defer 的语义一致
+func (s *HTTPServer) OnShutdown(hook func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shutdownHooks = append(s.shutdownHooks, hook)
+}
+
+// ServerStopped 服务器关闭完成的事件，通过 OnStopped 订阅
+type ServerStopped struct {
+	// StoppedAt 关闭完成的时间
+	StoppedAt time.Time
+	// Duration 从调用 Shutdown 到关闭完成的耗时
+	Duration time.Duration
+	// Abandoned 截止时间到达时仍未处理完成的请求数，全部请求正常完成时为0
+	Abandoned int64
+	// Err 关闭过程中发生的全部错误，与 Shutdown 的返回值相同
+	Err error
+}
+
+// OnStopped 注册服务器关闭完成后通知的监听器，例如上报关闭耗时、从服务注册中心注销
+// listener: 监听器，在全部关闭钩子执行完成后按注册顺序同步调用
+func (s *HTTPServer) OnStopped(listener func(ev ServerStopped)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stoppedListeners = append(s.stoppedListeners, listener)
+}
+
+// Shutdown 优雅地关闭服务器
+// 停止接收新连接，等待进行中的请求处理完成与通过 Go 提交的后台任务执行完毕，然后执行关闭钩子，
+// 最后向 OnStopped 注册的监听器发送 ServerStopped 事件
+// ctx: 控制关闭的截止时间，超时后返回 ctx 的错误
+// 返回值: 关闭过程中发生的全部错误
+// 注意：http.Server 不会等待已被接管的连接，WebSocket 等接管连接的处理函数同样会等待其返回
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	start := time.Now()
+	s.mu.Lock()
+	servers := make([]*http.Server, len(s.servers))
+	copy(servers, s.servers)
+	hooks := make([]func(ctx context.Context) error, len(s.shutdownHooks))
+	copy(hooks, s.shutdownHooks)
+	listeners := make([]func(ev ServerStopped), len(s.stoppedListeners))
+	copy(listeners, s.stoppedListeners)
+	if s.tasks == nil {
+		// 关闭后调用 Go 应返回 ErrTaskPoolClosed
+		s.tasks = newTaskPool(TaskPool{Workers: 1, QueueSize: 1})
+	}
+	tasks := s.tasks
+	s.mu.Unlock()
+
+	var errs []error
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// 已经超时的情况下不再重复记录 ctx 的错误
+	if ctx.Err() == nil {
+		if err := s.waitHandlers(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	abandoned := s.metrics.inFlight.Load()
+	// 请求处理完成后不会再提交新的任务，钩子可能关闭任务依赖的资源，需要在钩子之前等待
+	if err := tasks.drain(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	err := errors.Join(errs...)
+	ev := ServerStopped{StoppedAt: time.Now(), Abandoned: abandoned, Err: err}
+	ev.Duration = ev.StoppedAt.Sub(start)
+	for _, listener := range listeners {
+		listener(ev)
+	}
+	return err
+}
+
+// waitHandlers 等待全部处理函数返回，包括 http.Server.Shutdown 不会等待的接管连接
+// 与 http.Server.Shutdown 一样轮询，间隔从 1ms 逐步增加到 500ms
+func (s *HTTPServer) waitHandlers(ctx context.Context) error {
+	interval := time.Millisecond
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for s.metrics.inFlight.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+		interval = min(interval*2, 500*time.Millisecond)
+		timer.Reset(interval)
+	}
+	return nil
+}
+
+// RunWithGracefulShutdown 启动服务器，并在收到信号后优雅关闭
+// addr: 服务器监听地址
+// timeout: 等待进行中请求完成的最长时间
+// signals: 触发关闭的信号，默认为 SIGINT 与 SIGTERM
+// 返回值: 服务器启动失败或关闭过程中的错误，正常关闭时返回nil
+func (s *HTTPServer) RunWithGracefulShutdown(addr string, timeout time.Duration, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), signals...)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Run(addr)
+	}()
+
+	select {
+	case err := <-errCh:
+		// 服务器未能启动或意外退出
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := s.Shutdown(shutdownCtx)
+	if runErr := <-errCh; !errors.Is(runErr, http.ErrServerClosed) {
+		err = errors.Join(err, runErr)
+	}
+	return err
 }
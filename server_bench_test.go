@@ -0,0 +1,155 @@
+package ant
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// discardWriter 丢弃响应内容的 ResponseWriter，避免基准测试统计 httptest.ResponseRecorder 的分配
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
+// BenchmarkServeHTTP 测试静态路由的请求处理开销
+func BenchmarkServeHTTP(b *testing.B) {
+	server := NewHTTPServer()
+	server.Handle("GET /ping", func(ctx *Context) {
+		_ = ctx.WriteString("pong")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := &discardWriter{header: make(http.Header)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		server.ServeHTTP(w, req)
+	}
+}
+
+// BenchmarkServeHTTPUserValues 测试使用 UserValues 的请求处理开销，池化后 map 会被复用
+func BenchmarkServeHTTPUserValues(b *testing.B) {
+	server := NewHTTPServer()
+	server.Use(func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			if ctx.UserValues == nil {
+				ctx.UserValues = make(map[string]any, 1)
+			}
+			ctx.UserValues["user"] = "tom"
+			next(ctx)
+		}
+	})
+	server.Handle("GET /ping", func(ctx *Context) {
+		_ = ctx.WriteString("pong")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := &discardWriter{header: make(http.Header)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		server.ServeHTTP(w, req)
+	}
+}
+
+// BenchmarkContextAllocate 对比池化前每个请求新建 Context 的分配情况
+func BenchmarkContextAllocate(b *testing.B) {
+	server := NewHTTPServer()
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := &discardWriter{header: make(http.Header)}
+
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ctx := &Context{Req: req, Resp: w, TemplateEngine: server.TemplateEngine}
+			ctx.UserValues = make(map[string]any, 1)
+			ctx.UserValues["user"] = "tom"
+			ctx.RespData = []byte("pong")
+			sinkContext = ctx
+		}
+	})
+	b.Run("pool", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ctx := server.acquireContext(w, req)
+			if ctx.UserValues == nil {
+				ctx.UserValues = make(map[string]any, 1)
+			}
+			ctx.UserValues["user"] = "tom"
+			_ = ctx.WriteString("pong")
+			releaseContext(ctx)
+		}
+	})
+}
+
+// sinkContext 防止编译器优化掉基准测试中的分配
+var sinkContext *Context
+
+// BenchmarkPathParams 对比两种获取路径参数方式的分配情况
+func BenchmarkPathParams(b *testing.B) {
+	req := httptest.NewRequest(http.MethodGet, "/users/42/files/a.txt", nil)
+	w := &discardWriter{header: make(http.Header)}
+
+	b.Run("map", func(b *testing.B) {
+		server := NewHTTPServer()
+		server.Handle("GET /users/{id}/files/{name}", func(ctx *Context) {
+			sinkParams = len(ctx.PathParams())
+		})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			server.ServeHTTP(w, req)
+		}
+	})
+	b.Run("append", func(b *testing.B) {
+		server := NewHTTPServer()
+		server.Handle("GET /users/{id}/files/{name}", func(ctx *Context) {
+			var buf [4]PathParam
+			sinkParams = len(ctx.AppendPathParams(buf[:0]))
+		})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			server.ServeHTTP(w, req)
+		}
+	})
+	b.Run("static", func(b *testing.B) {
+		server := NewHTTPServer()
+		server.Handle("GET /users/{id}/files/{name}", func(ctx *Context) {})
+		server.Handle("GET /health", func(ctx *Context) {
+			sinkParams = len(ctx.PathParams())
+		})
+		static := httptest.NewRequest(http.MethodGet, "/health", nil)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			server.ServeHTTP(w, static)
+		}
+	})
+}
+
+// sinkParams 防止编译器优化掉参数读取
+var sinkParams int
+
+// BenchmarkServeHTTPManyRoutes 测试路由数量对匹配开销的影响
+// http.ServeMux 按路径段组织成树进行匹配，开销不随路由数量线性增长
+func BenchmarkServeHTTPManyRoutes(b *testing.B) {
+	w := &discardWriter{header: make(http.Header)}
+	for _, n := range []int{10, 1000, 10000} {
+		b.Run(fmt.Sprintf("routes=%d", n), func(b *testing.B) {
+			server := NewHTTPServer()
+			for i := 0; i < n; i++ {
+				server.Handle(fmt.Sprintf("GET /api/r%d/items/{id}", i), func(ctx *Context) {})
+			}
+			// 请求最后注册的路由
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/r%d/items/42", n-1), nil)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				server.ServeHTTP(w, req)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 )
 
@@ -328,3 +329,37 @@ func TestMultipleServerOptions(t *testing.T) {
 		t.Error("自定义配置选项未被正确应用")
 	}
 }
+
+// TestUseConcurrentWithRequests 测试运行期间注册中间件不影响并发处理的请求
+func TestUseConcurrentWithRequests(t *testing.T) {
+	server := NewHTTPServer()
+	server.Handle("GET /ping", func(ctx *Context) {
+		ctx.RespData = []byte("pong")
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				rec := httptest.NewRecorder()
+				server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ping", nil))
+				if rec.Body.String() != "pong" {
+					t.Errorf("响应不正确: %q", rec.Body.String())
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		server.Use(func(next HandleFunc) HandleFunc {
+			return next
+		})
+	}
+	wg.Wait()
+
+	if len(server.middlewares) != 50 {
+		t.Errorf("期望注册 50 个中间件, 得到 %d", len(server.middlewares))
+	}
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// Codec 会话数据的编解码器，由需要序列化会话数据的远程存储使用
+type Codec interface {
+	// Marshal 将会话数据序列化
+	Marshal(data map[string]any) ([]byte, error)
+	// Unmarshal 解析会话数据
+	Unmarshal(b []byte) (map[string]any, error)
+}
+
+// SessionCodec 将编码结果与会话ID绑定的编解码器
+// 远程存储在编解码器实现了该接口时传入会话ID，例如 EncryptedCodec 将会话ID作为附加数据参与认证，
+// 从一个会话的存储项复制到另一个会话的数据无法解码
+type SessionCodec interface {
+	Codec
+	// MarshalSession 序列化会话 id 的数据
+	MarshalSession(id string, data map[string]any) ([]byte, error)
+	// UnmarshalSession 解析会话 id 的数据，数据不属于该会话时返回错误
+	UnmarshalSession(id string, b []byte) (map[string]any, error)
+}
+
+// MarshalSession 序列化会话数据，c 实现了 SessionCodec 时绑定会话ID
+// 供远程存储实现使用
+func MarshalSession(c Codec, id string, data map[string]any) ([]byte, error) {
+	if sc, ok := c.(SessionCodec); ok {
+		return sc.MarshalSession(id, data)
+	}
+	return c.Marshal(data)
+}
+
+// UnmarshalSession 解析会话数据，c 实现了 SessionCodec 时校验数据属于该会话
+// 供远程存储实现使用
+func UnmarshalSession(c Codec, id string, b []byte) (map[string]any, error) {
+	if sc, ok := c.(SessionCodec); ok {
+		return sc.UnmarshalSession(id, b)
+	}
+	return c.Unmarshal(b)
+}
+
+// JSONCodec 基于 encoding/json 的编解码器
+// 注意：数字解析后为 float64，结构体解析后为 map[string]any
+type JSONCodec struct{}
+
+// Marshal 实现 Codec 接口
+func (JSONCodec) Marshal(data map[string]any) ([]byte, error) {
+	return json.Marshal(data)
+}
+
+// Unmarshal 实现 Codec 接口
+func (JSONCodec) Unmarshal(b []byte) (map[string]any, error) {
+	data := make(map[string]any)
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// GobCodec 基于 encoding/gob 的编解码器，可以保留数据的类型
+// 注意：自定义类型需要先通过 gob.Register 注册
+type GobCodec struct{}
+
+// Marshal 实现 Codec 接口
+func (GobCodec) Marshal(data map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal 实现 Codec 接口
+func (GobCodec) Unmarshal(b []byte) (map[string]any, error) {
+	data := make(map[string]any)
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
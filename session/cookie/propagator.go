@@ -1,12 +1,62 @@
 package cookie
 
-import "net/http"
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/justinwongcn/ant/session"
+)
+
+// 确保 Propagator 可以在注入时参考当前请求
+var _ session.RequestPropagator = (*Propagator)(nil)
+
+// ErrInvalidSignature Cookie 的签名不合法，可能被篡改或签名密钥已被移除
+var ErrInvalidSignature = errors.New("cookie: 签名不合法")
+
+// SecureMode Cookie 的 Secure 属性的设置方式
+type SecureMode int
+
+const (
+	// SecureAuto 请求通过 TLS 到达时设置 Secure，默认值
+	// 需要传入请求才能判断，session.Manager 会通过 InjectWith 传入，直接调用 Inject 不会设置 Secure
+	SecureAuto SecureMode = iota
+	// SecureAlways 总是设置 Secure
+	SecureAlways
+	// SecureNever 从不设置 Secure，只应在本地开发时使用
+	SecureNever
+)
+
+// Cookie 名称前缀，浏览器会强制检查带前缀的 Cookie 的属性
+const (
+	// HostPrefix 要求 Secure、Path=/ 且不能设置 Domain，Cookie 只属于当前主机
+	HostPrefix = "__Host-"
+	// SecurePrefix 要求 Secure
+	SecurePrefix = "__Secure-"
+)
 
 // Propagator 基于Cookie的会话传播器
 // 用于在HTTP请求和响应中传递会话信息
+// 默认的 Cookie 属性为 Path=/、HttpOnly、SameSite=Lax，请求通过 TLS 到达时设置 Secure
 type Propagator struct {
 	// cookieName 存储会话ID的Cookie名称
 	cookieName string
+	// prefix Cookie 名称前缀，例如 HostPrefix
+	prefix string
+	// sameSite Cookie 的 SameSite 属性
+	sameSite http.SameSite
+	// secure Secure 属性的设置方式
+	secure SecureMode
+	// partitioned 是否设置 Partitioned 属性（CHIPS），用于第三方上下文中的会话
+	partitioned bool
+	// isTLS 判断请求是否通过 TLS 到达
+	isTLS func(req *http.Request) bool
+	// keyring 签名使用的密钥环，为nil时不签名
+	keyring session.Keyring
 	// cookieOption 用于配置Cookie属性的函数
 	cookieOption func(cookie *http.Cookie)
 }
@@ -18,7 +68,11 @@ type Propagator struct {
 // - *Propagator: 配置完成的Cookie传播器实例
 func NewPropagator(opts ...func(*Propagator)) *Propagator {
 	p := &Propagator{
-		cookieName:   "sessid",
+		cookieName: "sessid",
+		sameSite:   http.SameSiteLaxMode,
+		isTLS: func(req *http.Request) bool {
+			return req.TLS != nil
+		},
 		cookieOption: func(c *http.Cookie) {},
 	}
 
@@ -42,7 +96,7 @@ func WithCookieName(name string) func(*Propagator) {
 
 // WithCookieOption 设置Cookie选项的函数
 // 参数:
-// - fn: 用于配置http.Cookie属性的函数
+// - fn: 用于配置http.Cookie属性的函数，在默认属性之后、单次注入的覆盖之前调用
 // 返回值:
 // - func(*Propagator): 返回一个配置函数，用于设置Cookie选项
 func WithCookieOption(fn func(cookie *http.Cookie)) func(*Propagator) {
@@ -51,6 +105,58 @@ func WithCookieOption(fn func(cookie *http.Cookie)) func(*Propagator) {
 	}
 }
 
+// WithSameSite 设置 Cookie 的 SameSite 属性，默认为 http.SameSiteLaxMode
+// 设置为 http.SameSiteNoneMode 时浏览器要求同时设置 Secure，会自动设置
+func WithSameSite(mode http.SameSite) func(*Propagator) {
+	return func(p *Propagator) {
+		p.sameSite = mode
+	}
+}
+
+// WithSecure 设置 Secure 属性的设置方式，默认为 SecureAuto
+func WithSecure(mode SecureMode) func(*Propagator) {
+	return func(p *Propagator) {
+		p.secure = mode
+	}
+}
+
+// WithTLSDetector 设置判断请求是否通过 TLS 到达的函数，默认检查 req.TLS
+// 位于终止 TLS 的代理之后时，可以在确认对端是受信任的代理后读取 X-Forwarded-Proto：
+//
+//	cookie.WithTLSDetector(func(req *http.Request) bool {
+//		return req.TLS != nil || (fromTrustedProxy(req) && req.Header.Get("X-Forwarded-Proto") == "https")
+//	})
+func WithTLSDetector(fn func(req *http.Request) bool) func(*Propagator) {
+	return func(p *Propagator) {
+		p.isTLS = fn
+	}
+}
+
+// WithPrefix 设置 Cookie 名称前缀，例如 HostPrefix
+// 设置前缀后 Cookie 总是带有 Secure，使用 HostPrefix 时 Path 固定为 / 且不设置 Domain
+func WithPrefix(prefix string) func(*Propagator) {
+	return func(p *Propagator) {
+		p.prefix = prefix
+	}
+}
+
+// WithPartitioned 设置 Partitioned 属性，用于嵌入在第三方页面中的应用
+// 浏览器要求 Partitioned 的 Cookie 同时设置 Secure，会自动设置
+func WithPartitioned() func(*Propagator) {
+	return func(p *Propagator) {
+		p.partitioned = true
+	}
+}
+
+// WithSigning 使用 HMAC-SHA256 对会话ID签名，防止客户端伪造会话ID
+// Cookie 的值为 "会话ID.密钥ID.签名"，可以与 session.EncryptedCodec 共用同一个密钥环
+// 轮换密钥后，使用旧密钥签名的 Cookie 在旧密钥被移除之前仍然有效
+func WithSigning(keyring session.Keyring) func(*Propagator) {
+	return func(p *Propagator) {
+		p.keyring = keyring
+	}
+}
+
 // Inject 将会话ID注入到HTTP响应的Cookie中
 // 参数:
 // - id: 要注入的会话ID
@@ -58,11 +164,29 @@ func WithCookieOption(fn func(cookie *http.Cookie)) func(*Propagator) {
 // 返回值:
 // - error: 注入过程中可能发生的错误
 func (p *Propagator) Inject(id string, writer http.ResponseWriter) error {
-	c := &http.Cookie{
-		Name:  p.cookieName,
-		Value: id,
+	return p.InjectWith(id, writer, nil)
+}
+
+// InjectWith 将会话ID注入到HTTP响应的Cookie中，并允许覆盖本次注入的Cookie属性
+// 参数:
+// - id: 要注入的会话ID
+// - writer: HTTP响应写入器
+// - req: 当前请求，用于在 SecureAuto 模式下判断是否设置 Secure，可以为nil
+// - overrides: 本次注入的属性覆盖，例如"记住我"时设置更长的 MaxAge
+// 返回值:
+// - error: 注入过程中可能发生的错误
+func (p *Propagator) InjectWith(id string, writer http.ResponseWriter, req *http.Request, overrides ...func(cookie *http.Cookie)) error {
+	c := p.newCookie(req)
+	value, err := p.sign(id)
+	if err != nil {
+		return err
 	}
+	c.Value = value
 	p.cookieOption(c)
+	for _, override := range overrides {
+		override(c)
+	}
+	p.enforce(c)
 	http.SetCookie(writer, c)
 
 	return nil
@@ -73,14 +197,65 @@ func (p *Propagator) Inject(id string, writer http.ResponseWriter) error {
 // - req: HTTP请求
 // 返回值:
 // - string: 提取的会话ID
-// - error: 提取过程中可能发生的错误，如Cookie不存在
+// - error: 提取过程中可能发生的错误，如Cookie不存在、签名不合法时返回 ErrInvalidSignature
 func (p *Propagator) Extract(req *http.Request) (string, error) {
-	c, err := req.Cookie(p.cookieName)
+	c, err := req.Cookie(p.name())
+	if err != nil {
+		return "", err
+	}
+
+	return p.verify(c.Value)
+}
+
+// sign 在启用签名时为会话ID附加密钥ID与签名
+func (p *Propagator) sign(id string) (string, error) {
+	if p.keyring == nil {
+		return id, nil
+	}
+	keyID, key, err := p.keyring.Current()
 	if err != nil {
 		return "", err
 	}
+	return id + "." + keyID + "." + p.mac(key, id, keyID), nil
+}
+
+// verify 在启用签名时校验签名并返回会话ID
+func (p *Propagator) verify(value string) (string, error) {
+	if p.keyring == nil {
+		return value, nil
+	}
+	rest, sig, ok := cutLast(value)
+	if !ok {
+		return "", ErrInvalidSignature
+	}
+	id, keyID, ok := cutLast(rest)
+	if !ok {
+		return "", ErrInvalidSignature
+	}
+	key, err := p.keyring.Key(keyID)
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(sig), []byte(p.mac(key, id, keyID))) {
+		return "", ErrInvalidSignature
+	}
+	return id, nil
+}
 
-	return c.Value, nil
+// mac 计算签名，Cookie 名称参与签名，防止同一个值被用于其他 Cookie
+func (p *Propagator) mac(key []byte, id, keyID string) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(p.name() + "=" + id + "." + keyID))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// cutLast 按最后一个 "." 切分
+func cutLast(s string) (before, after string, ok bool) {
+	i := strings.LastIndexByte(s, '.')
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+1:], true
 }
 
 // Remove 从HTTP响应中移除会话Cookie
@@ -88,12 +263,52 @@ func (p *Propagator) Extract(req *http.Request) (string, error) {
 // - writer: HTTP响应写入器
 // 返回值:
 // - error: 移除过程中可能发生的错误
+// 注意：浏览器只会删除 Path 与 Domain 相同的 Cookie，因此使用与注入时相同的属性
 func (p *Propagator) Remove(writer http.ResponseWriter) error {
-	c := &http.Cookie{
-		Name:   p.cookieName,
-		MaxAge: -1,
-	}
+	c := p.newCookie(nil)
+	p.cookieOption(c)
+	c.MaxAge = -1
+	c.Expires = time.Time{}
+	p.enforce(c)
 	http.SetCookie(writer, c)
 
 	return nil
 }
+
+// name 返回带前缀的 Cookie 名称
+func (p *Propagator) name() string {
+	if p.prefix == "" || strings.HasPrefix(p.cookieName, p.prefix) {
+		return p.cookieName
+	}
+	return p.prefix + p.cookieName
+}
+
+// newCookie 创建带有默认属性的 Cookie
+func (p *Propagator) newCookie(req *http.Request) *http.Cookie {
+	c := &http.Cookie{
+		Name:        p.name(),
+		Path:        "/",
+		HttpOnly:    true,
+		SameSite:    p.sameSite,
+		Partitioned: p.partitioned,
+	}
+	switch p.secure {
+	case SecureAlways:
+		c.Secure = true
+	case SecureAuto:
+		c.Secure = req != nil && p.isTLS(req)
+	}
+	return c
+}
+
+// enforce 设置浏览器强制要求的属性，避免 Cookie 被浏览器拒绝
+func (p *Propagator) enforce(c *http.Cookie) {
+	c.Name = p.name()
+	if p.prefix != "" || c.SameSite == http.SameSiteNoneMode || c.Partitioned {
+		c.Secure = true
+	}
+	if p.prefix == HostPrefix {
+		c.Path = "/"
+		c.Domain = ""
+	}
+}
@@ -1,10 +1,17 @@
 package cookie
 
 import (
+	"bytes"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/justinwongcn/ant"
+	"github.com/justinwongcn/ant/session"
+	"github.com/justinwongcn/ant/session/memory"
 )
 
 func TestNewPropagator(t *testing.T) {
@@ -319,3 +326,184 @@ func TestWithCookieOption(t *testing.T) {
 		})
 	}
 }
+
+func TestPropagatorDefaults(t *testing.T) {
+	p := NewPropagator()
+	w := httptest.NewRecorder()
+	if err := p.Inject("id", w); err != nil {
+		t.Fatalf("注入失败: %v", err)
+	}
+	c := w.Result().Cookies()[0]
+	if c.Path != "/" || !c.HttpOnly || c.SameSite != http.SameSiteLaxMode {
+		t.Errorf("默认属性应为 Path=/、HttpOnly、SameSite=Lax，实际为 %v", c)
+	}
+	if c.Secure {
+		t.Error("没有请求时不应设置 Secure")
+	}
+
+	// 请求通过 TLS 到达时自动设置 Secure
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	w = httptest.NewRecorder()
+	_ = p.InjectWith("id", w, req)
+	if !w.Result().Cookies()[0].Secure {
+		t.Error("TLS 请求应设置 Secure")
+	}
+
+	// SecureNever 时不设置
+	w = httptest.NewRecorder()
+	_ = NewPropagator(WithSecure(SecureNever)).InjectWith("id", w, req)
+	if w.Result().Cookies()[0].Secure {
+		t.Error("SecureNever 不应设置 Secure")
+	}
+}
+
+func TestPropagatorTLSDetector(t *testing.T) {
+	p := NewPropagator(WithTLSDetector(func(req *http.Request) bool {
+		return req.Header.Get("X-Forwarded-Proto") == "https"
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+	_ = p.InjectWith("id", w, req)
+	if !w.Result().Cookies()[0].Secure {
+		t.Error("自定义检测认为是 TLS 时应设置 Secure")
+	}
+}
+
+func TestPropagatorSameSiteNone(t *testing.T) {
+	p := NewPropagator(WithSameSite(http.SameSiteNoneMode), WithPartitioned())
+	w := httptest.NewRecorder()
+	_ = p.Inject("id", w)
+	c := w.Result().Cookies()[0]
+	if c.SameSite != http.SameSiteNoneMode || !c.Partitioned {
+		t.Errorf("应为 SameSite=None 且 Partitioned，实际为 %v", c)
+	}
+	if !c.Secure {
+		t.Error("SameSite=None 与 Partitioned 必须设置 Secure")
+	}
+}
+
+func TestPropagatorHostPrefix(t *testing.T) {
+	p := NewPropagator(WithPrefix(HostPrefix), WithCookieOption(func(c *http.Cookie) {
+		c.Domain = "example.com"
+		c.Path = "/app"
+	}))
+	w := httptest.NewRecorder()
+	_ = p.Inject("id", w)
+	c := w.Result().Cookies()[0]
+	if c.Name != "__Host-sessid" {
+		t.Errorf("Cookie 名称应为 '__Host-sessid'，实际为 '%s'", c.Name)
+	}
+	if !c.Secure || c.Path != "/" || c.Domain != "" {
+		t.Errorf("__Host- 前缀要求 Secure、Path=/ 且不设置 Domain，实际为 %v", c)
+	}
+
+	// 提取与删除使用带前缀的名称
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "__Host-sessid", Value: "id"})
+	id, err := p.Extract(req)
+	if err != nil || id != "id" {
+		t.Errorf("应提取到会话ID 'id'，实际为 '%s'，错误: %v", id, err)
+	}
+	w = httptest.NewRecorder()
+	_ = p.Remove(w)
+	c = w.Result().Cookies()[0]
+	if c.Name != "__Host-sessid" || c.MaxAge != -1 || !c.Secure || c.Path != "/" {
+		t.Errorf("删除时应使用与注入相同的属性，实际为 %v", c)
+	}
+}
+
+func TestPropagatorInjectOverrides(t *testing.T) {
+	p := NewPropagator(WithCookieOption(func(c *http.Cookie) {
+		c.MaxAge = 1800
+	}))
+	w := httptest.NewRecorder()
+	// 记住我：本次注入使用更长的有效期
+	_ = p.InjectWith("id", w, nil, func(c *http.Cookie) {
+		c.MaxAge = int((30 * 24 * time.Hour).Seconds())
+	})
+	if got := w.Result().Cookies()[0].MaxAge; got != 2592000 {
+		t.Errorf("覆盖后 MaxAge 应为 2592000，实际为 %d", got)
+	}
+
+	// 覆盖不影响后续注入
+	w = httptest.NewRecorder()
+	_ = p.Inject("id", w)
+	if got := w.Result().Cookies()[0].MaxAge; got != 1800 {
+		t.Errorf("MaxAge 应为 1800，实际为 %d", got)
+	}
+}
+
+func TestPropagatorSigning(t *testing.T) {
+	keyring, err := session.NewStaticKeyring("k1", bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPropagator(WithSigning(keyring))
+
+	extract := func(value string) (string, error) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "sessid", Value: value})
+		return p.Extract(req)
+	}
+
+	w := httptest.NewRecorder()
+	if err = p.Inject("abc", w); err != nil {
+		t.Fatal(err)
+	}
+	signed := w.Result().Cookies()[0].Value
+	if !strings.HasPrefix(signed, "abc.k1.") {
+		t.Fatalf("签名后的值应以 abc.k1. 开头，实际为 %q", signed)
+	}
+	if id, err := extract(signed); err != nil || id != "abc" {
+		t.Errorf("应提取出 abc，实际为 %q %v", id, err)
+	}
+
+	// 轮换后旧签名仍然有效，移除旧密钥后失效
+	if err = keyring.Rotate("k2", bytes.Repeat([]byte{2}, 32)); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := extract(signed); err != nil || id != "abc" {
+		t.Errorf("轮换后旧签名应仍然有效，实际为 %q %v", id, err)
+	}
+	_ = keyring.Remove("k1")
+	for _, value := range []string{signed, "abc", "abd.k2." + strings.Split(signed, ".")[2]} {
+		if _, err := extract(value); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%q 应返回 ErrInvalidSignature，实际为 %v", value, err)
+		}
+	}
+}
+
+// TestManagerSecureBehindTLS 测试通过 Manager 注入时根据请求是否使用 TLS 设置 Secure
+func TestManagerSecureBehindTLS(t *testing.T) {
+	m := &session.Manager{
+		Store:      memory.NewStore(time.Minute),
+		Propagator: NewPropagator(),
+		SessCtxKey: "sess",
+	}
+
+	for _, tls := range []bool{true, false} {
+		req := httptest.NewRequest(http.MethodGet, "https://example.com/login", nil)
+		if !tls {
+			req.TLS = nil
+		}
+		w := httptest.NewRecorder()
+		ctx := ant.Context{Req: req, Resp: w}
+		if _, err := m.InitSession(ctx, "abc"); err != nil {
+			t.Fatal(err)
+		}
+		if c := w.Result().Cookies()[0]; c.Secure != tls {
+			t.Errorf("InitSession TLS=%v 时 Secure 应为 %v", tls, tls)
+		}
+
+		req.AddCookie(&http.Cookie{Name: "sessid", Value: "abc"})
+		w = httptest.NewRecorder()
+		ctx = ant.Context{Req: req, Resp: w}
+		if _, err := m.RefreshSession(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if c := w.Result().Cookies()[0]; c.Secure != tls {
+			t.Errorf("RefreshSession TLS=%v 时 Secure 应为 %v", tls, tls)
+		}
+	}
+}
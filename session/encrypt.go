@@ -0,0 +1,113 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+)
+
+// ErrDecrypt 会话数据无法解密，可能被篡改或使用了错误的密钥
+var ErrDecrypt = errors.New("session: 会话数据解密失败")
+
+// encryptedVersion 加密格式的版本号
+const encryptedVersion byte = 1
+
+// EncryptedCodec 在序列化之后使用 AES-GCM 加密会话数据，适用于 Redis、memcached 或数据库等远程存储
+// 数据格式为：版本号(1字节) | 密钥ID长度(1字节) | 密钥ID | nonce | 密文
+// 密钥ID与会话ID作为附加数据参与认证，轮换密钥后旧数据仍可以使用旧密钥解密，下次写入时使用新密钥
+// 通过 MarshalSession 加密的数据只能由同一个会话ID解密，复制到其他会话的存储项中无法使用；
+// redis 与 memcached 存储会自动传入会话ID，直接调用 Marshal 的数据不绑定会话
+//
+//	keyring, _ := session.NewStaticKeyring("2024-01", key)
+//	store, _ := memcached.NewStore(addrs, 30*time.Minute, memcached.WithCodec(session.NewEncryptedCodec(session.GobCodec{}, keyring)))
+type EncryptedCodec struct {
+	inner   Codec
+	keyring Keyring
+}
+
+// NewEncryptedCodec 创建加密编解码器
+// inner: 实际序列化会话数据的编解码器，例如 GobCodec
+func NewEncryptedCodec(inner Codec, keyring Keyring) *EncryptedCodec {
+	return &EncryptedCodec{inner: inner, keyring: keyring}
+}
+
+// 确保 EncryptedCodec 在远程存储中与会话ID绑定
+var _ SessionCodec = (*EncryptedCodec)(nil)
+
+// Marshal 实现 Codec 接口，加密结果不绑定会话ID
+func (c *EncryptedCodec) Marshal(data map[string]any) ([]byte, error) {
+	return c.MarshalSession("", data)
+}
+
+// Unmarshal 实现 Codec 接口，只能解密没有绑定会话ID的数据
+func (c *EncryptedCodec) Unmarshal(b []byte) (map[string]any, error) {
+	return c.UnmarshalSession("", b)
+}
+
+// MarshalSession 实现 SessionCodec 接口，加密结果与会话ID绑定
+func (c *EncryptedCodec) MarshalSession(sessionID string, data map[string]any) ([]byte, error) {
+	plain, err := c.inner.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	id, key, err := c.keyring.Current()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, 0, 2+len(id)+aead.NonceSize())
+	header = append(header, encryptedVersion, byte(len(id)))
+	header = append(header, id...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(header, nonce...)
+	return aead.Seal(out, nonce, plain, additionalData(id, sessionID)), nil
+}
+
+// UnmarshalSession 实现 SessionCodec 接口
+// 密钥已被移除时返回 ErrUnknownKey，数据被篡改或不属于该会话时返回 ErrDecrypt
+func (c *EncryptedCodec) UnmarshalSession(sessionID string, b []byte) (map[string]any, error) {
+	if len(b) < 2 || b[0] != encryptedVersion || len(b) < 2+int(b[1]) {
+		return nil, ErrDecrypt
+	}
+	id := b[2 : 2+int(b[1])]
+	key, err := c.keyring.Key(string(id))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	rest := b[2+len(id):]
+	if len(rest) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], additionalData(string(id), sessionID))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return c.inner.Unmarshal(plain)
+}
+
+// additionalData 返回参与认证的附加数据：密钥ID长度(1字节) | 密钥ID | 会话ID
+func additionalData(keyID, sessionID string) []byte {
+	ad := make([]byte, 0, 1+len(keyID)+len(sessionID))
+	ad = append(ad, byte(len(keyID)))
+	ad = append(ad, keyID...)
+	return append(ad, sessionID...)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
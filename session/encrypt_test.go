@@ -0,0 +1,89 @@
+package session_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/justinwongcn/ant/session"
+)
+
+func TestEncryptedCodec(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	keyring, err := session.NewStaticKeyring("k1", oldKey)
+	require.NoError(t, err)
+	codec := session.NewEncryptedCodec(session.JSONCodec{}, keyring)
+
+	data := map[string]any{"user": "tom", "role": "admin"}
+	b, err := codec.Marshal(data)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(b, []byte("tom")), "存储的数据不应包含明文")
+
+	again, err := codec.Marshal(data)
+	require.NoError(t, err)
+	assert.NotEqual(t, b, again, "每次加密应使用不同的 nonce")
+
+	got, err := codec.Unmarshal(b)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	// 轮换密钥后旧数据仍可以解密，新数据使用新密钥
+	require.NoError(t, keyring.Rotate("k2", bytes.Repeat([]byte{2}, 16)))
+	got, err = codec.Unmarshal(b)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+	fresh, err := codec.Marshal(data)
+	require.NoError(t, err)
+
+	// 移除旧密钥后旧数据无法解密
+	require.Error(t, keyring.Remove("k2"), "不能移除当前密钥")
+	require.NoError(t, keyring.Remove("k1"))
+	_, err = codec.Unmarshal(b)
+	assert.True(t, errors.Is(err, session.ErrUnknownKey))
+	_, err = codec.Unmarshal(fresh)
+	require.NoError(t, err)
+
+	// 篡改的数据无法解密
+	fresh[len(fresh)-1] ^= 1
+	_, err = codec.Unmarshal(fresh)
+	assert.ErrorIs(t, err, session.ErrDecrypt)
+	_, err = codec.Unmarshal([]byte("x"))
+	assert.ErrorIs(t, err, session.ErrDecrypt)
+}
+
+func TestEncryptedCodecSessionBinding(t *testing.T) {
+	keyring, err := session.NewStaticKeyring("k1", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	codec := session.NewEncryptedCodec(session.GobCodec{}, keyring)
+
+	data := map[string]any{"user": "admin"}
+	b, err := session.MarshalSession(codec, "sess-a", data)
+	require.NoError(t, err)
+
+	got, err := session.UnmarshalSession(codec, "sess-a", b)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	// 复制到其他会话或不带会话ID解密都会失败
+	_, err = session.UnmarshalSession(codec, "sess-b", b)
+	assert.ErrorIs(t, err, session.ErrDecrypt)
+	_, err = codec.Unmarshal(b)
+	assert.ErrorIs(t, err, session.ErrDecrypt)
+
+	// 没有实现 SessionCodec 的编解码器不受影响
+	plain, err := session.MarshalSession(session.JSONCodec{}, "sess-a", data)
+	require.NoError(t, err)
+	got, err = session.UnmarshalSession(session.JSONCodec{}, "sess-b", plain)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+}
+
+func TestStaticKeyringValidation(t *testing.T) {
+	_, err := session.NewStaticKeyring("k1", []byte("short"))
+	assert.Error(t, err)
+	_, err = session.NewStaticKeyring("k.1", bytes.Repeat([]byte{1}, 16))
+	assert.Error(t, err)
+}
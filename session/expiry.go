@@ -0,0 +1,115 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/justinwongcn/ant"
+)
+
+// TTLReporter 可以查询会话剩余有效期的存储
+type TTLReporter interface {
+	// TTL 返回会话的剩余有效期，会话永不过期时返回负数
+	TTL(ctx context.Context, id string) (time.Duration, error)
+}
+
+// 报告会话有效期的响应头
+const (
+	// HeaderExpiresIn 会话剩余有效期，单位为秒
+	HeaderExpiresIn = "X-Session-Expires-In"
+	// HeaderExpiring 会话即将过期时为 "1"，前端可以据此提醒用户或静默刷新会话
+	HeaderExpiring = "X-Session-Expiring"
+)
+
+// ExpiryStatus 会话的有效期状态
+type ExpiryStatus struct {
+	// ExpiresIn 剩余有效期，单位为秒，永不过期时为 -1
+	ExpiresIn int64 `json:"expires_in"`
+	// ExpiresAt 过期时间，永不过期时省略
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// NearExpiry 是否即将过期
+	NearExpiry bool `json:"near_expiry"`
+}
+
+// ExpiryMiddlewareBuilder 会话过期提醒中间件构建器
+// 在响应头中报告会话的剩余有效期，会话即将过期时标记响应头并触发 OnNearExpiry
+// 存储需要实现 TTLReporter 接口，否则中间件不做任何处理
+type ExpiryMiddlewareBuilder struct {
+	manager *Manager
+	// WarnBefore 剩余有效期不超过该值时视为即将过期，默认为 5 分钟
+	WarnBefore time.Duration
+	// OnNearExpiry 会话即将过期时调用，可以用于记录日志或推送通知
+	OnNearExpiry func(ctx *ant.Context, id string, remaining time.Duration)
+}
+
+// NewExpiryMiddlewareBuilder 创建会话过期提醒中间件构建器
+// m: 会话管理器
+func NewExpiryMiddlewareBuilder(m *Manager) *ExpiryMiddlewareBuilder {
+	return &ExpiryMiddlewareBuilder{
+		manager:    m,
+		WarnBefore: 5 * time.Minute,
+	}
+}
+
+// Build 构建中间件
+// 有效期在调用后续处理器之前读取，处理器中刷新的会话会在下一次请求中体现
+func (b *ExpiryMiddlewareBuilder) Build() ant.Middleware {
+	return func(next ant.HandleFunc) ant.HandleFunc {
+		return func(ctx *ant.Context) {
+			if id, st, ok := b.status(ctx); ok {
+				ctx.Resp.Header().Set(HeaderExpiresIn, strconv.FormatInt(st.ExpiresIn, 10))
+				if st.NearExpiry {
+					ctx.Resp.Header().Set(HeaderExpiring, "1")
+					if b.OnNearExpiry != nil {
+						b.OnNearExpiry(ctx, id, time.Duration(st.ExpiresIn)*time.Second)
+					}
+				}
+			}
+			next(ctx)
+		}
+	}
+}
+
+// Handler 返回报告会话有效期的处理器，响应 ExpiryStatus 的 JSON
+// 查询不会刷新会话，前端可以轮询该接口而不延长会话
+// 没有会话或存储无法报告有效期时响应 401
+func (b *ExpiryMiddlewareBuilder) Handler() ant.HandleFunc {
+	return func(ctx *ant.Context) {
+		_, st, ok := b.status(ctx)
+		if !ok {
+			_ = ctx.RespJSON(http.StatusUnauthorized, map[string]string{"error": "会话不存在"})
+			return
+		}
+		ctx.NoStore()
+		_ = ctx.RespJSONOK(st)
+	}
+}
+
+// status 查询当前请求的会话有效期
+func (b *ExpiryMiddlewareBuilder) status(ctx *ant.Context) (string, ExpiryStatus, bool) {
+	reporter, ok := b.manager.Store.(TTLReporter)
+	if !ok {
+		return "", ExpiryStatus{}, false
+	}
+	sess, err := b.manager.GetSession(*ctx)
+	if err != nil {
+		return "", ExpiryStatus{}, false
+	}
+	ttl, err := reporter.TTL(ctx.Req.Context(), sess.ID())
+	if err != nil {
+		return "", ExpiryStatus{}, false
+	}
+	if ttl < 0 {
+		return sess.ID(), ExpiryStatus{ExpiresIn: -1}, true
+	}
+	at := time.Now().Add(ttl).Truncate(time.Second)
+	// 向上取整，避免剩余不足一秒时报告为 0
+	secs := int64((ttl + time.Second - 1) / time.Second)
+	return sess.ID(), ExpiryStatus{
+		ExpiresIn:  secs,
+		ExpiresAt:  &at,
+		NearExpiry: ttl <= b.WarnBefore,
+	}, true
+}
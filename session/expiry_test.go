@@ -0,0 +1,89 @@
+package session_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/justinwongcn/ant"
+	"github.com/justinwongcn/ant/session"
+	"github.com/justinwongcn/ant/session/cookie"
+	"github.com/justinwongcn/ant/session/memory"
+)
+
+func newExpiryServer(t *testing.T, ttl time.Duration) (*ant.HTTPServer, *session.ExpiryMiddlewareBuilder, *[]string) {
+	t.Helper()
+	store := memory.NewStore(ttl)
+	_, err := store.Generate(context.Background(), "sess")
+	require.NoError(t, err)
+	m := &session.Manager{Store: store, Propagator: cookie.NewPropagator(), SessCtxKey: "session"}
+
+	var warned []string
+	b := session.NewExpiryMiddlewareBuilder(m)
+	b.OnNearExpiry = func(_ *ant.Context, id string, _ time.Duration) {
+		warned = append(warned, id)
+	}
+	s := ant.NewHTTPServer()
+	s.Use(b.Build())
+	s.Handle("GET /hello", func(ctx *ant.Context) {
+		_ = ctx.WriteString("hello")
+	})
+	s.Handle("GET /session/ttl", b.Handler())
+	return s, b, &warned
+}
+
+func expiryRequest(s *ant.HTTPServer, path string, withCookie bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if withCookie {
+		req.AddCookie(&http.Cookie{Name: "sessid", Value: "sess"})
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w
+}
+
+func TestExpiryMiddleware(t *testing.T) {
+	s, b, warned := newExpiryServer(t, time.Hour)
+
+	w := expiryRequest(s, "/hello", true)
+	secs, err := strconv.Atoi(w.Header().Get(session.HeaderExpiresIn))
+	require.NoError(t, err)
+	assert.InDelta(t, 3600, secs, 2)
+	assert.Empty(t, w.Header().Get(session.HeaderExpiring))
+	assert.Empty(t, *warned)
+
+	// 剩余有效期不超过 WarnBefore 时视为即将过期
+	b.WarnBefore = 2 * time.Hour
+	w = expiryRequest(s, "/hello", true)
+	assert.Equal(t, "1", w.Header().Get(session.HeaderExpiring))
+	assert.Equal(t, []string{"sess"}, *warned)
+
+	// 没有会话时不报告
+	w = expiryRequest(s, "/hello", false)
+	assert.Equal(t, "hello", w.Body.String())
+	assert.Empty(t, w.Header().Get(session.HeaderExpiresIn))
+}
+
+func TestExpiryHandler(t *testing.T) {
+	s, _, _ := newExpiryServer(t, time.Minute)
+
+	w := expiryRequest(s, "/session/ttl", true)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var st session.ExpiryStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+	assert.InDelta(t, 60, st.ExpiresIn, 2)
+	assert.True(t, st.NearExpiry)
+	require.NotNil(t, st.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *st.ExpiresAt, 2*time.Second)
+
+	w = expiryRequest(s, "/session/ttl", false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
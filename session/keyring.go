@@ -0,0 +1,84 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownKey 密钥环中不存在指定ID的密钥，通常是密钥已被移除
+var ErrUnknownKey = errors.New("session: 未知的密钥")
+
+// Keyring 密钥环，管理加密会话数据与签名 Cookie 使用的密钥
+// 新数据总是使用当前密钥，旧数据通过密钥ID查找轮换前的密钥
+type Keyring interface {
+	// Current 返回当前使用的密钥ID与密钥
+	Current() (id string, key []byte, err error)
+	// Key 按ID查找密钥，不存在时返回 ErrUnknownKey
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeyring 保存在内存中的密钥环，可以并发使用
+type StaticKeyring struct {
+	mu      sync.RWMutex
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeyring 创建密钥环，并将 id 对应的密钥设置为当前密钥
+// 密钥ID不能包含 "."，密钥长度必须为 16、24 或 32 字节，分别对应 AES-128、AES-192 与 AES-256
+func NewStaticKeyring(id string, key []byte) (*StaticKeyring, error) {
+	k := &StaticKeyring{keys: make(map[string][]byte)}
+	if err := k.Rotate(id, key); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Rotate 添加新密钥并设置为当前密钥，之前的密钥仍可用于解密与验证
+// 所有旧数据都已过期后，可以通过 Remove 移除旧密钥
+func (k *StaticKeyring) Rotate(id string, key []byte) error {
+	if id == "" || len(id) > 255 || strings.Contains(id, ".") {
+		return fmt.Errorf("session: 密钥ID %q 不合法", id)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return fmt.Errorf("session: 密钥长度必须为 16、24 或 32 字节，实际为 %d", len(key))
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[id] = append([]byte(nil), key...)
+	k.current = id
+	return nil
+}
+
+// Remove 移除旧密钥，不能移除当前密钥
+func (k *StaticKeyring) Remove(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if id == k.current {
+		return fmt.Errorf("session: 不能移除当前密钥 %s", id)
+	}
+	delete(k.keys, id)
+	return nil
+}
+
+// Current 实现 Keyring 接口
+func (k *StaticKeyring) Current() (string, []byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current, k.keys[k.current], nil
+}
+
+// Key 实现 Keyring 接口
+func (k *StaticKeyring) Key(id string) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	return key, nil
+}
@@ -58,7 +58,7 @@ func (m *Manager) InitSession(ctx ant.Context, id string) (Session, error) {
 		return nil, err
 	}
 
-	if err = m.Inject(id, ctx.Resp); err != nil {
+	if err = m.inject(ctx, id); err != nil {
 		return nil, err
 	}
 	return sess, nil
@@ -83,12 +83,21 @@ func (m *Manager) RefreshSession(ctx ant.Context) (Session, error) {
 	}
 
 	// 重新注入到HTTP响应中
-	if err = m.Inject(sess.ID(), ctx.Resp); err != nil {
+	if err = m.inject(ctx, sess.ID()); err != nil {
 		return nil, err
 	}
 	return sess, nil
 }
 
+// inject 将会话ID注入到HTTP响应中
+// Propagator 实现了 RequestPropagator 时传入当前请求，例如 Cookie 传播器据此在 TLS 下设置 Secure
+func (m *Manager) inject(ctx ant.Context, id string) error {
+	if p, ok := m.Propagator.(RequestPropagator); ok {
+		return p.InjectWith(id, ctx.Resp, ctx.Req)
+	}
+	return m.Inject(id, ctx.Resp)
+}
+
 // RemoveSession 删除会话
 // ctx: 上下文，包含请求和响应信息
 // 返回值: 删除过程中的错误
@@ -0,0 +1,266 @@
+package memcached
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// 二进制协议的操作码与状态码
+const (
+	magicRequest  = 0x80
+	magicResponse = 0x81
+
+	opGet    = 0x00
+	opSet    = 0x01
+	opDelete = 0x04
+	opNoop   = 0x0a
+	opTouch  = 0x1c
+
+	statusOK          = 0x0000
+	statusKeyNotFound = 0x0001
+
+	headerLen = 24
+
+	// maxRelativeExpiration memcached 将超过 30 天的过期时间视为 Unix 时间戳
+	maxRelativeExpiration = 30 * 24 * time.Hour
+
+	// virtualNodes 一致性哈希中每个节点的虚拟节点数量
+	virtualNodes = 160
+)
+
+// errKeyNotFound 键不存在
+var errKeyNotFound = errors.New("memcached: key not found")
+
+// statusError 服务器返回的错误状态
+type statusError struct {
+	status uint16
+	msg    string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("memcached: 状态码 0x%04x: %s", e.status, e.msg)
+}
+
+// request 二进制协议的请求
+type request struct {
+	opcode byte
+	key    string
+	extras []byte
+	value  []byte
+}
+
+// response 二进制协议的响应
+type response struct {
+	status uint16
+	extras []byte
+	value  []byte
+}
+
+// node memcached 节点，维护空闲连接
+type node struct {
+	addr string
+	idle chan net.Conn
+}
+
+// client 基于二进制协议的 memcached 客户端，按一致性哈希将键分布到各节点
+type client struct {
+	nodes   []*node
+	ring    []uint32
+	owners  map[uint32]*node
+	timeout time.Duration
+}
+
+// newClient 创建客户端
+func newClient(addrs []string, timeout time.Duration, maxIdle int) *client {
+	c := &client{owners: make(map[uint32]*node), timeout: timeout}
+	for _, addr := range addrs {
+		n := &node{addr: addr, idle: make(chan net.Conn, maxIdle)}
+		c.nodes = append(c.nodes, n)
+		for i := 0; i < virtualNodes; i++ {
+			h := crc32.ChecksumIEEE([]byte(addr + "#" + strconv.Itoa(i)))
+			if _, ok := c.owners[h]; ok {
+				continue
+			}
+			c.owners[h] = n
+			c.ring = append(c.ring, h)
+		}
+	}
+	sort.Slice(c.ring, func(i, j int) bool { return c.ring[i] < c.ring[j] })
+	return c
+}
+
+// pick 返回负责该键的节点，增删节点时只有少部分键需要迁移
+func (c *client) pick(key string) *node {
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(c.ring), func(i int) bool { return c.ring[i] >= h })
+	if i == len(c.ring) {
+		i = 0
+	}
+	return c.owners[c.ring[i]]
+}
+
+// expiration 将过期时间转换为 memcached 的格式
+func expiration(ttl time.Duration) uint32 {
+	if ttl <= 0 {
+		return 0
+	}
+	if ttl > maxRelativeExpiration {
+		return uint32(time.Now().Add(ttl).Unix())
+	}
+	// 不足一秒时按一秒处理，0 表示永不过期
+	return uint32((ttl + time.Second - 1) / time.Second)
+}
+
+// get 读取键的值
+func (c *client) get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := c.do(ctx, c.pick(key), request{opcode: opGet, key: key})
+	if err != nil {
+		return nil, err
+	}
+	return resp.value, nil
+}
+
+// set 写入键的值
+func (c *client) set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	extras := make([]byte, 8)
+	binary.BigEndian.PutUint32(extras[4:], expiration(ttl))
+	_, err := c.do(ctx, c.pick(key), request{opcode: opSet, key: key, extras: extras, value: value})
+	return err
+}
+
+// touch 更新键的过期时间
+func (c *client) touch(ctx context.Context, key string, ttl time.Duration) error {
+	extras := make([]byte, 4)
+	binary.BigEndian.PutUint32(extras, expiration(ttl))
+	_, err := c.do(ctx, c.pick(key), request{opcode: opTouch, key: key, extras: extras})
+	return err
+}
+
+// delete 删除键
+func (c *client) delete(ctx context.Context, key string) error {
+	_, err := c.do(ctx, c.pick(key), request{opcode: opDelete, key: key})
+	return err
+}
+
+// ping 探测全部节点
+func (c *client) ping(ctx context.Context) error {
+	var errs []error
+	for _, n := range c.nodes {
+		if _, err := c.do(ctx, n, request{opcode: opNoop}); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.addr, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// close 关闭全部空闲连接
+func (c *client) close() error {
+	for _, n := range c.nodes {
+	drain:
+		for {
+			select {
+			case conn := <-n.idle:
+				_ = conn.Close()
+			default:
+				break drain
+			}
+		}
+	}
+	return nil
+}
+
+// do 在节点上执行请求，键不存在时返回 errKeyNotFound
+func (c *client) do(ctx context.Context, n *node, req request) (response, error) {
+	conn, err := c.conn(ctx, n)
+	if err != nil {
+		return response{}, err
+	}
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return response{}, err
+	}
+
+	resp, err := roundTrip(conn, req)
+	if err != nil {
+		// 连接状态未知，不再复用
+		_ = conn.Close()
+		return response{}, err
+	}
+	select {
+	case n.idle <- conn:
+	default:
+		_ = conn.Close()
+	}
+
+	switch resp.status {
+	case statusOK:
+		return resp, nil
+	case statusKeyNotFound:
+		return resp, errKeyNotFound
+	default:
+		return resp, &statusError{status: resp.status, msg: string(resp.value)}
+	}
+}
+
+// conn 获取空闲连接或建立新连接
+func (c *client) conn(ctx context.Context, n *node) (net.Conn, error) {
+	select {
+	case conn := <-n.idle:
+		return conn, nil
+	default:
+	}
+	dialer := net.Dialer{Timeout: c.timeout}
+	return dialer.DialContext(ctx, "tcp", n.addr)
+}
+
+// roundTrip 发送请求并读取响应
+func roundTrip(rw io.ReadWriter, req request) (response, error) {
+	bodyLen := len(req.extras) + len(req.key) + len(req.value)
+	buf := make([]byte, headerLen+bodyLen)
+	buf[0] = magicRequest
+	buf[1] = req.opcode
+	binary.BigEndian.PutUint16(buf[2:], uint16(len(req.key)))
+	buf[4] = byte(len(req.extras))
+	binary.BigEndian.PutUint32(buf[8:], uint32(bodyLen))
+	n := headerLen
+	n += copy(buf[n:], req.extras)
+	n += copy(buf[n:], req.key)
+	copy(buf[n:], req.value)
+	if _, err := rw.Write(buf); err != nil {
+		return response{}, err
+	}
+
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(rw, header); err != nil {
+		return response{}, err
+	}
+	if header[0] != magicResponse || header[1] != req.opcode {
+		return response{}, errors.New("memcached: 无效的响应")
+	}
+	keyLen := int(binary.BigEndian.Uint16(header[2:]))
+	extrasLen := int(header[4])
+	body := make([]byte, binary.BigEndian.Uint32(header[8:]))
+	if _, err := io.ReadFull(rw, body); err != nil {
+		return response{}, err
+	}
+	if extrasLen+keyLen > len(body) {
+		return response{}, errors.New("memcached: 无效的响应")
+	}
+	return response{
+		status: binary.BigEndian.Uint16(header[6:]),
+		extras: body[:extrasLen],
+		value:  body[extrasLen+keyLen:],
+	}, nil
+}
@@ -0,0 +1,184 @@
+package memcached
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/justinwongcn/ant/session"
+)
+
+// ErrSessionNotFound 会话不存在或已过期
+var ErrSessionNotFound = errors.New("memcached: session not found")
+
+// 确保 Store 实现了 session.Store 接口
+var _ session.Store = (*Store)(nil)
+
+// Store 基于 memcached 的会话存储
+// 使用二进制协议通信，多个节点之间按一致性哈希分布会话
+type Store struct {
+	client     *client
+	expiration time.Duration
+	prefix     string
+	codec      session.Codec
+}
+
+// Option 定义 Store 的配置选项函数类型
+type Option func(s *Store)
+
+// WithPrefix 设置会话键的前缀，默认为 "session:"
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// WithCodec 设置会话数据的编解码器，默认为 session.GobCodec
+func WithCodec(codec session.Codec) Option {
+	return func(s *Store) {
+		s.codec = codec
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间，默认为 1 秒
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Store) {
+		s.client.timeout = timeout
+	}
+}
+
+// NewStore 创建基于 memcached 的会话存储
+// addrs: memcached 节点地址，例如 "127.0.0.1:11211"
+// expiration: 会话的过期时间，超过 30 天时自动转换为 memcached 要求的绝对时间
+// opts: 可选的配置选项
+// 返回值: 创建的 Store 实例，没有节点地址时返回错误
+func NewStore(addrs []string, expiration time.Duration, opts ...Option) (*Store, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("memcached: 至少需要一个节点地址")
+	}
+	s := &Store{
+		client:     newClient(addrs, time.Second, 8),
+		expiration: expiration,
+		prefix:     "session:",
+		codec:      session.GobCodec{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Generate 生成一个新的会话并写入 memcached
+func (s *Store) Generate(ctx context.Context, id string) (session.Session, error) {
+	sess := &memcachedSession{id: id, store: s, data: make(map[string]any)}
+	if err := s.save(ctx, id, sess.data); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+// Refresh 刷新会话的过期时间
+func (s *Store) Refresh(ctx context.Context, id string) error {
+	err := s.client.touch(ctx, s.prefix+id, s.expiration)
+	if errors.Is(err, errKeyNotFound) {
+		return ErrSessionNotFound
+	}
+	return err
+}
+
+// Remove 删除会话，会话不存在时不返回错误
+func (s *Store) Remove(ctx context.Context, id string) error {
+	err := s.client.delete(ctx, s.prefix+id)
+	if errors.Is(err, errKeyNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Get 获取会话，会话不存在时返回 ErrSessionNotFound
+func (s *Store) Get(ctx context.Context, id string) (session.Session, error) {
+	b, err := s.client.get(ctx, s.prefix+id)
+	if errors.Is(err, errKeyNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := session.UnmarshalSession(s.codec, id, b)
+	if err != nil {
+		return nil, err
+	}
+	return &memcachedSession{id: id, store: s, data: data}, nil
+}
+
+// Ping 探测全部节点是否可用
+func (s *Store) Ping(ctx context.Context) error {
+	return s.client.ping(ctx)
+}
+
+// Close 关闭空闲连接，可以注册为服务器的关闭钩子
+func (s *Store) Close() error {
+	return s.client.close()
+}
+
+// save 序列化会话数据并写入 memcached
+func (s *Store) save(ctx context.Context, id string, data map[string]any) error {
+	b, err := session.MarshalSession(s.codec, id, data)
+	if err != nil {
+		return err
+	}
+	return s.client.set(ctx, s.prefix+id, b, s.expiration)
+}
+
+// memcachedSession memcached 会话实例
+// 读取使用获取会话时加载的数据，每次写入都会把全部数据写回 memcached
+// 需要修改多个键时可以使用 session.Object 或 SetMany 合并写入
+type memcachedSession struct {
+	id    string
+	store *Store
+	mu    sync.Mutex
+	data  map[string]any
+}
+
+// Get 获取会话中的数据
+func (m *memcachedSession) Get(_ context.Context, key string) (any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	val, ok := m.data[key]
+	if !ok {
+		return nil, errors.New("找不到这个 key")
+	}
+	return val, nil
+}
+
+// Set 设置会话中的数据并写回 memcached
+func (m *memcachedSession) Set(ctx context.Context, key string, value any) error {
+	return m.SetMany(ctx, map[string]any{key: value})
+}
+
+// SetMany 一次设置多个数据并写回 memcached，实现 session.BatchSetter 接口
+func (m *memcachedSession) SetMany(ctx context.Context, values map[string]any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data := maps.Clone(m.data)
+	maps.Copy(data, values)
+	if err := m.store.save(ctx, m.id, data); err != nil {
+		return err
+	}
+	m.data = data
+	return nil
+}
+
+// All 返回会话中全部数据的副本，实现 session.Exporter 接口
+func (m *memcachedSession) All(_ context.Context) (map[string]any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return maps.Clone(m.data), nil
+}
+
+// ID 获取会话ID
+func (m *memcachedSession) ID() string {
+	return m.id
+}
@@ -0,0 +1,195 @@
+package memcached
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/justinwongcn/ant/session"
+)
+
+// fakeServer 实现了二进制协议 Get、Set、Delete、Touch 与 Noop 的 memcached 服务器
+type fakeServer struct {
+	ln    net.Listener
+	mu    sync.Mutex
+	items map[string][]byte
+	ttls  map[string]uint32
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeServer{ln: ln, items: make(map[string][]byte), ttls: make(map[string]uint32)}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *fakeServer) keys() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	header := make([]byte, headerLen)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		keyLen := int(binary.BigEndian.Uint16(header[2:]))
+		extrasLen := int(header[4])
+		body := make([]byte, binary.BigEndian.Uint32(header[8:]))
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return
+		}
+		extras := body[:extrasLen]
+		key := string(body[extrasLen : extrasLen+keyLen])
+		value := body[extrasLen+keyLen:]
+
+		status, respExtras, respValue := s.handle(header[1], key, extras, value)
+		resp := make([]byte, headerLen+len(respExtras)+len(respValue))
+		resp[0] = magicResponse
+		resp[1] = header[1]
+		resp[4] = byte(len(respExtras))
+		binary.BigEndian.PutUint16(resp[6:], status)
+		binary.BigEndian.PutUint32(resp[8:], uint32(len(respExtras)+len(respValue)))
+		copy(resp[headerLen:], respExtras)
+		copy(resp[headerLen+len(respExtras):], respValue)
+		if _, err := conn.Write(resp); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeServer) handle(opcode byte, key string, extras, value []byte) (uint16, []byte, []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch opcode {
+	case opGet:
+		v, ok := s.items[key]
+		if !ok {
+			return statusKeyNotFound, nil, []byte("Not found")
+		}
+		return statusOK, make([]byte, 4), v
+	case opSet:
+		s.items[key] = append([]byte(nil), value...)
+		s.ttls[key] = binary.BigEndian.Uint32(extras[4:])
+	case opDelete, opTouch:
+		if _, ok := s.items[key]; !ok {
+			return statusKeyNotFound, nil, []byte("Not found")
+		}
+		if opcode == opDelete {
+			delete(s.items, key)
+		} else {
+			s.ttls[key] = binary.BigEndian.Uint32(extras)
+		}
+	}
+	return statusOK, nil, nil
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newFakeServer(t)
+	store, err := NewStore([]string{srv.addr()}, 30*time.Minute)
+	require.NoError(t, err)
+	defer store.Close()
+
+	sess, err := store.Generate(ctx, "sess-1")
+	require.NoError(t, err)
+	require.NoError(t, sess.Set(ctx, "user", 42))
+	require.NoError(t, sess.(session.BatchSetter).SetMany(ctx, map[string]any{"name": "tom", "roles": []string{"admin"}}))
+
+	got, err := store.Get(ctx, "sess-1")
+	require.NoError(t, err)
+	all, err := got.(session.Exporter).All(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"user": 42, "name": "tom", "roles": []string{"admin"}}, all)
+	assert.Equal(t, uint32(1800), srv.ttls["session:sess-1"])
+
+	require.NoError(t, store.Refresh(ctx, "sess-1"))
+	require.NoError(t, store.Remove(ctx, "sess-1"))
+	_, err = store.Get(ctx, "sess-1")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	assert.ErrorIs(t, store.Refresh(ctx, "sess-1"), ErrSessionNotFound)
+	assert.NoError(t, store.Remove(ctx, "sess-1"))
+	assert.NoError(t, store.Ping(ctx))
+}
+
+func TestConsistentHashing(t *testing.T) {
+	ctx := context.Background()
+	servers := []*fakeServer{newFakeServer(t), newFakeServer(t), newFakeServer(t)}
+	addrs := make([]string, len(servers))
+	for i, s := range servers {
+		addrs[i] = s.addr()
+	}
+	store, err := NewStore(addrs, time.Minute, WithPrefix("s:"), WithCodec(session.JSONCodec{}))
+	require.NoError(t, err)
+
+	for i := 0; i < 300; i++ {
+		_, err = store.Generate(ctx, fmt.Sprintf("id-%d", i))
+		require.NoError(t, err)
+	}
+	for i, s := range servers {
+		assert.Greater(t, s.keys(), 30, "节点 %d 分配到的会话过少", i)
+	}
+
+	// 增加节点后大部分会话仍由原来的节点负责
+	grown := newClient(append(addrs, "127.0.0.1:1"), time.Second, 1)
+	moved := 0
+	for i := 0; i < 300; i++ {
+		key := fmt.Sprintf("s:id-%d", i)
+		if grown.pick(key).addr != store.client.pick(key).addr {
+			moved++
+		}
+	}
+	assert.Less(t, moved, 150, "增加一个节点时不应有超过一半的会话迁移")
+}
+
+func TestExpiration(t *testing.T) {
+	assert.Equal(t, uint32(0), expiration(0))
+	assert.Equal(t, uint32(1), expiration(100*time.Millisecond))
+	assert.Equal(t, uint32(3600), expiration(time.Hour))
+	// 超过 30 天时使用 Unix 时间戳
+	abs := expiration(60 * 24 * time.Hour)
+	assert.InDelta(t, time.Now().Add(60*24*time.Hour).Unix(), int64(abs), 2)
+}
+
+func TestStoreUnavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	store, err := NewStore([]string{addr}, time.Minute, WithTimeout(100*time.Millisecond))
+	require.NoError(t, err)
+	assert.Error(t, store.Ping(context.Background()))
+	_, err = store.Get(context.Background(), "id")
+	assert.False(t, errors.Is(err, ErrSessionNotFound))
+
+	_, err = NewStore(nil, time.Minute)
+	assert.Error(t, err)
+}
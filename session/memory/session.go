@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"errors"
+	"maps"
 	"sync"
 	"time"
 
@@ -44,12 +45,15 @@ type memorySession struct {
 }
 
 // Get 获取会话中的数据
-// ctx: 上下文（当前未使用）
+// ctx: 上下文，已取消或超时时返回 ctx.Err()
 // key: 数据的键
 // 返回值:
 // - 获取到的数据
 // - 如果键不存在则返回错误
-func (m *memorySession) Get(_ context.Context, key string) (any, error) {
+func (m *memorySession) Get(ctx context.Context, key string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	val, ok := m.data[key]
@@ -61,17 +65,45 @@ func (m *memorySession) Get(_ context.Context, key string) (any, error) {
 }
 
 // Set 设置会话中的数据
-// ctx: 上下文（当前未使用）
+// ctx: 上下文，已取消或超时时返回 ctx.Err()
 // key: 数据的键
 // value: 要存储的数据
 // 返回值: 设置过程中的错误
-func (m *memorySession) Set(_ context.Context, key string, value any) error {
+func (m *memorySession) Set(ctx context.Context, key string, value any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.data[key] = value
 	return nil
 }
 
+// SetMany 一次设置多个数据，实现 session.BatchSetter 接口
+// ctx: 上下文，已取消或超时时返回 ctx.Err()
+// values: 要存储的数据
+// 返回值: 设置过程中的错误
+func (m *memorySession) SetMany(ctx context.Context, values map[string]any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	maps.Copy(m.data, values)
+	return nil
+}
+
+// All 返回会话中全部数据的副本，实现 session.Exporter 接口
+// ctx: 上下文，已取消或超时时返回 ctx.Err()
+func (m *memorySession) All(ctx context.Context) (map[string]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return maps.Clone(m.data), nil
+}
+
 // ID 获取会话ID
 // 返回值: 会话的唯一标识符
 func (m *memorySession) ID() string {
@@ -79,12 +111,15 @@ func (m *memorySession) ID() string {
 }
 
 // Generate 生成一个新的会话
-// ctx: 上下文（当前未使用）
+// ctx: 上下文，已取消或超时时返回 ctx.Err()
 // id: 会话ID
 // 返回值:
 // - 生成的会话实例
 // - 可能发生的错误
-func (m *Store) Generate(_ context.Context, id string) (session.Session, error) {
+func (m *Store) Generate(ctx context.Context, id string) (session.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	sess := &memorySession{
 		id:         id,
 		data:       make(map[string]any),
@@ -112,21 +147,27 @@ func (m *Store) Refresh(ctx context.Context, id string) error {
 }
 
 // Remove 删除会话
-// ctx: 上下文（当前未使用）
+// ctx: 上下文，已取消或超时时返回 ctx.Err()
 // id: 要删除的会话ID
 // 返回值: 删除过程中的错误
-func (m *Store) Remove(_ context.Context, id string) error {
+func (m *Store) Remove(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	m.c.Delete(id)
 	return nil
 }
 
 // Get 获取会话
-// ctx: 上下文（当前未使用）
+// ctx: 上下文，已取消或超时时返回 ctx.Err()
 // id: 会话ID
 // 返回值:
 // - 获取到的会话实例
 // - 如果会话不存在则返回错误
-func (m *Store) Get(_ context.Context, id string) (session.Session, error) {
+func (m *Store) Get(ctx context.Context, id string) (session.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	sess, ok := m.c.Get(id)
 	if !ok {
 		return nil, errors.New("session not found")
@@ -134,3 +175,44 @@ func (m *Store) Get(_ context.Context, id string) (session.Session, error) {
 
 	return sess.(*memorySession), nil
 }
+
+// TTL 返回会话的剩余有效期，实现 session.TTLReporter 接口
+// ctx: 上下文，已取消或超时时返回 ctx.Err()
+// id: 会话ID
+// 返回值:
+// - 剩余有效期，会话永不过期时返回 -1
+// - 如果会话不存在则返回错误
+func (m *Store) TTL(ctx context.Context, id string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	_, exp, ok := m.c.GetWithExpiration(id)
+	if !ok {
+		return 0, errors.New("session not found")
+	}
+	if exp.IsZero() {
+		return -1, nil
+	}
+
+	return time.Until(exp), nil
+}
+
+// List 返回全部未过期会话的ID，实现 session.Lister 接口
+// ctx: 上下文，已取消或超时时返回 ctx.Err()
+func (m *Store) List(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	items := m.c.Items()
+	ids := make([]string, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Ping 探测存储是否可用
+// 内存存储始终可用，仅在上下文已取消时返回错误
+func (m *Store) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
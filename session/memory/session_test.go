@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/justinwongcn/ant/session"
 )
 
 func TestStoreNewStore(t *testing.T) {
@@ -119,6 +121,18 @@ func TestMemorySessionSet(t *testing.T) {
 	assert.Equal(t, "new-value", sess.data["test-key"])
 }
 
+func TestMemorySessionSetMany(t *testing.T) {
+	sess := &memorySession{
+		id:   "test-id",
+		data: map[string]any{"keep": 1},
+	}
+	var _ session.BatchSetter = sess
+
+	err := sess.SetMany(context.Background(), map[string]any{"a": "x", "b": "y"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"keep": 1, "a": "x", "b": "y"}, sess.data)
+}
+
 func TestMemorySessionID(t *testing.T) {
 	sess := &memorySession{
 		id:   "test-id",
@@ -181,3 +195,29 @@ func TestConcurrentAccess(t *testing.T) {
 		assert.Equal(t, i, val)
 	}
 }
+
+func TestStoreCanceledContext(t *testing.T) {
+	store := NewStore(30 * time.Minute)
+	sess, err := store.Generate(context.Background(), "test-id")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = store.Generate(ctx, "other-id")
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = store.Get(ctx, "test-id")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, store.Refresh(ctx, "test-id"), context.Canceled)
+	assert.ErrorIs(t, store.Remove(ctx, "test-id"), context.Canceled)
+	_, err = store.List(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, sess.Set(ctx, "key", "value"), context.Canceled)
+	_, err = sess.Get(ctx, "key")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// 取消的操作不应修改数据
+	_, err = store.Get(context.Background(), "other-id")
+	assert.Error(t, err)
+	_, err = store.Get(context.Background(), "test-id")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,149 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrNotListable 源存储不支持遍历会话
+var ErrNotListable = errors.New("session: 源存储没有实现 Lister 接口，无法遍历会话")
+
+// Lister 可以遍历全部会话的存储
+type Lister interface {
+	// List 返回全部未过期会话的ID
+	List(ctx context.Context) ([]string, error)
+}
+
+// Exporter 可以导出全部数据的会话
+type Exporter interface {
+	// All 返回会话中全部数据的副本
+	All(ctx context.Context) (map[string]any, error)
+}
+
+// MigrateOptions 会话迁移的配置
+type MigrateOptions struct {
+	// Rate 每秒迁移的会话数量上限，用于避免迁移压垮目标存储，为0时不限制
+	Rate int
+	// Verify 写入后从目标存储读回会话并与源数据比较
+	Verify bool
+}
+
+// MigrateResult 会话迁移的结果
+type MigrateResult struct {
+	// Copied 成功迁移的会话数量
+	Copied int
+	// Skipped 遍历之后、迁移之前已经过期或被删除的会话数量
+	Skipped int
+	// Mismatched 校验失败的会话ID
+	Mismatched []string
+}
+
+// Migrate 将源存储中的全部会话复制到目标存储，用于切换存储时不让用户重新登录
+// 例如从内存存储迁移到 Redis，或在 Redis 集群之间迁移
+// ctx: 上下文，取消后停止迁移并返回已迁移的结果
+// src: 源存储，必须实现 Lister 接口，会话必须实现 Exporter 接口
+// dst: 目标存储，会话使用目标存储的过期时间
+// 返回值: 迁移结果，以及遇到的第一个错误；校验失败不视为错误，记录在 Mismatched 中
+// 注意：迁移期间仍在写入的会话可能不一致，建议在切换流量后再执行一次迁移
+func Migrate(ctx context.Context, src, dst Store, opts MigrateOptions) (MigrateResult, error) {
+	var res MigrateResult
+	lister, ok := src.(Lister)
+	if !ok {
+		return res, ErrNotListable
+	}
+	ids, err := lister.List(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	var tick <-chan time.Time
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(opts.Rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for i, id := range ids {
+		if tick != nil && i > 0 {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				return res, ctx.Err()
+			}
+		}
+		if err = ctx.Err(); err != nil {
+			return res, err
+		}
+
+		data, err := exportSession(ctx, src, id)
+		if err != nil {
+			return res, fmt.Errorf("session: 读取会话 %s 失败: %w", id, err)
+		}
+		if data == nil {
+			res.Skipped++
+			continue
+		}
+		if err = importSession(ctx, dst, id, data); err != nil {
+			return res, fmt.Errorf("session: 写入会话 %s 失败: %w", id, err)
+		}
+		res.Copied++
+
+		if opts.Verify && !verifySession(ctx, dst, id, data) {
+			res.Mismatched = append(res.Mismatched, id)
+		}
+	}
+	return res, nil
+}
+
+// exportSession 导出会话的全部数据，会话已经不存在时返回nil
+func exportSession(ctx context.Context, store Store, id string) (map[string]any, error) {
+	sess, err := store.Get(ctx, id)
+	if err != nil {
+		// Store 接口没有区分不存在与其他错误，遍历后消失的会话视为已过期
+		return nil, nil
+	}
+	exporter, ok := sess.(Exporter)
+	if !ok {
+		return nil, fmt.Errorf("会话 %T 没有实现 Exporter 接口", sess)
+	}
+	return exporter.All(ctx)
+}
+
+// importSession 在目标存储中创建会话并写入数据
+func importSession(ctx context.Context, store Store, id string, data map[string]any) error {
+	sess, err := store.Generate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if bs, ok := sess.(BatchSetter); ok {
+		return bs.SetMany(ctx, data)
+	}
+	for key, val := range data {
+		if err = sess.Set(ctx, key, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifySession 从目标存储读回会话并与源数据比较
+func verifySession(ctx context.Context, store Store, id string, want map[string]any) bool {
+	sess, err := store.Get(ctx, id)
+	if err != nil {
+		return false
+	}
+	if exporter, ok := sess.(Exporter); ok {
+		got, err := exporter.All(ctx)
+		return err == nil && reflect.DeepEqual(got, want)
+	}
+	for key, val := range want {
+		got, err := sess.Get(ctx, key)
+		if err != nil || !reflect.DeepEqual(got, val) {
+			return false
+		}
+	}
+	return true
+}
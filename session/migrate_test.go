@@ -0,0 +1,72 @@
+// 迁移测试使用内存存储，放在外部测试包中以避免循环引用
+package session_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/justinwongcn/ant/session"
+	"github.com/justinwongcn/ant/session/memory"
+)
+
+// plainStore 只实现了 session.Store 的存储，会话也不支持批量写入与导出
+type plainStore struct {
+	session.Store
+}
+
+func newSourceStore(t *testing.T, n int) *memory.Store {
+	t.Helper()
+	src := memory.NewStore(time.Minute)
+	for i := 0; i < n; i++ {
+		sess, err := src.Generate(context.Background(), string(rune('a'+i)))
+		require.NoError(t, err)
+		require.NoError(t, sess.Set(context.Background(), "user", i))
+		require.NoError(t, sess.Set(context.Background(), "cart", []string{"apple"}))
+	}
+	return src
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	src := newSourceStore(t, 3)
+	dst := memory.NewStore(time.Minute)
+
+	res, err := session.Migrate(ctx, src, plainStore{dst}, session.MigrateOptions{Verify: true})
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Copied)
+	assert.Empty(t, res.Mismatched)
+
+	sess, err := dst.Get(ctx, "b")
+	require.NoError(t, err)
+	val, err := sess.Get(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	val, err = sess.Get(ctx, "cart")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"apple"}, val)
+}
+
+func TestMigrateRateLimit(t *testing.T) {
+	src := newSourceStore(t, 3)
+	start := time.Now()
+	res, err := session.Migrate(context.Background(), src, memory.NewStore(time.Minute), session.MigrateOptions{Rate: 20})
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Copied)
+	// 3 个会话之间等待 2 个间隔
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = session.Migrate(ctx, src, memory.NewStore(time.Minute), session.MigrateOptions{Rate: 1})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMigrateNotListable(t *testing.T) {
+	_, err := session.Migrate(context.Background(), plainStore{memory.NewStore(time.Minute)}, memory.NewStore(time.Minute), session.MigrateOptions{})
+	assert.True(t, errors.Is(err, session.ErrNotListable))
+}
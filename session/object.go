@@ -0,0 +1,121 @@
+package session
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// BatchSetter 支持一次写入多个键的会话
+// 远程存储（例如 Redis）的会话实现该接口后，Object.Save 只需要一次往返
+type BatchSetter interface {
+	// SetMany 写入多个键值
+	SetMany(ctx context.Context, values map[string]any) error
+}
+
+// 确保 Object 实现了 Session 接口
+var _ Session = (*Object)(nil)
+
+// Object 带变更跟踪的会话对象，例如购物车
+// 读取的值会被缓存，写入只记录在本地，调用 Save 时只把修改过的键写回底层会话，
+// 避免每次修改都写回整个会话，减少远程存储的写放大
+type Object struct {
+	sess Session
+
+	mu     sync.Mutex
+	values map[string]any      // 已读取或写入的值
+	dirty  map[string]struct{} // 修改过、尚未保存的键
+}
+
+// NewObject 创建带变更跟踪的会话对象
+// sess: 底层会话
+func NewObject(sess Session) *Object {
+	return &Object{
+		sess:   sess,
+		values: make(map[string]any),
+		dirty:  make(map[string]struct{}),
+	}
+}
+
+// Get 获取会话中的数据，同一个键只从底层会话读取一次
+func (o *Object) Get(ctx context.Context, key string) (any, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if val, ok := o.values[key]; ok {
+		return val, nil
+	}
+	val, err := o.sess.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	o.values[key] = val
+	return val, nil
+}
+
+// Set 设置会话中的数据并标记为已修改，在调用 Save 之前不会写入底层会话
+// 注意：原地修改读取到的切片或 map 后，需要调用 Set 才会被写回
+func (o *Object) Set(_ context.Context, key string, value any) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.values[key] = value
+	o.dirty[key] = struct{}{}
+	return nil
+}
+
+// ID 获取会话ID
+func (o *Object) ID() string {
+	return o.sess.ID()
+}
+
+// Dirty 返回修改过、尚未保存的键，按字母顺序排列
+func (o *Object) Dirty() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	keys := make([]string, 0, len(o.dirty))
+	for key := range o.dirty {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Save 把修改过的键写回底层会话
+// 底层会话实现了 BatchSetter 时一次写入全部修改，否则逐个调用 Set
+// 写入失败时保留未写入的修改，可以重试
+func (o *Object) Save(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.dirty) == 0 {
+		return nil
+	}
+
+	if bs, ok := o.sess.(BatchSetter); ok {
+		values := make(map[string]any, len(o.dirty))
+		for key := range o.dirty {
+			values[key] = o.values[key]
+		}
+		if err := bs.SetMany(ctx, values); err != nil {
+			return err
+		}
+		clear(o.dirty)
+		return nil
+	}
+
+	for key := range o.dirty {
+		if err := o.sess.Set(ctx, key, o.values[key]); err != nil {
+			return err
+		}
+		delete(o.dirty, key)
+	}
+	return nil
+}
+
+// Discard 放弃尚未保存的修改，之后的读取会重新从底层会话加载
+func (o *Object) Discard() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for key := range o.dirty {
+		delete(o.values, key)
+	}
+	clear(o.dirty)
+}
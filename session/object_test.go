@@ -0,0 +1,129 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// countingSession 记录读写次数的会话
+type countingSession struct {
+	mockSession
+	gets, sets, batches int
+	setErr              error
+}
+
+func (c *countingSession) Get(ctx context.Context, key string) (any, error) {
+	c.gets++
+	return c.mockSession.Get(ctx, key)
+}
+
+func (c *countingSession) Set(ctx context.Context, key string, value any) error {
+	if c.setErr != nil {
+		return c.setErr
+	}
+	c.sets++
+	return c.mockSession.Set(ctx, key, value)
+}
+
+// batchSession 支持批量写入的会话
+type batchSession struct {
+	countingSession
+}
+
+func (b *batchSession) SetMany(ctx context.Context, values map[string]any) error {
+	b.batches++
+	for k, v := range values {
+		b.data[k] = v
+	}
+	return nil
+}
+
+func newCountingSession() *countingSession {
+	return &countingSession{mockSession: mockSession{id: "sess-1", data: map[string]any{
+		"cart":    []string{"apple"},
+		"profile": "large payload",
+	}}}
+}
+
+func TestObjectTracksChanges(t *testing.T) {
+	ctx := context.Background()
+	sess := newCountingSession()
+	obj := NewObject(sess)
+
+	// 同一个键只读取一次
+	for i := 0; i < 3; i++ {
+		if _, err := obj.Get(ctx, "profile"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sess.gets != 1 {
+		t.Errorf("期望只读取一次，实际 %d 次", sess.gets)
+	}
+
+	val, _ := obj.Get(ctx, "cart")
+	cart := append(val.([]string), "pear")
+	_ = obj.Set(ctx, "cart", cart)
+	_ = obj.Set(ctx, "coupon", "SAVE10")
+	if got := obj.Dirty(); !reflect.DeepEqual(got, []string{"cart", "coupon"}) {
+		t.Errorf("期望修改过的键为 cart、coupon，实际 %v", got)
+	}
+	if sess.sets != 0 {
+		t.Error("Save 之前不应写入底层会话")
+	}
+
+	if err := obj.Save(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if sess.sets != 2 {
+		t.Errorf("期望只写入 2 个修改过的键，实际 %d 次", sess.sets)
+	}
+	if !reflect.DeepEqual(sess.data["cart"], []string{"apple", "pear"}) || sess.data["coupon"] != "SAVE10" {
+		t.Errorf("底层会话数据错误: %v", sess.data)
+	}
+	if len(obj.Dirty()) != 0 {
+		t.Error("保存后不应有修改过的键")
+	}
+
+	// 没有修改时不写入
+	if err := obj.Save(ctx); err != nil || sess.sets != 2 {
+		t.Errorf("没有修改时不应写入，实际 %d 次 %v", sess.sets, err)
+	}
+	if obj.ID() != "sess-1" {
+		t.Errorf("会话ID错误: %s", obj.ID())
+	}
+}
+
+func TestObjectBatchAndErrors(t *testing.T) {
+	ctx := context.Background()
+	batch := &batchSession{countingSession: *newCountingSession()}
+	obj := NewObject(batch)
+	_ = obj.Set(ctx, "a", 1)
+	_ = obj.Set(ctx, "b", 2)
+	if err := obj.Save(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if batch.batches != 1 || batch.sets != 0 {
+		t.Errorf("期望一次批量写入，实际批量 %d 次，逐个 %d 次", batch.batches, batch.sets)
+	}
+
+	// 写入失败时保留修改
+	sess := newCountingSession()
+	sess.setErr = errors.New("存储不可用")
+	obj = NewObject(sess)
+	_ = obj.Set(ctx, "cart", []string{})
+	if err := obj.Save(ctx); !errors.Is(err, sess.setErr) {
+		t.Fatalf("期望写入错误，实际 %v", err)
+	}
+	if len(obj.Dirty()) != 1 {
+		t.Error("写入失败时应保留修改")
+	}
+
+	// 放弃修改后重新从底层会话读取
+	obj.Discard()
+	val, _ := obj.Get(ctx, "cart")
+	if !reflect.DeepEqual(val, []string{"apple"}) || len(obj.Dirty()) != 0 {
+		t.Errorf("放弃修改后期望读取到原始数据，实际 %v", val)
+	}
+}
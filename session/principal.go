@@ -0,0 +1,136 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+	"unicode"
+
+	"github.com/justinwongcn/ant"
+)
+
+var (
+	// ErrInvalidUserID 用户ID为空、过长或包含控制字符
+	ErrInvalidUserID = errors.New("session: 用户ID不合法")
+	// ErrNotLoggedIn 会话中没有登录的用户
+	ErrNotLoggedIn = errors.New("session: 用户未登录")
+)
+
+// principalKey 登录用户在会话中的键名
+const principalKey = "_principal"
+
+// UserID 用户ID值对象，通过 ParseUserID 创建以保证合法
+type UserID string
+
+// ParseUserID 解析用户ID，不能为空、不超过 128 字节且不能包含控制字符
+func ParseUserID(s string) (UserID, error) {
+	if s == "" || len(s) > 128 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidUserID, s)
+	}
+	for _, r := range s {
+		if unicode.IsControl(r) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidUserID, s)
+		}
+	}
+	return UserID(s), nil
+}
+
+// String 实现 fmt.Stringer 接口
+func (id UserID) String() string {
+	return string(id)
+}
+
+// Principal 已认证的用户
+type Principal struct {
+	// UserID 用户ID
+	UserID UserID `json:"user_id"`
+	// Tenant 用户所属的租户，可以为空
+	Tenant string `json:"tenant,omitempty"`
+	// Roles 用户的角色
+	Roles []string `json:"roles,omitempty"`
+	// AuthTime 认证的时间，为零值时由 Login 设置为当前时间
+	AuthTime time.Time `json:"auth_time"`
+}
+
+// Validate 校验用户ID是否合法
+func (p Principal) Validate() error {
+	_, err := ParseUserID(string(p.UserID))
+	return err
+}
+
+// HasRole 判断用户是否拥有指定角色
+func (p Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// Login 登录用户
+// 为防止会话固定攻击，总是删除请求携带的旧会话并生成新的会话ID，再将用户保存到新会话中
+// 用户以 JSON 字符串的形式保存，因此可以使用任意 Codec
+func (m *Manager) Login(ctx ant.Context, p Principal) (Session, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if p.AuthTime.IsZero() {
+		p.AuthTime = time.Now()
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if old, err := m.GetSession(ctx); err == nil {
+		if err = m.Store.Remove(ctx.Req.Context(), old.ID()); err != nil {
+			return nil, err
+		}
+	}
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	sess, err := m.InitSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = sess.Set(ctx.Req.Context(), principalKey, string(data)); err != nil {
+		return nil, err
+	}
+	if ctx.UserValues != nil {
+		ctx.UserValues[m.SessCtxKey] = sess
+	}
+	return sess, nil
+}
+
+// CurrentUser 返回当前登录的用户
+// 请求没有会话或会话中没有用户时返回 ErrNotLoggedIn
+func (m *Manager) CurrentUser(ctx ant.Context) (Principal, error) {
+	sess, err := m.GetSession(ctx)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrNotLoggedIn, err)
+	}
+	val, err := sess.Get(ctx.Req.Context(), principalKey)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrNotLoggedIn, err)
+	}
+	s, ok := val.(string)
+	if !ok {
+		return Principal{}, fmt.Errorf("session: 会话中的用户类型错误 %T", val)
+	}
+	var p Principal
+	if err = json.Unmarshal([]byte(s), &p); err != nil {
+		return Principal{}, err
+	}
+	return p, nil
+}
+
+// newSessionID 生成 128 位的随机会话ID
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,75 @@
+package session_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/justinwongcn/ant"
+	"github.com/justinwongcn/ant/session"
+	"github.com/justinwongcn/ant/session/cookie"
+	"github.com/justinwongcn/ant/session/memory"
+)
+
+func TestParseUserID(t *testing.T) {
+	id, err := session.ParseUserID("u-1")
+	require.NoError(t, err)
+	assert.Equal(t, "u-1", id.String())
+	for _, s := range []string{"", strings.Repeat("a", 129), "a\nb"} {
+		_, err = session.ParseUserID(s)
+		assert.ErrorIs(t, err, session.ErrInvalidUserID, s)
+	}
+}
+
+func TestManagerLogin(t *testing.T) {
+	store := memory.NewStore(time.Minute)
+	_, err := store.Generate(context.Background(), "anonymous")
+	require.NoError(t, err)
+	m := &session.Manager{Store: store, Propagator: cookie.NewPropagator(), SessCtxKey: "session"}
+
+	newCtx := func(cookieValue string) (ant.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if cookieValue != "" {
+			req.AddCookie(&http.Cookie{Name: "sessid", Value: cookieValue})
+		}
+		w := httptest.NewRecorder()
+		return ant.Context{Req: req, Resp: w, UserValues: map[string]any{}}, w
+	}
+
+	ctx, _ := newCtx("")
+	_, err = m.CurrentUser(ctx)
+	assert.ErrorIs(t, err, session.ErrNotLoggedIn)
+
+	_, err = m.Login(ctx, session.Principal{})
+	assert.ErrorIs(t, err, session.ErrInvalidUserID)
+
+	// 登录时替换请求携带的旧会话
+	ctx, w := newCtx("anonymous")
+	sess, err := m.Login(ctx, session.Principal{UserID: "u-1", Tenant: "acme", Roles: []string{"admin"}})
+	require.NoError(t, err)
+	assert.NotEqual(t, "anonymous", sess.ID())
+	_, err = store.Get(context.Background(), "anonymous")
+	assert.Error(t, err, "旧会话应被删除")
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, sess.ID(), cookies[0].Value)
+
+	ctx, _ = newCtx(sess.ID())
+	p, err := m.CurrentUser(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, session.UserID("u-1"), p.UserID)
+	assert.Equal(t, "acme", p.Tenant)
+	assert.True(t, p.HasRole("admin"))
+	assert.False(t, p.AuthTime.IsZero())
+
+	// 未登录的会话
+	ctx, _ = newCtx("missing")
+	_, err = m.CurrentUser(ctx)
+	assert.ErrorIs(t, err, session.ErrNotLoggedIn)
+}
@@ -0,0 +1,215 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/justinwongcn/ant/session"
+)
+
+// ErrSessionNotFound 会话不存在或已过期
+var ErrSessionNotFound = errors.New("redis: session not found")
+
+// 确保 Store 实现了 session.Store、session.TTLReporter 与 session.Lister 接口
+var (
+	_ session.Store       = (*Store)(nil)
+	_ session.TTLReporter = (*Store)(nil)
+	_ session.Lister      = (*Store)(nil)
+)
+
+// Store 基于 Redis 的会话存储
+// 会话数据通过 session.Codec 序列化后保存在一个键中，过期时间由 Redis 维护，
+// 进程重启后会话仍然有效，多个实例可以共享同一个 Redis
+type Store struct {
+	client     redis.UniversalClient
+	expiration time.Duration
+	prefix     string
+	codec      session.Codec
+}
+
+// Option 定义 Store 的配置选项函数类型
+type Option func(s *Store)
+
+// WithPrefix 设置会话键的前缀，默认为 "session:"
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// WithCodec 设置会话数据的编解码器，默认为 session.GobCodec
+// 需要加密会话数据时可以使用 session.NewEncryptedCodec
+func WithCodec(codec session.Codec) Option {
+	return func(s *Store) {
+		s.codec = codec
+	}
+}
+
+// NewStore 创建基于 Redis 的会话存储
+// client: Redis 客户端，可以是 *redis.Client、*redis.ClusterClient 或 *redis.Ring，由调用方负责关闭
+// expiration: 会话的过期时间，每次写入与 Refresh 时重新计算
+// opts: 可选的配置选项
+//
+//	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:6379"})
+//	store := sessredis.NewStore(client, 30*time.Minute, sessredis.WithPrefix("app:session:"))
+func NewStore(client redis.UniversalClient, expiration time.Duration, opts ...Option) *Store {
+	s := &Store{
+		client:     client,
+		expiration: expiration,
+		prefix:     "session:",
+		codec:      session.GobCodec{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Generate 生成一个新的会话并写入 Redis
+func (s *Store) Generate(ctx context.Context, id string) (session.Session, error) {
+	sess := &redisSession{id: id, store: s, data: make(map[string]any)}
+	if err := s.save(ctx, id, sess.data); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+// Refresh 刷新会话的过期时间
+func (s *Store) Refresh(ctx context.Context, id string) error {
+	ok, err := s.client.Expire(ctx, s.prefix+id, s.expiration).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// Remove 删除会话，会话不存在时不返回错误
+func (s *Store) Remove(ctx context.Context, id string) error {
+	return s.client.Del(ctx, s.prefix+id).Err()
+}
+
+// Get 获取会话，会话不存在时返回 ErrSessionNotFound
+func (s *Store) Get(ctx context.Context, id string) (session.Session, error) {
+	b, err := s.client.Get(ctx, s.prefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := session.UnmarshalSession(s.codec, id, b)
+	if err != nil {
+		return nil, err
+	}
+	return &redisSession{id: id, store: s, data: data}, nil
+}
+
+// TTL 返回会话的剩余有效期，实现 session.TTLReporter 接口
+// 会话永不过期时返回 -1，会话不存在时返回 ErrSessionNotFound
+func (s *Store) TTL(ctx context.Context, id string) (time.Duration, error) {
+	ttl, err := s.client.PTTL(ctx, s.prefix+id).Result()
+	if err != nil {
+		return 0, err
+	}
+	// Redis 对不存在的键返回 -2，对没有过期时间的键返回 -1，客户端原样保留为纳秒
+	switch {
+	case ttl == -2:
+		return 0, ErrSessionNotFound
+	case ttl < 0:
+		return -1, nil
+	}
+	return ttl, nil
+}
+
+// List 返回全部未过期会话的ID，实现 session.Lister 接口
+// 通过 SCAN 遍历前缀匹配的键，不会阻塞 Redis；集群模式下需要使用 ClusterClient.ForEachMaster 自行遍历
+func (s *Store) List(ctx context.Context) ([]string, error) {
+	var ids []string
+	iter := s.client.Scan(ctx, 0, escapePattern(s.prefix)+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		ids = append(ids, strings.TrimPrefix(iter.Val(), s.prefix))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Ping 探测 Redis 是否可用
+func (s *Store) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+// save 序列化会话数据并写入 Redis，同时重置过期时间
+func (s *Store) save(ctx context.Context, id string, data map[string]any) error {
+	b, err := session.MarshalSession(s.codec, id, data)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+id, b, s.expiration).Err()
+}
+
+// escapePattern 转义 SCAN 匹配模式中的特殊字符
+func escapePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(s)
+}
+
+// redisSession Redis 会话实例
+// 读取使用获取会话时加载的数据，每次写入都会把全部数据写回 Redis
+// 需要修改多个键时可以使用 session.Object 或 SetMany 合并写入
+type redisSession struct {
+	id    string
+	store *Store
+	mu    sync.Mutex
+	data  map[string]any
+}
+
+// Get 获取会话中的数据
+func (r *redisSession) Get(_ context.Context, key string) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	val, ok := r.data[key]
+	if !ok {
+		return nil, errors.New("找不到这个 key")
+	}
+	return val, nil
+}
+
+// Set 设置会话中的数据并写回 Redis
+func (r *redisSession) Set(ctx context.Context, key string, value any) error {
+	return r.SetMany(ctx, map[string]any{key: value})
+}
+
+// SetMany 一次设置多个数据并写回 Redis，实现 session.BatchSetter 接口
+func (r *redisSession) SetMany(ctx context.Context, values map[string]any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data := maps.Clone(r.data)
+	maps.Copy(data, values)
+	if err := r.store.save(ctx, r.id, data); err != nil {
+		return err
+	}
+	r.data = data
+	return nil
+}
+
+// All 返回会话中全部数据的副本，实现 session.Exporter 接口
+func (r *redisSession) All(_ context.Context) (map[string]any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return maps.Clone(r.data), nil
+}
+
+// ID 获取会话ID
+func (r *redisSession) ID() string {
+	return r.id
+}
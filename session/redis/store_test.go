@@ -0,0 +1,124 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/justinwongcn/ant/session"
+)
+
+func newTestStore(t *testing.T, opts ...Option) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewStore(client, 30*time.Minute, opts...), mr
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestStore(t)
+
+	sess, err := store.Generate(ctx, "sess-1")
+	require.NoError(t, err)
+	require.NoError(t, sess.Set(ctx, "user", 42))
+	require.NoError(t, sess.(session.BatchSetter).SetMany(ctx, map[string]any{"name": "tom", "roles": []string{"admin"}}))
+	assert.Equal(t, 30*time.Minute, mr.TTL("session:sess-1"))
+
+	got, err := store.Get(ctx, "sess-1")
+	require.NoError(t, err)
+	all, err := got.(session.Exporter).All(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"user": 42, "name": "tom", "roles": []string{"admin"}}, all)
+
+	// Refresh 重置过期时间
+	mr.FastForward(20 * time.Minute)
+	require.NoError(t, store.Refresh(ctx, "sess-1"))
+	ttl, err := store.TTL(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, ttl)
+
+	require.NoError(t, store.Remove(ctx, "sess-1"))
+	_, err = store.Get(ctx, "sess-1")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	assert.ErrorIs(t, store.Refresh(ctx, "sess-1"), ErrSessionNotFound)
+	_, err = store.TTL(ctx, "sess-1")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	assert.NoError(t, store.Remove(ctx, "sess-1"))
+	assert.NoError(t, store.Ping(ctx))
+}
+
+func TestStoreExpiration(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestStore(t)
+	_, err := store.Generate(ctx, "sess-1")
+	require.NoError(t, err)
+
+	mr.FastForward(31 * time.Minute)
+	_, err = store.Get(ctx, "sess-1")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestStoreList(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestStore(t, WithPrefix("app*:"), WithCodec(session.JSONCodec{}))
+	for _, id := range []string{"a", "b", "c"} {
+		_, err := store.Generate(ctx, id)
+		require.NoError(t, err)
+	}
+	// 前缀中的通配符被转义，不会匹配其他键
+	require.NoError(t, mr.Set("apps:x", "other"))
+
+	ids, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, ids)
+
+	raw, err := mr.Get("app*:a")
+	require.NoError(t, err)
+	assert.Equal(t, "{}", raw)
+}
+
+func TestStoreEncryptedSessionBinding(t *testing.T) {
+	ctx := context.Background()
+	keyring, err := session.NewStaticKeyring("k1", make([]byte, 32))
+	require.NoError(t, err)
+	store, mr := newTestStore(t, WithCodec(session.NewEncryptedCodec(session.GobCodec{}, keyring)))
+
+	sess, err := store.Generate(ctx, "victim")
+	require.NoError(t, err)
+	require.NoError(t, sess.Set(ctx, "role", "admin"))
+	_, err = store.Generate(ctx, "attacker")
+	require.NoError(t, err)
+
+	// 将一个会话的存储项复制到另一个会话中无法解密
+	raw, err := mr.Get("session:victim")
+	require.NoError(t, err)
+	require.NoError(t, mr.Set("session:attacker", raw))
+	_, err = store.Get(ctx, "attacker")
+	assert.ErrorIs(t, err, session.ErrDecrypt)
+
+	got, err := store.Get(ctx, "victim")
+	require.NoError(t, err)
+	role, err := got.Get(ctx, "role")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", role)
+}
+
+func TestStoreUnavailable(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	defer client.Close()
+	store := NewStore(client, time.Minute)
+	mr.Close()
+
+	assert.Error(t, store.Ping(context.Background()))
+	_, err := store.Get(context.Background(), "id")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrSessionNotFound)
+}
@@ -66,3 +66,16 @@ type Propagator interface {
 	// 返回值: 移除过程中的错误
 	Remove(writer http.ResponseWriter) error
 }
+
+// RequestPropagator 注入时需要参考当前请求的会话传播器
+// 例如根据请求是否通过 TLS 到达决定 Cookie 的 Secure 属性
+// Manager 注入会话ID时，Propagator 实现了该接口则传入当前请求
+type RequestPropagator interface {
+	// InjectWith 将会话ID注入到HTTP响应中
+	// id: 会话ID
+	// writer: HTTP响应写入器
+	// req: 当前请求
+	// overrides: 本次注入的Cookie属性覆盖
+	// 返回值: 注入过程中的错误
+	InjectWith(id string, writer http.ResponseWriter, req *http.Request, overrides ...func(cookie *http.Cookie)) error
+}
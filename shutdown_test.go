@@ -0,0 +1,236 @@
+package ant
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+// freeAddr 返回一个当前可用的本地监听地址
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+// waitServing 等待服务器开始接受连接
+func waitServing(t *testing.T, addr string) {
+	t.Helper()
+	waitFor(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	})
+}
+
+// TestShutdownDrainsInFlight 测试关闭时等待进行中的请求并执行钩子
+func TestShutdownDrainsInFlight(t *testing.T) {
+	server := NewHTTPServer()
+	started := make(chan struct{})
+	server.Handle("GET /slow", func(ctx *Context) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		ctx.RespData = []byte("done")
+	})
+
+	var order []string
+	server.OnShutdown(func(ctx context.Context) error {
+		order = append(order, "first")
+		return nil
+	})
+	server.OnShutdown(func(ctx context.Context) error {
+		order = append(order, "second")
+		return errors.New("flush failed")
+	})
+
+	addr := freeAddr(t)
+	runErr := make(chan error, 1)
+	go func() { runErr <- server.Run(addr) }()
+	waitServing(t, addr)
+
+	respCh := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			respCh <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		respCh <- string(body)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := server.Shutdown(ctx)
+	if err == nil || err.Error() != "flush failed" {
+		t.Errorf("期望返回钩子的错误, 得到 %v", err)
+	}
+
+	if body := <-respCh; body != "done" {
+		t.Errorf("进行中的请求应正常完成, 得到 %s", body)
+	}
+	if err := <-runErr; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("期望 Run 返回 ErrServerClosed, 得到 %v", err)
+	}
+	if len(order) != 2 || order[0] != "second" || order[1] != "first" {
+		t.Errorf("钩子应按注册的相反顺序执行, 得到 %v", order)
+	}
+}
+
+// TestShutdownWithoutRun 测试未启动时关闭只执行钩子
+func TestShutdownWithoutRun(t *testing.T) {
+	server := NewHTTPServer()
+	called := false
+	server.OnShutdown(func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("期望执行关闭钩子")
+	}
+}
+
+// TestRunWithGracefulShutdown 测试收到信号后优雅关闭
+func TestRunWithGracefulShutdown(t *testing.T) {
+	server := NewHTTPServer()
+	closed := make(chan struct{})
+	server.OnShutdown(func(ctx context.Context) error {
+		close(closed)
+		return nil
+	})
+
+	addr := freeAddr(t)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.RunWithGracefulShutdown(addr, time.Second, os.Interrupt)
+	}()
+	waitServing(t, addr)
+
+	proc, _ := os.FindProcess(os.Getpid())
+	if err := proc.Signal(os.Interrupt); err != nil {
+		t.Skipf("当前平台不支持发送信号: %v", err)
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("期望正常关闭, 得到 %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("收到信号后服务器未关闭")
+	}
+	<-closed
+}
+
+// TestRunWithGracefulShutdownStartError 测试启动失败时直接返回错误
+func TestRunWithGracefulShutdownStartError(t *testing.T) {
+	server := NewHTTPServer()
+	if err := server.RunWithGracefulShutdown("invalid-address:999999", time.Second); err == nil {
+		t.Error("期望无效地址返回错误")
+	}
+}
+
+// TestShutdownWaitsHijacked 测试关闭时等待接管连接的处理函数并发送 ServerStopped 事件
+func TestShutdownWaitsHijacked(t *testing.T) {
+	server := NewHTTPServer()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	returned := make(chan struct{})
+	server.Handle("GET /hijack", func(ctx *Context) {
+		conn, _, err := http.NewResponseController(ctx.Resp).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		close(started)
+		<-release
+		close(returned)
+	})
+	events := make(chan ServerStopped, 1)
+	server.OnStopped(func(ev ServerStopped) { events <- ev })
+
+	addr := freeAddr(t)
+	go func() { _ = server.Run(addr) }()
+	waitServing(t, addr)
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, _ = io.WriteString(conn, "GET /hijack HTTP/1.1\r\nHost: test\r\n\r\n")
+	<-started
+
+	time.AfterFunc(50*time.Millisecond, func() { close(release) })
+	if err = server.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-returned:
+	default:
+		t.Fatal("Shutdown 应等待接管连接的处理函数返回")
+	}
+	ev := <-events
+	if ev.Abandoned != 0 || ev.Err != nil || ev.Duration < 50*time.Millisecond {
+		t.Errorf("ServerStopped 事件不正确: %+v", ev)
+	}
+}
+
+// TestShutdownAbandoned 测试超时后事件中记录未完成的请求数
+func TestShutdownAbandoned(t *testing.T) {
+	server := NewHTTPServer()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	server.Handle("GET /hijack", func(ctx *Context) {
+		conn, _, err := http.NewResponseController(ctx.Resp).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		close(started)
+		<-release
+	})
+	var got ServerStopped
+	server.OnStopped(func(ev ServerStopped) { got = ev })
+
+	addr := freeAddr(t)
+	go func() { _ = server.Run(addr) }()
+	waitServing(t, addr)
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, _ = io.WriteString(conn, "GET /hijack HTTP/1.1\r\nHost: test\r\n\r\n")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = server.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("超时后应返回 context.DeadlineExceeded，实际为 %v", err)
+	}
+	if got.Abandoned != 1 || !errors.Is(got.Err, context.DeadlineExceeded) {
+		t.Errorf("ServerStopped 事件不正确: %+v", got)
+	}
+}
@@ -0,0 +1,139 @@
+package ant
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSSEInvalidField 事件的 ID 或类型中包含换行符等不允许的字符
+var ErrSSEInvalidField = errors.New("web: SSE 事件字段不合法")
+
+// SSEEvent Server-Sent Events 的一个事件
+type SSEEvent struct {
+	// ID 事件ID，客户端重连时通过 Last-Event-ID 请求头带回，为空时不发送
+	ID string
+	// Event 事件类型，为空时客户端按 message 事件处理
+	Event string
+	// Data 事件数据，包含换行时拆分为多个 data 字段
+	Data string
+	// Retry 建议客户端断开后的重连间隔，为0时不发送
+	Retry time.Duration
+}
+
+// SSEStream Server-Sent Events 响应流，由 Context.SSE 创建
+// 每次发送后立即刷新，只能在处理函数所在的 goroutine 中使用
+type SSEStream struct {
+	ctx *Context
+	rc  *http.ResponseController
+}
+
+// SSE 将响应切换为 text/event-stream 流，写入响应头并立即刷新
+// 返回值: 响应流，底层写入器不支持刷新时返回错误
+// 注意：
+// 1. 调用后不应再设置 RespData 或 RespStatusCode
+// 2. 会取消服务器 WriteTimeout 对该请求的限制，客户端断开时请求上下文结束，发送返回错误
+// 3. 应定期通过 Comment 发送心跳，避免代理因连接空闲而断开
+//
+// 例如：
+//
+//	server.Handle("GET /events", func(ctx *ant.Context) {
+//		stream, err := ctx.SSE()
+//		if err != nil {
+//			return
+//		}
+//		for {
+//			select {
+//			case msg := <-updates:
+//				if err := stream.SendJSON("update", msg); err != nil {
+//					return
+//				}
+//			case <-stream.Done():
+//				return
+//			}
+//		}
+//	})
+func (c *Context) SSE() (*SSEStream, error) {
+	rc := http.NewResponseController(c.Resp)
+	header := c.Resp.Header()
+	header.Set("Content-Type", "text/event-stream; charset=utf-8")
+	header.Set("Cache-Control", "no-cache")
+	// 避免 nginx 等代理缓冲事件
+	header.Set("X-Accel-Buffering", "no")
+	header.Del("Content-Length")
+	c.Resp.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return nil, err
+	}
+	// 流式响应的持续时间不受 WriteTimeout 限制，底层写入器不支持时忽略
+	_ = rc.SetWriteDeadline(time.Time{})
+	return &SSEStream{ctx: c, rc: rc}, nil
+}
+
+// LastEventID 返回客户端重连时通过 Last-Event-ID 请求头带回的事件ID，首次连接时为空
+// 可以据此补发断开期间错过的事件
+func (s *SSEStream) LastEventID() string {
+	return s.ctx.Req.Header.Get("Last-Event-ID")
+}
+
+// Done 返回客户端断开连接或请求超时时被关闭的通道
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.ctx.Req.Context().Done()
+}
+
+// Send 发送事件并刷新
+// 客户端已断开时返回请求上下文的错误，ID 或 Event 包含换行符时返回 ErrSSEInvalidField
+func (s *SSEStream) Send(ev SSEEvent) error {
+	if strings.ContainsAny(ev.ID, "\r\n\x00") || strings.ContainsAny(ev.Event, "\r\n") {
+		return ErrSSEInvalidField
+	}
+	var sb strings.Builder
+	if ev.ID != "" {
+		sb.WriteString("id: " + ev.ID + "\n")
+	}
+	if ev.Event != "" {
+		sb.WriteString("event: " + ev.Event + "\n")
+	}
+	if ev.Retry > 0 {
+		sb.WriteString("retry: " + strconv.FormatInt(ev.Retry.Milliseconds(), 10) + "\n")
+	}
+	data := strings.ReplaceAll(ev.Data, "\r\n", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		sb.WriteString("data: " + line + "\n")
+	}
+	sb.WriteString("\n")
+	return s.write(sb.String())
+}
+
+// SendJSON 将数据序列化为JSON后作为 event 类型的事件发送
+func (s *SSEStream) SendJSON(event string, val any) error {
+	bs, err := s.ctx.JSONCodec().Marshal(val)
+	if err != nil {
+		return err
+	}
+	return s.Send(SSEEvent{Event: event, Data: string(bs)})
+}
+
+// Retry 设置客户端断开后的重连间隔
+func (s *SSEStream) Retry(d time.Duration) error {
+	return s.write("retry: " + strconv.FormatInt(d.Milliseconds(), 10) + "\n\n")
+}
+
+// Comment 发送注释，客户端会忽略注释，通常用作心跳
+func (s *SSEStream) Comment(text string) error {
+	text = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(text)
+	return s.write(": " + text + "\n\n")
+}
+
+// write 写入并刷新，客户端已断开时返回请求上下文的错误
+func (s *SSEStream) write(data string) error {
+	if err := s.ctx.Req.Context().Err(); err != nil {
+		return err
+	}
+	if _, err := s.ctx.Resp.Write([]byte(data)); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
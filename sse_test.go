@@ -0,0 +1,104 @@
+package ant
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSSE 测试事件的格式与响应头
+func TestSSE(t *testing.T) {
+	server := NewHTTPServer()
+	server.Handle("GET /events", func(ctx *Context) {
+		stream, err := ctx.SSE()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_ = stream.Retry(3 * time.Second)
+		_ = stream.Send(SSEEvent{ID: "7", Event: "update", Data: "line1\r\nline2"})
+		_ = stream.SendJSON("user", userResp{ID: 1, Name: "tom"})
+		_ = stream.Comment("ping\nping")
+		_ = stream.Send(SSEEvent{Data: "last-id=" + stream.LastEventID()})
+		if err = stream.Send(SSEEvent{Event: "a\nb"}); !errors.Is(err, ErrSSEInvalidField) {
+			t.Errorf("事件类型包含换行时应返回 ErrSSEInvalidField，实际为 %v", err)
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Last-Event-ID", "6")
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/event-stream; charset=utf-8" ||
+		w.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("响应头不正确: %d %v", w.Code, w.Header())
+	}
+	if !w.Flushed {
+		t.Error("事件应立即刷新")
+	}
+	want := "retry: 3000\n\n" +
+		"id: 7\nevent: update\ndata: line1\ndata: line2\n\n" +
+		"event: user\ndata: {\"id\":1,\"name\":\"tom\"}\n\n" +
+		": ping ping\n\n" +
+		"data: last-id=6\n\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("事件流不正确:\n%q\n期望:\n%q", got, want)
+	}
+}
+
+// TestSSEDisconnect 测试客户端断开后处理函数结束
+func TestSSEDisconnect(t *testing.T) {
+	sendErr := make(chan error, 1)
+	server := NewHTTPServer()
+	server.Handle("GET /events", func(ctx *Context) {
+		stream, err := ctx.SSE()
+		if err != nil {
+			sendErr <- err
+			return
+		}
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err = stream.Send(SSEEvent{Data: "tick"}); err != nil {
+					sendErr <- err
+					return
+				}
+			case <-stream.Done():
+				sendErr <- stream.Send(SSEEvent{Data: "tick"})
+				return
+			}
+		}
+	})
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(reqCtx, http.MethodGet, srv.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "data: tick") {
+		t.Fatalf("应收到事件，实际为 %q %v", line, err)
+	}
+	cancel()
+	resp.Body.Close()
+
+	select {
+	case err = <-sendErr:
+		if err == nil {
+			t.Error("客户端断开后发送应返回错误")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("客户端断开后处理函数应结束")
+	}
+}
@@ -0,0 +1,103 @@
+package ant
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrQuotaExceeded 超出存储配额
+var ErrQuotaExceeded = errors.New("web: 超出存储配额")
+
+// StorageUsage 用户或租户的存储用量
+type StorageUsage struct {
+	// Owner 用户或租户的标识
+	Owner string `json:"owner"`
+	// Used 已使用的字节数
+	Used int64 `json:"used"`
+	// Quota 配额字节数，为0时不限制
+	Quota int64 `json:"quota"`
+}
+
+// StorageAccounting 按用户或租户统计存储用量并执行配额
+// 用量保存在内存中，重启后可以通过 SetUsage 从实际存储恢复
+type StorageAccounting struct {
+	mu           sync.Mutex
+	used         map[string]int64
+	quotas       map[string]int64
+	defaultQuota int64
+}
+
+// NewStorageAccounting 创建存储用量统计
+// defaultQuota: 未通过 SetQuota 单独设置时的配额字节数，为0时不限制
+func NewStorageAccounting(defaultQuota int64) *StorageAccounting {
+	return &StorageAccounting{
+		used:         make(map[string]int64),
+		quotas:       make(map[string]int64),
+		defaultQuota: defaultQuota,
+	}
+}
+
+// SetQuota 设置用户或租户的配额字节数，为0时不限制
+func (a *StorageAccounting) SetQuota(owner string, quota int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.quotas[owner] = quota
+}
+
+// SetUsage 设置用户或租户已使用的字节数，用于从实际存储恢复用量
+func (a *StorageAccounting) SetUsage(owner string, used int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.used[owner] = used
+}
+
+// Reserve 预留存储空间，超出配额时返回 ErrQuotaExceeded 且不修改用量
+// 写入失败时需要通过 Release 释放预留的空间
+func (a *StorageAccounting) Reserve(owner string, n int64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	quota := a.quota(owner)
+	if quota > 0 && a.used[owner]+n > quota {
+		return fmt.Errorf("%w: %s 已使用 %d 字节，配额 %d 字节，本次需要 %d 字节", ErrQuotaExceeded, owner, a.used[owner], quota, n)
+	}
+	a.used[owner] += n
+	return nil
+}
+
+// Release 释放存储空间，例如删除文件或写入失败时
+func (a *StorageAccounting) Release(owner string, n int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.used[owner] = max(a.used[owner]-n, 0)
+}
+
+// Usage 返回用户或租户的存储用量
+func (a *StorageAccounting) Usage(owner string) StorageUsage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return StorageUsage{Owner: owner, Used: a.used[owner], Quota: a.quota(owner)}
+}
+
+// UsageHandler 返回以 JSON 响应当前用户存储用量的处理函数
+// ownerFunc: 从请求中获取用户或租户的标识，返回空字符串时响应 401
+func (a *StorageAccounting) UsageHandler(ownerFunc func(ctx *Context) string) HandleFunc {
+	return func(ctx *Context) {
+		owner := ownerFunc(ctx)
+		if owner == "" {
+			ctx.RespStatusCode = http.StatusUnauthorized
+			ctx.RespData = []byte(http.StatusText(http.StatusUnauthorized))
+			return
+		}
+		_ = ctx.RespJSONOK(a.Usage(owner))
+	}
+}
+
+// quota 返回配额，调用方需持有锁
+func (a *StorageAccounting) quota(owner string) int64 {
+	if q, ok := a.quotas[owner]; ok {
+		return q
+	}
+	return a.defaultQuota
+}
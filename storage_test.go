@@ -0,0 +1,104 @@
+package ant
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// uploadRequest 创建上传文件的请求，X-User 请求头标识用户
+func uploadRequest(t *testing.T, user, filename, content string) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = part.Write([]byte(content))
+	_ = writer.Close()
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("X-User", user)
+	return req
+}
+
+// TestStorageAccounting 测试配额的预留与释放
+func TestStorageAccounting(t *testing.T) {
+	a := NewStorageAccounting(10)
+	a.SetQuota("vip", 0)
+	if err := a.Reserve("alice", 8); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Reserve("alice", 3); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("超出配额应返回 ErrQuotaExceeded，实际为 %v", err)
+	}
+	if u := a.Usage("alice"); u.Used != 8 || u.Quota != 10 {
+		t.Errorf("超出配额不应修改用量: %+v", u)
+	}
+	a.Release("alice", 100)
+	if u := a.Usage("alice"); u.Used != 0 {
+		t.Errorf("用量不应小于0: %+v", u)
+	}
+	if err := a.Reserve("vip", 1<<40); err != nil {
+		t.Errorf("配额为0时不限制，实际为 %v", err)
+	}
+}
+
+// TestFileUploaderQuota 测试上传文件时统计用量并执行配额
+func TestFileUploaderQuota(t *testing.T) {
+	dir := t.TempDir()
+	accounting := NewStorageAccounting(20)
+	owner := func(ctx *Context) string { return ctx.Req.Header.Get("X-User") }
+	uploader := &FileUploader{
+		FileField: "file",
+		DstPathFunc: func(fh *multipart.FileHeader) string {
+			return filepath.Join(dir, fh.Filename)
+		},
+		Accounting: accounting,
+		OwnerFunc:  owner,
+	}
+	s := NewHTTPServer()
+	s.Handle("POST /upload", uploader.Handle())
+	s.Handle("GET /usage", accounting.UsageHandler(owner))
+
+	upload := func(user, filename, content string) int {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, uploadRequest(t, user, filename, content))
+		return w.Code
+	}
+
+	if code := upload("alice", "a.txt", "0123456789"); code != http.StatusOK {
+		t.Fatalf("第一次上传应成功，实际为 %d", code)
+	}
+	if code := upload("alice", "b.txt", "0123456789ab"); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("超出配额应返回 413，实际为 %d", code)
+	}
+	// 覆盖已有文件时只计算差值
+	if code := upload("alice", "a.txt", "0123456789abcdef"); code != http.StatusOK {
+		t.Errorf("覆盖文件应成功，实际为 %d", code)
+	}
+	if u := accounting.Usage("alice"); u.Used != 16 {
+		t.Errorf("覆盖后用量应为 16，实际为 %d", u.Used)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/usage", nil)
+	req.Header.Set("X-User", "alice")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	var usage StorageUsage
+	if err := json.Unmarshal(w.Body.Bytes(), &usage); err != nil || usage.Used != 16 || usage.Quota != 20 {
+		t.Errorf("用量接口返回错误: %s %v", w.Body.String(), err)
+	}
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/usage", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("没有用户时应返回 401，实际为 %d", w.Code)
+	}
+}
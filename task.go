@@ -0,0 +1,158 @@
+package ant
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+var (
+	// ErrTaskPoolClosed 服务器已经关闭，不再接受后台任务
+	ErrTaskPoolClosed = errors.New("web: 服务器已关闭，不再接受后台任务")
+	// ErrTaskQueueFull 后台任务队列已满
+	ErrTaskQueueFull = errors.New("web: 后台任务队列已满")
+)
+
+// TaskPool 后台任务池的配置
+type TaskPool struct {
+	// Workers 执行任务的 goroutine 数量，默认为 runtime.NumCPU()
+	Workers int
+	// QueueSize 等待执行的任务数量上限，默认为 1024
+	QueueSize int
+}
+
+// ServerWithTaskPool 设置 Go 使用的后台任务池
+func ServerWithTaskPool(pool TaskPool) ServerOption {
+	return func(server *HTTPServer) {
+		server.tasks = newTaskPool(pool)
+	}
+}
+
+// task 提交到任务池的任务
+type task struct {
+	ctx context.Context
+	fn  func(ctx context.Context)
+}
+
+// taskPool 有界的后台任务池，worker 在第一次提交任务时启动
+type taskPool struct {
+	workers int
+	queue   chan task
+	once    sync.Once
+	wg      sync.WaitGroup
+
+	mu     sync.RWMutex // 保护 closed 与向 queue 发送
+	closed bool
+
+	// ctx 在关闭超时后取消，通知仍在执行的任务尽快退出
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// newTaskPool 创建任务池
+func newTaskPool(cfg TaskPool) *taskPool {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1024
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &taskPool{
+		workers: cfg.Workers,
+		queue:   make(chan task, cfg.QueueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Go 在后台任务池中执行任务，用于在处理函数中异步发送邮件、通知等
+// ctx: 通常为请求的 context，任务会继承其中的值，但不会因为请求结束而被取消
+// fn: 任务函数，参数 ctx 在服务器关闭超时后取消
+// 返回值: 服务器已关闭时返回 ErrTaskPoolClosed，队列已满时返回 ErrTaskQueueFull
+// 注意：
+// 1. Shutdown 会在进行中的请求完成后等待全部任务执行完毕，再执行关闭钩子
+// 2. 任务中的 panic 会被捕获并记录日志，不会导致进程退出
+// 3. 任务中不能使用请求的 *Context，它在处理函数返回后会被复用
+func (s *HTTPServer) Go(ctx context.Context, fn func(ctx context.Context)) error {
+	s.mu.Lock()
+	if s.tasks == nil {
+		s.tasks = newTaskPool(TaskPool{})
+	}
+	p := s.tasks
+	s.mu.Unlock()
+	return p.submit(task{ctx: context.WithoutCancel(ctx), fn: fn})
+}
+
+// submit 将任务放入队列，不会阻塞
+func (p *taskPool) submit(t task) error {
+	p.once.Do(p.start)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrTaskPoolClosed
+	}
+	select {
+	case p.queue <- t:
+		return nil
+	default:
+		return ErrTaskQueueFull
+	}
+}
+
+// start 启动 worker
+func (p *taskPool) start() {
+	p.wg.Add(p.workers)
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			defer p.wg.Done()
+			for t := range p.queue {
+				p.run(t)
+			}
+		}()
+	}
+}
+
+// run 执行单个任务，任务的 ctx 在任务池取消时一并取消
+func (p *taskPool) run(t task) {
+	ctx, cancel := context.WithCancel(t.ctx)
+	stop := context.AfterFunc(p.ctx, cancel)
+	defer func() {
+		stop()
+		cancel()
+		if val := recover(); val != nil {
+			log.Printf("web: 后台任务 panic: %v\n%s", val, debug.Stack())
+		}
+	}()
+	t.fn(ctx)
+}
+
+// drain 停止接受新任务并等待队列中的任务执行完毕
+// ctx 超时后取消正在执行的任务，并返回 ctx 的错误
+func (p *taskPool) drain(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+	// 没有提交过任务时 worker 尚未启动，启动后会直接退出
+	p.once.Do(p.start)
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,100 @@
+package ant
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type taskCtxKey struct{}
+
+// TestGoDrainedOnShutdown 测试关闭时等待后台任务执行完毕
+func TestGoDrainedOnShutdown(t *testing.T) {
+	server := NewHTTPServer(ServerWithTaskPool(TaskPool{Workers: 2, QueueSize: 10}))
+	var done atomic.Int32
+	var hookSawAll atomic.Bool
+	server.OnShutdown(func(ctx context.Context) error {
+		hookSawAll.Store(done.Load() == 5)
+		return nil
+	})
+
+	reqCtx, cancel := context.WithCancel(context.WithValue(context.Background(), taskCtxKey{}, "v"))
+	for i := 0; i < 5; i++ {
+		err := server.Go(reqCtx, func(ctx context.Context) {
+			time.Sleep(20 * time.Millisecond)
+			if ctx.Err() == nil && ctx.Value(taskCtxKey{}) == "v" {
+				done.Add(1)
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 请求结束不应取消后台任务
+	cancel()
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if done.Load() != 5 {
+		t.Errorf("期望 5 个任务执行完毕，实际 %d", done.Load())
+	}
+	if !hookSawAll.Load() {
+		t.Error("关闭钩子应在后台任务执行完毕后执行")
+	}
+	if err := server.Go(context.Background(), func(context.Context) {}); !errors.Is(err, ErrTaskPoolClosed) {
+		t.Errorf("关闭后期望 ErrTaskPoolClosed，实际 %v", err)
+	}
+}
+
+// TestGoQueueFull 测试队列已满
+func TestGoQueueFull(t *testing.T) {
+	server := NewHTTPServer(ServerWithTaskPool(TaskPool{Workers: 1, QueueSize: 1}))
+	release := make(chan struct{})
+	started := make(chan struct{})
+	block := func(ctx context.Context) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+	}
+	if err := server.Go(context.Background(), block); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := server.Go(context.Background(), block); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Go(context.Background(), block); !errors.Is(err, ErrTaskQueueFull) {
+		t.Errorf("期望 ErrTaskQueueFull，实际 %v", err)
+	}
+	close(release)
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestGoShutdownTimeout 测试关闭超时后取消任务，以及任务中的 panic
+func TestGoShutdownTimeout(t *testing.T) {
+	server := NewHTTPServer()
+	cancelled := make(chan struct{})
+	_ = server.Go(context.Background(), func(context.Context) { panic("boom") })
+	_ = server.Go(context.Background(), func(ctx context.Context) {
+		<-ctx.Done()
+		close(cancelled)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := server.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望超时错误，实际 %v", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("关闭超时后任务的 ctx 应被取消")
+	}
+}
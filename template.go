@@ -3,8 +3,23 @@ package ant
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"html/template"
+	"io"
 	"io/fs"
+	"maps"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // TemplateEngine 定义了模板引擎的接口
@@ -26,11 +41,34 @@ type GoTemplateEngine struct {
 	// T 是底层的模板对象
 	// 使用单个Template实例而不是map，因为Template本身支持按名称索引子模板
 	T *template.Template
+	// Funcs 模板中可以使用的函数，需要在加载模板之前设置，例如 FeatureFlags.FuncMap
+	Funcs template.FuncMap
+
+	// mu 保护 Watch 重新加载时替换的 T、pages、source 与 modTimes
+	mu sync.RWMutex
+	// pages 通过 LoadPages 加载的页面，每个页面都是布局与该页面组合后的模板
+	pages map[string]*template.Template
+	// source 最近一次加载的模板来源，用于 Watch
+	source *templateSource
+	// modTimes 最近一次加载时模板文件的修改时间
+	modTimes map[string]time.Time
+
+	// version 缓存的模板版本
+	version atomic.Pointer[templateVersion]
+}
+
+// templateSource 模板的来源，记录如何列出模板文件以及如何重新解析
+type templateSource struct {
+	// files 返回当前匹配的全部模板文件及其修改时间
+	files func() (map[string]time.Time, error)
+	// parse 解析模板，返回共享的模板与按名称索引的页面，没有页面时为nil
+	parse func() (*template.Template, map[string]*template.Template, error)
 }
 
 // Render 实现了TemplateEngine接口
 // 将数据渲染到指定模板中，并返回渲染结果
 // 保持RespData语义，支持中间件对渲染结果进行修改
+// 通过 LoadPages 加载的页面优先，其他名称在共享的模板中查找
 //
 // ctx: 上下文对象，用于控制渲染过程
 // tplName: 要渲染的模板名称
@@ -39,8 +77,14 @@ type GoTemplateEngine struct {
 // - []byte: 渲染后的页面内容
 // - error: 渲染过程中的错误
 func (g *GoTemplateEngine) Render(ctx context.Context, tplName string, data any) ([]byte, error) {
+	g.mu.RLock()
+	t := g.T
+	if page, ok := g.pages[tplName]; ok {
+		t = page
+	}
+	g.mu.RUnlock()
 	res := &bytes.Buffer{}
-	err := g.T.ExecuteTemplate(res, tplName, data)
+	err := t.ExecuteTemplate(res, tplName, data)
 	return res.Bytes(), err
 }
 
@@ -48,18 +92,30 @@ func (g *GoTemplateEngine) Render(ctx context.Context, tplName string, data any)
 // pattern: glob模式字符串，用于匹配模板文件
 // 返回值: 加载过程中发生的错误
 func (g *GoTemplateEngine) LoadFromGlob(pattern string) error {
-	var err error
-	g.T, err = template.ParseGlob(pattern)
-	return err
+	return g.load(&templateSource{
+		files: func() (map[string]time.Time, error) {
+			return osModTimes(filepath.Glob(pattern))
+		},
+		parse: func() (*template.Template, map[string]*template.Template, error) {
+			t, err := g.newTemplate().ParseGlob(pattern)
+			return t, nil, err
+		},
+	})
 }
 
 // LoadFromFiles 从指定的文件列表加载模板
 // files: 模板文件路径列表
 // 返回值: 加载过程中发生的错误
 func (g *GoTemplateEngine) LoadFromFiles(files ...string) error {
-	var err error
-	g.T, err = template.ParseFiles(files...)
-	return err
+	return g.load(&templateSource{
+		files: func() (map[string]time.Time, error) {
+			return osModTimes(files, nil)
+		},
+		parse: func() (*template.Template, map[string]*template.Template, error) {
+			t, err := g.newTemplate().ParseFiles(files...)
+			return t, nil, err
+		},
+	})
 }
 
 // LoadFromFS 从文件系统加载模板
@@ -67,7 +123,329 @@ func (g *GoTemplateEngine) LoadFromFiles(files ...string) error {
 // paths: 模板文件在文件系统中的路径列表
 // 返回值: 加载过程中发生的错误
 func (g *GoTemplateEngine) LoadFromFS(fs fs.FS, paths ...string) error {
-	var err error
-	g.T, err = template.ParseFS(fs, paths...)
-	return err
+	return g.load(&templateSource{
+		files: func() (map[string]time.Time, error) {
+			return fsModTimes(fs, paths...)
+		},
+		parse: func() (*template.Template, map[string]*template.Template, error) {
+			t, err := g.newTemplate().ParseFS(fs, paths...)
+			return t, nil, err
+		},
+	})
+}
+
+// LoadPages 加载布局与页面，每个页面单独与全部布局组合，不同页面可以定义同名的块
+// fsys: 文件系统，例如 os.DirFS("views") 或 embed.FS
+// layouts: 布局与局部模板的 glob 模式，例如 "layouts/*.gohtml"，所有页面共享
+// pages: 页面的 glob 模式，例如 "pages/*.gohtml"，页面按文件名渲染，文件名不能重复
+// 返回值: 加载过程中发生的错误
+//
+// 例如布局 layouts/base.gohtml：
+//
+//	<html><body>{{template "nav.gohtml" .}}{{block "content" .}}{{end}}</body></html>
+//
+// 页面 pages/article.gohtml 通过 ctx.RespTemplate("article.gohtml", data) 渲染：
+//
+//	{{template "base.gohtml" .}}
+//	{{define "content"}}<h1>{{.Title}}</h1>{{end}}
+func (g *GoTemplateEngine) LoadPages(fsys fs.FS, layouts, pages string) error {
+	return g.load(&templateSource{
+		files: func() (map[string]time.Time, error) {
+			return fsModTimes(fsys, layouts, pages)
+		},
+		parse: func() (*template.Template, map[string]*template.Template, error) {
+			shared, err := g.newTemplate().ParseFS(fsys, layouts)
+			if err != nil {
+				return nil, nil, err
+			}
+			names, err := fs.Glob(fsys, pages)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(names) == 0 {
+				return nil, nil, fmt.Errorf("web: 页面模式 %s 没有匹配任何文件", pages)
+			}
+			set := make(map[string]*template.Template, len(names))
+			for _, name := range names {
+				key := path.Base(name)
+				if _, ok := set[key]; ok {
+					return nil, nil, fmt.Errorf("web: 页面 %s 的文件名重复", name)
+				}
+				page, err := template.Must(shared.Clone()).ParseFS(fsys, name)
+				if err != nil {
+					return nil, nil, err
+				}
+				set[key] = page
+			}
+			return shared, set, nil
+		},
+	})
+}
+
+// Watch 按间隔检查模板文件，文件新增、删除或修改后重新加载，直到 ctx 被取消，用于开发模式下的热加载
+// onError: 检查或重新加载失败时调用，可以为nil，重新加载失败时继续使用原来的模板
+// 注意：
+// 1. 只对通过 LoadFromGlob、LoadFromFiles、LoadFromFS 或 LoadPages 加载的模板生效
+// 2. embed.FS 中文件的修改时间为零，内容不会变化，不需要热加载
+//
+//	if ant.DevMode() {
+//		engine.Watch(ctx, time.Second, func(err error) { log.Println(err) })
+//	}
+func (g *GoTemplateEngine) Watch(ctx context.Context, interval time.Duration, onError func(err error)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := g.reloadIfChanged(); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+}
+
+// reloadIfChanged 模板文件有变化时重新加载
+func (g *GoTemplateEngine) reloadIfChanged() error {
+	g.mu.RLock()
+	src, prev := g.source, g.modTimes
+	g.mu.RUnlock()
+	if src == nil {
+		return nil
+	}
+	files, err := src.files()
+	if err != nil {
+		return err
+	}
+	if maps.EqualFunc(files, prev, time.Time.Equal) {
+		return nil
+	}
+	t, pages, err := src.parse()
+	if err != nil {
+		// 记录本次的修改时间，文件再次修改之前不重复报告同一个错误
+		g.mu.Lock()
+		g.modTimes = files
+		g.mu.Unlock()
+		return err
+	}
+	g.replace(src, files, t, pages)
+	return nil
+}
+
+// load 从来源加载模板，加载失败时保留原来的模板
+func (g *GoTemplateEngine) load(src *templateSource) error {
+	// 先记录修改时间，解析期间发生的修改会在下一次检查时发现
+	files, err := src.files()
+	if err != nil {
+		return err
+	}
+	t, pages, err := src.parse()
+	if err != nil {
+		return err
+	}
+	g.replace(src, files, t, pages)
+	return nil
+}
+
+// replace 替换模板并计算版本
+// 第一次执行模板时 html/template 会修改解析结果，需要在模板可以被渲染之前计算版本
+func (g *GoTemplateEngine) replace(src *templateSource, files map[string]time.Time, t *template.Template, pages map[string]*template.Template) {
+	version := &templateVersion{t: t, sum: templatesSum(t, pages)}
+	g.mu.Lock()
+	g.T, g.pages, g.source, g.modTimes = t, pages, src, files
+	g.version.Store(version)
+	g.mu.Unlock()
+}
+
+// newTemplate 创建带有 Funcs 的空模板，解析的文件按文件名作为子模板
+func (g *GoTemplateEngine) newTemplate() *template.Template {
+	return template.New("").Funcs(g.Funcs)
+}
+
+// osModTimes 返回操作系统中文件的修改时间
+func osModTimes(files []string, err error) (map[string]time.Time, error) {
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		res[file] = info.ModTime()
+	}
+	return res, nil
+}
+
+// fsModTimes 返回文件系统中匹配 patterns 的文件的修改时间
+func fsModTimes(fsys fs.FS, patterns ...string) (map[string]time.Time, error) {
+	res := make(map[string]time.Time)
+	for _, pattern := range patterns {
+		files, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			info, err := fs.Stat(fsys, file)
+			if err != nil {
+				return nil, err
+			}
+			res[file] = info.ModTime()
+		}
+	}
+	return res, nil
+}
+
+// TemplateVersioner 可以返回模板版本的模板引擎，模板内容变化后版本随之变化
+// TemplateValidators 使用版本生成 ETag，没有实现该接口的模板引擎只按数据生成
+type TemplateVersioner interface {
+	TemplateVersion() string
+}
+
+// LastModifier 渲染数据实现该接口时，TemplateValidators 据此设置 Last-Modified
+type LastModifier interface {
+	LastModified() time.Time
+}
+
+// templateVersion 缓存的模板版本
+type templateVersion struct {
+	t   *template.Template
+	sum string
+}
+
+// TemplateVersion 实现 TemplateVersioner 接口，返回全部模板与页面解析结果的摘要
+// 加载模板时计算，直接设置 T 时在第一次调用时计算
+func (g *GoTemplateEngine) TemplateVersion() string {
+	g.mu.RLock()
+	t, pages := g.T, g.pages
+	g.mu.RUnlock()
+	if t == nil {
+		return ""
+	}
+	if v := g.version.Load(); v != nil && v.t == t {
+		return v.sum
+	}
+	sum := templatesSum(t, pages)
+	g.version.Store(&templateVersion{t: t, sum: sum})
+	return sum
+}
+
+// templatesSum 计算模板与页面解析结果的摘要
+func templatesSum(t *template.Template, pages map[string]*template.Template) string {
+	h := sha256.New()
+	hashTemplates(h, "", t)
+	for _, name := range slices.Sorted(maps.Keys(pages)) {
+		hashTemplates(h, name+"/", pages[name])
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// hashTemplates 按名称顺序将模板的解析结果写入摘要
+func hashTemplates(h io.Writer, prefix string, t *template.Template) {
+	tpls := t.Templates()
+	sort.Slice(tpls, func(i, j int) bool {
+		return tpls[i].Name() < tpls[j].Name()
+	})
+	for _, tpl := range tpls {
+		if tpl.Tree == nil || tpl.Tree.Root == nil {
+			continue
+		}
+		_, _ = io.WriteString(h, prefix+tpl.Name())
+		_, _ = h.Write([]byte{0})
+		_, _ = io.WriteString(h, tpl.Tree.Root.String())
+		_, _ = h.Write([]byte{0})
+	}
+}
+
+// TemplateValidators 返回为 RespTemplate 渲染的页面生成缓存验证器的中间件，按路由开启
+// ETag 由模板版本、模板名称与数据的JSON摘要生成，数据无法序列化为JSON时使用渲染结果的摘要
+// 数据实现了 LastModifier 时同时设置 Last-Modified
+// 请求的 If-None-Match 或 If-Modified-Since 表明页面没有变化时响应 304，并跳过渲染
+// 只处理 GET 与 HEAD 请求；缓存策略仍需通过 CacheControl 设置，例如 CacheControl{NoCache: true}
+//
+//	server.Handle("GET /articles/{id}", ant.TemplateValidators()(func(ctx *ant.Context) {
+//		_ = ctx.RespTemplate("article.gohtml", article)
+//	}))
+func TemplateValidators() Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			if ctx.Req.Method == http.MethodGet || ctx.Req.Method == http.MethodHead {
+				ctx.templateValidators = true
+			}
+			next(ctx)
+		}
+	}
+}
+
+// checkTemplateValidators 设置 ETag 与 Last-Modified，页面没有变化时写入 304
+// 返回值: ETag，数据无法序列化时为空；以及是否已经响应 304
+func (c *Context) checkTemplateValidators(tplName string, data any) (string, bool) {
+	var lastModified time.Time
+	if lm, ok := data.(LastModifier); ok {
+		lastModified = lm.LastModified()
+	}
+	bs, err := c.JSONCodec().Marshal(data)
+	if err != nil {
+		return "", false
+	}
+	var version string
+	if v, ok := c.TemplateEngine.(TemplateVersioner); ok {
+		version = v.TemplateVersion()
+	}
+	h := sha256.New()
+	_, _ = io.WriteString(h, version)
+	_, _ = h.Write([]byte{0})
+	_, _ = io.WriteString(h, tplName)
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(bs)
+	etag := `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+	return etag, c.writeNotModified(etag, lastModified)
+}
+
+// contentETag 按内容生成弱 ETag
+func contentETag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// writeNotModified 设置验证器响应头，请求的条件表明页面没有变化时写入 304
+// 同时存在 If-None-Match 时忽略 If-Modified-Since
+func (c *Context) writeNotModified(etag string, lastModified time.Time) bool {
+	header := c.Resp.Header()
+	header.Set("ETag", etag)
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	notModified := false
+	if inm := c.Req.Header.Get("If-None-Match"); inm != "" {
+		notModified = etagMatch(inm, etag)
+	} else if ims := c.Req.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		notModified = err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+	if !notModified {
+		return false
+	}
+	c.RespStatusCode = http.StatusNotModified
+	c.RespData = nil
+	c.Resp.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatch 按弱比较判断 If-None-Match 是否包含 etag
+func etagMatch(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == opaque {
+			return true
+		}
+	}
+	return false
 }
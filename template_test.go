@@ -3,10 +3,14 @@ package ant
 import (
 	"context"
 	"html/template"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"testing/fstest"
+	"time"
 )
 
 // TestGoTemplateEngineRender 测试基本的模板渲染功能
@@ -163,3 +167,199 @@ func TestGoTemplateEngineLoadFromGlob(t *testing.T) {
 		t.Errorf("Render() = %v, want %v", string(result), expected)
 	}
 }
+
+// article 实现了 LastModifier 的渲染数据
+type article struct {
+	Title   string
+	Updated time.Time
+}
+
+func (a article) LastModified() time.Time {
+	return a.Updated
+}
+
+// TestTemplateValidators 测试模板页面的 ETag、Last-Modified 与 304 响应
+func TestTemplateValidators(t *testing.T) {
+	engine := &GoTemplateEngine{}
+	if err := engine.LoadFromFS(fstest.MapFS{
+		"article.gohtml": {Data: []byte(`<h1>{{.Title}}</h1>`)},
+	}, "*.gohtml"); err != nil {
+		t.Fatal(err)
+	}
+	version := engine.TemplateVersion()
+	if version == "" {
+		t.Fatal("加载模板后应有版本")
+	}
+
+	updated := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	data := article{Title: "hello", Updated: updated}
+	renders := 0
+	server := NewHTTPServer(ServerWithTemplateEngine(&countingEngine{TemplateEngine: engine, renders: &renders}))
+	server.Handle("GET /articles/{id}", TemplateValidators()(func(ctx *Context) {
+		_ = ctx.RespTemplate("article.gohtml", data)
+	}))
+	server.Handle("GET /plain", func(ctx *Context) {
+		_ = ctx.RespTemplate("article.gohtml", data)
+	})
+
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/articles/1", nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || w.Body.String() != "<h1>hello</h1>" || etag == "" {
+		t.Fatalf("首次请求应返回页面与 ETag，实际为 %d %q %q", w.Code, w.Body.String(), etag)
+	}
+	if w.Header().Get("Last-Modified") != "Wed, 01 May 2024 08:00:00 GMT" {
+		t.Errorf("Last-Modified 不正确: %q", w.Header().Get("Last-Modified"))
+	}
+
+	renders = 0
+	w = get("/articles/1", http.Header{"If-None-Match": {`"other", ` + etag}})
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 || renders != 0 {
+		t.Errorf("ETag 匹配时应响应 304 且不渲染，实际为 %d %q 渲染 %d 次", w.Code, w.Body.String(), renders)
+	}
+	w = get("/articles/1", http.Header{"If-Modified-Since": {"Wed, 01 May 2024 09:00:00 GMT"}})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("页面在 If-Modified-Since 之后没有修改时应响应 304，实际为 %d", w.Code)
+	}
+	// 同时存在时以 If-None-Match 为准
+	w = get("/articles/1", http.Header{"If-None-Match": {`W/"stale"`}, "If-Modified-Since": {"Wed, 01 May 2024 09:00:00 GMT"}})
+	if w.Code != http.StatusOK {
+		t.Errorf("ETag 不匹配时应返回页面，实际为 %d", w.Code)
+	}
+
+	data.Title = "changed"
+	if w = get("/articles/1", http.Header{"If-None-Match": {etag}}); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("数据变化后应返回新的页面与 ETag，实际为 %d %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	if w = get("/plain", http.Header{"If-None-Match": {"*"}}); w.Code != http.StatusOK || w.Header().Get("ETag") != "" {
+		t.Errorf("未开启的路由不应处理验证器，实际为 %d %v", w.Code, w.Header())
+	}
+
+	// 重新加载不同的模板后版本变化
+	if err := engine.LoadFromFS(fstest.MapFS{
+		"article.gohtml": {Data: []byte(`<h2>{{.Title}}</h2>`)},
+	}, "*.gohtml"); err != nil {
+		t.Fatal(err)
+	}
+	if engine.TemplateVersion() == version {
+		t.Error("模板变化后版本应变化")
+	}
+}
+
+// countingEngine 记录渲染次数的模板引擎
+type countingEngine struct {
+	TemplateEngine
+	renders *int
+}
+
+func (e *countingEngine) Render(ctx context.Context, tplName string, data any) ([]byte, error) {
+	*e.renders++
+	return e.TemplateEngine.Render(ctx, tplName, data)
+}
+
+func (e *countingEngine) TemplateVersion() string {
+	return e.TemplateEngine.(TemplateVersioner).TemplateVersion()
+}
+
+// TestGoTemplateEngineLoadPages 测试布局与页面的组合
+func TestGoTemplateEngineLoadPages(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/base.gohtml": {Data: []byte(`<main>{{template "nav.gohtml" .}}{{block "content" .}}默认{{end}}</main>`)},
+		"layouts/nav.gohtml":  {Data: []byte(`<nav>{{upper .}}</nav>`)},
+		"pages/a.gohtml":      {Data: []byte(`{{template "base.gohtml" .}}{{define "content"}}A {{.}}{{end}}`)},
+		"pages/b.gohtml":      {Data: []byte(`{{template "base.gohtml" .}}{{define "content"}}B {{.}}{{end}}`)},
+	}
+	engine := &GoTemplateEngine{Funcs: template.FuncMap{"upper": strings.ToUpper}}
+	if err := engine.LoadPages(fsys, "layouts/*.gohtml", "pages/*.gohtml"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"a.gohtml", "<main><nav>TOM</nav>A tom</main>"},
+		{"b.gohtml", "<main><nav>TOM</nav>B tom</main>"},
+		{"nav.gohtml", "<nav>TOM</nav>"},
+	}
+	for _, tt := range tests {
+		got, err := engine.Render(context.Background(), tt.name, "tom")
+		if err != nil || string(got) != tt.want {
+			t.Errorf("渲染 %s 不正确: %s %v", tt.name, got, err)
+		}
+	}
+
+	// 页面变化时版本随之变化
+	version := engine.TemplateVersion()
+	fsys["pages/b.gohtml"] = &fstest.MapFile{Data: []byte(`{{template "base.gohtml" .}}{{define "content"}}C{{end}}`)}
+	if err := engine.LoadPages(fsys, "layouts/*.gohtml", "pages/*.gohtml"); err != nil {
+		t.Fatal(err)
+	}
+	if engine.TemplateVersion() == version {
+		t.Error("页面变化后模板版本应变化")
+	}
+
+	if err := engine.LoadPages(fsys, "layouts/*.gohtml", "missing/*.gohtml"); err == nil {
+		t.Error("页面模式没有匹配任何文件时应返回错误")
+	}
+	if got, _ := engine.Render(context.Background(), "b.gohtml", "tom"); string(got) != "<main><nav>TOM</nav>C</main>" {
+		t.Errorf("加载失败时应保留原来的模板，实际为 %s", got)
+	}
+}
+
+// TestGoTemplateEngineWatch 测试模板文件修改后重新加载
+func TestGoTemplateEngineWatch(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "index.html")
+	if err := os.WriteFile(file, []byte("v1 {{.}}"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	engine := &GoTemplateEngine{}
+	if err := engine.LoadFromGlob(filepath.Join(dir, "*.html")); err != nil {
+		t.Fatal(err)
+	}
+	errs := make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Watch(ctx, 5*time.Millisecond, func(err error) { errs <- err })
+
+	// 修改时间的精度可能较低，显式设置为不同的时间
+	rewrite := func(content string, mtime time.Time) {
+		if err := os.WriteFile(file, []byte(content), 0o666); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(file, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	render := func() string {
+		got, _ := engine.Render(context.Background(), "index.html", "tom")
+		return string(got)
+	}
+
+	rewrite("v2 {{.}}", time.Now().Add(time.Minute))
+	waitFor(t, func() bool { return render() == "v2 tom" })
+
+	rewrite("v3 {{.", time.Now().Add(2*time.Minute))
+	select {
+	case <-errs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("重新加载失败时应报告错误")
+	}
+	if got := render(); got != "v2 tom" {
+		t.Errorf("重新加载失败时应保留原来的模板，实际为 %s", got)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if len(errs) != 0 {
+		t.Error("文件没有再次修改时不应重复报告错误")
+	}
+}
@@ -0,0 +1,60 @@
+package ant
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// ServerWithReadHeaderTimeout 设置读取请求头的超时时间
+// 建议在直接面向公网时设置，防止慢速请求头攻击占用连接
+func ServerWithReadHeaderTimeout(d time.Duration) ServerOption {
+	return ServerWithHTTPServer(func(srv *http.Server) {
+		srv.ReadHeaderTimeout = d
+	})
+}
+
+// ServerWithReadTimeout 设置读取整个请求（包括请求体）的超时时间
+func ServerWithReadTimeout(d time.Duration) ServerOption {
+	return ServerWithHTTPServer(func(srv *http.Server) {
+		srv.ReadTimeout = d
+	})
+}
+
+// ServerWithWriteTimeout 设置写入响应的超时时间
+// 注意：该时间从读取完请求头开始计算，会影响 WebSocket、流式响应等长连接
+func ServerWithWriteTimeout(d time.Duration) ServerOption {
+	return ServerWithHTTPServer(func(srv *http.Server) {
+		srv.WriteTimeout = d
+	})
+}
+
+// ServerWithIdleTimeout 设置 keep-alive 连接的空闲超时时间
+func ServerWithIdleTimeout(d time.Duration) ServerOption {
+	return ServerWithHTTPServer(func(srv *http.Server) {
+		srv.IdleTimeout = d
+	})
+}
+
+// ServerWithMaxHeaderBytes 设置请求头的最大字节数，默认为 http.DefaultMaxHeaderBytes
+func ServerWithMaxHeaderBytes(n int) ServerOption {
+	return ServerWithHTTPServer(func(srv *http.Server) {
+		srv.MaxHeaderBytes = n
+	})
+}
+
+// ServerWithConnState 设置连接状态变化的回调，可用于统计活跃连接数
+func ServerWithConnState(fn func(conn net.Conn, state http.ConnState)) ServerOption {
+	return ServerWithHTTPServer(func(srv *http.Server) {
+		srv.ConnState = fn
+	})
+}
+
+// ServerWithHTTPServer 在启动前修改底层的 http.Server
+// fn: 修改函数，每个监听器对应的 http.Server 都会调用一次
+// 注意：不应修改 Addr、Handler 与 TLSConfig，TLS 相关配置请使用 ServerWithTLSConfig
+func ServerWithHTTPServer(fn func(srv *http.Server)) ServerOption {
+	return func(server *HTTPServer) {
+		server.serverHooks = append(server.serverHooks, fn)
+	}
+}
@@ -0,0 +1,66 @@
+package ant
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestServerTimeoutOptions 测试超时与限制选项应用到底层服务器
+func TestServerTimeoutOptions(t *testing.T) {
+	server := NewHTTPServer(
+		ServerWithReadHeaderTimeout(time.Second),
+		ServerWithReadTimeout(2*time.Second),
+		ServerWithWriteTimeout(3*time.Second),
+		ServerWithIdleTimeout(4*time.Second),
+		ServerWithMaxHeaderBytes(8<<10),
+	)
+	srv := server.newServer(":0")
+	if srv.ReadHeaderTimeout != time.Second || srv.ReadTimeout != 2*time.Second ||
+		srv.WriteTimeout != 3*time.Second || srv.IdleTimeout != 4*time.Second {
+		t.Errorf("超时配置未生效: %+v", srv)
+	}
+	if srv.MaxHeaderBytes != 8<<10 {
+		t.Errorf("期望 MaxHeaderBytes 为 %d, 得到 %d", 8<<10, srv.MaxHeaderBytes)
+	}
+}
+
+// TestServerReadHeaderTimeout 测试读取请求头超时后关闭连接，并触发连接状态回调
+func TestServerReadHeaderTimeout(t *testing.T) {
+	var closed atomic.Int32
+	server := NewHTTPServer(
+		ServerWithReadHeaderTimeout(50*time.Millisecond),
+		ServerWithConnState(func(conn net.Conn, state http.ConnState) {
+			if state == http.StateClosed {
+				closed.Add(1)
+			}
+		}),
+	)
+	addr := freeAddr(t)
+	go func() {
+		_ = server.Run(addr)
+	}()
+	waitServing(t, addr)
+	t.Cleanup(func() { _ = server.Shutdown(context.Background()) })
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// 只发送部分请求头
+	if _, err = conn.Write([]byte("GET / HTTP/1.1\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err = io.ReadAll(conn); err != nil {
+		t.Fatalf("期望服务器关闭连接, 得到 %v", err)
+	}
+	waitFor(t, func() bool {
+		return closed.Load() > 0
+	})
+}
@@ -0,0 +1,177 @@
+package ant
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// autoTLSOptions 自动证书管理的配置
+type autoTLSOptions struct {
+	cache     autocert.Cache // 证书缓存，为nil时使用 DirCache
+	email     string         // 注册 ACME 账户时使用的邮箱
+	directory string         // ACME 服务的目录地址，为空时使用 Let's Encrypt
+	httpAddr  string         // HTTP-01 质询监听地址，为空时不启动
+}
+
+// DefaultAutoTLSCacheDir 自动证书默认的缓存目录
+const DefaultAutoTLSCacheDir = "certs"
+
+// DefaultTLSConfig 返回推荐的 TLS 配置
+// 1. 最低版本为 TLS 1.2
+// 2. 优先使用 X25519 与 P-256 曲线
+// 3. TLS 1.2 下只启用支持前向保密的 AEAD 密码套件，TLS 1.3 的套件由标准库管理
+func DefaultTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+	}
+}
+
+// ServerWithTLSConfig 设置 TLS 配置，替换 DefaultTLSConfig
+// cfg: TLS 配置，服务器启动时会复制一份使用
+func ServerWithTLSConfig(cfg *tls.Config) ServerOption {
+	return func(server *HTTPServer) {
+		server.tlsConfig = cfg
+	}
+}
+
+// ServerWithClientCAs 开启双向 TLS，要求客户端提供由 pool 中的CA签发的证书
+// pool: 受信任的客户端证书CA
+// 注意：在 ServerWithTLSConfig 之后使用，否则会被其覆盖
+func ServerWithClientCAs(pool *x509.CertPool) ServerOption {
+	return func(server *HTTPServer) {
+		if server.tlsConfig == nil {
+			server.tlsConfig = DefaultTLSConfig()
+		}
+		server.tlsConfig.ClientCAs = pool
+		server.tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+}
+
+// ServerWithAutoTLSCache 设置自动证书的缓存存储
+// cache: 实现了 autocert.Cache 的存储，例如 autocert.DirCache 或基于数据库的实现
+// 多实例部署时应使用共享存储，避免重复申请证书触发 ACME 的频率限制
+func ServerWithAutoTLSCache(cache autocert.Cache) ServerOption {
+	return func(server *HTTPServer) {
+		server.autoTLSOpts.cache = cache
+	}
+}
+
+// ServerWithAutoTLSEmail 设置注册 ACME 账户时使用的邮箱，用于接收证书过期提醒
+func ServerWithAutoTLSEmail(email string) ServerOption {
+	return func(server *HTTPServer) {
+		server.autoTLSOpts.email = email
+	}
+}
+
+// ServerWithAutoTLSDirectory 设置 ACME 服务的目录地址，例如 Let's Encrypt 的测试环境
+func ServerWithAutoTLSDirectory(url string) ServerOption {
+	return func(server *HTTPServer) {
+		server.autoTLSOpts.directory = url
+	}
+}
+
+// ServerWithAutoTLSHTTPAddr 设置 HTTP-01 质询的监听地址，默认为 ":80"
+// 该地址上的其他请求会被重定向到 HTTPS，传入空字符串时不启动
+func ServerWithAutoTLSHTTPAddr(addr string) ServerOption {
+	return func(server *HTTPServer) {
+		server.autoTLSOpts.httpAddr = addr
+	}
+}
+
+// RunTLS 使用证书文件启动 HTTPS 服务器
+// addr: 监听地址
+// certFile: 证书文件路径，包含证书链时服务器证书应在最前
+// keyFile: 私钥文件路径
+// 返回值: 服务器运行过程中发生的错误，调用 Shutdown 后返回 http.ErrServerClosed
+func (s *HTTPServer) RunTLS(addr, certFile, keyFile string) error {
+	if addr == "" {
+		addr = ":https"
+	}
+	l, err := listenTCP(addr)
+	if err != nil {
+		return err
+	}
+	// 证书加载失败时 ServeTLS 不会关闭监听器
+	defer l.Close()
+	srv := s.newServer(addr)
+	srv.TLSConfig = s.serverTLSConfig()
+	s.reportStartup(l.Addr().String())
+	return srv.ServeTLS(l, certFile, keyFile)
+}
+
+// RunAutoTLS 启动 HTTPS 服务器，并通过 ACME 自动申请与续期证书
+// domains: 允许申请证书的域名，其他域名的握手会被拒绝
+// 返回值: 服务器运行过程中发生的错误
+// 注意：
+// 1. 服务监听 ":443"，并默认在 ":80" 上处理 HTTP-01 质询
+// 2. 证书默认缓存在 DefaultAutoTLSCacheDir 目录，可通过 ServerWithAutoTLSCache 替换
+// 3. 调用即表示同意 ACME 服务的服务条款
+func (s *HTTPServer) RunAutoTLS(domains ...string) error {
+	if len(domains) == 0 {
+		return errors.New("web: RunAutoTLS 至少需要一个域名")
+	}
+	m := s.autocertManager(domains)
+
+	errCh := make(chan error, 2)
+	if s.autoTLSOpts.httpAddr != "" {
+		challenge := &http.Server{Addr: s.autoTLSOpts.httpAddr, Handler: m.HTTPHandler(nil)}
+		s.mu.Lock()
+		s.servers = append(s.servers, challenge)
+		s.mu.Unlock()
+		go func() {
+			if err := challenge.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errCh <- err
+			}
+		}()
+	}
+
+	srv := s.newServer(":443")
+	srv.TLSConfig = s.serverTLSConfig()
+	srv.TLSConfig.GetCertificate = m.GetCertificate
+	srv.TLSConfig.NextProtos = append(srv.TLSConfig.NextProtos, m.TLSConfig().NextProtos...)
+
+	go func() {
+		errCh <- srv.ListenAndServeTLS("", "")
+	}()
+	return <-errCh
+}
+
+// autocertManager 根据配置创建证书管理器
+func (s *HTTPServer) autocertManager(domains []string) *autocert.Manager {
+	cache := s.autoTLSOpts.cache
+	if cache == nil {
+		cache = autocert.DirCache(DefaultAutoTLSCacheDir)
+	}
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      cache,
+		Email:      s.autoTLSOpts.email,
+	}
+	if s.autoTLSOpts.directory != "" {
+		m.Client = &acme.Client{DirectoryURL: s.autoTLSOpts.directory}
+	}
+	return m
+}
+
+// serverTLSConfig 返回服务器使用的 TLS 配置副本
+func (s *HTTPServer) serverTLSConfig() *tls.Config {
+	if s.tlsConfig == nil {
+		return DefaultTLSConfig()
+	}
+	return s.tlsConfig.Clone()
+}
@@ -0,0 +1,209 @@
+package ant
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// testCert 测试用的证书与私钥
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// newTestCert 生成证书并写入临时目录，parent 为nil时生成自签名证书
+func newTestCert(t *testing.T, name string, parent *testCert, isCA bool) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	tc := &testCert{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, "cert.pem"),
+		keyFile:  filepath.Join(dir, "key.pem"),
+	}
+	if err = os.WriteFile(tc.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(tc.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return tc
+}
+
+// tlsClient 创建信任指定证书的HTTPS客户端
+func tlsClient(roots *x509.CertPool, certs ...tls.Certificate) *http.Client {
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs},
+		},
+	}
+}
+
+// startTLS 在后台启动 HTTPS 服务器并在测试结束时关闭
+func startTLS(t *testing.T, server *HTTPServer, cert *testCert) string {
+	t.Helper()
+	addr := freeAddr(t)
+	go func() {
+		_ = server.RunTLS(addr, cert.certFile, cert.keyFile)
+	}()
+	waitServing(t, addr)
+	t.Cleanup(func() {
+		_ = server.Shutdown(context.Background())
+	})
+	return addr
+}
+
+// TestRunTLS 测试使用证书文件启动 HTTPS 服务器
+func TestRunTLS(t *testing.T) {
+	cert := newTestCert(t, "server", nil, true)
+	server := NewHTTPServer()
+	server.Handle("GET /hello", func(ctx *Context) {
+		ctx.RespData = []byte("hello tls")
+	})
+	addr := startTLS(t, server, cert)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert.cert)
+	resp, err := tlsClient(roots).Get("https://" + addr + "/hello")
+	if err != nil {
+		t.Fatalf("HTTPS 请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "hello tls" {
+		t.Errorf("期望响应 'hello tls', 得到 %q", body)
+	}
+	if resp.TLS == nil || resp.TLS.Version < tls.VersionTLS12 {
+		t.Errorf("协商的 TLS 版本不符合预期: %+v", resp.TLS)
+	}
+
+	// 低于 TLS 1.2 的客户端应被拒绝
+	old := tlsClient(roots)
+	old.Transport.(*http.Transport).TLSClientConfig.MaxVersion = tls.VersionTLS11
+	if _, err = old.Get("https://" + addr + "/hello"); err == nil {
+		t.Error("期望 TLS 1.1 的握手失败")
+	}
+}
+
+// TestRunTLSClientCAs 测试双向 TLS 的客户端证书校验
+func TestRunTLSClientCAs(t *testing.T) {
+	serverCert := newTestCert(t, "server", nil, true)
+	clientCA := newTestCert(t, "client-ca", nil, true)
+	clientCert := newTestCert(t, "client", clientCA, false)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA.cert)
+	server := NewHTTPServer(ServerWithClientCAs(clientCAs))
+	server.Handle("GET /whoami", func(ctx *Context) {
+		ctx.RespData = []byte(ctx.Req.TLS.PeerCertificates[0].Subject.CommonName)
+	})
+	addr := startTLS(t, server, serverCert)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(serverCert.cert)
+
+	if _, err := tlsClient(roots).Get("https://" + addr + "/whoami"); err == nil {
+		t.Error("未提供客户端证书时期望请求失败")
+	}
+
+	pair, err := tls.LoadX509KeyPair(clientCert.certFile, clientCert.keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := tlsClient(roots, pair).Get("https://" + addr + "/whoami")
+	if err != nil {
+		t.Fatalf("提供客户端证书后请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "client" {
+		t.Errorf("期望客户端名称 'client', 得到 %q", body)
+	}
+}
+
+// TestRunAutoTLSConfig 测试自动证书的参数校验与配置
+func TestRunAutoTLSConfig(t *testing.T) {
+	server := NewHTTPServer()
+	if err := server.RunAutoTLS(); err == nil {
+		t.Error("未指定域名时期望返回错误")
+	}
+
+	cache := autocert.DirCache(t.TempDir())
+	server = NewHTTPServer(
+		ServerWithAutoTLSCache(cache),
+		ServerWithAutoTLSEmail("ops@example.com"),
+		ServerWithAutoTLSDirectory("https://acme-staging-v02.api.letsencrypt.org/directory"),
+	)
+	m := server.autocertManager([]string{"example.com"})
+	if m.Cache != cache {
+		t.Error("期望使用配置的证书缓存")
+	}
+	if m.Email != "ops@example.com" {
+		t.Errorf("期望邮箱 'ops@example.com', 得到 %q", m.Email)
+	}
+	if m.Client == nil || m.Client.DirectoryURL == "" {
+		t.Error("期望使用配置的 ACME 目录地址")
+	}
+	if err := m.HostPolicy(context.Background(), "example.com"); err != nil {
+		t.Errorf("白名单内的域名应被允许: %v", err)
+	}
+	if err := m.HostPolicy(context.Background(), "evil.com"); err == nil {
+		t.Error("白名单外的域名应被拒绝")
+	}
+
+	if _, err := cache.Get(context.Background(), "missing"); !errors.Is(err, autocert.ErrCacheMiss) {
+		t.Errorf("期望缓存未命中, 得到 %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package ant
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies 受信任的代理配置
+// 用于解析客户端真实IP，以及决定是否接受来自对端的 PROXY protocol 头部
+// 由 Context.ClientIP、访问日志等组件共用，保证各处得到的客户端IP一致
+type TrustedProxies struct {
+	prefixes []netip.Prefix
+	headers  []string
+}
+
+// NewTrustedProxies 创建受信任的代理配置
+// cidrs: 受信任的代理地址，支持 CIDR（例如 "10.0.0.0/8"）与单个IP
+// 返回值: 代理配置，地址格式不正确时返回错误
+func NewTrustedProxies(cidrs ...string) (*TrustedProxies, error) {
+	p := &TrustedProxies{
+		prefixes: make([]netip.Prefix, 0, len(cidrs)),
+		headers:  []string{"X-Forwarded-For", "X-Real-IP"},
+	}
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("web: 非法的代理地址 %q: %w", cidr, err)
+			}
+			p.prefixes = append(p.prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("web: 非法的代理地址 %q: %w", cidr, err)
+		}
+		p.prefixes = append(p.prefixes, prefix.Masked())
+	}
+	return p, nil
+}
+
+// Headers 设置携带客户端IP的请求头，按顺序查找，默认为 X-Forwarded-For 与 X-Real-IP
+// 多个值以逗号分隔的请求头按 X-Forwarded-For 的格式解析
+func (p *TrustedProxies) Headers(headers ...string) *TrustedProxies {
+	p.headers = headers
+	return p
+}
+
+// Contains 判断地址是否属于受信任的代理
+func (p *TrustedProxies) Contains(addr netip.Addr) bool {
+	if p == nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range p.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP 解析请求的客户端IP
+// 只有直接对端是受信任的代理时才读取转发头部，避免客户端伪造
+// 转发链从右向左查找第一个不受信任的地址作为客户端IP
+func (p *TrustedProxies) ClientIP(r *http.Request) string {
+	remote := remoteIP(r.RemoteAddr)
+	addr, err := netip.ParseAddr(remote)
+	if err != nil || !p.Contains(addr) {
+		return remote
+	}
+	for _, header := range p.headers {
+		values := r.Header.Values(header)
+		if len(values) == 0 {
+			continue
+		}
+		hops := strings.Split(strings.Join(values, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				// 格式不正确的地址之后的内容不可信
+				break
+			}
+			if i == 0 || !p.Contains(hop) {
+				return hop.Unmap().String()
+			}
+		}
+	}
+	return remote
+}
+
+// remoteIP 去掉地址中的端口部分
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// ServerWithTrustedProxies 设置受信任的代理
+// 同时作用于 Context.ClientIP 与开启了 PROXY protocol 的监听器
+func ServerWithTrustedProxies(p *TrustedProxies) ServerOption {
+	return func(server *HTTPServer) {
+		server.trustedProxies = p
+	}
+}
+
+// ClientIP 返回客户端的真实IP
+// 未配置受信任的代理时直接使用连接的对端地址
+func (c *Context) ClientIP() string {
+	return c.trustedProxies.ClientIP(c.Req)
+}
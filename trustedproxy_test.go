@@ -0,0 +1,103 @@
+package ant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestTrustedProxiesClientIP 测试根据受信任的代理解析客户端IP
+func TestTrustedProxiesClientIP(t *testing.T) {
+	proxies, err := NewTrustedProxies("10.0.0.0/8", "192.168.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "不受信任的对端忽略转发头部",
+			remoteAddr: "203.0.113.9:1234",
+			headers:    map[string]string{"X-Forwarded-For": "1.1.1.1"},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "受信任的对端读取转发头部",
+			remoteAddr: "10.1.2.3:1234",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.7"},
+			want:       "198.51.100.7",
+		},
+		{
+			name:       "跳过链路中受信任的代理",
+			remoteAddr: "10.1.2.3:1234",
+			headers:    map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.7, 192.168.1.1, 10.9.9.9"},
+			want:       "198.51.100.7",
+		},
+		{
+			name:       "全部是受信任的代理时使用最左侧地址",
+			remoteAddr: "10.1.2.3:1234",
+			headers:    map[string]string{"X-Forwarded-For": "10.0.0.1, 10.0.0.2"},
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "使用 X-Real-IP",
+			remoteAddr: "192.168.1.1:1234",
+			headers:    map[string]string{"X-Real-IP": "198.51.100.8"},
+			want:       "198.51.100.8",
+		},
+		{
+			name:       "格式不正确的转发头部",
+			remoteAddr: "10.1.2.3:1234",
+			headers:    map[string]string{"X-Forwarded-For": "not-an-ip"},
+			want:       "10.1.2.3",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			if got := proxies.ClientIP(req); got != tc.want {
+				t.Errorf("期望客户端IP %s, 得到 %s", tc.want, got)
+			}
+		})
+	}
+
+	if _, err = NewTrustedProxies("10.0.0.0/33"); err == nil {
+		t.Error("非法的 CIDR 应返回错误")
+	}
+}
+
+// TestContextClientIP 测试 Context.ClientIP 使用服务器的代理配置
+func TestContextClientIP(t *testing.T) {
+	proxies, err := NewTrustedProxies("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewHTTPServer(ServerWithTrustedProxies(proxies))
+	server.Handle("GET /ip", func(ctx *Context) {
+		ctx.RespData = []byte(ctx.ClientIP())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Body.String() != "198.51.100.7" {
+		t.Errorf("期望客户端IP 198.51.100.7, 得到 %s", rec.Body.String())
+	}
+
+	// 未配置代理时使用对端地址
+	ctx := &Context{Req: req}
+	if ip := ctx.ClientIP(); ip != "127.0.0.1" {
+		t.Errorf("期望客户端IP 127.0.0.1, 得到 %s", ip)
+	}
+}
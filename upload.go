@@ -0,0 +1,382 @@
+package ant
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChunkedUploader 分片上传处理器，用于可靠地上传数GB的大文件
+// 客户端先创建上传，再按顺序发送分片；连接中断后查询已接收的字节数，从该位置继续发送
+// 未完成的上传保存在 Dir 中，服务重启后仍然可以继续
+//
+//	uploader := &ant.ChunkedUploader{
+//		Dir:         "./data/uploads",
+//		DstPathFunc: func(name string) string { return filepath.Join("./data/files", name) },
+//		MaxFileSize: 10 << 30,
+//	}
+//	server.Handle("POST /uploads", uploader.Create())
+//	server.Handle("GET /uploads/{id}", uploader.Status())
+//	server.Handle("PATCH /uploads/{id}", uploader.Append())
+type ChunkedUploader struct {
+	// Dir 未完成的上传保存的目录，应与目标文件位于同一个文件系统，完成后直接重命名
+	Dir string
+	// DstPathFunc 根据客户端提供的文件名确定目标存储路径，文件名已去掉目录部分
+	DstPathFunc func(filename string) string
+	// MaxFileSize 文件的大小上限，为0时不限制
+	MaxFileSize int64
+	// AllowedExts 允许上传的文件扩展名，例如 ".zip"，不区分大小写，为空时不限制
+	AllowedExts []string
+
+	// mu 保护 busy
+	mu sync.Mutex
+	// busy 正在写入分片的上传，同一个上传的分片不能并发写入
+	busy map[string]bool
+}
+
+// Upload 分片上传的状态
+type Upload struct {
+	// ID 上传的标识，后续请求通过路径参数 id 携带
+	ID string `json:"id"`
+	// Filename 客户端提供的文件名
+	Filename string `json:"filename"`
+	// Size 文件的总字节数
+	Size int64 `json:"size"`
+	// Offset 已经接收的字节数，下一个分片从该位置开始
+	Offset int64 `json:"offset"`
+	// SHA256 客户端提供的文件 SHA-256 摘要（十六进制），完成时校验，为空时不校验
+	SHA256 string `json:"sha256,omitempty"`
+	// Complete 是否已经接收全部内容并保存到目标路径
+	Complete bool `json:"complete"`
+}
+
+// createUploadReq 创建上传的请求
+type createUploadReq struct {
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+}
+
+// HeaderUploadOffset 分片在文件中的起始位置，PATCH 请求必须携带，响应中为已经接收的字节数
+const HeaderUploadOffset = "Upload-Offset"
+
+// Create 返回创建上传的处理函数
+// 请求体为 {"filename": "backup.tar", "size": 5368709120, "sha256": "..."}，sha256 可以省略
+// 成功时响应 201，响应体为 Upload，Location 为后续请求的地址
+// 文件名或摘要不合法时响应 400，超出 MaxFileSize 时响应 413，扩展名不在 AllowedExts 中时响应 415
+func (u *ChunkedUploader) Create() HandleFunc {
+	return func(ctx *Context) {
+		var req createUploadReq
+		if err := ctx.BindJSON(&req); err != nil {
+			ctx.RespError(fmt.Errorf("%w: %w", ErrValidation, err))
+			return
+		}
+		req.Filename = filepath.Base(filepath.Clean("/" + req.Filename))
+		req.SHA256 = strings.ToLower(req.SHA256)
+		switch {
+		case req.Filename == "/" || req.Filename == ".":
+			ctx.RespError(fmt.Errorf("%w: 未指定文件名", ErrValidation))
+			return
+		case req.Size <= 0:
+			ctx.RespError(fmt.Errorf("%w: 文件大小必须大于0", ErrValidation))
+			return
+		case req.SHA256 != "" && !validSHA256(req.SHA256):
+			ctx.RespError(fmt.Errorf("%w: sha256 不合法", ErrValidation))
+			return
+		case u.MaxFileSize > 0 && req.Size > u.MaxFileSize:
+			ctx.RespError(NewHTTPError(http.StatusRequestEntityTooLarge, "文件过大"))
+			return
+		case !allowedExt(u.AllowedExts, req.Filename):
+			ctx.RespError(fmt.Errorf("%w: %s", ErrUnsupportedMediaType, filepath.Ext(req.Filename)))
+			return
+		}
+
+		id := rand.Text()
+		upload := Upload{ID: id, Filename: req.Filename, Size: req.Size, SHA256: req.SHA256}
+		if err := u.create(upload); err != nil {
+			ctx.RespError(err)
+			return
+		}
+		ctx.Resp.Header().Set("Location", strings.TrimSuffix(ctx.Req.URL.Path, "/")+"/"+id)
+		ctx.Resp.Header().Set(HeaderUploadOffset, "0")
+		_ = ctx.RespJSON(http.StatusCreated, upload)
+	}
+}
+
+// Status 返回查询上传状态的处理函数，路由需要包含路径参数 {id}
+// 响应体为 Upload，响应头 Upload-Offset 为已经接收的字节数，上传不存在或已经完成时响应 404
+func (u *ChunkedUploader) Status() HandleFunc {
+	return func(ctx *Context) {
+		upload, err := u.load(ctx.Req.PathValue("id"))
+		if err != nil {
+			ctx.RespError(err)
+			return
+		}
+		ctx.Resp.Header().Set(HeaderUploadOffset, strconv.FormatInt(upload.Offset, 10))
+		ctx.Resp.Header().Set("Cache-Control", "no-store")
+		_ = ctx.RespJSONOK(upload)
+	}
+}
+
+// Append 返回接收分片的处理函数，路由需要包含路径参数 {id}
+// 请求体为分片的原始内容，请求头 Upload-Offset 必须等于已经接收的字节数，否则响应 409 并在响应头中返回正确的位置
+// 可以通过 Content-Digest: sha-256=:base64: 请求头（RFC 9530）校验分片，不一致时丢弃该分片并响应 400
+// 接收全部内容后校验整个文件的摘要并重命名到 DstPathFunc 返回的路径，摘要不一致时删除上传并响应 400
+// 注意：
+// 1. 分片内容直接写入磁盘，不会读入内存
+// 2. 没有校验分片时，连接中断前已经接收的内容会被保留，客户端查询状态后继续发送即可
+// 3. 同一个上传的分片并发写入时响应 409
+func (u *ChunkedUploader) Append() HandleFunc {
+	return func(ctx *Context) {
+		id := ctx.Req.PathValue("id")
+		if !u.acquire(id) {
+			ctx.RespError(fmt.Errorf("%w: 上传 %s 的另一个分片正在写入", ErrConflict, id))
+			return
+		}
+		defer u.release(id)
+
+		upload, err := u.load(id)
+		if err != nil {
+			ctx.RespError(err)
+			return
+		}
+		ctx.Resp.Header().Set(HeaderUploadOffset, strconv.FormatInt(upload.Offset, 10))
+		offset, err := strconv.ParseInt(ctx.Req.Header.Get(HeaderUploadOffset), 10, 64)
+		if err != nil || offset != upload.Offset {
+			ctx.RespError(fmt.Errorf("%w: 分片应从 %d 开始", ErrConflict, upload.Offset))
+			return
+		}
+		var want []byte
+		if digest := ctx.Req.Header.Get("Content-Digest"); digest != "" {
+			if want, err = parseSHA256Digest(digest); err != nil {
+				ctx.RespError(err)
+				return
+			}
+		}
+
+		written, err := u.write(upload, ctx.Req.Body, want)
+		if err != nil {
+			ctx.RespError(err)
+			return
+		}
+		upload.Offset += written
+		ctx.Resp.Header().Set(HeaderUploadOffset, strconv.FormatInt(upload.Offset, 10))
+		if upload.Offset == upload.Size {
+			if err = u.complete(upload); err != nil {
+				ctx.RespError(err)
+				return
+			}
+			upload.Complete = true
+		}
+		_ = ctx.RespJSONOK(upload)
+	}
+}
+
+// RemoveStale 删除超过 maxAge 没有收到分片的未完成上传，可以定期调用
+// 返回值: 删除的上传数量
+func (u *ChunkedUploader) RemoveStale(maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(u.Dir)
+	if err != nil {
+		return 0, err
+	}
+	deadline := time.Now().Add(-maxAge)
+	removed := 0
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || !validUploadID(id) {
+			continue
+		}
+		info, err := os.Stat(u.partPath(id))
+		if errors.Is(err, fs.ErrNotExist) {
+			info, err = e.Info()
+		}
+		if err != nil || info.ModTime().After(deadline) || !u.acquire(id) {
+			continue
+		}
+		u.remove(id)
+		u.release(id)
+		removed++
+	}
+	return removed, nil
+}
+
+// create 创建上传的元数据与空的数据文件
+func (u *ChunkedUploader) create(upload Upload) error {
+	if err := os.MkdirAll(u.Dir, 0o755); err != nil {
+		return err
+	}
+	meta, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(u.partPath(upload.ID), nil, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(u.metaPath(upload.ID), meta, 0o644)
+}
+
+// load 读取上传的元数据，已经接收的字节数为数据文件的大小
+func (u *ChunkedUploader) load(id string) (Upload, error) {
+	if !validUploadID(id) {
+		return Upload{}, fmt.Errorf("%w: 上传 %s", ErrNotFound, id)
+	}
+	meta, err := os.ReadFile(u.metaPath(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Upload{}, fmt.Errorf("%w: 上传 %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return Upload{}, err
+	}
+	var upload Upload
+	if err = json.Unmarshal(meta, &upload); err != nil {
+		return Upload{}, err
+	}
+	info, err := os.Stat(u.partPath(id))
+	if err != nil {
+		return Upload{}, err
+	}
+	upload.Offset = info.Size()
+	return upload, nil
+}
+
+// write 将分片追加到数据文件，want 不为nil时校验分片的摘要
+// 分片超出文件大小或摘要不一致时截断到原来的位置
+func (u *ChunkedUploader) write(upload Upload, body io.Reader, want []byte) (int64, error) {
+	f, err := os.OpenFile(u.partPath(upload.ID), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	remaining := upload.Size - upload.Offset
+	written, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(body, remaining+1))
+	switch {
+	case written > remaining:
+		err = NewHTTPError(http.StatusRequestEntityTooLarge, "分片超出文件大小")
+	case want != nil && err == nil && !bytes.Equal(h.Sum(nil), want):
+		err = fmt.Errorf("%w: 分片的摘要不一致", ErrValidation)
+	case want == nil && err != nil:
+		// 保留中断前已经接收的内容，客户端可以从新的位置继续
+		return written, err
+	}
+	if err != nil {
+		if truncErr := f.Truncate(upload.Offset); truncErr != nil {
+			return 0, errors.Join(err, truncErr)
+		}
+		return 0, err
+	}
+	return written, nil
+}
+
+// complete 校验整个文件的摘要并重命名到目标路径
+func (u *ChunkedUploader) complete(upload Upload) error {
+	part := u.partPath(upload.ID)
+	if upload.SHA256 != "" {
+		f, err := os.Open(part)
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+		if hex.EncodeToString(h.Sum(nil)) != upload.SHA256 {
+			u.remove(upload.ID)
+			return fmt.Errorf("%w: 文件的摘要不一致，需要重新上传", ErrValidation)
+		}
+	}
+	dst := u.DstPathFunc(upload.Filename)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	if err := os.Rename(part, dst); err != nil {
+		return err
+	}
+	return os.Remove(u.metaPath(upload.ID))
+}
+
+// remove 删除上传的元数据与数据文件
+func (u *ChunkedUploader) remove(id string) {
+	_ = os.Remove(u.partPath(id))
+	_ = os.Remove(u.metaPath(id))
+}
+
+// acquire 标记上传正在写入，已经在写入时返回false
+func (u *ChunkedUploader) acquire(id string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.busy[id] {
+		return false
+	}
+	if u.busy == nil {
+		u.busy = make(map[string]bool)
+	}
+	u.busy[id] = true
+	return true
+}
+
+// release 取消上传正在写入的标记
+func (u *ChunkedUploader) release(id string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.busy, id)
+}
+
+func (u *ChunkedUploader) partPath(id string) string {
+	return filepath.Join(u.Dir, id+".part")
+}
+
+func (u *ChunkedUploader) metaPath(id string) string {
+	return filepath.Join(u.Dir, id+".json")
+}
+
+// validUploadID 判断上传的标识是否为 rand.Text 生成的格式，防止路径穿越
+func validUploadID(id string) bool {
+	if len(id) != 26 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'A' && c <= 'Z' || c >= '2' && c <= '7') {
+			return false
+		}
+	}
+	return true
+}
+
+// validSHA256 判断是否为十六进制的 SHA-256 摘要
+func validSHA256(s string) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == sha256.Size
+}
+
+// parseSHA256Digest 解析 Content-Digest 请求头中的 sha-256 摘要，例如 sha-256=:base64:
+func parseSHA256Digest(header string) ([]byte, error) {
+	for _, item := range strings.Split(header, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || !strings.EqualFold(name, "sha-256") {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(strings.Trim(value, ":"))
+		if err != nil || len(b) != sha256.Size {
+			break
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("%w: Content-Digest 中没有合法的 sha-256 摘要", ErrValidation)
+}
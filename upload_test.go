@@ -0,0 +1,149 @@
+package ant
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newChunkedServer(t *testing.T) (*HTTPServer, *ChunkedUploader, string) {
+	t.Helper()
+	dstDir := t.TempDir()
+	uploader := &ChunkedUploader{
+		Dir:         t.TempDir(),
+		DstPathFunc: func(name string) string { return filepath.Join(dstDir, name) },
+		MaxFileSize: 64,
+		AllowedExts: []string{".bin"},
+	}
+	server := NewHTTPServer()
+	server.Handle("POST /uploads", uploader.Create())
+	server.Handle("GET /uploads/{id}", uploader.Status())
+	server.Handle("PATCH /uploads/{id}", uploader.Append())
+	return server, uploader, dstDir
+}
+
+func doUpload(server http.Handler, method, path, body string, header map[string]string) (*httptest.ResponseRecorder, Upload) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	var upload Upload
+	_ = json.Unmarshal(w.Body.Bytes(), &upload)
+	return w, upload
+}
+
+// TestChunkedUploader 测试分片上传、断点续传与完成后的校验
+func TestChunkedUploader(t *testing.T) {
+	server, _, dstDir := newChunkedServer(t)
+	content := strings.Repeat("0123456789", 5)
+	sum := sha256.Sum256([]byte(content))
+
+	w, upload := doUpload(server, http.MethodPost, "/uploads",
+		`{"filename":"../data.bin","size":50,"sha256":"`+hex.EncodeToString(sum[:])+`"}`, nil)
+	if w.Code != http.StatusCreated || upload.Filename != "data.bin" || w.Header().Get("Location") != "/uploads/"+upload.ID {
+		t.Fatalf("创建上传失败: %d %s %v", w.Code, w.Body.String(), w.Header())
+	}
+	path := "/uploads/" + upload.ID
+
+	w, _ = doUpload(server, http.MethodPatch, path, content[:20], map[string]string{HeaderUploadOffset: "0"})
+	if w.Code != http.StatusOK || w.Header().Get(HeaderUploadOffset) != "20" {
+		t.Fatalf("第一个分片应被接收，实际为 %d %v", w.Code, w.Header())
+	}
+	w, _ = doUpload(server, http.MethodPatch, path, content[20:30], map[string]string{HeaderUploadOffset: "0"})
+	if w.Code != http.StatusConflict || w.Header().Get(HeaderUploadOffset) != "20" {
+		t.Errorf("位置不正确时应返回 409 与正确的位置，实际为 %d %v", w.Code, w.Header())
+	}
+	chunkSum := sha256.Sum256([]byte("wrong"))
+	w, _ = doUpload(server, http.MethodPatch, path, content[20:30], map[string]string{
+		HeaderUploadOffset: "20",
+		"Content-Digest":   "sha-256=:" + base64.StdEncoding.EncodeToString(chunkSum[:]) + ":",
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("分片摘要不一致时应返回 400，实际为 %d", w.Code)
+	}
+
+	// 断点续传：查询已经接收的字节数
+	w, upload = doUpload(server, http.MethodGet, path, "", nil)
+	if w.Code != http.StatusOK || upload.Offset != 20 || upload.Complete {
+		t.Fatalf("查询状态不正确: %d %+v", w.Code, upload)
+	}
+	chunkSum = sha256.Sum256([]byte(content[20:]))
+	w, upload = doUpload(server, http.MethodPatch, path, content[20:], map[string]string{
+		HeaderUploadOffset: strconv.FormatInt(upload.Offset, 10),
+		"Content-Digest":   "sha-256=:" + base64.StdEncoding.EncodeToString(chunkSum[:]) + ":",
+	})
+	if w.Code != http.StatusOK || !upload.Complete || upload.Offset != 50 {
+		t.Fatalf("最后一个分片后应完成上传，实际为 %d %s", w.Code, w.Body.String())
+	}
+	if got, err := os.ReadFile(filepath.Join(dstDir, "data.bin")); err != nil || string(got) != content {
+		t.Errorf("保存的文件不正确: %q %v", got, err)
+	}
+	if w, _ = doUpload(server, http.MethodGet, path, "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("完成后上传应被删除，实际为 %d", w.Code)
+	}
+}
+
+// TestChunkedUploaderErrors 测试创建上传的校验、超出大小与整个文件的摘要不一致
+func TestChunkedUploaderErrors(t *testing.T) {
+	server, uploader, dstDir := newChunkedServer(t)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "超出 MaxFileSize", body: `{"filename":"a.bin","size":65}`, wantCode: http.StatusRequestEntityTooLarge},
+		{name: "不允许的扩展名", body: `{"filename":"a.exe","size":1}`, wantCode: http.StatusUnsupportedMediaType},
+		{name: "没有文件名", body: `{"size":1}`, wantCode: http.StatusBadRequest},
+		{name: "摘要不合法", body: `{"filename":"a.bin","size":1,"sha256":"xyz"}`, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w, _ := doUpload(server, http.MethodPost, "/uploads", tt.body, nil); w.Code != tt.wantCode {
+				t.Errorf("期望状态码 %d, 得到 %d", tt.wantCode, w.Code)
+			}
+		})
+	}
+
+	if w, _ := doUpload(server, http.MethodGet, "/uploads/..%2F..%2Fetc", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("不合法的上传ID应返回 404，实际为 %d", w.Code)
+	}
+
+	_, upload := doUpload(server, http.MethodPost, "/uploads",
+		`{"filename":"b.bin","size":4,"sha256":"`+strings.Repeat("0", 64)+`"}`, nil)
+	path := "/uploads/" + upload.ID
+	if w, _ := doUpload(server, http.MethodPatch, path, "12345", map[string]string{HeaderUploadOffset: "0"}); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("分片超出文件大小时应返回 413，实际为 %d", w.Code)
+	}
+	if w, _ := doUpload(server, http.MethodPatch, path, "1234", map[string]string{HeaderUploadOffset: "0"}); w.Code != http.StatusBadRequest {
+		t.Errorf("文件摘要不一致时应返回 400，实际为 %d", w.Code)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "b.bin")); !os.IsNotExist(err) {
+		t.Errorf("摘要不一致的文件不应被保存: %v", err)
+	}
+	if w, _ := doUpload(server, http.MethodGet, path, "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("摘要不一致后上传应被删除，实际为 %d", w.Code)
+	}
+
+	_, upload = doUpload(server, http.MethodPost, "/uploads", `{"filename":"c.bin","size":4}`, nil)
+	if n, err := uploader.RemoveStale(time.Hour); err != nil || n != 0 {
+		t.Errorf("未过期的上传不应被删除: %d %v", n, err)
+	}
+	if n, err := uploader.RemoveStale(-time.Second); err != nil || n != 1 {
+		t.Errorf("过期的上传应被删除: %d %v", n, err)
+	}
+	if w, _ := doUpload(server, http.MethodGet, "/uploads/"+upload.ID, "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("删除后查询应返回 404，实际为 %d", w.Code)
+	}
+}
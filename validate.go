@@ -0,0 +1,74 @@
+package ant
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// namedCheck 通过 AddCheck 注册的检查
+type namedCheck struct {
+	name  string
+	check func() error
+}
+
+// AddCheck 注册 Validate 时执行的检查，例如检查处理函数依赖的目录或外部配置
+// name: 检查的名称，出现在错误信息中
+// check: 检查函数，返回nil表示通过
+func (s *HTTPServer) AddCheck(name string, check func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks = append(s.checks, namedCheck{name: name, check: check})
+}
+
+// Validate 在启动前检查服务器配置的一致性，不会 panic
+// 检查项:
+// 1. 不可达的路由，例如方法名为小写的 "get /users"
+// 2. 设置了模板引擎但没有加载任何模板
+// 3. 通过 AddCheck 注册的检查
+// 返回值: 所有问题合并后的错误，没有问题时返回nil
+func (s *HTTPServer) Validate() error {
+	var errs []error
+	errs = append(errs, s.checkRoutes()...)
+	if g, ok := s.TemplateEngine.(*GoTemplateEngine); ok && (g == nil || g.T == nil) {
+		errs = append(errs, errors.New("web: 设置了模板引擎但没有加载任何模板"))
+	}
+
+	s.mu.Lock()
+	checks := make([]namedCheck, len(s.checks))
+	copy(checks, s.checks)
+	s.mu.Unlock()
+	for _, c := range checks {
+		if err := c.check(); err != nil {
+			errs = append(errs, fmt.Errorf("web: %s: %w", c.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkRoutes 检查不可达的路由
+// ServeMux 已经拒绝了冲突与路径不规范的路由，这里检查包含小写字母的方法名：
+// HTTP 方法区分大小写，客户端总是发送大写的方法名，例如 "get /a" 永远不会被匹配
+func (s *HTTPServer) checkRoutes() []error {
+	var errs []error
+	for _, pattern := range s.Routes() {
+		method, _, _ := splitPattern(pattern)
+		if method != strings.ToUpper(method) {
+			errs = append(errs, fmt.Errorf("web: 路由 %q 不可达，HTTP 方法区分大小写，应为 %s", pattern, strings.ToUpper(method)))
+		}
+	}
+	return errs
+}
+
+// checkDir 检查目录存在
+func checkDir(dir string) error {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s 不是目录", dir)
+	}
+	return nil
+}
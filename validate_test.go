@@ -0,0 +1,85 @@
+package ant
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestValidateOK 测试配置正确时没有错误
+func TestValidateOK(t *testing.T) {
+	server := NewHTTPServer()
+	handler := func(ctx *Context) {}
+	server.Handle("GET /users/{id}", handler)
+	server.Handle("GET /users/new", handler)
+	server.Handle("/files/{path...}", handler)
+	server.Handle("GET /{$}", handler)
+	server.Handle("POST example.com/hooks/{name}", handler)
+	server.Handle("/docs/", handler)
+
+	if err := server.Validate(); err != nil {
+		t.Errorf("期望没有错误，实际 %v", err)
+	}
+}
+
+// TestValidateUnreachableRoutes 测试不可达的路由
+func TestValidateUnreachableRoutes(t *testing.T) {
+	server := NewHTTPServer()
+	handler := func(ctx *Context) {}
+	server.Handle("get /a", handler)
+	server.Handle("Post /b/{id}", handler)
+	server.Handle("GET /ok", handler)
+
+	err := server.Validate()
+	if err == nil {
+		t.Fatal("期望返回不可达路由的错误")
+	}
+	for _, pattern := range []string{`"get /a"`, `"Post /b/{id}"`} {
+		if !strings.Contains(err.Error(), pattern) {
+			t.Errorf("错误信息应包含 %s，实际 %v", pattern, err)
+		}
+	}
+	if strings.Contains(err.Error(), "/ok") {
+		t.Errorf("可达的路由不应报告错误: %v", err)
+	}
+}
+
+// TestValidateTemplateEngine 测试没有加载模板的模板引擎
+func TestValidateTemplateEngine(t *testing.T) {
+	server := NewHTTPServer(ServerWithTemplateEngine(&GoTemplateEngine{}))
+	if err := server.Validate(); err == nil || !strings.Contains(err.Error(), "模板") {
+		t.Errorf("期望模板引擎的错误，实际 %v", err)
+	}
+}
+
+// TestValidateChecks 测试注册的检查与多个错误的合并
+func TestValidateChecks(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewHTTPServer()
+	server.AddCheck("存在的目录", NewStaticResourceHandler(dir, "/static").Validate)
+	server.AddCheck("不存在的目录", NewStaticResourceHandler(filepath.Join(dir, "missing"), "/static").Validate)
+	server.AddCheck("文件", (&FileDownloader{Dir: file}).Validate)
+	sentinel := errors.New("依赖不可用")
+	server.AddCheck("自定义", func() error { return sentinel })
+
+	err := server.Validate()
+	if !errors.Is(err, sentinel) || !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("合并的错误应包含每个检查的错误，实际 %v", err)
+	}
+	msg := err.Error()
+	for _, name := range []string{"不存在的目录", "文件", "自定义"} {
+		if !strings.Contains(msg, name) {
+			t.Errorf("错误信息应包含检查名称 %s，实际 %v", name, msg)
+		}
+	}
+	if n := len(strings.Split(msg, "\n")); n != 3 {
+		t.Errorf("期望 3 个错误，实际 %d 个: %v", n, msg)
+	}
+}
@@ -0,0 +1,477 @@
+package ant
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket 消息类型
+const (
+	// WSTextMessage 文本消息
+	WSTextMessage = websocket.TextMessage
+	// WSBinaryMessage 二进制消息
+	WSBinaryMessage = websocket.BinaryMessage
+)
+
+// WebSocket 关闭码，定义见 RFC 6455 第 7.4.1 节
+const (
+	// WSCloseNormal 正常关闭
+	WSCloseNormal = websocket.CloseNormalClosure
+	// WSCloseGoingAway 服务端关闭或客户端离开页面
+	WSCloseGoingAway = websocket.CloseGoingAway
+	// WSClosePolicyViolation 消息违反了服务端的策略，例如未通过鉴权
+	WSClosePolicyViolation = websocket.ClosePolicyViolation
+	// WSCloseMessageTooBig 消息超过了 ReadLimit
+	WSCloseMessageTooBig = websocket.CloseMessageTooBig
+	// WSCloseInternalError 服务端内部错误
+	WSCloseInternalError = websocket.CloseInternalServerErr
+)
+
+var (
+	// ErrWSClosed 连接已关闭
+	ErrWSClosed = errors.New("web: websocket 连接已关闭")
+	// ErrWSSendQueueFull 发送队列已满，通常意味着客户端消费过慢
+	ErrWSSendQueueFull = errors.New("web: websocket 发送队列已满")
+)
+
+// WSOptions WebSocket 连接的配置
+type WSOptions struct {
+	// ReadLimit 单条消息的最大字节数，超过时连接会被关闭
+	ReadLimit int64
+	// SendQueueSize 每个连接的发送队列长度
+	SendQueueSize int
+	// PingInterval 发送 ping 的间隔，必须小于 PongWait，小于等于0时不发送 ping
+	PingInterval time.Duration
+	// PongWait 等待 pong 的最长时间，超时后连接被视为已断开，小于等于0时不设置读取超时
+	PongWait time.Duration
+	// WriteWait 单次写入的超时时间
+	WriteWait time.Duration
+	// ReadBufferSize 与 WriteBufferSize 为底层 I/O 缓冲区大小
+	ReadBufferSize  int
+	WriteBufferSize int
+	// Subprotocols 服务端支持的子协议
+	Subprotocols []string
+	// CheckOrigin 校验请求来源，为nil时只允许同源请求
+	CheckOrigin func(r *http.Request) bool
+}
+
+// WSOption WebSocket 连接的配置选项
+type WSOption func(opts *WSOptions)
+
+// WSWithReadLimit 设置单条消息的最大字节数
+func WSWithReadLimit(limit int64) WSOption {
+	return func(opts *WSOptions) {
+		opts.ReadLimit = limit
+	}
+}
+
+// WSWithSendQueueSize 设置发送队列长度
+func WSWithSendQueueSize(size int) WSOption {
+	return func(opts *WSOptions) {
+		opts.SendQueueSize = size
+	}
+}
+
+// WSWithKeepalive 设置 ping 间隔与 pong 等待时间
+// 两者都小于等于0时关闭心跳检测，连接只在读写出错时断开
+func WSWithKeepalive(pingInterval, pongWait time.Duration) WSOption {
+	return func(opts *WSOptions) {
+		opts.PingInterval = pingInterval
+		opts.PongWait = pongWait
+	}
+}
+
+// WSWithCheckOrigin 设置请求来源校验函数
+func WSWithCheckOrigin(fn func(r *http.Request) bool) WSOption {
+	return func(opts *WSOptions) {
+		opts.CheckOrigin = fn
+	}
+}
+
+// WSWithSubprotocols 设置服务端支持的子协议
+func WSWithSubprotocols(protocols ...string) WSOption {
+	return func(opts *WSOptions) {
+		opts.Subprotocols = protocols
+	}
+}
+
+// defaultWSOptions 返回默认的 WebSocket 配置
+func defaultWSOptions() WSOptions {
+	return WSOptions{
+		ReadLimit:     1 << 20,
+		SendQueueSize: 256,
+		PingInterval:  30 * time.Second,
+		PongWait:      60 * time.Second,
+		WriteWait:     10 * time.Second,
+	}
+}
+
+// wsMessage 发送队列中的消息
+type wsMessage struct {
+	messageType int
+	data        []byte
+}
+
+// wsConnID 连接ID生成器
+var wsConnID atomic.Uint64
+
+// WSConn 受管理的 WebSocket 连接
+// 写入通过发送队列由单独的 goroutine 完成，并自动维持 ping/pong 心跳
+// 读取需要由调用方在单个 goroutine 中完成
+type WSConn struct {
+	id        string
+	conn      *websocket.Conn
+	opts      WSOptions
+	codec     JSONCodec
+	send      chan wsMessage
+	done      chan struct{}
+	closeOnce sync.Once
+	// closeCode 与 closeReason 为关闭时发送给客户端的关闭帧内容
+	closeCode   int
+	closeReason string
+
+	// hub 连接所属的连接管理器，可能为nil
+	hub   atomic.Pointer[Hub]
+	rooms map[string]struct{}
+}
+
+// Upgrade 将当前请求升级为 WebSocket 连接
+// opts: 连接配置选项
+// 返回值: 受管理的连接，或升级失败时的错误
+// 注意：
+// 1. 升级前已执行的中间件（例如鉴权）对连接同样生效
+// 2. 升级成功后不应再写入 RespData 或调用 Resp 的方法
+// 3. 单条消息默认最大 1MB，可通过 WSWithReadLimit 调整
+func (c *Context) Upgrade(opts ...WSOption) (*WSConn, error) {
+	o := defaultWSOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  o.ReadBufferSize,
+		WriteBufferSize: o.WriteBufferSize,
+		Subprotocols:    o.Subprotocols,
+		CheckOrigin:     o.CheckOrigin,
+	}
+	conn, err := upgrader.Upgrade(c.Resp, c.Req, nil)
+	if err != nil {
+		// Upgrader 已经写入了错误响应
+		c.hijacked = true
+		return nil, err
+	}
+	c.hijacked = true
+
+	ws := &WSConn{
+		id:    strconv.FormatUint(wsConnID.Add(1), 10),
+		conn:  conn,
+		opts:  o,
+		codec: c.JSONCodec(),
+		send:  make(chan wsMessage, o.SendQueueSize),
+		done:  make(chan struct{}),
+		rooms: make(map[string]struct{}),
+
+		closeCode: WSCloseNormal,
+	}
+
+	conn.SetReadLimit(o.ReadLimit)
+	if o.PongWait > 0 {
+		_ = conn.SetReadDeadline(time.Now().Add(o.PongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(o.PongWait))
+		})
+	}
+
+	go ws.writePump()
+	return ws, nil
+}
+
+// WSHandleFunc WebSocket 路由的处理函数
+// 处理函数返回后连接会被关闭，因此读取循环应在处理函数中完成
+type WSHandleFunc func(ctx *Context, conn *WSConn)
+
+// HandleWS 注册 WebSocket 路由，请求经过全局中间件与路由中间件后升级为 WebSocket 连接
+// 升级失败时已经响应了错误，不会调用 handler；handler 返回后连接以 WSCloseNormal 关闭
+// 单条消息默认最大 1MB，超过 ReadLimit 时以 WSCloseMessageTooBig 关闭连接
+// 例如：
+//
+//	server.HandleWS("GET /ws", func(ctx *ant.Context, conn *ant.WSConn) {
+//		hub.Register(conn)
+//		for {
+//			_, data, err := conn.ReadMessage()
+//			if err != nil {
+//				return
+//			}
+//			hub.Broadcast(data)
+//		}
+//	}, ant.WSWithReadLimit(64<<10))
+func (s *HTTPServer) HandleWS(pattern string, handler WSHandleFunc, opts ...WSOption) {
+	s.handle(pattern, func(ctx *Context) {
+		conn, err := ctx.Upgrade(opts...)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(ctx, conn)
+	})
+}
+
+// ID 返回连接的唯一标识
+func (w *WSConn) ID() string {
+	return w.id
+}
+
+// Subprotocol 返回协商后的子协议
+func (w *WSConn) Subprotocol() string {
+	return w.conn.Subprotocol()
+}
+
+// ReadMessage 读取下一条消息
+// 返回值: 消息类型、消息内容及读取过程中的错误，连接关闭后返回错误
+func (w *WSConn) ReadMessage() (int, []byte, error) {
+	messageType, data, err := w.conn.ReadMessage()
+	if err != nil {
+		w.Close()
+	}
+	return messageType, data, err
+}
+
+// ReadJSON 读取下一条消息并解析为JSON
+func (w *WSConn) ReadJSON(val any) error {
+	_, data, err := w.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return w.codec.Unmarshal(data, val)
+}
+
+// Send 将文本消息放入发送队列
+// 队列已满时立即返回 ErrWSSendQueueFull，不会阻塞调用方
+func (w *WSConn) Send(data []byte) error {
+	return w.enqueue(wsMessage{messageType: websocket.TextMessage, data: data})
+}
+
+// SendBinary 将二进制消息放入发送队列
+func (w *WSConn) SendBinary(data []byte) error {
+	return w.enqueue(wsMessage{messageType: websocket.BinaryMessage, data: data})
+}
+
+// SendJSON 将数据序列化为JSON后放入发送队列
+func (w *WSConn) SendJSON(val any) error {
+	data, err := w.codec.Marshal(val)
+	if err != nil {
+		return err
+	}
+	return w.Send(data)
+}
+
+// enqueue 非阻塞地将消息放入发送队列
+func (w *WSConn) enqueue(msg wsMessage) error {
+	select {
+	case <-w.done:
+		return ErrWSClosed
+	default:
+	}
+	select {
+	case w.send <- msg:
+		return nil
+	case <-w.done:
+		return ErrWSClosed
+	default:
+		return ErrWSSendQueueFull
+	}
+}
+
+// Done 返回连接关闭时被关闭的通道
+func (w *WSConn) Done() <-chan struct{} {
+	return w.done
+}
+
+// Close 以 WSCloseNormal 关闭连接，并从所属的连接管理器中移除
+// 可以被多次调用
+func (w *WSConn) Close() {
+	w.CloseWith(WSCloseNormal, "")
+}
+
+// CloseWith 使用指定的关闭码与原因关闭连接，已入队的消息会先发送完
+// 只有第一次关闭生效，reason 最多 123 字节
+func (w *WSConn) CloseWith(code int, reason string) {
+	w.closeOnce.Do(func() {
+		w.closeCode, w.closeReason = code, reason
+		close(w.done)
+		if h := w.hub.Load(); h != nil {
+			h.Unregister(w)
+		}
+	})
+}
+
+// writePump 负责写入队列中的消息并定期发送 ping
+func (w *WSConn) writePump() {
+	// 没有设置 ping 间隔时 ping 为nil，不会被选中
+	var ping <-chan time.Time
+	if w.opts.PingInterval > 0 {
+		ticker := time.NewTicker(w.opts.PingInterval)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+	defer func() {
+		_ = w.conn.Close()
+	}()
+
+	for {
+		select {
+		case msg := <-w.send:
+			_ = w.conn.SetWriteDeadline(time.Now().Add(w.opts.WriteWait))
+			if err := w.conn.WriteMessage(msg.messageType, msg.data); err != nil {
+				w.Close()
+				return
+			}
+		case <-ping:
+			_ = w.conn.SetWriteDeadline(time.Now().Add(w.opts.WriteWait))
+			if err := w.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				w.Close()
+				return
+			}
+		case <-w.done:
+			// 尽量将已入队的消息发送完再关闭
+			for {
+				select {
+				case msg := <-w.send:
+					_ = w.conn.SetWriteDeadline(time.Now().Add(w.opts.WriteWait))
+					if err := w.conn.WriteMessage(msg.messageType, msg.data); err != nil {
+						return
+					}
+				default:
+					_ = w.conn.WriteControl(websocket.CloseMessage,
+						websocket.FormatCloseMessage(w.closeCode, w.closeReason),
+						time.Now().Add(w.opts.WriteWait))
+					return
+				}
+			}
+		}
+	}
+}
+
+// Hub WebSocket 连接管理器
+// 维护连接与房间的关系，支持全局广播与房间内广播
+type Hub struct {
+	mu    sync.RWMutex
+	conns map[string]*WSConn
+	rooms map[string]map[string]*WSConn
+}
+
+// NewHub 创建连接管理器
+func NewHub() *Hub {
+	return &Hub{
+		conns: make(map[string]*WSConn),
+		rooms: make(map[string]map[string]*WSConn),
+	}
+}
+
+// Register 将连接加入管理器，连接关闭时会被自动移除
+func (h *Hub) Register(conn *WSConn) {
+	h.mu.Lock()
+	h.conns[conn.id] = conn
+	h.mu.Unlock()
+	conn.hub.Store(h)
+
+	// 连接可能在注册前已经关闭
+	select {
+	case <-conn.done:
+		h.Unregister(conn)
+	default:
+	}
+}
+
+// Unregister 将连接从管理器及其加入的全部房间中移除
+func (h *Hub) Unregister(conn *WSConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.conns, conn.id)
+	for room := range conn.rooms {
+		h.leave(conn, room)
+	}
+}
+
+// Join 将连接加入房间
+func (h *Hub) Join(conn *WSConn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.conns[conn.id]; !ok {
+		return
+	}
+	members, ok := h.rooms[room]
+	if !ok {
+		members = make(map[string]*WSConn)
+		h.rooms[room] = members
+	}
+	members[conn.id] = conn
+	conn.rooms[room] = struct{}{}
+}
+
+// Leave 将连接移出房间
+func (h *Hub) Leave(conn *WSConn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.leave(conn, room)
+}
+
+// leave 在持有锁的情况下将连接移出房间
+func (h *Hub) leave(conn *WSConn, room string) {
+	delete(conn.rooms, room)
+	members, ok := h.rooms[room]
+	if !ok {
+		return
+	}
+	delete(members, conn.id)
+	if len(members) == 0 {
+		delete(h.rooms, room)
+	}
+}
+
+// Broadcast 向所有连接发送文本消息
+// 返回值: 因发送队列已满或连接关闭而未能送达的连接数
+func (h *Hub) Broadcast(data []byte) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return broadcast(h.conns, data)
+}
+
+// BroadcastRoom 向房间内的所有连接发送文本消息
+// 返回值: 未能送达的连接数
+func (h *Hub) BroadcastRoom(room string, data []byte) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return broadcast(h.rooms[room], data)
+}
+
+// broadcast 向一组连接发送消息，统计失败的数量
+func broadcast(conns map[string]*WSConn, data []byte) int {
+	failed := 0
+	for _, conn := range conns {
+		if err := conn.Send(data); err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Count 返回当前管理的连接数
+func (h *Hub) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+// RoomCount 返回房间内的连接数
+func (h *Hub) RoomCount(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
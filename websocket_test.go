@@ -0,0 +1,274 @@
+package ant
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialWS 连接测试服务器上的 WebSocket 端点
+func dialWS(t *testing.T, srv *httptest.Server, path string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + path
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// TestWebSocketEcho 测试升级连接与消息收发
+func TestWebSocketEcho(t *testing.T) {
+	server := NewHTTPServer()
+	server.Handle("GET /ws", func(ctx *Context) {
+		conn, err := ctx.Upgrade()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			_ = conn.Send(append([]byte("echo: "), data...))
+		}
+	})
+
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	client := dialWS(t, srv, "/ws")
+	if err := client.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	_ = client.SetReadDeadline(time.Now().Add(time.Second))
+	_, data, err := client.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "echo: hello" {
+		t.Errorf("期望 echo: hello, 得到 %s", data)
+	}
+}
+
+// TestWebSocketKeepaliveDisabled 测试 ping 间隔与 pong 等待时间为0时关闭心跳检测
+func TestWebSocketKeepaliveDisabled(t *testing.T) {
+	server := NewHTTPServer()
+	server.Handle("GET /ws", func(ctx *Context) {
+		conn, err := ctx.Upgrade(WSWithKeepalive(0, 0))
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = conn.Send(data)
+	})
+
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	client := dialWS(t, srv, "/ws")
+	// 没有读取超时，稍后发送的消息仍能被读取
+	time.Sleep(20 * time.Millisecond)
+	if err := client.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	_ = client.SetReadDeadline(time.Now().Add(time.Second))
+	_, data, err := client.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("期望 hello, 得到 %s", data)
+	}
+}
+
+// TestWebSocketMiddlewareAuth 测试中间件在升级前执行
+func TestWebSocketMiddlewareAuth(t *testing.T) {
+	server := NewHTTPServer()
+	server.Use(func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			if ctx.Req.URL.Query().Get("token") != "ok" {
+				ctx.RespStatusCode = http.StatusUnauthorized
+				return
+			}
+			next(ctx)
+		}
+	})
+	server.Handle("GET /ws", func(ctx *Context) {
+		conn, err := ctx.Upgrade()
+		if err == nil {
+			conn.Close()
+		}
+	})
+
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatal("期望未授权的连接被拒绝")
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("期望状态码 401, 得到 %d", resp.StatusCode)
+	}
+}
+
+// TestWebSocketUpgradeFailure 测试非 WebSocket 请求的升级失败
+func TestWebSocketUpgradeFailure(t *testing.T) {
+	server := NewHTTPServer()
+	var upgradeErr error
+	server.Handle("GET /ws", func(ctx *Context) {
+		_, upgradeErr = ctx.Upgrade()
+	})
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	if upgradeErr == nil {
+		t.Fatal("期望普通请求升级失败")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("期望状态码 400, 得到 %d", rec.Code)
+	}
+}
+
+// TestHandleWS 测试通过 HandleWS 注册的路由与关闭码
+func TestHandleWS(t *testing.T) {
+	server := NewHTTPServer()
+	server.HandleWS("GET /ws", func(ctx *Context, conn *WSConn) {
+		if ctx.Req.URL.Query().Get("room") == "" {
+			conn.CloseWith(WSClosePolicyViolation, "missing room")
+			return
+		}
+		_ = conn.Send([]byte("welcome"))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}, WSWithReadLimit(8))
+
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	closeCode := func(client *websocket.Conn) int {
+		_ = client.SetReadDeadline(time.Now().Add(time.Second))
+		for {
+			if _, _, err := client.ReadMessage(); err != nil {
+				var ce *websocket.CloseError
+				if errors.As(err, &ce) {
+					return ce.Code
+				}
+				t.Fatalf("期望收到关闭帧，实际为 %v", err)
+			}
+		}
+	}
+
+	client := dialWS(t, srv, "/ws")
+	if code := closeCode(client); code != WSClosePolicyViolation {
+		t.Errorf("期望关闭码 %d，实际为 %d", WSClosePolicyViolation, code)
+	}
+
+	client = dialWS(t, srv, "/ws?room=a")
+	_ = client.SetReadDeadline(time.Now().Add(time.Second))
+	if _, data, err := client.ReadMessage(); err != nil || string(data) != "welcome" {
+		t.Fatalf("期望收到 welcome，实际为 %s %v", data, err)
+	}
+	if err := client.WriteMessage(websocket.TextMessage, []byte("too long message")); err != nil {
+		t.Fatal(err)
+	}
+	if code := closeCode(client); code != WSCloseMessageTooBig {
+		t.Errorf("超过 ReadLimit 时期望关闭码 %d，实际为 %d", WSCloseMessageTooBig, code)
+	}
+}
+
+// TestHubRooms 测试连接管理器的房间与广播
+func TestHubRooms(t *testing.T) {
+	hub := NewHub()
+	server := NewHTTPServer()
+	server.Handle("GET /ws/{room}", func(ctx *Context) {
+		conn, err := ctx.Upgrade()
+		if err != nil {
+			return
+		}
+		hub.Register(conn)
+		hub.Join(conn, ctx.Req.PathValue("room"))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	a := dialWS(t, srv, "/ws/a")
+	b := dialWS(t, srv, "/ws/b")
+
+	waitFor(t, func() bool { return hub.Count() == 2 && hub.RoomCount("a") == 1 })
+
+	if failed := hub.BroadcastRoom("a", []byte("room a")); failed != 0 {
+		t.Errorf("期望全部送达, 失败 %d", failed)
+	}
+	hub.Broadcast([]byte("all"))
+
+	_ = a.SetReadDeadline(time.Now().Add(time.Second))
+	_, first, _ := a.ReadMessage()
+	_, second, _ := a.ReadMessage()
+	if string(first) != "room a" || string(second) != "all" {
+		t.Errorf("房间 a 收到的消息不正确: %s, %s", first, second)
+	}
+
+	_ = b.SetReadDeadline(time.Now().Add(time.Second))
+	_, msg, _ := b.ReadMessage()
+	if string(msg) != "all" {
+		t.Errorf("房间 b 应只收到全局广播, 得到 %s", msg)
+	}
+
+	// 客户端断开后连接应被自动移除
+	b.Close()
+	waitFor(t, func() bool { return hub.Count() == 1 && hub.RoomCount("b") == 0 })
+}
+
+// TestWSConnSendQueueFull 测试发送队列已满与连接关闭后的发送
+func TestWSConnSendQueueFull(t *testing.T) {
+	conn := &WSConn{
+		send: make(chan wsMessage, 1),
+		done: make(chan struct{}),
+	}
+	if err := conn.Send([]byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Send([]byte("2")); err != ErrWSSendQueueFull {
+		t.Errorf("期望 ErrWSSendQueueFull, 得到 %v", err)
+	}
+	conn.Close()
+	if err := conn.Send([]byte("3")); err != ErrWSClosed {
+		t.Errorf("期望 ErrWSClosed, 得到 %v", err)
+	}
+}
+
+// waitFor 等待条件成立，超时则测试失败
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("等待条件成立超时")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}